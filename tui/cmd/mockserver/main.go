@@ -0,0 +1,78 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/rubber_duck/tui/internal/mockserver"
+)
+
+func main() {
+	opts := mockserver.DefaultOptions()
+
+	addr := flag.String("addr", "localhost:5555", "Address to listen on")
+	flag.StringVar(&opts.APIKey, "api-key", opts.APIKey, "API key accepted by the user socket")
+	flag.StringVar(&opts.Username, "username", opts.Username, "Username accepted by login")
+	flag.StringVar(&opts.Password, "password", opts.Password, "Password accepted by login")
+	flag.StringVar(&opts.Root, "root", opts.Root, "Directory served to list_files, read_file, and search_code")
+	flag.BoolVar(&opts.Stream, "stream", opts.Stream, "Stream responses in chunks")
+	flag.DurationVar(&opts.ChunkDelay, "chunk-delay", opts.ChunkDelay, "Delay between streamed chunks")
+	flag.IntVar(&opts.History, "history", opts.History, "Canned messages served as conversation history, paged by get_history")
+	roles := flag.String("roles", "", "Comma-separated roles sent with the logged-in user, e.g. admin")
+	permissions := flag.String("permissions", "", "Comma-separated permissions sent with the logged-in user, e.g. api_keys,sessions")
+	providerStatus := flag.String("provider-status", "", "Comma-separated provider health, e.g. openai=degraded,anthropic=unavailable")
+	verbose := flag.Bool("v", false, "Log every frame")
+	record := flag.String("record", "", "Proxy to -upstream and record frames to this cassette")
+	upstream := flag.String("upstream", "ws://localhost:4000", "Phoenix server to proxy when recording")
+	replay := flag.String("replay", "", "Serve a recorded cassette instead of canned responses")
+	replaySpeed := flag.Float64("replay-speed", 1, "Replay speed multiplier (0 sends frames without delay)")
+	flag.Parse()
+
+	if *roles != "" {
+		opts.Roles = strings.Split(*roles, ",")
+	}
+	if *permissions != "" {
+		opts.Permissions = strings.Split(*permissions, ",")
+	}
+
+	if *providerStatus != "" {
+		opts.ProviderStatus = make(map[string]string)
+		for _, entry := range strings.Split(*providerStatus, ",") {
+			provider, status, _ := strings.Cut(entry, "=")
+			opts.ProviderStatus[strings.ToLower(provider)] = status
+		}
+	}
+
+	if *verbose {
+		opts.Logger = log.New(os.Stderr, "mockserver: ", log.LstdFlags)
+	}
+
+	var handler http.Handler
+	switch {
+	case *record != "":
+		proxy, err := mockserver.NewProxy(*upstream, *record)
+		if err != nil {
+			log.Fatalf("Error creating cassette: %v", err)
+		}
+		// Frames are written as they arrive, so the cassette survives Ctrl+C
+		handler = proxy
+		log.Printf("Recording %s to %s", *upstream, *record)
+	case *replay != "":
+		cassette, err := mockserver.LoadCassette(*replay)
+		if err != nil {
+			log.Fatalf("Error loading cassette: %v", err)
+		}
+		replayer := mockserver.NewReplayer(cassette)
+		replayer.Speed = *replaySpeed
+		handler = replayer
+		log.Printf("Replaying %s", *replay)
+	default:
+		handler = mockserver.New(opts)
+	}
+
+	log.Printf("Mock Phoenix server listening on ws://%s/socket and ws://%s/auth_socket", *addr, *addr)
+	log.Fatal(http.ListenAndServe(*addr, handler))
+}
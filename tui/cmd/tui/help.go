@@ -0,0 +1,141 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/rubber_duck/tui/internal/ui"
+)
+
+// example is a sample invocation shown in --help and the man page
+type example struct {
+	command     string
+	description string
+}
+
+var examples = []example{
+	{"rubber_duck_tui", "Connect to a local server on port 5555"},
+	{"rubber_duck_tui -url ws://example.com:4000/socket -auth-url ws://example.com:4000/auth_socket", "Connect to another server"},
+	{"rubber_duck_tui -api-key YOUR_API_KEY", "Authenticate with an API key"},
+	{"rubber_duck_tui -url wss://duck.corp.example/socket -proxy socks5://127.0.0.1:1080 -ca-file corp-ca.pem", "Connect through a SOCKS proxy to a server with a private CA"},
+	{"rubber_duck_tui -demo", "Try the interface without a server"},
+	{"echo 'explain main.go' | rubber_duck_tui -headless", "Ask from a script and read the answer as JSON events"},
+	{"rubber_duck_tui -record session.jsonl", "Record the session for a bug report"},
+	{"rubber_duck_tui -replay session.jsonl -replay-speed 2", "Replay a recording at double speed without a server"},
+}
+
+// environment lists the variables the TUI reads
+var environment = []example{
+	{"RUBBER_DUCK_API_KEY", "API key used when -api-key is not given; takes precedence over the config file"},
+	{"HTTPS_PROXY, HTTP_PROXY, NO_PROXY", "Proxy for the server connection when -proxy and network.proxy are not set"},
+	{"RUBBER_DUCK_PASSPHRASE", "Passphrase for encrypted transcripts (tui.encryption = \"passphrase\") instead of typing it at startup"},
+	{"ALL_PROXY", "Proxy used when neither HTTPS_PROXY nor HTTP_PROXY is set, e.g. socks5://127.0.0.1:1080"},
+	{"HOME", "Configuration, saved conversations, and crash reports live in $HOME/.rubber_duck"},
+}
+
+// files lists the files the TUI reads and writes
+var files = []example{
+	{"~/.rubber_duck/config.json", "Configuration: API key, default model and provider, budgets, timeouts, telemetry"},
+	{"~/.rubber_duck/conversations/", "Conversations saved for offline reading and crash recovery"},
+	{"~/.rubber_duck/encryption.json", "How the transcript key is made, with a check that the key is right; no key is stored"},
+	{"~/.rubber_duck/crash/", "Crash reports"},
+	{"~/.rubber_duck/diagnostics.log", "Malformed server payloads"},
+	{"~/.rubber_duck/audit.log", "Commands run, with duration and status; arguments only as hashes"},
+}
+
+// printUsage writes the --help text
+func printUsage(w io.Writer) {
+	fmt.Fprintf(w, "RubberDuck TUI - terminal client for the RubberDuck coding assistant\n\n")
+	fmt.Fprintf(w, "Usage:\n  rubber_duck_tui [flags]\n\nFlags:\n")
+	flag.VisitAll(func(f *flag.Flag) {
+		name, usage := flag.UnquoteUsage(f)
+		fmt.Fprintf(w, "  -%s", f.Name)
+		if name != "" {
+			fmt.Fprintf(w, " %s", name)
+		}
+		fmt.Fprintf(w, "\n      %s", usage)
+		switch {
+		case name == "string" && f.DefValue != "":
+			fmt.Fprintf(w, " (default %q)", f.DefValue)
+		case name != "string" && f.DefValue != "false":
+			fmt.Fprintf(w, " (default %s)", f.DefValue)
+		}
+		fmt.Fprintln(w)
+	})
+
+	fmt.Fprintf(w, "\nExamples:\n")
+	for _, e := range examples {
+		fmt.Fprintf(w, "  %s\n      %s\n", e.command, e.description)
+	}
+	fmt.Fprintf(w, "\nEnvironment:\n")
+	for _, e := range environment {
+		fmt.Fprintf(w, "  %-20s %s\n", e.command, e.description)
+	}
+	fmt.Fprintf(w, "\nSlash commands (type in chat):\n")
+	for _, line := range strings.Split(strings.TrimRight(ui.FormatSlashCommandList(), "\n"), "\n") {
+		fmt.Fprintf(w, "  %s\n", line)
+	}
+	fmt.Fprintf(w, "\nRun with -man for the full manual page.\n")
+}
+
+// printManPage writes a roff manual page, e.g. for man -l
+func printManPage(w io.Writer) {
+	fmt.Fprintf(w, ".TH RUBBER_DUCK_TUI 1\n")
+	fmt.Fprintf(w, ".SH NAME\nrubber_duck_tui \\- terminal client for the RubberDuck coding assistant\n")
+	fmt.Fprintf(w, ".SH SYNOPSIS\n.B rubber_duck_tui\n[\\fIflags\\fR]\n")
+	fmt.Fprintf(w, ".SH DESCRIPTION\nA chat, file tree, and editor interface to a RubberDuck Phoenix server. Type a message to ask the assistant, or a slash command listed below.\n")
+
+	fmt.Fprintf(w, ".SH OPTIONS\n")
+	flag.VisitAll(func(f *flag.Flag) {
+		name, usage := flag.UnquoteUsage(f)
+		fmt.Fprintf(w, ".TP\n.B \\-%s", roff(f.Name))
+		if name != "" {
+			fmt.Fprintf(w, " \\fI%s\\fR", roff(name))
+		}
+		fmt.Fprintf(w, "\n%s", roff(usage))
+		if f.DefValue != "" && f.DefValue != "false" {
+			fmt.Fprintf(w, " (default %s)", roff(f.DefValue))
+		}
+		fmt.Fprintln(w)
+	})
+
+	fmt.Fprintf(w, ".SH SLASH COMMANDS\n")
+	for _, c := range ui.SlashCommands {
+		fmt.Fprintf(w, ".TP\n.B %s\n%s", roff(c.Usage()), roff(c.Summary))
+		if len(c.Aliases) > 0 {
+			fmt.Fprintf(w, ". Also /%s", roff(strings.Join(c.Aliases, ", /")))
+		}
+		fmt.Fprintln(w, ".")
+		for _, e := range c.Examples {
+			fmt.Fprintf(w, ".br\nExample: %s\n", roff(e))
+		}
+		if len(c.Related) > 0 {
+			fmt.Fprintf(w, ".br\nSee also: /%s\n", roff(strings.Join(c.Related, ", /")))
+		}
+	}
+
+	fmt.Fprintf(w, ".SH EXAMPLES\n")
+	for _, e := range examples {
+		fmt.Fprintf(w, ".TP\n.B %s\n%s\n", roff(e.command), roff(e.description))
+	}
+	fmt.Fprintf(w, ".SH ENVIRONMENT\n")
+	for _, e := range environment {
+		fmt.Fprintf(w, ".TP\n.B %s\n%s\n", roff(e.command), roff(e.description))
+	}
+	fmt.Fprintf(w, ".SH FILES\n")
+	for _, e := range files {
+		fmt.Fprintf(w, ".TP\n.I %s\n%s\n", roff(e.command), roff(e.description))
+	}
+}
+
+// roff escapes text for a man page
+func roff(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "-", `\-`)
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = `\&` + s
+	}
+	return s
+}
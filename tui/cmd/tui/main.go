@@ -0,0 +1,392 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/phoenix"
+	"github.com/rubber_duck/tui/internal/ui"
+	"golang.org/x/term"
+)
+
+func init() {
+	// Suppress logging at the earliest possible moment - even before main()
+	log.SetOutput(ioutil.Discard)
+	log.SetFlags(0)
+	log.SetPrefix("")
+}
+
+func main() {
+	// Re-ensure logging is suppressed (belt and suspenders)
+	log.SetOutput(ioutil.Discard)
+	log.SetFlags(0)
+	
+	// Parse command line flags
+	var (
+		url       = flag.String("url", "ws://localhost:5555/socket", "Phoenix WebSocket URL (authenticated)")
+		authURL   = flag.String("auth-url", "ws://localhost:5555/auth_socket", "Phoenix Auth WebSocket URL")
+		apiKey    = flag.String("api-key", "", "API key for authentication")
+		debug     = flag.Bool("debug", false, "Enable debug logging")
+		mouse     = flag.Bool("mouse", false, "Enable mouse support for scrolling (disables text selection)")
+		record    = flag.String("record", "", "Record the session's messages to a file")
+		replay    = flag.String("replay", "", "Replay a recorded session instead of connecting")
+		speed     = flag.Float64("replay-speed", 1.0, "Playback speed multiplier for --replay")
+		restore   = flag.String("restore", "", "Reopen a saved conversation, as offered after a crash")
+		demo      = flag.Bool("demo", false, "Try the interface against a built-in simulated server; no server or credentials are used")
+		man       = flag.Bool("man", false, "Print the manual page in roff format (man -l <(rubber_duck_tui -man))")
+		proxy     = flag.String("proxy", "", "Proxy for the server connection: http://, https://, or socks5://host:port, or \"direct\" to ignore HTTPS_PROXY")
+		caFile    = flag.String("ca-file", "", "PEM bundle of certificate authorities to trust besides the system ones")
+		certFile  = flag.String("client-cert", "", "Client certificate (PEM) for servers that require mutual TLS")
+		keyFile   = flag.String("client-key", "", "Private key (PEM) for -client-cert, if not in the same file")
+		insecure  = flag.Bool("insecure", false, "Skip verifying the server's TLS certificate (testing only)")
+		compress  = flag.Bool("compress", false, "Negotiate permessage-deflate compression with the server")
+		ping      = flag.Int("ping", 0, "Seconds between heartbeats; 0 uses the config or 30, negative sends none")
+		profile   = flag.String("network-profile", "", "Use one of the network_profiles in config")
+		metricsAt = flag.String("metrics-addr", "", "Serve Prometheus metrics on this localhost address, e.g. 127.0.0.1:9464")
+		otlp      = flag.String("otlp-endpoint", "", "Export command spans as OTLP traces to this URL, e.g. http://localhost:4318/v1/traces")
+		headless  = flag.Bool("headless", false, "Run without a screen: read messages and slash commands from stdin, one per line, and write what happens to stdout")
+		format    = flag.String("format", "json", "Output of -headless: json (one event per line) or text")
+	)
+	flag.CommandLine.SetOutput(os.Stdout)
+	flag.Usage = func() { printUsage(flag.CommandLine.Output()) }
+	flag.Parse()
+	
+	if *man {
+		printManPage(os.Stdout)
+		return
+	}
+	
+	// More aggressive suppression for non-debug mode
+	if !*debug {
+		// Create a devnull file
+		devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0755)
+		if err == nil {
+			// Redirect stderr file descriptor directly using dup2
+			// This catches output at the lowest level
+			err = syscall.Dup2(int(devNull.Fd()), 2) // 2 is stderr
+			if err != nil {
+				// Fallback to high-level redirect
+				os.Stderr = devNull
+			}
+		}
+		
+		// Additional suppression: disable all Go default loggers
+		log.SetOutput(ioutil.Discard)
+		log.SetFlags(0)
+		log.SetPrefix("")
+	}
+	if !*debug && !*headless {
+		// Clear any existing terminal content that might interfere
+		fmt.Print("\033[2J\033[H") // Clear screen and move cursor to top
+		
+		// Additional terminal control to prevent output leakage
+		fmt.Print("\033[?1049h") // Save screen and use alternate buffer
+		fmt.Print("\033[3J")     // Clear scrollback buffer
+	}
+	
+	// Load API key from various sources; the demo never uses one
+	finalAPIKey := loadAPIKey(*apiKey)
+	if *demo {
+		finalAPIKey = ""
+	}
+
+	// Create the model
+	model := ui.NewModel()
+	
+	// Set mouse mode based on flag
+	model.SetMouseEnabled(*mouse)
+	
+	// Configure Phoenix connection
+	if *url != "" {
+		model.SetPhoenixConfig(*url, *authURL, finalAPIKey)
+	}
+	network := phoenix.NetworkOptions{
+		Proxy:              *proxy,
+		CAFile:             *caFile,
+		CertFile:           *certFile,
+		KeyFile:            *keyFile,
+		InsecureSkipVerify: *insecure,
+		PingSeconds:        *ping,
+	}
+	if *compress {
+		network.Compression = compress
+	}
+	model.SetNetworkOptions(network)
+	if *profile != "" {
+		if err := model.SetNetworkProfile(*profile); err != nil {
+			fmt.Fprintln(os.Stdout, "Cannot use network profile:", err)
+			os.Exit(1)
+		}
+	}
+	
+	// Answer from the scripted demo instead of a server
+	if *demo {
+		if err := model.EnableDemo(); err != nil {
+			fmt.Fprintln(os.Stdout, "Cannot start demo:", err)
+			os.Exit(1)
+		}
+	}
+	
+	// Expose usage metrics to local monitoring
+	if err := model.StartMetrics(*metricsAt, *otlp); err != nil {
+		fmt.Fprintln(os.Stdout, "Cannot serve metrics:", err)
+		os.Exit(1)
+	}
+	defer model.StopMetrics()
+	
+	// Unlock encrypted transcripts before any are read or written
+	if err := model.SetupEncryption(readPassphrase); err != nil {
+		fmt.Fprintln(os.Stdout, "Cannot unlock transcripts:", err)
+		os.Exit(1)
+	}
+	
+	// Reopen the session saved by a crash report
+	if *restore != "" {
+		if err := model.RestoreSession(*restore); err != nil {
+			fmt.Fprintln(os.Stdout, "Cannot restore session:", err)
+			os.Exit(1)
+		}
+	}
+
+	// Create the program with additional options to ensure full terminal usage
+	programOpts := []tea.ProgramOption{
+		tea.WithAltScreen(),     // Use alternate screen buffer
+		tea.WithoutCatchPanics(), // Let us handle panics
+		tea.WithInputTTY(),       // Force TTY input handling
+	}
+	
+	// Only enable mouse support if explicitly enabled
+	if *mouse {
+		programOpts = append(programOpts, tea.WithMouseCellMotion())
+	}
+	// Headless runs read stdin themselves and draw nothing
+	if *headless {
+		programOpts = []tea.ProgramOption{tea.WithoutRenderer(), tea.WithInput(nil), tea.WithoutCatchPanics()}
+	}
+	
+	// Track recent messages so a panic can be written up, then wrap the
+	// guarded model for recording or replay. Headless runs drive the model
+	// themselves, so the guard goes around them instead
+	guard := ui.NewCrashGuard(model)
+	var program tea.Model = guard
+	if *replay != "" {
+		msgs, err := ui.LoadRecording(*replay)
+		if err != nil {
+			fmt.Fprintln(os.Stdout, "Cannot replay:", err)
+			os.Exit(1)
+		}
+		program = ui.NewReplayModel(guard, msgs, *speed)
+	} else if *headless {
+		if *format != "json" && *format != "text" {
+			fmt.Fprintln(os.Stdout, "Unknown -format:", *format)
+			os.Exit(1)
+		}
+		guard = ui.NewCrashGuard(ui.NewHeadlessModel(model, os.Stdin, os.Stdout, *format))
+		program = guard
+	} else if *record != "" {
+		recorder, err := ui.NewRecorder(*record, finalAPIKey)
+		if err != nil {
+			fmt.Fprintln(os.Stdout, "Cannot record:", err)
+			os.Exit(1)
+		}
+		defer recorder.Close()
+		program = ui.NewRecordingModel(guard, recorder)
+	}
+	
+	p := tea.NewProgram(program, programOpts...)
+	
+	// Store program reference for UI components
+	ui.SetProgramHolder(p)
+	
+	// Set up Phoenix client with program reference; its channel manager
+	// delivers messages for every channel on the user socket
+	if phoenixClient := model.GetPhoenixClient(); phoenixClient != nil {
+		if client, ok := phoenixClient.(*phoenix.Client); ok {
+			client.SetProgram(p)
+		}
+	}
+	
+	// The demo's scripted answers stream to the program
+	if demoClient := model.DemoClient(); demoClient != nil {
+		demoClient.SetProgram(p)
+	}
+	
+	// Set up Auth client with program reference
+	if authClient := model.GetAuthClient(); authClient != nil {
+		if client, ok := authClient.(*phoenix.AuthClient); ok {
+			client.SetProgram(p)
+		}
+	}
+
+
+	// Enable debug logging if requested (stderr redirection already handled above)
+	if *debug {
+		// Re-enable stderr for debug mode
+		f, err := tea.LogToFile("debug.log", "debug")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "fatal: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		// Re-enable standard logging for debug
+		log.SetOutput(f)
+		log.SetFlags(log.LstdFlags)
+	}
+
+	// Set up cleanup on exit
+	defer ui.RemoveHistorySpill()
+	defer func() {
+		if !*debug && !*headless {
+			// Restore terminal state
+			fmt.Print("\033[?1049l") // Restore screen from alternate buffer
+			fmt.Print("\033[2J\033[H") // Clear screen one more time
+		}
+	}()
+	
+	// Report panics instead of leaving the terminal in raw mode
+	defer func() {
+		if r := recover(); r != nil {
+			handleCrash(p, guard, r, *debug)
+		}
+	}()
+	
+	// Run the program with better error handling
+	final, err := p.Run()
+	if err != nil {
+		// Don't use log.Fatal as it might output to stderr
+		if *debug {
+			fmt.Fprintln(os.Stderr, "TUI Error:", err)
+		}
+		os.Exit(1)
+	}
+	// Scripts can tell from the exit status whether anything failed
+	if guarded, ok := final.(ui.CrashGuard); ok {
+		final = guarded.Unwrap()
+	}
+	if run, ok := final.(ui.HeadlessModel); ok && run.Failed() {
+		os.Exit(1)
+	}
+}
+
+// handleCrash restores the terminal, writes a crash report, and offers to
+// reopen the TUI with the conversation restored
+func handleCrash(p *tea.Program, guard ui.CrashGuard, recovered any, debug bool) {
+	p.ReleaseTerminal()
+	if !debug {
+		fmt.Print("\033[?1049l") // Leave the alternate buffer we entered by hand
+	}
+	
+	fmt.Fprintf(os.Stdout, "RubberDuck TUI crashed: %v\n", recovered)
+	report, err := guard.WriteCrashReport(recovered)
+	if err != nil {
+		fmt.Fprintf(os.Stdout, "Could not write crash report: %v\n", err)
+	} else {
+		fmt.Fprintf(os.Stdout, "Crash report written to %s\n", report.Path)
+	}
+	if report.SessionID == "" {
+		os.Exit(2)
+	}
+	
+	fmt.Fprint(os.Stdout, "Reopen with your conversation restored? [Y/n] ")
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "" && answer != "y" && answer != "yes" {
+		fmt.Fprintf(os.Stdout, "Restore it later with --restore %s\n", report.SessionID)
+		os.Exit(2)
+	}
+	
+	executable, err := os.Executable()
+	if err == nil {
+		err = syscall.Exec(executable, restoreArgs(os.Args, report.SessionID), os.Environ())
+	}
+	fmt.Fprintf(os.Stdout, "Could not restart: %v\nRestore it with --restore %s\n", err, report.SessionID)
+	os.Exit(2)
+}
+
+// restoreArgs returns the command line with --restore set to sessionID
+func restoreArgs(args []string, sessionID string) []string {
+	restarted := []string{args[0]}
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "-restore" || arg == "--restore":
+			i++ // Drop the flag's value too
+		case strings.HasPrefix(arg, "-restore=") || strings.HasPrefix(arg, "--restore="):
+		default:
+			restarted = append(restarted, arg)
+		}
+	}
+	return append(restarted, "--restore", sessionID)
+}
+
+// loadAPIKey loads the API key from various sources in order of precedence:
+// 1. Command line flag (if provided)
+// 2. RUBBER_DUCK_API_KEY environment variable
+// 3. ~/.rubber_duck/config.json file
+func loadAPIKey(flagValue string) string {
+	// 1. Command line flag takes precedence
+	if flagValue != "" {
+		return flagValue
+	}
+	
+	// 2. Environment variable
+	if envKey := os.Getenv("RUBBER_DUCK_API_KEY"); envKey != "" {
+		return envKey
+	}
+	
+	// 3. Config file
+	homeDir, err := os.UserHomeDir()
+	if err == nil {
+		configPath := filepath.Join(homeDir, ".rubber_duck", "config.json")
+		if data, err := os.ReadFile(configPath); err == nil {
+			var config map[string]interface{}
+			if err := json.Unmarshal(data, &config); err == nil {
+				if apiKey, ok := config["api_key"].(string); ok && apiKey != "" {
+					return apiKey
+				}
+			}
+		}
+	}
+	
+	return ""
+}
+
+// readPassphrase reads a passphrase from the terminal without echoing it
+func readPassphrase(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("no terminal to type the passphrase in; set RUBBER_DUCK_PASSPHRASE")
+	}
+	fmt.Fprint(os.Stdout, prompt)
+	passphrase, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stdout)
+	return string(passphrase), err
+}
+
+// containsErrorMarkers checks if output contains error message markers
+func containsErrorMarkers(output string) bool {
+	errorMarkers := []string{
+		"[ERROR]",
+		"[WARN]",
+		"Connection error:",
+		"dial tcp",
+		"connection refused",
+		"<socket>",
+		"<channel>",
+	}
+	
+	for _, marker := range errorMarkers {
+		if strings.Contains(output, marker) {
+			return true
+		}
+	}
+	return false
+}
\ No newline at end of file
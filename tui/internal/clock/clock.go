@@ -0,0 +1,37 @@
+// Package clock provides the current time so tests can freeze it
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+var (
+	mu  sync.RWMutex
+	now = time.Now
+)
+
+// Now returns the current time, or the frozen time in tests
+func Now() time.Time {
+	mu.RLock()
+	defer mu.RUnlock()
+	return now()
+}
+
+// Since returns the time elapsed since t
+func Since(t time.Time) time.Duration {
+	return Now().Sub(t)
+}
+
+// Freeze fixes the clock at t until the returned restore function is called
+func Freeze(t time.Time) (restore func()) {
+	mu.Lock()
+	defer mu.Unlock()
+	previous := now
+	now = func() time.Time { return t }
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		now = previous
+	}
+}
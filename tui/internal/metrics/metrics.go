@@ -0,0 +1,278 @@
+// Package metrics counts what the TUI does, for users who want to graph
+// their assistant usage. Counters and latencies are served in the
+// Prometheus text format on a localhost listener, and command spans are
+// exported as OTLP traces. Only names, statuses, and timings are recorded,
+// never message content or arguments
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metric names
+const (
+	CommandsTotal   = "rubber_duck_commands_total"
+	CommandSeconds  = "rubber_duck_command_duration_seconds"
+	RequestsTotal   = "rubber_duck_requests_total"
+	RequestSeconds  = "rubber_duck_request_duration_seconds"
+	ErrorsTotal     = "rubber_duck_errors_total"
+	ReconnectsTotal = "rubber_duck_reconnects_total"
+)
+
+// help describes each metric on the /metrics page
+var help = map[string]string{
+	CommandsTotal:   "Commands run, by command and status.",
+	CommandSeconds:  "Time the TUI spent running a command.",
+	RequestsTotal:   "Server requests answered or given up on, by event.",
+	RequestSeconds:  "Time from sending a request until the server answered it.",
+	ErrorsTotal:     "Errors shown or counted, by component and error code.",
+	ReconnectsTotal: "Reconnection attempts.",
+}
+
+// buckets are the histogram upper bounds in seconds
+var buckets = []float64{0.005, 0.025, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// histogram counts observations per bucket
+type histogram struct {
+	counts []uint64 // Per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+// Registry holds the counters and histograms. The zero value is not usable;
+// create one with New. A nil Registry ignores everything, so callers need
+// not check whether metrics are on
+type Registry struct {
+	mu         sync.Mutex
+	counters   map[string]map[string]float64 // Metric, then label set
+	histograms map[string]map[string]*histogram
+	server     *http.Server
+	addr       string
+	exporter   *Exporter
+}
+
+// New creates an empty registry
+func New() *Registry {
+	return &Registry{
+		counters:   make(map[string]map[string]float64),
+		histograms: make(map[string]map[string]*histogram),
+	}
+}
+
+// labelSet renders name/value pairs as a Prometheus label set, sorted by
+// name so the same labels always make the same series
+func labelSet(labels ...string) string {
+	var pairs []string
+	for i := 0; i+1 < len(labels); i += 2 {
+		value := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(labels[i+1])
+		pairs = append(pairs, fmt.Sprintf("%s=%q", labels[i], value))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// Inc adds one to a counter; labels are name/value pairs
+func (r *Registry) Inc(name string, labels ...string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.counters[name] == nil {
+		r.counters[name] = make(map[string]float64)
+	}
+	r.counters[name][labelSet(labels...)]++
+}
+
+// Observe records a duration in a histogram; labels are name/value pairs
+func (r *Registry) Observe(name string, d time.Duration, labels ...string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.histograms[name] == nil {
+		r.histograms[name] = make(map[string]*histogram)
+	}
+	key := labelSet(labels...)
+	h := r.histograms[name][key]
+	if h == nil {
+		h = &histogram{counts: make([]uint64, len(buckets))}
+		r.histograms[name][key] = h
+	}
+	seconds := d.Seconds()
+	for i, bound := range buckets {
+		if seconds <= bound {
+			h.counts[i]++
+			break
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+// Counter returns a counter's value summed over all label sets
+func (r *Registry) Counter(name string) float64 {
+	if r == nil {
+		return 0
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	total := 0.0
+	for _, value := range r.counters[name] {
+		total += value
+	}
+	return total
+}
+
+// WritePrometheus writes every metric in the Prometheus text format
+func (r *Registry) WritePrometheus(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var b strings.Builder
+	for _, name := range sortedKeys(r.counters) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s counter\n", name, help[name], name)
+		for _, labels := range sortedKeys(r.counters[name]) {
+			fmt.Fprintf(&b, "%s%s %g\n", name, braced(labels), r.counters[name][labels])
+		}
+	}
+	for _, name := range sortedKeys(r.histograms) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s histogram\n", name, help[name], name)
+		for _, labels := range sortedKeys(r.histograms[name]) {
+			h := r.histograms[name][labels]
+			var cumulative uint64
+			for i, bound := range buckets {
+				cumulative += h.counts[i]
+				fmt.Fprintf(&b, "%s_bucket%s %d\n", name, braced(joinLabels(labels, fmt.Sprintf("le=\"%g\"", bound))), cumulative)
+			}
+			fmt.Fprintf(&b, "%s_bucket%s %d\n", name, braced(joinLabels(labels, `le="+Inf"`)), h.count)
+			fmt.Fprintf(&b, "%s_sum%s %g\n", name, braced(labels), h.sum)
+			fmt.Fprintf(&b, "%s_count%s %d\n", name, braced(labels), h.count)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// braced wraps a non-empty label set in braces
+func braced(labels string) string {
+	if labels == "" {
+		return ""
+	}
+	return "{" + labels + "}"
+}
+
+// joinLabels appends a label to a label set
+func joinLabels(labels, label string) string {
+	if labels == "" {
+		return label
+	}
+	return labels + "," + label
+}
+
+// sortedKeys returns a map's keys in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Serve exposes the metrics at http://addr/metrics. Only loopback
+// addresses are accepted, since the counters describe the user's work
+func (r *Registry) Serve(addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("metrics address %q: %w", addr, err)
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return fmt.Errorf("metrics address %q: only localhost or a loopback IP is allowed", addr)
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = r.WritePrometheus(w)
+	})
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			r.mu.Lock()
+			r.addr = ""
+			r.mu.Unlock()
+		}
+	}()
+
+	r.mu.Lock()
+	r.server, r.addr = server, listener.Addr().String()
+	r.mu.Unlock()
+	return nil
+}
+
+// Addr returns where the metrics are served, "" when they aren't
+func (r *Registry) Addr() string {
+	if r == nil {
+		return ""
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.addr
+}
+
+// SetExporter sends spans to an OTLP exporter; nil stops exporting
+func (r *Registry) SetExporter(exporter *Exporter) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.exporter = exporter
+}
+
+// Exporter returns the span exporter, nil when traces aren't exported
+func (r *Registry) Exporter() *Exporter {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.exporter
+}
+
+// Span records a span when traces are exported
+func (r *Registry) Span(span Span) {
+	r.Exporter().Record(span)
+}
+
+// Close stops the listener and sends the spans not yet exported
+func (r *Registry) Close(ctx context.Context) error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	server, exporter := r.server, r.exporter
+	r.server, r.addr = nil, ""
+	r.mu.Unlock()
+
+	var errs []error
+	if server != nil {
+		errs = append(errs, server.Shutdown(ctx))
+	}
+	if exporter != nil {
+		errs = append(errs, exporter.Close(ctx))
+	}
+	return errors.Join(errs...)
+}
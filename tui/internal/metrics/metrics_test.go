@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServePrometheus(t *testing.T) {
+	r := New()
+	r.Inc(CommandsTotal, "status", "ok", "command", "help")
+	r.Inc(CommandsTotal, "command", "help", "status", "ok")
+	r.Observe(RequestSeconds, 300*time.Millisecond, "event", "message")
+
+	if err := r.Serve("0.0.0.0:0"); err == nil {
+		t.Fatal("Expected a non-loopback address to be refused")
+	}
+	if err := r.Serve("127.0.0.1:0"); err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close(context.Background())
+
+	resp, err := http.Get("http://" + r.Addr() + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	for _, want := range []string{
+		"# TYPE rubber_duck_commands_total counter",
+		`rubber_duck_commands_total{command="help",status="ok"} 2`,
+		`rubber_duck_request_duration_seconds_bucket{event="message",le="0.25"} 0`,
+		`rubber_duck_request_duration_seconds_bucket{event="message",le="0.5"} 1`,
+		`rubber_duck_request_duration_seconds_count{event="message"} 1`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("Expected %q in:\n%s", want, body)
+		}
+	}
+}
+
+func TestExporterSendsOTLP(t *testing.T) {
+	received := make(chan otlpTraces, 1)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var traces otlpTraces
+		json.NewDecoder(req.Body).Decode(&traces)
+		received <- traces
+	}))
+	defer collector.Close()
+
+	exporter := NewExporter(collector.URL)
+	start := time.Unix(100, 0)
+	exporter.Record(Span{Name: "command help", Start: start, End: start.Add(time.Second), Failed: true})
+	if err := exporter.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	traces := <-received
+	span := traces.ResourceSpans[0].ScopeSpans[0].Spans[0]
+	if span.Name != "command help" || span.StartTimeUnixNano != "100000000000" || span.Status.Code != 2 {
+		t.Errorf("Unexpected span: %+v", span)
+	}
+	if len(span.TraceID) != 32 || len(span.SpanID) != 16 {
+		t.Errorf("Expected hex trace and span IDs, got %q and %q", span.TraceID, span.SpanID)
+	}
+}
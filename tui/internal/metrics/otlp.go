@@ -0,0 +1,247 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// exportInterval is how often buffered spans are sent
+const exportInterval = 5 * time.Second
+
+// maxBufferedSpans caps the spans held while the collector is unreachable;
+// the oldest are dropped beyond it
+const maxBufferedSpans = 2048
+
+// exportTimeout bounds one export request
+const exportTimeout = 10 * time.Second
+
+// serviceName identifies the TUI to the collector
+const serviceName = "rubber_duck_tui"
+
+// Span is a timed operation: a command run in the TUI or a request to the
+// server
+type Span struct {
+	Name       string
+	Start      time.Time
+	End        time.Time
+	Attributes map[string]string
+	Failed     bool
+}
+
+// Exporter sends spans to an OTLP/HTTP collector as JSON, batching them in
+// the background. It is safe for concurrent use
+type Exporter struct {
+	endpoint string
+	client   *http.Client
+
+	mu      sync.Mutex
+	spans   []Span
+	dropped int
+	lastErr error
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewExporter starts exporting to an OTLP traces endpoint such as
+// http://localhost:4318/v1/traces
+func NewExporter(endpoint string) *Exporter {
+	e := &Exporter{
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: exportTimeout},
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go e.run()
+	return e
+}
+
+// Endpoint returns where spans are sent
+func (e *Exporter) Endpoint() string {
+	return e.endpoint
+}
+
+// Record buffers a span for the next export
+func (e *Exporter) Record(span Span) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, span)
+	if over := len(e.spans) - maxBufferedSpans; over > 0 {
+		e.spans = e.spans[over:]
+		e.dropped += over
+	}
+}
+
+// Status returns the spans waiting, the spans dropped, and the last export
+// error
+func (e *Exporter) Status() (pending, dropped int, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.spans), e.dropped, e.lastErr
+}
+
+// run exports on a timer until Close
+func (e *Exporter) run() {
+	defer close(e.done)
+	ticker := time.NewTicker(exportInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+			_ = e.Flush(ctx)
+			cancel()
+		case <-e.stop:
+			return
+		}
+	}
+}
+
+// Flush sends the buffered spans now. Spans a failed export held are kept
+// for the next one
+func (e *Exporter) Flush(ctx context.Context) error {
+	e.mu.Lock()
+	spans := e.spans
+	e.spans = nil
+	e.mu.Unlock()
+	if len(spans) == 0 {
+		return nil
+	}
+
+	err := e.send(ctx, spans)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.lastErr = err
+	if err != nil {
+		e.spans = append(spans, e.spans...)
+		if over := len(e.spans) - maxBufferedSpans; over > 0 {
+			e.spans = e.spans[over:]
+			e.dropped += over
+		}
+	}
+	return err
+}
+
+// Close stops the background exports and sends what is left
+func (e *Exporter) Close(ctx context.Context) error {
+	close(e.stop)
+	<-e.done
+	return e.Flush(ctx)
+}
+
+// send posts spans to the collector
+func (e *Exporter) send(ctx context.Context, spans []Span) error {
+	data, err := json.Marshal(otlpRequest(spans))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("trace export rejected: %s", resp.Status)
+	}
+	return nil
+}
+
+// OTLP JSON encoding of an ExportTraceServiceRequest, limited to the fields
+// the TUI sets
+type (
+	otlpTraces struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+	otlpResource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	}
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+	otlpSpan struct {
+		TraceID           string          `json:"traceId"`
+		SpanID            string          `json:"spanId"`
+		Name              string          `json:"name"`
+		Kind              int             `json:"kind"`
+		StartTimeUnixNano string          `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+		Attributes        []otlpAttribute `json:"attributes,omitempty"`
+		Status            otlpStatus      `json:"status"`
+	}
+	otlpAttribute struct {
+		Key   string    `json:"key"`
+		Value otlpValue `json:"value"`
+	}
+	otlpValue struct {
+		StringValue string `json:"stringValue"`
+	}
+	otlpStatus struct {
+		Code int `json:"code"` // 1 is OK, 2 is error
+	}
+)
+
+// spanKindClient marks spans as calls out of the TUI
+const spanKindClient = 3
+
+// otlpRequest encodes spans for the collector. Each span is its own trace,
+// since commands don't share a request context
+func otlpRequest(spans []Span) otlpTraces {
+	encoded := make([]otlpSpan, 0, len(spans))
+	for _, span := range spans {
+		status := otlpStatus{Code: 1}
+		if span.Failed {
+			status.Code = 2
+		}
+		var attributes []otlpAttribute
+		for _, key := range sortedKeys(span.Attributes) {
+			attributes = append(attributes, otlpAttribute{Key: key, Value: otlpValue{StringValue: span.Attributes[key]}})
+		}
+		encoded = append(encoded, otlpSpan{
+			TraceID:           randomID(16),
+			SpanID:            randomID(8),
+			Name:              span.Name,
+			Kind:              spanKindClient,
+			StartTimeUnixNano: strconv.FormatInt(span.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.End.UnixNano(), 10),
+			Attributes:        attributes,
+			Status:            status,
+		})
+	}
+	return otlpTraces{ResourceSpans: []otlpResourceSpans{{
+		Resource: otlpResource{Attributes: []otlpAttribute{
+			{Key: "service.name", Value: otlpValue{StringValue: serviceName}},
+		}},
+		ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{Name: serviceName}, Spans: encoded}},
+	}}}
+}
+
+// randomID returns n random bytes in hex
+func randomID(n int) string {
+	id := make([]byte, n)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,196 @@
+package mockserver
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// cassetteVersion is written to cassette headers and checked on load
+const cassetteVersion = 1
+
+// Frame directions in a cassette
+const (
+	FromClient = "client"
+	FromServer = "server"
+)
+
+// redacted replaces secret payload values; replay treats it as a wildcard
+const redacted = "[REDACTED]"
+
+// secretFields are payload keys whose string values are never recorded
+var secretFields = map[string]bool{
+	"password": true,
+	"token":    true,
+	"api_key":  true,
+	"key":      true,
+}
+
+// Cassette is recorded Phoenix traffic, one entry per websocket frame
+type Cassette struct {
+	RecordedAt time.Time
+	Frames     []CassetteFrame
+}
+
+// CassetteFrame is one recorded frame. Conn numbers the websocket connections
+// in the order they were opened; At is the time since that connection opened
+type CassetteFrame struct {
+	Conn  int             `json:"conn"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	At    time.Duration   `json:"at"`
+	Frame json.RawMessage `json:"frame"`
+}
+
+// cassetteHeader is the first line of a cassette file
+type cassetteHeader struct {
+	Version    int       `json:"version"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// LoadCassette reads a cassette file
+func LoadCassette(path string) (*Cassette, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	if !scanner.Scan() {
+		return nil, fmt.Errorf("%s: empty cassette", path)
+	}
+	var header cassetteHeader
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil {
+		return nil, fmt.Errorf("%s: bad header: %w", path, err)
+	}
+	if header.Version != cassetteVersion {
+		return nil, fmt.Errorf("%s: unsupported cassette version %d", path, header.Version)
+	}
+
+	cassette := &Cassette{RecordedAt: header.RecordedAt}
+	for line := 2; scanner.Scan(); line++ {
+		var f CassetteFrame
+		if err := json.Unmarshal(scanner.Bytes(), &f); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		cassette.Frames = append(cassette.Frames, f)
+	}
+	return cassette, scanner.Err()
+}
+
+// cassetteWriter appends frames to a cassette file as they are recorded
+type cassetteWriter struct {
+	mu    sync.Mutex
+	file  *os.File
+	conns int
+}
+
+// newCassetteWriter creates the cassette file and writes its header
+func newCassetteWriter(path string) (*cassetteWriter, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	header, _ := json.Marshal(cassetteHeader{Version: cassetteVersion, RecordedAt: time.Now().UTC()})
+	if _, err := file.Write(append(header, '\n')); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &cassetteWriter{file: file}, nil
+}
+
+// nextConn numbers a newly opened connection
+func (w *cassetteWriter) nextConn() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.conns++
+	return w.conns
+}
+
+// write records one frame, redacting secrets; heartbeats are not recorded
+func (w *cassetteWriter) write(conn int, path, from string, at time.Duration, data []byte) error {
+	f, err := decodeFrame(data)
+	if err != nil || f.isHeartbeat() {
+		return err
+	}
+	redact(f.Payload)
+	encoded, err := f.encode()
+	if err != nil {
+		return err
+	}
+	line, err := json.Marshal(CassetteFrame{Conn: conn, Path: path, From: from, At: at, Frame: encoded})
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, err = w.file.Write(append(line, '\n'))
+	return err
+}
+
+// Close closes the cassette file
+func (w *cassetteWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.file.Close()
+}
+
+// redact replaces secret string values in a decoded payload, recursively
+func redact(value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if _, ok := item.(string); ok && secretFields[key] {
+				v[key] = redacted
+				continue
+			}
+			redact(item)
+		}
+	case []any:
+		for _, item := range v {
+			redact(item)
+		}
+	}
+}
+
+// payloadMatches compares a recorded payload with a live one, treating
+// redacted values as wildcards
+func payloadMatches(recorded, live any) bool {
+	switch r := recorded.(type) {
+	case string:
+		if r == redacted {
+			return true
+		}
+	case map[string]any:
+		l, ok := live.(map[string]any)
+		if !ok || len(l) != len(r) {
+			return false
+		}
+		for key, value := range r {
+			if !payloadMatches(value, l[key]) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		l, ok := live.([]any)
+		if !ok || len(l) != len(r) {
+			return false
+		}
+		for i := range r {
+			if !payloadMatches(r[i], l[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	recordedJSON, _ := json.Marshal(recorded)
+	liveJSON, _ := json.Marshal(live)
+	return string(recordedJSON) == string(liveJSON)
+}
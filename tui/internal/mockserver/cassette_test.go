@@ -0,0 +1,106 @@
+package mockserver
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// expectResponse sends a message and checks the conversation response
+func expectResponse(t *testing.T, client *phoenix.Client, msgs chan tea.Msg, content string) {
+	t.Helper()
+	client.SendMessage(content)()
+	msg := waitFor[phoenix.ConversationResponseMsg](t, msgs)
+
+	var response phoenix.ConversationMessage
+	if err := json.Unmarshal(msg.Response, &response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Response != "Mock response to: "+content {
+		t.Errorf("Unexpected response: %+v", response)
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	opts := DefaultOptions()
+	upstream := httptest.NewServer(New(opts))
+	defer upstream.Close()
+
+	path := filepath.Join(t.TempDir(), "conversation.jsonl")
+	proxy, err := NewProxy("ws"+strings.TrimPrefix(upstream.URL, "http"), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, msgs := connectTo(t, proxy, opts.APIKey)
+	expectResponse(t, client, msgs, "hello duck")
+	proxy.Close()
+
+	cassette, err := LoadCassette(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range cassette.Frames {
+		if strings.Contains(string(f.Frame), "heartbeat") || strings.Contains(f.Path, opts.APIKey) {
+			t.Errorf("Cassette recorded a heartbeat or credentials: %s %s", f.Path, f.Frame)
+		}
+	}
+
+	replayer := NewReplayer(cassette)
+	replayer.Speed = 0
+	client, msgs = connectTo(t, replayer, "any-key")
+	expectResponse(t, client, msgs, "hello duck")
+	if mismatches := replayer.Mismatches(); len(mismatches) > 0 {
+		t.Errorf("Unexpected mismatches: %v", mismatches)
+	}
+}
+
+func TestReplayReportsMismatch(t *testing.T) {
+	cassette, err := LoadCassette(filepath.Join("testdata", "cassettes", "conversation.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayer := NewReplayer(cassette)
+	replayer.Speed = 0
+	client, msgs := connectTo(t, replayer, "any-key")
+
+	// The recorded session sent "hello duck"; a different message is a protocol change
+	client.SendMessage("something else")()
+	waitFor[phoenix.ConversationResponseMsg](t, msgs)
+	if len(replayer.Mismatches()) != 1 {
+		t.Errorf("Expected one payload mismatch, got %v", replayer.Mismatches())
+	}
+}
+
+func TestReplayCassette(t *testing.T) {
+	cassette, err := LoadCassette(filepath.Join("testdata", "cassettes", "conversation.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayer := NewReplayer(cassette)
+	replayer.Speed = 0
+	client, msgs := connectTo(t, replayer, "any-key")
+	expectResponse(t, client, msgs, "hello duck")
+	if mismatches := replayer.Mismatches(); len(mismatches) > 0 {
+		t.Errorf("Unexpected mismatches: %v", mismatches)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	payload := map[string]any{
+		"username": "duck",
+		"password": "quack",
+		"user":     map[string]any{"token": "secret"},
+	}
+	redact(payload)
+	if payload["password"] != redacted || payload["user"].(map[string]any)["token"] != redacted || payload["username"] != "duck" {
+		t.Errorf("Unexpected redaction: %v", payload)
+	}
+	if !payloadMatches(map[string]any{"password": redacted}, map[string]any{"password": "anything"}) {
+		t.Error("Expected redacted values to match anything")
+	}
+}
@@ -0,0 +1,1061 @@
+package mockserver
+
+import (
+	"bufio"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// statusCategories are the categories offered by status channels
+var statusCategories = map[string]string{
+	"conversation": "Conversation processing",
+	"engine":       "Engine lifecycle and processing",
+	"error":        "Errors and warnings",
+	"info":         "General information",
+	"progress":     "Long-running task progress",
+	"tool":         "Tool execution",
+	"workflow":     "Workflow progress",
+}
+
+// joinResponse returns the join reply for topics this socket serves
+func (c *conn) joinResponse(topic string) (map[string]any, bool) {
+	if c.auth {
+		return map[string]any{}, topic == "auth:lobby"
+	}
+
+	switch {
+	case topic == "conversation:lobby":
+		c.server.mu.Lock()
+		c.server.conversations++
+		c.server.mu.Unlock()
+		return map[string]any{
+			"conversation_id":  c.server.newID("conversation"),
+			"server_version":   ServerVersion,
+			"protocol_version": phoenix.ProtocolVersion,
+			"capabilities":     c.server.capabilities(),
+		}, true
+	case topic == "api_keys:manage", topic == "planning:lobby", topic == "index:lobby":
+		return map[string]any{}, true
+	case topic == "admin:lobby":
+		// Only admins may join, like the real server's authorization check
+		return map[string]any{}, c.server.isAdmin()
+	case strings.HasPrefix(topic, "status:"):
+		categories := sortedCategories()
+		return map[string]any{
+			"conversation_id":       strings.TrimPrefix(topic, "status:"),
+			"available_categories":  categories,
+			"subscribed_categories": categories,
+			"category_descriptions": statusCategories,
+		}, true
+	}
+	return nil, false
+}
+
+// capabilities returns the features the server offers, sorted
+func (s *Server) capabilities() []string {
+	capabilities := s.opts.Capabilities
+	if capabilities == nil {
+		capabilities = append([]string{phoenix.CapabilityResume, phoenix.CapabilityFormat, phoenix.CapabilityPullRequests, phoenix.CapabilityReact, phoenix.CapabilityModels, phoenix.CapabilityProviderStatus, phoenix.CapabilityChunkedTransfer}, phoenix.ClientCapabilities...)
+	}
+	capabilities = append([]string(nil), capabilities...)
+	sort.Strings(capabilities)
+	return capabilities
+}
+
+// sortedCategories returns the status category names in a stable order
+func sortedCategories() []string {
+	categories := make([]string, 0, len(statusCategories))
+	for category := range statusCategories {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	return categories
+}
+
+// handleEvent answers a push on a joined topic
+func (c *conn) handleEvent(f frame) {
+	switch {
+	case f.Topic == "auth:lobby":
+		c.handleAuth(f)
+	case f.Topic == "conversation:lobby":
+		c.handleConversation(f)
+	case f.Topic == "api_keys:manage":
+		c.handleAPIKeys(f)
+	case f.Topic == "planning:lobby":
+		c.handlePlanning(f)
+	case f.Topic == "admin:lobby":
+		c.handleAdmin(f)
+	case f.Topic == "index:lobby":
+		c.handleIndex(f)
+	case strings.HasPrefix(f.Topic, "status:"):
+		c.handleStatus(f)
+	default:
+		c.reply(f, "error", map[string]any{"reason": "unknown topic"})
+	}
+}
+
+// handleAuth serves the auth:lobby channel
+func (c *conn) handleAuth(f frame) {
+	s := c.server
+	switch f.Event {
+	case "login":
+		c.reply(f, "ok", map[string]any{})
+		if user, ok := s.checkPassword(stringField(f.Payload, "username"), stringField(f.Payload, "password")); ok {
+			c.signIn(stringField(f.Payload, "client"), user.Username)
+			c.answer(f, "login_success", map[string]any{"user": user, "token": s.token})
+		} else {
+			c.answer(f, "login_error", map[string]any{"message": "Invalid credentials", "details": map[string]any{}})
+		}
+	case "authenticate_with_api_key":
+		c.reply(f, "ok", map[string]any{})
+		if s.useAPIKey(stringField(f.Payload, "api_key"), c.remote) {
+			c.signIn(stringField(f.Payload, "client"), s.user.Username)
+			c.answer(f, "authenticate_with_api_key_success", map[string]any{"user": s.user, "token": s.token})
+		} else if s.apiKeyRevoked(stringField(f.Payload, "api_key")) {
+			c.answer(f, "authenticate_with_api_key_error", map[string]any{"message": "API key revoked", "reason": "revoked", "details": map[string]any{}})
+		} else {
+			c.answer(f, "authenticate_with_api_key_error", map[string]any{"message": "Invalid API key", "details": map[string]any{}})
+		}
+	case "register":
+		c.reply(f, "ok", map[string]any{})
+		user, problem := s.register(stringField(f.Payload, "username"), stringField(f.Payload, "email"), stringField(f.Payload, "password"))
+		if problem != "" {
+			c.answer(f, "register_error", map[string]any{"message": problem})
+			return
+		}
+		c.signIn(stringField(f.Payload, "client"), user.Username)
+		c.answer(f, "register_success", map[string]any{"user": user, "token": s.token})
+	case "change_password":
+		c.reply(f, "ok", map[string]any{})
+		c.mu.Lock()
+		username := c.username
+		c.mu.Unlock()
+		if problem := s.changePassword(username, stringField(f.Payload, "current_password"), stringField(f.Payload, "new_password")); problem != "" {
+			c.answer(f, "password_change_error", map[string]any{"message": problem})
+		} else {
+			c.answer(f, "password_changed", map[string]any{"message": "Password changed"})
+		}
+	case "logout":
+		c.reply(f, "ok", map[string]any{})
+		c.answer(f, "logout_success", map[string]any{"message": "Logged out successfully"})
+	case "get_status":
+		c.reply(f, "ok", map[string]any{})
+		c.answer(f, "auth_status", map[string]any{"authenticated": true, "user": s.user, "authenticated_at": timestamp(time.Now())})
+	case "refresh_token":
+		c.reply(f, "ok", map[string]any{})
+		c.answer(f, "token_refreshed", map[string]any{"user": s.user, "token": s.token})
+	case "list_provider_credentials":
+		c.reply(f, "ok", map[string]any{})
+		c.answer(f, "provider_credentials", map[string]any{"credentials": s.credentialList()})
+	case "set_provider_credentials":
+		c.reply(f, "ok", map[string]any{})
+		provider := stringField(f.Payload, "provider")
+		fields, _ := f.Payload["credentials"].(map[string]any)
+		if provider == "" || stringField(fields, "api_key") == "" && provider != "ollama" {
+			c.answer(f, "provider_credentials_error", map[string]any{"operation": "set", "provider": provider, "message": "An API key is required"})
+			return
+		}
+		s.setCredentials(provider, fields)
+		c.answer(f, "provider_credentials_saved", map[string]any{"provider": provider, "message": "Credentials saved for " + provider})
+	case "remove_provider_credentials":
+		c.reply(f, "ok", map[string]any{})
+		provider := stringField(f.Payload, "provider")
+		if s.removeCredentials(provider) {
+			c.answer(f, "provider_credentials_removed", map[string]any{"provider": provider, "message": "Credentials removed for " + provider})
+		} else {
+			c.answer(f, "provider_credentials_error", map[string]any{"operation": "remove", "provider": provider, "message": "No credentials stored for " + provider})
+		}
+	case "test_provider_credentials":
+		c.reply(f, "ok", map[string]any{})
+		provider := stringField(f.Payload, "provider")
+		ok, message := s.pingProvider(provider)
+		c.answer(f, "provider_credentials_tested", map[string]any{"provider": provider, "ok": ok, "latency_ms": 120, "message": message})
+	case "list_sessions":
+		c.reply(f, "ok", map[string]any{})
+		c.answer(f, "session_list", map[string]any{"sessions": c.sessionList()})
+	case "revoke_session":
+		c.reply(f, "ok", map[string]any{})
+		id := stringField(f.Payload, "session_id")
+		if s.revokeSession(id) {
+			c.answer(f, "session_revoked", map[string]any{"session_id": id, "message": "Session signed out"})
+		} else {
+			c.answer(f, "session_error", map[string]any{"operation": "revoke", "message": "Session not found"})
+		}
+	default:
+		c.reply(f, "error", map[string]any{"reason": "unknown event " + f.Event})
+	}
+}
+
+// signIn starts a session for this connection
+func (c *conn) signIn(client, username string) {
+	id := c.server.startSession(client, c.remote)
+	c.mu.Lock()
+	c.session = id
+	c.username = username
+	c.mu.Unlock()
+}
+
+// checkPassword returns the account's user when the password matches
+func (s *Server) checkPassword(username, password string) (User, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if a, ok := s.accounts[username]; ok && a.Password == password {
+		return a.User, true
+	}
+	return User{}, false
+}
+
+// register creates an account, returning why it was refused if it was
+func (s *Server) register(username, email, password string) (User, string) {
+	switch {
+	case username == "" || email == "":
+		return User{}, "Username and email are required"
+	case len(password) < 8:
+		return User{}, "Password must be at least 8 characters"
+	}
+	id := s.newID("user")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, taken := s.accounts[username]; taken {
+		return User{}, "Username is already taken"
+	}
+	user := User{ID: id, Username: username, Email: email}
+	s.accounts[username] = &account{User: user, Password: password}
+	return user, ""
+}
+
+// changePassword replaces a signed-in account's password, returning why it
+// was refused if it was
+func (s *Server) changePassword(username, current, next string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.accounts[username]
+	switch {
+	case !ok:
+		return "Not logged in"
+	case a.Password != current:
+		return "Current password is incorrect"
+	case len(next) < 8:
+		return "Password must be at least 8 characters"
+	}
+	a.Password = next
+	return ""
+}
+
+// sessionList lists the active sessions, marking this connection's own
+func (c *conn) sessionList() []map[string]any {
+	c.mu.Lock()
+	current := c.session
+	c.mu.Unlock()
+
+	s := c.server
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sessions := []map[string]any{}
+	for _, session := range s.sessions {
+		if session.Revoked {
+			continue
+		}
+		sessions = append(sessions, map[string]any{
+			"id":           session.ID,
+			"client":       session.Client,
+			"ip":           session.IP,
+			"created_at":   timestamp(session.CreatedAt),
+			"last_seen_at": timestamp(session.LastSeenAt),
+			"current":      session.ID == current,
+		})
+	}
+	return sessions
+}
+
+// credentialList returns the stored provider credentials, masked
+func (s *Server) credentialList() []map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	credentials := []map[string]any{}
+	providers := make([]string, 0, len(s.credentials))
+	for provider := range s.credentials {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+	for _, provider := range providers {
+		masked := map[string]any{}
+		for field, value := range s.credentials[provider] {
+			masked[field] = maskCredential(value)
+		}
+		credentials = append(credentials, map[string]any{"provider": provider, "fields": masked, "updated_at": timestamp(time.Now())})
+	}
+	return credentials
+}
+
+// maskCredential hides all but the ends of a secret
+func maskCredential(value string) string {
+	if len(value) <= 10 {
+		return "••••"
+	}
+	return value[:3] + "…" + value[len(value)-4:]
+}
+
+// setCredentials stores a provider's credentials, replacing any it had
+func (s *Server) setCredentials(provider string, fields map[string]any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.credentials == nil {
+		s.credentials = make(map[string]map[string]string)
+	}
+	stored := make(map[string]string)
+	for field, value := range fields {
+		if text, ok := value.(string); ok && text != "" {
+			stored[field] = text
+		}
+	}
+	s.credentials[provider] = stored
+}
+
+// removeCredentials deletes a provider's credentials, reporting whether it had any
+func (s *Server) removeCredentials(provider string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.credentials[provider]; !ok {
+		return false
+	}
+	delete(s.credentials, provider)
+	return true
+}
+
+// pingProvider pretends to reach a provider with its stored credentials
+func (s *Server) pingProvider(provider string) (bool, string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.credentials[provider]; !ok {
+		return false, "No credentials stored for " + provider
+	}
+	if s.disabled[provider] {
+		return false, provider + " is disabled on this server"
+	}
+	return true, provider + " answered the ping"
+}
+
+// revokeSession signs out a session, reporting whether it was active
+func (s *Server) revokeSession(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.sessions {
+		if s.sessions[i].ID == id && !s.sessions[i].Revoked {
+			s.sessions[i].Revoked = true
+			return true
+		}
+	}
+	return false
+}
+
+// handleConversation serves the conversation:lobby channel
+func (c *conn) handleConversation(f frame) {
+	switch f.Event {
+	case "message":
+		c.reply(f, "ok", map[string]any{})
+		go c.respond(f)
+	case "cancel_processing":
+		c.reply(f, "ok", map[string]any{})
+		c.answer(f, "processing_cancelled", map[string]any{})
+	case "new_conversation":
+		c.reply(f, "ok", map[string]any{})
+		c.answer(f, "conversation_reset", map[string]any{"conversation_id": c.server.newID("conversation")})
+	case "get_history":
+		limit, _ := f.Payload["limit"].(float64)
+		before := stringField(f.Payload, "before")
+		messages, cursor := c.server.historyPage(int(limit), before)
+		c.reply(f, "ok", map[string]any{})
+		c.answer(f, "history", map[string]any{"conversation_id": "", "messages": messages, "count": len(messages), "before": before, "next_cursor": cursor})
+	case "set_context":
+		c.reply(f, "ok", map[string]any{})
+		c.answer(f, "context_updated", map[string]any{"context": f.Payload["context"], "timestamp": timestamp(time.Now())})
+	case "list_files":
+		files, err := c.server.listFiles(stringField(f.Payload, "path"))
+		if err != nil {
+			c.reply(f, "error", map[string]any{"reason": err.Error()})
+			return
+		}
+		c.reply(f, "ok", map[string]any{"files": files})
+	case "read_file":
+		content, err := c.server.readFile(stringField(f.Payload, "path"))
+		if err != nil {
+			c.reply(f, "error", map[string]any{"reason": err.Error()})
+			return
+		}
+		if chunkedRead(content) {
+			c.reply(f, "ok", map[string]any{"chunked": true, "size": len(content)})
+			return
+		}
+		c.reply(f, "ok", map[string]any{"content": content})
+	case "write_file":
+		if err := c.server.writeFile(stringField(f.Payload, "path"), stringField(f.Payload, "content")); err != nil {
+			c.reply(f, "error", map[string]any{"reason": err.Error()})
+			return
+		}
+		c.reply(f, "ok", map[string]any{})
+	case "upload_begin":
+		size, _ := f.Payload["size"].(float64)
+		id, offset, err := c.server.beginUpload(stringField(f.Payload, "path"), int(size), stringField(f.Payload, "sha256"))
+		if err != nil {
+			c.reply(f, "error", map[string]any{"reason": err.Error()})
+			return
+		}
+		c.reply(f, "ok", map[string]any{"upload_id": id, "offset": offset})
+	case "upload_chunk":
+		offset, _ := f.Payload["offset"].(float64)
+		next, err := c.server.uploadChunk(stringField(f.Payload, "upload_id"), int(offset), stringField(f.Payload, "data"))
+		if err != nil {
+			c.reply(f, "error", map[string]any{"reason": err.Error()})
+			return
+		}
+		c.reply(f, "ok", map[string]any{"offset": next})
+	case "upload_commit":
+		if err := c.server.commitUpload(stringField(f.Payload, "upload_id")); err != nil {
+			c.reply(f, "error", map[string]any{"reason": err.Error()})
+			return
+		}
+		c.reply(f, "ok", map[string]any{})
+	case "download_begin":
+		response, err := c.server.beginDownload(stringField(f.Payload, "path"))
+		if err != nil {
+			c.reply(f, "error", map[string]any{"reason": err.Error()})
+			return
+		}
+		c.reply(f, "ok", response)
+	case "download_chunk":
+		offset, _ := f.Payload["offset"].(float64)
+		length, _ := f.Payload["length"].(float64)
+		data, err := c.server.downloadChunk(stringField(f.Payload, "download_id"), int(offset), int(length))
+		if err != nil {
+			c.reply(f, "error", map[string]any{"reason": err.Error()})
+			return
+		}
+		c.reply(f, "ok", map[string]any{"data": data})
+	case "format_code":
+		content, err := formatCode(stringField(f.Payload, "path"), stringField(f.Payload, "content"))
+		if err != nil {
+			c.reply(f, "error", map[string]any{"reason": err.Error()})
+			return
+		}
+		c.reply(f, "ok", map[string]any{"content": content})
+	case "react_control":
+		c.reply(f, "ok", map[string]any{})
+		execution := stringField(f.Payload, "execution_id")
+		state := map[string]string{"pause": "paused", "resume": "running", "abort": "aborted"}[stringField(f.Payload, "action")]
+		if state == "aborted" {
+			c.mu.Lock()
+			if c.aborted == nil {
+				c.aborted = make(map[string]bool)
+			}
+			c.aborted[execution] = true
+			c.mu.Unlock()
+		}
+		c.answer(f, "react:state", map[string]any{"execution_id": execution, "state": state})
+	case "create_pull_request":
+		id := c.server.newID("pr")
+		c.reply(f, "ok", map[string]any{"branch": "rubber-duck/" + id, "url": "http://localhost/pulls/" + id})
+	case "list_models":
+		c.reply(f, "ok", map[string]any{"models": mockModels})
+	case "get_provider_status":
+		c.reply(f, "ok", c.server.providerStatus(stringField(f.Payload, "provider")))
+	case "search_code":
+		c.reply(f, "ok", map[string]any{"matches": c.server.searchCode(stringField(f.Payload, "query"))})
+	case "set_tags":
+		c.reply(f, "ok", map[string]any{})
+	case "archive_conversation":
+		c.reply(f, "ok", map[string]any{})
+		c.answer(f, "conversation_archived", map[string]any{"conversation_id": f.Payload["conversation_id"], "archived": f.Payload["archived"]})
+	case "resume_conversation":
+		c.reply(f, "ok", map[string]any{})
+		c.answer(f, "conversation_resumed", map[string]any{"conversation_id": stringField(f.Payload, "conversation_id")})
+	case "delete_conversation":
+		c.reply(f, "ok", map[string]any{})
+		c.answer(f, "conversation_deleted", map[string]any{"conversation_id": f.Payload["conversation_id"]})
+	default:
+		c.reply(f, "error", map[string]any{"reason": "unknown event " + f.Event})
+	}
+}
+
+// historyPage returns up to limit canned history messages older than the
+// before cursor, oldest first, and the cursor for the page before them
+func (s *Server) historyPage(limit int, before string) ([]map[string]any, string) {
+	end := s.opts.History
+	if n, err := strconv.Atoi(before); err == nil && n < end {
+		end = n
+	}
+	if limit <= 0 {
+		limit = end
+	}
+	start := max(end-limit, 0)
+
+	messages := make([]map[string]any, 0, end-start)
+	for i := start; i < end; i++ {
+		role := "user"
+		if i%2 == 1 {
+			role = "assistant"
+		}
+		messages = append(messages, map[string]any{
+			"id":        fmt.Sprintf("history-%d", i),
+			"role":      role,
+			"content":   fmt.Sprintf("History message %d", i+1),
+			"timestamp": timestamp(s.started.Add(time.Duration(i-s.opts.History) * time.Hour)),
+		})
+	}
+	cursor := ""
+	if start > 0 {
+		cursor = strconv.Itoa(start)
+	}
+	return messages, cursor
+}
+
+// respond answers a conversation message, streaming when configured, and
+// reports progress to joined status channels. Stream frames are spaced by
+// ChunkDelay since the client handles events concurrently
+func (c *conn) respond(f frame) {
+	content := stringField(f.Payload, "content")
+	answer := "Mock response to: " + content
+
+	c.answer(f, "thinking", map[string]any{"stage": "processing", "queue_position": 0})
+	c.pushStatus("engine", "Processing message")
+
+	if strings.HasPrefix(content, "react:") {
+		c.react(f, strings.TrimSpace(strings.TrimPrefix(content, "react:")))
+		return
+	}
+
+	c.mu.Lock()
+	stream := c.server.opts.Stream && c.streaming
+	c.mu.Unlock()
+
+	if stream {
+		id := c.server.newID("stream")
+		c.answer(f, "stream:start", map[string]any{"id": id})
+		for _, chunk := range strings.SplitAfter(answer, " ") {
+			time.Sleep(c.server.opts.ChunkDelay)
+			c.answer(f, "stream:data", map[string]any{"id": id, "chunk": chunk})
+		}
+		time.Sleep(c.server.opts.ChunkDelay)
+		c.answer(f, "stream:end", map[string]any{"id": id})
+	} else {
+		response := map[string]any{
+			"query":             content,
+			"response":          answer,
+			"conversation_type": "simple",
+			"timestamp":         timestamp(time.Now()),
+		}
+		if sources := c.server.retrieve(content); len(sources) > 0 {
+			response["metadata"] = map[string]any{"sources": sources}
+		}
+		c.answer(f, "response", response)
+	}
+
+	c.pushStatus("engine", "Response complete")
+}
+
+// react runs a canned two-cycle ReAct execution for messages starting with
+// "react:", spacing phases by ChunkDelay and stopping when aborted
+func (c *conn) react(f frame, query string) {
+	id := c.server.newID("react")
+	cycles := [][3]string{
+		{"I should look for files related to " + query, "search_code", "3 matches in lib/"},
+		{"The first match looks relevant; read it", "read_file", "defmodule Example do ... end"},
+	}
+	for i, cycle := range cycles {
+		for phase, name := range []string{"thought", "action", "observation"} {
+			time.Sleep(c.server.opts.ChunkDelay)
+			c.mu.Lock()
+			aborted := c.aborted[id]
+			c.mu.Unlock()
+			if aborted {
+				return
+			}
+			step := map[string]any{"execution_id": id, "cycle": i + 1, "phase": name, "content": cycle[phase]}
+			if name == "action" {
+				step["tool"] = cycle[1]
+				step["content"] = cycle[1] + "(" + query + ")"
+			}
+			if name == "observation" {
+				step["duration_ms"] = 40
+			}
+			c.answer(f, "react:step", step)
+		}
+	}
+	answer := "Mock ReAct answer to: " + query
+	c.answer(f, "react:state", map[string]any{"execution_id": id, "state": "completed", "answer": answer})
+	c.answer(f, "response", map[string]any{
+		"query":             query,
+		"response":          answer,
+		"conversation_type": "simple",
+		"timestamp":         timestamp(time.Now()),
+	})
+}
+
+// pushStatus sends a status update to every joined status channel
+func (c *conn) pushStatus(category, text string) {
+	for _, topic := range c.joinedTopics("status:") {
+		c.push(topic, "status_update", map[string]any{
+			"category":  category,
+			"text":      text,
+			"metadata":  map[string]any{},
+			"timestamp": timestamp(time.Now()),
+		})
+	}
+}
+
+// handleStatus serves status:<conversation_id> channels
+func (c *conn) handleStatus(f frame) {
+	switch f.Event {
+	case "subscribe_categories", "unsubscribe_categories", "get_subscriptions":
+		c.reply(f, "ok", map[string]any{
+			"subscribed_categories": sortedCategories(),
+			"available_categories":  sortedCategories(),
+		})
+	default:
+		c.reply(f, "error", map[string]any{"reason": "unknown event " + f.Event})
+	}
+}
+
+// handleAPIKeys serves the api_keys:manage channel
+func (c *conn) handleAPIKeys(f frame) {
+	s := c.server
+	switch f.Event {
+	case "generate_api_key":
+		now := time.Now().UTC()
+		days := 365
+		if n, ok := f.Payload["expires_in_days"].(float64); ok && n > 0 {
+			days = int(n)
+		}
+		var scopes []string
+		if list, ok := f.Payload["scopes"].([]any); ok {
+			for _, scope := range list {
+				if name, ok := scope.(string); ok {
+					scopes = append(scopes, name)
+				}
+			}
+		}
+		key := apiKey{ID: s.newID("key"), Key: s.newID("mock-key"), CreatedAt: now, ExpiresAt: now.AddDate(0, 0, days), CreatedFrom: stringField(f.Payload, "client"), Name: stringField(f.Payload, "name"), Scopes: scopes}
+		s.mu.Lock()
+		s.apiKeys = append(s.apiKeys, key)
+		s.mu.Unlock()
+
+		c.reply(f, "ok", map[string]any{})
+		generated := map[string]any{
+			"id":         key.ID,
+			"key":        key.Key,
+			"created_at": timestamp(key.CreatedAt),
+			"expires_at": timestamp(key.ExpiresAt),
+		}
+		if key.Name != "" {
+			generated["name"] = key.Name
+		}
+		if len(key.Scopes) > 0 {
+			generated["scopes"] = key.Scopes
+		}
+		c.answer(f, "api_key_generated", map[string]any{
+			"api_key": generated,
+			"warning": "Store this key securely. It will not be shown again.",
+		})
+	case "list_api_keys":
+		s.mu.Lock()
+		keys := make([]map[string]any, 0, len(s.apiKeys))
+		for _, key := range s.apiKeys {
+			fields := map[string]any{
+				"id":         key.ID,
+				"valid":      !key.Revoked,
+				"created_at": timestamp(key.CreatedAt),
+				"expires_at": timestamp(key.ExpiresAt),
+			}
+			if key.CreatedFrom != "" {
+				fields["created_from"] = key.CreatedFrom
+			}
+			if key.Name != "" {
+				fields["name"] = key.Name
+			}
+			if len(key.Scopes) > 0 {
+				fields["scopes"] = key.Scopes
+			}
+			if !key.LastUsedAt.IsZero() {
+				fields["last_used_at"] = timestamp(key.LastUsedAt)
+				fields["last_ip"] = key.LastIP
+			}
+			keys = append(keys, fields)
+		}
+		s.mu.Unlock()
+
+		c.reply(f, "ok", map[string]any{})
+		c.answer(f, "api_key_list", map[string]any{"api_keys": keys})
+	case "get_api_key_usage":
+		s.mu.Lock()
+		usage := make([]map[string]any, 0, len(s.apiKeys))
+		for _, key := range s.apiKeys {
+			fields := map[string]any{"api_key_id": key.ID, "request_count": key.Requests}
+			if !key.LastUsedAt.IsZero() {
+				fields["last_used_at"] = timestamp(key.LastUsedAt)
+				fields["last_ip"] = key.LastIP
+			}
+			usage = append(usage, fields)
+		}
+		s.mu.Unlock()
+
+		c.reply(f, "ok", map[string]any{})
+		c.answer(f, "api_key_usage", map[string]any{"usage": usage})
+	case "revoke_api_key":
+		id := stringField(f.Payload, "api_key_id")
+		revoked := false
+		s.mu.Lock()
+		for i := range s.apiKeys {
+			if s.apiKeys[i].ID == id {
+				s.apiKeys[i].Revoked = true
+				revoked = true
+			}
+		}
+		s.mu.Unlock()
+
+		c.reply(f, "ok", map[string]any{})
+		if revoked {
+			c.answer(f, "api_key_revoked", map[string]any{"message": "API key revoked"})
+		} else {
+			c.answer(f, "api_key_error", map[string]any{"operation": "revoke", "message": "API key not found", "details": map[string]any{}})
+		}
+	default:
+		c.reply(f, "error", map[string]any{"reason": "unknown event " + f.Event})
+	}
+}
+
+// handlePlanning serves the planning:lobby channel with a two-step plan
+func (c *conn) handlePlanning(f frame) {
+	switch f.Event {
+	case "start_planning":
+		c.reply(f, "ok", map[string]any{})
+		query := stringField(f.Payload, "query")
+		steps := []map[string]any{
+			{"step_id": "1", "type": "analysis", "description": "Analyze: " + query, "details": map[string]any{}},
+			{"step_id": "2", "type": "implementation", "description": "Implement the change", "details": map[string]any{}},
+		}
+		c.answer(f, "planning_started", map[string]any{"session_id": c.server.newID("plan")})
+		for _, step := range steps {
+			c.answer(f, "planning_step", step)
+		}
+		c.answer(f, "planning_completed", map[string]any{
+			"summary": "Plan for: " + query,
+			"steps":   []map[string]any{{"description": steps[0]["description"]}, {"description": steps[1]["description"]}},
+		})
+	case "cancel_planning":
+		c.reply(f, "ok", map[string]any{})
+		c.answer(f, "planning_cancelled", map[string]any{})
+	case "planning_feedback":
+		c.reply(f, "ok", map[string]any{})
+	default:
+		c.reply(f, "error", map[string]any{"reason": "unknown event " + f.Event})
+	}
+}
+
+// maxIndexedFiles caps the files build_index indexes
+const maxIndexedFiles = 2000
+
+// handleIndex serves the index:lobby channel, indexing the files under Root
+func (c *conn) handleIndex(f frame) {
+	switch f.Event {
+	case "build_index":
+		c.reply(f, "ok", map[string]any{})
+		go c.buildIndex(f)
+	case "get_index_status":
+		c.reply(f, "ok", map[string]any{})
+		c.answer(f, "index_status", c.server.indexStatus())
+	default:
+		c.reply(f, "error", map[string]any{"reason": "unknown event " + f.Event})
+	}
+}
+
+// buildIndex walks Root, reporting progress about every quarter of the files
+func (c *conn) buildIndex(f frame) {
+	s := c.server
+	root, err := s.resolve("")
+	if err != nil {
+		c.answer(f, "index_error", map[string]any{"operation": "build_index", "message": err.Error()})
+		return
+	}
+	var files []string
+	filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil || len(files) >= maxIndexedFiles {
+			return filepath.SkipDir
+		}
+		if d.IsDir() {
+			if path != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, _ := filepath.Rel(root, path)
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+
+	c.answer(f, "index_progress", map[string]any{"phase": "scanning", "files_done": 0, "files_total": len(files)})
+	step := max(len(files)/4, 1)
+	for i, file := range files {
+		if (i+1)%step == 0 || i == len(files)-1 {
+			time.Sleep(s.opts.ChunkDelay)
+			c.answer(f, "index_progress", map[string]any{"phase": "embedding", "files_done": i + 1, "files_total": len(files), "current": file})
+		}
+	}
+
+	s.mu.Lock()
+	s.indexed = files
+	s.indexedAt = time.Now()
+	s.mu.Unlock()
+	c.answer(f, "index_status", s.indexStatus())
+}
+
+// indexStatus reports the files indexed by the last build
+func (s *Server) indexStatus() map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.indexed == nil {
+		return map[string]any{"state": phoenix.IndexNone, "files": 0, "chunks": 0}
+	}
+	return map[string]any{"state": phoenix.IndexReady, "files": len(s.indexed), "chunks": len(s.indexed) * 3, "indexed_at": timestamp(s.indexedAt)}
+}
+
+// retrieve returns up to three indexed files whose name appears in the query
+func (s *Server) retrieve(query string) []map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	query = strings.ToLower(query)
+	sources := []map[string]any{}
+	for _, file := range s.indexed {
+		name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		if len(name) > 2 && strings.Contains(query, strings.ToLower(name)) && len(sources) < 3 {
+			sources = append(sources, map[string]any{"path": file, "start_line": 1, "end_line": 20, "score": 0.8})
+		}
+	}
+	return sources
+}
+
+// mockProviders are the providers the admin channel reports
+var mockProviders = []string{"anthropic", "ollama", "openai"}
+
+// mockModels are the models list_models reports, by provider
+var mockModels = []map[string]any{
+	{"provider": "openai", "name": "gpt-4", "context_window": 8192},
+	{"provider": "openai", "name": "gpt-4o", "context_window": 128000},
+	{"provider": "openai", "name": "gpt-3.5-turbo", "context_window": 16385},
+	{"provider": "anthropic", "name": "claude-3-opus", "context_window": 200000},
+	{"provider": "anthropic", "name": "claude-3-sonnet", "context_window": 200000},
+	{"provider": "anthropic", "name": "claude-3-haiku", "context_window": 200000},
+	{"provider": "ollama", "name": "llama3", "context_window": 8192},
+	{"provider": "ollama", "name": "codellama", "context_window": 16384},
+}
+
+// providerStatus reports a provider as healthy unless the options say otherwise
+func (s *Server) providerStatus(provider string) map[string]any {
+	status := map[string]any{"provider": provider, "status": phoenix.ProviderHealthy, "circuit_state": "closed", "latency_ms": 420.0, "error_rate": 0.0}
+	switch s.opts.ProviderStatus[strings.ToLower(provider)] {
+	case phoenix.ProviderDegraded:
+		status["status"] = phoenix.ProviderDegraded
+		status["reason"] = "elevated error rate"
+		status["circuit_state"] = "half_open"
+		status["latency_ms"] = 3800.0
+		status["error_rate"] = 0.35
+	case phoenix.ProviderUnavailable:
+		status["status"] = phoenix.ProviderUnavailable
+		status["reason"] = "circuit breaker open"
+		status["circuit_state"] = "open"
+		status["error_rate"] = 1.0
+	}
+	return status
+}
+
+// isAdmin reports whether the mock user may use the admin channel
+func (s *Server) isAdmin() bool {
+	grants := phoenix.AuthUser{Roles: s.opts.Roles, Permissions: s.opts.Permissions}.Grants()
+	return grants != nil && grants.Has(phoenix.PermissionAdmin)
+}
+
+// handleAdmin serves the admin:lobby channel
+func (c *conn) handleAdmin(f frame) {
+	s := c.server
+	switch f.Event {
+	case "get_stats":
+		c.reply(f, "ok", map[string]any{})
+		c.answer(f, "admin_stats", s.adminStats())
+	case "flush_caches":
+		c.reply(f, "ok", map[string]any{})
+		c.answer(f, "maintenance_result", map[string]any{"action": "flush_caches", "message": "Caches flushed"})
+	case "toggle_provider":
+		provider := stringField(f.Payload, "provider")
+		enabled, _ := f.Payload["enabled"].(bool)
+		known := false
+		for _, name := range mockProviders {
+			known = known || name == provider
+		}
+		c.reply(f, "ok", map[string]any{})
+		if !known {
+			c.answer(f, "admin_error", map[string]any{"operation": "toggle_provider", "message": "Unknown provider " + provider})
+			return
+		}
+		s.mu.Lock()
+		s.disabled[provider] = !enabled
+		s.mu.Unlock()
+		state := "enabled"
+		if !enabled {
+			state = "disabled"
+		}
+		c.answer(f, "maintenance_result", map[string]any{"action": "toggle_provider", "message": provider + " " + state})
+	default:
+		c.reply(f, "error", map[string]any{"reason": "unknown event " + f.Event})
+	}
+}
+
+// adminStats reports uptime, conversation and session counts, and canned
+// provider health
+func (s *Server) adminStats() map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	users := 0
+	for _, session := range s.sessions {
+		if !session.Revoked {
+			users++
+		}
+	}
+	providers := make([]map[string]any, 0, len(mockProviders))
+	for i, name := range mockProviders {
+		providers = append(providers, map[string]any{
+			"name":       name,
+			"enabled":    !s.disabled[name],
+			"healthy":    true,
+			"latency_ms": 120 * (i + 1),
+			"error_rate": 0.01 * float64(i),
+		})
+	}
+	return map[string]any{
+		"uptime_seconds":       int(time.Since(s.started).Seconds()),
+		"active_conversations": s.conversations,
+		"connected_users":      users,
+		"metrics":              map[string]any{"api_keys": len(s.apiKeys)},
+		"providers":            providers,
+	}
+}
+
+// resolve maps a client path into Root, rejecting paths that escape it
+func (s *Server) resolve(path string) (string, error) {
+	root, err := filepath.Abs(s.opts.Root)
+	if err != nil {
+		return "", err
+	}
+	full := filepath.Join(root, filepath.FromSlash(path))
+	if full != root && !strings.HasPrefix(full, root+string(filepath.Separator)) {
+		return "", os.ErrPermission
+	}
+	return full, nil
+}
+
+// listFiles lists a directory under Root
+func (s *Server) listFiles(path string) ([]map[string]any, error) {
+	dir, err := s.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]map[string]any, 0, len(entries))
+	for _, entry := range entries {
+		files = append(files, map[string]any{
+			"name":   entry.Name(),
+			"path":   filepath.ToSlash(filepath.Join(path, entry.Name())),
+			"is_dir": entry.IsDir(),
+		})
+	}
+	return files, nil
+}
+
+// readFile reads a file under Root
+func (s *Server) readFile(path string) (string, error) {
+	full, err := s.resolve(path)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(full)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// writeFile writes a file under Root
+func (s *Server) writeFile(path, content string) error {
+	full, err := s.resolve(path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(full, []byte(content), 0644)
+}
+
+// formatCode formats Go source; other languages are returned unchanged
+func formatCode(path, content string) (string, error) {
+	if filepath.Ext(path) != ".go" {
+		return content, nil
+	}
+	formatted, err := format.Source([]byte(content))
+	if err != nil {
+		return "", err
+	}
+	return string(formatted), nil
+}
+
+// maxSearchMatches caps search_code results
+const maxSearchMatches = 100
+
+// searchCode finds lines under Root containing query
+func (s *Server) searchCode(query string) []map[string]any {
+	matches := []map[string]any{}
+	root, err := s.resolve("")
+	if query == "" || err != nil {
+		return matches
+	}
+
+	filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil || len(matches) >= maxSearchMatches {
+			return filepath.SkipDir
+		}
+		if d.IsDir() {
+			if path != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return nil
+		}
+		defer file.Close()
+
+		rel, _ := filepath.Rel(root, path)
+		scanner := bufio.NewScanner(file)
+		for line := 1; scanner.Scan() && len(matches) < maxSearchMatches; line++ {
+			if strings.Contains(scanner.Text(), query) {
+				matches = append(matches, map[string]any{"path": filepath.ToSlash(rel), "line": line, "text": scanner.Text()})
+			}
+		}
+		return nil
+	})
+	return matches
+}
+
+// stringField returns a string payload field, or "" when absent
+func stringField(payload map[string]any, key string) string {
+	s, _ := payload[key].(string)
+	return s
+}
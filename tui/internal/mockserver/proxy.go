@@ -0,0 +1,92 @@
+package mockserver
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Proxy forwards websocket traffic to an upstream Phoenix server and records
+// every frame to a cassette
+type Proxy struct {
+	upstream *url.URL
+	cassette *cassetteWriter
+	upgrader websocket.Upgrader
+	dialer   websocket.Dialer
+}
+
+// NewProxy creates a recording proxy for the upstream server, e.g.
+// ws://localhost:5555, writing frames to the cassette at path
+func NewProxy(upstream, path string) (*Proxy, error) {
+	target, err := url.Parse(upstream)
+	if err != nil {
+		return nil, err
+	}
+	cassette, err := newCassetteWriter(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Proxy{
+		upstream: target,
+		cassette: cassette,
+		upgrader: websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }},
+	}, nil
+}
+
+// Close finishes the cassette
+func (p *Proxy) Close() error {
+	return p.cassette.Close()
+}
+
+// ServeHTTP dials the upstream socket at the same path and query, then
+// relays frames both ways until either side closes
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	target := *p.upstream
+	target.Path = strings.TrimSuffix(target.Path, "/") + r.URL.Path
+	target.RawQuery = r.URL.RawQuery
+
+	upstream, resp, err := p.dialer.Dial(target.String(), nil)
+	if err != nil {
+		// Pass handshake rejections such as a bad API key through to the client
+		status := http.StatusBadGateway
+		if resp != nil {
+			status = resp.StatusCode
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	defer upstream.Close()
+
+	client, err := p.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer client.Close()
+
+	// The query holds credentials, so only the path is recorded
+	conn := p.cassette.nextConn()
+	opened := time.Now()
+	var once sync.Once
+	done := make(chan struct{})
+	relay := func(from, to *websocket.Conn, direction string) {
+		defer once.Do(func() { close(done) })
+		for {
+			kind, data, err := from.ReadMessage()
+			if err != nil {
+				return
+			}
+			p.cassette.write(conn, r.URL.Path, direction, time.Since(opened), data)
+			if err := to.WriteMessage(kind, data); err != nil {
+				return
+			}
+		}
+	}
+
+	go relay(client, upstream, FromClient)
+	go relay(upstream, client, FromServer)
+	<-done
+}
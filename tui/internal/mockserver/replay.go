@@ -0,0 +1,215 @@
+package mockserver
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// Replayer serves a cassette in place of the server. Each connection replays
+// the next recorded connection on the same path: client frames are checked
+// against the recording and answered with the server frames that followed,
+// with refs and request IDs rewritten to the live client's
+type Replayer struct {
+	cassette *Cassette
+	upgrader websocket.Upgrader
+
+	// Speed scales recorded gaps between server frames; 0 sends them at once
+	Speed float64
+
+	mu         sync.Mutex
+	used       map[int]bool
+	mismatches []string
+}
+
+// NewReplayer creates a replayer for the cassette at recorded speed
+func NewReplayer(cassette *Cassette) *Replayer {
+	return &Replayer{
+		cassette: cassette,
+		upgrader: websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }},
+		Speed:    1,
+		used:     make(map[int]bool),
+	}
+}
+
+// Mismatches returns client frames that differed from the recording
+func (p *Replayer) Mismatches() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.mismatches...)
+}
+
+// mismatch records a divergence from the cassette
+func (p *Replayer) mismatch(format string, args ...any) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.mismatches = append(p.mismatches, fmt.Sprintf(format, args...))
+}
+
+// ServeHTTP replays the next unused recorded connection for the path
+func (p *Replayer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	frames := p.claim(r.URL.Path)
+	if frames == nil {
+		p.mismatch("unexpected connection to %s", r.URL.Path)
+		http.NotFound(w, r)
+		return
+	}
+
+	ws, err := p.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer ws.Close()
+
+	rc := &replayConn{replayer: p, ws: ws, frames: frames, refs: make(map[any]any), requests: make(map[string]string)}
+	rc.serve()
+}
+
+// claim returns the frames of the first unused connection on path
+func (p *Replayer) claim(path string) []CassetteFrame {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	conn := 0
+	var frames []CassetteFrame
+	for _, f := range p.cassette.Frames {
+		if conn == 0 && f.Path == path && !p.used[f.Conn] {
+			conn = f.Conn
+			p.used[conn] = true
+		}
+		if conn != 0 && f.Conn == conn {
+			frames = append(frames, f)
+		}
+	}
+	return frames
+}
+
+// replayConn plays one recorded connection
+type replayConn struct {
+	replayer *Replayer
+	ws       *websocket.Conn
+	frames   []CassetteFrame
+	next     int
+	last     time.Duration
+	refs     map[any]any       // recorded ref -> live ref
+	requests map[string]string // recorded request ID -> live request ID
+}
+
+// serve sends the frames recorded before the first client frame, then
+// answers client frames until the connection closes
+func (rc *replayConn) serve() {
+	rc.flush()
+	for {
+		_, data, err := rc.ws.ReadMessage()
+		if err != nil {
+			return
+		}
+		live, err := decodeFrame(data)
+		if err != nil {
+			rc.replayer.mismatch("bad client frame: %s", data)
+			continue
+		}
+		if live.isHeartbeat() {
+			rc.send(frame{JoinRef: live.JoinRef, Ref: live.Ref, Topic: live.Topic, Event: "phx_reply", Payload: map[string]any{"status": "ok", "response": map[string]any{}}})
+			continue
+		}
+		rc.receive(live)
+	}
+}
+
+// receive matches a client frame against the recording and plays the reply
+func (rc *replayConn) receive(live frame) {
+	if rc.next >= len(rc.frames) {
+		rc.replayer.mismatch("unexpected %s %s after the end of the recording", live.Topic, live.Event)
+		rc.refuse(live)
+		return
+	}
+
+	recorded, err := decodeFrame(rc.frames[rc.next].Frame)
+	if err != nil || recorded.Topic != live.Topic || recorded.Event != live.Event {
+		rc.replayer.mismatch("expected %s %s, got %s %s", recorded.Topic, recorded.Event, live.Topic, live.Event)
+		rc.refuse(live)
+		return
+	}
+	// Request IDs differ on every run; map them like refs
+	recordedID := stringField(recorded.Payload, phoenix.RequestIDField)
+	liveID := stringField(live.Payload, phoenix.RequestIDField)
+	if recordedID != "" && liveID != "" {
+		rc.requests[recordedID] = liveID
+		live.Payload[phoenix.RequestIDField] = recordedID
+	}
+	if !payloadMatches(recorded.Payload, live.Payload) {
+		rc.replayer.mismatch("%s %s payload %v does not match recorded %v", live.Topic, live.Event, live.Payload, recorded.Payload)
+	}
+
+	rc.mapRef(recorded.JoinRef, live.JoinRef)
+	rc.mapRef(recorded.Ref, live.Ref)
+	rc.last = rc.frames[rc.next].At
+	rc.next++
+	rc.flush()
+}
+
+// refuse answers an unexpected push with an error reply
+func (rc *replayConn) refuse(live frame) {
+	rc.send(frame{JoinRef: live.JoinRef, Ref: live.Ref, Topic: live.Topic, Event: "phx_reply", Payload: map[string]any{
+		"status":   "error",
+		"response": map[string]any{"reason": "not in cassette"},
+	}})
+}
+
+// mapRef remembers which live ref stands for a recorded one
+func (rc *replayConn) mapRef(recorded, live any) {
+	if recorded != nil {
+		rc.refs[recorded] = live
+	}
+}
+
+// rewrite maps a recorded ref to the live client's
+func (rc *replayConn) rewrite(ref any) any {
+	if live, ok := rc.refs[ref]; ok {
+		return live
+	}
+	return ref
+}
+
+// rewriteRequestID maps a recorded request ID in a payload to the live one
+func (rc *replayConn) rewriteRequestID(payload map[string]any) {
+	if live, ok := rc.requests[stringField(payload, phoenix.RequestIDField)]; ok {
+		payload[phoenix.RequestIDField] = live
+	}
+}
+
+// flush sends server frames up to the next client frame, keeping the
+// recorded gaps between them
+func (rc *replayConn) flush() {
+	for ; rc.next < len(rc.frames) && rc.frames[rc.next].From == FromServer; rc.next++ {
+		recorded := rc.frames[rc.next]
+		if gap := recorded.At - rc.last; gap > 0 && rc.replayer.Speed > 0 {
+			time.Sleep(time.Duration(float64(gap) / rc.replayer.Speed))
+		}
+		rc.last = recorded.At
+
+		f, err := decodeFrame(recorded.Frame)
+		if err != nil {
+			continue
+		}
+		f.JoinRef = rc.rewrite(f.JoinRef)
+		f.Ref = rc.rewrite(f.Ref)
+		rc.rewriteRequestID(f.Payload)
+		if response, ok := f.Payload["response"].(map[string]any); ok {
+			rc.rewriteRequestID(response)
+		}
+		rc.send(f)
+	}
+}
+
+// send writes one frame to the client
+func (rc *replayConn) send(f frame) {
+	if data, err := f.encode(); err == nil {
+		rc.ws.WriteMessage(websocket.TextMessage, data)
+	}
+}
@@ -0,0 +1,417 @@
+// Package mockserver implements the RubberDuck Phoenix socket protocol with
+// canned responses, so the real client can be tested end to end
+package mockserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// Options configures the canned behavior of the mock server
+type Options struct {
+	Username       string // Credentials accepted by login
+	Password       string
+	APIKey         string            // API key accepted on the user socket and by authenticate_with_api_key
+	Root           string            // Directory served by list_files, read_file, and search_code
+	Stream         bool              // Stream responses in chunks instead of a single response event
+	ChunkDelay     time.Duration     // Delay between streamed chunks
+	Capabilities   []string          // Features offered on join, nil for everything the client knows
+	Roles          []string          // Roles sent with the logged-in user, nil for none
+	Permissions    []string          // Permissions sent with the logged-in user, nil for none
+	History        int               // Canned messages served by get_history, one page at a time
+	ProviderStatus map[string]string // Health reported by get_provider_status, e.g. "openai": "degraded"; others are healthy
+	Logger         *log.Logger       // Frame log, nil for none
+}
+
+// ServerVersion is reported in the conversation join handshake
+const ServerVersion = "mock-1"
+
+// DefaultOptions returns the options used by cmd/mockserver
+func DefaultOptions() Options {
+	return Options{
+		Username:   "duck",
+		Password:   "quack",
+		APIKey:     "mock-api-key",
+		Root:       ".",
+		ChunkDelay: 30 * time.Millisecond,
+	}
+}
+
+// User is the account the mock server authenticates
+type User struct {
+	ID          string   `json:"id"`
+	Username    string   `json:"username"`
+	Email       string   `json:"email"`
+	Roles       []string `json:"roles,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+// Server serves the auth socket and the authenticated user socket
+type Server struct {
+	opts     Options
+	upgrader websocket.Upgrader
+	user     User
+	token    string
+	started  time.Time
+
+	mu            sync.Mutex
+	accounts      map[string]*account // By username
+	apiKeys       []apiKey
+	sessions      []session
+	nextID        int
+	conversations int                          // Conversation channels joined, for admin stats
+	disabled      map[string]bool              // Providers turned off through the admin channel
+	credentials   map[string]map[string]string // Provider credentials set through the auth channel
+	indexed       []string                     // Files under Root indexed by build_index, nil before the first build
+	indexedAt     time.Time
+	uploads       map[string]*upload // Chunked uploads in progress, by ID
+	downloads     map[string][]byte  // Chunked download snapshots, by ID
+}
+
+// account is a user login accepts
+type account struct {
+	User     User
+	Password string
+}
+
+// apiKey is a key managed through the api_keys channel
+type apiKey struct {
+	ID          string
+	Key         string
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+	Revoked     bool
+	CreatedFrom string
+	Name        string
+	Scopes      []string
+	LastUsedAt  time.Time
+	LastIP      string
+	Requests    int
+}
+
+// session is a sign-in listed by list_sessions
+type session struct {
+	ID         string
+	Client     string
+	IP         string
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	Revoked    bool
+}
+
+// New creates a mock server
+func New(opts Options) *Server {
+	if opts.Root == "" {
+		opts.Root = "."
+	}
+	s := &Server{
+		opts:     opts,
+		upgrader: websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }},
+		user:     User{ID: "user-1", Username: opts.Username, Email: opts.Username + "@example.com", Roles: opts.Roles, Permissions: opts.Permissions},
+		token:    "mock-jwt-token",
+		started:  time.Now(),
+		disabled: make(map[string]bool),
+	}
+	s.accounts = map[string]*account{opts.Username: {User: s.user, Password: opts.Password}}
+	if opts.APIKey != "" {
+		now := time.Now().UTC()
+		s.apiKeys = append(s.apiKeys, apiKey{ID: "key-0", Key: opts.APIKey, CreatedAt: now, ExpiresAt: now.AddDate(1, 0, 0)})
+	}
+	return s
+}
+
+// ServeHTTP upgrades /auth_socket/websocket and /socket/websocket connections
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var auth bool
+	switch strings.TrimSuffix(r.URL.Path, "/") {
+	case "/auth_socket/websocket":
+		auth = true
+	case "/socket/websocket":
+		// The user socket only accepts a known API key or token, like Phoenix's connect/3
+		query := r.URL.Query()
+		if !s.useAPIKey(query.Get("api_key"), remoteIP(r)) && query.Get("token") != s.token {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	ws, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	c := &conn{server: s, ws: ws, auth: auth, remote: remoteIP(r), joined: make(map[string]any)}
+	c.serve()
+}
+
+// remoteIP returns the client address of a request without its port
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// useAPIKey reports whether key is a current API key, recording its use
+func (s *Server) useAPIKey(key, ip string) bool {
+	if key == "" {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.apiKeys {
+		if k := &s.apiKeys[i]; k.Key == key && !k.Revoked {
+			k.LastUsedAt = time.Now().UTC()
+			k.LastIP = ip
+			k.Requests++
+			return true
+		}
+	}
+	return false
+}
+
+// apiKeyRevoked reports whether a key was issued and later revoked
+func (s *Server) apiKeyRevoked(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, k := range s.apiKeys {
+		if k.Key == key {
+			return k.Revoked
+		}
+	}
+	return false
+}
+
+// startSession records a sign-in and returns its ID
+func (s *Server) startSession(client, ip string) string {
+	id := s.newID("session")
+	now := time.Now().UTC()
+	if client == "" {
+		client = "unknown"
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions = append(s.sessions, session{ID: id, Client: client, IP: ip, CreatedAt: now, LastSeenAt: now})
+	return id
+}
+
+// logf writes to the frame log when one is configured
+func (s *Server) logf(format string, args ...any) {
+	if s.opts.Logger != nil {
+		s.opts.Logger.Printf(format, args...)
+	}
+}
+
+// frame is a V2 serializer message: [join_ref, ref, topic, event, payload]
+type frame struct {
+	JoinRef any
+	Ref     any
+	Topic   string
+	Event   string
+	Payload map[string]any
+}
+
+// decodeFrame parses a V2 serializer message
+func decodeFrame(data []byte) (frame, error) {
+	var f frame
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return f, err
+	}
+	if len(raw) != 5 {
+		return f, fmt.Errorf("frame has %d elements, want 5", len(raw))
+	}
+	json.Unmarshal(raw[0], &f.JoinRef)
+	json.Unmarshal(raw[1], &f.Ref)
+	json.Unmarshal(raw[2], &f.Topic)
+	json.Unmarshal(raw[3], &f.Event)
+	if err := json.Unmarshal(raw[4], &f.Payload); err != nil {
+		return f, err
+	}
+	return f, nil
+}
+
+// encode serializes the frame as a V2 message
+func (f frame) encode() ([]byte, error) {
+	return json.Marshal([]any{f.JoinRef, f.Ref, f.Topic, f.Event, f.Payload})
+}
+
+// isHeartbeat reports whether the frame is socket keepalive traffic
+func (f frame) isHeartbeat() bool {
+	return f.Topic == "phoenix"
+}
+
+// conn is one websocket connection and its joined channels
+type conn struct {
+	server *Server
+	ws     *websocket.Conn
+	auth   bool
+
+	remote string // Client IP
+
+	writeMu   sync.Mutex
+	mu        sync.Mutex
+	joined    map[string]any  // topic -> join_ref
+	streaming bool            // The client negotiated streaming responses
+	session   string          // Session started by signing in on this connection
+	username  string          // Account signed in on this connection
+	aborted   map[string]bool // ReAct executions the client aborted
+}
+
+// serve reads frames until the connection closes
+func (c *conn) serve() {
+	defer c.ws.Close()
+	for {
+		_, data, err := c.ws.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		f, err := decodeFrame(data)
+		if err != nil {
+			c.server.logf("bad frame: %s", data)
+			continue
+		}
+		c.server.logf("<- %s %s %v", f.Topic, f.Event, f.Payload)
+
+		c.handle(f)
+	}
+}
+
+// handle dispatches protocol frames and channel events
+func (c *conn) handle(f frame) {
+	switch {
+	case f.isHeartbeat():
+		c.reply(f, "ok", map[string]any{})
+	case f.Event == "phx_join":
+		c.join(f)
+	case f.Event == "phx_leave":
+		c.mu.Lock()
+		delete(c.joined, f.Topic)
+		c.mu.Unlock()
+		c.reply(f, "ok", map[string]any{})
+		c.write(f.JoinRef, f.Ref, f.Topic, "phx_close", map[string]any{})
+	default:
+		if !c.isJoined(f.Topic) {
+			c.reply(f, "error", map[string]any{"reason": "unmatched topic"})
+			return
+		}
+		c.handleEvent(f)
+	}
+}
+
+// join accepts the topics the real server routes to each socket
+func (c *conn) join(f frame) {
+	response, ok := c.joinResponse(f.Topic)
+	if !ok {
+		c.reply(f, "error", map[string]any{"reason": "unmatched topic"})
+		return
+	}
+	c.mu.Lock()
+	c.joined[f.Topic] = f.JoinRef
+	if f.Topic == "conversation:lobby" {
+		c.streaming = offers(response["capabilities"], phoenix.CapabilityStreaming) &&
+			strings.Contains(","+stringField(f.Payload, "capabilities")+",", ","+phoenix.CapabilityStreaming+",")
+	}
+	c.mu.Unlock()
+	c.reply(f, "ok", response)
+}
+
+// offers reports whether a capability list includes capability
+func offers(capabilities any, capability string) bool {
+	list, _ := capabilities.([]string)
+	for _, item := range list {
+		if item == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// isJoined reports whether the connection joined topic
+func (c *conn) isJoined(topic string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.joined[topic]
+	return ok
+}
+
+// joinedTopics returns the joined topics with the given prefix
+func (c *conn) joinedTopics(prefix string) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var topics []string
+	for topic := range c.joined {
+		if strings.HasPrefix(topic, prefix) {
+			topics = append(topics, topic)
+		}
+	}
+	return topics
+}
+
+// reply answers a client push
+func (c *conn) reply(f frame, status string, response any) {
+	if fields, ok := response.(map[string]any); ok {
+		echoRequestID(f, fields)
+	}
+	c.write(f.JoinRef, f.Ref, f.Topic, "phx_reply", map[string]any{"status": status, "response": response})
+}
+
+// answer pushes an event caused by a client frame, echoing its request ID
+func (c *conn) answer(f frame, event string, payload map[string]any) {
+	c.push(f.Topic, event, echoRequestID(f, payload))
+}
+
+// echoRequestID copies a client frame's correlation ID into a payload
+func echoRequestID(f frame, payload map[string]any) map[string]any {
+	if id := stringField(f.Payload, phoenix.RequestIDField); id != "" {
+		payload[phoenix.RequestIDField] = id
+	}
+	return payload
+}
+
+// push sends a server event on a joined topic
+func (c *conn) push(topic, event string, payload any) {
+	c.mu.Lock()
+	joinRef := c.joined[topic]
+	c.mu.Unlock()
+	c.write(joinRef, nil, topic, event, payload)
+}
+
+// write encodes and sends one frame
+func (c *conn) write(joinRef, ref any, topic, event string, payload any) {
+	data, err := json.Marshal([]any{joinRef, ref, topic, event, payload})
+	if err != nil {
+		return
+	}
+	c.server.logf("-> %s %s %s", topic, event, data)
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.ws.WriteMessage(websocket.TextMessage, data)
+}
+
+// timestamp formats a time the way the server does
+func timestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// newID returns a unique ID with the given prefix
+func (s *Server) newID(prefix string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	return fmt.Sprintf("%s-%d", prefix, s.nextID)
+}
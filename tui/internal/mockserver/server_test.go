@@ -0,0 +1,428 @@
+package mockserver
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// collector is a headless tea.Model that forwards every message to a channel
+type collector struct {
+	msgs chan tea.Msg
+}
+
+func (c collector) Init() tea.Cmd { return nil }
+
+func (c collector) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	c.msgs <- msg
+	return c, nil
+}
+
+func (c collector) View() string { return "" }
+
+// startProgram runs a headless program for the real client to send to
+func startProgram(t *testing.T) (*tea.Program, chan tea.Msg) {
+	t.Helper()
+	msgs := make(chan tea.Msg, 100)
+	program := tea.NewProgram(collector{msgs: msgs}, tea.WithInput(nil), tea.WithOutput(io.Discard), tea.WithoutRenderer())
+	go program.Run()
+	t.Cleanup(program.Kill)
+	return program, msgs
+}
+
+// waitFor returns the first message of type T, failing after a timeout
+func waitFor[T tea.Msg](t *testing.T, msgs chan tea.Msg) T {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case msg := <-msgs:
+			if m, ok := msg.(T); ok {
+				return m
+			}
+			if err, ok := msg.(phoenix.ErrorMsg); ok {
+				t.Fatalf("Unexpected error from %s: %v", err.Component, err.Err)
+			}
+		case <-timeout:
+			var zero T
+			t.Fatalf("Timed out waiting for %T", zero)
+			return zero
+		}
+	}
+}
+
+// connect starts a mock server and connects the real client to its user socket
+func connect(t *testing.T, opts Options) (*phoenix.Client, chan tea.Msg) {
+	t.Helper()
+	return connectTo(t, New(opts), opts.APIKey)
+}
+
+// connectTo connects the real client to handler and joins the conversation
+func connectTo(t *testing.T, handler http.Handler, apiKey string) (*phoenix.Client, chan tea.Msg) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	program, msgs := startProgram(t)
+	client := phoenix.NewClient()
+	client.SetProgram(program)
+	t.Cleanup(func() { client.Disconnect()() })
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/socket"
+	program.Send(client.Connect(phoenix.Config{URL: url, APIKey: apiKey})())
+	waitFor[phoenix.ConnectedMsg](t, msgs)
+
+	client.JoinChannel("conversation:lobby")()
+	joined := waitFor[phoenix.ChannelJoinedMsg](t, msgs)
+	if response, _ := joined.Response.(map[string]any); response["conversation_id"] == "" {
+		t.Errorf("Expected a conversation_id in the join reply, got %v", joined.Response)
+	}
+	return client, msgs
+}
+
+func TestRealClientSendMessage(t *testing.T) {
+	client, msgs := connect(t, DefaultOptions())
+
+	client.SendMessage("hello duck")()
+	msg := waitFor[phoenix.ConversationResponseMsg](t, msgs)
+
+	var response phoenix.ConversationMessage
+	if err := json.Unmarshal(msg.Response, &response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Query != "hello duck" || response.Response != "Mock response to: hello duck" {
+		t.Errorf("Unexpected response: %+v", response)
+	}
+}
+
+func TestRealClientStreaming(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Stream = true
+	opts.ChunkDelay = 10 * time.Millisecond
+	client, msgs := connect(t, opts)
+
+	client.SendMessage("stream please")()
+	start := waitFor[phoenix.StreamStartMsg](t, msgs)
+
+	var text strings.Builder
+	for {
+		select {
+		case msg := <-msgs:
+			switch msg := msg.(type) {
+			case phoenix.StreamDataMsg:
+				text.WriteString(msg.Data)
+				continue
+			case phoenix.StreamEndMsg:
+				if msg.ID != start.ID {
+					t.Errorf("Expected stream %s to end, got %s", start.ID, msg.ID)
+				}
+				if text.String() != "Mock response to: stream please" {
+					t.Errorf("Unexpected streamed text %q", text.String())
+				}
+				return
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for the stream to end")
+		}
+	}
+}
+
+func TestRealClientPagesHistory(t *testing.T) {
+	opts := DefaultOptions()
+	opts.History = 150
+	client, msgs := connect(t, opts)
+
+	client.GetConversationHistory(100)()
+	latest := waitFor[phoenix.ConversationHistoryMsg](t, msgs).History
+	if len(latest.Messages) != 100 || latest.Messages[0].ID != "history-50" || latest.NextCursor == "" {
+		t.Fatalf("Expected the latest 100 messages and a cursor, got %d from %q, cursor %q", len(latest.Messages), latest.Messages[0].ID, latest.NextCursor)
+	}
+
+	client.GetConversationHistoryBefore(100, latest.NextCursor)()
+	older := waitFor[phoenix.ConversationHistoryMsg](t, msgs).History
+	if older.Before != latest.NextCursor || len(older.Messages) != 50 || older.Messages[49].ID != "history-49" || older.NextCursor != "" {
+		t.Fatalf("Expected the 50 oldest messages and no further cursor, got %d, cursor %q", len(older.Messages), older.NextCursor)
+	}
+}
+
+func TestRealClientFileRequests(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Root = t.TempDir()
+	writeFile(t, opts.Root, "main.go", "package main\n\nfunc quack() {}\n")
+	client, _ := connect(t, opts)
+
+	files, err := client.ListFiles(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Name != "main.go" {
+		t.Errorf("Unexpected files: %+v", files)
+	}
+
+	content, err := client.ReadFile(context.Background(), "main.go")
+	if err != nil || !strings.Contains(content, "func quack") {
+		t.Errorf("ReadFile = %q, %v", content, err)
+	}
+	if _, err := client.ReadFile(context.Background(), "../outside"); err == nil {
+		t.Error("Expected reading outside the root to fail")
+	}
+
+	matches, err := client.SearchCode(context.Background(), "quack", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 || matches[0].Line != 3 {
+		t.Errorf("Unexpected matches: %+v", matches)
+	}
+}
+
+func TestRealClientChunkedTransfer(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Root = t.TempDir()
+	client, _ := connect(t, opts)
+
+	data := []byte(strings.Repeat("quack ", phoenix.ChunkThreshold/3))
+	var reported []int64
+	progress := func(done, total int64) { reported = append(reported, done) }
+	if err := client.Upload(context.Background(), "big/duck.txt", data, progress); err != nil {
+		t.Fatal(err)
+	}
+	if written, err := os.ReadFile(filepath.Join(opts.Root, "big", "duck.txt")); err != nil || string(written) != string(data) {
+		t.Fatalf("Uploaded file differs: %d bytes, %v", len(written), err)
+	}
+	if len(reported) < 3 || reported[len(reported)-1] != int64(len(data)) {
+		t.Errorf("Unexpected progress: %v", reported)
+	}
+
+	// Files over the threshold come back through download_chunk
+	content, err := client.ReadFile(context.Background(), "big/duck.txt")
+	if err != nil || content != string(data) {
+		t.Errorf("ReadFile returned %d bytes, %v", len(content), err)
+	}
+}
+
+func TestUploadResumes(t *testing.T) {
+	s := New(Options{Root: t.TempDir()})
+	id, offset, err := s.beginUpload("duck.txt", 8, "checksum")
+	if err != nil || offset != 0 {
+		t.Fatalf("beginUpload = %d, %v", offset, err)
+	}
+	if _, err := s.uploadChunk(id, 0, "cXVhY2s="); err != nil { // "quack"
+		t.Fatal(err)
+	}
+	resumed, offset, err := s.beginUpload("duck.txt", 8, "checksum")
+	if err != nil || resumed != id || offset != 5 {
+		t.Errorf("Expected to resume %s at 5, got %s at %d (%v)", id, resumed, offset, err)
+	}
+	if _, err := s.uploadChunk(id, 7, "cXVhY2s="); err == nil {
+		t.Error("Expected a chunk leaving a gap to fail")
+	}
+}
+
+func TestRealClientRejoinsChannelsOnNewSocket(t *testing.T) {
+	server := httptest.NewServer(New(DefaultOptions()))
+	defer server.Close()
+
+	program, msgs := startProgram(t)
+	client := phoenix.NewClient()
+	client.SetProgram(program)
+	defer client.Disconnect()()
+	status := phoenix.NewStatusClient(client.Channels())
+
+	config := phoenix.Config{URL: "ws" + strings.TrimPrefix(server.URL, "http") + "/socket", APIKey: DefaultOptions().APIKey}
+	client.Connect(config)()
+	waitFor[phoenix.ConnectedMsg](t, msgs)
+	client.JoinChannel("conversation:lobby")()
+	waitFor[phoenix.ChannelJoinedMsg](t, msgs)
+	status.JoinStatusChannel("conv-1")()
+	waitFor[phoenix.StatusChannelJoinedMsg](t, msgs)
+
+	// A reconnect creates a new socket; both channels follow it
+	client.Connect(config)()
+	var conversation, statusJoined bool
+	timeout := time.After(5 * time.Second)
+	for !conversation || !statusJoined {
+		select {
+		case msg := <-msgs:
+			switch msg := msg.(type) {
+			case phoenix.ChannelJoinedMsg:
+				conversation = true
+			case phoenix.StatusChannelJoinedMsg:
+				statusJoined = true
+			case phoenix.ErrorMsg:
+				t.Fatalf("Unexpected error from %s: %v", msg.Component, msg.Err)
+			}
+		case <-timeout:
+			t.Fatalf("Timed out rejoining: conversation=%v status=%v", conversation, statusJoined)
+		}
+	}
+
+	if topics := client.Channels().Topics(); len(topics) != 2 {
+		t.Errorf("Expected two registered channels, got %v", topics)
+	}
+	client.SendMessage("still there?")()
+	waitFor[phoenix.ConversationResponseMsg](t, msgs)
+}
+
+func TestAuthSessions(t *testing.T) {
+	opts := DefaultOptions()
+	server := httptest.NewServer(New(opts))
+	t.Cleanup(server.Close)
+	program, msgs := startProgram(t)
+
+	client := phoenix.NewClient()
+	client.SetProgram(program)
+	t.Cleanup(func() { client.Disconnect()() })
+	program.Send(client.Connect(phoenix.Config{URL: "ws" + strings.TrimPrefix(server.URL, "http") + "/auth_socket", IsAuth: true})())
+	created := waitFor[phoenix.SocketCreatedMsg](t, msgs)
+
+	auth := phoenix.NewAuthClient()
+	auth.SetProgram(program)
+	auth.SetSocket(created.Socket)
+	auth.JoinAuthChannel()()
+	waitFor[phoenix.AuthChannelJoinedMsg](t, msgs)
+
+	auth.AuthenticateWithAPIKey(opts.APIKey)()
+	waitFor[phoenix.LoginSuccessMsg](t, msgs)
+
+	auth.ListSessions()()
+	sessions := waitFor[phoenix.SessionListMsg](t, msgs).Sessions
+	if len(sessions) != 1 || !sessions[0].Current || sessions[0].Client != phoenix.ClientName() || sessions[0].IP == "" {
+		t.Fatalf("Expected this client's session, got %+v", sessions)
+	}
+
+	auth.RevokeSession(sessions[0].ID)()
+	if revoked := waitFor[phoenix.SessionRevokedMsg](t, msgs); revoked.SessionID != sessions[0].ID {
+		t.Errorf("Unexpected revoke reply: %+v", revoked)
+	}
+}
+
+func TestAuthRegisterAndChangePassword(t *testing.T) {
+	server := httptest.NewServer(New(DefaultOptions()))
+	t.Cleanup(server.Close)
+	program, msgs := startProgram(t)
+
+	client := phoenix.NewClient()
+	client.SetProgram(program)
+	t.Cleanup(func() { client.Disconnect()() })
+	program.Send(client.Connect(phoenix.Config{URL: "ws" + strings.TrimPrefix(server.URL, "http") + "/auth_socket", IsAuth: true})())
+	created := waitFor[phoenix.SocketCreatedMsg](t, msgs)
+
+	auth := phoenix.NewAuthClient()
+	auth.SetProgram(program)
+	auth.SetSocket(created.Socket)
+	auth.JoinAuthChannel()()
+	waitFor[phoenix.AuthChannelJoinedMsg](t, msgs)
+
+	auth.Register("duck", "duck@example.com", "longenough")()
+	if rejected := waitFor[phoenix.RegisterErrorMsg](t, msgs); !strings.Contains(rejected.Message, "taken") {
+		t.Errorf("Expected a taken username to be refused, got %q", rejected.Message)
+	}
+
+	auth.Register("goose", "goose@example.com", "honkhonk")()
+	if registered := waitFor[phoenix.RegisterSuccessMsg](t, msgs); registered.User.Username != "goose" || registered.Token == "" {
+		t.Fatalf("Unexpected registration: %+v", registered)
+	}
+
+	auth.ChangePassword("wrong", "newpassword")()
+	waitFor[phoenix.PasswordChangeErrorMsg](t, msgs)
+	auth.ChangePassword("honkhonk", "newpassword")()
+	waitFor[phoenix.PasswordChangedMsg](t, msgs)
+
+	auth.Login("goose", "newpassword")()
+	if login := waitFor[phoenix.LoginSuccessMsg](t, msgs); login.User.Username != "goose" {
+		t.Errorf("Expected to log in with the new password, got %+v", login.User)
+	}
+}
+
+func TestAdminChannel(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Roles = []string{phoenix.RoleAdmin}
+	client, msgs := connect(t, opts)
+	admin := phoenix.NewAdminClient(client.Channels())
+
+	admin.JoinAdminChannel()()
+	waitFor[phoenix.AdminChannelJoinedMsg](t, msgs)
+
+	admin.SetProviderEnabled("ollama", false)()
+	if result := waitFor[phoenix.AdminActionMsg](t, msgs); result.Message != "ollama disabled" {
+		t.Errorf("Unexpected toggle result: %+v", result)
+	}
+
+	admin.GetStats()()
+	stats := waitFor[phoenix.AdminStatsMsg](t, msgs).Stats
+	if stats.ActiveConversations != 1 || len(stats.Providers) != 3 {
+		t.Fatalf("Unexpected stats: %+v", stats)
+	}
+	for _, provider := range stats.Providers {
+		if provider.Enabled == (provider.Name == "ollama") {
+			t.Errorf("Expected only ollama to be disabled, got %+v", provider)
+		}
+	}
+}
+
+func TestAdminChannelRejectsNonAdmins(t *testing.T) {
+	client, msgs := connect(t, DefaultOptions())
+	phoenix.NewAdminClient(client.Channels()).JoinAdminChannel()()
+	if err := waitFor[phoenix.ErrorMsg](t, msgs); !strings.Contains(err.Err.Error(), "admin:lobby join rejected") {
+		t.Errorf("Expected the join to be rejected, got %v", err.Err)
+	}
+}
+
+func TestIndexChannelRetrievesIndexedFiles(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Root = t.TempDir()
+	opts.ChunkDelay = 0
+	writeFile(t, opts.Root, "parser.go", "package main\n")
+	writeFile(t, opts.Root, "lexer.go", "package main\n")
+	client, msgs := connect(t, opts)
+	index := phoenix.NewIndexClient(client.Channels())
+
+	index.JoinIndexChannel()()
+	waitFor[phoenix.IndexChannelJoinedMsg](t, msgs)
+	index.BuildIndex(false)()
+	if status := waitFor[phoenix.IndexStatusMsg](t, msgs).Status; status.State != phoenix.IndexReady || status.Files != 2 {
+		t.Fatalf("Unexpected index status: %+v", status)
+	}
+
+	client.SendMessage("why does the parser panic?")()
+	var response phoenix.ConversationMessage
+	if err := json.Unmarshal(waitFor[phoenix.ConversationResponseMsg](t, msgs).Response, &response); err != nil {
+		t.Fatal(err)
+	}
+	if sources := response.RetrievedSources(); len(sources) != 1 || sources[0].Path != "parser.go" || sources[0].EndLine != 20 {
+		t.Errorf("Expected parser.go as the only source, got %+v", sources)
+	}
+}
+
+func TestUserSocketRejectsUnknownAPIKey(t *testing.T) {
+	server := httptest.NewServer(New(DefaultOptions()))
+	defer server.Close()
+
+	resp, err := server.Client().Get(server.URL + "/socket/websocket?api_key=wrong")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != 403 {
+		t.Errorf("Expected 403 for an unknown API key, got %d", resp.StatusCode)
+	}
+}
+
+// writeFile creates a file under dir
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
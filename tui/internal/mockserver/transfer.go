@@ -0,0 +1,137 @@
+package mockserver
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// upload is a chunked upload in progress
+type upload struct {
+	Path   string
+	Size   int
+	SHA256 string
+	Data   []byte
+}
+
+// beginUpload starts an upload, or resumes the unfinished one of the same
+// content to the same path
+func (s *Server) beginUpload(path string, size int, checksum string) (string, int, error) {
+	if _, err := s.resolve(path); err != nil {
+		return "", 0, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, u := range s.uploads {
+		if u.Path == path && u.Size == size && u.SHA256 == checksum {
+			return id, len(u.Data), nil
+		}
+	}
+	if s.uploads == nil {
+		s.uploads = make(map[string]*upload)
+	}
+	s.nextID++
+	id := fmt.Sprintf("upload-%d", s.nextID)
+	s.uploads[id] = &upload{Path: path, Size: size, SHA256: checksum}
+	return id, 0, nil
+}
+
+// uploadChunk appends a chunk at offset, returning where the next starts.
+// A chunk the server already has is ignored
+func (s *Server) uploadChunk(id string, offset int, encoded string) (int, error) {
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return 0, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.uploads[id]
+	switch {
+	case !ok:
+		return 0, errors.New("unknown upload " + id)
+	case offset > len(u.Data):
+		return 0, fmt.Errorf("chunk at %d leaves a gap after %d", offset, len(u.Data))
+	case offset+len(data) > u.Size:
+		return 0, fmt.Errorf("chunk at %d overruns the %d bytes announced", offset, u.Size)
+	}
+	u.Data = append(u.Data[:offset], data...)
+	return len(u.Data), nil
+}
+
+// commitUpload checks a finished upload against its checksum and writes it
+func (s *Server) commitUpload(id string) error {
+	s.mu.Lock()
+	u, ok := s.uploads[id]
+	delete(s.uploads, id)
+	s.mu.Unlock()
+	if !ok {
+		return errors.New("unknown upload " + id)
+	}
+	if len(u.Data) != u.Size {
+		return fmt.Errorf("upload is incomplete: %d of %d bytes", len(u.Data), u.Size)
+	}
+	if sum := sha256.Sum256(u.Data); hex.EncodeToString(sum[:]) != u.SHA256 {
+		return errors.New("checksum mismatch")
+	}
+	full, err := s.resolve(u.Path)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(full, u.Data, 0644)
+}
+
+// beginDownload snapshots a file for chunked reading
+func (s *Server) beginDownload(path string) (map[string]any, error) {
+	full, err := s.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(full)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.downloads == nil {
+		s.downloads = make(map[string][]byte)
+	}
+	s.nextID++
+	id := fmt.Sprintf("download-%d", s.nextID)
+	s.downloads[id] = data
+	sum := sha256.Sum256(data)
+	return map[string]any{"download_id": id, "size": len(data), "sha256": hex.EncodeToString(sum[:])}, nil
+}
+
+// downloadChunk returns up to length bytes from offset, dropping the
+// snapshot once its end is read
+func (s *Server) downloadChunk(id string, offset, length int) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.downloads[id]
+	if !ok {
+		return "", errors.New("unknown download " + id)
+	}
+	if offset < 0 || offset > len(data) || length <= 0 {
+		return "", fmt.Errorf("invalid range %d+%d", offset, length)
+	}
+	end := min(offset+length, len(data))
+	if end == len(data) {
+		delete(s.downloads, id)
+	}
+	return base64.StdEncoding.EncodeToString(data[offset:end]), nil
+}
+
+// chunkedRead reports whether read_file should point the client at
+// download_begin instead of sending content
+func chunkedRead(content string) bool {
+	return len(content) > phoenix.ChunkThreshold
+}
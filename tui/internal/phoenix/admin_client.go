@@ -0,0 +1,143 @@
+package phoenix
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nshafer/phx"
+)
+
+// adminTopic is the server operators' channel; the server only lets admins
+// join it
+const adminTopic = "admin:lobby"
+
+// AdminClient handles admin channel operations
+type AdminClient struct {
+	channels *ChannelManager
+}
+
+// NewAdminClient creates an admin client on the shared channel manager
+func NewAdminClient(channels *ChannelManager) *AdminClient {
+	return &AdminClient{channels: channels}
+}
+
+// JoinAdminChannel joins the admin channel
+func (a *AdminClient) JoinAdminChannel() tea.Cmd {
+	return a.channels.Join(ChannelSpec{
+		Topic:     adminTopic,
+		Component: "Admin Client",
+		Handlers:  a.adminHandlers(),
+		OnJoin: func(_ *phx.Channel, _ any) tea.Msg {
+			return AdminChannelJoinedMsg{}
+		},
+	})
+}
+
+// Joined reports whether the admin channel is joined
+func (a *AdminClient) Joined() bool {
+	channel := a.channels.Channel(adminTopic)
+	return channel != nil && channel.IsJoined()
+}
+
+// adminHandlers returns the admin channel event handlers
+func (a *AdminClient) adminHandlers() map[string]func(any) {
+	return map[string]func(any){
+		// Server metrics and provider health
+		"admin_stats": func(payload any) {
+			var stats AdminStats
+			a.channels.decode(adminTopic, "admin_stats", payload, &stats)
+			a.channels.Send(AdminStatsMsg{Stats: stats})
+		},
+
+		// Maintenance action finished
+		"maintenance_result": func(payload any) {
+			var result struct {
+				Action  string `json:"action"`
+				Message string `json:"message"`
+			}
+			a.channels.decode(adminTopic, "maintenance_result", payload, &result)
+			a.channels.Send(AdminActionMsg{Action: result.Action, Message: result.Message})
+		},
+
+		// Admin operation failed
+		"admin_error": func(payload any) {
+			var failure struct {
+				Operation string `json:"operation"`
+				Message   string `json:"message"`
+			}
+			a.channels.decode(adminTopic, "admin_error", payload, &failure)
+			a.channels.Send(AdminErrorMsg{Operation: failure.Operation, Message: failure.Message})
+		},
+	}
+}
+
+// push sends an admin request; results arrive as channel events
+func (a *AdminClient) push(event string, payload map[string]any) tea.Cmd {
+	return a.channels.Push(adminTopic, event, payload, PushOptions{
+		OnError: func(response any) tea.Msg {
+			return AdminErrorMsg{Operation: event, Message: fmt.Sprintf("%v", response)}
+		},
+	})
+}
+
+// GetStats requests server metrics and provider health
+func (a *AdminClient) GetStats() tea.Cmd {
+	return a.push("get_stats", map[string]any{})
+}
+
+// FlushCaches clears the server's response and context caches
+func (a *AdminClient) FlushCaches() tea.Cmd {
+	return a.push("flush_caches", map[string]any{})
+}
+
+// SetProviderEnabled turns an LLM provider on or off for every user
+func (a *AdminClient) SetProviderEnabled(provider string, enabled bool) tea.Cmd {
+	return a.push("toggle_provider", map[string]any{
+		"provider": provider,
+		"enabled":  enabled,
+	})
+}
+
+// LeaveChannel leaves the admin channel
+func (a *AdminClient) LeaveChannel() {
+	a.channels.Leave(adminTopic)
+}
+
+// AdminStats is the server's operational snapshot
+type AdminStats struct {
+	UptimeSeconds       float64            `json:"uptime_seconds"`
+	ActiveConversations int                `json:"active_conversations"`
+	ConnectedUsers      int                `json:"connected_users"`
+	Metrics             map[string]float64 `json:"metrics,omitempty"` // e.g. requests_per_minute, memory_mb
+	Providers           []ProviderHealth   `json:"providers"`
+}
+
+// ProviderHealth is one LLM provider as the server sees it
+type ProviderHealth struct {
+	Name      string  `json:"name"`
+	Enabled   bool    `json:"enabled"`
+	Healthy   bool    `json:"healthy"`
+	LatencyMS float64 `json:"latency_ms"`
+	ErrorRate float64 `json:"error_rate"` // Fraction of recent requests that failed
+}
+
+// Admin channel message types
+
+type AdminChannelJoinedMsg struct{}
+
+// AdminStatsMsg carries a stats snapshot
+type AdminStatsMsg struct {
+	Stats AdminStats
+}
+
+// AdminActionMsg reports a finished maintenance action
+type AdminActionMsg struct {
+	Action  string
+	Message string
+}
+
+// AdminErrorMsg reports a failed admin operation
+type AdminErrorMsg struct {
+	Operation string
+	Message   string
+}
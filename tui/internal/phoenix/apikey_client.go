@@ -8,27 +8,18 @@ import (
 	"github.com/nshafer/phx"
 )
 
+// apiKeyTopic is the user's API key management channel
+const apiKeyTopic = "api_keys:manage"
+
 // ApiKeyClient handles API key channel operations
 type ApiKeyClient struct {
-	socket    *phx.Socket
-	channel   *phx.Channel
-	program   *tea.Program
-	userID    string
-}
-
-// NewApiKeyClient creates a new API key client
-func NewApiKeyClient() *ApiKeyClient {
-	return &ApiKeyClient{}
-}
-
-// SetSocket sets the Phoenix socket (must be authenticated user socket)
-func (a *ApiKeyClient) SetSocket(socket *phx.Socket) {
-	a.socket = socket
+	channels *ChannelManager
+	userID   string
 }
 
-// SetProgram sets the tea.Program for sending messages
-func (a *ApiKeyClient) SetProgram(program *tea.Program) {
-	a.program = program
+// NewApiKeyClient creates an API key client on the shared channel manager
+func NewApiKeyClient(channels *ChannelManager) *ApiKeyClient {
+	return &ApiKeyClient{channels: channels}
 }
 
 // SetUserID sets the user ID for the channel topic
@@ -38,316 +29,228 @@ func (a *ApiKeyClient) SetUserID(userID string) {
 
 // JoinApiKeyChannel joins the api_keys channel for the user
 func (a *ApiKeyClient) JoinApiKeyChannel() tea.Cmd {
-	return func() tea.Msg {
-		if a.socket == nil {
+	if a.userID == "" {
+		return func() tea.Msg {
 			return ErrorMsg{
-				Err:       fmt.Errorf("socket not connected"),
+				Err:       Errorf(ErrUserUnknown, "user ID not set"),
 				Component: "ApiKey Client",
 			}
 		}
-		
-		if a.userID == "" {
-			return ErrorMsg{
-				Err:       fmt.Errorf("user ID not set"),
-				Component: "ApiKey Client",
-			}
-		}
-		
-		// Join api_keys:manage channel
-		channelName := "api_keys:manage"
-		channel := a.socket.Channel(channelName, nil)
-		
-		// Set up event handlers
-		a.setupApiKeyHandlers(channel)
-		
-		// Join the channel
-		join, err := channel.Join()
-		if err != nil {
-			return ErrorMsg{
-				Err:       fmt.Errorf("failed to join api_keys channel: %w", err),
-				Component: "ApiKey Client",
-			}
-		}
-		
-		// Handle join responses
-		join.Receive("ok", func(response any) {
-			a.channel = channel
-			if a.program != nil {
-				a.program.Send(ApiKeyChannelJoinedMsg{})
-			}
-		})
-		
-		join.Receive("error", func(response any) {
-			if a.program != nil {
-				a.program.Send(ErrorMsg{
-					Err:       fmt.Errorf("api_keys channel join rejected: %v", response),
-					Component: "ApiKey Client",
-				})
-			}
-		})
-		
-		join.Receive("timeout", func(response any) {
-			if a.program != nil {
-				a.program.Send(ErrorMsg{
-					Err:       fmt.Errorf("api_keys channel join timeout"),
-					Component: "ApiKey Client",
-				})
-			}
-		})
-		
-		return nil
 	}
+	
+	return a.channels.Join(ChannelSpec{
+		Topic:     apiKeyTopic,
+		Component: "ApiKey Client",
+		Handlers:  a.apiKeyHandlers(),
+		OnJoin: func(_ *phx.Channel, _ any) tea.Msg {
+			return ApiKeyChannelJoinedMsg{}
+		},
+	})
 }
 
-// setupApiKeyHandlers sets up event handlers for api_keys channel
-func (a *ApiKeyClient) setupApiKeyHandlers(channel *phx.Channel) {
-	// API key generated
-	channel.On("api_key_generated", func(payload any) {
-		var msg struct {
-			APIKey struct {
-				ID        string `json:"id"`
-				Key       string `json:"key"`
-				CreatedAt string `json:"created_at"`
-				ExpiresAt string `json:"expires_at"`
-			} `json:"api_key"`
-			Warning string `json:"warning"`
-		}
+// apiKeyHandlers returns the api_keys channel event handlers
+func (a *ApiKeyClient) apiKeyHandlers() map[string]func(any) {
+	return map[string]func(any){
+		// API key generated
+		"api_key_generated": func(payload any) {
+			var msg struct {
+				APIKey struct {
+					ID        string   `json:"id"`
+					Key       string   `json:"key"`
+					CreatedAt string   `json:"created_at"`
+					ExpiresAt string   `json:"expires_at"`
+					Name      string   `json:"name"`
+					Scopes    []string `json:"scopes"`
+				} `json:"api_key"`
+				Warning string `json:"warning"`
+			}
 		
-		if data, ok := payload.(map[string]any); ok {
-			// Parse the response
-			if apiKeyData, ok := data["api_key"].(map[string]any); ok {
-				msg.APIKey.ID = getString(apiKeyData, "id")
-				msg.APIKey.Key = getString(apiKeyData, "key")
-				msg.APIKey.CreatedAt = getString(apiKeyData, "created_at")
-				msg.APIKey.ExpiresAt = getString(apiKeyData, "expires_at")
+			if data, ok := payload.(map[string]any); ok {
+				// Parse the response
+				if apiKeyData, ok := data["api_key"].(map[string]any); ok {
+					msg.APIKey.ID = getString(apiKeyData, "id")
+					msg.APIKey.Key = getString(apiKeyData, "key")
+					msg.APIKey.CreatedAt = getString(apiKeyData, "created_at")
+					msg.APIKey.ExpiresAt = getString(apiKeyData, "expires_at")
+					msg.APIKey.Name = getString(apiKeyData, "name")
+					msg.APIKey.Scopes = getStrings(apiKeyData, "scopes")
+				}
+				msg.Warning = getString(data, "warning")
 			}
-			msg.Warning = getString(data, "warning")
-		}
 		
-		// Parse timestamps
-		var createdAt, expiresAt time.Time
-		if msg.APIKey.CreatedAt != "" {
-			createdAt, _ = time.Parse(time.RFC3339, msg.APIKey.CreatedAt)
-		}
-		if msg.APIKey.ExpiresAt != "" {
-			expiresAt, _ = time.Parse(time.RFC3339, msg.APIKey.ExpiresAt)
-		}
+			// Parse timestamps
+			var createdAt, expiresAt time.Time
+			if msg.APIKey.CreatedAt != "" {
+				createdAt, _ = time.Parse(time.RFC3339, msg.APIKey.CreatedAt)
+			}
+			if msg.APIKey.ExpiresAt != "" {
+				expiresAt, _ = time.Parse(time.RFC3339, msg.APIKey.ExpiresAt)
+			}
 		
-		if a.program != nil {
-			a.program.Send(APIKeyGeneratedMsg{
+			a.channels.Send(APIKeyGeneratedMsg{
 				APIKey: APIKey{
 					ID:        msg.APIKey.ID,
 					Key:       msg.APIKey.Key,
+					Name:      msg.APIKey.Name,
+					Scopes:    msg.APIKey.Scopes,
 					CreatedAt: createdAt,
 					ExpiresAt: expiresAt,
 				},
-				Warning: msg.Warning,
+				Warning:   msg.Warning,
+				RequestID: RequestIDOf(payload),
 			})
-		}
-	})
+		},
 	
-	// API keys listed
-	channel.On("api_key_list", func(payload any) {
-		var apiKeys []APIKey
-		
-		if data, ok := payload.(map[string]any); ok {
-			if keysData, ok := data["api_keys"].([]any); ok {
-				for _, keyData := range keysData {
-					if key, ok := keyData.(map[string]any); ok {
-						var apiKey APIKey
-						apiKey.ID = getString(key, "id")
-						apiKey.Valid = getBool(key, "valid")
+		// API keys listed
+		"api_key_list": func(payload any) {
+			var apiKeys []APIKey
+		
+			if data, ok := payload.(map[string]any); ok {
+				if keysData, ok := data["api_keys"].([]any); ok {
+					for _, keyData := range keysData {
+						if key, ok := keyData.(map[string]any); ok {
+							var apiKey APIKey
+							apiKey.ID = getString(key, "id")
+							apiKey.Valid = getBool(key, "valid")
+							apiKey.Name = getString(key, "name")
+							apiKey.Scopes = getStrings(key, "scopes")
 						
-						if createdStr := getString(key, "created_at"); createdStr != "" {
-							apiKey.CreatedAt, _ = time.Parse(time.RFC3339, createdStr)
-						}
-						if expiresStr := getString(key, "expires_at"); expiresStr != "" {
-							apiKey.ExpiresAt, _ = time.Parse(time.RFC3339, expiresStr)
-						}
+							if createdStr := getString(key, "created_at"); createdStr != "" {
+								apiKey.CreatedAt, _ = time.Parse(time.RFC3339, createdStr)
+							}
+							if expiresStr := getString(key, "expires_at"); expiresStr != "" {
+								apiKey.ExpiresAt, _ = time.Parse(time.RFC3339, expiresStr)
+							}
+							if lastUsed, err := time.Parse(time.RFC3339, getString(key, "last_used_at")); err == nil {
+								apiKey.LastUsedAt = &lastUsed
+							}
+							apiKey.LastIP = getString(key, "last_ip")
+							apiKey.CreatedFrom = getString(key, "created_from")
 						
-						apiKeys = append(apiKeys, apiKey)
+							apiKeys = append(apiKeys, apiKey)
+						}
 					}
 				}
 			}
-		}
 		
-		if a.program != nil {
-			a.program.Send(APIKeyListMsg{
-				APIKeys: apiKeys,
-				Count:   len(apiKeys),
+			a.channels.Send(APIKeyListMsg{
+				APIKeys:   apiKeys,
+				Count:     len(apiKeys),
+				RequestID: RequestIDOf(payload),
 			})
-		}
-	})
+		},
 	
-	// API key revoked
-	channel.On("api_key_revoked", func(payload any) {
-		message := "API key revoked successfully"
-		if data, ok := payload.(map[string]any); ok {
-			if msg, ok := data["message"].(string); ok {
-				message = msg
+		// API key usage counts
+		"api_key_usage": func(payload any) {
+			var usage []APIKeyUsage
+		
+			if data, ok := payload.(map[string]any); ok {
+				if entries, ok := data["usage"].([]any); ok {
+					for _, entry := range entries {
+						if fields, ok := entry.(map[string]any); ok {
+							keyUsage := APIKeyUsage{
+								APIKeyID: getString(fields, "api_key_id"),
+								LastIP:   getString(fields, "last_ip"),
+							}
+							if count, ok := fields["request_count"].(float64); ok {
+								keyUsage.Requests = int(count)
+							}
+							if lastUsed, err := time.Parse(time.RFC3339, getString(fields, "last_used_at")); err == nil {
+								keyUsage.LastUsedAt = &lastUsed
+							}
+							usage = append(usage, keyUsage)
+						}
+					}
+				}
 			}
-		}
 		
-		if a.program != nil {
-			a.program.Send(APIKeyRevokedMsg{
-				Message: message,
+			a.channels.Send(APIKeyUsageMsg{
+				Usage:     usage,
+				RequestID: RequestIDOf(payload),
 			})
-		}
-	})
+		},
 	
-	// API key error
-	channel.On("api_key_error", func(payload any) {
-		var operation, message, details string
+		// API key revoked
+		"api_key_revoked": func(payload any) {
+			message := "API key revoked successfully"
+			if data, ok := payload.(map[string]any); ok {
+				if msg, ok := data["message"].(string); ok {
+					message = msg
+				}
+			}
 		
-		if data, ok := payload.(map[string]any); ok {
-			operation = getString(data, "operation")
-			message = getString(data, "message")
-			details = getString(data, "details")
-		}
+			a.channels.Send(APIKeyRevokedMsg{
+				Message:   message,
+				RequestID: RequestIDOf(payload),
+			})
+		},
+	
+		// API key error
+		"api_key_error": func(payload any) {
+			var operation, message, details string
+		
+			if data, ok := payload.(map[string]any); ok {
+				operation = getString(data, "operation")
+				message = getString(data, "message")
+				details = getString(data, "details")
+			}
 		
-		if a.program != nil {
-			a.program.Send(APIKeyErrorMsg{
+			a.channels.Send(APIKeyErrorMsg{
 				Operation: operation,
 				Message:   message,
 				Details:   details,
+				RequestID: RequestIDOf(payload),
 			})
-		}
+		},
+	}
+}
+
+// pushAPIKeyEvent sends an api_keys request; success arrives as a channel
+// event, so only failures are reported from the reply
+func (a *ApiKeyClient) pushAPIKeyEvent(event string, payload map[string]any, operation, message string) tea.Cmd {
+	return a.channels.Push(apiKeyTopic, event, payload, PushOptions{
+		OnError: func(response any) tea.Msg {
+			return APIKeyErrorMsg{
+				Operation: operation,
+				Message:   message,
+				Details:   fmt.Sprintf("%v", response),
+				RequestID: RequestIDOf(response),
+			}
+		},
 	})
 }
 
-// GenerateAPIKey generates a new API key
+// GenerateAPIKey generates a new API key, naming this client as its
+// creator. params can set "name", "expires_in_days", and "scopes"
 func (a *ApiKeyClient) GenerateAPIKey(params map[string]any) tea.Cmd {
-	return func() tea.Msg {
-		if a.channel == nil {
-			return ErrorMsg{
-				Err:       fmt.Errorf("api_keys channel not joined"),
-				Component: "ApiKey Client",
-			}
-		}
-		
-		push, err := a.channel.Push("generate_api_key", nil)
-		if err != nil {
-			return ErrorMsg{
-				Err:       fmt.Errorf("failed to generate API key: %w", err),
-				Component: "ApiKey Client",
-			}
-		}
-		
-		// Handle responses
-		push.Receive("ok", func(response any) {
-			// Response will be handled by the event handler
-		})
-		
-		push.Receive("error", func(response any) {
-			if a.program != nil {
-				a.program.Send(APIKeyErrorMsg{
-					Operation: "generate",
-					Message:   "Failed to generate API key",
-					Details:   fmt.Sprintf("%v", response),
-				})
-			}
-		})
-		
-		push.Receive("timeout", func(response any) {
-			// API key generation uses channel events for success, ignore push timeout
-		})
-		
-		return nil
+	payload := map[string]any{"client": ClientName()}
+	for key, value := range params {
+		payload[key] = value
 	}
+	return a.pushAPIKeyEvent("generate_api_key", payload, "generate", "Failed to generate API key")
 }
 
 // ListAPIKeys lists all API keys for the user
 func (a *ApiKeyClient) ListAPIKeys() tea.Cmd {
-	return func() tea.Msg {
-		if a.channel == nil {
-			return ErrorMsg{
-				Err:       fmt.Errorf("api_keys channel not joined"),
-				Component: "ApiKey Client",
-			}
-		}
-		
-		push, err := a.channel.Push("list_api_keys", nil)
-		if err != nil {
-			return ErrorMsg{
-				Err:       fmt.Errorf("failed to list API keys: %w", err),
-				Component: "ApiKey Client",
-			}
-		}
-		
-		// Handle responses
-		push.Receive("ok", func(response any) {
-			// Response will be handled by the event handler
-		})
-		
-		push.Receive("error", func(response any) {
-			if a.program != nil {
-				a.program.Send(APIKeyErrorMsg{
-					Operation: "list",
-					Message:   "Failed to list API keys",
-					Details:   fmt.Sprintf("%v", response),
-				})
-			}
-		})
-		
-		push.Receive("timeout", func(response any) {
-			// API key list uses channel events for success, ignore push timeout
-		})
-		
-		return nil
-	}
+	return a.pushAPIKeyEvent("list_api_keys", nil, "list", "Failed to list API keys")
+}
+
+// GetAPIKeyUsage requests the request counts and last use of every key
+func (a *ApiKeyClient) GetAPIKeyUsage() tea.Cmd {
+	return a.pushAPIKeyEvent("get_api_key_usage", nil, "usage", "Failed to get API key usage")
 }
 
 // RevokeAPIKey revokes a specific API key
 func (a *ApiKeyClient) RevokeAPIKey(keyID string) tea.Cmd {
-	return func() tea.Msg {
-		if a.channel == nil {
-			return ErrorMsg{
-				Err:       fmt.Errorf("api_keys channel not joined"),
-				Component: "ApiKey Client",
-			}
-		}
-		
-		params := map[string]any{
-			"api_key_id": keyID,
-		}
-		
-		push, err := a.channel.Push("revoke_api_key", params)
-		if err != nil {
-			return ErrorMsg{
-				Err:       fmt.Errorf("failed to revoke API key: %w", err),
-				Component: "ApiKey Client",
-			}
-		}
-		
-		// Handle responses
-		push.Receive("ok", func(response any) {
-			// Response will be handled by the event handler
-		})
-		
-		push.Receive("error", func(response any) {
-			if a.program != nil {
-				a.program.Send(APIKeyErrorMsg{
-					Operation: "revoke",
-					Message:   "Failed to revoke API key",
-					Details:   fmt.Sprintf("%v", response),
-				})
-			}
-		})
-		
-		push.Receive("timeout", func(response any) {
-			// API key revoke uses channel events for success, ignore push timeout
-		})
-		
-		return nil
+	params := map[string]any{
+		"api_key_id": keyID,
 	}
+	return a.pushAPIKeyEvent("revoke_api_key", params, "revoke", "Failed to revoke API key")
 }
 
 // LeaveChannel leaves the api_keys channel
 func (a *ApiKeyClient) LeaveChannel() {
-	if a.channel != nil {
-		a.channel.Leave()
-		a.channel = nil
-	}
+	a.channels.Leave(apiKeyTopic)
 }
 
 // Helper functions
@@ -358,6 +261,17 @@ func getString(data map[string]any, key string) string {
 	return ""
 }
 
+func getStrings(data map[string]any, key string) []string {
+	values, _ := data[key].([]any)
+	var strs []string
+	for _, value := range values {
+		if str, ok := value.(string); ok {
+			strs = append(strs, str)
+		}
+	}
+	return strs
+}
+
 func getBool(data map[string]any, key string) bool {
 	if val, ok := data[key].(bool); ok {
 		return val
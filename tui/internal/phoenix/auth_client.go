@@ -2,7 +2,6 @@ package phoenix
 
 import (
 	"encoding/json"
-	"fmt"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -38,7 +37,7 @@ func (a *AuthClient) JoinAuthChannel() tea.Cmd {
 	return func() tea.Msg {
 		if a.socket == nil {
 			return ErrorMsg{
-				Err:       fmt.Errorf("socket not connected"),
+				Err:       Errorf(ErrNotConnected, "socket not connected"),
 				Component: "Auth Client",
 			}
 		}
@@ -76,7 +75,7 @@ func (a *AuthClient) JoinAuthChannel() tea.Cmd {
 			a.joining = false
 			if a.program != nil {
 				a.program.Send(ErrorMsg{
-					Err:       fmt.Errorf("failed to join auth channel: %v", response),
+					Err:       Errorf(ErrJoinRejected, "failed to join auth channel: %v", response),
 					Component: "Auth Channel Join",
 				})
 			}
@@ -150,20 +149,43 @@ func (a *AuthClient) setupAuthHandlers(channel *phx.Channel) {
 	channel.On("authenticate_with_api_key_error", func(payload any) {
 		var msg struct {
 			Message string `json:"message"`
-			Details string `json:"details"`
+			Reason  string `json:"reason"`
+			Details any    `json:"details"` // Some servers send an object
 		}
 		if data, err := json.Marshal(payload); err == nil {
 			if err := json.Unmarshal(data, &msg); err == nil {
 				if a.program != nil {
+					details, _ := msg.Details.(string)
+					reason := msg.Reason
+					if detailMap, ok := msg.Details.(map[string]any); ok && reason == "" {
+						reason = getString(detailMap, "reason")
+					}
 					a.program.Send(LoginErrorMsg{
 						Message: msg.Message,
-						Details: msg.Details,
+						Details: details,
+						APIKey:  true,
+						Revoked: revokedReason(reason),
 					})
 				}
 			}
 		}
 	})
 
+	// The session's API key or token was revoked
+	channel.On("credentials_revoked", func(payload any) {
+		var msg struct {
+			Credential string `json:"credential"`
+			Message    string `json:"message"`
+		}
+		if data, err := json.Marshal(payload); err == nil {
+			if err := json.Unmarshal(data, &msg); err == nil {
+				if a.program != nil {
+					a.program.Send(CredentialsRevokedMsg{Credential: msg.Credential, Message: msg.Message})
+				}
+			}
+		}
+	})
+
 	// Logout success
 	channel.On("logout_success", func(payload any) {
 		var msg struct {
@@ -200,6 +222,189 @@ func (a *AuthClient) setupAuthHandlers(channel *phx.Channel) {
 		}
 	})
 
+	// Active sessions listed
+	channel.On("session_list", func(payload any) {
+		var msg struct {
+			Sessions []AuthSession `json:"sessions"`
+		}
+		if data, err := json.Marshal(payload); err == nil {
+			if err := json.Unmarshal(data, &msg); err == nil {
+				if a.program != nil {
+					a.program.Send(SessionListMsg{Sessions: msg.Sessions})
+				}
+			}
+		}
+	})
+
+	// Session revoked
+	channel.On("session_revoked", func(payload any) {
+		var msg struct {
+			SessionID string `json:"session_id"`
+			Message   string `json:"message"`
+		}
+		if data, err := json.Marshal(payload); err == nil {
+			if err := json.Unmarshal(data, &msg); err == nil {
+				if a.program != nil {
+					a.program.Send(SessionRevokedMsg{SessionID: msg.SessionID, Message: msg.Message})
+				}
+			}
+		}
+	})
+
+	// Session operation failed
+	channel.On("session_error", func(payload any) {
+		var msg struct {
+			Operation string `json:"operation"`
+			Message   string `json:"message"`
+		}
+		if data, err := json.Marshal(payload); err == nil {
+			if err := json.Unmarshal(data, &msg); err == nil {
+				if a.program != nil {
+					a.program.Send(SessionErrorMsg{Operation: msg.Operation, Message: msg.Message})
+				}
+			}
+		}
+	})
+
+	// Providers with stored credentials listed
+	channel.On("provider_credentials", func(payload any) {
+		var msg struct {
+			Credentials []ProviderCredential `json:"credentials"`
+		}
+		if data, err := json.Marshal(payload); err == nil {
+			if err := json.Unmarshal(data, &msg); err == nil {
+				if a.program != nil {
+					a.program.Send(ProviderCredentialsMsg{Credentials: msg.Credentials})
+				}
+			}
+		}
+	})
+
+	// Provider credentials stored
+	channel.On("provider_credentials_saved", func(payload any) {
+		var msg struct {
+			Provider string `json:"provider"`
+			Message  string `json:"message"`
+		}
+		if data, err := json.Marshal(payload); err == nil {
+			if err := json.Unmarshal(data, &msg); err == nil {
+				if a.program != nil {
+					a.program.Send(ProviderCredentialsSavedMsg{Provider: msg.Provider, Message: msg.Message})
+				}
+			}
+		}
+	})
+
+	// Provider credentials deleted
+	channel.On("provider_credentials_removed", func(payload any) {
+		var msg struct {
+			Provider string `json:"provider"`
+			Message  string `json:"message"`
+		}
+		if data, err := json.Marshal(payload); err == nil {
+			if err := json.Unmarshal(data, &msg); err == nil {
+				if a.program != nil {
+					a.program.Send(ProviderCredentialsRemovedMsg{Provider: msg.Provider, Message: msg.Message})
+				}
+			}
+		}
+	})
+
+	// Provider pinged with the stored credentials
+	channel.On("provider_credentials_tested", func(payload any) {
+		var msg struct {
+			Provider  string  `json:"provider"`
+			OK        bool    `json:"ok"`
+			LatencyMS float64 `json:"latency_ms"`
+			Message   string  `json:"message"`
+		}
+		if data, err := json.Marshal(payload); err == nil {
+			if err := json.Unmarshal(data, &msg); err == nil {
+				if a.program != nil {
+					a.program.Send(ProviderCredentialsTestedMsg{
+						Provider: msg.Provider,
+						OK:       msg.OK,
+						Latency:  time.Duration(msg.LatencyMS * float64(time.Millisecond)),
+						Message:  msg.Message,
+					})
+				}
+			}
+		}
+	})
+
+	// Provider credentials operation failed
+	channel.On("provider_credentials_error", func(payload any) {
+		var msg struct {
+			Operation string `json:"operation"`
+			Provider  string `json:"provider"`
+			Message   string `json:"message"`
+		}
+		if data, err := json.Marshal(payload); err == nil {
+			if err := json.Unmarshal(data, &msg); err == nil {
+				if a.program != nil {
+					a.program.Send(ProviderCredentialsErrorMsg{Operation: msg.Operation, Provider: msg.Provider, Message: msg.Message})
+				}
+			}
+		}
+	})
+
+	// Account created; the server signs the new account in
+	channel.On("register_success", func(payload any) {
+		var msg struct {
+			User  AuthUser `json:"user"`
+			Token string   `json:"token"`
+		}
+		if data, err := json.Marshal(payload); err == nil {
+			if err := json.Unmarshal(data, &msg); err == nil {
+				if a.program != nil {
+					a.program.Send(RegisterSuccessMsg{User: msg.User, Token: msg.Token})
+				}
+			}
+		}
+	})
+
+	// Registration rejected
+	channel.On("register_error", func(payload any) {
+		var msg struct {
+			Message string `json:"message"`
+		}
+		if data, err := json.Marshal(payload); err == nil {
+			if err := json.Unmarshal(data, &msg); err == nil {
+				if a.program != nil {
+					a.program.Send(RegisterErrorMsg{Message: msg.Message})
+				}
+			}
+		}
+	})
+
+	// Password changed
+	channel.On("password_changed", func(payload any) {
+		var msg struct {
+			Message string `json:"message"`
+		}
+		if data, err := json.Marshal(payload); err == nil {
+			if err := json.Unmarshal(data, &msg); err == nil {
+				if a.program != nil {
+					a.program.Send(PasswordChangedMsg{Message: msg.Message})
+				}
+			}
+		}
+	})
+
+	// Password change rejected
+	channel.On("password_change_error", func(payload any) {
+		var msg struct {
+			Message string `json:"message"`
+		}
+		if data, err := json.Marshal(payload); err == nil {
+			if err := json.Unmarshal(data, &msg); err == nil {
+				if a.program != nil {
+					a.program.Send(PasswordChangeErrorMsg{Message: msg.Message})
+				}
+			}
+		}
+	})
+
 	// Token refreshed
 	channel.On("token_refreshed", func(payload any) {
 		var msg struct {
@@ -224,6 +429,25 @@ func (a *AuthClient) Login(username, password string) tea.Cmd {
 	return a.push("login", map[string]any{
 		"username": username,
 		"password": password,
+		"client":   ClientName(),
+	})
+}
+
+// Register creates an account and signs it in
+func (a *AuthClient) Register(username, email, password string) tea.Cmd {
+	return a.push("register", map[string]any{
+		"username": username,
+		"email":    email,
+		"password": password,
+		"client":   ClientName(),
+	})
+}
+
+// ChangePassword changes the signed-in account's password
+func (a *AuthClient) ChangePassword(currentPassword, newPassword string) tea.Cmd {
+	return a.push("change_password", map[string]any{
+		"current_password": currentPassword,
+		"new_password":     newPassword,
 	})
 }
 
@@ -238,6 +462,43 @@ func (a *AuthClient) GetStatus() tea.Cmd {
 }
 
 
+// ListSessions lists the account's active sessions
+func (a *AuthClient) ListSessions() tea.Cmd {
+	return a.push("list_sessions", map[string]any{})
+}
+
+// RevokeSession signs out another session of the account
+func (a *AuthClient) RevokeSession(sessionID string) tea.Cmd {
+	return a.push("revoke_session", map[string]any{
+		"session_id": sessionID,
+	})
+}
+
+// ListProviderCredentials lists the providers with stored credentials
+func (a *AuthClient) ListProviderCredentials() tea.Cmd {
+	return a.push("list_provider_credentials", map[string]any{})
+}
+
+// SetProviderCredentials stores a provider's credentials on the server,
+// replacing any it had
+func (a *AuthClient) SetProviderCredentials(provider string, fields map[string]string) tea.Cmd {
+	return a.push("set_provider_credentials", map[string]any{
+		"provider":    provider,
+		"credentials": fields,
+	})
+}
+
+// RemoveProviderCredentials deletes a provider's stored credentials
+func (a *AuthClient) RemoveProviderCredentials(provider string) tea.Cmd {
+	return a.push("remove_provider_credentials", map[string]any{"provider": provider})
+}
+
+// TestProviderCredentials has the server ping a provider with its stored
+// credentials
+func (a *AuthClient) TestProviderCredentials(provider string) tea.Cmd {
+	return a.push("test_provider_credentials", map[string]any{"provider": provider})
+}
+
 // RefreshToken refreshes the authentication token
 func (a *AuthClient) RefreshToken() tea.Cmd {
 	return a.push("refresh_token", map[string]any{})
@@ -247,6 +508,7 @@ func (a *AuthClient) RefreshToken() tea.Cmd {
 func (a *AuthClient) AuthenticateWithAPIKey(apiKey string) tea.Cmd {
 	return a.push("authenticate_with_api_key", map[string]any{
 		"api_key": apiKey,
+		"client":  ClientName(),
 	})
 }
 
@@ -255,7 +517,7 @@ func (a *AuthClient) push(event string, payload map[string]any) tea.Cmd {
 	return func() tea.Msg {
 		if a.authChannel == nil {
 			return ErrorMsg{
-				Err:       fmt.Errorf("auth channel not joined"),
+				Err:       Errorf(ErrNotConnected, "auth channel not joined"),
 				Component: "Auth Push",
 			}
 		}
@@ -276,7 +538,7 @@ func (a *AuthClient) push(event string, payload map[string]any) tea.Cmd {
 		push.Receive("error", func(response any) {
 			if a.program != nil {
 				a.program.Send(ErrorMsg{
-					Err:       fmt.Errorf("auth push failed: %v", response),
+					Err:       Errorf(ErrRequestFailed, "auth push failed: %v", response),
 					Component: "Auth Push",
 				})
 			}
@@ -285,14 +547,14 @@ func (a *AuthClient) push(event string, payload map[string]any) tea.Cmd {
 		push.Receive("timeout", func(response any) {
 			// Don't report timeout for login/logout if we're already authenticated
 			// These events use channel events for success, not push replies
-			if event == "login" || event == "logout" || event == "authenticate_with_api_key" {
+			if event == "login" || event == "logout" || event == "authenticate_with_api_key" || event == "register" || event == "change_password" {
 				// These are handled by channel events, ignore push timeout
 				return
 			}
 			
 			if a.program != nil {
 				a.program.Send(ErrorMsg{
-					Err:       fmt.Errorf("Connection timeout for event: %s", event),
+					Err:       Errorf(ErrTimeout, "Connection timeout for event: %s", event),
 					Component: "Auth Push",
 				})
 			}
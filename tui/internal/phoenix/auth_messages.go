@@ -26,6 +26,29 @@ type LoginSuccessMsg struct {
 type LoginErrorMsg struct {
 	Message string
 	Details string
+	APIKey  bool // The saved API key was rejected, not a typed password
+	Revoked bool // The server said the key was revoked or expired
+}
+
+// RegisterSuccessMsg is sent when an account is created and signed in
+type RegisterSuccessMsg struct {
+	User  AuthUser
+	Token string
+}
+
+// RegisterErrorMsg is sent when registration is rejected
+type RegisterErrorMsg struct {
+	Message string
+}
+
+// PasswordChangedMsg is sent when the password has been changed
+type PasswordChangedMsg struct {
+	Message string
+}
+
+// PasswordChangeErrorMsg is sent when a password change is rejected
+type PasswordChangeErrorMsg struct {
+	Message string
 }
 
 // LogoutSuccessMsg is sent when logout succeeds
@@ -47,20 +70,29 @@ type AuthStatusMsg struct {
 
 // APIKeyGeneratedMsg is sent when API key is generated
 type APIKeyGeneratedMsg struct {
-	APIKey  APIKey
-	Warning string
+	APIKey    APIKey
+	Warning   string
+	RequestID string
 }
 
 // APIKeyListMsg contains list of API keys
 type APIKeyListMsg struct {
-	APIKeys []APIKey
-	Count   int
+	APIKeys   []APIKey
+	Count     int
+	RequestID string
+}
+
+// APIKeyUsageMsg carries per-key usage counts
+type APIKeyUsageMsg struct {
+	Usage     []APIKeyUsage
+	RequestID string
 }
 
 // APIKeyRevokedMsg is sent when API key is revoked
 type APIKeyRevokedMsg struct {
-	APIKeyID string
-	Message  string
+	APIKeyID  string
+	Message   string
+	RequestID string
 }
 
 // APIKeyErrorMsg is sent when API key operation fails
@@ -68,6 +100,7 @@ type APIKeyErrorMsg struct {
 	Operation string
 	Message   string
 	Details   string
+	RequestID string
 }
 
 // TokenRefreshedMsg is sent when token is refreshed
@@ -76,6 +109,56 @@ type TokenRefreshedMsg struct {
 	Token string
 }
 
+// SessionListMsg lists the account's active sessions
+type SessionListMsg struct {
+	Sessions []AuthSession
+}
+
+// SessionRevokedMsg is sent when a session is revoked
+type SessionRevokedMsg struct {
+	SessionID string
+	Message   string
+}
+
+// SessionErrorMsg is sent when a session operation fails
+type SessionErrorMsg struct {
+	Operation string
+	Message   string
+}
+
+// ProviderCredentialsMsg lists the providers with stored credentials
+type ProviderCredentialsMsg struct {
+	Credentials []ProviderCredential
+}
+
+// ProviderCredentialsSavedMsg is sent when a provider's credentials are stored
+type ProviderCredentialsSavedMsg struct {
+	Provider string
+	Message  string
+}
+
+// ProviderCredentialsRemovedMsg is sent when a provider's credentials are deleted
+type ProviderCredentialsRemovedMsg struct {
+	Provider string
+	Message  string
+}
+
+// ProviderCredentialsTestedMsg reports whether a provider answered a ping
+// with the stored credentials
+type ProviderCredentialsTestedMsg struct {
+	Provider string
+	OK       bool
+	Latency  time.Duration
+	Message  string
+}
+
+// ProviderCredentialsErrorMsg is sent when a credentials operation fails
+type ProviderCredentialsErrorMsg struct {
+	Operation string
+	Provider  string
+	Message   string
+}
+
 // TokenErrorMsg is sent when token operation fails
 type TokenErrorMsg struct {
 	Message string
@@ -87,13 +170,48 @@ type AuthUser struct {
 	ID       string `json:"id"`
 	Username string `json:"username"`
 	Email    string `json:"email"`
+	// Sent by servers with role-based access; see Grants
+	Roles       []string `json:"roles,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
 }
 
 // APIKey represents an API key
 type APIKey struct {
 	ID        string    `json:"id"`
 	Key       string    `json:"key,omitempty"` // Only present when generated
+	Name      string    `json:"name,omitempty"`
+	Scopes    []string  `json:"scopes,omitempty"` // Empty for keys with the account's full access
 	ExpiresAt time.Time `json:"expires_at"`
 	Valid     bool      `json:"valid"`
 	CreatedAt time.Time `json:"created_at"`
+	// Usage metadata, present when the server tracks it
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	LastIP      string     `json:"last_ip,omitempty"`
+	CreatedFrom string     `json:"created_from,omitempty"` // Client that generated the key
+}
+
+// APIKeyUsage is how much a key has been used
+type APIKeyUsage struct {
+	APIKeyID   string     `json:"api_key_id"`
+	Requests   int        `json:"request_count"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	LastIP     string     `json:"last_ip,omitempty"`
+}
+
+// ProviderCredential is a provider's stored credentials; the server only
+// ever sends them masked
+type ProviderCredential struct {
+	Provider  string            `json:"provider"`
+	Fields    map[string]string `json:"fields"` // Field name to masked value, e.g. "api_key": "sk-…3xQ9"
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// AuthSession is a signed-in client of the account
+type AuthSession struct {
+	ID         string    `json:"id"`
+	Client     string    `json:"client"`
+	IP         string    `json:"ip,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	Current    bool      `json:"current"` // The session this TUI is using
 }
\ No newline at end of file
@@ -0,0 +1,195 @@
+package phoenix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCacheTTLs are how long answers to idempotent queries are reused,
+// by event. Events not listed are never cached
+var DefaultCacheTTLs = map[string]time.Duration{
+	"list_models":         5 * time.Minute,  // Providers and their models
+	"get_provider_status": 15 * time.Second, // Provider health
+	"list_files":          30 * time.Second, // Directory listings
+}
+
+// cacheInvalidations lists the cached events a change makes stale, whether
+// the change was ours or pushed by the server
+var cacheInvalidations = map[string][]string{
+	"write_file":    {"list_files"},
+	"upload_commit": {"list_files"},
+	"file_changed":  {"list_files"},
+}
+
+// cachedResponse is a stored answer
+type cachedResponse struct {
+	event    string
+	response map[string]any
+	storedAt time.Time
+}
+
+// CacheStats counts what the cache holds and how often it answered
+type CacheStats struct {
+	Entries map[string]int // Fresh entries by event
+	Hits    int
+	Misses  int
+}
+
+// ResponseCache keeps answers to idempotent queries for a while, so asking
+// again doesn't cross the channel. It is safe for concurrent use
+type ResponseCache struct {
+	mu      sync.Mutex
+	ttls    map[string]time.Duration
+	entries map[string]cachedResponse
+	hits    int
+	misses  int
+}
+
+// NewResponseCache creates a cache with the default TTLs
+func NewResponseCache() *ResponseCache {
+	c := &ResponseCache{entries: make(map[string]cachedResponse)}
+	c.SetTTLs(nil)
+	return c
+}
+
+// SetTTLs replaces the TTLs; events not given keep their defaults, and a
+// negative TTL stops caching an event
+func (c *ResponseCache) SetTTLs(ttls map[string]time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ttls = make(map[string]time.Duration, len(DefaultCacheTTLs))
+	for event, ttl := range DefaultCacheTTLs {
+		c.ttls[event] = ttl
+	}
+	for event, ttl := range ttls {
+		if ttl != 0 {
+			c.ttls[event] = ttl
+		}
+	}
+}
+
+// cacheKey identifies a query by topic, event, and payload
+func cacheKey(topic, event string, payload map[string]any) string {
+	// Maps marshal with sorted keys, so equal payloads make equal keys
+	data, _ := json.Marshal(payload)
+	return topic + "\x00" + event + "\x00" + string(data)
+}
+
+// get returns a fresh answer to a query. ok is false for events that
+// aren't cached as well as for misses
+func (c *ResponseCache) get(key, event string) (response map[string]any, cacheable, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ttl := c.ttls[event]
+	if ttl <= 0 {
+		return nil, false, false
+	}
+	entry, found := c.entries[key]
+	if !found || time.Since(entry.storedAt) > ttl {
+		delete(c.entries, key)
+		c.misses++
+		return nil, true, false
+	}
+	c.hits++
+	return entry.response, true, true
+}
+
+// put stores an answer
+func (c *ResponseCache) put(key, event string, response map[string]any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = cachedResponse{event: event, response: response, storedAt: time.Now()}
+}
+
+// Clear drops the answers to the given events, or every answer when none
+// are given, and returns how many were dropped
+func (c *ResponseCache) Clear(events ...string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	dropped := 0
+	for key, entry := range c.entries {
+		if len(events) == 0 || slices.Contains(events, entry.event) {
+			delete(c.entries, key)
+			dropped++
+		}
+	}
+	return dropped
+}
+
+// Stats returns the fresh entries by event and the hit and miss counts
+func (c *ResponseCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := CacheStats{Entries: make(map[string]int), Hits: c.hits, Misses: c.misses}
+	for _, entry := range c.entries {
+		if time.Since(entry.storedAt) <= c.ttls[entry.event] {
+			stats.Entries[entry.event]++
+		}
+	}
+	return stats
+}
+
+// TTL returns how long an event's answers are kept, 0 when they aren't
+func (c *ResponseCache) TTL(event string) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return max(c.ttls[event], 0)
+}
+
+// Format describes the cache for /cache
+func (s CacheStats) Format(cache *ResponseCache) string {
+	var b strings.Builder
+	events := make([]string, 0, len(DefaultCacheTTLs))
+	for event := range DefaultCacheTTLs {
+		events = append(events, event)
+	}
+	sort.Strings(events)
+	b.WriteString("Cached server queries:\n")
+	for _, event := range events {
+		ttl := "off"
+		if d := cache.TTL(event); d > 0 {
+			ttl = d.String()
+		}
+		fmt.Fprintf(&b, "  %-20s %3d fresh  (kept %s)\n", event, s.Entries[event], ttl)
+	}
+	total := s.Hits + s.Misses
+	if total > 0 {
+		fmt.Fprintf(&b, "\n%d of %d queries answered from the cache (%.0f%%)", s.Hits, total, float64(s.Hits)*100/float64(total))
+	} else {
+		b.WriteString("\nNo cacheable queries yet")
+	}
+	return b.String()
+}
+
+// Cache returns the client's cache of idempotent query answers
+func (c *Client) Cache() *ResponseCache {
+	return c.cache
+}
+
+// cachedRequest is Request answered from the cache when it holds a fresh
+// answer, storing the server's answer otherwise
+func (c *Client) cachedRequest(ctx context.Context, event string, payload map[string]any) (map[string]any, error) {
+	key := cacheKey(c.conversationTopic(), event, payload)
+	response, cacheable, ok := c.cache.get(key, event)
+	if ok {
+		return response, nil
+	}
+	response, err := c.Request(ctx, event, payload)
+	if err == nil && cacheable {
+		c.cache.put(key, event, response)
+	}
+	return response, err
+}
+
+// invalidate drops cached answers a change made stale
+func (c *Client) invalidate(event string) {
+	if stale := cacheInvalidations[event]; len(stale) > 0 {
+		c.cache.Clear(stale...)
+	}
+}
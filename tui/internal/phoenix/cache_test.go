@@ -0,0 +1,58 @@
+package phoenix
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResponseCache(t *testing.T) {
+	cache := NewResponseCache()
+	cache.SetTTLs(map[string]time.Duration{"get_provider_status": -1})
+
+	files := cacheKey("conversation:lobby", "list_files", map[string]any{"path": "."})
+	if _, cacheable, ok := cache.get(files, "list_files"); !cacheable || ok {
+		t.Fatal("Expected a miss for a listing not yet asked for")
+	}
+	cache.put(files, "list_files", map[string]any{"files": []any{}})
+	if _, _, ok := cache.get(cacheKey("conversation:lobby", "list_files", map[string]any{"path": "."}), "list_files"); !ok {
+		t.Error("Expected the same query to hit")
+	}
+	if _, _, ok := cache.get(cacheKey("conversation:lobby", "list_files", map[string]any{"path": "lib"}), "list_files"); ok {
+		t.Error("Expected another directory to miss")
+	}
+	if _, cacheable, _ := cache.get(cacheKey("conversation:lobby", "get_provider_status", nil), "get_provider_status"); cacheable {
+		t.Error("Expected a negative TTL to turn caching off")
+	}
+	if _, cacheable, _ := cache.get(cacheKey("conversation:lobby", "write_file", nil), "write_file"); cacheable {
+		t.Error("Expected writes never to be cached")
+	}
+
+	// Stale answers are dropped
+	cache.entries[files] = cachedResponse{event: "list_files", storedAt: time.Now().Add(-time.Hour)}
+	if _, _, ok := cache.get(files, "list_files"); ok {
+		t.Error("Expected a stale answer to miss")
+	}
+
+	cache.put(files, "list_files", nil)
+	cache.put("models", "list_models", nil)
+	if dropped := cache.Clear("list_files"); dropped != 1 || cache.Stats().Entries["list_models"] != 1 {
+		t.Errorf("Expected only listings cleared, dropped %d: %+v", dropped, cache.Stats())
+	}
+	if dropped := cache.Clear(); dropped != 1 {
+		t.Errorf("Expected clearing everything to drop the models, dropped %d", dropped)
+	}
+	if stats := cache.Stats(); stats.Hits != 1 || stats.Misses != 3 {
+		t.Errorf("Unexpected counts: %+v", stats)
+	}
+}
+
+func TestFileChangedDropsCachedListings(t *testing.T) {
+	client := NewClient()
+	files := cacheKey("conversation:lobby", "list_files", map[string]any{"path": "."})
+	client.cache.put(files, "list_files", map[string]any{"files": []any{}})
+
+	client.channelHandlers("conversation:lobby")["file_changed"](map[string]any{"path": "new.ex", "event": "created"})
+	if _, _, ok := client.cache.get(files, "list_files"); ok {
+		t.Error("Expected a server file change to drop cached listings")
+	}
+}
@@ -0,0 +1,144 @@
+package phoenix
+
+import (
+	"sort"
+	"strings"
+)
+
+// ClientVersion is the TUI version sent when joining the conversation channel
+var ClientVersion = "0.1.0"
+
+// ClientName identifies the TUI to the server, e.g. in API key and session lists
+func ClientName() string {
+	return "rubber_duck_tui/" + ClientVersion
+}
+
+// ProtocolVersion is the channel protocol this client speaks
+const ProtocolVersion = "1"
+
+// Capabilities the client can negotiate with the server
+const (
+	CapabilityStreaming = "streaming"
+	CapabilityPlanning  = "planning"
+	CapabilityAPIKeys   = "api_keys"
+	CapabilityFiles     = "files"
+	CapabilitySearch    = "search"
+)
+
+// CapabilityResume is offered by servers that can switch the channel back to
+// an earlier conversation
+const CapabilityResume = "resume"
+
+// CapabilityFormat is offered by servers that can format source code
+const CapabilityFormat = "format"
+
+// CapabilityPullRequests is offered by servers that can open a branch and
+// pull request from a patch
+const CapabilityPullRequests = "pull_requests"
+
+// CapabilityReact is offered by servers that stream ReAct executions and
+// accept pause, resume, and abort
+const CapabilityReact = "react"
+
+// CapabilityModels is offered by servers that list the models their
+// providers make available
+const CapabilityModels = "models"
+
+// CapabilityProviderStatus is offered by servers that report the health of
+// each of their providers
+const CapabilityProviderStatus = "provider_status"
+
+// OptionalCapabilities are used when the server offers them but aren't
+// asked for in the handshake or reported as missing
+var OptionalCapabilities = []string{CapabilityResume, CapabilityFormat, CapabilityPullRequests, CapabilityReact, CapabilityModels, CapabilityProviderStatus, CapabilityChunkedTransfer}
+
+// ClientCapabilities are the features this client supports
+var ClientCapabilities = []string{
+	CapabilityStreaming,
+	CapabilityPlanning,
+	CapabilityAPIKeys,
+	CapabilityFiles,
+	CapabilitySearch,
+}
+
+// Capabilities is the feature set negotiated with the server. A nil set
+// means the server predates negotiation, so every feature is assumed
+type Capabilities map[string]bool
+
+// Has reports whether a feature was negotiated
+func (c Capabilities) Has(capability string) bool {
+	return c == nil || c[capability]
+}
+
+// Missing returns the client capabilities the server did not accept, sorted
+func (c Capabilities) Missing() []string {
+	var missing []string
+	for _, capability := range ClientCapabilities {
+		if !c.Has(capability) {
+			missing = append(missing, capability)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// Negotiation is the server's answer to the join handshake
+type Negotiation struct {
+	ServerVersion   string
+	ProtocolVersion string
+	Capabilities    Capabilities
+	InstanceID      string // Identifies the server install, for servers reached without TLS
+}
+
+// Compatible reports whether the server speaks this client's protocol;
+// servers that don't report a version are assumed compatible
+func (n Negotiation) Compatible() bool {
+	return n.ProtocolVersion == "" || n.ProtocolVersion == ProtocolVersion
+}
+
+// HandshakeParams are the conversation join params announcing the client's
+// version and capabilities. phx join params are strings, so the capability
+// list is comma-separated
+func HandshakeParams() map[string]string {
+	return map[string]string{
+		"client_version":   ClientVersion,
+		"protocol_version": ProtocolVersion,
+		"capabilities":     strings.Join(ClientCapabilities, ","),
+	}
+}
+
+// ParseNegotiation reads the handshake fields from a join response. The
+// negotiated set is what both sides support
+func ParseNegotiation(response any) Negotiation {
+	var n Negotiation
+	data, ok := response.(map[string]any)
+	if !ok {
+		return n
+	}
+	n.ServerVersion, _ = data["server_version"].(string)
+	n.ProtocolVersion, _ = data["protocol_version"].(string)
+	n.InstanceID, _ = data["instance_id"].(string)
+
+	features, ok := data["capabilities"].([]any)
+	if !ok {
+		return n
+	}
+	server := make(map[string]bool, len(features))
+	for _, feature := range features {
+		if name, ok := feature.(string); ok {
+			server[name] = true
+		}
+	}
+	n.Capabilities = Capabilities{}
+	for _, capability := range ClientCapabilities {
+		if server[capability] {
+			n.Capabilities[capability] = true
+		}
+	}
+	for _, capability := range OptionalCapabilities {
+		if server[capability] {
+			n.Capabilities[capability] = true
+		}
+	}
+	return n
+}
@@ -0,0 +1,35 @@
+package phoenix
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseNegotiation(t *testing.T) {
+	n := ParseNegotiation(map[string]any{
+		"server_version":   "2.3.0",
+		"protocol_version": "1",
+		"capabilities":     []any{"streaming", "files", "telepathy"},
+	})
+
+	if n.ServerVersion != "2.3.0" || !n.Compatible() {
+		t.Errorf("Unexpected negotiation: %+v", n)
+	}
+	if !n.Capabilities.Has(CapabilityStreaming) || n.Capabilities.Has(CapabilityPlanning) || n.Capabilities.Has("telepathy") {
+		t.Errorf("Expected only capabilities both sides support, got %v", n.Capabilities)
+	}
+	if want := []string{CapabilityAPIKeys, CapabilityPlanning, CapabilitySearch}; !reflect.DeepEqual(n.Capabilities.Missing(), want) {
+		t.Errorf("Expected missing %v, got %v", want, n.Capabilities.Missing())
+	}
+}
+
+func TestParseNegotiationLegacyServer(t *testing.T) {
+	n := ParseNegotiation(map[string]any{"conversation_id": "abc"})
+
+	if !n.Compatible() || !n.Capabilities.Has(CapabilityPlanning) || len(n.Capabilities.Missing()) != 0 {
+		t.Errorf("Expected a server without a handshake to keep every feature, got %+v", n)
+	}
+	if ParseNegotiation(map[string]any{"protocol_version": "2"}).Compatible() {
+		t.Error("Expected a different protocol version to be incompatible")
+	}
+}
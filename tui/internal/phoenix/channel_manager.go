@@ -0,0 +1,440 @@
+package phoenix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nshafer/phx"
+)
+
+// ChannelManager owns the user socket and every channel joined on it. Each
+// channel client registers a ChannelSpec; when a reconnect replaces the
+// socket, every registered channel is joined again on the new one. All
+// channel messages reach the UI through Send. It is safe for concurrent use:
+// tea.Cmds, socket callbacks, and channel handlers all run on their own
+// goroutines
+type ChannelManager struct {
+	// joinMu serializes changes to the socket and registrations so a topic
+	// is never joined twice; mu guards the fields below
+	joinMu    sync.Mutex
+	mu        sync.Mutex
+	socket    *phx.Socket
+	program   *tea.Program
+	onMessage func(tea.Msg)
+	channels  map[string]*managedChannel
+	scheduler *Scheduler
+	streams   *StreamCoalescer
+}
+
+// ChannelSpec describes a channel to join and the events it handles
+type ChannelSpec struct {
+	Topic     string
+	Params    map[string]string
+	Component string                                           // Names the channel in errors
+	Handlers  map[string]func(payload any)                     // Server events by name
+	OnJoin    func(channel *phx.Channel, response any) tea.Msg // Message sent after each successful join
+}
+
+// PushOptions controls how replies to a push are reported
+type PushOptions struct {
+	OnReply       func(response any) tea.Msg // Message for an ok reply; nil ignores it
+	OnError       func(response any) tea.Msg // Message for an error reply; nil sends an ErrorMsg
+	ReportTimeout bool                       // Send an ErrorMsg when no reply arrives
+	NoReply       bool                       // Ignore replies; results arrive as channel events
+}
+
+// managedChannel is a registered spec and its channel on the current socket
+type managedChannel struct {
+	spec    ChannelSpec
+	channel *phx.Channel
+}
+
+// NewChannelManager creates a manager without a socket
+func NewChannelManager() *ChannelManager {
+	m := &ChannelManager{channels: make(map[string]*managedChannel), scheduler: NewScheduler()}
+	m.streams = NewStreamCoalescer(StreamFlushInterval, m.deliver)
+	m.scheduler.OnChange(sendQueueChanges(m))
+	return m
+}
+
+// Scheduler returns the scheduler limiting concurrent pushes
+func (m *ChannelManager) Scheduler() *Scheduler {
+	return m.scheduler
+}
+
+// SetProgram sets the tea.Program that receives channel messages
+func (m *ChannelManager) SetProgram(program *tea.Program) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.program = program
+}
+
+// OnMessage delivers messages to fn instead of the program, for tests
+func (m *ChannelManager) OnMessage(fn func(tea.Msg)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onMessage = fn
+}
+
+// Send delivers a message to the UI; it is dropped when no program is set.
+// Stream chunks are coalesced, reaching the UI every StreamFlushInterval
+func (m *ChannelManager) Send(msg tea.Msg) {
+	if msg == nil {
+		return
+	}
+	m.streams.Send(msg)
+}
+
+// deliver hands a message to the program or the test hook
+func (m *ChannelManager) deliver(msg tea.Msg) {
+	m.mu.Lock()
+	program, onMessage := m.program, m.onMessage
+	m.mu.Unlock()
+
+	switch {
+	case onMessage != nil:
+		onMessage(msg)
+	case program != nil:
+		program.Send(msg)
+	}
+}
+
+// UnroutedEventMsg reports a server event no channel handler took, so
+// protocol drift between server and client shows up instead of being dropped
+type UnroutedEventMsg struct {
+	Topic   string
+	Event   string
+	Reason  string
+	Payload json.RawMessage
+}
+
+// reportUnrouted checks a socket message against the registered channels
+// and reports it when nothing handles it. Replies, heartbeats, and other
+// protocol events are handled by phx itself
+func (m *ChannelManager) reportUnrouted(msg phx.Message) {
+	if msg.Topic == "phoenix" || strings.HasPrefix(msg.Event, "phx_") {
+		return
+	}
+	m.mu.Lock()
+	mc, ok := m.channels[msg.Topic]
+	m.mu.Unlock()
+
+	reason := ""
+	switch {
+	case !ok:
+		reason = "no channel joined for this topic"
+	case mc.spec.Handlers[msg.Event] == nil:
+		reason = "no handler for this event"
+	default:
+		return
+	}
+	payload, _ := json.Marshal(msg.Payload)
+	m.Send(UnroutedEventMsg{Topic: msg.Topic, Event: msg.Event, Reason: reason, Payload: payload})
+}
+
+// Socket returns the current socket, or nil before connecting
+func (m *ChannelManager) Socket() *phx.Socket {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.socket
+}
+
+// SetSocket adopts a newly connected socket and rejoins every registered
+// channel on it
+func (m *ChannelManager) SetSocket(socket *phx.Socket) {
+	m.joinMu.Lock()
+	defer m.joinMu.Unlock()
+
+	m.mu.Lock()
+	if m.socket == socket {
+		m.mu.Unlock()
+		return
+	}
+	m.socket = socket
+	var stale []*phx.Channel
+	channels := make([]*managedChannel, 0, len(m.channels))
+	for _, mc := range m.channels {
+		if mc.channel != nil {
+			stale = append(stale, mc.channel)
+		}
+		mc.channel = nil
+		channels = append(channels, mc)
+	}
+	m.mu.Unlock()
+
+	for _, channel := range stale {
+		leaveChannel(channel)
+	}
+
+	if socket == nil {
+		return
+	}
+	sort.Slice(channels, func(i, j int) bool { return channels[i].spec.Topic < channels[j].spec.Topic })
+	for _, mc := range channels {
+		if err := m.join(mc); err != nil {
+			m.Send(ErrorMsg{Err: Errorf(ErrJoinFailed, "failed to rejoin %s: %w", mc.spec.Topic, err), Component: mc.spec.Component})
+		}
+	}
+}
+
+// Join registers a channel and joins it. A topic that is already joined or
+// joining on the current socket is left alone; any other registration for
+// the topic is left and replaced
+func (m *ChannelManager) Join(spec ChannelSpec) tea.Cmd {
+	return func() tea.Msg {
+		m.joinMu.Lock()
+		defer m.joinMu.Unlock()
+
+		if m.Socket() == nil {
+			return ErrorMsg{Err: Errorf(ErrNotConnected, "socket not connected"), Component: spec.Component}
+		}
+		if channel := m.Channel(spec.Topic); channel != nil && (channel.IsJoined() || channel.IsJoining()) {
+			return nil
+		}
+
+		m.leave(spec.Topic)
+		mc := &managedChannel{spec: spec}
+		m.mu.Lock()
+		m.channels[spec.Topic] = mc
+		m.mu.Unlock()
+
+		if err := m.join(mc); err != nil {
+			return ErrorMsg{Err: Errorf(ErrJoinFailed, "failed to join %s: %w", spec.Topic, err), Component: spec.Component}
+		}
+		return nil
+	}
+}
+
+// join creates the channel on the current socket and joins it
+func (m *ChannelManager) join(mc *managedChannel) error {
+	socket := m.Socket()
+	if socket == nil {
+		return Errorf(ErrNotConnected, "socket not connected")
+	}
+	spec := mc.spec
+
+	channel := socket.Channel(spec.Topic, spec.Params)
+	for event, handler := range spec.Handlers {
+		channel.On(event, handler)
+	}
+
+	join, err := channel.Join()
+	if err != nil {
+		return err
+	}
+	join.Receive("ok", func(response any) {
+		if spec.OnJoin != nil {
+			m.Send(spec.OnJoin(channel, response))
+		}
+	})
+	join.Receive("error", func(response any) {
+		m.Send(ErrorMsg{Err: joinRejection(spec.Topic, response), Component: spec.Component})
+	})
+	join.Receive("timeout", func(response any) {
+		m.Send(ErrorMsg{Err: Errorf(ErrTimeout, "%s join timeout", spec.Topic), Component: spec.Component})
+	})
+
+	m.mu.Lock()
+	mc.channel = channel
+	m.mu.Unlock()
+	return nil
+}
+
+// Leave leaves a channel and stops rejoining it
+func (m *ChannelManager) Leave(topic string) {
+	m.joinMu.Lock()
+	defer m.joinMu.Unlock()
+	m.leave(topic)
+}
+
+// leave unregisters a topic; the caller holds joinMu
+func (m *ChannelManager) leave(topic string) {
+	m.mu.Lock()
+	mc, ok := m.channels[topic]
+	delete(m.channels, topic)
+	m.mu.Unlock()
+
+	if ok && mc.channel != nil {
+		leaveChannel(mc.channel)
+	}
+}
+
+// leaveChannel leaves and removes a channel so the topic can be joined again
+func leaveChannel(channel *phx.Channel) {
+	if channel.IsJoined() || channel.IsJoining() {
+		channel.Leave()
+	}
+	channel.Remove()
+}
+
+// Registered reports whether a topic is registered, joined or not
+func (m *ChannelManager) Registered(topic string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.channels[topic]
+	return ok
+}
+
+// Channel returns the channel for a topic, or nil when it isn't joined
+func (m *ChannelManager) Channel(topic string) *phx.Channel {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if mc, ok := m.channels[topic]; ok {
+		return mc.channel
+	}
+	return nil
+}
+
+// Topics returns the registered topics, sorted
+func (m *ChannelManager) Topics() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	topics := make([]string, 0, len(m.channels))
+	for topic := range m.channels {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+	return topics
+}
+
+// component returns the error component registered for a topic
+func (m *ChannelManager) component(topic string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if mc, ok := m.channels[topic]; ok && mc.spec.Component != "" {
+		return mc.spec.Component
+	}
+	return "Phoenix Push"
+}
+
+// Push sends an event on a joined channel, reporting replies as opts says.
+// Map payloads get a correlation ID, reported in a RequestSentMsg once the
+// push is sent. Pushes wait their turn when their category is at its limit;
+// one without a reply holds its slot until Scheduler().Finish is called
+// with its request ID
+func (m *ChannelManager) Push(topic, event string, payload any, opts PushOptions) tea.Cmd {
+	return func() tea.Msg {
+		component := m.component(topic)
+		slot, err := m.scheduler.acquire(context.Background(), CommandCategory(topic, event), event, commandLabel(payload))
+		if err != nil {
+			return ErrorMsg{Err: err, Component: component}
+		}
+		var once sync.Once
+		release := func() { once.Do(func() { m.scheduler.release(slot) }) }
+
+		channel := m.Channel(topic)
+		if channel == nil {
+			release()
+			return ErrorMsg{Err: Errorf(ErrNotConnected, "%s channel not joined", topic), Component: component}
+		}
+
+		tagged, requestID := withRequestID(payload)
+		push, err := channel.Push(event, tagged)
+		if err != nil {
+			release()
+			return ErrorMsg{Err: err, Component: component, RequestID: requestID}
+		}
+		sent := RequestSentMsg{RequestID: requestID, Topic: topic, Event: event, Payload: payload}
+		if opts.NoReply {
+			m.scheduler.hold(slot, requestID)
+			return sent
+		}
+
+		push.Receive("ok", func(response any) {
+			release()
+			if opts.OnReply != nil {
+				m.Send(opts.OnReply(response))
+			}
+		})
+		push.Receive("error", func(response any) {
+			release()
+			if opts.OnError != nil {
+				m.Send(opts.OnError(response))
+				return
+			}
+			m.Send(ErrorMsg{Err: Errorf(ErrRequestFailed, "%s failed: %v", event, response), Component: component, RequestID: requestID})
+		})
+		push.Receive("timeout", func(response any) {
+			release()
+			if opts.ReportTimeout {
+				m.Send(ErrorMsg{Err: Errorf(ErrTimeout, "Connection timeout for event: %s", event), Component: component, RequestID: requestID})
+			}
+		})
+		return sent
+	}
+}
+
+// Request pushes an event and waits for its reply until ctx is done
+func (m *ChannelManager) Request(ctx context.Context, topic, event string, payload map[string]any) (map[string]any, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", event, err)
+	}
+	slot, err := m.scheduler.acquire(ctx, CommandCategory(topic, event), event, commandLabel(payload))
+	if err != nil {
+		return nil, fmt.Errorf("%s: waiting in the command queue: %w", event, err)
+	}
+	defer m.scheduler.release(slot)
+
+	channel := m.Channel(topic)
+	if channel == nil {
+		return nil, Errorf(ErrNotConnected, "channel not joined")
+	}
+
+	tagged, _ := withRequestID(payload)
+	push, err := channel.Push(event, tagged)
+	if err != nil {
+		return nil, err
+	}
+
+	type reply struct {
+		response map[string]any
+		err      error
+	}
+	replies := make(chan reply, 1)
+
+	push.Receive("ok", func(response any) {
+		data, _ := response.(map[string]any)
+		replies <- reply{response: data}
+	})
+	push.Receive("error", func(response any) {
+		replies <- reply{err: Errorf(ErrRequestFailed, "%s failed: %v", event, response)}
+	})
+
+	select {
+	case r := <-replies:
+		return r.response, r.err
+	case <-ctx.Done():
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, Errorf(ErrTimeout, "%s timed out", event)
+		}
+		return nil, fmt.Errorf("%s: %w", event, ctx.Err())
+	}
+}
+
+// Close leaves every channel, forgets them, and disconnects the socket
+func (m *ChannelManager) Close() {
+	m.joinMu.Lock()
+	defer m.joinMu.Unlock()
+
+	m.mu.Lock()
+	socket := m.socket
+	channels := m.channels
+	m.socket = nil
+	m.channels = make(map[string]*managedChannel)
+	m.mu.Unlock()
+	m.scheduler.Reset()
+
+	for _, mc := range channels {
+		if mc.channel != nil {
+			leaveChannel(mc.channel)
+		}
+	}
+	if socket != nil {
+		socket.Disconnect()
+	}
+}
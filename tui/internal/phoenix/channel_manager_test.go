@@ -0,0 +1,95 @@
+package phoenix
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nshafer/phx"
+)
+
+func TestChannelManagerSendWithoutProgram(t *testing.T) {
+	channels := NewChannelManager()
+
+	// No program yet: messages are dropped instead of panicking
+	channels.Send(ConnectedMsg{})
+
+	var got []tea.Msg
+	channels.OnMessage(func(msg tea.Msg) { got = append(got, msg) })
+	channels.Send(ConnectedMsg{})
+	channels.Send(nil)
+	if len(got) != 1 {
+		t.Errorf("Expected one delivered message, got %v", got)
+	}
+}
+
+func TestChannelManagerUnjoinedTopic(t *testing.T) {
+	channels := NewChannelManager()
+
+	if msg, ok := channels.Join(ChannelSpec{Topic: "status:1", Component: "StatusClient"})().(ErrorMsg); !ok || msg.Component != "StatusClient" {
+		t.Errorf("Expected a StatusClient error joining without a socket, got %v", msg)
+	}
+	if channels.Registered("status:1") {
+		t.Error("Expected a failed join not to register the topic")
+	}
+	if _, ok := channels.Push("status:1", "get_subscriptions", nil, PushOptions{})().(ErrorMsg); !ok {
+		t.Error("Expected an error pushing to an unjoined topic")
+	}
+}
+
+func TestChannelManagerRequestHonorsContext(t *testing.T) {
+	channels := NewChannelManager()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := channels.Request(ctx, "conversation:lobby", "list_files", nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestClientConcurrentUse(t *testing.T) {
+	client := NewClient()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client.SetProgram(nil)
+			client.JoinChannel("conversation:lobby")()
+			client.Push("set_context", nil)()
+			client.SendMessage("hello")()
+			client.Channels().Send(ConnectedMsg{})
+			client.Channels().Topics()
+			client.Disconnect()()
+		}()
+	}
+	wg.Wait()
+}
+
+func TestChannelManagerReportsUnroutedEvents(t *testing.T) {
+	channels := NewChannelManager()
+	channels.channels["conversation:lobby"] = &managedChannel{spec: ChannelSpec{
+		Topic:    "conversation:lobby",
+		Handlers: map[string]func(any){"response": func(any) {}},
+	}}
+	var got []UnroutedEventMsg
+	channels.OnMessage(func(msg tea.Msg) { got = append(got, msg.(UnroutedEventMsg)) })
+
+	channels.reportUnrouted(phx.Message{Topic: "conversation:lobby", Event: "response"})
+	channels.reportUnrouted(phx.Message{Topic: "conversation:lobby", Event: "phx_reply"})
+	channels.reportUnrouted(phx.Message{Topic: "phoenix", Event: "heartbeat"})
+	channels.reportUnrouted(phx.Message{Topic: "conversation:lobby", Event: "tool_progress", Payload: map[string]any{"step": 1}})
+	channels.reportUnrouted(phx.Message{Topic: "metrics:live", Event: "sample"})
+
+	if len(got) != 2 {
+		t.Fatalf("Expected two unrouted events, got %+v", got)
+	}
+	if got[0].Event != "tool_progress" || got[0].Reason != "no handler for this event" || string(got[0].Payload) != `{"step":1}` {
+		t.Errorf("Unexpected unhandled event report: %+v", got[0])
+	}
+	if got[1].Topic != "metrics:live" || got[1].Reason != "no channel joined for this topic" {
+		t.Errorf("Unexpected unjoined topic report: %+v", got[1])
+	}
+}
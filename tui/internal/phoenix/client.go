@@ -1,20 +1,30 @@
 package phoenix
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"net/url"
+	"sync"
 	"time"
 	
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/nshafer/phx"
 )
 
-// Client represents a Phoenix WebSocket client
+// fileRequestTimeout bounds how long file API requests may block the UI
+const fileRequestTimeout = 5 * time.Second
+
+// searchRequestTimeout bounds server-side project searches
+const searchRequestTimeout = 15 * time.Second
+
+// Client represents a Phoenix WebSocket client. It is safe for concurrent
+// use; messages reach the program only through its channel manager
 type Client struct {
-	socket   *phx.Socket
-	channel  *phx.Channel
-	program  *tea.Program
+	channels *ChannelManager
+	traffic  *Traffic
+	cache    *ResponseCache // Answers to idempotent queries
+	mu       sync.RWMutex   // Guards topic and apiKey
+	topic    string
 	apiKey   string
 }
 
@@ -25,21 +35,46 @@ type Config struct {
 	JWTToken string
 	Channel  string
 	IsAuth   bool // true if connecting to auth socket
+	Network  NetworkOptions
+
+	// Fingerprint is the certificate pinned for a wss:// URL; empty trusts
+	// the first one presented
+	Fingerprint string
 }
 
 // NewClient creates a new Phoenix client
 func NewClient() *Client {
-	return &Client{}
+	return &Client{channels: NewChannelManager(), traffic: NewTraffic(), cache: NewResponseCache()}
+}
+
+// Traffic returns the byte and message counts of the client's sockets
+func (c *Client) Traffic() *Traffic {
+	return c.traffic
 }
 
 // SetProgram sets the tea.Program for sending messages
 func (c *Client) SetProgram(program *tea.Program) {
-	c.program = program
+	c.channels.SetProgram(program)
+}
+
+// Channels returns the manager for channels on the user socket, shared by
+// the status, API key, and planning clients
+func (c *Client) Channels() *ChannelManager {
+	return c.channels
+}
+
+// conversationTopic returns the topic pushes are sent on
+func (c *Client) conversationTopic() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.topic
 }
 
 // Connect establishes a WebSocket connection to Phoenix
 func (c *Client) Connect(config Config) tea.Cmd {
+	c.mu.Lock()
 	c.apiKey = config.APIKey
+	c.mu.Unlock()
 	
 	return func() tea.Msg {
 		// Parse the WebSocket URL
@@ -62,8 +97,29 @@ func (c *Client) Connect(config Config) tea.Cmd {
 			}
 		}
 		
+		socketType := UserSocketType
+		if config.IsAuth {
+			socketType = AuthSocketType
+		}
+		
+		// Dial through the configured proxy and TLS settings
+		dialer, err := config.Network.Dialer()
+		if err != nil {
+			return DisconnectedMsg{Error: err, SocketType: socketType}
+		}
+		dialer.NetDialContext = c.traffic.meter(dialer.NetDialContext)
+		if endPoint.Scheme == "wss" {
+			dialer.TLSClientConfig = pinCertificate(dialer.TLSClientConfig, endPoint.Host, config.Fingerprint, func(msg ServerFingerprintMsg) {
+				c.channels.Send(msg)
+			})
+		}
+		
 		// Create the socket
 		socket := phx.NewSocket(endPoint)
+		transport := phx.NewWebsocket(socket)
+		transport.Dialer = dialer
+		socket.Transport = transport
+		socket.HeartbeatInterval = config.Network.heartbeatInterval()
 		// Use silent logger to prevent console spam
 		socket.Logger = NewSilentLogger()
 		
@@ -74,230 +130,497 @@ func (c *Client) Connect(config Config) tea.Cmd {
 		}
 		
 		// Set up event handlers
-		socketType := UserSocketType
-		if config.IsAuth {
-			socketType = AuthSocketType
-		}
-		
 		socket.OnOpen(func() {
-			if c.program != nil {
-				c.program.Send(ConnectedMsg{SocketType: socketType})
-			}
+			c.channels.Send(ConnectedMsg{SocketType: socketType})
 		})
 		
 		socket.OnClose(func() {
-			if c.program != nil {
-				c.program.Send(DisconnectedMsg{Error: nil, SocketType: socketType})
-			}
+			c.channels.Send(DisconnectedMsg{Error: nil, SocketType: socketType})
 		})
 		
+		// A refused handshake is told apart from an unreachable server, so
+		// revoked credentials aren't retried
+		credentialed := !config.IsAuth && (config.APIKey != "" || config.JWTToken != "")
 		socket.OnError(func(err error) {
-			if c.program != nil {
-				c.program.Send(DisconnectedMsg{Error: err, SocketType: socketType})
+			if credentialed {
+				err = handshakeRejection(err, dialer, endPoint)
 			}
+			c.channels.Send(DisconnectedMsg{Error: err, SocketType: socketType})
 		})
 		
+		socket.OnMessage(c.traffic.countMessage)
+		
+		// Events no channel handles are reported instead of dropped
+		if !config.IsAuth {
+			socket.OnMessage(c.channels.reportUnrouted)
+		}
+		
 		// Connect to the socket
 		if err := socket.Connect(); err != nil {
 			return DisconnectedMsg{Error: err, SocketType: socketType}
 		}
 		
-		c.socket = socket
+		// The user socket carries the channels; a new one rejoins them
+		if !config.IsAuth {
+			c.channels.SetSocket(socket)
+		}
 		return SocketCreatedMsg{Socket: socket}
 	}
 }
 
 // JoinChannel joins a Phoenix channel
 func (c *Client) JoinChannel(topic string) tea.Cmd {
-	return func() tea.Msg {
-		if c.socket == nil {
-			return ErrorMsg{
-				Err:       fmt.Errorf("socket not connected"),
-				Component: "Phoenix Client",
-			}
-		}
-		
-		// Create channel with proper params
-		// Note: nshafer/phx expects map[string]string, so we need to serialize complex data
-		params := map[string]string{}
-		channel := c.socket.Channel(topic, params)
-		
-		// Join the channel
-		join, err := channel.Join()
-		if err != nil {
-			return ErrorMsg{
-				Err:       err,
-				Component: "Phoenix Channel Join",
-			}
-		}
-		
-		// Handle join response
-		join.Receive("ok", func(response any) {
-			c.program.Send(ChannelJoinedMsg{
+	c.mu.Lock()
+	c.topic = topic
+	c.mu.Unlock()
+	// Join with the version and capability handshake
+	// Note: nshafer/phx expects map[string]string, so we need to serialize complex data
+	join := c.channels.Join(ChannelSpec{
+		Topic:     topic,
+		Params:    HandshakeParams(),
+		Component: "Phoenix Channel Join",
+		Handlers:  c.channelHandlers(topic),
+		OnJoin: func(channel *phx.Channel, response any) tea.Msg {
+			return ChannelJoinedMsg{
 				Channel:  channel,
 				Response: response,
-			})
-		})
-		
-		join.Receive("error", func(response any) {
-			c.program.Send(ErrorMsg{
-				Err:       fmt.Errorf("failed to join channel: %v", response),
-				Component: "Phoenix Channel Join",
-			})
-		})
-		
-		// Set up channel event handlers
-		c.setupChannelHandlers(channel)
-		
-		c.channel = channel
+			}
+		},
+	})
+	return func() tea.Msg {
+		if msg := join(); msg != nil {
+			return msg
+		}
 		return ChannelJoiningMsg{}
 	}
 }
 
-// setupChannelHandlers sets up event handlers for the channel
-func (c *Client) setupChannelHandlers(channel *phx.Channel) {
-	// Handle conversation responses
-	channel.On("response", func(payload any) {
-		data, _ := json.Marshal(payload)
-		c.program.Send(ConversationResponseMsg{
-			Response: data,
-		})
-	})
-	
-	// Handle thinking indicator
-	channel.On("thinking", func(payload any) {
-		c.program.Send(ConversationThinkingMsg{})
-	})
+// channelHandlers returns the conversation channel event handlers
+func (c *Client) channelHandlers(topic string) map[string]func(any) {
+	return map[string]func(any){
+		// Handle conversation responses
+		"response": func(payload any) {
+			data, _ := json.Marshal(payload)
+			c.channels.Send(ConversationResponseMsg{
+				Response: data,
+			})
+		},
 	
-	// Handle context updates
-	channel.On("context_updated", func(payload any) {
-		data, _ := json.Marshal(payload)
-		c.program.Send(ConversationContextUpdatedMsg{
-			Context: data,
-		})
-	})
+		// Handle thinking indicator
+		"thinking": func(payload any) {
+			msg := ConversationThinkingMsg{}
+			if data, ok := payload.(map[string]any); ok {
+				msg.Stage, _ = data["stage"].(string)
+				msg.RequestID = RequestIDOf(data)
+				if position, ok := data["queue_position"].(float64); ok {
+					msg.QueuePosition = int(position)
+				}
+			}
+			c.channels.Send(msg)
+		},
 	
-	// Handle conversation reset
-	channel.On("conversation_reset", func(payload any) {
-		data, _ := json.Marshal(payload)
-		c.program.Send(ConversationResetMsg{
-			SessionInfo: data,
-		})
-	})
+		// Handle queue position updates when the server is under load
+		"queued": func(payload any) {
+			if data, ok := payload.(map[string]any); ok {
+				position, _ := data["position"].(float64)
+				c.channels.Send(ConversationQueuedMsg{
+					Position: int(position),
+				})
+			}
+		},
 	
-	// Handle processing cancelled
-	channel.On("processing_cancelled", func(payload any) {
-		c.program.Send(ProcessingCancelledMsg{})
-	})
+		// Handle context updates
+		"context_updated": func(payload any) {
+			var update ConversationContext
+			c.channels.decode(topic, "context_updated", payload, &update)
+			c.channels.Send(ConversationContextUpdatedMsg{Update: update})
+		},
 	
-	// Handle conversation history
-	channel.On("history", func(payload any) {
-		if data, ok := payload.(map[string]any); ok {
-			c.program.Send(ConversationHistoryMsg{
-				ConversationID: data["conversation_id"],
-				Messages:       data["messages"],
-				Count:          data["count"],
+		// Handle conversation reset
+		"conversation_reset": func(payload any) {
+			data, _ := json.Marshal(payload)
+			c.channels.Send(ConversationResetMsg{
+				SessionInfo: data,
 			})
-		}
-	})
+		},
 	
-	// Handle streaming responses
-	channel.On("stream:start", func(payload any) {
-		data := payload.(map[string]any)
-		c.program.Send(StreamStartMsg{ID: data["id"].(string)})
-	})
+		// Handle archived and deleted conversations
+		"conversation_archived": func(payload any) {
+			var archived struct {
+				ConversationID string `json:"conversation_id"`
+				Archived       bool   `json:"archived"`
+			}
+			c.channels.decode(topic, "conversation_archived", payload, &archived)
+			c.channels.Send(ConversationArchivedMsg{ConversationID: archived.ConversationID, Archived: archived.Archived})
+		},
+		"conversation_resumed": func(payload any) {
+			var resumed struct {
+				ConversationID string `json:"conversation_id"`
+			}
+			c.channels.decode(topic, "conversation_resumed", payload, &resumed)
+			c.channels.Send(ConversationResumedMsg{ConversationID: resumed.ConversationID})
+		},
+		"conversation_deleted": func(payload any) {
+			var removed struct {
+				ConversationID string `json:"conversation_id"`
+			}
+			c.channels.decode(topic, "conversation_deleted", payload, &removed)
+			c.channels.Send(ConversationDeletedMsg{ConversationID: removed.ConversationID})
+		},
 	
-	channel.On("stream:data", func(payload any) {
-		data := payload.(map[string]any)
-		c.program.Send(StreamDataMsg{
-			ID:   data["id"].(string),
-			Data: data["chunk"].(string),
-		})
-	})
+		// Handle file change notifications from the server's file watcher
+		"file_changed": func(payload any) {
+			if data, ok := payload.(map[string]any); ok {
+				path, _ := data["path"].(string)
+				event, _ := data["event"].(string)
+				c.invalidate("file_changed")
+				c.channels.Send(FileChangedMsg{Path: path, Event: event})
+			}
+		},
 	
-	channel.On("stream:end", func(payload any) {
-		data := payload.(map[string]any)
-		c.program.Send(StreamEndMsg{ID: data["id"].(string)})
-	})
+		// Handle processing cancelled
+		"processing_cancelled": func(payload any) {
+			c.channels.Send(ProcessingCancelledMsg{})
+		},
 	
-	// Error handling
-	channel.On("error", func(payload any) {
-		c.program.Send(ErrorMsg{
-			Err:       fmt.Errorf("channel error: %v", payload),
-			Component: "Phoenix Channel",
-		})
-	})
+		// Handle conversation history
+		"history": func(payload any) {
+			var history ConversationHistory
+			c.channels.decode(topic, "history", payload, &history)
+			c.channels.Send(ConversationHistoryMsg{History: history})
+		},
+	
+		// Handle streaming responses; malformed frames are dropped rather
+		// than panicking the handler goroutine
+		"stream:start": func(payload any) {
+			data, _ := payload.(map[string]any)
+			if id, ok := data["id"].(string); ok {
+				c.channels.Send(StreamStartMsg{ID: id, RequestID: RequestIDOf(data)})
+			}
+		},
+	
+		"stream:data": func(payload any) {
+			data, _ := payload.(map[string]any)
+			id, ok := data["id"].(string)
+			chunk, _ := data["chunk"].(string)
+			if ok {
+				c.channels.Send(StreamDataMsg{
+					ID:   id,
+					Data: chunk,
+				})
+			}
+		},
+	
+		"stream:end": func(payload any) {
+			data, _ := payload.(map[string]any)
+			if id, ok := data["id"].(string); ok {
+				c.channels.Send(StreamEndMsg{ID: id})
+			}
+		},
+	
+		// Handle ReAct executions: each loop phase, then pause, resume, and
+		// the final state
+		"react:step": func(payload any) {
+			data, _ := payload.(map[string]any)
+			msg := ReactStepMsg{}
+			msg.ExecutionID, _ = data["execution_id"].(string)
+			msg.Phase, _ = data["phase"].(string)
+			msg.Content, _ = data["content"].(string)
+			msg.Tool, _ = data["tool"].(string)
+			if cycle, ok := data["cycle"].(float64); ok {
+				msg.Cycle = int(cycle)
+			}
+			if ms, ok := data["duration_ms"].(float64); ok {
+				msg.Duration = time.Duration(ms * float64(time.Millisecond))
+			}
+			if msg.ExecutionID != "" {
+				c.channels.Send(msg)
+			}
+		},
+	
+		"react:state": func(payload any) {
+			data, _ := payload.(map[string]any)
+			msg := ReactStateMsg{}
+			msg.ExecutionID, _ = data["execution_id"].(string)
+			msg.State, _ = data["state"].(string)
+			msg.Answer, _ = data["answer"].(string)
+			if msg.ExecutionID != "" {
+				c.channels.Send(msg)
+			}
+		},
+	
+		// Error handling
+		"error": func(payload any) {
+			// Provider failures carry the provider and model that failed
+			if data, ok := payload.(map[string]any); ok {
+				if errorType, _ := data["type"].(string); errorType == "provider_error" {
+					provider, _ := data["provider"].(string)
+					model, _ := data["model"].(string)
+					message, _ := data["message"].(string)
+					c.channels.Send(ProviderErrorMsg{
+						Provider:  provider,
+						Model:     model,
+						Message:   message,
+						RequestID: RequestIDOf(data),
+					})
+					return
+				}
+			}
+			c.channels.Send(ErrorMsg{
+				Err:       Errorf(ErrChannel, "channel error: %v", payload),
+				Component: "Phoenix Channel",
+				RequestID: RequestIDOf(payload),
+			})
+		},
+	}
 }
 
 // Push sends a message to the Phoenix channel
 func (c *Client) Push(event string, payload map[string]any) tea.Cmd {
-	return func() tea.Msg {
-		if c.channel == nil {
-			return ErrorMsg{
-				Err:       fmt.Errorf("channel not joined"),
-				Component: "Phoenix Push",
-			}
+	// Some events answer through channel events rather than push replies
+	quiet := event == "get_history" || event == "message" || event == "cancel_processing"
+	return c.channels.Push(c.conversationTopic(), event, payload, PushOptions{ReportTimeout: !quiet})
+}
+
+// Request pushes an event and waits for its reply until ctx is done
+func (c *Client) Request(ctx context.Context, event string, payload map[string]any) (map[string]any, error) {
+	return c.channels.Request(ctx, c.conversationTopic(), event, payload)
+}
+
+// requestWithin is Request bounded by timeout as well as ctx
+func (c *Client) requestWithin(ctx context.Context, timeout time.Duration, event string, payload map[string]any) (map[string]any, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return c.Request(ctx, event, payload)
+}
+
+// ListFiles lists a directory through the server's file API
+func (c *Client) ListFiles(ctx context.Context, path string) ([]FileEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, fileRequestTimeout)
+	defer cancel()
+	response, err := c.cachedRequest(ctx, "list_files", map[string]any{"path": path})
+	if err != nil {
+		return nil, err
+	}
+	
+	var entries []FileEntry
+	files, _ := response["files"].([]any)
+	for _, file := range files {
+		data, ok := file.(map[string]any)
+		if !ok {
+			continue
 		}
-		
-		push, err := c.channel.Push(event, payload)
-		if err != nil {
-			return ErrorMsg{
-				Err:       err,
-				Component: "Phoenix Push",
-			}
+		entry := FileEntry{}
+		entry.Name, _ = data["name"].(string)
+		entry.Path, _ = data["path"].(string)
+		entry.IsDir, _ = data["is_dir"].(bool)
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// ReadFile loads a file through the server's file API
+func (c *Client) ReadFile(ctx context.Context, path string) (string, error) {
+	response, err := c.requestWithin(ctx, fileRequestTimeout, "read_file", map[string]any{"path": path})
+	if err != nil {
+		return "", err
+	}
+	// Files over the server's size limit are fetched in chunks
+	if chunked, _ := response["chunked"].(bool); chunked {
+		data, err := c.Download(ctx, path, nil)
+		return string(data), err
+	}
+	content, ok := response["content"].(string)
+	if !ok {
+		return "", Errorf(ErrBadResponse, "read_file: no content for %s", path)
+	}
+	return content, nil
+}
+
+// WriteFile saves a file through the server's file API
+func (c *Client) WriteFile(ctx context.Context, path, content string) error {
+	_, err := c.requestWithin(ctx, fileRequestTimeout, "write_file", map[string]any{"path": path, "content": content})
+	c.invalidate("write_file")
+	return err
+}
+
+// FormatCode formats source with the server's formatter for the file's language
+func (c *Client) FormatCode(ctx context.Context, path, content string) (string, error) {
+	response, err := c.requestWithin(ctx, fileRequestTimeout, "format_code", map[string]any{"path": path, "content": content})
+	if err != nil {
+		return "", err
+	}
+	formatted, ok := response["content"].(string)
+	if !ok {
+		return "", Errorf(ErrBadResponse, "format_code: no content for %s", path)
+	}
+	return formatted, nil
+}
+
+// CreatePullRequest asks the server to commit a patch to a new branch and
+// open a pull request, returning the branch and the pull request's URL
+func (c *Client) CreatePullRequest(ctx context.Context, title, patch string) (branch, url string, err error) {
+	response, err := c.Request(ctx, "create_pull_request", map[string]any{"title": title, "patch": patch})
+	if err != nil {
+		return "", "", err
+	}
+	branch, _ = response["branch"].(string)
+	url, _ = response["url"].(string)
+	return branch, url, nil
+}
+
+// AvailableModel is a model one of the server's providers offers
+type AvailableModel struct {
+	Provider      string
+	Name          string
+	ContextWindow int // Tokens; 0 when the provider doesn't say
+}
+
+// ListModels asks the server which models its providers currently offer
+func (c *Client) ListModels(ctx context.Context) ([]AvailableModel, error) {
+	response, err := c.cachedRequest(ctx, "list_models", map[string]any{})
+	if err != nil {
+		return nil, err
+	}
+
+	var models []AvailableModel
+	items, _ := response["models"].([]any)
+	for _, item := range items {
+		data, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		model := AvailableModel{}
+		model.Provider, _ = data["provider"].(string)
+		model.Name, _ = data["name"].(string)
+		if window, ok := data["context_window"].(float64); ok {
+			model.ContextWindow = int(window)
+		}
+		if model.Name != "" {
+			models = append(models, model)
 		}
-		
-		push.Receive("ok", func(response any) {
-			// Success - nothing to do for now
-		})
-		
-		push.Receive("error", func(response any) {
-			c.program.Send(ErrorMsg{
-				Err:       fmt.Errorf("push failed: %v", response),
-				Component: "Phoenix Push",
-			})
-		})
-		
-		push.Receive("timeout", func(response any) {
-			// For certain events, we handle the response through channel events, not push replies
-			if event == "get_history" || event == "message" || event == "cancel_processing" {
-				// These are handled by channel events, ignore push timeout
-				return
-			}
-			
-			c.program.Send(ErrorMsg{
-				Err:       fmt.Errorf("Connection timeout for event: %s", event),
-				Component: "Phoenix Push",
-			})
-		})
-		
-		return nil
 	}
+	return models, nil
+}
+
+// Provider health states reported by get_provider_status
+const (
+	ProviderHealthy     = "healthy"
+	ProviderDegraded    = "degraded"
+	ProviderUnavailable = "unavailable"
+)
+
+// ProviderStatus is the server's view of one provider's health
+type ProviderStatus struct {
+	Provider     string
+	Status       string // ProviderHealthy, ProviderDegraded, or ProviderUnavailable
+	Reason       string // Why the provider isn't healthy, e.g. "rate limited"
+	CircuitState string // "closed", "half_open", or "open"
+	LatencyMS    float64
+	ErrorRate    float64 // Fraction of recent requests that failed
+}
+
+// Healthy reports whether requests to the provider are expected to succeed
+func (s ProviderStatus) Healthy() bool {
+	return s.Status == "" || s.Status == ProviderHealthy
+}
+
+// GetProviderStatus asks the server how healthy a provider currently is
+func (c *Client) GetProviderStatus(ctx context.Context, provider string) (ProviderStatus, error) {
+	response, err := c.cachedRequest(ctx, "get_provider_status", map[string]any{"provider": provider})
+	if err != nil {
+		return ProviderStatus{}, err
+	}
+
+	status := ProviderStatus{Provider: provider}
+	if name, ok := response["provider"].(string); ok && name != "" {
+		status.Provider = name
+	}
+	status.Status, _ = response["status"].(string)
+	status.Reason, _ = response["reason"].(string)
+	status.CircuitState, _ = response["circuit_state"].(string)
+	status.LatencyMS, _ = response["latency_ms"].(float64)
+	status.ErrorRate, _ = response["error_rate"].(float64)
+	return status, nil
+}
+
+// SearchCode runs a project-wide search on the server
+func (c *Client) SearchCode(ctx context.Context, query string, globs []string, contextLines int) ([]CodeMatch, error) {
+	response, err := c.requestWithin(ctx, searchRequestTimeout, "search_code", map[string]any{
+		"query":   query,
+		"globs":   globs,
+		"context": contextLines,
+	})
+	if err != nil {
+		return nil, err
+	}
+	
+	var matches []CodeMatch
+	items, _ := response["matches"].([]any)
+	for _, item := range items {
+		data, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		match := CodeMatch{}
+		match.Path, _ = data["path"].(string)
+		match.Text, _ = data["text"].(string)
+		if line, ok := data["line"].(float64); ok {
+			match.Line = int(line)
+		}
+		match.Before = stringList(data["before"])
+		match.After = stringList(data["after"])
+		matches = append(matches, match)
+	}
+	return matches, nil
+}
+
+// stringList converts a decoded JSON array to strings
+func stringList(value any) []string {
+	items, _ := value.([]any)
+	var result []string
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
 }
 
 // PushAsync sends a message to the Phoenix channel without waiting for responses
 // Use this for events where responses come through channel events, not push replies
 func (c *Client) PushAsync(event string, payload map[string]any) tea.Cmd {
-	return func() tea.Msg {
-		if c.channel == nil {
-			return ErrorMsg{
-				Err:       fmt.Errorf("channel not joined"),
-				Component: "Phoenix Push",
-			}
-		}
-		
-		// Just push without setting up response handlers
-		_, err := c.channel.Push(event, payload)
-		if err != nil {
-			return ErrorMsg{
-				Err:       err,
-				Component: "Phoenix Push",
-			}
-		}
-		
-		// Don't wait for any responses - they'll come through channel events
-		return nil
+	return c.channels.Push(c.conversationTopic(), event, payload, PushOptions{NoReply: true})
+}
+
+// SetTags replaces the tags the server keeps for a conversation
+func (c *Client) SetTags(conversationID string, tags []string) tea.Cmd {
+	if tags == nil {
+		tags = []string{}
 	}
+	return c.PushAsync("set_tags", map[string]any{
+		"conversation_id": conversationID,
+		"tags":            tags,
+	})
+}
+
+// ArchiveConversation hides a conversation from the server's conversation
+// list, or brings it back when archived is false
+func (c *Client) ArchiveConversation(conversationID string, archived bool) tea.Cmd {
+	return c.PushAsync("archive_conversation", map[string]any{
+		"conversation_id": conversationID,
+		"archived":        archived,
+	})
+}
+
+// DeleteConversation permanently deletes a conversation on the server
+func (c *Client) DeleteConversation(conversationID string) tea.Cmd {
+	return c.PushAsync("delete_conversation", map[string]any{"conversation_id": conversationID})
+}
+
+// ResumeConversation switches the channel back to an earlier conversation
+func (c *Client) ResumeConversation(conversationID string) tea.Cmd {
+	return c.PushAsync("resume_conversation", map[string]any{"conversation_id": conversationID})
 }
 
 // SendMessage sends a message to the conversation channel
@@ -310,9 +633,19 @@ func (c *Client) SendMessage(content string) tea.Cmd {
 
 // SendMessageWithConfig sends a message with LLM configuration
 func (c *Client) SendMessageWithConfig(content string, model string, provider string, temperature float64) tea.Cmd {
+	return c.SendReplyWithConfig(content, "", model, provider, temperature)
+}
+
+// SendReplyWithConfig sends a message with LLM configuration as a reply to
+// an earlier message, so the server can thread its context. An empty
+// replyTo sends a plain message
+func (c *Client) SendReplyWithConfig(content string, replyTo string, model string, provider string, temperature float64) tea.Cmd {
 	payload := map[string]any{
 		"content": content,
 	}
+	if replyTo != "" {
+		payload["reply_to"] = replyTo
+	}
 	
 	// Add llm_config with both provider and model
 	if model != "" && provider != "" {
@@ -332,6 +665,15 @@ func (c *Client) CancelProcessing() tea.Cmd {
 	return c.PushAsync("cancel_processing", map[string]any{})
 }
 
+// ControlReact pauses, resumes, or aborts a ReAct execution; action is
+// "pause", "resume", or "abort"
+func (c *Client) ControlReact(executionID, action string) tea.Cmd {
+	return c.PushAsync("react_control", map[string]any{
+		"execution_id": executionID,
+		"action":       action,
+	})
+}
+
 // StartNewConversation starts a new conversation
 func (c *Client) StartNewConversation() tea.Cmd {
 	return c.Push("new_conversation", map[string]any{})
@@ -344,6 +686,15 @@ func (c *Client) GetConversationHistory(limit int) tea.Cmd {
 	})
 }
 
+// GetConversationHistoryBefore requests the page of history older than a
+// cursor from an earlier history event
+func (c *Client) GetConversationHistoryBefore(limit int, cursor string) tea.Cmd {
+	return c.PushAsync("get_history", map[string]any{
+		"limit":  limit,
+		"before": cursor,
+	})
+}
+
 // SetConversationContext updates the conversation context
 func (c *Client) SetConversationContext(context map[string]any) tea.Cmd {
 	payload := map[string]any{
@@ -366,16 +717,7 @@ func (c *Client) SetConversationModel(model string, provider string) tea.Cmd {
 // Disconnect closes the WebSocket connection
 func (c *Client) Disconnect() tea.Cmd {
 	return func() tea.Msg {
-		if c.channel != nil {
-			c.channel.Leave()
-			c.channel = nil
-		}
-		
-		if c.socket != nil {
-			c.socket.Disconnect()
-			c.socket = nil
-		}
-		
+		c.channels.Close()
 		return DisconnectedMsg{Error: nil}
 	}
 }
@@ -14,12 +14,12 @@ func TestNewClient(t *testing.T) {
 		t.Fatal("Expected non-nil client")
 	}
 	
-	if client.socket != nil {
+	if client.Channels().Socket() != nil {
 		t.Error("Expected socket to be nil initially")
 	}
 	
-	if client.channel != nil {
-		t.Error("Expected channel to be nil initially")
+	if topics := client.Channels().Topics(); len(topics) != 0 {
+		t.Errorf("Expected no channels initially, got %v", topics)
 	}
 }
 
@@ -47,7 +47,7 @@ func TestClient_SetProgram(t *testing.T) {
 	
 	client.SetProgram(program)
 	
-	if client.program == nil {
+	if client.channels.program == nil {
 		t.Error("Expected program to be set")
 	}
 }
@@ -0,0 +1,86 @@
+package phoenix
+
+import (
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// StreamFlushInterval is how often coalesced stream chunks reach the UI,
+// about 40 times a second: smooth to read, and few enough updates that a
+// fast provider can't flood the event loop
+const StreamFlushInterval = 25 * time.Millisecond
+
+// StreamCoalescer merges StreamDataMsg chunks that arrive between flushes
+// into one StreamDataMsg per stream, delivered from a timer goroutine. Any
+// other message flushes the waiting chunks first, so a stream's end never
+// overtakes its data. It is safe for concurrent use
+type StreamCoalescer struct {
+	interval time.Duration
+	deliver  func(tea.Msg)
+
+	// sendMu serializes deliveries so they keep their order; mu guards the
+	// fields below
+	sendMu  sync.Mutex
+	mu      sync.Mutex
+	pending []StreamDataMsg // One per stream, in order of first chunk
+	timer   *time.Timer
+}
+
+// NewStreamCoalescer creates a coalescer that hands messages to deliver. A
+// zero interval delivers every chunk as it comes
+func NewStreamCoalescer(interval time.Duration, deliver func(tea.Msg)) *StreamCoalescer {
+	return &StreamCoalescer{interval: interval, deliver: deliver}
+}
+
+// Send delivers msg, holding stream chunks back until the next flush
+func (c *StreamCoalescer) Send(msg tea.Msg) {
+	if data, ok := msg.(StreamDataMsg); ok && c.interval > 0 {
+		c.add(data)
+		return
+	}
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	c.deliverPending()
+	c.deliver(msg)
+}
+
+// Flush delivers the chunks waiting for the next flush now
+func (c *StreamCoalescer) Flush() {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	c.deliverPending()
+}
+
+// add appends a chunk to its stream's pending data, starting the flush
+// timer for the first one
+func (c *StreamCoalescer) add(data StreamDataMsg) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := range c.pending {
+		if c.pending[i].ID == data.ID {
+			c.pending[i].Data += data.Data
+			return
+		}
+	}
+	c.pending = append(c.pending, data)
+	if c.timer == nil {
+		c.timer = time.AfterFunc(c.interval, c.Flush)
+	}
+}
+
+// deliverPending hands over the waiting chunks; c.sendMu must be held
+func (c *StreamCoalescer) deliverPending() {
+	c.mu.Lock()
+	pending := c.pending
+	c.pending = nil
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+	c.mu.Unlock()
+	for _, data := range pending {
+		c.deliver(data)
+	}
+}
@@ -0,0 +1,37 @@
+package phoenix
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestStreamCoalescer_MergesChunksAndKeepsOrder(t *testing.T) {
+	var mu sync.Mutex
+	var received []tea.Msg
+	coalescer := NewStreamCoalescer(StreamFlushInterval, func(msg tea.Msg) {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, msg)
+	})
+
+	for i := 0; i < 100; i++ {
+		coalescer.Send(StreamDataMsg{ID: "s1", Data: "x"})
+	}
+	coalescer.Send(StreamEndMsg{ID: "s1"})
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 2 {
+		t.Fatalf("Expected the chunks merged into one message before the end, got %d messages", len(received))
+	}
+	data, ok := received[0].(StreamDataMsg)
+	if !ok || data.Data != strings.Repeat("x", 100) {
+		t.Fatalf("Expected all chunks in order, got %#v", received[0])
+	}
+	if _, ok := received[1].(StreamEndMsg); !ok {
+		t.Fatalf("Expected the end after the data, got %#v", received[1])
+	}
+}
@@ -0,0 +1,71 @@
+package phoenix
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync/atomic"
+)
+
+// RequestIDField is the payload field carrying a push's correlation ID. The
+// server echoes it in the reply and in every event the push causes
+const RequestIDField = "request_id"
+
+// requestSession prefixes request IDs so they are unique across runs
+var requestSession = func() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "tui"
+	}
+	return hex.EncodeToString(b)
+}()
+
+// requestCounter numbers the requests of this run
+var requestCounter atomic.Uint64
+
+// NewRequestID returns a new correlation ID
+func NewRequestID() string {
+	return fmt.Sprintf("%s-%d", requestSession, requestCounter.Add(1))
+}
+
+// RequestSentMsg reports a push that reached the socket, so the UI can match
+// later events to it by RequestID
+type RequestSentMsg struct {
+	RequestID string
+	Topic     string
+	Event     string
+	Payload   any
+}
+
+// withRequestID returns a copy of a map payload with a new correlation ID.
+// Other payloads are sent unchanged and get no ID
+func withRequestID(payload any) (any, string) {
+	var fields map[string]any
+	switch p := payload.(type) {
+	case nil:
+		fields = map[string]any{}
+	case map[string]any:
+		if p == nil {
+			fields = map[string]any{}
+		} else {
+			fields = make(map[string]any, len(p)+1)
+			for key, value := range p {
+				fields[key] = value
+			}
+		}
+	default:
+		return payload, ""
+	}
+	id := NewRequestID()
+	fields[RequestIDField] = id
+	return fields, id
+}
+
+// RequestIDOf returns the correlation ID echoed in a server payload
+func RequestIDOf(payload any) string {
+	if data, ok := payload.(map[string]any); ok {
+		id, _ := data[RequestIDField].(string)
+		return id
+	}
+	return ""
+}
@@ -0,0 +1,160 @@
+package phoenix
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ErrorCode identifies a kind of error in the catalog, e.g. "E101"
+type ErrorCode string
+
+// Error codes, grouped by hundreds: connection, auth, requests, transfers,
+// local providers, configuration
+const (
+	ErrNotConnected       ErrorCode = "E100"
+	ErrConnectionRefused  ErrorCode = "E101"
+	ErrHostNotFound       ErrorCode = "E102"
+	ErrTimeout            ErrorCode = "E103"
+	ErrHandshake          ErrorCode = "E104"
+	ErrCertificate        ErrorCode = "E105"
+	ErrFingerprintChanged ErrorCode = "E106"
+	ErrJoinFailed         ErrorCode = "E110"
+	ErrJoinRejected       ErrorCode = "E111"
+	ErrUnauthorized       ErrorCode = "E200"
+	ErrUserUnknown        ErrorCode = "E201"
+	ErrCredentialsRevoked ErrorCode = "E202"
+	ErrRequestFailed      ErrorCode = "E300"
+	ErrBadResponse        ErrorCode = "E301"
+	ErrChannel            ErrorCode = "E302"
+	ErrTransferCorrupt    ErrorCode = "E400"
+	ErrTransferStalled    ErrorCode = "E401"
+	ErrOllamaUnreachable  ErrorCode = "E500"
+	ErrOllama             ErrorCode = "E501"
+	ErrProxyConfig        ErrorCode = "E600"
+	ErrTLSConfig          ErrorCode = "E601"
+	ErrUnknown            ErrorCode = "E999"
+)
+
+// ErrorInfo describes a kind of error for users: what it means, what to do
+// about it, and whether trying again can help
+type ErrorInfo struct {
+	Code      ErrorCode
+	Title     string
+	Hint      string
+	Retryable bool
+}
+
+// errorCatalog lists every error code
+var errorCatalog = []ErrorInfo{
+	{ErrNotConnected, "Not connected", "The socket or channel isn't up yet. Wait for the reconnect, or press Ctrl+R to reconnect now", true},
+	{ErrConnectionRefused, "Connection refused", "Nothing is listening at the server URL. Start the server (mix phx.server) or check the port in -url", true},
+	{ErrHostNotFound, "Host not found", "The server's hostname doesn't resolve. Check -url for typos and your DNS or VPN", false},
+	{ErrTimeout, "Timed out", "The server didn't answer in time. It may be busy or the network slow; try again, or raise read_timeout_seconds under network", true},
+	{ErrHandshake, "WebSocket handshake failed", "The server answered but not as a Phoenix socket. Check the URL path (/socket) and that a proxy isn't stripping the upgrade", false},
+	{ErrCertificate, "Certificate not trusted", "The server's TLS certificate didn't verify. Pass its CA with -ca-file, or use ws:// against a development server", false},
+	{ErrFingerprintChanged, "Server identity changed", "The server isn't the one first trusted at this address: its certificate or instance fingerprint changed. Someone may be intercepting the connection. If the server was legitimately replaced, check the new fingerprint with its admin and run /trust accept", false},
+	{ErrJoinFailed, "Channel join failed", "The channel couldn't be joined. It is retried on reconnect; Ctrl+R retries now", true},
+	{ErrJoinRejected, "Channel join refused", "The server refused the channel, usually for permissions. Sign in again with /login or check your API key's scopes", false},
+	{ErrUnauthorized, "Not signed in", "Your session or API key was rejected. Sign in with /login or set a valid api_key in config", false},
+	{ErrUserUnknown, "User unknown", "Sign in first so the client knows who you are", false},
+	{ErrCredentialsRevoked, "Credentials revoked", "The server no longer accepts the saved API key or session token; it was revoked or expired. Reconnecting can't help: log in with your password (/login) or set a new api_key in config", false},
+	{ErrRequestFailed, "Request failed", "The server rejected the request; the message says why", false},
+	{ErrBadResponse, "Unexpected response", "The server's reply lacked fields the client needs, which points at a version mismatch. Compare the versions in /doctor", false},
+	{ErrChannel, "Channel error", "The channel crashed on the server. It rejoins by itself; repeated errors are worth a bug report with /doctor", true},
+	{ErrTransferCorrupt, "Transfer corrupt", "The file's checksum didn't match after the transfer. Try again", true},
+	{ErrTransferStalled, "Transfer stalled", "The server stopped accepting or sending chunks. Run the command again to resume", true},
+	{ErrOllamaUnreachable, "Ollama not reachable", "Start Ollama with 'ollama serve', or point ollama_url in config at it", true},
+	{ErrOllama, "Ollama error", "Ollama rejected the request; check the model is pulled with 'ollama list'", false},
+	{ErrProxyConfig, "Invalid proxy", "Set network.proxy (or -proxy) to an http://, https://, or socks5:// URL, or \"direct\"", false},
+	{ErrTLSConfig, "Invalid TLS settings", "Check the files given as network.ca_file, cert_file, and key_file (or -ca-file, -client-cert, -client-key)", false},
+	{ErrUnknown, "Error", "No advice for this one. /doctor collects details for a bug report", false},
+}
+
+// Error is an error with a catalog code
+type Error struct {
+	Code ErrorCode
+	err  error
+}
+
+// Errorf creates an error with a code; the format accepts %w like fmt.Errorf
+func Errorf(code ErrorCode, format string, args ...any) error {
+	return &Error{Code: code, err: fmt.Errorf(format, args...)}
+}
+
+// Error returns the message
+func (e *Error) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the wrapped error, if any
+func (e *Error) Unwrap() error {
+	return errors.Unwrap(e.err)
+}
+
+// ErrorCatalog returns every catalog entry in code order
+func ErrorCatalog() []ErrorInfo {
+	return append([]ErrorInfo(nil), errorCatalog...)
+}
+
+// LookupError finds a catalog entry by code, ignoring case
+func LookupError(code string) (ErrorInfo, bool) {
+	for _, info := range errorCatalog {
+		if strings.EqualFold(string(info.Code), code) {
+			return info, true
+		}
+	}
+	return ErrorInfo{}, false
+}
+
+// ClassifyError returns the catalog entry for err. Errors without a code,
+// such as those from the network stack, are recognized by their kind or
+// message
+func ClassifyError(err error) ErrorInfo {
+	var coded *Error
+	if errors.As(err, &coded) {
+		if info, ok := LookupError(string(coded.Code)); ok {
+			return info
+		}
+	}
+	info, _ := LookupError(string(guessErrorCode(err)))
+	return info
+}
+
+// IsRetryable reports whether trying again can fix err
+func IsRetryable(err error) bool {
+	return ClassifyError(err).Retryable
+}
+
+// guessErrorCode recognizes uncoded errors
+func guessErrorCode(err error) ErrorCode {
+	if err == nil {
+		return ErrUnknown
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return ErrHostNotFound
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return ErrTimeout
+	}
+
+	text := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(text, "connection refused"):
+		return ErrConnectionRefused
+	case strings.Contains(text, "no such host"):
+		return ErrHostNotFound
+	case strings.Contains(text, "timeout") || strings.Contains(text, "timed out"):
+		return ErrTimeout
+	case strings.Contains(text, "bad handshake"):
+		return ErrHandshake
+	case strings.Contains(text, "certificate"):
+		return ErrCertificate
+	case strings.Contains(text, "unauthorized") || strings.Contains(text, "authentication"):
+		return ErrUnauthorized
+	}
+	return ErrUnknown
+}
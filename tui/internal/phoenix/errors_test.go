@@ -0,0 +1,46 @@
+package phoenix
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want ErrorCode
+	}{
+		{Errorf(ErrJoinRejected, "conversation:lobby join rejected"), ErrJoinRejected},
+		{fmt.Errorf("read_file: %w", Errorf(ErrTimeout, "read_file timed out")), ErrTimeout},
+		{errors.New("dial tcp 127.0.0.1:5555: connect: connection refused"), ErrConnectionRefused},
+		{errors.New("websocket: bad handshake"), ErrHandshake},
+		{errors.New("something odd"), ErrUnknown},
+	}
+	for _, tt := range tests {
+		if got := ClassifyError(tt.err).Code; got != tt.want {
+			t.Errorf("ClassifyError(%v) = %s, want %s", tt.err, got, tt.want)
+		}
+	}
+	if !IsRetryable(Errorf(ErrTimeout, "slow")) || IsRetryable(Errorf(ErrUnauthorized, "denied")) {
+		t.Error("Unexpected retryability")
+	}
+}
+
+func TestErrorWrapsCause(t *testing.T) {
+	cause := errors.New("disk full")
+	err := Errorf(ErrTLSConfig, "reading CA bundle: %w", cause)
+	if !errors.Is(err, cause) || err.Error() != "reading CA bundle: disk full" {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestErrorCatalogCodesAreUnique(t *testing.T) {
+	seen := map[ErrorCode]bool{}
+	for _, info := range ErrorCatalog() {
+		if seen[info.Code] || info.Hint == "" {
+			t.Errorf("Catalog entry %s is duplicated or has no hint", info.Code)
+		}
+		seen[info.Code] = true
+	}
+}
@@ -0,0 +1,82 @@
+package phoenix
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"strings"
+)
+
+// keyPinPrefix marks a fingerprint of a certificate's public key. Pins
+// without it hash the whole certificate, as older versions did
+const keyPinPrefix = "spki-sha256:"
+
+// ServerFingerprintMsg reports the public key a wss:// server presented.
+// Pinned is the fingerprint the connection was checked against, empty when
+// the server wasn't known yet; a different Fingerprint means the handshake
+// was refused. Repinned is set when an older certificate pin matched, so the
+// key's fingerprint should replace it
+type ServerFingerprintMsg struct {
+	Host        string
+	Fingerprint string
+	Pinned      string
+	Repinned    bool
+}
+
+// Changed reports whether the server presented another certificate than the
+// pinned one
+func (m ServerFingerprintMsg) Changed() bool {
+	return m.Pinned != "" && m.Pinned != m.Fingerprint
+}
+
+// KeyFingerprint returns the SHA-256 fingerprint of a certificate's
+// SubjectPublicKeyInfo, which survives renewals that keep the key
+func KeyFingerprint(cert *x509.Certificate) string {
+	return fingerprint(keyPinPrefix, cert.RawSubjectPublicKeyInfo)
+}
+
+// certFingerprint returns the SHA-256 fingerprint of a whole certificate, the
+// form browsers and openssl show and older versions pinned
+func certFingerprint(cert *x509.Certificate) string {
+	return fingerprint("sha256:", cert.Raw)
+}
+
+// fingerprint hashes data as the prefix and colon-separated hex
+func fingerprint(prefix string, data []byte) string {
+	sum := sha256.Sum256(data)
+	hexSum := strings.ToUpper(hex.EncodeToString(sum[:]))
+	pairs := make([]string, 0, len(sum))
+	for i := 0; i < len(hexSum); i += 2 {
+		pairs = append(pairs, hexSum[i:i+2])
+	}
+	return prefix + strings.Join(pairs, ":")
+}
+
+// pinCertificate returns a TLS config that reports the server's public key
+// through report and refuses one that doesn't match pinned. Normal
+// verification still runs first; the pin only narrows what is accepted
+func pinCertificate(config *tls.Config, host, pinned string, report func(ServerFingerprintMsg)) *tls.Config {
+	if config == nil {
+		config = &tls.Config{}
+	} else {
+		config = config.Clone()
+	}
+	config.VerifyConnection = func(state tls.ConnectionState) error {
+		if len(state.PeerCertificates) == 0 {
+			return Errorf(ErrCertificate, "%s presented no certificate", host)
+		}
+		leaf := state.PeerCertificates[0]
+		msg := ServerFingerprintMsg{Host: host, Fingerprint: KeyFingerprint(leaf), Pinned: pinned}
+		// An older certificate pin is honored once, then replaced by the key
+		if pinned != "" && !strings.HasPrefix(pinned, keyPinPrefix) && pinned == certFingerprint(leaf) {
+			msg.Pinned, msg.Repinned = msg.Fingerprint, true
+		}
+		report(msg)
+		if msg.Changed() {
+			return Errorf(ErrFingerprintChanged, "%s presented key %s, not the pinned %s", host, msg.Fingerprint, pinned)
+		}
+		return nil
+	}
+	return config
+}
@@ -0,0 +1,56 @@
+package phoenix
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPinCertificate(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+	host := server.Listener.Addr().String()
+	want := KeyFingerprint(server.Certificate())
+
+	dial := func(pinned string) (ServerFingerprintMsg, error) {
+		var reported ServerFingerprintMsg
+		config := pinCertificate(server.Client().Transport.(*http.Transport).TLSClientConfig, host, pinned, func(msg ServerFingerprintMsg) {
+			reported = msg
+		})
+		conn, err := tls.Dial("tcp", host, config)
+		if err == nil {
+			conn.Close()
+		}
+		return reported, err
+	}
+
+	reported, err := dial("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reported.Fingerprint != want || reported.Changed() {
+		t.Fatalf("Expected the certificate %s reported on first use, got %+v", want, reported)
+	}
+
+	if _, err := dial(want); err != nil {
+		t.Fatalf("Expected the pinned key to be accepted, got %v", err)
+	}
+
+	// A pin of the whole certificate from an older version still matches,
+	// and is replaced by the key's fingerprint
+	reported, err = dial(certFingerprint(server.Certificate()))
+	if err != nil || !reported.Repinned || reported.Fingerprint != want || reported.Changed() {
+		t.Fatalf("Expected an older certificate pin honored and repinned, got %+v, %v", reported, err)
+	}
+
+	reported, err = dial(keyPinPrefix + "00")
+	var coded *Error
+	if !errors.As(err, &coded) || coded.Code != ErrFingerprintChanged {
+		t.Fatalf("Expected a changed certificate to be refused, got %v", err)
+	}
+	if !reported.Changed() {
+		t.Fatal("Expected the change to be reported")
+	}
+}
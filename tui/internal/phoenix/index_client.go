@@ -0,0 +1,141 @@
+package phoenix
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nshafer/phx"
+)
+
+// indexTopic is the channel the server builds the project's embeddings index on
+const indexTopic = "index:lobby"
+
+// Index states reported by index_status
+const (
+	IndexNone     = "none"
+	IndexBuilding = "building"
+	IndexReady    = "ready"
+	IndexFailed   = "failed"
+)
+
+// IndexClient handles project indexing channel operations
+type IndexClient struct {
+	channels *ChannelManager
+}
+
+// NewIndexClient creates an index client on the shared channel manager
+func NewIndexClient(channels *ChannelManager) *IndexClient {
+	return &IndexClient{channels: channels}
+}
+
+// JoinIndexChannel joins the index channel
+func (i *IndexClient) JoinIndexChannel() tea.Cmd {
+	return i.channels.Join(ChannelSpec{
+		Topic:     indexTopic,
+		Component: "Index Client",
+		Handlers:  i.indexHandlers(),
+		OnJoin: func(_ *phx.Channel, _ any) tea.Msg {
+			return IndexChannelJoinedMsg{}
+		},
+	})
+}
+
+// Joined reports whether the index channel is joined
+func (i *IndexClient) Joined() bool {
+	channel := i.channels.Channel(indexTopic)
+	return channel != nil && channel.IsJoined()
+}
+
+// indexHandlers returns the index channel event handlers
+func (i *IndexClient) indexHandlers() map[string]func(any) {
+	return map[string]func(any){
+		// Files embedded so far during a build
+		"index_progress": func(payload any) {
+			var progress IndexProgress
+			i.channels.decode(indexTopic, "index_progress", payload, &progress)
+			i.channels.Send(IndexProgressMsg{Progress: progress})
+		},
+
+		// Index state, sent on request and when a build finishes
+		"index_status": func(payload any) {
+			var status IndexStatus
+			i.channels.decode(indexTopic, "index_status", payload, &status)
+			i.channels.Send(IndexStatusMsg{Status: status})
+		},
+
+		// Index operation failed
+		"index_error": func(payload any) {
+			var failure struct {
+				Operation string `json:"operation"`
+				Message   string `json:"message"`
+				RequestID string `json:"request_id"`
+			}
+			i.channels.decode(indexTopic, "index_error", payload, &failure)
+			i.channels.Send(IndexErrorMsg{Operation: failure.Operation, Message: failure.Message})
+		},
+	}
+}
+
+// push sends an index request; results arrive as channel events
+func (i *IndexClient) push(event string, payload map[string]any) tea.Cmd {
+	return i.channels.Push(indexTopic, event, payload, PushOptions{
+		OnError: func(response any) tea.Msg {
+			return IndexErrorMsg{Operation: event, Message: fmt.Sprintf("%v", response)}
+		},
+	})
+}
+
+// BuildIndex starts indexing the project, re-embedding unchanged files too
+// when force is set
+func (i *IndexClient) BuildIndex(force bool) tea.Cmd {
+	return i.push("build_index", map[string]any{"force": force})
+}
+
+// GetIndexStatus asks for the index's current state
+func (i *IndexClient) GetIndexStatus() tea.Cmd {
+	return i.push("get_index_status", map[string]any{})
+}
+
+// LeaveChannel leaves the index channel
+func (i *IndexClient) LeaveChannel() {
+	i.channels.Leave(indexTopic)
+}
+
+// IndexProgress is a build's progress through the project's files
+type IndexProgress struct {
+	Phase      string `json:"phase"` // e.g. "scanning", "embedding"
+	FilesDone  int    `json:"files_done"`
+	FilesTotal int    `json:"files_total"`
+	Current    string `json:"current,omitempty"` // File being embedded
+	RequestID  string `json:"request_id"`
+}
+
+// IndexStatus is the state of the project's embeddings index
+type IndexStatus struct {
+	State     string `json:"state" schema:"required"` // IndexNone, IndexBuilding, IndexReady, or IndexFailed
+	Files     int    `json:"files"`
+	Chunks    int    `json:"chunks"`
+	IndexedAt string `json:"indexed_at,omitempty"` // RFC 3339
+	Error     string `json:"error,omitempty"`
+	RequestID string `json:"request_id"`
+}
+
+// Index channel message types
+
+type IndexChannelJoinedMsg struct{}
+
+// IndexProgressMsg reports a build's progress
+type IndexProgressMsg struct {
+	Progress IndexProgress
+}
+
+// IndexStatusMsg carries the index's state
+type IndexStatusMsg struct {
+	Status IndexStatus
+}
+
+// IndexErrorMsg reports a failed index operation
+type IndexErrorMsg struct {
+	Operation string
+	Message   string
+}
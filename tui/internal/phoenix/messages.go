@@ -2,6 +2,8 @@ package phoenix
 
 import (
 	"encoding/json"
+	"time"
+
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/nshafer/phx"
 )
@@ -32,6 +34,7 @@ type (
 		Err       error
 		Component string
 		Retry     tea.Cmd
+		RequestID string // Correlation ID of the failed push, if any
 	}
 	
 	RetryMsg struct {
@@ -43,34 +46,109 @@ type (
 		Response json.RawMessage
 	}
 	
-	ConversationThinkingMsg struct{}
+	ConversationThinkingMsg struct {
+		Stage         string
+		QueuePosition int
+		RequestID     string
+	}
+	
+	ConversationQueuedMsg struct {
+		Position int
+	}
 	
 	ConversationContextUpdatedMsg struct {
-		Context json.RawMessage
+		Update ConversationContext
 	}
 	
 	ProcessingCancelledMsg struct{}
 	
+	// FileChangedMsg reports a file created, modified, or deleted on the server
+	FileChangedMsg struct {
+		Path  string
+		Event string // "created", "modified", or "deleted"
+	}
+	
+	// ProviderErrorMsg reports that the LLM provider failed to answer
+	ProviderErrorMsg struct {
+		Provider  string
+		Model     string
+		Message   string
+		RequestID string
+	}
+	
 	ConversationResetMsg struct {
 		SessionInfo json.RawMessage
 	}
 	
 	ConversationHistoryMsg struct {
-		ConversationID any
-		Messages       any
-		Count          any
+		History ConversationHistory
+	}
+	
+	// ConversationArchivedMsg confirms the server archived or unarchived a
+	// conversation
+	ConversationArchivedMsg struct {
+		ConversationID string
+		Archived       bool
+	}
+	
+	// ConversationResumedMsg confirms the channel is back on an earlier
+	// conversation
+	ConversationResumedMsg struct {
+		ConversationID string
+	}
+	
+	// ConversationDeletedMsg confirms the server deleted a conversation
+	ConversationDeletedMsg struct {
+		ConversationID string
 	}
 	
 	// Streaming message types
-	StreamStartMsg struct{ ID string }
+	StreamStartMsg struct {
+		ID        string
+		RequestID string
+	}
 	StreamDataMsg  struct {
 		ID   string
 		Data string
 	}
 	StreamEndMsg struct{ ID string }
+	
+	// ReactStepMsg is one phase of a ReAct execution's
+	// thought → action → observation loop
+	ReactStepMsg struct {
+		ExecutionID string
+		Cycle       int
+		Phase       string // "thought", "action", or "observation"
+		Content     string
+		Tool        string        // Tool called by an action
+		Duration    time.Duration // Server-measured time of the phase, if sent
+	}
+	
+	// ReactStateMsg reports a ReAct execution paused, resumed, or finished
+	ReactStateMsg struct {
+		ExecutionID string
+		State       string // "running", "paused", "completed", "aborted", or "failed"
+		Answer      string // Final answer when completed
+	}
 )
 
 // Response types for conversation
+// FileEntry describes a file returned by the server's file API
+type FileEntry struct {
+	Name  string
+	Path  string
+	IsDir bool
+}
+
+// CodeMatch is a search hit returned by the server's search_code command
+type CodeMatch struct {
+	Path   string
+	Line   int
+	Text   string
+	Before []string
+	After  []string
+}
+
 type ConversationMessage struct {
 	Query            string         `json:"query"`
 	Response         string         `json:"response"`
@@ -78,11 +156,116 @@ type ConversationMessage struct {
 	RoutedTo         string         `json:"routed_to,omitempty"`
 	Timestamp        string         `json:"timestamp"`
 	Metadata         map[string]any `json:"metadata,omitempty"`
+	RequestID        string         `json:"request_id,omitempty"`
+}
+
+// FileChange is a file rewritten by a refactor response
+type FileChange struct {
+	Path        string
+	Content     string // The file's full new content
+	Description string
 }
 
-type ConversationContext struct {
-	Context   map[string]any `json:"context"`
-	Timestamp string         `json:"timestamp"`
+// FileChanges returns the file rewrites in a refactor response's metadata
+func (m ConversationMessage) FileChanges() []FileChange {
+	items, _ := m.Metadata["changes"].([]any)
+	var changes []FileChange
+	for _, item := range items {
+		data, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		change := FileChange{}
+		change.Path, _ = data["path"].(string)
+		change.Content, _ = data["content"].(string)
+		change.Description, _ = data["description"].(string)
+		if change.Path != "" {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// ToolStep is one tool call the agent made while answering
+type ToolStep struct {
+	Tool     string
+	Input    map[string]any
+	Duration time.Duration
+	Result   string
+	Error    string
+}
+
+// ToolSteps returns the tool calls in a response's metadata, from
+// "tool_calls" or "execution_trace"
+func (m ConversationMessage) ToolSteps() []ToolStep {
+	items, _ := m.Metadata["tool_calls"].([]any)
+	if len(items) == 0 {
+		items, _ = m.Metadata["execution_trace"].([]any)
+	}
+	var steps []ToolStep
+	for _, item := range items {
+		data, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		step := ToolStep{}
+		if step.Tool, _ = data["tool"].(string); step.Tool == "" {
+			step.Tool, _ = data["name"].(string)
+		}
+		if step.Input, _ = data["input"].(map[string]any); step.Input == nil {
+			step.Input, _ = data["arguments"].(map[string]any)
+		}
+		if ms, ok := data["duration_ms"].(float64); ok {
+			step.Duration = time.Duration(ms * float64(time.Millisecond))
+		}
+		if step.Result, _ = data["result"].(string); step.Result == "" {
+			step.Result, _ = data["output"].(string)
+		}
+		step.Error, _ = data["error"].(string)
+		if step.Tool != "" {
+			steps = append(steps, step)
+		}
+	}
+	return steps
+}
+
+// RetrievedSource is a chunk of a project file retrieved as context for an answer
+type RetrievedSource struct {
+	Path      string
+	StartLine int // 0 when the server doesn't say
+	EndLine   int
+	Score     float64 // Similarity to the query, 0 to 1
+}
+
+// RetrievedSources returns the context chunks in a response's metadata,
+// from "sources" or "retrieved_context"
+func (m ConversationMessage) RetrievedSources() []RetrievedSource {
+	items, _ := m.Metadata["sources"].([]any)
+	if len(items) == 0 {
+		items, _ = m.Metadata["retrieved_context"].([]any)
+	}
+	var sources []RetrievedSource
+	for _, item := range items {
+		data, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		source := RetrievedSource{}
+		if source.Path, _ = data["path"].(string); source.Path == "" {
+			source.Path, _ = data["file"].(string)
+		}
+		if line, ok := data["start_line"].(float64); ok {
+			source.StartLine = int(line)
+		}
+		if line, ok := data["end_line"].(float64); ok {
+			source.EndLine = int(line)
+		}
+		source.Score, _ = data["score"].(float64)
+		if source.Path != "" {
+			sources = append(sources, source)
+		}
+	}
+	return sources
 }
 
 type ConversationSessionInfo struct {
@@ -0,0 +1,190 @@
+package phoenix
+
+import (
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// MockClient is a PhoenixClient without a server. Scripted scenarios decide
+// how it answers; calls without a matching step get a canned reply, failing
+// at random with probability ErrorRate
+type MockClient struct {
+	mu        sync.Mutex
+	program   *tea.Program
+	onMessage func(tea.Msg)
+	scenario  *Scenario
+	used      []bool
+	calls     []MockCall
+	rand      *rand.Rand
+
+	// ErrorRate is the chance (0-1) that an unscripted connect or send fails
+	ErrorRate float64
+}
+
+// MockCall records a call made to the MockClient
+type MockCall struct {
+	Trigger string
+	Subject string
+	Payload map[string]any
+}
+
+var _ PhoenixClient = (*MockClient)(nil)
+
+// NewMockClient creates a MockClient, optionally driven by a scenario
+func NewMockClient(scenario *Scenario) *MockClient {
+	c := &MockClient{rand: rand.New(rand.NewSource(time.Now().UnixNano()))}
+	c.SetScenario(scenario)
+	return c
+}
+
+// SetScenario replaces the scenario and resets its progress
+func (c *MockClient) SetScenario(scenario *Scenario) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.scenario = scenario
+	c.used = nil
+	if scenario != nil {
+		c.used = make([]bool, len(scenario.Steps))
+	}
+}
+
+// SetSeed makes random failures reproducible
+func (c *MockClient) SetSeed(seed int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rand = rand.New(rand.NewSource(seed))
+}
+
+// SetProgram sets the tea.Program that receives scenario messages
+func (c *MockClient) SetProgram(program *tea.Program) {
+	c.program = program
+}
+
+// OnMessage delivers messages to fn instead of the program, for tests
+func (c *MockClient) OnMessage(fn func(tea.Msg)) {
+	c.onMessage = fn
+}
+
+// Calls returns the calls made so far
+func (c *MockClient) Calls() []MockCall {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]MockCall(nil), c.calls...)
+}
+
+// Connect plays the next connect step, or connects successfully
+func (c *MockClient) Connect(config Config) tea.Cmd {
+	socket := UserSocketType
+	if config.IsAuth {
+		socket = AuthSocketType
+	}
+	return c.handle(TriggerConnect, string(socket), nil, true, ConnectedMsg{SocketType: socket})
+}
+
+// JoinChannel plays the next join step matching the topic
+func (c *MockClient) JoinChannel(topic string) tea.Cmd {
+	return c.handle(TriggerJoin, topic, nil, false, ChannelJoiningMsg{})
+}
+
+// Push plays the next push step matching the event
+func (c *MockClient) Push(event string, payload map[string]any) tea.Cmd {
+	return c.handle(TriggerPush, event, payload, false, nil)
+}
+
+// SendMessage plays the next send step matching the content, or echoes it
+func (c *MockClient) SendMessage(content string) tea.Cmd {
+	response, _ := json.Marshal(ConversationMessage{
+		Query:            content,
+		Response:         "Mock response to: " + content,
+		ConversationType: "simple",
+		Timestamp:        time.Now().Format(time.RFC3339),
+	})
+	return c.handle(TriggerSend, content, map[string]any{"content": content}, true, ConversationResponseMsg{Response: response})
+}
+
+// StartNewConversation plays the next new_conversation step, or resets
+func (c *MockClient) StartNewConversation() tea.Cmd {
+	return c.handle(TriggerNewConversation, "", nil, false, ConversationResetMsg{SessionInfo: json.RawMessage("{}")})
+}
+
+// Disconnect plays the next disconnect step, or disconnects cleanly
+func (c *MockClient) Disconnect() tea.Cmd {
+	return c.handle(TriggerDisconnect, "", nil, false, DisconnectedMsg{})
+}
+
+// Reconnect attempts to reconnect after a delay
+func (c *MockClient) Reconnect(config Config, delay time.Duration) tea.Cmd {
+	return tea.Tick(delay, func(t time.Time) tea.Msg {
+		return RetryMsg{Cmd: c.Connect(config)}
+	})
+}
+
+// handle records a call and plays the matching scenario step, falling back
+// to the default reply
+func (c *MockClient) handle(trigger, subject string, payload map[string]any, canFail bool, fallback tea.Msg) tea.Cmd {
+	c.mu.Lock()
+	c.calls = append(c.calls, MockCall{Trigger: trigger, Subject: subject, Payload: payload})
+	events, scripted := c.nextStep(trigger, subject)
+	failed := !scripted && canFail && c.ErrorRate > 0 && c.rand.Float64() < c.ErrorRate
+	c.mu.Unlock()
+
+	switch {
+	case scripted:
+		return c.play(events)
+	case failed:
+		err := ErrorMsg{Err: errors.New("mock: injected " + trigger + " failure"), Component: "Mock Client"}
+		return func() tea.Msg { return err }
+	case fallback == nil:
+		return nil
+	}
+	return func() tea.Msg { return fallback }
+}
+
+// nextStep finds the first unused step for the call; callers hold c.mu
+func (c *MockClient) nextStep(trigger, subject string) ([]ScenarioEvent, bool) {
+	if c.scenario == nil {
+		return nil, false
+	}
+	for i, step := range c.scenario.Steps {
+		if c.used[i] || !step.matches(trigger, subject) {
+			continue
+		}
+		if !step.Repeat {
+			c.used[i] = true
+		}
+		return step.Events, true
+	}
+	return nil, false
+}
+
+// play delivers events with their delays; messages go to the program since
+// a command can only return one of them
+func (c *MockClient) play(events []ScenarioEvent) tea.Cmd {
+	return func() tea.Msg {
+		for _, event := range events {
+			if event.Delay > 0 {
+				time.Sleep(event.Delay)
+			}
+			// Events are validated when the scenario is parsed
+			if msg, err := event.Msg(); err == nil {
+				c.deliver(msg)
+			}
+		}
+		return nil
+	}
+}
+
+// deliver sends a message to the test hook or the program
+func (c *MockClient) deliver(msg tea.Msg) {
+	switch {
+	case c.onMessage != nil:
+		c.onMessage(msg)
+	case c.program != nil:
+		c.program.Send(msg)
+	}
+}
@@ -0,0 +1,75 @@
+package phoenix
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestMockClientScenario(t *testing.T) {
+	scenario, err := LoadScenario("testdata/scenarios/stream_then_rate_limit.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := NewMockClient(scenario)
+	var received []tea.Msg
+	client.OnMessage(func(msg tea.Msg) {
+		received = append(received, msg)
+	})
+
+	client.Connect(Config{IsAuth: true})()
+	client.Connect(Config{})()
+	start := time.Now()
+	client.SendMessage("hello duck")()
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("Expected chunk delays to be honored, took %s", elapsed)
+	}
+	client.SendMessage("again")()
+	client.SendMessage("and again")()
+
+	want := []tea.Msg{
+		ConnectedMsg{SocketType: AuthSocketType},
+		ConnectedMsg{SocketType: UserSocketType},
+		ConversationThinkingMsg{Stage: "Analyzing request"},
+		StreamStartMsg{ID: "answer-1"},
+		StreamDataMsg{ID: "answer-1", Data: "Hello "},
+		StreamDataMsg{ID: "answer-1", Data: "there!"},
+		StreamEndMsg{ID: "answer-1"},
+		ProviderErrorMsg{Provider: "openai", Model: "gpt-4", Message: "rate limit exceeded"},
+		ProviderErrorMsg{Provider: "openai", Model: "gpt-4", Message: "rate limit exceeded"},
+	}
+	if !reflect.DeepEqual(received, want) {
+		t.Errorf("Unexpected messages:\n got  %#v\n want %#v", received, want)
+	}
+	if calls := client.Calls(); len(calls) != 5 || calls[2].Subject != "hello duck" {
+		t.Errorf("Unexpected calls %#v", calls)
+	}
+}
+
+func TestMockClientDefaults(t *testing.T) {
+	client := NewMockClient(nil)
+	if msg := client.Connect(Config{})(); msg != (ConnectedMsg{SocketType: UserSocketType}) {
+		t.Errorf("Expected default connect, got %#v", msg)
+	}
+	if _, ok := client.SendMessage("hi")().(ConversationResponseMsg); !ok {
+		t.Error("Expected canned response")
+	}
+
+	// Unscripted calls fail at the configured rate
+	client.ErrorRate = 1
+	if _, ok := client.SendMessage("hi")().(ErrorMsg); !ok {
+		t.Error("Expected injected error")
+	}
+}
+
+func TestParseScenarioValidates(t *testing.T) {
+	if _, err := ParseScenario([]byte("steps:\n  - on: sned\n")); err == nil {
+		t.Error("Expected unknown trigger error")
+	}
+	if _, err := ParseScenario([]byte("steps:\n  - on: send\n    events:\n      - type: explode\n")); err == nil {
+		t.Error("Expected unknown event error")
+	}
+}
@@ -0,0 +1,202 @@
+package phoenix
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// DefaultOllamaURL is the default address of a local Ollama server
+const DefaultOllamaURL = "http://localhost:11434"
+
+// OllamaClient talks directly to a local Ollama HTTP API, bypassing the server
+type OllamaClient struct {
+	baseURL string
+	http    *http.Client
+	program *tea.Program
+	streams *StreamCoalescer
+
+	mu       sync.Mutex
+	cancel   context.CancelFunc
+	streamID int
+}
+
+// OllamaMessage is a chat message in the Ollama API format
+type OllamaMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// OllamaModelsMsg lists the models installed on the local Ollama server
+type OllamaModelsMsg struct {
+	Models []string
+}
+
+// ollamaChatChunk is one line of a streamed /api/chat response
+type ollamaChatChunk struct {
+	Message OllamaMessage `json:"message"`
+	Done    bool          `json:"done"`
+	Error   string        `json:"error"`
+}
+
+// NewOllamaClient creates a new Ollama client
+func NewOllamaClient(baseURL string) *OllamaClient {
+	if baseURL == "" {
+		baseURL = DefaultOllamaURL
+	}
+	o := &OllamaClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		// No overall timeout; long generations are cancelled explicitly
+		http: &http.Client{},
+	}
+	o.streams = NewStreamCoalescer(StreamFlushInterval, o.deliver)
+	return o
+}
+
+// SetProgram sets the tea.Program used to deliver streamed chunks
+func (o *OllamaClient) SetProgram(program *tea.Program) {
+	o.program = program
+}
+
+// BaseURL returns the Ollama server address
+func (o *OllamaClient) BaseURL() string {
+	return o.baseURL
+}
+
+// Chat streams a chat completion, delivering StreamStartMsg, StreamDataMsg, and StreamEndMsg
+func (o *OllamaClient) Chat(model string, messages []OllamaMessage, temperature float64) tea.Cmd {
+	o.mu.Lock()
+	o.streamID++
+	id := fmt.Sprintf("ollama-%d", o.streamID)
+	ctx, cancel := context.WithCancel(context.Background())
+	o.cancel = cancel
+	o.mu.Unlock()
+
+	return func() tea.Msg {
+		defer cancel()
+		// Chunks held back must arrive before the message returned here
+		defer o.streams.Flush()
+
+		body, err := json.Marshal(map[string]any{
+			"model":    model,
+			"messages": messages,
+			"stream":   true,
+			"options": map[string]any{
+				"temperature": temperature,
+			},
+		})
+		if err != nil {
+			return ErrorMsg{Err: err, Component: "Ollama"}
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.baseURL+"/api/chat", bytes.NewReader(body))
+		if err != nil {
+			return ErrorMsg{Err: err, Component: "Ollama"}
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := o.http.Do(req)
+		if err != nil {
+			if errors.Is(ctx.Err(), context.Canceled) {
+				return nil
+			}
+			return ErrorMsg{Err: Errorf(ErrOllamaUnreachable, "ollama not reachable at %s: %w", o.baseURL, err), Component: "Ollama"}
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			var apiErr struct {
+				Error string `json:"error"`
+			}
+			_ = json.NewDecoder(resp.Body).Decode(&apiErr)
+			if apiErr.Error == "" {
+				apiErr.Error = resp.Status
+			}
+			return ErrorMsg{Err: Errorf(ErrOllama, "ollama: %s", apiErr.Error), Component: "Ollama"}
+		}
+
+		o.send(StreamStartMsg{ID: id})
+
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			var chunk ollamaChatChunk
+			if err := json.Unmarshal(scanner.Bytes(), &chunk); err != nil {
+				continue
+			}
+			if chunk.Error != "" {
+				return ErrorMsg{Err: Errorf(ErrOllama, "ollama: %s", chunk.Error), Component: "Ollama"}
+			}
+			if chunk.Message.Content != "" {
+				o.send(StreamDataMsg{ID: id, Data: chunk.Message.Content})
+			}
+			if chunk.Done {
+				break
+			}
+		}
+		if err := scanner.Err(); err != nil && !errors.Is(ctx.Err(), context.Canceled) {
+			return ErrorMsg{Err: err, Component: "Ollama"}
+		}
+
+		return StreamEndMsg{ID: id}
+	}
+}
+
+// Cancel stops the in-flight chat request
+func (o *OllamaClient) Cancel() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.cancel != nil {
+		o.cancel()
+		o.cancel = nil
+	}
+}
+
+// ListModels fetches the installed models
+func (o *OllamaClient) ListModels() tea.Cmd {
+	return func() tea.Msg {
+		client := &http.Client{Timeout: 5 * time.Second}
+		resp, err := client.Get(o.baseURL + "/api/tags")
+		if err != nil {
+			return ErrorMsg{Err: Errorf(ErrOllamaUnreachable, "ollama not reachable at %s: %w", o.baseURL, err), Component: "Ollama"}
+		}
+		defer resp.Body.Close()
+
+		var tags struct {
+			Models []struct {
+				Name string `json:"name"`
+			} `json:"models"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+			return ErrorMsg{Err: err, Component: "Ollama"}
+		}
+
+		var models []string
+		for _, model := range tags.Models {
+			models = append(models, model.Name)
+		}
+		return OllamaModelsMsg{Models: models}
+	}
+}
+
+// send delivers a message to the program if one is set, coalescing
+// stream chunks
+func (o *OllamaClient) send(msg tea.Msg) {
+	o.streams.Send(msg)
+}
+
+// deliver hands a message to the program
+func (o *OllamaClient) deliver(msg tea.Msg) {
+	if o.program != nil {
+		o.program.Send(msg)
+	}
+}
@@ -0,0 +1,103 @@
+package phoenix
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ollamaServer answers /api/chat with the given lines, or status when it
+// isn't OK
+func ollamaServer(t *testing.T, status int, lines ...string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Model  string `json:"model"`
+			Stream bool   `json:"stream"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Model != "llama3" || !body.Stream {
+			t.Errorf("Expected a streamed llama3 request, got %+v (%v)", body, err)
+		}
+		w.WriteHeader(status)
+		for _, line := range lines {
+			fmt.Fprintln(w, line)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// streamedChat runs a chat against the server, returning the messages sent
+// along the way and the one the command returned
+func streamedChat(t *testing.T, url string) ([]tea.Msg, tea.Msg) {
+	t.Helper()
+	client := NewOllamaClient(url)
+	var sent []tea.Msg
+	client.streams = NewStreamCoalescer(0, func(msg tea.Msg) { sent = append(sent, msg) })
+	result := client.Chat("llama3", []OllamaMessage{{Role: "user", Content: "hi"}}, 0.7)()
+	return sent, result
+}
+
+func TestOllamaClient_StreamsChunks(t *testing.T) {
+	server := ollamaServer(t, http.StatusOK,
+		`{"message":{"role":"assistant","content":"Hel"}}`,
+		`not json`,
+		`{"message":{"role":"assistant","content":"lo"}}`,
+		`{"done":true}`,
+		`{"message":{"role":"assistant","content":"after done"}}`,
+	)
+
+	sent, result := streamedChat(t, server.URL)
+	want := []tea.Msg{
+		StreamStartMsg{ID: "ollama-1"},
+		StreamDataMsg{ID: "ollama-1", Data: "Hel"},
+		StreamDataMsg{ID: "ollama-1", Data: "lo"},
+	}
+	if len(sent) != len(want) {
+		t.Fatalf("Expected %d messages, got %#v", len(want), sent)
+	}
+	for i := range want {
+		if sent[i] != want[i] {
+			t.Errorf("Message %d: expected %#v, got %#v", i, want[i], sent[i])
+		}
+	}
+	if result != (StreamEndMsg{ID: "ollama-1"}) {
+		t.Errorf("Expected the stream to end, got %#v", result)
+	}
+}
+
+func TestOllamaClient_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		lines  []string
+		want   ErrorCode
+	}{
+		{"error chunk", http.StatusOK, []string{`{"message":{"content":"par"}}`, `{"error":"out of memory"}`}, ErrOllama},
+		{"refused request", http.StatusNotFound, []string{`{"error":"model \"llama3\" not found"}`}, ErrOllama},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, result := streamedChat(t, ollamaServer(t, tt.status, tt.lines...).URL)
+			msg, ok := result.(ErrorMsg)
+			var coded *Error
+			if !ok || !errors.As(msg.Err, &coded) || coded.Code != tt.want {
+				t.Fatalf("Expected a %s error, got %#v", tt.want, result)
+			}
+		})
+	}
+
+	server := ollamaServer(t, http.StatusOK)
+	server.Close()
+	_, result := streamedChat(t, server.URL)
+	msg, ok := result.(ErrorMsg)
+	var coded *Error
+	if !ok || !errors.As(msg.Err, &coded) || coded.Code != ErrOllamaUnreachable {
+		t.Fatalf("Expected an unreachable server reported, got %#v", result)
+	}
+}
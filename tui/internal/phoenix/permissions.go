@@ -0,0 +1,40 @@
+package phoenix
+
+// Permissions the server can grant an account
+const (
+	PermissionAPIKeys  = "api_keys" // Manage the account's own API keys
+	PermissionSessions = "sessions" // List and sign out the account's sessions
+	PermissionPlanning = "planning" // Start planning sessions
+	PermissionAdmin    = "admin"    // Server health and other accounts' keys and sessions
+	PermissionAll      = "*"        // Granted by the admin role
+)
+
+// RoleAdmin is the role that grants every permission
+const RoleAdmin = "admin"
+
+// Permissions is what the signed-in account may do. A nil set means the
+// server sent no roles or permissions, so nothing is hidden and the server
+// decides
+type Permissions map[string]bool
+
+// Has reports whether a permission is granted
+func (p Permissions) Has(permission string) bool {
+	return p == nil || p[PermissionAll] || p[permission]
+}
+
+// Grants returns the permissions from the user's roles and permission list
+func (u AuthUser) Grants() Permissions {
+	if u.Roles == nil && u.Permissions == nil {
+		return nil
+	}
+	grants := Permissions{}
+	for _, role := range u.Roles {
+		if role == RoleAdmin {
+			grants[PermissionAll] = true
+		}
+	}
+	for _, permission := range u.Permissions {
+		grants[permission] = true
+	}
+	return grants
+}
@@ -1,199 +1,98 @@
 package phoenix
 
 import (
-	"encoding/json"
-	"fmt"
-
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/nshafer/phx"
 )
 
+// planningTopic is the planning channel
+const planningTopic = "planning:lobby"
+
 // PlanningClient handles planning channel operations
 type PlanningClient struct {
-	socket  *phx.Socket
-	channel *phx.Channel
-	program *tea.Program
-}
-
-// NewPlanningClient creates a new planning client
-func NewPlanningClient() *PlanningClient {
-	return &PlanningClient{}
+	channels *ChannelManager
 }
 
-// SetSocket sets the Phoenix socket
-func (p *PlanningClient) SetSocket(socket *phx.Socket) {
-	p.socket = socket
-}
-
-// SetProgram sets the Bubble Tea program for sending messages
-func (p *PlanningClient) SetProgram(program *tea.Program) {
-	p.program = program
+// NewPlanningClient creates a planning client on the shared channel manager
+func NewPlanningClient(channels *ChannelManager) *PlanningClient {
+	return &PlanningClient{channels: channels}
 }
 
 // JoinPlanningChannel joins the planning channel
 func (p *PlanningClient) JoinPlanningChannel() tea.Cmd {
-	return func() tea.Msg {
-		if p.socket == nil {
-			return ErrorMsg{
-				Err:       fmt.Errorf("socket not connected"),
-				Component: "Planning Client",
+	join := p.channels.Join(ChannelSpec{
+		Topic:     planningTopic,
+		Component: "Planning Client",
+		Handlers:  p.channelHandlers(),
+		OnJoin: func(channel *phx.Channel, response any) tea.Msg {
+			return PlanningChannelJoinedMsg{
+				Channel:  channel,
+				Response: response,
 			}
+		},
+	})
+	return func() tea.Msg {
+		if msg := join(); msg != nil {
+			return msg
 		}
+		return PlanningChannelJoiningMsg{}
+	}
+}
+
+// channelHandlers returns the planning channel event handlers
+func (p *PlanningClient) channelHandlers() map[string]func(any) {
+	return map[string]func(any){
+		// Handle planning started event
+		"planning_started": func(payload any) {
+			var data PlanningStarted
+			p.channels.decode(planningTopic, "planning_started", payload, &data)
+			p.channels.Send(PlanningStartedMsg{Started: data})
+		},
 		
-		// Join planning:lobby channel
-		channel := p.socket.Channel("planning:lobby", nil)
+		// Handle planning step event
+		"planning_step": func(payload any) {
+			var data PlanningStep
+			p.channels.decode(planningTopic, "planning_step", payload, &data)
+			p.channels.Send(PlanningStepMsg{Step: data})
+		},
 		
-		// Join the channel
-		join, err := channel.Join()
-		if err != nil {
-			return ErrorMsg{
-				Err:       fmt.Errorf("failed to join planning channel: %w", err),
-				Component: "Planning Client",
-			}
-		}
+		// Handle planning completed event
+		"planning_completed": func(payload any) {
+			var data PlanningCompleted
+			p.channels.decode(planningTopic, "planning_completed", payload, &data)
+			p.channels.Send(PlanningCompletedMsg{Completed: data})
+		},
 		
-		// Handle join response
-		join.Receive("ok", func(response any) {
-			p.program.Send(PlanningChannelJoinedMsg{
-				Channel:  channel,
-				Response: response,
-			})
-		})
+		// Handle planning error event
+		"planning_error": func(payload any) {
+			var data PlanningError
+			p.channels.decode(planningTopic, "planning_error", payload, &data)
+			p.channels.Send(PlanningErrorMsg{Error: data})
+		},
 		
-		join.Receive("error", func(response any) {
-			p.program.Send(ErrorMsg{
-				Err:       fmt.Errorf("planning channel join failed: %v", response),
-				Component: "Planning Client",
-			})
-		})
+		// Handle planning cancelled event
+		"planning_cancelled": func(payload any) {
+			p.channels.Send(PlanningCancelledMsg{})
+		},
 		
-		join.Receive("timeout", func(response any) {
-			p.program.Send(ErrorMsg{
-				Err:       fmt.Errorf("planning channel join timeout"),
-				Component: "Planning Client",
+		// Handle error event
+		"error": func(payload any) {
+			p.channels.Send(ErrorMsg{
+				Err:       Errorf(ErrChannel, "planning channel error: %v", payload),
+				Component: "Planning Channel",
 			})
-		})
-		
-		// Set up channel event handlers
-		p.setupChannelHandlers(channel)
-		
-		p.channel = channel
-		return PlanningChannelJoiningMsg{}
+		},
 	}
 }
 
-// setupChannelHandlers sets up event handlers for planning channel
-func (p *PlanningClient) setupChannelHandlers(channel *phx.Channel) {
-	// Handle planning started event
-	channel.On("planning_started", func(payload any) {
-		data, _ := json.Marshal(payload)
-		p.program.Send(PlanningStartedMsg{
-			Data: data,
-		})
-	})
-	
-	// Handle planning step event
-	channel.On("planning_step", func(payload any) {
-		data, _ := json.Marshal(payload)
-		p.program.Send(PlanningStepMsg{
-			Data: data,
-		})
-	})
-	
-	// Handle planning completed event
-	channel.On("planning_completed", func(payload any) {
-		data, _ := json.Marshal(payload)
-		p.program.Send(PlanningCompletedMsg{
-			Data: data,
-		})
-	})
-	
-	// Handle planning error event
-	channel.On("planning_error", func(payload any) {
-		data, _ := json.Marshal(payload)
-		p.program.Send(PlanningErrorMsg{
-			Data: data,
-		})
-	})
-	
-	// Handle planning cancelled event
-	channel.On("planning_cancelled", func(payload any) {
-		p.program.Send(PlanningCancelledMsg{})
-	})
-	
-	// Handle error event
-	channel.On("error", func(payload any) {
-		p.program.Send(ErrorMsg{
-			Err:       fmt.Errorf("planning channel error: %v", payload),
-			Component: "Planning Channel",
-		})
-	})
-}
-
 // Push sends a message to the planning channel
 func (p *PlanningClient) Push(event string, payload map[string]any) tea.Cmd {
-	return func() tea.Msg {
-		if p.channel == nil {
-			return ErrorMsg{
-				Err:       fmt.Errorf("planning channel not joined"),
-				Component: "Planning Client",
-			}
-		}
-		
-		push, err := p.channel.Push(event, payload)
-		if err != nil {
-			return ErrorMsg{
-				Err:       err,
-				Component: "Planning Push",
-			}
-		}
-		
-		push.Receive("ok", func(response any) {
-			// Success - nothing specific to do
-		})
-		
-		push.Receive("error", func(response any) {
-			p.program.Send(ErrorMsg{
-				Err:       fmt.Errorf("planning push failed: %v", response),
-				Component: "Planning Push",
-			})
-		})
-		
-		push.Receive("timeout", func(response any) {
-			// For planning events, we might handle responses through channel events
-			if event != "start_planning" && event != "cancel_planning" {
-				p.program.Send(ErrorMsg{
-					Err:       fmt.Errorf("planning push timeout for event: %s", event),
-					Component: "Planning Push",
-				})
-			}
-		})
-		
-		return nil
-	}
+	return p.channels.Push(planningTopic, event, payload, PushOptions{ReportTimeout: true})
 }
 
 // PushAsync sends a message to the planning channel without waiting for responses
 func (p *PlanningClient) PushAsync(event string, payload map[string]any) tea.Cmd {
-	return func() tea.Msg {
-		if p.channel == nil {
-			return ErrorMsg{
-				Err:       fmt.Errorf("planning channel not joined"),
-				Component: "Planning Client",
-			}
-		}
-		
-		// Just push without setting up response handlers
-		_, err := p.channel.Push(event, payload)
-		if err != nil {
-			return ErrorMsg{
-				Err:       err,
-				Component: "Planning Push",
-			}
-		}
-		
-		return nil
-	}
+	return p.channels.Push(planningTopic, event, payload, PushOptions{NoReply: true})
 }
 
 // StartPlanning starts a planning session
@@ -221,10 +120,7 @@ func (p *PlanningClient) SendPlanningFeedback(stepID string, feedback string) te
 
 // LeaveChannel leaves the planning channel
 func (p *PlanningClient) LeaveChannel() {
-	if p.channel != nil {
-		p.channel.Leave()
-		p.channel = nil
-	}
+	p.channels.Leave(planningTopic)
 }
 
 // Planning channel message types
@@ -237,19 +133,19 @@ type PlanningChannelJoinedMsg struct {
 }
 
 type PlanningStartedMsg struct {
-	Data json.RawMessage
+	Started PlanningStarted
 }
 
 type PlanningStepMsg struct {
-	Data json.RawMessage
+	Step PlanningStep
 }
 
 type PlanningCompletedMsg struct {
-	Data json.RawMessage
+	Completed PlanningCompleted
 }
 
 type PlanningErrorMsg struct {
-	Data json.RawMessage
+	Error PlanningError
 }
 
 type PlanningCancelledMsg struct{}
\ No newline at end of file
@@ -0,0 +1,69 @@
+package phoenix
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/gorilla/websocket"
+)
+
+// CredentialsRevokedMsg is sent when the server says mid-session that it no
+// longer accepts the session's API key or token
+type CredentialsRevokedMsg struct {
+	Credential string // "api_key" or "token"
+	Message    string
+}
+
+// revokedJoinReasons are join refusals meaning the socket's credentials
+// are no longer accepted
+var revokedJoinReasons = []string{"unauthenticated", "credentials_revoked", "invalid_token", "token_expired", "token_revoked", "revoked"}
+
+// revokedReason reports whether a refusal's reason means the credentials
+// are no longer accepted
+func revokedReason(reason string) bool {
+	return slices.Contains(revokedJoinReasons, strings.ToLower(reason))
+}
+
+// IsCredentialsRevoked reports whether err means the server no longer
+// accepts the session's credentials
+func IsCredentialsRevoked(err error) bool {
+	var coded *Error
+	return errors.As(err, &coded) && coded.Code == ErrCredentialsRevoked
+}
+
+// joinRejection describes a refused channel join, telling refusals for
+// revoked credentials apart from other ones
+func joinRejection(topic string, response any) error {
+	if data, ok := response.(map[string]any); ok {
+		if reason := strings.ToLower(getString(data, "reason")); revokedReason(reason) {
+			return Errorf(ErrCredentialsRevoked, "%s join refused: %s", topic, reason)
+		}
+	}
+	return Errorf(ErrJoinRejected, "%s join rejected: %v", topic, response)
+}
+
+// handshakeRejection finds out why a credentialed socket's handshake
+// failed. The socket library drops the HTTP response, so the handshake is
+// tried once more to read its status: 401 and 403 mean the server refused
+// the credentials rather than being down
+func handshakeRejection(err error, dialer *websocket.Dialer, endPoint *url.URL) error {
+	if !errors.Is(err, websocket.ErrBadHandshake) {
+		return err
+	}
+	conn, resp, dialErr := dialer.Dial(endPoint.String(), nil)
+	if dialErr == nil {
+		conn.Close()
+		return err
+	}
+	if resp == nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return Errorf(ErrCredentialsRevoked, "the server refused the socket's credentials (HTTP %d)", resp.StatusCode)
+	}
+	return err
+}
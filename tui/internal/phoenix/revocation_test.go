@@ -0,0 +1,50 @@
+package phoenix
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestHandshakeRejection(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("token") == "revoked" {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	endPoint := func(token string) *url.URL {
+		u, _ := url.Parse(strings.Replace(server.URL, "http", "ws", 1) + "/socket/websocket?token=" + token)
+		return u
+	}
+	dialer := websocket.DefaultDialer
+
+	if err := handshakeRejection(websocket.ErrBadHandshake, dialer, endPoint("revoked")); !IsCredentialsRevoked(err) {
+		t.Errorf("Expected a 403 read as revoked credentials, got %v", err)
+	}
+	if err := handshakeRejection(websocket.ErrBadHandshake, dialer, endPoint("valid")); !errors.Is(err, websocket.ErrBadHandshake) || IsCredentialsRevoked(err) {
+		t.Errorf("Expected other refusals left alone, got %v", err)
+	}
+	refused := errors.New("dial tcp: connection refused")
+	if err := handshakeRejection(refused, dialer, endPoint("revoked")); err != refused {
+		t.Errorf("Expected errors other than a bad handshake passed through, got %v", err)
+	}
+}
+
+func TestJoinRejection(t *testing.T) {
+	if err := joinRejection("conversation:lobby", map[string]any{"reason": "token_revoked"}); !IsCredentialsRevoked(err) {
+		t.Errorf("Expected token_revoked read as revoked credentials, got %v", err)
+	}
+	// Lacking a permission isn't a reason to sign out
+	if err := joinRejection("admin:dashboard", map[string]any{"reason": "unauthorized"}); IsCredentialsRevoked(err) || ClassifyError(err).Code != ErrJoinRejected {
+		t.Errorf("Expected an ordinary join refusal, got %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+package phoenix
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"gopkg.in/yaml.v3"
+)
+
+// Scenario triggers: the MockClient call that plays a step
+const (
+	TriggerConnect         = "connect"
+	TriggerJoin            = "join"
+	TriggerPush            = "push"
+	TriggerSend            = "send"
+	TriggerNewConversation = "new_conversation"
+	TriggerDisconnect      = "disconnect"
+)
+
+// defaultStreamID is used for stream events that don't name a stream
+const defaultStreamID = "scenario"
+
+// Scenario is a scripted sequence of server behaviors for the MockClient
+type Scenario struct {
+	Name  string         `yaml:"name"`
+	Steps []ScenarioStep `yaml:"steps"`
+}
+
+// ScenarioStep plays its events when a matching client call is made; steps
+// are used once, in order, unless Repeat is set
+type ScenarioStep struct {
+	On     string          `yaml:"on"`
+	Match  string          `yaml:"match"` // Substring of the message, event, topic, or socket type
+	Repeat bool            `yaml:"repeat"`
+	Events []ScenarioEvent `yaml:"events"`
+}
+
+// ScenarioEvent is one server message, delivered after Delay
+type ScenarioEvent struct {
+	Type      string         `yaml:"type"`
+	Delay     time.Duration  `yaml:"delay"`
+	Socket    string         `yaml:"socket"`
+	ID        string         `yaml:"id"`
+	Data      string         `yaml:"data"`
+	Message   string         `yaml:"message"`
+	Component string         `yaml:"component"`
+	Stage     string         `yaml:"stage"`
+	Position  int            `yaml:"position"`
+	Category  string         `yaml:"category"`
+	Provider  string         `yaml:"provider"`
+	Model     string         `yaml:"model"`
+	Path      string         `yaml:"path"`
+	Event     string         `yaml:"event"`
+	Response  map[string]any `yaml:"response"`
+}
+
+// LoadScenario reads and validates a YAML scenario file
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	scenario, err := ParseScenario(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return scenario, nil
+}
+
+// ParseScenario parses and validates a YAML scenario
+func ParseScenario(data []byte) (*Scenario, error) {
+	var scenario Scenario
+	if err := yaml.Unmarshal(data, &scenario); err != nil {
+		return nil, err
+	}
+
+	for i, step := range scenario.Steps {
+		switch step.On {
+		case TriggerConnect, TriggerJoin, TriggerPush, TriggerSend, TriggerNewConversation, TriggerDisconnect:
+		default:
+			return nil, fmt.Errorf("step %d: unknown trigger %q", i+1, step.On)
+		}
+		for j, event := range step.Events {
+			if _, err := event.Msg(); err != nil {
+				return nil, fmt.Errorf("step %d, event %d: %w", i+1, j+1, err)
+			}
+		}
+	}
+	return &scenario, nil
+}
+
+// Msg converts the event to the message the real client would send
+func (e ScenarioEvent) Msg() (tea.Msg, error) {
+	streamID := e.ID
+	if streamID == "" {
+		streamID = defaultStreamID
+	}
+
+	switch e.Type {
+	case "connected":
+		return ConnectedMsg{SocketType: e.socketType()}, nil
+	case "disconnected":
+		msg := DisconnectedMsg{SocketType: e.socketType()}
+		if e.Message != "" {
+			msg.Error = errors.New(e.Message)
+		}
+		return msg, nil
+	case "channel_joining":
+		return ChannelJoiningMsg{}, nil
+	case "error":
+		component := e.Component
+		if component == "" {
+			component = "Phoenix"
+		}
+		return ErrorMsg{Err: errors.New(e.Message), Component: component}, nil
+	case "provider_error":
+		return ProviderErrorMsg{Provider: e.Provider, Model: e.Model, Message: e.Message}, nil
+	case "thinking":
+		return ConversationThinkingMsg{Stage: e.Stage, QueuePosition: e.Position}, nil
+	case "queued":
+		return ConversationQueuedMsg{Position: e.Position}, nil
+	case "response":
+		data, err := json.Marshal(e.Response)
+		if err != nil {
+			return nil, fmt.Errorf("response: %w", err)
+		}
+		return ConversationResponseMsg{Response: data}, nil
+	case "reset":
+		return ConversationResetMsg{SessionInfo: json.RawMessage("{}")}, nil
+	case "cancelled":
+		return ProcessingCancelledMsg{}, nil
+	case "stream_start":
+		return StreamStartMsg{ID: streamID}, nil
+	case "stream_chunk":
+		return StreamDataMsg{ID: streamID, Data: e.Data}, nil
+	case "stream_end":
+		return StreamEndMsg{ID: streamID}, nil
+	case "status":
+		return StatusUpdateMsg{Category: e.Category, Text: e.Message, Timestamp: time.Now()}, nil
+	case "file_changed":
+		return FileChangedMsg{Path: e.Path, Event: e.Event}, nil
+	}
+	return nil, fmt.Errorf("unknown event type %q", e.Type)
+}
+
+// socketType returns the event's socket, defaulting to the user socket
+func (e ScenarioEvent) socketType() SocketType {
+	if e.Socket == string(AuthSocketType) {
+		return AuthSocketType
+	}
+	return UserSocketType
+}
+
+// matches reports whether the step handles a call with the given subject
+func (s ScenarioStep) matches(trigger, subject string) bool {
+	return s.On == trigger && strings.Contains(subject, s.Match)
+}
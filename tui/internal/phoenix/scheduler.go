@@ -0,0 +1,392 @@
+package phoenix
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Command categories the scheduler limits. Events outside them, such as
+// cancels and auth, are never queued
+const (
+	CategoryChat     = "chat"     // Messages to the assistant
+	CategoryFiles    = "files"    // File reads, writes, and searches
+	CategoryAnalysis = "analysis" // Planning and indexing, run in the background
+	CategoryTotal    = "total"    // All limited categories together
+)
+
+// DefaultCommandLimits are the concurrent commands allowed per category
+var DefaultCommandLimits = map[string]int{
+	CategoryTotal:    6,
+	CategoryChat:     2,
+	CategoryFiles:    4,
+	CategoryAnalysis: 1,
+}
+
+// commandSlotTTL frees the slot of a command whose answer never arrives
+const commandSlotTTL = 5 * time.Minute
+
+// fileEvents are the conversation events of the file API
+var fileEvents = map[string]bool{
+	"list_files": true, "read_file": true, "write_file": true, "format_code": true, "search_code": true,
+	"upload_begin": true, "upload_chunk": true, "upload_commit": true, "download_begin": true, "download_chunk": true,
+}
+
+// CommandCategory returns the category an event is limited under, "" for
+// none
+func CommandCategory(topic, event string) string {
+	switch {
+	case strings.HasPrefix(topic, "planning:") && event != "cancel_planning",
+		topic == indexTopic && event == "build_index":
+		return CategoryAnalysis
+	case fileEvents[event]:
+		return CategoryFiles
+	case event == "message":
+		return CategoryChat
+	}
+	return ""
+}
+
+// QueuedCommand is a command waiting for a slot
+type QueuedCommand struct {
+	ID         int
+	Category   string
+	Event      string
+	Background bool
+	Position   int // 1-based place in line
+	Label      string
+	QueuedAt   time.Time
+}
+
+// CommandQueueMsg reports the queue after it changed, and the commands
+// running per category. Seq orders the snapshots, which may arrive out of
+// order
+type CommandQueueMsg struct {
+	Seq     uint64
+	Queued  []QueuedCommand
+	Running map[string]int
+}
+
+// ticket is a command waiting for or holding a slot
+type ticket struct {
+	id         int
+	category   string
+	event      string
+	label      string
+	background bool
+	queuedAt   time.Time
+	ready      chan error
+	generation int // Reset count when the slot was taken
+}
+
+// Scheduler limits how many commands run at once per category, queueing
+// the rest. Interactive commands go ahead of background ones in line. It is
+// safe for concurrent use
+type Scheduler struct {
+	mu       sync.Mutex
+	limits   map[string]int
+	running  map[string]int
+	queue    []*ticket
+	held     map[string]*ticket // Slots awaiting Finish, by request ID
+	nextID   int
+	reset    int // Times Reset was called; slots from before are ignored
+	seq      uint64
+	onChange func(CommandQueueMsg)
+}
+
+// NewScheduler creates a scheduler with the default limits
+func NewScheduler() *Scheduler {
+	s := &Scheduler{running: make(map[string]int), held: make(map[string]*ticket)}
+	s.SetLimits(nil)
+	return s
+}
+
+// SetLimits replaces the limits; categories not given keep their defaults,
+// and a negative limit means unlimited
+func (s *Scheduler) SetLimits(limits map[string]int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limits = make(map[string]int, len(DefaultCommandLimits))
+	for category, limit := range DefaultCommandLimits {
+		s.limits[category] = limit
+	}
+	for category, limit := range limits {
+		if limit != 0 {
+			s.limits[category] = limit
+		}
+	}
+	if waiting := len(s.queue); waiting > 0 {
+		if s.promote(); len(s.queue) < waiting {
+			s.changed()
+		}
+	}
+}
+
+// Limit returns a category's limit, negative for unlimited
+func (s *Scheduler) Limit(category string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.limits[category]
+}
+
+// acquire waits for a slot in category. It returns a ticket to release, or
+// nil for unlimited events
+func (s *Scheduler) acquire(ctx context.Context, category, event, label string) (*ticket, error) {
+	if category == "" {
+		return nil, nil
+	}
+	s.mu.Lock()
+	s.nextID++
+	t := &ticket{
+		id:         s.nextID,
+		category:   category,
+		event:      event,
+		label:      label,
+		background: category == CategoryAnalysis,
+		queuedAt:   time.Now(),
+		ready:      make(chan error, 1),
+	}
+	s.enqueue(t)
+	s.promote()
+	if !s.queued(t.id) {
+		s.mu.Unlock()
+		return t, nil
+	}
+	s.changed()
+	s.mu.Unlock()
+
+	select {
+	case err := <-t.ready:
+		if err != nil {
+			return nil, err
+		}
+		return t, nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		removed := s.removeQueued(t.id)
+		if removed {
+			s.changed()
+		}
+		s.mu.Unlock()
+		if !removed {
+			// The slot was granted as ctx ended; give it back
+			if err := <-t.ready; err == nil {
+				s.release(t)
+			}
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// release frees a ticket's slot and starts whoever is next
+func (s *Scheduler) release(t *ticket) {
+	if t == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t.generation == s.reset && s.running[t.category] > 0 {
+		s.running[t.category]--
+		s.running[CategoryTotal]--
+	}
+	if waiting := len(s.queue); waiting > 0 {
+		if s.promote(); len(s.queue) < waiting {
+			s.changed()
+		}
+	}
+}
+
+// hold keeps a ticket's slot until Finish is called with requestID, or
+// until commandSlotTTL passes
+func (s *Scheduler) hold(t *ticket, requestID string) {
+	if t == nil {
+		return
+	}
+	if requestID == "" {
+		s.release(t)
+		return
+	}
+	s.mu.Lock()
+	s.held[requestID] = t
+	s.mu.Unlock()
+	time.AfterFunc(commandSlotTTL, func() { s.Finish(requestID) })
+}
+
+// Finish frees the slot a command holds once its answer arrived
+func (s *Scheduler) Finish(requestID string) {
+	s.mu.Lock()
+	t, ok := s.held[requestID]
+	delete(s.held, requestID)
+	s.mu.Unlock()
+	if ok {
+		s.release(t)
+	}
+}
+
+// Bump moves a queued command to the front of the line as interactive
+func (s *Scheduler) Bump(id int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, t := range s.queue {
+		if t.id == id {
+			t.background = false
+			s.queue = append([]*ticket{t}, append(s.queue[:i:i], s.queue[i+1:]...)...)
+			s.promote()
+			s.changed()
+			return true
+		}
+	}
+	return false
+}
+
+// Cancel drops a queued command; it fails without being sent
+func (s *Scheduler) Cancel(id int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range s.queue {
+		if t.id == id {
+			s.removeQueued(id)
+			t.ready <- Errorf(ErrRequestFailed, "%s cancelled while queued", t.event)
+			s.changed()
+			return true
+		}
+	}
+	return false
+}
+
+// Reset fails every queued command and forgets running ones, as when the
+// socket is closed
+func (s *Scheduler) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range s.queue {
+		t.ready <- Errorf(ErrNotConnected, "%s dropped from the queue: disconnected", t.event)
+	}
+	s.queue = nil
+	s.reset++
+	s.running = make(map[string]int)
+	s.held = make(map[string]*ticket)
+	s.changed()
+}
+
+// Snapshot returns the queued commands in line order and the running counts
+func (s *Scheduler) Snapshot() CommandQueueMsg {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.snapshot()
+}
+
+// OnChange sets the function told about every queue change. It is called
+// with the scheduler locked, so it must not call back into it
+func (s *Scheduler) OnChange(fn func(CommandQueueMsg)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onChange = fn
+}
+
+// snapshot builds a CommandQueueMsg; s.mu must be held
+func (s *Scheduler) snapshot() CommandQueueMsg {
+	msg := CommandQueueMsg{Seq: s.seq, Running: make(map[string]int, len(s.running))}
+	for category, n := range s.running {
+		if n > 0 {
+			msg.Running[category] = n
+		}
+	}
+	for i, t := range s.queue {
+		msg.Queued = append(msg.Queued, QueuedCommand{
+			ID: t.id, Category: t.category, Event: t.event, Background: t.background,
+			Position: i + 1, Label: t.label, QueuedAt: t.queuedAt,
+		})
+	}
+	return msg
+}
+
+// changed reports the new state; s.mu must be held
+func (s *Scheduler) changed() {
+	s.seq++
+	if s.onChange != nil {
+		s.onChange(s.snapshot())
+	}
+}
+
+// free reports whether category has a slot; s.mu must be held
+func (s *Scheduler) free(category string) bool {
+	within := func(name string) bool {
+		limit := s.limits[name]
+		return limit < 0 || s.running[name] < limit
+	}
+	return within(category) && within(CategoryTotal)
+}
+
+// start gives a ticket its slot; s.mu must be held
+func (s *Scheduler) start(t *ticket) {
+	t.generation = s.reset
+	s.running[t.category]++
+	s.running[CategoryTotal]++
+}
+
+// enqueue puts a ticket in line behind others of its priority; s.mu must
+// be held
+func (s *Scheduler) enqueue(t *ticket) {
+	s.queue = append(s.queue, t)
+	sort.SliceStable(s.queue, func(i, j int) bool {
+		return !s.queue[i].background && s.queue[j].background
+	})
+}
+
+// promote starts queued tickets in line order while slots are free; a
+// ticket whose category is full doesn't block others. s.mu must be held
+func (s *Scheduler) promote() {
+	for i := 0; i < len(s.queue); {
+		t := s.queue[i]
+		if !s.free(t.category) {
+			i++
+			continue
+		}
+		s.queue = append(s.queue[:i], s.queue[i+1:]...)
+		s.start(t)
+		t.ready <- nil
+	}
+}
+
+// queued reports whether a ticket is waiting; s.mu must be held
+func (s *Scheduler) queued(id int) bool {
+	for _, t := range s.queue {
+		if t.id == id {
+			return true
+		}
+	}
+	return false
+}
+
+// removeQueued drops a ticket from the line; s.mu must be held
+func (s *Scheduler) removeQueued(id int) bool {
+	for i, t := range s.queue {
+		if t.id == id {
+			s.queue = append(s.queue[:i], s.queue[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// commandLabel describes a payload in the queue: the message or the path
+func commandLabel(payload any) string {
+	fields, _ := payload.(map[string]any)
+	for _, key := range []string{"content", "path", "query"} {
+		if label, ok := fields[key].(string); ok && label != "" {
+			return label
+		}
+	}
+	return ""
+}
+
+// sendQueueChanges reports queue changes to the UI. Snapshots are sent from
+// their own goroutine since commands may be scheduled from Update
+func sendQueueChanges(m *ChannelManager) func(CommandQueueMsg) {
+	return func(msg CommandQueueMsg) {
+		go m.Send(msg)
+	}
+}
@@ -0,0 +1,102 @@
+package phoenix
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// acquireAsync takes a slot on its own goroutine, reporting when it has it
+func acquireAsync(s *Scheduler, category, event string) chan *ticket {
+	got := make(chan *ticket, 1)
+	go func() {
+		t, _ := s.acquire(context.Background(), category, event, "")
+		got <- t
+	}()
+	return got
+}
+
+// waitQueued waits until n commands are in line
+func waitQueued(t *testing.T, s *Scheduler, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for len(s.Snapshot().Queued) != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d queued, got %+v", n, s.Snapshot().Queued)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSchedulerQueuesInteractiveFirst(t *testing.T) {
+	s := NewScheduler()
+	s.SetLimits(map[string]int{CategoryTotal: 1})
+
+	first, err := s.acquire(context.Background(), CategoryFiles, "read_file", "")
+	if err != nil || first == nil {
+		t.Fatalf("Expected a free slot, got %v", err)
+	}
+	background := acquireAsync(s, CategoryAnalysis, "build_index")
+	waitQueued(t, s, 1)
+	interactive := acquireAsync(s, CategoryChat, "message")
+	waitQueued(t, s, 2)
+
+	if queued := s.Snapshot().Queued; queued[0].Event != "message" || queued[1].Position != 2 || !queued[1].Background {
+		t.Errorf("Expected the message ahead of the background analysis, got %+v", queued)
+	}
+
+	s.release(first)
+	next := <-interactive
+	if next == nil || next.event != "message" {
+		t.Fatalf("Expected the message to run next, got %+v", next)
+	}
+	s.release(next)
+	if last := <-background; last == nil || last.event != "build_index" {
+		t.Errorf("Expected the analysis to run last, got %+v", last)
+	}
+}
+
+func TestSchedulerBumpAndCancel(t *testing.T) {
+	s := NewScheduler()
+	s.SetLimits(map[string]int{CategoryFiles: 1})
+	running, _ := s.acquire(context.Background(), CategoryFiles, "read_file", "")
+	acquireAsync(s, CategoryFiles, "list_files")
+	waitQueued(t, s, 1)
+	second := acquireAsync(s, CategoryFiles, "write_file")
+	waitQueued(t, s, 2)
+
+	queued := s.Snapshot().Queued
+	if !s.Bump(queued[1].ID) || s.Snapshot().Queued[0].Event != "write_file" {
+		t.Errorf("Expected write_file first after bumping, got %+v", s.Snapshot().Queued)
+	}
+	if !s.Cancel(queued[0].ID) || len(s.Snapshot().Queued) != 1 {
+		t.Errorf("Expected list_files dropped, got %+v", s.Snapshot().Queued)
+	}
+
+	// Commands outside the limited categories never wait
+	if free, err := s.acquire(context.Background(), "", "cancel_processing", ""); free != nil || err != nil {
+		t.Errorf("Expected no slot for an unlimited event, got %+v, %v", free, err)
+	}
+
+	// A held slot is freed when the answer arrives
+	s.hold(running, "req-1")
+	s.Finish("req-1")
+	if next := <-second; next == nil || next.event != "write_file" {
+		t.Errorf("Expected write_file to run once read_file finished, got %+v", next)
+	}
+}
+
+func TestCommandCategory(t *testing.T) {
+	tests := map[[2]string]string{
+		{"conversation:lobby", "message"}:           CategoryChat,
+		{"conversation:lobby", "read_file"}:         CategoryFiles,
+		{"planning:lobby", "start_planning"}:        CategoryAnalysis,
+		{indexTopic, "build_index"}:                 CategoryAnalysis,
+		{"conversation:lobby", "cancel_processing"}: "",
+	}
+	for in, want := range tests {
+		if got := CommandCategory(in[0], in[1]); got != want {
+			t.Errorf("CommandCategory(%s, %s) = %q, want %q", in[0], in[1], got, want)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package phoenix
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Typed server payloads. Fields tagged schema:"required" must be present
+// and non-empty; see DecodePayload. RequestID echoes the push that caused
+// the event
+
+// PlanningStarted is the planning_started payload
+type PlanningStarted struct {
+	SessionID string `json:"session_id" schema:"required"`
+	RequestID string `json:"request_id"`
+}
+
+// PlanningStep is the planning_step payload
+type PlanningStep struct {
+	StepID      string         `json:"step_id" schema:"required"`
+	Type        string         `json:"type"`
+	Description string         `json:"description" schema:"required"`
+	Details     map[string]any `json:"details"`
+	RequestID   string         `json:"request_id"`
+}
+
+// PlanningStepSummary is a step listed in planning_completed
+type PlanningStepSummary struct {
+	Description string `json:"description"`
+}
+
+// PlanningCompleted is the planning_completed payload
+type PlanningCompleted struct {
+	Summary   string                `json:"summary"`
+	Steps     []PlanningStepSummary `json:"steps" schema:"required"`
+	RequestID string                `json:"request_id"`
+}
+
+// PlanningError is the planning_error payload; details may be text or an object
+type PlanningError struct {
+	Message   string `json:"message" schema:"required"`
+	Details   any    `json:"details"`
+	RequestID string `json:"request_id"`
+}
+
+// HistoryMessage is one message in a history payload
+type HistoryMessage struct {
+	ID        string `json:"id"` // Server message ID, used to drop duplicates across pages
+	Role      string `json:"role"`
+	Content   string `json:"content"`
+	Timestamp string `json:"timestamp"`
+}
+
+// ConversationHistory is the history payload
+type ConversationHistory struct {
+	ConversationID string           `json:"conversation_id"`
+	Messages       []HistoryMessage `json:"messages"`
+	Count          int              `json:"count"`
+	Before         string           `json:"before"`      // Cursor this page was requested with, empty for the latest
+	NextCursor     string           `json:"next_cursor"` // Cursor for the next older page, empty at the start
+	RequestID      string           `json:"request_id"`
+}
+
+// ContextPreferences are the context fields the TUI reads; the context
+// itself is open ended, so other keys are not checked
+type ContextPreferences struct {
+	PreferredModel    string `json:"preferred_model"`
+	PreferredProvider string `json:"preferred_provider"`
+}
+
+// ConversationContext is the context_updated payload
+type ConversationContext struct {
+	Context   ContextPreferences `json:"context"`
+	Timestamp string             `json:"timestamp"`
+	RequestID string             `json:"request_id"`
+}
+
+// SchemaIssue describes how a server payload differed from its schema
+type SchemaIssue struct {
+	Field   string
+	Problem string
+}
+
+// String formats the issue for logs
+func (i SchemaIssue) String() string {
+	if i.Field == "" {
+		return i.Problem
+	}
+	return i.Field + ": " + i.Problem
+}
+
+// DecodePayload decodes a channel payload into the struct dst points to and
+// validates it. Decoding is lenient: fields that decode are kept, and type
+// mismatches, unknown fields, and missing required fields are returned as
+// issues instead of being dropped silently
+func DecodePayload(payload any, dst any) []SchemaIssue {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return []SchemaIssue{{Problem: err.Error()}}
+	}
+
+	var issues []SchemaIssue
+	if err := json.Unmarshal(data, dst); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			issues = append(issues, SchemaIssue{Field: typeErr.Field, Problem: fmt.Sprintf("expected %s, got %s", typeErr.Type, typeErr.Value)})
+		} else {
+			return []SchemaIssue{{Problem: err.Error()}}
+		}
+	}
+
+	t := reflect.TypeOf(dst).Elem()
+	var fields map[string]any
+	if json.Unmarshal(data, &fields) == nil {
+		known := jsonFields(t)
+		var unknown []string
+		for name := range fields {
+			if _, ok := known[name]; !ok {
+				unknown = append(unknown, name)
+			}
+		}
+		sort.Strings(unknown)
+		for _, name := range unknown {
+			issues = append(issues, SchemaIssue{Field: name, Problem: "unknown field"})
+		}
+	}
+
+	return append(issues, missingRequired(reflect.ValueOf(dst).Elem())...)
+}
+
+// jsonFields maps a struct's JSON field names to their struct fields
+func jsonFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" {
+			name = field.Name
+		}
+		if name != "-" {
+			fields[name] = field
+		}
+	}
+	return fields
+}
+
+// missingRequired lists required fields left empty
+func missingRequired(v reflect.Value) []SchemaIssue {
+	var issues []SchemaIssue
+	for name, field := range jsonFields(v.Type()) {
+		if field.Tag.Get("schema") == "required" && v.FieldByIndex(field.Index).IsZero() {
+			issues = append(issues, SchemaIssue{Field: name, Problem: "required field is missing"})
+		}
+	}
+	sort.Slice(issues, func(i, j int) bool { return issues[i].Field < issues[j].Field })
+	return issues
+}
+
+// PayloadDiagnosticMsg reports a server payload that didn't match its schema.
+// The decoded message is still delivered with whatever fields were usable
+type PayloadDiagnosticMsg struct {
+	Topic   string
+	Event   string
+	Issues  []SchemaIssue
+	Payload json.RawMessage
+}
+
+// decode decodes a channel payload and reports schema issues to the UI
+func (m *ChannelManager) decode(topic, event string, payload any, dst any) {
+	issues := DecodePayload(payload, dst)
+	if len(issues) == 0 {
+		return
+	}
+	data, _ := json.Marshal(payload)
+	m.Send(PayloadDiagnosticMsg{Topic: topic, Event: event, Issues: issues, Payload: data})
+}
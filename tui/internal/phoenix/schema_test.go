@@ -0,0 +1,58 @@
+package phoenix
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecodePayloadValid(t *testing.T) {
+	var step PlanningStep
+	issues := DecodePayload(map[string]any{
+		"step_id":     "1",
+		"type":        "analysis",
+		"description": "Read the failing test",
+		"details":     map[string]any{"file": "parser.go"},
+	}, &step)
+
+	if len(issues) != 0 {
+		t.Errorf("Expected no issues, got %v", issues)
+	}
+	if step.Description != "Read the failing test" || step.Details["file"] != "parser.go" {
+		t.Errorf("Unexpected step: %+v", step)
+	}
+}
+
+func TestDecodePayloadReportsIssues(t *testing.T) {
+	var history ConversationHistory
+	issues := DecodePayload(map[string]any{
+		"conversation_id": "abc",
+		"messages":        []any{map[string]any{"role": "user", "content": "hi"}},
+		"count":           "one",
+		"cursor":          "next",
+	}, &history)
+
+	want := []SchemaIssue{
+		{Field: "count", Problem: "expected int, got string"},
+		{Field: "cursor", Problem: "unknown field"},
+	}
+	if !reflect.DeepEqual(issues, want) {
+		t.Errorf("Expected %v, got %v", want, issues)
+	}
+	// Fields that decoded are kept
+	if history.ConversationID != "abc" || len(history.Messages) != 1 || history.Messages[0].Content != "hi" {
+		t.Errorf("Expected the valid fields to decode, got %+v", history)
+	}
+}
+
+func TestDecodePayloadRequiredFields(t *testing.T) {
+	var step PlanningStep
+	issues := DecodePayload(map[string]any{"type": "analysis"}, &step)
+
+	want := []SchemaIssue{
+		{Field: "description", Problem: "required field is missing"},
+		{Field: "step_id", Problem: "required field is missing"},
+	}
+	if !reflect.DeepEqual(issues, want) {
+		t.Errorf("Expected %v, got %v", want, issues)
+	}
+}
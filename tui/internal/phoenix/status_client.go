@@ -10,152 +10,62 @@ import (
 
 // StatusClient handles status channel operations
 type StatusClient struct {
-	socket    *phx.Socket
-	channel   *phx.Channel
+	channels  *ChannelManager
 	channelID string
-	program   *tea.Program
 }
 
-// NewStatusClient creates a new status client
-func NewStatusClient() *StatusClient {
-	return &StatusClient{}
+// NewStatusClient creates a status client on the shared channel manager
+func NewStatusClient(channels *ChannelManager) *StatusClient {
+	return &StatusClient{channels: channels}
 }
 
-// SetSocket sets the Phoenix socket
-func (s *StatusClient) SetSocket(socket *phx.Socket) {
-	s.socket = socket
-}
-
-// SetProgram sets the Bubble Tea program for sending messages
-func (s *StatusClient) SetProgram(program *tea.Program) {
-	s.program = program
-}
-
-// JoinStatusChannel joins a status channel for a specific conversation
+// JoinStatusChannel joins a status channel for a specific conversation,
+// leaving the previous conversation's channel
 func (s *StatusClient) JoinStatusChannel(conversationID string) tea.Cmd {
-	return func() tea.Msg {
-		if s.socket == nil {
-			return ErrorMsg{
-				Component: "StatusClient",
-				Err:       fmt.Errorf("socket not initialized"),
-			}
-		}
-
-		channelName := fmt.Sprintf("status:%s", conversationID)
-		s.channelID = channelName
-		
-		// Create and join the channel
-		channel := s.socket.Channel(channelName, nil)
-		s.channel = channel
-		
-		// Set up event handlers
-		channel.On("status_update", func(payload any) {
-			s.handleStatusUpdate(channel, payload)
-		})
-		
-		// Join the channel
-		join, err := channel.Join()
-		if err != nil {
-			return ErrorMsg{
-				Component: "StatusClient",
-				Err:       fmt.Errorf("failed to join status channel: %w", err),
-			}
-		}
-
-		// Handle join responses
-		join.Receive("ok", func(response any) {
-			// Parse response
-			var resp struct {
-				ConversationID       string              `json:"conversation_id"`
-				AvailableCategories []string            `json:"available_categories"`
-				SubscribedCategories []string           `json:"subscribed_categories"`
-				CategoryDescriptions map[string]string  `json:"category_descriptions"`
-			}
-			
+	channelName := fmt.Sprintf("status:%s", conversationID)
+	if s.channelID != "" && s.channelID != channelName {
+		s.channels.Leave(s.channelID)
+	}
+	s.channelID = channelName
+
+	return s.channels.Join(ChannelSpec{
+		Topic:     channelName,
+		Component: "StatusClient",
+		Handlers: map[string]func(any){
+			"status_update": s.handleStatusUpdate,
+		},
+		OnJoin: func(_ *phx.Channel, response any) tea.Msg {
+			msg := StatusChannelJoinedMsg{}
 			if data, ok := response.(map[string]any); ok {
-				if convID, ok := data["conversation_id"].(string); ok {
-					resp.ConversationID = convID
-				}
-				if cats, ok := data["available_categories"].([]any); ok {
-					for _, cat := range cats {
-						if catStr, ok := cat.(string); ok {
-							resp.AvailableCategories = append(resp.AvailableCategories, catStr)
-						}
-					}
-				}
+				msg.ConversationID, _ = data["conversation_id"].(string)
+				msg.AvailableCategories = stringList(data["available_categories"])
 				if descs, ok := data["category_descriptions"].(map[string]any); ok {
-					resp.CategoryDescriptions = make(map[string]string)
+					msg.CategoryDescriptions = make(map[string]string)
 					for cat, desc := range descs {
 						if descStr, ok := desc.(string); ok {
-							resp.CategoryDescriptions[cat] = descStr
+							msg.CategoryDescriptions[cat] = descStr
 						}
 					}
 				}
 			}
-			
-			s.program.Send(StatusChannelJoinedMsg{
-				ConversationID:       resp.ConversationID,
-				AvailableCategories:  resp.AvailableCategories,
-				CategoryDescriptions: resp.CategoryDescriptions,
-			})
-		})
-		
-		join.Receive("error", func(response any) {
-			s.program.Send(ErrorMsg{
-				Component: "StatusClient",
-				Err:       fmt.Errorf("status channel join failed: %v", response),
-			})
-		})
-		
-		join.Receive("timeout", func(response any) {
-			s.program.Send(ErrorMsg{
-				Component: "StatusClient",
-				Err:       fmt.Errorf("status channel join timeout"),
-			})
-		})
-		
-		return nil
-	}
+			return msg
+		},
+	})
+}
+
+// subscribedReply reads the categories confirmed by a subscription change
+func subscribedReply(response any) tea.Msg {
+	data, _ := response.(map[string]any)
+	return StatusCategoriesSubscribedMsg{Categories: stringList(data["subscribed"])}
 }
 
 // SubscribeCategories subscribes to specific status categories
 func (s *StatusClient) SubscribeCategories(categories []string) tea.Cmd {
-	return func() tea.Msg {
-		if s.channel == nil {
-			return ErrorMsg{
-				Component: "StatusClient",
-				Err:       fmt.Errorf("status channel not joined"),
-			}
-		}
-
-		push, err := s.channel.Push("subscribe_categories", map[string]any{
-			"categories": categories,
-		})
-		if err != nil {
-			return ErrorMsg{
-				Component: "StatusClient",
-				Err:       fmt.Errorf("failed to push subscribe: %w", err),
-			}
-		}
-
-		push.Receive("ok", func(response any) {
-			var subscribed []string
-			if data, ok := response.(map[string]any); ok {
-				if subs, ok := data["subscribed"].([]any); ok {
-					for _, sub := range subs {
-						if subStr, ok := sub.(string); ok {
-							subscribed = append(subscribed, subStr)
-						}
-					}
-				}
-			}
-			
-			s.program.Send(StatusCategoriesSubscribedMsg{
-				Categories: subscribed,
-			})
-		})
-		
-		push.Receive("error", func(response any) {
+	return s.channels.Push(s.channelID, "subscribe_categories", map[string]any{
+		"categories": categories,
+	}, PushOptions{
+		OnReply: subscribedReply,
+		OnError: func(response any) tea.Msg {
 			var errMsg string
 			if data, ok := response.(map[string]any); ok {
 				if reason, ok := data["reason"].(string); ok {
@@ -165,156 +75,46 @@ func (s *StatusClient) SubscribeCategories(categories []string) tea.Cmd {
 					errMsg += ": " + msg
 				}
 			}
-			
-			s.program.Send(ErrorMsg{
-				Component: "StatusClient",
-				Err:       fmt.Errorf("subscribe failed: %s", errMsg),
-			})
-		})
-		
-		push.Receive("timeout", func(response any) {
-			s.program.Send(ErrorMsg{
+			return ErrorMsg{
 				Component: "StatusClient",
-				Err:       fmt.Errorf("Connection timeout for event: subscribe_categories"),
-			})
-		})
-		
-		return nil
-	}
+				Err:       Errorf(ErrRequestFailed, "subscribe failed: %s", errMsg),
+			}
+		},
+		ReportTimeout: true,
+	})
 }
 
 // UnsubscribeCategories unsubscribes from specific status categories
 func (s *StatusClient) UnsubscribeCategories(categories []string) tea.Cmd {
-	return func() tea.Msg {
-		if s.channel == nil {
-			return ErrorMsg{
-				Component: "StatusClient",
-				Err:       fmt.Errorf("status channel not joined"),
-			}
-		}
-
-		push, err := s.channel.Push("unsubscribe_categories", map[string]any{
-			"categories": categories,
-		})
-		if err != nil {
-			return ErrorMsg{
-				Component: "StatusClient",
-				Err:       fmt.Errorf("failed to push unsubscribe: %w", err),
-			}
-		}
-
-		push.Receive("ok", func(response any) {
-			var subscribed []string
-			if data, ok := response.(map[string]any); ok {
-				if subs, ok := data["subscribed"].([]any); ok {
-					for _, sub := range subs {
-						if subStr, ok := sub.(string); ok {
-							subscribed = append(subscribed, subStr)
-						}
-					}
-				}
-			}
-			
-			s.program.Send(StatusCategoriesSubscribedMsg{
-				Categories: subscribed,
-			})
-		})
-		
-		push.Receive("error", func(response any) {
-			s.program.Send(ErrorMsg{
-				Component: "StatusClient",
-				Err:       fmt.Errorf("unsubscribe failed: %v", response),
-			})
-		})
-		
-		push.Receive("timeout", func(response any) {
-			s.program.Send(ErrorMsg{
-				Component: "StatusClient",
-				Err:       fmt.Errorf("Connection timeout for event: unsubscribe_categories"),
-			})
-		})
-		
-		return nil
-	}
+	return s.channels.Push(s.channelID, "unsubscribe_categories", map[string]any{
+		"categories": categories,
+	}, PushOptions{OnReply: subscribedReply, ReportTimeout: true})
 }
 
 // GetSubscriptions gets current category subscriptions
 func (s *StatusClient) GetSubscriptions() tea.Cmd {
-	return func() tea.Msg {
-		if s.channel == nil {
-			return ErrorMsg{
-				Component: "StatusClient",
-				Err:       fmt.Errorf("status channel not joined"),
+	return s.channels.Push(s.channelID, "get_subscriptions", nil, PushOptions{
+		OnReply: func(response any) tea.Msg {
+			data, _ := response.(map[string]any)
+			return StatusSubscriptionsMsg{
+				Subscribed: stringList(data["subscribed_categories"]),
+				Available:  stringList(data["available_categories"]),
 			}
-		}
-
-		push, err := s.channel.Push("get_subscriptions", nil)
-		if err != nil {
-			return ErrorMsg{
-				Component: "StatusClient",
-				Err:       fmt.Errorf("failed to push get_subscriptions: %w", err),
-			}
-		}
-
-		push.Receive("ok", func(response any) {
-			var subscribed, available []string
-			
-			if data, ok := response.(map[string]any); ok {
-				if subs, ok := data["subscribed_categories"].([]any); ok {
-					for _, sub := range subs {
-						if subStr, ok := sub.(string); ok {
-							subscribed = append(subscribed, subStr)
-						}
-					}
-				}
-				if avail, ok := data["available_categories"].([]any); ok {
-					for _, av := range avail {
-						if avStr, ok := av.(string); ok {
-							available = append(available, avStr)
-						}
-					}
-				}
-			}
-			
-			s.program.Send(StatusSubscriptionsMsg{
-				Subscribed: subscribed,
-				Available:  available,
-			})
-		})
-		
-		push.Receive("error", func(response any) {
-			s.program.Send(ErrorMsg{
-				Component: "StatusClient",
-				Err:       fmt.Errorf("get subscriptions failed: %v", response),
-			})
-		})
-		
-		push.Receive("timeout", func(response any) {
-			s.program.Send(ErrorMsg{
-				Component: "StatusClient",
-				Err:       fmt.Errorf("Connection timeout for event: get_subscriptions"),
-			})
-		})
-		
-		return nil
-	}
+		},
+		ReportTimeout: true,
+	})
 }
 
 // LeaveChannel leaves the status channel
 func (s *StatusClient) LeaveChannel() {
-	if s.channel != nil {
-		s.channel.Leave()
-		s.channel = nil
+	if s.channelID != "" {
+		s.channels.Leave(s.channelID)
+		s.channelID = ""
 	}
 }
 
 // handleStatusUpdate handles incoming status update messages
-func (s *StatusClient) handleStatusUpdate(_ *phx.Channel, payload any) {
-	// Skip if program not set
-	if s.program == nil {
-		return
-	}
-
+func (s *StatusClient) handleStatusUpdate(payload any) {
 	// Parse the status update
 	data, ok := payload.(map[string]any)
 	if !ok {
@@ -325,6 +125,12 @@ func (s *StatusClient) handleStatusUpdate(_ *phx.Channel, payload any) {
 	text, _ := data["text"].(string)
 	metadata, _ := data["metadata"].(map[string]any)
 	
+	// Priority may be sent alongside the category or in the metadata
+	priority, _ := data["priority"].(string)
+	if priority == "" {
+		priority, _ = metadata["priority"].(string)
+	}
+	
 	// Parse timestamp
 	var timestamp time.Time
 	if ts, ok := data["timestamp"].(string); ok {
@@ -334,8 +140,9 @@ func (s *StatusClient) handleStatusUpdate(_ *phx.Channel, payload any) {
 	}
 
 	// Send to the UI
-	s.program.Send(StatusUpdateMsg{
+	s.channels.Send(StatusUpdateMsg{
 		Category:  category,
+		Priority:  priority,
 		Text:      text,
 		Metadata:  metadata,
 		Timestamp: timestamp,
@@ -361,6 +168,7 @@ type StatusSubscriptionsMsg struct {
 
 type StatusUpdateMsg struct {
 	Category  string
+	Priority  string // "critical", "high", "normal", or "low"; empty derives it from the category
 	Text      string
 	Metadata  map[string]any
 	Timestamp time.Time
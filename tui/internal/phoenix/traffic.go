@@ -0,0 +1,97 @@
+package phoenix
+
+import (
+	"context"
+	"net"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/nshafer/phx"
+)
+
+// Traffic counts the bytes the sockets send and receive, and the messages
+// received per topic. It is safe for concurrent use
+type Traffic struct {
+	sent     atomic.Int64
+	received atomic.Int64
+
+	mu     sync.Mutex
+	topics map[string]int
+}
+
+// TopicCount is the number of messages received on a topic
+type TopicCount struct {
+	Topic    string
+	Messages int
+}
+
+// NewTraffic creates an empty traffic counter
+func NewTraffic() *Traffic {
+	return &Traffic{topics: make(map[string]int)}
+}
+
+// Sent returns the bytes written to the sockets, TLS included
+func (t *Traffic) Sent() int64 {
+	return t.sent.Load()
+}
+
+// Received returns the bytes read from the sockets, TLS included
+func (t *Traffic) Received() int64 {
+	return t.received.Load()
+}
+
+// Topics returns the topics by messages received, busiest first
+func (t *Traffic) Topics() []TopicCount {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	counts := make([]TopicCount, 0, len(t.topics))
+	for topic, n := range t.topics {
+		counts = append(counts, TopicCount{Topic: topic, Messages: n})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Messages != counts[j].Messages {
+			return counts[i].Messages > counts[j].Messages
+		}
+		return counts[i].Topic < counts[j].Topic
+	})
+	return counts
+}
+
+// countMessage notes a message received on a topic
+func (t *Traffic) countMessage(msg phx.Message) {
+	t.mu.Lock()
+	t.topics[msg.Topic]++
+	t.mu.Unlock()
+}
+
+// meter wraps a dial function so its connections are counted
+func (t *Traffic) meter(dial func(ctx context.Context, network, addr string) (net.Conn, error)) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		return &meteredConn{Conn: conn, traffic: t}, nil
+	}
+}
+
+// meteredConn counts the bytes read and written on a connection
+type meteredConn struct {
+	net.Conn
+	traffic *Traffic
+}
+
+// Read reads and counts the bytes received
+func (c *meteredConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.traffic.received.Add(int64(n))
+	return n, err
+}
+
+// Write writes and counts the bytes sent
+func (c *meteredConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	c.traffic.sent.Add(int64(n))
+	return n, err
+}
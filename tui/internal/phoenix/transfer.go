@@ -0,0 +1,187 @@
+package phoenix
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// CapabilityChunkedTransfer is offered by servers that move large files over
+// the channel in chunks
+const CapabilityChunkedTransfer = "chunked_transfer"
+
+// ChunkThreshold is the size above which files are transferred in chunks
+// instead of in one read_file or write_file payload
+const ChunkThreshold = 256 * 1024
+
+// transferChunkSize is the bytes sent or fetched per chunk
+const transferChunkSize = 64 * 1024
+
+// maxDownloadSize is the largest file Download accepts, so a bad size from
+// the server can't make the client reserve unbounded memory
+const maxDownloadSize = 256 << 20
+
+// transferChunkAttempts is how often a chunk is tried before giving up
+const transferChunkAttempts = 3
+
+// TransferProgress is told the bytes transferred so far and the total
+type TransferProgress func(done, total int64)
+
+// TransferProgressMsg reports a chunked transfer's progress
+type TransferProgressMsg struct {
+	Path   string
+	Upload bool
+	Done   int64
+	Total  int64
+}
+
+// ReportProgress returns a TransferProgress that sends TransferProgressMsg
+// to the program. Use it only off the event loop, e.g. in a tea.Cmd
+func (c *Client) ReportProgress(path string, upload bool) TransferProgress {
+	return func(done, total int64) {
+		c.channels.Send(TransferProgressMsg{Path: path, Upload: upload, Done: done, Total: total})
+	}
+}
+
+// Upload writes data to path on the server in chunks. The server keeps
+// what it received of an interrupted upload of the same content, so
+// calling Upload again resumes where it stopped
+func (c *Client) Upload(ctx context.Context, path string, data []byte, progress TransferProgress) error {
+	sum := sha256.Sum256(data)
+	total := int64(len(data))
+	response, err := c.requestWithin(ctx, fileRequestTimeout, "upload_begin", map[string]any{
+		"path":       path,
+		"size":       total,
+		"sha256":     hex.EncodeToString(sum[:]),
+		"chunk_size": transferChunkSize,
+	})
+	if err != nil {
+		return err
+	}
+	id, _ := response["upload_id"].(string)
+	if id == "" {
+		return Errorf(ErrBadResponse, "upload_begin: no upload_id for %s", path)
+	}
+	offset := int64(numberField(response, "offset"))
+
+	for offset < total {
+		report(progress, offset, total)
+		end := min(offset+transferChunkSize, total)
+		response, err := c.requestChunk(ctx, "upload_chunk", map[string]any{
+			"upload_id": id,
+			"offset":    offset,
+			"data":      base64.StdEncoding.EncodeToString(data[offset:end]),
+		})
+		if err != nil {
+			return err
+		}
+		// The server says where to continue, which also covers a resend of
+		// a chunk it already had
+		next := int64(numberField(response, "offset"))
+		if next <= offset {
+			return Errorf(ErrTransferStalled, "upload_chunk: server stopped at byte %d of %s", next, path)
+		}
+		offset = next
+	}
+	report(progress, total, total)
+
+	_, err = c.requestWithin(ctx, fileRequestTimeout, "upload_commit", map[string]any{"upload_id": id})
+	c.invalidate("upload_commit")
+	return err
+}
+
+// Download reads path from the server in chunks, checking the result
+// against the server's checksum. Failed chunks are retried from where they
+// stopped
+func (c *Client) Download(ctx context.Context, path string, progress TransferProgress) ([]byte, error) {
+	response, err := c.requestWithin(ctx, fileRequestTimeout, "download_begin", map[string]any{"path": path})
+	if err != nil {
+		return nil, err
+	}
+	id, _ := response["download_id"].(string)
+	if id == "" {
+		return nil, Errorf(ErrBadResponse, "download_begin: no download_id for %s", path)
+	}
+	total := int64(numberField(response, "size"))
+	checksum, _ := response["sha256"].(string)
+
+	data, err := readChunks(path, total, progress, func(offset int64) ([]byte, error) {
+		response, err := c.requestChunk(ctx, "download_chunk", map[string]any{
+			"download_id": id,
+			"offset":      offset,
+			"length":      transferChunkSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+		encoded, _ := response["data"].(string)
+		chunk, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, Errorf(ErrBadResponse, "download_chunk: %w", err)
+		}
+		return chunk, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if sum := sha256.Sum256(data); checksum != "" && hex.EncodeToString(sum[:]) != checksum {
+		return nil, Errorf(ErrTransferCorrupt, "download of %s is corrupt: checksum mismatch", path)
+	}
+	return data, nil
+}
+
+// readChunks collects total bytes of path from fetch, which returns the
+// chunk at an offset. A size out of range, or chunks that don't add up to
+// exactly total, fail the download
+func readChunks(path string, total int64, progress TransferProgress, fetch func(offset int64) ([]byte, error)) ([]byte, error) {
+	if total < 0 || total > maxDownloadSize {
+		return nil, Errorf(ErrBadResponse, "download_begin: size %d of %s is outside 0-%d bytes", total, path, maxDownloadSize)
+	}
+	data := make([]byte, 0, total)
+	for int64(len(data)) < total {
+		report(progress, int64(len(data)), total)
+		chunk, err := fetch(int64(len(data)))
+		if err != nil {
+			return nil, err
+		}
+		if len(chunk) == 0 {
+			return nil, Errorf(ErrTransferStalled, "download_chunk: server sent nothing at byte %d of %s", len(data), path)
+		}
+		if int64(len(data)+len(chunk)) > total {
+			return nil, Errorf(ErrBadResponse, "download_chunk: server sent %d bytes of %s, more than the %d announced", len(data)+len(chunk), path, total)
+		}
+		data = append(data, chunk...)
+	}
+	report(progress, total, total)
+	return data, nil
+}
+
+// requestChunk sends a chunk request, trying again when it fails
+func (c *Client) requestChunk(ctx context.Context, event string, payload map[string]any) (map[string]any, error) {
+	var err error
+	for attempt := 0; attempt < transferChunkAttempts; attempt++ {
+		var response map[string]any
+		if response, err = c.requestWithin(ctx, fileRequestTimeout, event, payload); err == nil {
+			return response, nil
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, err
+}
+
+// report calls progress when it is set
+func report(progress TransferProgress, done, total int64) {
+	if progress != nil {
+		progress(done, total)
+	}
+}
+
+// numberField reads a JSON number from a response
+func numberField(response map[string]any, key string) float64 {
+	n, _ := response[key].(float64)
+	return n
+}
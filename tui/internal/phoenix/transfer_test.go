@@ -0,0 +1,50 @@
+package phoenix
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestReadChunks(t *testing.T) {
+	file := []byte("0123456789")
+	// chunksOf serves file n bytes at a time, padded with extra at the end
+	chunksOf := func(n int, extra string) func(int64) ([]byte, error) {
+		return func(offset int64) ([]byte, error) {
+			end := min(int(offset)+n, len(file))
+			chunk := append([]byte{}, file[offset:end]...)
+			if end == len(file) {
+				chunk = append(chunk, extra...)
+			}
+			return chunk, nil
+		}
+	}
+	tests := []struct {
+		name  string
+		total int64
+		fetch func(int64) ([]byte, error)
+		want  ErrorCode
+	}{
+		{"whole file", 10, chunksOf(4, ""), ""},
+		{"empty file", 0, chunksOf(4, ""), ""},
+		{"negative size", -1, chunksOf(4, ""), ErrBadResponse},
+		{"huge size", maxDownloadSize + 1, chunksOf(4, ""), ErrBadResponse},
+		{"more than announced", 10, chunksOf(4, "extra"), ErrBadResponse},
+		{"less than announced", 12, chunksOf(4, ""), ErrTransferStalled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := readChunks("notes.txt", tt.total, nil, tt.fetch)
+			if tt.want == "" {
+				if err != nil || !bytes.Equal(data, file[:tt.total]) {
+					t.Fatalf("Expected %q, got %q (%v)", file[:tt.total], data, err)
+				}
+				return
+			}
+			var coded *Error
+			if !errors.As(err, &coded) || coded.Code != tt.want {
+				t.Fatalf("Expected a %s error, got %v", tt.want, err)
+			}
+		})
+	}
+}
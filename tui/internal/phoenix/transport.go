@@ -0,0 +1,220 @@
+package phoenix
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"golang.org/x/net/http/httpproxy"
+)
+
+// ProxyDirect as the proxy setting connects without a proxy even when the
+// environment names one
+const ProxyDirect = "direct"
+
+// defaultPingInterval is how often a heartbeat is sent unless ping_seconds
+// says otherwise, short enough for most NAT idle timeouts
+const defaultPingInterval = 30 * time.Second
+
+// disabledPingInterval stands in for no heartbeat, which phx doesn't offer
+const disabledPingInterval = 24 * time.Hour
+
+// NetworkOptions configures how sockets reach the server, for networks that
+// require a proxy or their own certificate authority
+type NetworkOptions struct {
+	// Proxy is an http://, https://, or socks5:// URL. Empty uses
+	// HTTPS_PROXY, HTTP_PROXY, and NO_PROXY, then ALL_PROXY
+	Proxy              string `json:"proxy,omitempty"`
+	CAFile             string `json:"ca_file,omitempty"`   // PEM bundle trusted besides the system roots
+	CertFile           string `json:"cert_file,omitempty"` // Client certificate for mutual TLS
+	KeyFile            string `json:"key_file,omitempty"`  // Its private key
+	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty"`
+
+	// Compression negotiates permessage-deflate, which helps on slow links
+	// when the server supports it
+	Compression *bool `json:"compression,omitempty"`
+	// PingSeconds is the heartbeat interval; 0 is 30s, negative sends none
+	PingSeconds int `json:"ping_seconds,omitempty"`
+	// ReadTimeoutSeconds drops a connection that receives nothing for that
+	// long; 0 allows two missed heartbeats, negative waits forever
+	ReadTimeoutSeconds int `json:"read_timeout_seconds,omitempty"`
+}
+
+// Merge returns the options with those set in override replacing them
+func (o NetworkOptions) Merge(override NetworkOptions) NetworkOptions {
+	if override.Proxy != "" {
+		o.Proxy = override.Proxy
+	}
+	if override.CAFile != "" {
+		o.CAFile = override.CAFile
+	}
+	if override.CertFile != "" {
+		o.CertFile, o.KeyFile = override.CertFile, override.KeyFile
+	}
+	o.InsecureSkipVerify = o.InsecureSkipVerify || override.InsecureSkipVerify
+	if override.Compression != nil {
+		o.Compression = override.Compression
+	}
+	if override.PingSeconds != 0 {
+		o.PingSeconds = override.PingSeconds
+	}
+	if override.ReadTimeoutSeconds != 0 {
+		o.ReadTimeoutSeconds = override.ReadTimeoutSeconds
+	}
+	return o
+}
+
+// PingInterval returns the heartbeat interval, 0 when heartbeats are off
+func (o NetworkOptions) PingInterval() time.Duration {
+	switch {
+	case o.PingSeconds < 0:
+		return 0
+	case o.PingSeconds == 0:
+		return defaultPingInterval
+	}
+	return time.Duration(o.PingSeconds) * time.Second
+}
+
+// ReadTimeout returns how long a silent connection is kept, 0 for forever
+func (o NetworkOptions) ReadTimeout() time.Duration {
+	switch {
+	case o.ReadTimeoutSeconds < 0:
+		return 0
+	case o.ReadTimeoutSeconds > 0:
+		return time.Duration(o.ReadTimeoutSeconds) * time.Second
+	}
+	// Heartbeat replies arrive every interval; allow two to go missing
+	if ping := o.PingInterval(); ping > 0 {
+		return 2*ping + 10*time.Second
+	}
+	return 0
+}
+
+// heartbeatInterval returns the interval to give phx, which always sends
+// heartbeats
+func (o NetworkOptions) heartbeatInterval() time.Duration {
+	if ping := o.PingInterval(); ping > 0 {
+		return ping
+	}
+	return disabledPingInterval
+}
+
+// Dialer builds the websocket dialer for the options
+func (o NetworkOptions) Dialer() (*websocket.Dialer, error) {
+	dialer := *websocket.DefaultDialer
+
+	proxy, err := o.proxyFunc()
+	if err != nil {
+		return nil, err
+	}
+	dialer.Proxy = proxy
+
+	tlsConfig, err := o.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	dialer.TLSClientConfig = tlsConfig
+	dialer.EnableCompression = o.Compression != nil && *o.Compression
+
+	// TCP keepalives match the heartbeat so NATs see traffic either way
+	netDialer := &net.Dialer{Timeout: dialer.HandshakeTimeout, KeepAlive: o.PingInterval()}
+	readTimeout := o.ReadTimeout()
+	dialer.NetDialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := netDialer.DialContext(ctx, network, addr)
+		if err != nil || readTimeout == 0 {
+			return conn, err
+		}
+		return &deadlineConn{Conn: conn, timeout: readTimeout}, nil
+	}
+	return &dialer, nil
+}
+
+// deadlineConn fails reads after a stretch without data, so a connection
+// silently dropped by a NAT is noticed and reconnected
+type deadlineConn struct {
+	net.Conn
+	timeout time.Duration
+}
+
+// Read reads with the deadline pushed out from now
+func (c *deadlineConn) Read(b []byte) (int, error) {
+	if err := c.Conn.SetReadDeadline(time.Now().Add(c.timeout)); err != nil {
+		return 0, err
+	}
+	return c.Conn.Read(b)
+}
+
+// proxyFunc picks the proxy for each connection
+func (o NetworkOptions) proxyFunc() (func(*http.Request) (*url.URL, error), error) {
+	switch o.Proxy {
+	case ProxyDirect:
+		return nil, nil
+	case "":
+		config := httpproxy.FromEnvironment()
+		// ALL_PROXY is how SOCKS proxies are usually given
+		if config.HTTPProxy == "" && config.HTTPSProxy == "" {
+			all := os.Getenv("ALL_PROXY")
+			if all == "" {
+				all = os.Getenv("all_proxy")
+			}
+			config.HTTPProxy, config.HTTPSProxy = all, all
+		}
+		proxy := config.ProxyFunc()
+		return func(req *http.Request) (*url.URL, error) {
+			return proxy(req.URL)
+		}, nil
+	}
+
+	proxyURL, err := url.Parse(o.Proxy)
+	if err != nil || proxyURL.Host == "" {
+		return nil, Errorf(ErrProxyConfig, "invalid proxy %q: expected http://, https://, or socks5://host:port", o.Proxy)
+	}
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5":
+		return http.ProxyURL(proxyURL), nil
+	}
+	return nil, Errorf(ErrProxyConfig, "unsupported proxy scheme %q: use http, https, or socks5", proxyURL.Scheme)
+}
+
+// tlsConfig returns the TLS settings, nil for the defaults
+func (o NetworkOptions) tlsConfig() (*tls.Config, error) {
+	if o.CAFile == "" && o.CertFile == "" && !o.InsecureSkipVerify {
+		return nil, nil
+	}
+	config := &tls.Config{InsecureSkipVerify: o.InsecureSkipVerify}
+
+	if o.CAFile != "" {
+		pem, err := os.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, Errorf(ErrTLSConfig, "reading CA bundle: %w", err)
+		}
+		roots, err := x509.SystemCertPool()
+		if err != nil {
+			roots = x509.NewCertPool()
+		}
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, Errorf(ErrTLSConfig, "no certificates found in %s", o.CAFile)
+		}
+		config.RootCAs = roots
+	}
+
+	if o.CertFile != "" {
+		keyFile := o.KeyFile
+		if keyFile == "" {
+			// The key may be in the same PEM file as the certificate
+			keyFile = o.CertFile
+		}
+		cert, err := tls.LoadX509KeyPair(o.CertFile, keyFile)
+		if err != nil {
+			return nil, Errorf(ErrTLSConfig, "loading client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
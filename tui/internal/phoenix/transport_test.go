@@ -0,0 +1,106 @@
+package phoenix
+
+import (
+	"context"
+	"encoding/pem"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestNetworkOptionsProxy(t *testing.T) {
+	t.Setenv("HTTPS_PROXY", "")
+	t.Setenv("HTTP_PROXY", "")
+	t.Setenv("ALL_PROXY", "socks5://127.0.0.1:1080")
+	req, _ := http.NewRequest("GET", "https://duck.example.com/socket", nil)
+
+	dialer, err := NetworkOptions{}.Dialer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if proxy, _ := dialer.Proxy(req); proxy == nil || proxy.String() != "socks5://127.0.0.1:1080" {
+		t.Errorf("Expected ALL_PROXY used when no HTTP proxy is set, got %v", proxy)
+	}
+
+	dialer, _ = NetworkOptions{Proxy: ProxyDirect}.Dialer()
+	if dialer.Proxy != nil {
+		t.Error("Expected direct to ignore the environment")
+	}
+
+	if _, err := (NetworkOptions{Proxy: "ftp://proxy:21"}).Dialer(); err == nil || !strings.Contains(err.Error(), "unsupported proxy scheme") {
+		t.Errorf("Expected an unsupported scheme error, got %v", err)
+	}
+}
+
+func TestNetworkOptionsTrustCustomCA(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err == nil {
+			conn.Close()
+		}
+	}))
+	defer server.Close()
+	url := "wss" + strings.TrimPrefix(server.URL, "https")
+
+	// The test server's certificate isn't trusted by default
+	dialer, _ := NetworkOptions{Proxy: ProxyDirect}.Dialer()
+	if _, _, err := dialer.Dial(url, nil); err == nil {
+		t.Fatal("Expected the self-signed certificate rejected")
+	}
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, certPEM, 0644); err != nil {
+		t.Fatal(err)
+	}
+	dialer, err := NetworkOptions{Proxy: ProxyDirect, CAFile: caFile}.Dialer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, _, err := dialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Expected the CA bundle trusted, got %v", err)
+	}
+	conn.Close()
+}
+
+func TestNetworkOptionsKeepalive(t *testing.T) {
+	if got := (NetworkOptions{}).ReadTimeout(); got != 70*time.Second {
+		t.Errorf("Expected two missed heartbeats plus slack by default, got %v", got)
+	}
+	profile := NetworkOptions{PingSeconds: 10}
+	if got := (NetworkOptions{ReadTimeoutSeconds: -1}).Merge(profile); got.PingInterval() != 10*time.Second || got.ReadTimeout() != 0 {
+		t.Errorf("Expected the profile's ping over the base's disabled read timeout, got %v %v", got.PingInterval(), got.ReadTimeout())
+	}
+
+	// A connection that goes silent fails its reads after the timeout
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		if conn, err := listener.Accept(); err == nil {
+			defer conn.Close()
+			time.Sleep(2 * time.Second)
+		}
+	}()
+	dialer, _ := NetworkOptions{ReadTimeoutSeconds: 1}.Dialer()
+	conn, err := dialer.NetDialContext(context.Background(), "tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	start := time.Now()
+	if _, err := conn.Read(make([]byte, 1)); err == nil || time.Since(start) > 1500*time.Millisecond {
+		t.Errorf("Expected the read to time out after 1s, got %v after %v", err, time.Since(start))
+	}
+}
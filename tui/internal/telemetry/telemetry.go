@@ -0,0 +1,241 @@
+// Package telemetry counts feature usage and error classes for users who opt
+// in. Only names and counts are recorded, never message content, file paths,
+// or error text
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rubber_duck/tui/internal/clock"
+)
+
+// Counts is what the local telemetry file holds and what is uploaded
+type Counts struct {
+	Since    time.Time      `json:"since"`
+	Features map[string]int `json:"features"`
+	Errors   map[string]int `json:"errors"`
+}
+
+// Recorder counts events while enabled and keeps the counts in a local file
+type Recorder struct {
+	mu      sync.Mutex
+	path    string
+	enabled bool
+	counts  Counts
+}
+
+// DefaultPath returns the local telemetry file
+func DefaultPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".rubber_duck", "telemetry.json"), nil
+}
+
+// New creates a recorder that keeps its counts at path, loading any counts
+// already there. An empty path keeps counts in memory only
+func New(path string, enabled bool) *Recorder {
+	r := &Recorder{path: path, enabled: enabled}
+	r.counts = emptyCounts()
+	if path != "" {
+		if data, err := os.ReadFile(path); err == nil {
+			var counts Counts
+			if json.Unmarshal(data, &counts) == nil && !counts.Since.IsZero() {
+				r.counts = counts
+			}
+		}
+	}
+	if r.counts.Features == nil {
+		r.counts.Features = make(map[string]int)
+	}
+	if r.counts.Errors == nil {
+		r.counts.Errors = make(map[string]int)
+	}
+	return r
+}
+
+// emptyCounts starts a new counting period
+func emptyCounts() Counts {
+	return Counts{Since: clock.Now(), Features: make(map[string]int), Errors: make(map[string]int)}
+}
+
+// Path returns the local telemetry file
+func (r *Recorder) Path() string {
+	return r.path
+}
+
+// Enabled reports whether events are being counted
+func (r *Recorder) Enabled() bool {
+	if r == nil {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.enabled
+}
+
+// SetEnabled turns counting on or off; turning it off deletes the local file
+func (r *Recorder) SetEnabled(enabled bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.enabled = enabled
+	if enabled || r.path == "" {
+		return nil
+	}
+	r.counts = emptyCounts()
+	if err := os.Remove(r.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Feature counts a use of a named feature
+func (r *Recorder) Feature(name string) {
+	r.count(func(c *Counts) { c.Features[name]++ })
+}
+
+// Error counts an error by component and class
+func (r *Recorder) Error(component string, err error) {
+	if err == nil {
+		return
+	}
+	r.ErrorClass(component, Classify(err))
+}
+
+// ErrorClass counts an error whose class is already known
+func (r *Recorder) ErrorClass(component, class string) {
+	r.count(func(c *Counts) { c.Errors[component+"/"+class]++ })
+}
+
+// count applies an update while enabled and saves the counts
+func (r *Recorder) count(update func(*Counts)) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.enabled {
+		return
+	}
+	update(&r.counts)
+	// Counting is best effort; a failed write must not disturb the UI
+	_ = r.save()
+}
+
+// save writes the counts to the local file; the caller holds mu
+func (r *Recorder) save() error {
+	if r.path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(r.counts, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, data, 0644)
+}
+
+// Snapshot returns a copy of the current counts
+func (r *Recorder) Snapshot() Counts {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	snapshot := Counts{Since: r.counts.Since, Features: make(map[string]int), Errors: make(map[string]int)}
+	for name, n := range r.counts.Features {
+		snapshot.Features[name] = n
+	}
+	for name, n := range r.counts.Errors {
+		snapshot.Errors[name] = n
+	}
+	return snapshot
+}
+
+// Upload posts the counts as JSON to url and starts a new counting period
+// once the server accepts them
+func (r *Recorder) Upload(ctx context.Context, url string) error {
+	if !r.Enabled() {
+		return fmt.Errorf("telemetry is off")
+	}
+	data, err := json.Marshal(r.Snapshot())
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("upload rejected: %s", resp.Status)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.counts = emptyCounts()
+	return r.save()
+}
+
+// Classify reduces an error to a coarse class so its text is never recorded
+func Classify(err error) string {
+	text := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(text, "timeout") || strings.Contains(text, "timed out"):
+		return "timeout"
+	case strings.Contains(text, "refused") || strings.Contains(text, "not connected") || strings.Contains(text, "dial") || strings.Contains(text, "eof"):
+		return "connection"
+	case strings.Contains(text, "unauthorized") || strings.Contains(text, "forbidden") || strings.Contains(text, "auth"):
+		return "auth"
+	case strings.Contains(text, "not joined") || strings.Contains(text, "rejected") || strings.Contains(text, "join"):
+		return "channel"
+	case strings.Contains(text, "rate limit"):
+		return "rate_limit"
+	}
+	return "other"
+}
+
+// Format describes the counts for /telemetry status
+func Format(counts Counts) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Counting since %s\n", counts.Since.Format("2006-01-02 15:04"))
+	for _, section := range []struct {
+		title  string
+		counts map[string]int
+	}{{"Features", counts.Features}, {"Errors", counts.Errors}} {
+		fmt.Fprintf(&b, "\n%s:\n", section.title)
+		if len(section.counts) == 0 {
+			b.WriteString("  (none)\n")
+			continue
+		}
+		for _, name := range sortedKeys(section.counts) {
+			fmt.Fprintf(&b, "  %-32s %d\n", name, section.counts[name])
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// sortedKeys returns a map's keys in order
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,57 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRecorderCountsOnlyWhenEnabled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "telemetry.json")
+	r := New(path, false)
+	r.Feature("command:help")
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatal("Expected nothing written before consent")
+	}
+
+	r.SetEnabled(true)
+	r.Feature("command:help")
+	r.Feature("command:help")
+	r.Error("Phoenix Push", errors.New("Connection timeout for event: message"))
+
+	// Counts survive a restart
+	counts := New(path, true).Snapshot()
+	if counts.Features["command:help"] != 2 || counts.Errors["Phoenix Push/timeout"] != 1 {
+		t.Errorf("Unexpected counts: %+v", counts)
+	}
+
+	r.SetEnabled(false)
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Expected the local file removed when telemetry is turned off")
+	}
+}
+
+func TestUploadResetsCounts(t *testing.T) {
+	var uploaded Counts
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&uploaded)
+	}))
+	defer server.Close()
+
+	r := New("", true)
+	r.Feature("chat_send")
+	if err := r.Upload(context.Background(), server.URL); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if uploaded.Features["chat_send"] != 1 {
+		t.Errorf("Expected the counts uploaded, got %+v", uploaded)
+	}
+	if len(r.Snapshot().Features) != 0 {
+		t.Error("Expected a new counting period after upload")
+	}
+}
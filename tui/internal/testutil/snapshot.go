@@ -0,0 +1,105 @@
+// Package testutil provides golden-file snapshot testing for rendered views
+package testutil
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rubber_duck/tui/internal/clock"
+)
+
+// update rewrites golden files instead of comparing against them:
+//
+//	go test ./... -update
+var update = flag.Bool("update", false, "update golden snapshot files")
+
+// Fixed terminal size used for snapshots
+const (
+	SnapshotWidth  = 120
+	SnapshotHeight = 40
+)
+
+// FrozenTime is the clock time snapshots are rendered at
+var FrozenTime = time.Date(2024, time.January, 15, 9, 30, 0, 0, time.UTC)
+
+// ansiPattern matches CSI and OSC escape sequences
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(\x07|\x1b\\)`)
+
+// StripANSI removes terminal escape sequences
+func StripANSI(s string) string {
+	return ansiPattern.ReplaceAllString(s, "")
+}
+
+// Normalize strips escape sequences and trailing whitespace so snapshots
+// don't depend on the terminal's color support
+func Normalize(view string) string {
+	view = strings.ReplaceAll(StripANSI(view), "\r\n", "\n")
+	lines := strings.Split(view, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n") + "\n"
+}
+
+// FreezeClock fixes the clock at FrozenTime for the rest of the test
+func FreezeClock(t testing.TB) {
+	t.Helper()
+	t.Cleanup(clock.Freeze(FrozenTime))
+}
+
+// IsolateHome points the home directory at an empty temp dir so saved
+// config, spend, and conversations don't leak into the test
+func IsolateHome(t testing.TB) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+}
+
+// AssertSnapshot compares a view against testdata/snapshots/<name>.golden
+func AssertSnapshot(t testing.TB, name, view string) {
+	t.Helper()
+	got := Normalize(view)
+	path := filepath.Join("testdata", "snapshots", name+".golden")
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Missing snapshot %s (run go test -update): %v", path, err)
+	}
+	if got != string(want) {
+		t.Errorf("Snapshot %s does not match%s\n\nGot:\n%s\nRun go test -update to accept the change", name, firstDifference(string(want), got), got)
+	}
+}
+
+// firstDifference describes the first line where two snapshots differ
+func firstDifference(want, got string) string {
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g {
+			return fmt.Sprintf(" at line %d:\n  want: %q\n  got:  %q", i+1, w, g)
+		}
+	}
+	return ""
+}
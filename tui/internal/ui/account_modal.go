@@ -0,0 +1,418 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// minPasswordLength is the shortest password registration and password
+// change accept
+const minPasswordLength = 8
+
+// usernamePattern matches the usernames registration accepts
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{3,32}$`)
+
+// AccountForm selects which form the account modal shows
+type AccountForm int
+
+const (
+	LoginForm AccountForm = iota
+	RegisterForm
+	PasswordForm
+	CredentialsForm
+)
+
+// LoginSubmitMsg carries the credentials entered in the login form
+type LoginSubmitMsg struct {
+	Username   string
+	Password   string
+	SaveAPIKey bool // Generate an API key after login and save it to the config
+}
+
+// RegisterSubmitMsg carries a validated registration
+type RegisterSubmitMsg struct {
+	Username string
+	Email    string
+	Password string
+}
+
+// PasswordChangeSubmitMsg carries a validated password change
+type PasswordChangeSubmitMsg struct {
+	CurrentPassword string
+	NewPassword     string
+}
+
+// ProviderCredentialsSubmitMsg carries the credentials entered for a provider
+type ProviderCredentialsSubmitMsg struct {
+	Provider string
+	Fields   map[string]string // Only the fields that were filled in
+}
+
+// accountField is one text field of an account form
+type accountField struct {
+	key   string
+	input textinput.Model
+}
+
+// AccountModal shows the login, registration, and password change forms.
+// Password fields are masked and errors are shown inside the form
+type AccountModal struct {
+	form       AccountForm
+	provider   string // Provider whose credentials the credentials form sets
+	fields     []accountField
+	saveKey    bool
+	focus      int // Index into fields; len(fields) is the save key checkbox
+	visible    bool
+	submitting bool
+	err        string
+}
+
+// NewAccountModal creates a new account modal
+func NewAccountModal() AccountModal {
+	return AccountModal{}
+}
+
+// newAccountField creates a labelled text field, masked for passwords
+func newAccountField(key, label string, secret bool) accountField {
+	input := textinput.New()
+	input.Prompt = label
+	input.CharLimit = 256
+	if secret {
+		input.EchoMode = textinput.EchoPassword
+		input.EchoCharacter = '•'
+	}
+	return accountField{key: key, input: input}
+}
+
+// Show displays a form, optionally with the username filled in
+func (am *AccountModal) Show(form AccountForm, username string) {
+	am.form = form
+	am.visible = true
+	am.submitting = false
+	am.err = ""
+
+	switch form {
+	case RegisterForm:
+		am.fields = []accountField{
+			newAccountField("username", "Username:         ", false),
+			newAccountField("email", "Email:            ", false),
+			newAccountField("password", "Password:         ", true),
+			newAccountField("confirm", "Confirm password: ", true),
+		}
+	case PasswordForm:
+		am.fields = []accountField{
+			newAccountField("current", "Current password: ", true),
+			newAccountField("password", "New password:     ", true),
+			newAccountField("confirm", "Confirm password: ", true),
+		}
+	case CredentialsForm:
+		am.fields = []accountField{
+			newAccountField("api_key", "API key:  ", true),
+			newAccountField("base_url", "Base URL: ", false),
+		}
+	default:
+		am.fields = []accountField{
+			newAccountField("username", "Username: ", false),
+			newAccountField("password", "Password: ", true),
+		}
+	}
+
+	am.setFocus(0)
+	if username != "" {
+		if i := am.fieldIndex("username"); i >= 0 {
+			am.fields[i].input.SetValue(username)
+			am.setFocus(i + 1)
+		}
+	}
+}
+
+// ShowCredentials displays the credentials form for a provider
+func (am *AccountModal) ShowCredentials(provider string) {
+	am.provider = provider
+	am.Show(CredentialsForm, "")
+}
+
+// Hide hides the form and forgets what was typed
+func (am *AccountModal) Hide() {
+	am.visible = false
+	am.submitting = false
+	am.fields = nil
+}
+
+// IsVisible returns whether a form is visible
+func (am AccountModal) IsVisible() bool {
+	return am.visible
+}
+
+// Form returns the form being shown
+func (am AccountModal) Form() AccountForm {
+	return am.form
+}
+
+// SetError shows a rejected submission inline and lets the user try again
+func (am *AccountModal) SetError(message string) {
+	am.submitting = false
+	am.err = message
+	// Passwords are typed again rather than resubmitted
+	for i := range am.fields {
+		if am.fields[i].input.EchoMode == textinput.EchoPassword {
+			am.fields[i].input.SetValue("")
+		}
+	}
+	if am.form == LoginForm {
+		am.setFocus(am.fieldIndex("password"))
+	} else {
+		am.setFocus(am.firstPasswordField())
+	}
+}
+
+// SecretFocused reports whether the focused field is masked
+func (am AccountModal) SecretFocused() bool {
+	return am.focus < len(am.fields) && am.fields[am.focus].input.EchoMode == textinput.EchoPassword
+}
+
+// fieldIndex returns the index of a field by key, or -1
+func (am AccountModal) fieldIndex(key string) int {
+	for i, field := range am.fields {
+		if field.key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// firstPasswordField returns the index of the first masked field
+func (am AccountModal) firstPasswordField() int {
+	for i, field := range am.fields {
+		if field.input.EchoMode == textinput.EchoPassword {
+			return i
+		}
+	}
+	return 0
+}
+
+// value returns a field's text
+func (am AccountModal) value(key string) string {
+	if i := am.fieldIndex(key); i >= 0 {
+		return am.fields[i].input.Value()
+	}
+	return ""
+}
+
+// focusCount is the number of focusable controls
+func (am AccountModal) focusCount() int {
+	if am.form == LoginForm {
+		return len(am.fields) + 1
+	}
+	return len(am.fields)
+}
+
+// setFocus moves focus to a control
+func (am *AccountModal) setFocus(index int) {
+	am.focus = index
+	for i := range am.fields {
+		if i == index {
+			am.fields[i].input.Focus()
+		} else {
+			am.fields[i].input.Blur()
+		}
+	}
+}
+
+// Update handles form input
+func (am AccountModal) Update(msg tea.Msg) (AccountModal, tea.Cmd) {
+	if !am.visible {
+		return am, nil
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return am, nil
+	}
+
+	switch keyMsg.String() {
+	case "esc":
+		am.Hide()
+		return am, nil
+	case "tab", "down":
+		am.setFocus((am.focus + 1) % am.focusCount())
+		return am, nil
+	case "shift+tab", "up":
+		am.setFocus((am.focus + am.focusCount() - 1) % am.focusCount())
+		return am, nil
+	case " ":
+		if am.focus == len(am.fields) {
+			am.saveKey = !am.saveKey
+			return am, nil
+		}
+	case "enter":
+		// Enter moves on while a later field is still empty
+		for i := am.focus + 1; i < len(am.fields); i++ {
+			if am.fields[i].input.Value() == "" {
+				am.setFocus(i)
+				return am, nil
+			}
+		}
+		return am.submit()
+	}
+
+	// Ignore edits while the server is checking the submission
+	if am.submitting || am.focus >= len(am.fields) {
+		return am, nil
+	}
+
+	var cmd tea.Cmd
+	am.fields[am.focus].input, cmd = am.fields[am.focus].input.Update(msg)
+	return am, cmd
+}
+
+// submit validates the form and sends it
+func (am AccountModal) submit() (AccountModal, tea.Cmd) {
+	if am.submitting {
+		return am, nil
+	}
+	if problem, field := am.validate(); problem != "" {
+		am.err = problem
+		am.setFocus(am.fieldIndex(field))
+		return am, nil
+	}
+
+	var submit tea.Msg
+	switch am.form {
+	case RegisterForm:
+		submit = RegisterSubmitMsg{
+			Username: strings.TrimSpace(am.value("username")),
+			Email:    strings.TrimSpace(am.value("email")),
+			Password: am.value("password"),
+		}
+	case PasswordForm:
+		submit = PasswordChangeSubmitMsg{CurrentPassword: am.value("current"), NewPassword: am.value("password")}
+	case CredentialsForm:
+		fields := make(map[string]string)
+		for _, field := range am.fields {
+			if value := strings.TrimSpace(field.input.Value()); value != "" {
+				fields[field.key] = value
+			}
+		}
+		submit = ProviderCredentialsSubmitMsg{Provider: am.provider, Fields: fields}
+	default:
+		submit = LoginSubmitMsg{Username: strings.TrimSpace(am.value("username")), Password: am.value("password"), SaveAPIKey: am.saveKey}
+	}
+
+	am.err = ""
+	am.submitting = true
+	return am, func() tea.Msg { return submit }
+}
+
+// validate checks the form before it is sent, returning the problem and
+// the field to fix
+func (am AccountModal) validate() (string, string) {
+	username := strings.TrimSpace(am.value("username"))
+	password := am.value("password")
+
+	switch am.form {
+	case LoginForm:
+		if username == "" {
+			return "Enter a username", "username"
+		}
+		if password == "" {
+			return "Enter a password", "password"
+		}
+		return "", ""
+	case RegisterForm:
+		if !usernamePattern.MatchString(username) {
+			return "Usernames are 3-32 letters, digits, '.', '_' or '-'", "username"
+		}
+		if email := strings.TrimSpace(am.value("email")); !strings.Contains(email, "@") || strings.ContainsAny(email, " \t") {
+			return "Enter a valid email address", "email"
+		}
+	case PasswordForm:
+		if am.value("current") == "" {
+			return "Enter your current password", "current"
+		}
+		if password == am.value("current") {
+			return "The new password must differ from the current one", "password"
+		}
+	case CredentialsForm:
+		if strings.TrimSpace(am.value("api_key")) == "" && strings.TrimSpace(am.value("base_url")) == "" {
+			return "Enter an API key or a base URL", "api_key"
+		}
+		if url := strings.TrimSpace(am.value("base_url")); url != "" && !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+			return "The base URL must start with http:// or https://", "base_url"
+		}
+		return "", ""
+	}
+
+	if len(password) < minPasswordLength {
+		return fmt.Sprintf("Passwords need at least %d characters", minPasswordLength), "password"
+	}
+	if am.value("confirm") != password {
+		return "Passwords don't match", "confirm"
+	}
+	return "", ""
+}
+
+// View renders the form
+func (am AccountModal) View() string {
+	if !am.visible {
+		return ""
+	}
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("62"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+	title, action := "◆ Log In ◆", "Log in"
+	switch am.form {
+	case RegisterForm:
+		title, action = "◆ Create Account ◆", "Register"
+	case PasswordForm:
+		title, action = "◆ Change Password ◆", "Change"
+	case CredentialsForm:
+		title, action = "◆ "+am.provider+" Credentials ◆", "Save"
+	}
+
+	lines := []string{titleStyle.Render(title), ""}
+	for _, field := range am.fields {
+		lines = append(lines, field.input.View())
+	}
+
+	help := "Tab: Next field | Enter: " + action + " | Esc: Cancel"
+	if am.form == LoginForm {
+		checkbox := "[ ]"
+		if am.saveKey {
+			checkbox = "[x]"
+		}
+		saveLine := checkbox + " Save an API key so next time logs in automatically"
+		if am.focus == len(am.fields) {
+			saveLine = lipgloss.NewStyle().Foreground(lipgloss.Color("220")).Render(saveLine)
+		}
+		lines = append(lines, "", saveLine)
+		help = "Tab: Next field | Space: Toggle | Enter: Log in | Esc: Cancel"
+	}
+	if am.form == CredentialsForm {
+		lines = append(lines, "", dimStyle.Render("Sent to the server over the authenticated channel and never\nsaved locally. Leave the base URL empty for the provider's default"))
+	}
+
+	lines = append(lines, "")
+	switch {
+	case am.submitting:
+		lines = append(lines, dimStyle.Render("Waiting for the server..."))
+	case am.err != "":
+		lines = append(lines, lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(am.err))
+	default:
+		lines = append(lines, "")
+	}
+	lines = append(lines, dimStyle.Render(help))
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Padding(1, 2).
+		Width(64).
+		Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
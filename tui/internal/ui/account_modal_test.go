@@ -0,0 +1,138 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func typeInto(am AccountModal, text string) AccountModal {
+	for _, r := range text {
+		am, _ = am.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	return am
+}
+
+// fillForm types each value and presses Enter after it
+func fillForm(am AccountModal, values ...string) (AccountModal, tea.Cmd) {
+	var cmd tea.Cmd
+	for _, value := range values {
+		am = typeInto(am, value)
+		am, cmd = am.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	}
+	return am, cmd
+}
+
+func TestLoginFormSubmit(t *testing.T) {
+	am := NewAccountModal()
+	am.Show(LoginForm, "")
+
+	am = typeInto(am, "duck")
+	am, _ = am.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	am = typeInto(am, "quack")
+	if strings.Contains(am.View(), "quack") {
+		t.Error("Expected the password to be masked")
+	}
+
+	am, _ = am.Update(tea.KeyMsg{Type: tea.KeyTab})
+	am, _ = am.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	am, cmd := am.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Expected the form to submit")
+	}
+	submit, ok := cmd().(LoginSubmitMsg)
+	if !ok || submit.Username != "duck" || submit.Password != "quack" || !submit.SaveAPIKey {
+		t.Fatalf("Unexpected submission: %+v", submit)
+	}
+
+	am.SetError("Invalid credentials")
+	if !strings.Contains(am.View(), "Invalid credentials") {
+		t.Error("Expected the error inside the form")
+	}
+	if am.value("password") != "" || am.focus != am.fieldIndex("password") {
+		t.Error("Expected the password to be cleared and focused after a failure")
+	}
+}
+
+func TestLoginFormRequiresFields(t *testing.T) {
+	am := NewAccountModal()
+	am.Show(LoginForm, "duck")
+	am, cmd := am.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd != nil {
+		t.Error("Expected no submission without a password")
+	}
+	if am.err == "" {
+		t.Error("Expected an inline error for the missing password")
+	}
+}
+
+func TestRegisterFormValidation(t *testing.T) {
+	am := NewAccountModal()
+	am.Show(RegisterForm, "goose")
+
+	am, cmd := fillForm(am, "goose@example.com", "honkhonk", "honkhonx")
+	if cmd != nil || !strings.Contains(am.err, "match") {
+		t.Fatalf("Expected mismatched passwords to be refused, got %q", am.err)
+	}
+	if am.focus != am.fieldIndex("confirm") {
+		t.Error("Expected focus on the confirmation field")
+	}
+
+	am.fields[am.focus].input.SetValue("honkhonk")
+	am, cmd = am.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatalf("Expected the form to submit, got %q", am.err)
+	}
+	if submit := cmd().(RegisterSubmitMsg); submit.Username != "goose" || submit.Email != "goose@example.com" || submit.Password != "honkhonk" {
+		t.Errorf("Unexpected submission: %+v", submit)
+	}
+}
+
+func TestPasswordFormValidation(t *testing.T) {
+	am := NewAccountModal()
+	am.Show(PasswordForm, "")
+
+	am, cmd := fillForm(am, "quackquack", "short", "short")
+	if cmd != nil || !strings.Contains(am.err, "at least") {
+		t.Fatalf("Expected a short password to be refused, got %q", am.err)
+	}
+
+	am.Show(PasswordForm, "")
+	_, cmd = fillForm(am, "quackquack", "newpassword", "newpassword")
+	if cmd == nil {
+		t.Fatal("Expected the form to submit")
+	}
+	if submit := cmd().(PasswordChangeSubmitMsg); submit.CurrentPassword != "quackquack" || submit.NewPassword != "newpassword" {
+		t.Errorf("Unexpected submission: %+v", submit)
+	}
+}
+
+func TestCredentialsFormSubmit(t *testing.T) {
+	am := NewAccountModal()
+	am.ShowCredentials("openai")
+
+	am, _ = fillForm(am, "sk-secret-1234567890", "ftp://proxy")
+	if strings.Contains(am.View(), "sk-secret") {
+		t.Error("Expected the API key to be masked")
+	}
+	if !strings.Contains(am.View(), "must start with http") {
+		t.Fatalf("Expected the base URL to be rejected, got:\n%s", am.View())
+	}
+
+	for range "ftp://proxy" {
+		am, _ = am.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	}
+	am, cmd := am.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Expected the form to submit")
+	}
+	submit, ok := cmd().(ProviderCredentialsSubmitMsg)
+	if !ok || submit.Provider != "openai" || submit.Fields["api_key"] != "sk-secret-1234567890" || len(submit.Fields) != 1 {
+		t.Fatalf("Unexpected submission: %+v", submit)
+	}
+
+	if got := maskCredential("api_key", "sk-secret-1234567890"); got != "sk-…7890" {
+		t.Errorf("maskCredential = %q", got)
+	}
+}
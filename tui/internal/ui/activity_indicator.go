@@ -0,0 +1,140 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rubber_duck/tui/internal/clock"
+)
+
+// activitySpinnerFrames are the frames of the activity spinner
+var activitySpinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// ActivityTickMsg refreshes the activity indicator started in Generation
+type ActivityTickMsg struct {
+	Time       time.Time
+	Generation int
+}
+
+// ActivityIndicator shows what the assistant is doing while a request is in flight
+type ActivityIndicator struct {
+	active        bool
+	startedAt     time.Time
+	stage         string
+	category      StatusCategory
+	queuePosition int
+	frame         int
+	generation    int // Bumped on each Start so ticks from an earlier run are dropped
+}
+
+// NewActivityIndicator creates a new activity indicator
+func NewActivityIndicator() ActivityIndicator {
+	return ActivityIndicator{}
+}
+
+// Start begins tracking a request, returning a tick command if not already running
+func (a *ActivityIndicator) Start() tea.Cmd {
+	if a.active {
+		return nil
+	}
+	a.active = true
+	a.startedAt = clock.Now()
+	a.stage = ""
+	a.category = ""
+	a.queuePosition = 0
+	a.frame = 0
+	a.generation++
+	return activityTick(a.generation)
+}
+
+// Stop clears the indicator
+func (a *ActivityIndicator) Stop() {
+	a.active = false
+	a.stage = ""
+	a.category = ""
+	a.queuePosition = 0
+}
+
+// IsActive returns whether a request is being tracked
+func (a ActivityIndicator) IsActive() bool {
+	return a.active
+}
+
+// SetStage records the current processing stage from the status channel
+func (a *ActivityIndicator) SetStage(category StatusCategory, stage string) {
+	a.category = category
+	a.stage = stage
+}
+
+// SetQueuePosition records the server-reported queue position (0 when not queued)
+func (a *ActivityIndicator) SetQueuePosition(position int) {
+	a.queuePosition = position
+}
+
+// Elapsed returns how long the current request has been running
+func (a ActivityIndicator) Elapsed() time.Duration {
+	if !a.active {
+		return 0
+	}
+	return clock.Since(a.startedAt)
+}
+
+// Update advances the spinner and keeps ticking while active
+func (a ActivityIndicator) Update(msg tea.Msg) (ActivityIndicator, tea.Cmd) {
+	tick, ok := msg.(ActivityTickMsg)
+	if !ok || !a.active || tick.Generation != a.generation {
+		return a, nil
+	}
+	a.frame = (a.frame + 1) % len(activitySpinnerFrames)
+	return a, activityTick(a.generation)
+}
+
+// View renders the indicator
+func (a ActivityIndicator) View() string {
+	if !a.active {
+		return ""
+	}
+
+	elapsed := a.Elapsed().Truncate(time.Second)
+	spinner := activitySpinnerFrames[a.frame]
+
+	// Queued requests haven't started thinking yet
+	if a.queuePosition > 0 {
+		return lipgloss.NewStyle().
+			Foreground(lipgloss.Color("214")).
+			Bold(true).
+			Render(fmt.Sprintf("%s Queued #%d (%s)", spinner, a.queuePosition, elapsed))
+	}
+
+	text := fmt.Sprintf("%s Thinking (%s)", spinner, elapsed)
+	if a.stage != "" {
+		label := string(a.category)
+		if label == "" {
+			label = "stage"
+		}
+		text = fmt.Sprintf("%s Thinking (%s) · %s: %s", spinner, elapsed, label, truncateStage(a.stage, 40))
+	}
+
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color("75")).
+		Bold(true).
+		Render(text)
+}
+
+// truncateStage keeps stage text short enough for the status bar
+func truncateStage(stage string, max int) string {
+	runes := []rune(stage)
+	if len(runes) <= max {
+		return stage
+	}
+	return string(runes[:max-1]) + "…"
+}
+
+// activityTick schedules the next indicator refresh
+func activityTick(generation int) tea.Cmd {
+	return tea.Tick(200*time.Millisecond, func(t time.Time) tea.Msg {
+		return ActivityTickMsg{Time: t, Generation: generation}
+	})
+}
@@ -0,0 +1,35 @@
+package ui
+
+import "testing"
+
+func TestActivityIndicator_RestartDropsEarlierTicks(t *testing.T) {
+	var a ActivityIndicator
+	if a.Start() == nil {
+		t.Fatal("Expected starting to schedule a tick")
+	}
+	if a.Start() != nil {
+		t.Fatal("Expected no second tick chain while already running")
+	}
+	stale := ActivityTickMsg{Generation: a.generation}
+
+	// Restarting before the pending tick arrives begins a new chain
+	a.Stop()
+	if a.Start() == nil {
+		t.Fatal("Expected restarting to schedule a tick")
+	}
+
+	updated, cmd := a.Update(stale)
+	if cmd != nil || updated.frame != 0 {
+		t.Fatalf("Expected the earlier run's tick dropped, got frame %d", updated.frame)
+	}
+
+	updated, cmd = a.Update(ActivityTickMsg{Generation: a.generation})
+	if cmd == nil || updated.frame != 1 {
+		t.Fatalf("Expected the current tick to advance and reschedule, got frame %d", updated.frame)
+	}
+
+	updated.Stop()
+	if _, cmd := updated.Update(ActivityTickMsg{Generation: updated.generation}); cmd != nil {
+		t.Error("Expected no tick once stopped")
+	}
+}
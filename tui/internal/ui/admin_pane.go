@@ -0,0 +1,266 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// adminRefreshInterval is how often the admin pane asks for fresh stats
+const adminRefreshInterval = 5 * time.Second
+
+// adminRefreshMsg asks for fresh stats while the admin pane is open
+type adminRefreshMsg struct{}
+
+// AdminRequestMsg asks the model to run an admin operation
+type AdminRequestMsg struct {
+	Operation string // "refresh", "flush_caches", or "toggle_provider"
+	Provider  string
+	Enabled   bool
+}
+
+// AdminPane shows server metrics and provider health to admins, with
+// maintenance actions
+type AdminPane struct {
+	stats    *phoenix.AdminStats
+	updated  time.Time
+	selected int // Provider under the cursor
+	notice   string
+	err      string
+	visible  bool
+	width    int
+	height   int
+}
+
+// NewAdminPane creates a new admin pane
+func NewAdminPane() AdminPane {
+	return AdminPane{width: 80, height: 24}
+}
+
+// Show displays the admin pane
+func (ap *AdminPane) Show() {
+	ap.visible = true
+	ap.notice = ""
+	ap.err = ""
+}
+
+// Hide hides the admin pane
+func (ap *AdminPane) Hide() {
+	ap.visible = false
+}
+
+// IsVisible returns whether the admin pane is visible
+func (ap AdminPane) IsVisible() bool {
+	return ap.visible
+}
+
+// SetSize updates the pane dimensions
+func (ap *AdminPane) SetSize(width, height int) {
+	ap.width = width
+	ap.height = height
+}
+
+// SetStats shows a new stats snapshot
+func (ap *AdminPane) SetStats(stats phoenix.AdminStats, at time.Time) {
+	ap.stats = &stats
+	ap.updated = at
+	ap.err = ""
+	if ap.selected >= len(stats.Providers) {
+		ap.selected = max(len(stats.Providers)-1, 0)
+	}
+}
+
+// SetNotice shows the result of a maintenance action
+func (ap *AdminPane) SetNotice(notice string) {
+	ap.notice = notice
+	ap.err = ""
+}
+
+// SetError shows a failed admin operation
+func (ap *AdminPane) SetError(err string) {
+	ap.err = err
+}
+
+// Update handles admin pane input
+func (ap AdminPane) Update(msg tea.Msg) (AdminPane, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || !ap.visible {
+		return ap, nil
+	}
+
+	request := func(r AdminRequestMsg) tea.Cmd {
+		return func() tea.Msg { return r }
+	}
+
+	switch keyMsg.String() {
+	case "esc", "q":
+		ap.Hide()
+	case "up", "k":
+		if ap.selected > 0 {
+			ap.selected--
+		}
+	case "down", "j":
+		if ap.stats != nil && ap.selected < len(ap.stats.Providers)-1 {
+			ap.selected++
+		}
+	case "r":
+		return ap, request(AdminRequestMsg{Operation: "refresh"})
+	case "f":
+		ap.notice = "Flushing caches..."
+		return ap, request(AdminRequestMsg{Operation: "flush_caches"})
+	case "t", " ", "enter":
+		if ap.stats == nil || len(ap.stats.Providers) == 0 {
+			return ap, nil
+		}
+		provider := ap.stats.Providers[ap.selected]
+		verb := "Enabling"
+		if provider.Enabled {
+			verb = "Disabling"
+		}
+		ap.notice = fmt.Sprintf("%s %s...", verb, provider.Name)
+		return ap, request(AdminRequestMsg{Operation: "toggle_provider", Provider: provider.Name, Enabled: !provider.Enabled})
+	}
+	return ap, nil
+}
+
+// View renders the admin pane
+func (ap AdminPane) View() string {
+	if !ap.visible {
+		return ""
+	}
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("62"))
+	headingStyle := lipgloss.NewStyle().Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	goodStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	badStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+
+	lines := []string{titleStyle.Render("◆ Server Admin ◆"), ""}
+
+	if ap.stats == nil {
+		lines = append(lines, dimStyle.Render("Waiting for server stats..."))
+	} else {
+		s := ap.stats
+		lines = append(lines,
+			headingStyle.Render("Server"),
+			fmt.Sprintf("  Uptime:               %s", formatUptime(s.UptimeSeconds)),
+			fmt.Sprintf("  Active conversations: %d", s.ActiveConversations),
+			fmt.Sprintf("  Connected users:      %d", s.ConnectedUsers),
+		)
+		names := make([]string, 0, len(s.Metrics))
+		for name := range s.Metrics {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			lines = append(lines, fmt.Sprintf("  %-22s%g", strings.ReplaceAll(name, "_", " ")+":", s.Metrics[name]))
+		}
+
+		lines = append(lines, "", headingStyle.Render("Providers"))
+		if len(s.Providers) == 0 {
+			lines = append(lines, dimStyle.Render("  (none reported)"))
+		}
+		for i, p := range s.Providers {
+			cursor := "  "
+			if i == ap.selected {
+				cursor = "> "
+			}
+			health := goodStyle.Render("healthy")
+			if !p.Healthy {
+				health = badStyle.Render("unhealthy")
+			}
+			state := "on "
+			if !p.Enabled {
+				state = dimStyle.Render("off")
+			}
+			lines = append(lines, fmt.Sprintf("%s%-14s %s  %-9s  %6.0f ms  %5.1f%% errors", cursor, p.Name, state, health, p.LatencyMS, p.ErrorRate*100))
+		}
+		lines = append(lines, "", dimStyle.Render("Updated "+ap.updated.Format("15:04:05")))
+	}
+
+	switch {
+	case ap.err != "":
+		lines = append(lines, badStyle.Render(ap.err))
+	case ap.notice != "":
+		lines = append(lines, ap.notice)
+	}
+	lines = append(lines, "", dimStyle.Render("↑/↓: Select provider | t/Enter: Toggle provider | f: Flush caches | r: Refresh | Esc: Close"))
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Padding(0, 1).
+		Width(ap.width - 2).
+		Height(ap.height - 2).
+		Render(strings.Join(lines, "\n"))
+}
+
+// formatUptime renders seconds as days, hours, and minutes
+func formatUptime(seconds float64) string {
+	d := time.Duration(seconds) * time.Second
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	minutes := int(d.Minutes()) % 60
+	if days > 0 {
+		return fmt.Sprintf("%dd %dh %dm", days, hours, minutes)
+	}
+	return fmt.Sprintf("%dh %dm", hours, minutes)
+}
+
+// openAdminPane shows the admin pane, joining the admin channel on first use
+func (m *Model) openAdminPane() tea.Cmd {
+	adminClient, ok := m.adminClient.(*phoenix.AdminClient)
+	if !ok {
+		return nil
+	}
+	m.adminPane.SetSize(m.width, m.height)
+	m.adminPane.Show()
+	m.statusBar = "Admin panel"
+
+	var cmds []tea.Cmd
+	if adminClient.Joined() {
+		cmds = append(cmds, adminClient.GetStats())
+	} else {
+		cmds = append(cmds, adminClient.JoinAdminChannel())
+	}
+	if !m.adminRefreshing {
+		m.adminRefreshing = true
+		cmds = append(cmds, tea.Tick(adminRefreshInterval, func(time.Time) tea.Msg { return adminRefreshMsg{} }))
+	}
+	return tea.Batch(cmds...)
+}
+
+// refreshAdminStats asks for fresh stats and schedules the next refresh
+// while the admin pane is open
+func (m *Model) refreshAdminStats() tea.Cmd {
+	adminClient, ok := m.adminClient.(*phoenix.AdminClient)
+	if !ok || !m.adminPane.IsVisible() {
+		m.adminRefreshing = false
+		return nil
+	}
+	next := tea.Tick(adminRefreshInterval, func(time.Time) tea.Msg { return adminRefreshMsg{} })
+	if !adminClient.Joined() {
+		return next
+	}
+	return tea.Batch(adminClient.GetStats(), next)
+}
+
+// runAdminRequest sends an operation chosen in the admin pane
+func (m *Model) runAdminRequest(request AdminRequestMsg) tea.Cmd {
+	adminClient, ok := m.adminClient.(*phoenix.AdminClient)
+	if !ok {
+		return nil
+	}
+	switch request.Operation {
+	case "flush_caches":
+		return adminClient.FlushCaches()
+	case "toggle_provider":
+		return adminClient.SetProviderEnabled(request.Provider, request.Enabled)
+	}
+	return adminClient.GetStats()
+}
@@ -2,6 +2,7 @@ package ui
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/rubber_duck/tui/internal/phoenix"
@@ -40,6 +41,11 @@ func (h *AnalysisResponseHandler) FormatResponse(response phoenix.ConversationMe
 	// Main response content
 	parts = append(parts, response.Response)
 	
+	// Add issues grouped by file and severity if available
+	if issues, ok := response.Metadata["issues"].([]any); ok && len(issues) > 0 {
+		parts = append(parts, h.formatIssues(issues))
+	}
+	
 	// Add recommendations if available
 	if recommendations, ok := response.Metadata["recommendations"].([]any); ok && len(recommendations) > 0 {
 		parts = append(parts, h.addSectionHeader("Recommendations"))
@@ -71,4 +77,159 @@ func (h *AnalysisResponseHandler) formatAnalysisType(aType string) string {
 		return formatted
 	}
 	return ""
+}
+
+// analysisSeverities are the issue severities from most to least severe
+var analysisSeverities = []string{"critical", "high", "medium", "low", "info"}
+
+// severityAliases maps other severity names onto analysisSeverities
+var severityAliases = map[string]string{
+	"error":   "high",
+	"major":   "high",
+	"warning": "medium",
+	"warn":    "medium",
+	"minor":   "low",
+	"note":    "info",
+	"hint":    "info",
+}
+
+// analysisIssue is one finding of an analysis
+type analysisIssue struct {
+	Severity   string
+	File       string
+	Line       int
+	Message    string
+	Suggestion string
+}
+
+// parseIssue reads an issue from response metadata
+func parseIssue(raw any) (analysisIssue, bool) {
+	fields, ok := raw.(map[string]any)
+	if !ok {
+		if text, ok := raw.(string); ok && text != "" {
+			return analysisIssue{Severity: "info", Message: text}, true
+		}
+		return analysisIssue{}, false
+	}
+	issue := analysisIssue{Severity: "info"}
+	if severity, ok := fields["severity"].(string); ok {
+		severity = strings.ToLower(severity)
+		if alias, ok := severityAliases[severity]; ok {
+			severity = alias
+		}
+		if severityRank(severity) < len(analysisSeverities) {
+			issue.Severity = severity
+		}
+	}
+	issue.File, _ = fields["file"].(string)
+	if line, ok := fields["line"].(float64); ok {
+		issue.Line = int(line)
+	}
+	issue.Message, _ = fields["message"].(string)
+	if issue.Message == "" {
+		issue.Message, _ = fields["description"].(string)
+	}
+	issue.Suggestion, _ = fields["suggestion"].(string)
+	return issue, issue.Message != ""
+}
+
+// severityRank orders severities, most severe first
+func severityRank(severity string) int {
+	for i, s := range analysisSeverities {
+		if s == severity {
+			return i
+		}
+	}
+	return len(analysisSeverities)
+}
+
+// severityIcon returns the marker shown for a severity
+func severityIcon(severity string) string {
+	switch severity {
+	case "critical":
+		return "🔴"
+	case "high":
+		return "🟠"
+	case "medium":
+		return "🟡"
+	case "low":
+		return "🔵"
+	default:
+		return "⚪"
+	}
+}
+
+// formatIssues formats issues as a severity summary followed by a section
+// per file, most severe files first, with each file's issues grouped by
+// severity and suggestions called out below them
+func (h *AnalysisResponseHandler) formatIssues(raw []any) string {
+	byFile := make(map[string][]analysisIssue)
+	counts := make(map[string]int)
+	for _, item := range raw {
+		issue, ok := parseIssue(item)
+		if !ok {
+			continue
+		}
+		byFile[issue.File] = append(byFile[issue.File], issue)
+		counts[issue.Severity]++
+	}
+	if len(byFile) == 0 {
+		return ""
+	}
+	
+	var summary []string
+	for _, severity := range analysisSeverities {
+		if counts[severity] > 0 {
+			summary = append(summary, fmt.Sprintf("%s %d %s", severityIcon(severity), counts[severity], severity))
+		}
+	}
+	parts := []string{h.addSectionHeader("Issues"), strings.Join(summary, " · ")}
+	
+	for _, issues := range byFile {
+		sort.SliceStable(issues, func(i, j int) bool {
+			if a, b := severityRank(issues[i].Severity), severityRank(issues[j].Severity); a != b {
+				return a < b
+			}
+			return issues[i].Line < issues[j].Line
+		})
+	}
+	files := make([]string, 0, len(byFile))
+	for file := range byFile {
+		files = append(files, file)
+	}
+	// Issues without a file come last, under General
+	sort.Slice(files, func(i, j int) bool {
+		a, b := files[i], files[j]
+		if (a == "") != (b == "") {
+			return b == ""
+		}
+		if ra, rb := severityRank(byFile[a][0].Severity), severityRank(byFile[b][0].Severity); ra != rb {
+			return ra < rb
+		}
+		return a < b
+	})
+	
+	for _, file := range files {
+		title := "General"
+		if file != "" {
+			title = fmt.Sprintf("`%s`", file)
+		}
+		parts = append(parts, fmt.Sprintf("\n#### %s", title))
+		severity := ""
+		for _, issue := range byFile[file] {
+			if issue.Severity != severity {
+				severity = issue.Severity
+				parts = append(parts, fmt.Sprintf("\n%s **%s**", severityIcon(severity), strings.ToUpper(severity[:1])+severity[1:]))
+			}
+			location := ""
+			if issue.Line > 0 {
+				location = fmt.Sprintf("Line %d: ", issue.Line)
+			}
+			parts = append(parts, fmt.Sprintf("- %s%s", location, issue.Message))
+			if issue.Suggestion != "" {
+				parts = append(parts, fmt.Sprintf("  > 💡 **Suggestion:** %s", issue.Suggestion))
+			}
+		}
+	}
+	return strings.Join(parts, "\n")
 }
\ No newline at end of file
@@ -0,0 +1,38 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+func TestAnalysisResponseGroupsIssues(t *testing.T) {
+	handler := &AnalysisResponseHandler{}
+	out := handler.FormatResponse(phoenix.ConversationMessage{
+		Response: "Found a few problems.",
+		Metadata: map[string]any{
+			"issues": []any{
+				map[string]any{"severity": "warning", "file": "util.go", "line": float64(9), "message": "Unused variable"},
+				map[string]any{"severity": "critical", "file": "auth.go", "line": float64(42), "message": "SQL built from input", "suggestion": "Use a prepared statement"},
+				map[string]any{"severity": "low", "file": "auth.go", "line": float64(3), "message": "Long function"},
+				"Consider adding tests",
+			},
+		},
+	})
+
+	if !strings.Contains(out, "🔴 1 critical · 🟡 1 medium · 🔵 1 low · ⚪ 1 info") {
+		t.Errorf("Expected a severity summary, got:\n%s", out)
+	}
+	// The file with the critical issue comes first, general findings last
+	auth, util, general := strings.Index(out, "`auth.go`"), strings.Index(out, "`util.go`"), strings.Index(out, "General")
+	if auth < 0 || util < auth || general < util {
+		t.Errorf("Expected files ordered by severity, got:\n%s", out)
+	}
+	if !strings.Contains(out, "- Line 42: SQL built from input\n  > 💡 **Suggestion:** Use a prepared statement") {
+		t.Errorf("Expected a suggestion callout under its issue, got:\n%s", out)
+	}
+	if strings.Index(out, "Line 42") > strings.Index(out, "Line 3:") {
+		t.Errorf("Expected a file's issues grouped most severe first, got:\n%s", out)
+	}
+}
@@ -0,0 +1,357 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// apiKeyExpiries are the lifetimes a new API key can have, in days
+var apiKeyExpiries = []int{7, 30, 90, 365}
+
+// defaultAPIKeyExpiry is the index of the lifetime the form starts on
+const defaultAPIKeyExpiry = 3
+
+// apiKeyScope is a permission a new API key can be limited to
+type apiKeyScope struct {
+	Name        string
+	Description string
+	Default     bool // Checked when the form opens
+}
+
+// apiKeyScopes are the scopes the generation form offers
+var apiKeyScopes = []apiKeyScope{
+	{Name: "chat", Description: "Conversations and messages", Default: true},
+	{Name: phoenix.PermissionPlanning, Description: "Start planning sessions", Default: true},
+	{Name: phoenix.PermissionAPIKeys, Description: "Manage the account's API keys"},
+	{Name: phoenix.PermissionSessions, Description: "List and sign out sessions"},
+}
+
+// APIKeyGenerateSubmitMsg carries the options picked in the generation form
+type APIKeyGenerateSubmitMsg struct {
+	Name          string
+	ExpiresInDays int
+	Scopes        []string
+}
+
+// apiKeyCopiedMsg reports copying the revealed key
+type apiKeyCopiedMsg struct {
+	Err error
+}
+
+// APIKeyModal asks for a new API key's name, lifetime, and scopes, then
+// shows the generated key once. The key is masked until revealed and is
+// forgotten when the modal closes
+type APIKeyModal struct {
+	name       textinput.Model
+	expiry     int    // Index into apiKeyExpiries
+	scopes     []bool // Checked scopes, parallel to apiKeyScopes
+	focus      int    // 0 is the name, 1 the lifetime, then the scopes
+	submitting bool
+	err        string
+
+	key      *phoenix.APIKey // The generated key while it is shown
+	warning  string
+	revealed bool
+	copied   bool
+	visible  bool
+}
+
+// Show opens the generation form
+func (ak *APIKeyModal) Show() {
+	ak.name = textinput.New()
+	ak.name.Prompt = "Name:    "
+	ak.name.Placeholder = "e.g. laptop, CI"
+	ak.name.CharLimit = 64
+	ak.expiry = defaultAPIKeyExpiry
+	ak.scopes = make([]bool, len(apiKeyScopes))
+	for i, scope := range apiKeyScopes {
+		ak.scopes[i] = scope.Default
+	}
+	ak.submitting = false
+	ak.err = ""
+	ak.key = nil
+	ak.warning = ""
+	ak.revealed = false
+	ak.copied = false
+	ak.visible = true
+	ak.setFocus(0)
+}
+
+// ShowKey switches to the one-time view of a generated key
+func (ak *APIKeyModal) ShowKey(key phoenix.APIKey, warning string) {
+	ak.key = &key
+	ak.warning = warning
+	ak.revealed = false
+	ak.copied = false
+	ak.submitting = false
+	ak.visible = true
+}
+
+// SetError shows a failed generation in the form
+func (ak *APIKeyModal) SetError(message string) {
+	ak.submitting = false
+	ak.err = message
+}
+
+// Hide closes the modal and forgets the key
+func (ak *APIKeyModal) Hide() {
+	ak.visible = false
+	ak.key = nil
+	ak.warning = ""
+}
+
+// IsVisible returns whether the modal is visible
+func (ak APIKeyModal) IsVisible() bool {
+	return ak.visible
+}
+
+// Waiting reports whether the form was sent and the key hasn't arrived
+func (ak APIKeyModal) Waiting() bool {
+	return ak.visible && ak.submitting && ak.key == nil
+}
+
+// focusCount is the number of focusable controls in the form
+func (ak APIKeyModal) focusCount() int {
+	return 2 + len(apiKeyScopes)
+}
+
+// setFocus moves focus to a control
+func (ak *APIKeyModal) setFocus(index int) {
+	ak.focus = index
+	if index == 0 {
+		ak.name.Focus()
+	} else {
+		ak.name.Blur()
+	}
+}
+
+// Update handles modal input
+func (ak APIKeyModal) Update(msg tea.Msg) (APIKeyModal, tea.Cmd) {
+	if !ak.visible {
+		return ak, nil
+	}
+	if copied, ok := msg.(apiKeyCopiedMsg); ok {
+		ak.copied = copied.Err == nil
+		if copied.Err != nil {
+			ak.err = fmt.Sprintf("Copy failed: %v", copied.Err)
+		}
+		return ak, nil
+	}
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return ak, nil
+	}
+	if ak.key != nil {
+		return ak.updateReveal(keyMsg)
+	}
+
+	switch keyMsg.String() {
+	case "esc":
+		ak.Hide()
+		return ak, nil
+	case "tab", "down":
+		ak.setFocus((ak.focus + 1) % ak.focusCount())
+		return ak, nil
+	case "shift+tab", "up":
+		ak.setFocus((ak.focus + ak.focusCount() - 1) % ak.focusCount())
+		return ak, nil
+	case "enter":
+		return ak.submit()
+	}
+
+	// Ignore edits while the server generates the key
+	if ak.submitting {
+		return ak, nil
+	}
+	switch {
+	case ak.focus == 0:
+		var cmd tea.Cmd
+		ak.name, cmd = ak.name.Update(msg)
+		return ak, cmd
+	case ak.focus == 1:
+		switch keyMsg.String() {
+		case "right", "l", " ":
+			ak.expiry = (ak.expiry + 1) % len(apiKeyExpiries)
+		case "left", "h":
+			ak.expiry = (ak.expiry + len(apiKeyExpiries) - 1) % len(apiKeyExpiries)
+		}
+	case keyMsg.String() == " " || keyMsg.String() == "x":
+		ak.scopes[ak.focus-2] = !ak.scopes[ak.focus-2]
+	}
+	return ak, nil
+}
+
+// updateReveal handles a key while the generated key is shown
+func (ak APIKeyModal) updateReveal(msg tea.KeyMsg) (APIKeyModal, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "enter", "q":
+		ak.Hide()
+	case "v", " ":
+		ak.revealed = !ak.revealed
+	case "c", "y":
+		key := ak.key.Key
+		return ak, func() tea.Msg { return apiKeyCopiedMsg{Err: clipboard.WriteAll(key)} }
+	}
+	return ak, nil
+}
+
+// submit validates the form and sends it
+func (ak APIKeyModal) submit() (APIKeyModal, tea.Cmd) {
+	if ak.submitting {
+		return ak, nil
+	}
+	var scopes []string
+	for i, checked := range ak.scopes {
+		if checked {
+			scopes = append(scopes, apiKeyScopes[i].Name)
+		}
+	}
+	if len(scopes) == 0 {
+		ak.err = "Pick at least one scope"
+		ak.setFocus(2)
+		return ak, nil
+	}
+	submit := APIKeyGenerateSubmitMsg{
+		Name:          strings.TrimSpace(ak.name.Value()),
+		ExpiresInDays: apiKeyExpiries[ak.expiry],
+		Scopes:        scopes,
+	}
+	ak.err = ""
+	ak.submitting = true
+	return ak, func() tea.Msg { return submit }
+}
+
+// expiryLabel describes a lifetime in days
+func expiryLabel(days int) string {
+	switch {
+	case days%365 == 0:
+		if days == 365 {
+			return "1 year"
+		}
+		return fmt.Sprintf("%d years", days/365)
+	case days == 1:
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", days)
+}
+
+// maskAPIKey hides all but the last four characters of a key
+func maskAPIKey(key string) string {
+	if len(key) <= 4 {
+		return strings.Repeat("•", len(key))
+	}
+	return strings.Repeat("•", min(len(key)-4, 24)) + key[len(key)-4:]
+}
+
+// View renders the form or the generated key
+func (ak APIKeyModal) View() string {
+	if !ak.visible {
+		return ""
+	}
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("62"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	focusStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("220"))
+	errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	warnStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
+
+	var lines []string
+	if ak.key != nil {
+		lines = append(lines, titleStyle.Render("◆ API Key Generated ◆"), "")
+		if ak.key.Name != "" {
+			lines = append(lines, "Name:    "+ak.key.Name)
+		}
+		key := maskAPIKey(ak.key.Key)
+		if ak.revealed {
+			key = ak.key.Key
+		}
+		lines = append(lines, "Key:     "+key)
+		if !ak.key.ExpiresAt.IsZero() {
+			lines = append(lines, "Expires: "+ak.key.ExpiresAt.Local().Format("2006-01-02 15:04"))
+		}
+		if len(ak.key.Scopes) > 0 {
+			lines = append(lines, "Scopes:  "+strings.Join(ak.key.Scopes, ", "))
+		}
+		warning := ak.warning
+		if warning == "" {
+			warning = "Store this key securely. It will not be shown again."
+		}
+		lines = append(lines, "", warnStyle.Render(warning))
+		if ak.copied {
+			lines = append(lines, lipgloss.NewStyle().Foreground(lipgloss.Color("46")).Render("Copied to the clipboard"))
+		}
+		if ak.err != "" {
+			lines = append(lines, errStyle.Render(ak.err))
+		}
+		reveal := "v: Reveal"
+		if ak.revealed {
+			reveal = "v: Hide"
+		}
+		lines = append(lines, "", dimStyle.Render(reveal+" | c: Copy | Enter/Esc: Done (the key is then forgotten)"))
+	} else {
+		lines = append(lines, titleStyle.Render("◆ Generate API Key ◆"), "", ak.name.View())
+
+		days := apiKeyExpiries[ak.expiry]
+		expiry := "Expires: ◀ " + expiryLabel(days) + " ▶"
+		if ak.focus == 1 {
+			expiry = focusStyle.Render(expiry)
+		}
+		expiry += " " + dimStyle.Render("("+time.Now().AddDate(0, 0, days).Format("2006-01-02")+")")
+		lines = append(lines, expiry, "", "Scopes:")
+		for i, scope := range apiKeyScopes {
+			checkbox := "[ ]"
+			if ak.scopes[i] {
+				checkbox = "[x]"
+			}
+			line := fmt.Sprintf("  %s %-10s", checkbox, scope.Name)
+			if ak.focus == i+2 {
+				line = focusStyle.Render(line)
+			}
+			lines = append(lines, line+" "+dimStyle.Render(scope.Description))
+		}
+		lines = append(lines, "")
+		switch {
+		case ak.submitting:
+			lines = append(lines, dimStyle.Render("Generating..."))
+		case ak.err != "":
+			lines = append(lines, errStyle.Render(ak.err))
+		}
+		lines = append(lines, dimStyle.Render("Tab: Next | ←/→: Lifetime | Space: Toggle scope | Enter: Generate | Esc: Cancel"))
+	}
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Padding(1, 2).
+		Render(strings.Join(lines, "\n"))
+}
+
+// openAPIKeyModal shows the API key generation form
+func (m *Model) openAPIKeyModal() {
+	m.apiKeyModal.Show()
+}
+
+// generateAPIKey sends the options picked in the generation form
+func (m *Model) generateAPIKey(msg APIKeyGenerateSubmitMsg) tea.Cmd {
+	apiKeyClient, ok := m.apiKeyClient.(*phoenix.ApiKeyClient)
+	if !ok || !m.connected {
+		m.apiKeyModal.SetError("Not connected to the server")
+		return nil
+	}
+	params := map[string]any{
+		"expires_in_days": msg.ExpiresInDays,
+		"scopes":          slices.Clone(msg.Scopes),
+	}
+	if msg.Name != "" {
+		params["name"] = msg.Name
+	}
+	m.statusBar = "Generating API key..."
+	return apiKeyClient.GenerateAPIKey(params)
+}
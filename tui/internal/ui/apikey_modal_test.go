@@ -0,0 +1,94 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/rubber_duck/tui/internal/phoenix"
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+func TestAPIKeyModalSubmitsOptions(t *testing.T) {
+	var modal APIKeyModal
+	modal.Show()
+
+	keys := []tea.KeyMsg{
+		{Type: tea.KeyRunes, Runes: []rune("laptop")},
+		{Type: tea.KeyTab},
+		{Type: tea.KeyLeft}, // One year back to 90 days
+		{Type: tea.KeyTab},
+		{Type: tea.KeySpace}, // Uncheck chat
+		{Type: tea.KeyTab},
+		{Type: tea.KeyTab},
+		{Type: tea.KeySpace}, // Check api_keys
+	}
+	for _, key := range keys {
+		modal, _ = modal.Update(key)
+	}
+	modal, cmd := modal.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Expected Enter to submit the form")
+	}
+	submit, ok := cmd().(APIKeyGenerateSubmitMsg)
+	if !ok {
+		t.Fatalf("Expected a submit message, got %T", cmd())
+	}
+	if submit.Name != "laptop" || submit.ExpiresInDays != 90 || strings.Join(submit.Scopes, ",") != "planning,api_keys" {
+		t.Errorf("Unexpected options %+v", submit)
+	}
+	if !modal.Waiting() {
+		t.Error("Expected the modal to wait for the key")
+	}
+
+	modal.Show()
+	for range 2 {
+		modal, _ = modal.Update(tea.KeyMsg{Type: tea.KeyTab})
+	}
+	for range 2 {
+		modal, _ = modal.Update(tea.KeyMsg{Type: tea.KeySpace})
+		modal, _ = modal.Update(tea.KeyMsg{Type: tea.KeyTab})
+	}
+	if modal, cmd = modal.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd != nil || modal.err == "" {
+		t.Error("Expected the form to refuse a key without scopes")
+	}
+}
+
+func TestAPIKeyRevealedOnce(t *testing.T) {
+	testutil.IsolateHome(t)
+	model := NewModel()
+	model.apiKeyModal.Show()
+
+	updated, _ := model.Update(phoenix.APIKeyGeneratedMsg{APIKey: phoenix.APIKey{
+		ID:        "key-1",
+		Key:       "rd_secret_value_1234",
+		Name:      "laptop",
+		Scopes:    []string{"chat"},
+		ExpiresAt: time.Now().AddDate(0, 0, 30),
+	}})
+	*model = updated.(Model)
+
+	for _, message := range model.chat.messages {
+		if strings.Contains(message.Content, "rd_secret_value") {
+			t.Fatalf("Expected the key kept out of the chat, got %q", message.Content)
+		}
+	}
+	view := ansi.Strip(model.apiKeyModal.View())
+	if strings.Contains(view, "rd_secret_value") || !strings.Contains(view, "1234") || !strings.Contains(view, "laptop") {
+		t.Errorf("Expected the key masked until revealed:\n%s", view)
+	}
+
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'v'}})
+	*model = updated.(Model)
+	if view := ansi.Strip(model.apiKeyModal.View()); !strings.Contains(view, "rd_secret_value_1234") {
+		t.Errorf("Expected v to reveal the key:\n%s", view)
+	}
+
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	*model = updated.(Model)
+	if model.apiKeyModal.IsVisible() || model.apiKeyModal.key != nil {
+		t.Error("Expected closing the modal to forget the key")
+	}
+}
@@ -0,0 +1,319 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rubber_duck/tui/internal/clock"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// staleAPIKeyAge is how long a valid key goes unused before the list
+// suggests revoking it
+const staleAPIKeyAge = 90 * 24 * time.Hour
+
+// apiKeySorts are the orders s steps through in the key list
+var apiKeySorts = []string{"last used", "requests", "created", "name"}
+
+// apiKeyRow is a key in the list with its usage
+type apiKeyRow struct {
+	Key      phoenix.APIKey
+	Requests int // -1 when the server didn't count them
+	LastUsed *time.Time
+	LastIP   string
+}
+
+// stale reports whether a valid key went unused for staleAPIKeyAge, and
+// for how long
+func (r apiKeyRow) stale(now time.Time) (time.Duration, bool) {
+	if !r.Key.Valid {
+		return 0, false
+	}
+	since := r.Key.CreatedAt
+	if r.LastUsed != nil {
+		since = *r.LastUsed
+	}
+	if since.IsZero() {
+		return 0, false
+	}
+	idle := now.Sub(since)
+	return idle, idle >= staleAPIKeyAge
+}
+
+// APIKeyList shows the account's API keys with how much each is used,
+// sortable, flagging keys unused for long enough to revoke
+type APIKeyList struct {
+	keys     []phoenix.APIKey
+	usage    map[string]phoenix.APIKeyUsage
+	usageErr string // Why there are no usage counts
+	sortBy   int    // Index into apiKeySorts
+	cursor   int
+	loading  bool
+	visible  bool
+	width    int
+	height   int
+}
+
+// Show opens the list, waiting for the keys to arrive
+func (kl *APIKeyList) Show() {
+	kl.keys = nil
+	kl.usage = nil
+	kl.usageErr = ""
+	kl.cursor = 0
+	kl.loading = true
+	kl.visible = true
+}
+
+// SetKeys fills the list, keeping the selected key when it is refreshed
+func (kl *APIKeyList) SetKeys(keys []phoenix.APIKey) {
+	selected := kl.selectedID()
+	kl.keys = keys
+	kl.loading = false
+	kl.selectID(selected)
+}
+
+// SetUsage adds the usage counts to the list
+func (kl *APIKeyList) SetUsage(usage []phoenix.APIKeyUsage) {
+	selected := kl.selectedID()
+	kl.usage = make(map[string]phoenix.APIKeyUsage, len(usage))
+	for _, keyUsage := range usage {
+		kl.usage[keyUsage.APIKeyID] = keyUsage
+	}
+	kl.usageErr = ""
+	kl.selectID(selected)
+}
+
+// SetUsageError notes that the server couldn't give usage counts
+func (kl *APIKeyList) SetUsageError(message string) {
+	kl.usageErr = message
+}
+
+// SetError shows a failed listing
+func (kl *APIKeyList) SetError(message string) {
+	kl.loading = false
+	kl.usageErr = message
+}
+
+// Hide hides the list
+func (kl *APIKeyList) Hide() {
+	kl.visible = false
+}
+
+// IsVisible returns whether the list is visible
+func (kl APIKeyList) IsVisible() bool {
+	return kl.visible
+}
+
+// SetSize updates the list dimensions
+func (kl *APIKeyList) SetSize(width, height int) {
+	kl.width = width
+	kl.height = height
+}
+
+// rows joins the keys with their usage in the chosen order
+func (kl APIKeyList) rows() []apiKeyRow {
+	rows := make([]apiKeyRow, len(kl.keys))
+	for i, key := range kl.keys {
+		row := apiKeyRow{Key: key, Requests: -1, LastUsed: key.LastUsedAt, LastIP: key.LastIP}
+		if usage, ok := kl.usage[key.ID]; ok {
+			row.Requests = usage.Requests
+			if usage.LastUsedAt != nil {
+				row.LastUsed = usage.LastUsedAt
+				row.LastIP = usage.LastIP
+			}
+		}
+		rows[i] = row
+	}
+
+	lastUsed := func(row apiKeyRow) time.Time {
+		if row.LastUsed == nil {
+			return time.Time{}
+		}
+		return *row.LastUsed
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		switch apiKeySorts[kl.sortBy] {
+		case "requests":
+			return rows[i].Requests > rows[j].Requests
+		case "created":
+			return rows[i].Key.CreatedAt.After(rows[j].Key.CreatedAt)
+		case "name":
+			return strings.ToLower(rows[i].Key.Name) < strings.ToLower(rows[j].Key.Name)
+		}
+		// Least recently used first, so the keys to revoke come first
+		return lastUsed(rows[i]).Before(lastUsed(rows[j]))
+	})
+	return rows
+}
+
+// selectedID returns the ID of the selected key
+func (kl APIKeyList) selectedID() string {
+	rows := kl.rows()
+	if kl.cursor < len(rows) {
+		return rows[kl.cursor].Key.ID
+	}
+	return ""
+}
+
+// selectID moves the cursor to a key, staying in range when it is gone
+func (kl *APIKeyList) selectID(id string) {
+	rows := kl.rows()
+	for i, row := range rows {
+		if row.Key.ID == id {
+			kl.cursor = i
+			return
+		}
+	}
+	kl.cursor = min(kl.cursor, max(len(rows)-1, 0))
+}
+
+// Update handles list input
+func (kl APIKeyList) Update(msg tea.Msg) (APIKeyList, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || !kl.visible {
+		return kl, nil
+	}
+
+	switch keyMsg.String() {
+	case "esc", "q":
+		kl.Hide()
+	case "up", "k":
+		kl.cursor = max(kl.cursor-1, 0)
+	case "down", "j":
+		kl.cursor = min(kl.cursor+1, max(len(kl.keys)-1, 0))
+	case "s":
+		selected := kl.selectedID()
+		kl.sortBy = (kl.sortBy + 1) % len(apiKeySorts)
+		kl.selectID(selected)
+	case "d", "delete":
+		id := kl.selectedID()
+		if id == "" {
+			break
+		}
+		return kl, func() tea.Msg {
+			return ExecuteCommandMsg{Command: "auth_apikey_revoke", Args: map[string]string{"id": id}}
+		}
+	case "g":
+		return kl, func() tea.Msg { return ExecuteCommandMsg{Command: "auth_apikey_list"} }
+	}
+	return kl, nil
+}
+
+// formatIdle describes how long ago something happened in days
+func formatIdle(idle time.Duration) string {
+	days := int(idle.Hours() / 24)
+	switch {
+	case days == 0:
+		return "today"
+	case days == 1:
+		return "yesterday"
+	}
+	return fmt.Sprintf("%dd ago", days)
+}
+
+// View renders the keys with their usage, and the selected key's details
+func (kl APIKeyList) View() string {
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("62"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	cursorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
+	staleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("208"))
+	revokedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Strikethrough(true)
+
+	now := clock.Now()
+	rows := kl.rows()
+	stale := 0
+	for _, row := range rows {
+		if _, ok := row.stale(now); ok {
+			stale++
+		}
+	}
+	summary := fmt.Sprintf("  %d keys, sorted by %s", len(rows), apiKeySorts[kl.sortBy])
+	if stale > 0 {
+		summary += fmt.Sprintf(", %d unused for %d+ days", stale, int(staleAPIKeyAge.Hours()/24))
+	}
+	lines := []string{titleStyle.Render("API keys") + dimStyle.Render(summary), ""}
+
+	switch {
+	case kl.loading:
+		lines = append(lines, dimStyle.Render("Loading..."))
+	case len(rows) == 0:
+		lines = append(lines, dimStyle.Render("No API keys - /apikey generate creates one"))
+	default:
+		lines = append(lines, dimStyle.Render(fmt.Sprintf("  %-16s %-14s %9s  %-12s %s", "ID", "Name", "Requests", "Last used", "")))
+	}
+
+	room := max(kl.height-16, 3)
+	start := max(min(kl.cursor-room/2, len(rows)-room), 0)
+	for i := start; i < min(start+room, len(rows)); i++ {
+		row := rows[i]
+		requests := "-"
+		if row.Requests >= 0 {
+			requests = fmt.Sprintf("%d", row.Requests)
+		}
+		used := "never"
+		if row.LastUsed != nil {
+			used = formatIdle(now.Sub(*row.LastUsed))
+		}
+		line := fmt.Sprintf("%-16s %-14s %9s  %-12s", truncateStage(row.Key.ID, 16), truncateStage(row.Key.Name, 14), requests, used)
+		switch idle, isStale := row.stale(now); {
+		case !row.Key.Valid:
+			line = revokedStyle.Render(line) + dimStyle.Render(" revoked")
+		case isStale:
+			line = staleStyle.Render(line + fmt.Sprintf(" unused %dd - consider revoking (d)", int(idle.Hours()/24)))
+		}
+		if i == kl.cursor {
+			line = cursorStyle.Render("▶ ") + line
+		} else {
+			line = "  " + line
+		}
+		lines = append(lines, line)
+	}
+
+	if kl.cursor < len(rows) {
+		row := rows[kl.cursor]
+		details := fmt.Sprintf("Created %s", row.Key.CreatedAt.Local().Format("2006-01-02"))
+		if row.Key.CreatedFrom != "" {
+			details += " from " + row.Key.CreatedFrom
+		}
+		details += ", expires " + row.Key.ExpiresAt.Local().Format("2006-01-02")
+		if row.LastUsed != nil {
+			details += "\nLast used " + row.LastUsed.Local().Format("2006-01-02 15:04")
+			if row.LastIP != "" {
+				details += " from " + row.LastIP
+			}
+		}
+		if len(row.Key.Scopes) > 0 {
+			details += "\nScopes: " + strings.Join(row.Key.Scopes, ", ")
+		}
+		lines = append(lines, "", details)
+	}
+	if kl.usageErr != "" {
+		lines = append(lines, "", dimStyle.Render("Usage counts unavailable: "+kl.usageErr))
+	}
+	lines = append(lines, "", dimStyle.Render("↑/↓: Select | s: Sort | d: Revoke | g: Refresh | Esc: Close"))
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Padding(0, 1).
+		Width(min(max(kl.width-10, 60), 110)).
+		Render(strings.Join(lines, "\n"))
+}
+
+// listAPIKeys opens the key list and asks for the keys and their usage
+func (m *Model) listAPIKeys() tea.Cmd {
+	apiKeyClient, ok := m.apiKeyClient.(*phoenix.ApiKeyClient)
+	if !ok {
+		return nil
+	}
+	if !m.apiKeyList.IsVisible() {
+		m.apiKeyList.SetSize(m.width, m.height)
+		m.apiKeyList.Show()
+	}
+	m.statusBar = "Listing API keys..."
+	return tea.Batch(apiKeyClient.ListAPIKeys(), apiKeyClient.GetAPIKeyUsage())
+}
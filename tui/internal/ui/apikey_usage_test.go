@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/rubber_duck/tui/internal/clock"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+func TestAPIKeyListUsageAndStaleKeys(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	defer clock.Freeze(now)()
+	days := func(n int) time.Time { return now.AddDate(0, 0, -n) }
+	recent, old := days(2), days(120)
+
+	var list APIKeyList
+	list.SetSize(120, 40)
+	list.Show()
+	list.SetKeys([]phoenix.APIKey{
+		{ID: "key-busy", Name: "ci", Valid: true, CreatedAt: days(200)},
+		{ID: "key-idle", Name: "laptop", Valid: true, CreatedAt: days(300)},
+		{ID: "key-fresh", Name: "new", Valid: true, CreatedAt: days(1)},
+	})
+	list.SetUsage([]phoenix.APIKeyUsage{
+		{APIKeyID: "key-busy", Requests: 42, LastUsedAt: &recent},
+		{APIKeyID: "key-idle", Requests: 3, LastUsedAt: &old},
+	})
+
+	// Least recently used first: never used, then the idle key. The key
+	// selected before usage arrived stays selected
+	if list.selectedID() != "key-busy" {
+		t.Errorf("Expected the selection kept when usage arrives, got %s", list.selectedID())
+	}
+	var order []string
+	for _, row := range list.rows() {
+		order = append(order, row.Key.ID)
+	}
+	if got := strings.Join(order, ","); got != "key-fresh,key-idle,key-busy" {
+		t.Errorf("Expected least recently used first, got %s", got)
+	}
+
+	view := ansi.Strip(list.View())
+	if !strings.Contains(view, "42") || !strings.Contains(view, "unused 120d - consider revoking") {
+		t.Errorf("Expected counts and the idle key flagged:\n%s", view)
+	}
+	if strings.Count(view, "consider revoking") != 1 {
+		t.Errorf("Expected only the idle key flagged, a new unused key isn't stale:\n%s", view)
+	}
+
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyUp})
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyUp})
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	if rows := list.rows(); rows[0].Key.ID != "key-busy" || list.selectedID() != "key-fresh" {
+		t.Errorf("Expected sorting by requests to keep the selection, got %s first and %s selected", rows[0].Key.ID, list.selectedID())
+	}
+
+	list, cmd := list.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	if cmd == nil {
+		t.Fatal("Expected d to revoke the selected key")
+	}
+	if revoke, ok := cmd().(ExecuteCommandMsg); !ok || revoke.Command != "auth_apikey_revoke" || revoke.Args["id"] != "key-fresh" {
+		t.Errorf("Expected a revoke of key-fresh, got %+v", cmd())
+	}
+}
@@ -0,0 +1,106 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// maxUploadSize limits the files /attach uploads to the server
+const maxUploadSize = 64 * 1024 * 1024
+
+// attachmentDir is where uploaded attachments go on the server
+const attachmentDir = ".rubber_duck/attachments"
+
+// AttachmentUploadedMsg reports a finished /attach upload
+type AttachmentUploadedMsg struct {
+	Path   string // Local file
+	Remote string // Where the server stored it
+	Size   int64
+	Err    error
+}
+
+// attachFile attaches a local file to the next message. Files small enough
+// to inline open in the compose modal; larger ones are uploaded to the
+// server in chunks and referenced by their server path
+func (m *Model) attachFile(file string) tea.Cmd {
+	local := m.projectFile(file)
+	info, err := os.Stat(local)
+	if err != nil {
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Cannot attach %s: %v", file, err), nil)
+		return nil
+	}
+	if info.IsDir() {
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Cannot attach %s: is a directory", file), nil)
+		return nil
+	}
+
+	if info.Size() <= maxAttachmentSize {
+		m.composeModal.SetSize(m.width, m.height)
+		m.composeModal.Show(m.chat.GetInputValue())
+		m.composeModal.addAttachment(file)
+		m.chat.SetInputValue("")
+		m.statusBar = "Composing message"
+		return nil
+	}
+
+	client, ok := m.phoenixClient.(*phoenix.Client)
+	switch {
+	case info.Size() > maxUploadSize:
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Cannot attach %s: larger than %d MB", file, maxUploadSize/1024/1024), nil)
+		return nil
+	case !ok || !m.connected:
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Cannot attach %s: files over %d KB are uploaded, which needs a connection", file, maxAttachmentSize/1024), nil)
+		return nil
+	case !m.capabilities.Has(phoenix.CapabilityChunkedTransfer):
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Cannot attach %s: larger than %d KB and the server does not accept uploads", file, maxAttachmentSize/1024), nil)
+		return nil
+	}
+
+	remote := path.Join(attachmentDir, filepath.Base(file))
+	m.statusBar = fmt.Sprintf("Uploading %s...", filepath.Base(file))
+	progress := client.ReportProgress(file, true)
+	return func() tea.Msg {
+		data, err := os.ReadFile(local)
+		if err == nil {
+			err = client.Upload(context.Background(), remote, data, progress)
+		}
+		return AttachmentUploadedMsg{Path: file, Remote: remote, Size: int64(len(data)), Err: err}
+	}
+}
+
+// handleTransferProgress shows a chunked transfer's progress in the status bar
+func (m *Model) handleTransferProgress(msg phoenix.TransferProgressMsg) {
+	verb := "Downloading"
+	if msg.Upload {
+		verb = "Uploading"
+	}
+	percent := 100
+	if msg.Total > 0 {
+		percent = int(msg.Done * 100 / msg.Total)
+	}
+	m.statusBar = fmt.Sprintf("%s %s: %d%% of %s", verb, filepath.Base(msg.Path), percent, formatBytes(msg.Total))
+}
+
+// handleAttachmentUploaded references an uploaded attachment in the input
+func (m *Model) handleAttachmentUploaded(msg AttachmentUploadedMsg) {
+	if msg.Err != nil {
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Upload of %s failed: %v - run /attach again to resume", msg.Path, msg.Err), nil)
+		m.statusBar = "Upload failed"
+		return
+	}
+	reference := fmt.Sprintf("**Attachment:** `%s` (%s, uploaded to the server)", msg.Remote, formatBytes(msg.Size))
+	input := strings.TrimRight(m.chat.GetInputValue(), "\n")
+	if input != "" {
+		input += "\n\n"
+	}
+	m.chat.SetInputValue(input + reference)
+	m.statusMessages.AddMessage(StatusCategoryInfo, fmt.Sprintf("Uploaded %s to %s", msg.Path, msg.Remote), nil)
+	m.statusBar = "Attachment uploaded"
+}
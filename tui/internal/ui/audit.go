@@ -0,0 +1,196 @@
+package ui
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/clock"
+)
+
+// maxAuditLogSize is the size at which the audit log is rotated to
+// audit.log.1, replacing the previous one
+const maxAuditLogSize = 5 * 1024 * 1024
+
+// auditEntriesShown is how many entries /audit lists by default
+const auditEntriesShown = 20
+
+// Audit statuses besides "ok"
+const (
+	auditError      = "error"      // Ran and reported an error
+	auditDenied     = "denied"     // The account lacks the permission
+	auditBlocked    = "blocked"    // Blocked by blocked_commands
+	auditConfirming = "confirming" // Waiting for the user to confirm
+)
+
+// auditEntry is one executed command in the audit log. Arguments are only
+// kept as a hash, so the log shows whether two runs had the same arguments
+// without recording them
+type auditEntry struct {
+	Time       time.Time `json:"time"`
+	Command    string    `json:"command"`
+	ArgsHash   string    `json:"args_hash,omitempty"`
+	DurationMS float64   `json:"duration_ms"`
+	Status     string    `json:"status"`
+}
+
+// auditLogPath returns where executed commands are recorded
+func auditLogPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".rubber_duck", "audit.log"), nil
+}
+
+// hashArgs returns a short hash of a command's arguments, "" for none
+func hashArgs(args map[string]string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	// Maps marshal with sorted keys, so equal arguments hash alike
+	data, _ := json.Marshal(args)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:6])
+}
+
+// appendAuditEntry adds an entry to the audit log, rotating it when full
+func appendAuditEntry(entry auditEntry) error {
+	path, err := auditLogPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	if info, err := os.Stat(path); err == nil && info.Size() > maxAuditLogSize {
+		_ = os.Rename(path, path+".1")
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+// readAuditLog returns the last n entries of the audit log, oldest first
+func readAuditLog(n int) ([]auditEntry, error) {
+	path, err := auditLogPath()
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []auditEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry auditEntry
+		if json.Unmarshal(scanner.Bytes(), &entry) == nil {
+			entries = append(entries, entry)
+		}
+	}
+	if len(entries) > n {
+		entries = entries[len(entries)-n:]
+	}
+	return entries, scanner.Err()
+}
+
+// auditStatus predicts how handleCommand will refuse a command, "" when it
+// will run
+func (m Model) auditStatus(msg ExecuteCommandMsg) string {
+	if permission, ok := commandPermissions[msg.Command]; ok && !m.permissions.Has(permission) {
+		return auditDenied
+	}
+	if commandBlocked(m.config.TUI.BlockedCommands, msg.Command) {
+		return auditBlocked
+	}
+	if m.needsConfirmation(msg) {
+		return auditConfirming
+	}
+	return ""
+}
+
+// runCommand runs a command and records it in the audit log and the metrics
+func (m Model) runCommand(msg ExecuteCommandMsg) (Model, tea.Cmd) {
+	status := m.auditStatus(msg)
+	errorsBefore := m.statusMessages.errorCount
+	outputFrom := m.chat.GetMessageCount()
+	started := time.Now()
+
+	m, cmd := m.handleCommand(msg)
+
+	if status == "" {
+		status = "ok"
+		if m.statusMessages.errorCount > errorsBefore {
+			status = auditError
+		}
+	}
+	m.observeCommand(msg.Command, status, started)
+	switch {
+	case m.commandResults != nil:
+		m.commandResults(m.collectOutput(msg.Command, status, outputFrom))
+	case msg.Format == formatJSON:
+		m.showJSONResult(m.collectOutput(msg.Command, status, outputFrom))
+	}
+	if m.config.TUI.AuditLogOff {
+		return m, cmd
+	}
+	entry := auditEntry{
+		Time:       clock.Now(),
+		Command:    msg.Command,
+		ArgsHash:   hashArgs(msg.Args),
+		DurationMS: float64(time.Since(started).Microseconds()) / 1000,
+		Status:     status,
+	}
+	if err := appendAuditEntry(entry); err != nil && !m.auditFailed {
+		// Said once; commands keep working without the log
+		m.auditFailed = true
+		m.statusMessages.AddMessage(StatusCategoryInfo, fmt.Sprintf("Cannot write the audit log: %v", err), nil)
+	}
+	return m, cmd
+}
+
+// formatAuditLog renders the last n audit entries
+func formatAuditLog(n int) string {
+	entries, err := readAuditLog(n)
+	if err != nil {
+		return fmt.Sprintf("Cannot read the audit log: %v", err)
+	}
+	path, _ := auditLogPath()
+	if len(entries) == 0 {
+		return fmt.Sprintf("No commands recorded yet in %s", path)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Last %d commands (%s):\n", len(entries), path)
+	for _, entry := range entries {
+		args := entry.ArgsHash
+		if args == "" {
+			args = "-"
+		}
+		fmt.Fprintf(&b, "  %s  %-22s %-12s %-10s %8.1f ms\n",
+			entry.Time.Local().Format("2006-01-02 15:04:05"), entry.Command, args, entry.Status, entry.DurationMS)
+	}
+	b.WriteString("\nArguments are stored only as hashes; equal hashes mean equal arguments.")
+	return b.String()
+}
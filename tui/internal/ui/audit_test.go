@@ -0,0 +1,48 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+func TestCommandsAreAudited(t *testing.T) {
+	testutil.IsolateHome(t)
+	var model Model = *NewModel()
+	run := func(msg ExecuteCommandMsg) {
+		updated, _ := model.Update(msg)
+		model = updated.(Model)
+	}
+
+	run(ExecuteCommandMsg{Command: "help", Args: map[string]string{"topic": "errors"}})
+	run(ExecuteCommandMsg{Command: "cancel_planning"})
+	model.config.TUI.BlockedCommands = []string{"help"}
+	run(ExecuteCommandMsg{Command: "help", Args: map[string]string{"topic": "errors"}})
+
+	entries, err := readAuditLog(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"help ok", "cancel_planning confirming", "help blocked"}
+	if len(entries) != len(want) {
+		t.Fatalf("Expected %d entries, got %+v", len(want), entries)
+	}
+	for i, entry := range entries {
+		if got := entry.Command + " " + entry.Status; got != want[i] {
+			t.Errorf("Entry %d: expected %q, got %q", i, want[i], got)
+		}
+	}
+	if entries[0].ArgsHash == "" || entries[0].ArgsHash != entries[2].ArgsHash {
+		t.Errorf("Expected equal arguments to hash alike, got %q and %q", entries[0].ArgsHash, entries[2].ArgsHash)
+	}
+	if strings.Contains(formatAuditLog(10), "errors") {
+		t.Error("Arguments should not appear in the audit log")
+	}
+
+	model.config.TUI.AuditLogOff = true
+	run(ExecuteCommandMsg{Command: "queue"})
+	if entries, _ := readAuditLog(10); len(entries) != 3 {
+		t.Errorf("Expected nothing recorded with audit_log_off, got %d entries", len(entries))
+	}
+}
@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// cache returns the phoenix client's response cache, nil without a client
+func (m Model) cache() *phoenix.ResponseCache {
+	if client, ok := m.phoenixClient.(*phoenix.Client); ok {
+		return client.Cache()
+	}
+	return nil
+}
+
+// applyCacheTTLs sets how long answers to idempotent queries are reused
+func (m *Model) applyCacheTTLs() {
+	cache := m.cache()
+	if cache == nil {
+		return
+	}
+	ttls := make(map[string]time.Duration, len(m.config.TUI.CacheTTLSeconds))
+	for event, seconds := range m.config.TUI.CacheTTLSeconds {
+		ttls[event] = time.Duration(seconds) * time.Second
+	}
+	cache.SetTTLs(ttls)
+}
+
+// clearCache asks for cached answers to be dropped, all of them when no
+// events are given
+func clearCache(events ...string) tea.Cmd {
+	return func() tea.Msg { return ClearCacheMsg{Events: events} }
+}
+
+// handleClearCache drops cached answers
+func (m *Model) handleClearCache(msg ClearCacheMsg) {
+	cache := m.cache()
+	if cache == nil {
+		return
+	}
+	dropped := cache.Clear(msg.Events...)
+	if !msg.Quiet {
+		m.statusBar = fmt.Sprintf("Cache cleared - %d answers dropped", dropped)
+	}
+}
+
+// formatCacheStatus describes what the cache holds
+func (m Model) formatCacheStatus() string {
+	cache := m.cache()
+	if cache == nil {
+		return "No server connection, so nothing is cached"
+	}
+	return cache.Stats().Format(cache) + "\n\n/cache clear drops everything; tui.cache_ttl_seconds changes how long answers are kept"
+}
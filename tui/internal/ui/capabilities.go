@@ -0,0 +1,69 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// capabilityNames describes negotiated features in user-facing messages
+var capabilityNames = map[string]string{
+	phoenix.CapabilityStreaming: "streaming responses",
+	phoenix.CapabilityPlanning:  "planning",
+	phoenix.CapabilityAPIKeys:   "API key management",
+	phoenix.CapabilityFiles:     "the file API",
+	phoenix.CapabilitySearch:    "server search",
+}
+
+// applyNegotiation stores the server's capabilities and explains what is
+// unavailable
+func (m *Model) applyNegotiation(n phoenix.Negotiation) {
+	m.capabilities = n.Capabilities
+	m.serverVersion = n.ServerVersion
+
+	if !n.Compatible() {
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Server speaks protocol v%s but this client speaks v%s - some features may not work", n.ProtocolVersion, phoenix.ProtocolVersion), nil)
+	}
+
+	missing := n.Capabilities.Missing()
+	if len(missing) == 0 {
+		return
+	}
+	names := make([]string, len(missing))
+	for i, capability := range missing {
+		names[i] = capabilityNames[capability]
+	}
+	server := "The server"
+	if n.ServerVersion != "" {
+		server = "Server " + n.ServerVersion
+	}
+	m.chat.AddMessage(SystemMessage, fmt.Sprintf("%s does not support %s - those features are disabled.", server, strings.Join(names, ", ")), "system")
+}
+
+// requireCapability reports whether a feature is available, explaining why
+// not when it isn't
+func (m *Model) requireCapability(capability string) bool {
+	if m.capabilities.Has(capability) {
+		return true
+	}
+	m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("The server does not support %s", capabilityNames[capability]), nil)
+	return false
+}
+
+// joinFeatureChannels joins the status channel and the feature channels the
+// server negotiated
+func (m *Model) joinFeatureChannels() []tea.Cmd {
+	var cmds []tea.Cmd
+	if statusClient, ok := m.statusClient.(*phoenix.StatusClient); ok {
+		cmds = append(cmds, statusClient.JoinStatusChannel(m.conversationID))
+	}
+	if m.capabilities.Has(phoenix.CapabilityAPIKeys) {
+		cmds = append(cmds, func() tea.Msg { return JoinApiKeyChannelMsg{} })
+	}
+	if m.capabilities.Has(phoenix.CapabilityPlanning) {
+		cmds = append(cmds, func() tea.Msg { return JoinPlanningChannelMsg{} })
+	}
+	return cmds
+}
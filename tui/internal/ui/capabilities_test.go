@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rubber_duck/tui/internal/phoenix"
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+func TestNegotiationDisablesMissingFeatures(t *testing.T) {
+	testutil.IsolateHome(t)
+	m := NewModel()
+	m.authenticated = true
+	m.applyNegotiation(phoenix.ParseNegotiation(map[string]any{
+		"server_version": "0.9.0",
+		"capabilities":   []any{"streaming", "files", "search", "api_keys"},
+	}))
+
+	messages := m.chat.GetMessages()
+	if last := messages[len(messages)-1].Content; !strings.Contains(last, "Server 0.9.0 does not support planning") {
+		t.Errorf("Expected a degradation message, got %q", last)
+	}
+
+	updated, cmd := m.handleCommand(ExecuteCommandMsg{Command: "start_planning", Args: map[string]string{"query": "refactor"}})
+	if cmd != nil || updated.statusBar == "Starting planning session..." {
+		t.Error("Expected planning to be refused when the server lacks it")
+	}
+	if cmds := m.joinFeatureChannels(); len(cmds) != 2 {
+		t.Errorf("Expected status and API key channels only, got %d commands", len(cmds))
+	}
+}
+
+func TestPermissionsLockCommands(t *testing.T) {
+	testutil.IsolateHome(t)
+	m := NewModel()
+	m.authenticated = true
+	m.applyGrants(phoenix.AuthUser{Username: "duck", Roles: []string{"user"}, Permissions: []string{phoenix.PermissionSessions}})
+
+	updated, cmd := m.handleCommand(ExecuteCommandMsg{Command: "auth_apikey_list"})
+	if cmd != nil || !strings.Contains(updated.statusBar, "Permission required") {
+		t.Errorf("Expected API key listing to be refused, got %q", updated.statusBar)
+	}
+	if help := formatSlashCommandsFor(m.permissions); !strings.Contains(help, "/sessions [revoke <id>]") || strings.Count(help, lockIcon) != 3 {
+		t.Errorf("Expected /apikey, /plan, and /admin to be locked:\n%s", help)
+	}
+	for _, c := range m.commandPalette.commands {
+		if locked := c.Action == "auth_apikey_list" || c.Action == "auth_apikey_generate" || c.Action == "admin_panel"; c.Locked != locked {
+			t.Errorf("Palette command %s: expected locked=%v", c.Action, locked)
+		}
+	}
+
+	// Admins can do everything; servers that send no roles lock nothing
+	if !(phoenix.AuthUser{Roles: []string{phoenix.RoleAdmin}}).Grants().Has(phoenix.PermissionAdmin) {
+		t.Error("Expected the admin role to grant admin commands")
+	}
+	if !(phoenix.AuthUser{}).Grants().Has(phoenix.PermissionAPIKeys) {
+		t.Error("Expected no gating without roles or permissions")
+	}
+}
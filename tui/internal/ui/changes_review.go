@@ -0,0 +1,366 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// Review states of a changed file
+const (
+	changePending = iota
+	changeApplied
+	changeFailed
+	changeRolledBack
+)
+
+// reviewFile is one file of a multi-file refactor under review
+type reviewFile struct {
+	Path     string
+	Original string
+	Existed  bool // The file existed before the change
+	Updated  string
+	Hunks    []hunk
+	Accepted bool
+	State    int
+	Err      string
+}
+
+// fileSnapshot is a file's content before a change was applied, kept so
+// the change can be rolled back
+type fileSnapshot struct {
+	Path    string
+	Content string
+	Existed bool
+}
+
+// ApplyNextChangeMsg applies the next accepted file of the review
+type ApplyNextChangeMsg struct {
+	Index int
+}
+
+// ChangesReview groups a refactor's hunks by file so each file can be
+// accepted or rejected before anything is written
+type ChangesReview struct {
+	files    []reviewFile
+	cursor   int
+	applying bool
+	applied  bool // Accepted files have been written
+	visible  bool
+	width    int
+	height   int
+}
+
+// Show reviews changes against the files' current contents
+func (cr *ChangesReview) Show(files []reviewFile) {
+	cr.files = files
+	cr.cursor = 0
+	cr.applying = false
+	cr.applied = false
+	cr.visible = true
+}
+
+// Hide hides the review
+func (cr *ChangesReview) Hide() {
+	cr.visible = false
+}
+
+// IsVisible returns whether the review is visible
+func (cr ChangesReview) IsVisible() bool {
+	return cr.visible
+}
+
+// SetSize updates the review dimensions
+func (cr *ChangesReview) SetSize(width, height int) {
+	cr.width = width
+	cr.height = height
+}
+
+// nextAccepted returns the first accepted, unapplied file from index on,
+// or -1 when none are left
+func (cr ChangesReview) nextAccepted(index int) int {
+	for i := index; i < len(cr.files); i++ {
+		if cr.files[i].Accepted && cr.files[i].State == changePending {
+			return i
+		}
+	}
+	return -1
+}
+
+// progress counts the accepted files and those already written
+func (cr ChangesReview) progress() (done, total int) {
+	for _, file := range cr.files {
+		if !file.Accepted {
+			continue
+		}
+		total++
+		if file.State != changePending {
+			done++
+		}
+	}
+	return done, total
+}
+
+// Update handles review input
+func (cr ChangesReview) Update(msg tea.Msg) (ChangesReview, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || !cr.visible || cr.applying {
+		return cr, nil
+	}
+
+	switch keyMsg.String() {
+	case "esc", "q":
+		cr.Hide()
+	case "up", "k":
+		cr.cursor = max(cr.cursor-1, 0)
+	case "down", "j":
+		cr.cursor = min(cr.cursor+1, len(cr.files)-1)
+	case " ", "y", "n":
+		if cr.applied {
+			break
+		}
+		file := &cr.files[cr.cursor]
+		switch keyMsg.String() {
+		case "y":
+			file.Accepted = true
+		case "n":
+			file.Accepted = false
+		default:
+			file.Accepted = !file.Accepted
+		}
+	case "enter":
+		if cr.applied {
+			break
+		}
+		first := cr.nextAccepted(0)
+		if first < 0 {
+			break
+		}
+		cr.applying = true
+		return cr, func() tea.Msg { return ApplyNextChangeMsg{Index: first} }
+	case "r":
+		if cr.applied {
+			return cr, func() tea.Msg { return ExecuteCommandMsg{Command: "rollback_changes"} }
+		}
+	}
+	return cr, nil
+}
+
+// View renders the review
+func (cr ChangesReview) View() string {
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("62"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	cursorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+
+	done, total := cr.progress()
+	status := fmt.Sprintf("%d of %d files accepted", total, len(cr.files))
+	switch {
+	case cr.applying:
+		status = fmt.Sprintf("Applying %d/%d...", done, total)
+	case cr.applied:
+		status = fmt.Sprintf("Applied %d/%d files", done, total)
+	}
+	lines := []string{titleStyle.Render("Review changes"), dimStyle.Render(status), ""}
+
+	for i, file := range cr.files {
+		mark := "[ ]"
+		if file.Accepted {
+			mark = "[x]"
+		}
+		added, removed := 0, 0
+		for _, h := range file.Hunks {
+			added += len(h.Added)
+			removed += len(h.Removed)
+		}
+		label := fmt.Sprintf("%s %s  +%d -%d", mark, file.Path, added, removed)
+		if !file.Existed {
+			label += " (new)"
+		}
+		switch file.State {
+		case changeApplied:
+			label += " ✓"
+		case changeRolledBack:
+			label += " ↺"
+		case changeFailed:
+			label += " " + errorStyle.Render("✗ "+file.Err)
+		}
+		if i == cr.cursor {
+			label = cursorStyle.Render("▶ ") + label
+		} else {
+			label = "  " + label
+		}
+		lines = append(lines, label)
+	}
+	lines = append(lines, "")
+
+	// The selected file's hunks fill the rest of the screen
+	if len(cr.files) > 0 {
+		file := cr.files[cr.cursor]
+		old := strings.Split(file.Original, "\n")
+		var hunkLines []string
+		for _, h := range file.Hunks {
+			hunkLines = append(hunkLines, strings.Split(renderHunk(old, h), "\n")...)
+		}
+		room := max(cr.height-len(lines)-2, 3)
+		if len(hunkLines) > room {
+			hunkLines = append(hunkLines[:room-1], dimStyle.Render(fmt.Sprintf("… %d more lines", len(hunkLines)-room+1)))
+		}
+		lines = append(lines, hunkLines...)
+	}
+
+	footer := "↑/↓: File | Space/y/n: Accept or reject file | Enter: Apply accepted | Esc: Cancel"
+	if cr.applied {
+		footer = "r: Roll back | Esc: Close"
+	}
+	lines = append(lines, dimStyle.Render(footer))
+	return lipgloss.NewStyle().Padding(0, 1).Render(strings.Join(lines, "\n"))
+}
+
+// reviewChanges opens the review for a multi-file refactor
+func (m *Model) reviewChanges(changes []phoenix.FileChange) {
+	fs := m.fileTree.FileSystem()
+	var files []reviewFile
+	for _, change := range changes {
+		file := reviewFile{Path: change.Path, Updated: change.Content, Accepted: true}
+		if data, err := fs.ReadFile(change.Path); err == nil {
+			file.Original = string(data)
+			file.Existed = true
+		} else if !os.IsNotExist(err) && fs.Name() == FileSourceLocal {
+			file.Accepted = false
+			file.State = changeFailed
+			file.Err = err.Error()
+		}
+		file.Hunks = diffHunks(file.Original, file.Updated)
+		if len(file.Hunks) > 0 || file.State == changeFailed {
+			files = append(files, file)
+		}
+	}
+	if len(files) == 0 {
+		m.statusBar = "The files already match the suggested changes"
+		return
+	}
+	m.changesReview.Show(files)
+}
+
+// permitChangeBatch asks before the review writes the suggested changes to
+// local files. Only the first file of a batch asks
+func (m *Model) permitChangeBatch(msg ApplyNextChangeMsg) bool {
+	if m.fileTree.FileSystem().Name() != FileSourceLocal {
+		return true
+	}
+	if done, _ := m.changesReview.progress(); done > 0 {
+		return true
+	}
+	var paths []string
+	for _, file := range m.changesReview.files {
+		if file.Accepted && file.State == changePending {
+			paths = append(paths, "  "+file.Path)
+		}
+	}
+	if len(paths) > 10 {
+		paths = append(paths[:10], fmt.Sprintf("  ... and %d more", len(paths)-10))
+	}
+	return m.checkPermission(permissionRequest{
+		Action: permissionWriteFiles,
+		Title:  "write local files",
+		Detail: "Write the changes the server suggested to these local files:\n\n" + strings.Join(paths, "\n"),
+	}, msg) == permissionAllowed
+}
+
+// applyNextChange writes one accepted file, snapshotting it first, then
+// moves on to the next so the review can show progress
+func (m *Model) applyNextChange(index int) tea.Cmd {
+	review := &m.changesReview
+	// A new batch replaces the previous rollback point
+	if done, _ := review.progress(); done == 0 {
+		m.changeSnapshots = nil
+	}
+	file := &review.files[index]
+	if err := m.fileTree.FileSystem().WriteFile(file.Path, []byte(file.Updated)); err != nil {
+		file.State = changeFailed
+		file.Err = err.Error()
+	} else {
+		file.State = changeApplied
+		m.recordAppliedChange(file.Path, file.Original, file.Updated, file.Existed)
+		m.changeSnapshots = append(m.changeSnapshots, fileSnapshot{Path: file.Path, Content: file.Original, Existed: file.Existed})
+		m.refreshAppliedFile(file.Path)
+	}
+
+	if next := review.nextAccepted(index + 1); next >= 0 {
+		return func() tea.Msg { return ApplyNextChangeMsg{Index: next} }
+	}
+	review.applying = false
+	review.applied = true
+	done, failed := 0, 0
+	for _, f := range review.files {
+		switch f.State {
+		case changeApplied:
+			done++
+		case changeFailed:
+			failed++
+		}
+	}
+	m.statusBar = fmt.Sprintf("Applied changes to %d files (%d failed) - /rollback undoes them", done, failed)
+	return m.fileTree.Refresh()
+}
+
+// refreshAppliedFile reloads the editor when a written file is open, or
+// flags it when the buffer has unsaved edits
+func (m *Model) refreshAppliedFile(path string) {
+	if m.currentFile == "" || !samePath(path, m.currentFile) {
+		return
+	}
+	if m.editor.Value() == m.editorOriginal {
+		*m, _ = m.reloadCurrentFile()
+		return
+	}
+	m.fileChangedOnDisk = true
+}
+
+// rollbackChanges restores the files written by the last applied review,
+// returning the command that lists the server's tree again
+func (m *Model) rollbackChanges() tea.Cmd {
+	if len(m.changeSnapshots) == 0 {
+		m.statusMessages.AddMessage(StatusCategoryError, "No applied changes to roll back", nil)
+		return nil
+	}
+	fs := m.fileTree.FileSystem()
+	var failed []string
+	// Restore in reverse so a file written twice ends at its first snapshot
+	for i := len(m.changeSnapshots) - 1; i >= 0; i-- {
+		snapshot := m.changeSnapshots[i]
+		var err error
+		switch {
+		case snapshot.Existed:
+			err = fs.WriteFile(snapshot.Path, []byte(snapshot.Content))
+		case fs.Name() == FileSourceLocal:
+			err = os.Remove(snapshot.Path)
+		default:
+			err = fmt.Errorf("created on the server; delete it there")
+		}
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", snapshot.Path, err))
+			continue
+		}
+		m.forgetAppliedChange(snapshot.Path)
+		for j := range m.changesReview.files {
+			if samePath(m.changesReview.files[j].Path, snapshot.Path) {
+				m.changesReview.files[j].State = changeRolledBack
+			}
+		}
+		m.refreshAppliedFile(snapshot.Path)
+	}
+	refresh := m.fileTree.Refresh()
+	restored := len(m.changeSnapshots) - len(failed)
+	m.changeSnapshots = nil
+	if len(failed) > 0 {
+		m.statusMessages.AddMessage(StatusCategoryError, "Rollback incomplete:\n"+strings.Join(failed, "\n"), nil)
+	}
+	m.statusBar = fmt.Sprintf("Rolled back %d files", restored)
+	return refresh
+}
@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/phoenix"
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+func TestReviewAppliesAndRollsBackChanges(t *testing.T) {
+	testutil.IsolateHome(t)
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "a.go")
+	created := filepath.Join(dir, "pkg", "b.go")
+	skipped := filepath.Join(dir, "c.go")
+	os.WriteFile(existing, []byte("old a"), 0644)
+	os.WriteFile(skipped, []byte("old c"), 0644)
+
+	var model Model = *NewModel()
+	model.sessionPermissions[permissionWriteFiles] = permissionAllow
+	model.offerChanges([]phoenix.FileChange{
+		{Path: existing, Content: "new a"},
+		{Path: created, Content: "new b"},
+		{Path: skipped, Content: "new c"},
+	})
+	model.previewChanges()
+	if !model.changesReview.IsVisible() || len(model.changesReview.files) != 3 {
+		t.Fatal("Expected a multi-file refactor to open the review")
+	}
+
+	// Reject the third file, then apply
+	press := func(key tea.KeyMsg) {
+		var cmd tea.Cmd
+		model.changesReview, cmd = model.changesReview.Update(key)
+		for cmd != nil {
+			msg := cmd()
+			updated, next := model.Update(msg)
+			model = updated.(Model)
+			cmd = next
+		}
+	}
+	press(tea.KeyMsg{Type: tea.KeyDown})
+	press(tea.KeyMsg{Type: tea.KeyDown})
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+
+	read := func(path string) string {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "<missing>"
+		}
+		return string(data)
+	}
+	if read(existing) != "new a" || read(created) != "new b" || read(skipped) != "old c" {
+		t.Fatalf("Unexpected files after applying: %q %q %q", read(existing), read(created), read(skipped))
+	}
+	if done, total := model.changesReview.progress(); done != 2 || total != 2 {
+		t.Fatalf("Expected 2/2 files applied, got %d/%d", done, total)
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	if read(existing) != "old a" || read(created) != "<missing>" {
+		t.Fatalf("Expected rollback to restore the files, got %q %q", read(existing), read(created))
+	}
+}
@@ -10,6 +10,8 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/rubber_duck/tui/internal/clock"
+	"github.com/rubber_duck/tui/internal/phoenix"
 )
 
 // MessageType represents different types of chat messages
@@ -24,10 +26,21 @@ const (
 
 // ChatMessage represents a single message in the chat
 type ChatMessage struct {
+	ID        string // Server message ID, empty for local messages
 	Type      MessageType
 	Content   string
 	Author    string
 	Timestamp time.Time
+	Pinned    bool
+	Full      string                    `json:",omitempty"` // Untruncated content when a response filter cut it off
+	Expanded  bool                      `json:"-"`          // Shown in full despite its length
+	Previous  string                    `json:",omitempty"` // The answer this one regenerated
+	ShowDiff  bool                      `json:"-"`          // Show the changes from Previous
+	Trace     []phoenix.ToolStep        `json:",omitempty"` // Tool calls made while answering
+	TraceOpen bool                      `json:"-"`          // Show each tool call of the trace
+	Sources   []phoenix.RetrievedSource `json:",omitempty"` // Project files retrieved as context for the answer
+	Private   bool                      `json:"-"`          // Added in incognito mode; never written to disk
+	ReplyTo   string                    `json:",omitempty"` // Reference of the message this one replies to
 }
 
 // Chat represents the chat component
@@ -39,6 +52,55 @@ type Chat struct {
 	height   int
 	focused  bool
 	renderer *glamour.TermRenderer
+	
+	// pendingPaste holds code-like pasted text awaiting the fence prompt
+	pendingPaste string
+	// pendingTrace holds a pasted stack trace awaiting the attach prompt
+	pendingTrace *pendingTrace
+	// hints labels links in the visible history while picking one
+	hints *hintMode
+	// copyMenu asks which format to copy the selected message in
+	copyMenu bool
+	// reply is the message the next send replies to
+	reply *replyTarget
+	
+	// Input diagnostics
+	spellChecker *SpellChecker
+	lintEnabled  bool
+	blocked      []string // Slash commands refused by blocked_commands
+	
+	// Message selection and pins
+	selecting     bool
+	selected      int
+	sourceCursor  int   // Source of the selected answer Enter opens
+	messageLines  []int // First viewport line of each message
+	dayLines      []int // Viewport lines of the date separators
+	pinsCollapsed bool
+	
+	// Messages taller than this render collapsed
+	collapseLines int
+	
+	// Bytes of the newest messages rendered in full, and the renderings kept
+	transcriptBudget int
+	renderCache      renderCache
+	renderedBytes    int // Size of the last rendered transcript
+	
+	// Older history pages on the server
+	hasOlder     bool
+	loadingOlder bool
+	
+	// Oldest messages of long conversations, compressed on disk
+	spill historySpill
+	
+	// New messages are private while set
+	incognito bool
+	
+	// The conversation's prompt prefix and suffix, for the badge
+	promptPrefix, promptSuffix string
+	
+	// Timestamp, compact, and glyph settings
+	display        chatDisplay
+	relativeMinute time.Time // Minute relative timestamps were last drawn
 }
 
 // NewChat creates a new chat component
@@ -88,6 +150,28 @@ func (c Chat) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if c.focused {
 		switch msg := msg.(type) {
 		case tea.KeyMsg:
+			// Resolve the "wrap in code fence?" prompt before anything else
+			if c.pendingPaste != "" {
+				return c.resolvePendingPaste(msg)
+			}
+			if c.pendingTrace != nil {
+				return c.resolvePendingTrace(msg)
+			}
+			
+			// Alt+Up selects messages for pinning
+			if c.selecting {
+				return c.updateSelection(msg)
+			}
+			if msg.String() == "alt+up" {
+				c.startSelection()
+				return c, nil
+			}
+			
+			// Bracketed paste arrives as a single key message with all runes
+			if msg.Paste {
+				return c.handlePaste(string(msg.Runes))
+			}
+			
 			switch msg.Type {
 			case tea.KeyEnter:
 				// Send message if we have content
@@ -133,10 +217,25 @@ func (c Chat) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	// Update input if focused
 	if c.focused {
+		before := c.input.Value()
 		c.input, inputCmd = c.input.Update(msg)
 		cmds = append(cmds, inputCmd)
+		c.dropReplyIfCleared(before)
 	}
 
+	// Keys and mouse input only scroll the history while the chat has focus
+	if !c.focused && isInputMsg(msg) {
+		return c, tea.Batch(cmds...)
+	}
+
+	// Scrolling up past the top reads back spilled messages, then pages in
+	// older history from the server
+	if c.wantsOlderHistory(msg) && !c.rehydrate() && c.hasOlder {
+		c.loadingOlder = true
+		c.viewport.SetContent(c.buildViewportContent())
+		cmds = append(cmds, func() tea.Msg { return LoadOlderHistoryMsg{} })
+	}
+	
 	// Update viewport
 	c.viewport, vpCmd = c.viewport.Update(msg)
 	cmds = append(cmds, vpCmd)
@@ -147,9 +246,7 @@ func (c Chat) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 // View renders the chat component
 func (c Chat) View() string {
 	// Add a title/label at the top
-	titleStyle := lipgloss.NewStyle().
-		Bold(true).
-		Foreground(lipgloss.Color("62")).
+	titleStyle := paneTitleStyle(c.focused).
 		Width(c.width).
 		Align(lipgloss.Center).
 		MarginBottom(1)
@@ -165,32 +262,197 @@ func (c Chat) View() string {
 		BorderForeground(lipgloss.Color("240")).
 		Render("")
 	
-	content := lipgloss.JoinVertical(
-		lipgloss.Left,
-		title,
-		c.viewport.View(),
+	// Show the paste prompt in place of the separator
+	if c.pendingPaste != "" {
+		separator = lipgloss.NewStyle().
+			Width(c.width-2).
+			Foreground(lipgloss.Color("220")).
+			Bold(true).
+			Render(pasteSummary(c.pendingPaste) + " - wrap in code fence? (y/n, Esc to discard)")
+	} else if c.pendingTrace != nil {
+		separator = lipgloss.NewStyle().
+			Width(c.width-2).
+			MaxHeight(1).
+			Render(c.renderTracePrompt())
+	} else if diagnostics := c.renderInputDiagnostics(); diagnostics != "" {
+		// Spelling and lint findings also replace the separator
+		separator = lipgloss.NewStyle().
+			Width(c.width-2).
+			MaxHeight(1).
+			Render(diagnostics)
+	} else if reply := c.renderReplyBadge(); reply != "" {
+		// As does the reminder that the next send is a reply
+		separator = lipgloss.NewStyle().
+			Width(c.width-2).
+			MaxHeight(1).
+			Render(reply)
+	} else if affixes := c.renderPromptAffixes(); affixes != "" {
+		// So does the reminder of what each message gets added
+		separator = lipgloss.NewStyle().
+			Width(c.width-2).
+			MaxHeight(1).
+			Render(affixes)
+	}
+	
+	if c.selecting {
+		hint := "↑/↓: Select message | p: Pin/unpin | c: Copy | r: Reply | o: Expand/collapse | d: Diff | Esc: Done"
+		if len(c.selectedSources()) > 0 {
+			hint = "↑/↓: Select message | p: Pin/unpin | c: Copy | r: Reply | s: Next source | Enter: Open source | Esc: Done"
+		}
+		if c.copyMenu {
+			hint = copyMenuHint()
+		}
+		separator = lipgloss.NewStyle().
+			Width(c.width-2).
+			Foreground(lipgloss.Color("214")).
+			Render(hint)
+	}
+	
+	// Pinned answers sit between the title and the history
+	sections := []string{title}
+	if panel := c.renderPinnedPanel(); panel != "" {
+		sections = append(sections, panel)
+	}
+	sections = append(sections,
+		lipgloss.JoinHorizontal(lipgloss.Top, c.viewportView(), c.renderScrollbar()),
 		separator,
 		lipgloss.NewStyle().
 			Width(c.width-2).
 			Render(c.input.View()),
 	)
+	content := lipgloss.JoinVertical(lipgloss.Left, sections...)
 
 	return content
 }
 
+// SetSpellChecker enables spellchecking of the input (nil disables it)
+func (c *Chat) SetSpellChecker(checker *SpellChecker) {
+	c.spellChecker = checker
+}
+
+// SetBlockedCommands sets the slash commands to refuse
+func (c *Chat) SetBlockedCommands(commands []string) {
+	c.blocked = commands
+}
+
+// SetLintEnabled enables or disables prompt linting
+func (c *Chat) SetLintEnabled(enabled bool) {
+	c.lintEnabled = enabled
+}
+
+// renderInputDiagnostics renders misspelled words and lint issues for the draft
+func (c Chat) renderInputDiagnostics() string {
+	draft := c.input.Value()
+	if strings.TrimSpace(draft) == "" || strings.HasPrefix(strings.TrimSpace(draft), "/") {
+		return ""
+	}
+	
+	var parts []string
+	
+	if c.lintEnabled {
+		warningStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("220"))
+		hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Italic(true)
+		for _, issue := range LintPrompt(draft) {
+			if issue.Severity == LintWarning {
+				parts = append(parts, warningStyle.Render("⚠ "+issue.Message))
+			} else {
+				parts = append(parts, hintStyle.Render("💡 "+issue.Message))
+			}
+		}
+	}
+	
+	if misspelled := c.spellChecker.Misspelled(draft); len(misspelled) > 0 {
+		wordStyle := lipgloss.NewStyle().
+			Foreground(lipgloss.Color("196")).
+			Underline(true)
+		var words []string
+		for _, word := range misspelled {
+			words = append(words, wordStyle.Render(word))
+		}
+		parts = append(parts, "Spelling: "+strings.Join(words, " "))
+	}
+	
+	return strings.Join(parts, "  ")
+}
+
+// handlePaste inserts pasted text as a single draft so embedded newlines
+// never trigger a send
+func (c Chat) handlePaste(text string) (tea.Model, tea.Cmd) {
+	text = normalizePaste(text)
+	if text == "" {
+		return c, nil
+	}
+	
+	// A dragged-in file arrives as its path, which is checked on disk first
+	if path := droppedFilePath(text); path != "" {
+		return c, checkDroppedFile(text, path)
+	}
+	return c.insertPaste(text)
+}
+
+// handleCheckedPaste inserts a paste that named a path, as the file when
+// it is one
+func (c Chat) handleCheckedPaste(msg PasteCheckedMsg) (tea.Model, tea.Cmd) {
+	if msg.File != "" {
+		c.input.InsertString(fmt.Sprintf("`%s`", msg.File))
+		return c, nil
+	}
+	return c.insertPaste(msg.Text)
+}
+
+// insertPaste inserts pasted text, offering stack trace files or a code
+// fence first
+func (c Chat) insertPaste(text string) (tea.Model, tea.Cmd) {
+	// Stack traces offer the files they pass through as context, which
+	// needs the project's files to find them
+	if looksLikeStackTrace(text) {
+		return c, func() tea.Msg { return StackTracePastedMsg{Text: text} }
+	}
+	
+	// Ask before fencing code so prose pastes aren't wrapped by accident
+	if looksLikeCode(text) {
+		c.pendingPaste = text
+		return c, nil
+	}
+	
+	c.input.InsertString(text)
+	return c, nil
+}
+
+// resolvePendingPaste handles the answer to the code fence prompt
+func (c Chat) resolvePendingPaste(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y":
+		c.input.InsertString(wrapInCodeFence(c.pendingPaste))
+	case "n", "N", "enter":
+		c.input.InsertString(c.pendingPaste)
+	case "esc":
+		// Discard the paste entirely
+	default:
+		// Keep waiting for an answer
+		return c, nil
+	}
+	c.pendingPaste = ""
+	return c, nil
+}
+
+// HasPendingPaste returns whether a paste is waiting for the fence prompt
+func (c *Chat) HasPendingPaste() bool {
+	return c.pendingPaste != "" || c.pendingTrace != nil
+}
+
 // SetSize updates the chat component dimensions
 func (c *Chat) SetSize(width, height int) {
-	c.width = width
-	c.height = height
-	// Update viewport size (leaving room for input and title)
-	c.viewport.Width = width
-	c.viewport.Height = height - 7 // Leave room for input area and title
-	c.input.SetWidth(width)
-	
 	// Clear renderer to force recreation with new width
 	if c.renderer != nil && c.width != width {
 		c.renderer = nil
 	}
+	c.width = width
+	c.height = height
+	// Update viewport size (leaving room for input and title)
+	c.viewport.Width = width - scrollbarWidth
+	c.layout()
+	c.input.SetWidth(width)
 }
 
 // Focus sets the focus state
@@ -207,13 +469,20 @@ func (c *Chat) Blur() {
 
 // AddMessage adds a message to the chat history
 func (c *Chat) AddMessage(msgType MessageType, content, author string) {
+	c.AddMessageAt(msgType, content, author, clock.Now())
+}
+
+// AddMessageAt adds a message sent at a given time, such as one from history
+func (c *Chat) AddMessageAt(msgType MessageType, content, author string, at time.Time) {
 	msg := ChatMessage{
 		Type:      msgType,
 		Content:   content,
 		Author:    author,
-		Timestamp: time.Now(),
+		Timestamp: at,
+		Private:   c.incognito,
 	}
 	c.messages = append(c.messages, msg)
+	c.compact()
 	
 	// Update viewport content
 	c.viewport.SetContent(c.buildViewportContent())
@@ -222,31 +491,69 @@ func (c *Chat) AddMessage(msgType MessageType, content, author string) {
 	c.viewport.GotoBottom()
 }
 
-// GetMessages returns all messages
+// UpdateLastMessage replaces the content of the most recent message (used while streaming)
+func (c *Chat) UpdateLastMessage(content string) {
+	if len(c.messages) == 0 {
+		return
+	}
+	c.messages[len(c.messages)-1].Content = content
+	c.viewport.SetContent(c.buildViewportContent())
+	c.viewport.GotoBottom()
+}
+
+// GetInputValue returns the current input draft
+func (c *Chat) GetInputValue() string {
+	return c.input.Value()
+}
+
+// SetInputValue replaces the input draft
+func (c *Chat) SetInputValue(value string) {
+	c.input.SetValue(value)
+}
+
+// GetMessages returns all messages, reading back any spilled to disk
 func (c *Chat) GetMessages() []ChatMessage {
-	return c.messages
+	if len(c.spill.segments) == 0 {
+		return c.messages
+	}
+	return append(c.spill.all(), c.messages...)
 }
 
 // ClearMessages clears all messages from the chat
 func (c *Chat) ClearMessages() {
 	c.messages = []ChatMessage{}
+	c.spill.discard()
 	c.viewport.SetContent(c.buildViewportContent())
 	c.viewport.GotoTop()
 }
 
 // GetMessageCount returns the number of messages
 func (c *Chat) GetMessageCount() int {
-	return len(c.messages)
+	return c.spill.count() + len(c.messages)
+}
+
+// takeMessagesSince removes and returns the messages added after the chat
+// held count of them
+func (c *Chat) takeMessagesSince(count int) []ChatMessage {
+	added := min(c.GetMessageCount()-count, len(c.messages))
+	if added <= 0 {
+		return nil
+	}
+	taken := append([]ChatMessage(nil), c.messages[len(c.messages)-added:]...)
+	c.messages = c.messages[:len(c.messages)-added]
+	c.viewport.SetContent(c.buildViewportContent())
+	return taken
 }
 
 // GetAllMessagesPlainText returns all messages as plain text for copying
 func (c *Chat) GetAllMessagesPlainText() string {
-	if len(c.messages) == 0 {
+	messages := c.GetMessages()
+	if len(messages) == 0 {
 		return ""
 	}
 	
 	var content strings.Builder
-	for i, msg := range c.messages {
+	for i, msg := range messages {
 		if i > 0 {
 			content.WriteString("\n\n")
 		}
@@ -327,6 +634,10 @@ func (c *Chat) buildViewportContent() string {
 		
 	timeStyle := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("240"))
+		
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("214")).
+		Bold(true)
 	
 	// Message content style with word wrapping
 	// Account for viewport width minus some padding
@@ -341,13 +652,37 @@ func (c *Chat) buildViewportContent() string {
 	messageStyle := lipgloss.NewStyle().
 		Width(wrapWidth)
 
+	if c.hasOlder || c.spill.count() > 0 || c.spill.dropped > 0 {
+		content.WriteString(c.renderHistoryTop())
+		content.WriteString("\n\n")
+	}
+	
+	c.messageLines = c.messageLines[:0]
+	c.dayLines = c.dayLines[:0]
+	c.renderCache.begin()
+	defer func() {
+		c.renderCache.end()
+		c.renderedBytes = content.Len()
+	}()
+	trimBefore := c.trimStart()
+	now := clock.Now()
 	for i, msg := range c.messages {
-		if i > 0 {
+		compact := c.compactWith(i)
+		if compact {
+			content.WriteString("\n")
+		} else if i > 0 {
 			content.WriteString("\n\n")
+			// Mark where a new day starts
+			if !sameDay(c.messages[i-1].Timestamp, msg.Timestamp) {
+				c.dayLines = append(c.dayLines, strings.Count(content.String(), "\n"))
+				content.WriteString(renderDateSeparator(msg.Timestamp, wrapWidth))
+				content.WriteString("\n\n")
+			}
 		}
+		c.messageLines = append(c.messageLines, strings.Count(content.String(), "\n"))
 		
 		// Format timestamp
-		timestamp := msg.Timestamp.Format("15:04:05")
+		timestamp := c.display.formatMessageTime(msg.Timestamp, now)
 		
 		// Format author and message based on type
 		var authorStyle lipgloss.Style
@@ -368,13 +703,38 @@ func (c *Chat) buildViewportContent() string {
 			prefix = "Error"
 		}
 		
-		// Build message header
-		header := fmt.Sprintf("%s %s", 
-			authorStyle.Render(prefix),
-			timeStyle.Render(timestamp))
+		if glyph := c.display.Glyphs[roleName(msg.Type)]; glyph != "" {
+			prefix = glyph + " " + prefix
+		}
+		
+		// Build message header, left out in compact runs
+		header := authorStyle.Render(prefix)
+		if timestamp != "" {
+			header += " " + timeStyle.Render(timestamp)
+		}
+		if msg.Pinned {
+			header += " 📌"
+		}
+		// Replies are indented under a note of what they answer
+		bodyStyle := messageStyle
+		if msg.ReplyTo != "" {
+			header = strings.Repeat(" ", replyIndent) + header + " " + timeStyle.Render(c.replyLabel(msg.ReplyTo))
+			bodyStyle = messageStyle.PaddingLeft(replyIndent)
+		}
+		if c.selecting && i == c.selected {
+			header = selectedStyle.Render("▶ ") + header
+		}
 		
-		content.WriteString(header)
-		content.WriteString("\n")
+		if !compact {
+			content.WriteString(header)
+			content.WriteString("\n")
+		}
+		
+		// Messages beyond the transcript budget render as one line
+		if i < trimBefore && !msg.Expanded {
+			content.WriteString(c.renderTrimmed(i, wrapWidth))
+			continue
+		}
 		
 		// Render message content
 		var renderedContent string
@@ -384,25 +744,36 @@ func (c *Chat) buildViewportContent() string {
 			// Ensure renderer is initialized
 			c.ensureRenderer()
 			if c.renderer != nil {
-				// Try to render as markdown
-				markdownContent, err := c.renderer.Render(msg.Content)
-				if err == nil {
+				// Try to render as markdown, reusing the last rendering
+				renderedContent = c.renderCache.render(msg.Content, c.width, func(source string) string {
+					markdownContent, err := c.renderer.Render(source)
+					if err != nil {
+						// Fallback to plain text with wrapping
+						return messageStyle.Render(source)
+					}
 					// Remove trailing newlines from glamour output
-					renderedContent = strings.TrimRight(markdownContent, "\n")
-				} else {
-					// Fallback to plain text with wrapping
-					renderedContent = messageStyle.Render(msg.Content)
-				}
+					return strings.TrimRight(markdownContent, "\n")
+				})
 			} else {
 				// No renderer available, use plain text
 				renderedContent = messageStyle.Render(msg.Content)
 			}
 		} else {
 			// For user, system, and error messages, use plain text with wrapping
-			renderedContent = messageStyle.Render(msg.Content)
+			renderedContent = bodyStyle.Render(msg.Content)
+		}
+		
+		if msg.ShowDiff && msg.Previous != "" {
+			renderedContent = messageStyle.Render(renderAnswerDiff(msg.Previous, msg.Content))
 		}
 		
-		content.WriteString(renderedContent)
+		content.WriteString(c.collapseRendered(i, renderedContent))
+		if len(msg.Trace) > 0 {
+			content.WriteString("\n" + c.renderTrace(i))
+		}
+		if len(msg.Sources) > 0 {
+			content.WriteString("\n" + c.renderSources(i))
+		}
 	}
 	
 	return content.String()
@@ -410,6 +781,12 @@ func (c *Chat) buildViewportContent() string {
 
 // handleSlashCommand processes slash commands
 func (c Chat) handleSlashCommand(command string) tea.Cmd {
+	command, format := splitFormatFlag(command)
+	return withFormat(c.runSlashCommand(command), format)
+}
+
+// runSlashCommand turns a slash command into the command it runs
+func (c Chat) runSlashCommand(command string) tea.Cmd {
 	// Remove the leading slash and convert to lowercase
 	cmd := strings.ToLower(strings.TrimPrefix(command, "/"))
 	parts := strings.Fields(cmd)
@@ -417,18 +794,43 @@ func (c Chat) handleSlashCommand(command string) tea.Cmd {
 	if len(parts) == 0 {
 		return nil
 	}
+	if commandBlocked(c.blocked, parts[0]) {
+		return func() tea.Msg { return CommandBlockedMsg{Command: parts[0]} }
+	}
 	
 	// Handle different slash commands
 	switch parts[0] {
 	case "help", "h", "?":
+		// /help <command> opens that command's page
+		var args map[string]string
+		if len(parts) > 1 && parts[1] == "errors" {
+			// /help errors [code] explains the error catalog
+			code := ""
+			if len(parts) > 2 {
+				code = parts[2]
+			}
+			args = map[string]string{"errors": code}
+		} else if len(parts) > 1 {
+			args = map[string]string{"command": parts[1]}
+		}
 		return func() tea.Msg {
-			return ExecuteCommandMsg{Command: "help"}
+			return ExecuteCommandMsg{Command: "help", Args: args}
 		}
 		
 	case "model", "m":
-		if len(parts) > 1 {
+		// --global changes the default instead of this conversation
+		scope := "conversation"
+		var rest []string
+		for _, part := range parts[1:] {
+			if part == "--global" || part == "-g" {
+				scope = "global"
+			} else {
+				rest = append(rest, part)
+			}
+		}
+		if len(rest) > 0 {
 			// Handle model selection with optional provider
-			modelName := strings.Join(parts[1:], " ")
+			modelName := strings.Join(rest, " ")
 			
 			// Check if provider is included (format: "model provider")
 			modelParts := strings.Fields(modelName)
@@ -442,6 +844,7 @@ func (c Chat) handleSlashCommand(command string) tea.Cmd {
 						Args: map[string]string{
 							"model":    model,
 							"provider": provider,
+							"scope":    scope,
 						},
 					}
 				}
@@ -452,14 +855,33 @@ func (c Chat) handleSlashCommand(command string) tea.Cmd {
 						Command: "set_model",
 						Args: map[string]string{
 							"model": modelName,
+							"scope": scope,
 						},
 					}
 				}
 			}
 		} else {
 			// Show usage - add to chat
-			c.AddMessage(SystemMessage, "Usage: /model <name> [provider]\nExample: /model gpt-4\nExample: /model claude-3-opus anthropic", "system")
+			c.AddMessage(SystemMessage, "Usage: /model <name> [provider] [--global]\nSets the model of this conversation; --global changes the default\nExample: /model gpt-4\nExample: /model claude-3-opus anthropic\nExample: /model --global default", "system")
+		}
+		
+	case "models":
+		return func() tea.Msg { return ExecuteCommandMsg{Command: "model_switcher"} }
+		
+	case "temperature", "temp":
+		args := map[string]string{"scope": "conversation"}
+		for _, part := range parts[1:] {
+			if part == "--global" || part == "-g" {
+				args["scope"] = "global"
+			} else {
+				args["temperature"] = part
+			}
+		}
+		if args["temperature"] == "" {
+			c.AddMessage(SystemMessage, "Usage: /temperature <0-2|default> [--global]\nSets the temperature of this conversation; --global changes the default", "system")
+			break
 		}
+		return func() tea.Msg { return ExecuteCommandMsg{Command: "set_temperature", Args: args} }
 		
 	case "provider", "p":
 		if len(parts) > 1 {
@@ -480,6 +902,106 @@ func (c Chat) handleSlashCommand(command string) tea.Cmd {
 			return ExecuteCommandMsg{Command: "new_conversation"}
 		}
 		
+	case "tutorial", "tour":
+		command := "tutorial"
+		if len(parts) > 1 {
+			switch parts[1] {
+			case "next", "skip":
+				command = "tutorial_next"
+			case "quit", "stop", "end":
+				command = "tutorial_quit"
+			default:
+				c.AddMessage(SystemMessage, "Usage: /tutorial [next|quit]", "system")
+				return nil
+			}
+		}
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: command}
+		}
+		
+	case "undo-clear", "undo":
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: "undo_clear"}
+		}
+		
+	case "send-unredacted":
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: "send_unredacted"}
+		}
+		
+	case "show-more":
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: "show_more"}
+		}
+		
+	case "apply":
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: "apply_changes"}
+		}
+		
+	case "rollback":
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: "rollback_changes"}
+		}
+		
+	case "react":
+		// /react shows the live view; pause, abort, and export control it
+		command := "react_view"
+		if len(parts) > 1 {
+			switch parts[1] {
+			case "pause", "resume":
+				command = "react_pause"
+			case "abort":
+				command = "react_abort"
+			case "export":
+				command = "react_export"
+			}
+		}
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: command}
+		}
+		
+	case "patch":
+		// /patch [file] exports a diff; /patch pr [title] opens a pull request
+		if len(parts) > 1 && parts[1] == "pr" {
+			title := strings.TrimSpace(strings.Join(parts[2:], " "))
+			return func() tea.Msg {
+				return ExecuteCommandMsg{Command: "create_pull_request", Args: map[string]string{"title": title}}
+			}
+		}
+		name := ""
+		if len(parts) > 1 {
+			name = parts[1]
+		}
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: "export_patch", Args: map[string]string{"file": name}}
+		}
+		
+	case "save":
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: "save_file"}
+		}
+		
+	case "format", "fmt":
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: "format_file"}
+		}
+		
+	case "regenerate", "regen":
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: "regenerate"}
+		}
+		
+	case "changes":
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: "toggle_diff"}
+		}
+		
+	case "trace":
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: "toggle_trace"}
+		}
+		
 	case "tree", "files":
 		// Toggle file tree
 		return func() tea.Msg {
@@ -492,6 +1014,57 @@ func (c Chat) handleSlashCommand(command string) tea.Cmd {
 			return ExecuteCommandMsg{Command: "toggle_editor"}
 		}
 		
+	case "compose":
+		// Open the multi-line compose modal
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: "compose"}
+		}
+		
+	case "attach":
+		// Attach a file to the next message; the path keeps its original case
+		fields := strings.Fields(strings.TrimPrefix(command, "/"))
+		if len(fields) < 2 {
+			c.AddMessage(SystemMessage, "Usage: /attach <file>", "system")
+			return nil
+		}
+		path := strings.Join(fields[1:], " ")
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: "attach", Args: map[string]string{"path": path}}
+		}
+		
+	case "outline", "symbols":
+		// Toggle the symbol outline
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: "toggle_outline"}
+		}
+		
+	case "project":
+		// Show the project, or open another; the path keeps its original case
+		if len(parts) > 1 && parts[1] == "open" {
+			fields := strings.Fields(strings.TrimPrefix(command, "/"))
+			if len(fields) < 3 {
+				c.AddMessage(SystemMessage, "Usage: /project open <dir>", "system")
+				return nil
+			}
+			path := strings.Join(fields[2:], " ")
+			return func() tea.Msg {
+				return ExecuteCommandMsg{Command: "project_open", Args: map[string]string{"path": path}}
+			}
+		}
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: "project_info"}
+		}
+		
+	case "search", "grep":
+		// Search the project; the query keeps its original case
+		query := ""
+		if fields := strings.SplitN(strings.TrimSpace(strings.TrimPrefix(command, "/")), " ", 2); len(fields) == 2 {
+			query = strings.TrimSpace(fields[1])
+		}
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: "search", Args: map[string]string{"query": query}}
+		}
+		
 	case "commands", "cmds", "palette":
 		// Show command palette
 		return func() tea.Msg {
@@ -510,9 +1083,239 @@ func (c Chat) handleSlashCommand(command string) tea.Cmd {
 				}
 			}
 		} else {
-			c.AddMessage(SystemMessage, "Usage: /login <username> <password>", "system")
+			// Without a password on the command line, ask for it in the login form
+			username := ""
+			if len(parts) == 2 {
+				username = parts[1]
+			}
+			return func() tea.Msg {
+				return ExecuteCommandMsg{Command: "login_form", Args: map[string]string{"username": username}}
+			}
+		}
+		
+	case "metrics":
+		// Show where metrics are served and exported
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: "metrics"}
+		}
+		
+	case "incognito", "private":
+		// /incognito toggles; on or off sets it
+		var args map[string]string
+		if len(parts) > 1 {
+			args = map[string]string{"state": parts[1]}
+		}
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: "incognito", Args: args}
 		}
 		
+	case "lock":
+		// /lock pin [off] sets or removes the PIN; /lock locks now
+		command := "lock"
+		if len(parts) > 1 && parts[1] == "pin" {
+			command = "lock_pin"
+			if len(parts) > 2 && parts[2] == "off" {
+				command = "lock_pin_off"
+			}
+		}
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: command}
+		}
+		
+	case "permissions":
+		// /permissions lists remembered answers; reset forgets them
+		command := "permissions"
+		var args map[string]string
+		if len(parts) > 1 && parts[1] == "reset" {
+			command = "permissions_reset"
+			if len(parts) > 2 {
+				args = map[string]string{"action": parts[2]}
+			}
+		}
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: command, Args: args}
+		}
+		
+	case "trust":
+		// /trust lists known servers; accept trusts a changed one
+		command := "trust"
+		var args map[string]string
+		if len(parts) > 1 {
+			switch parts[1] {
+			case "accept":
+				command = "trust_accept"
+			case "forget":
+				if len(parts) < 3 {
+					c.AddMessage(SystemMessage, "Usage: /trust forget <host>", "system")
+					return nil
+				}
+				command = "trust_forget"
+				args = map[string]string{"host": parts[2]}
+			}
+		}
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: command, Args: args}
+		}
+		
+	case "emoji", "symbol":
+		// /emoji [search] opens the picker, searching when given words
+		query := strings.TrimSpace(strings.Join(parts[1:], " "))
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: "emoji", Args: map[string]string{"query": query}}
+		}
+		
+	case "macro", "macros":
+		// /macro lists macros; save, load, play, and delete manage them
+		command := "macro"
+		args := map[string]string{}
+		if len(parts) > 1 {
+			switch {
+			case parts[1] == "save" && len(parts) == 4:
+				command, args["register"], args["name"] = "macro_save", parts[2], parts[3]
+			case parts[1] == "load" && (len(parts) == 3 || len(parts) == 4):
+				command, args["name"], args["register"] = "macro_load", parts[2], "a"
+				if len(parts) == 4 {
+					args["register"] = parts[3]
+				}
+			case parts[1] == "play" && len(parts) == 3:
+				command, args["name"] = "macro_play", parts[2]
+			case parts[1] == "delete" && len(parts) == 3:
+				command, args["name"] = "macro_delete", parts[2]
+			default:
+				c.AddMessage(SystemMessage, "Usage: /macro [save <register> <name> | load <name> [register] | play <name|register> | delete <name>]", "system")
+				return nil
+			}
+		}
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: command, Args: args}
+		}
+		
+	case "links":
+		// Label the links in view to open or copy one
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: "link_hints"}
+		}
+		
+	case "paths":
+		// Label the file paths in view to open one in the editor
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: "path_hints"}
+		}
+		
+	case "prefix":
+		// /prefix [suffix] [text|off|edit] shows or sets what each message gets
+		name, args := "prefix", map[string]string{"which": "prefix"}
+		rest := parts[1:]
+		if len(rest) > 0 && rest[0] == "suffix" {
+			args["which"], rest = "suffix", rest[1:]
+		}
+		switch {
+		case len(rest) == 1 && rest[0] == "edit":
+			name = "prefix_edit"
+		case len(rest) > 0:
+			name = "prefix_set"
+			// Keep the text's case, spacing, and lines
+			text := strings.TrimSpace(command)
+			text = strings.TrimSpace(text[strings.IndexAny(text, " \t\n"):])
+			if args["which"] == "suffix" {
+				text = strings.TrimSpace(text[len("suffix"):])
+			}
+			args["text"] = text
+		}
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: name, Args: args}
+		}
+		
+	case "encrypt":
+		// /encrypt shows the state; /encrypt migrate encrypts old files
+		command := "encrypt"
+		if len(parts) > 1 && parts[1] == "migrate" {
+			command = "encrypt_migrate"
+		}
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: command}
+		}
+		
+	case "memory", "mem":
+		// Estimate what each component holds in memory
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: "memory"}
+		}
+		
+	case "audit":
+		// Show the latest audit log entries
+		var args map[string]string
+		if len(parts) > 1 {
+			args = map[string]string{"count": parts[1]}
+		}
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: "audit", Args: args}
+		}
+		
+	case "cache":
+		// Show or clear cached answers to server queries
+		if len(parts) > 1 {
+			if parts[1] != "clear" || len(parts) > 3 {
+				c.AddMessage(SystemMessage, "Usage: /cache [clear [event]]", "system")
+				return nil
+			}
+			var args map[string]string
+			if len(parts) == 3 {
+				args = map[string]string{"event": parts[2]}
+			}
+			return func() tea.Msg {
+				return ExecuteCommandMsg{Command: "cache_clear", Args: args}
+			}
+		}
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: "cache"}
+		}
+		
+	case "queue":
+		// Show the command queue, or reorder it
+		if len(parts) > 1 {
+			if len(parts) != 3 || (parts[1] != "bump" && parts[1] != "cancel") {
+				c.AddMessage(SystemMessage, "Usage: /queue [bump|cancel <#>]", "system")
+				return nil
+			}
+			action, position := parts[1], parts[2]
+			return func() tea.Msg {
+				return ExecuteCommandMsg{Command: "queue_" + action, Args: map[string]string{"position": position}}
+			}
+		}
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: "queue"}
+		}
+		
+	case "problems", "errors":
+		// List recent errors by component
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: "problems"}
+		}
+		
+	case "admin":
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: "admin_panel"}
+		}
+		
+	case "register", "signup":
+		// Create an account in the registration form
+		username := ""
+		if len(parts) > 1 {
+			username = parts[1]
+		}
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: "register_form", Args: map[string]string{"username": username}}
+		}
+		
+	case "password", "passwd":
+		if len(parts) == 1 || parts[1] == "change" {
+			return func() tea.Msg {
+				return ExecuteCommandMsg{Command: "password_form"}
+			}
+		}
+		c.AddMessage(SystemMessage, "Usage: /password change", "system")
+		
 	case "logout":
 		// Logout command
 		return func() tea.Msg {
@@ -563,12 +1366,107 @@ func (c Chat) handleSlashCommand(command string) tea.Cmd {
 			c.AddMessage(SystemMessage, "Usage: /apikey <generate|list|revoke|save>", "system")
 		}
 		
+	case "providers":
+		if len(parts) == 1 || parts[1] == "list" {
+			return func() tea.Msg { return ExecuteCommandMsg{Command: "provider_credentials_list"} }
+		}
+		commands := map[string]string{"configure": "provider_credentials_form", "set": "provider_credentials_form", "test": "provider_credentials_test", "remove": "provider_credentials_remove"}
+		if command, ok := commands[parts[1]]; ok && len(parts) == 3 {
+			provider := parts[2]
+			return func() tea.Msg {
+				return ExecuteCommandMsg{Command: command, Args: map[string]string{"provider": provider}}
+			}
+		}
+		c.AddMessage(SystemMessage, "Usage: /providers [configure|test|remove <provider>]\n  (none)    - List stored credentials, masked\n  configure - Set a provider's API key and base URL in a masked form\n  test      - Ping the provider with the stored credentials\n  remove    - Delete the stored credentials", "system")
+		
+	case "index":
+		if len(parts) == 1 || (parts[1] == "status" && len(parts) == 2) {
+			return func() tea.Msg { return ExecuteCommandMsg{Command: "index_status"} }
+		}
+		if parts[1] == "build" && (len(parts) == 2 || len(parts) == 3 && parts[2] == "--force") {
+			force := fmt.Sprint(len(parts) == 3)
+			return func() tea.Msg {
+				return ExecuteCommandMsg{Command: "index_build", Args: map[string]string{"force": force}}
+			}
+		}
+		c.AddMessage(SystemMessage, "Usage: /index [build [--force]|status]\n  build  - Index the project on the server for retrieval; --force re-embeds unchanged files\n  status - Show how much of the project is indexed and when", "system")
+		
+	case "sessions":
+		if len(parts) == 1 {
+			return func() tea.Msg {
+				return ExecuteCommandMsg{Command: "auth_sessions_list"}
+			}
+		}
+		if len(parts) == 3 && (parts[1] == "revoke" || parts[1] == "rm") {
+			id := parts[2]
+			return func() tea.Msg {
+				return ExecuteCommandMsg{Command: "auth_sessions_revoke", Args: map[string]string{"id": id}}
+			}
+		}
+		c.AddMessage(SystemMessage, "Usage: /sessions [revoke <id>]\n  (none) - List the account's active sessions\n  revoke - Sign a session out", "system")
+		
 	case "status", "auth":
 		// Check auth status
 		return func() tea.Msg {
 			return ExecuteCommandMsg{Command: "auth_status"}
 		}
 		
+	case "display":
+		if len(parts) == 1 {
+			return func() tea.Msg { return ExecuteCommandMsg{Command: "display"} }
+		}
+		args := map[string]string{"setting": parts[1]}
+		if len(parts) > 2 {
+			args["value"] = parts[2]
+		}
+		if parts[1] == "glyph" && len(parts) > 3 {
+			// Glyphs keep the case they were typed in
+			args["role"] = parts[2]
+			args["value"] = strings.Fields(strings.TrimPrefix(command, "/"))[3]
+		}
+		return func() tea.Msg { return ExecuteCommandMsg{Command: "display_set", Args: args} }
+		
+	case "dead-letters", "deadletters", "unhandled":
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: "dead_letters"}
+		}
+		
+	case "status-info":
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: "status_info"}
+		}
+		
+	case "cues", "cue":
+		// /cues lists them; /cues <event> <cues> sets one; /cues test <event> tries one
+		switch {
+		case len(parts) == 1:
+			return func() tea.Msg { return ExecuteCommandMsg{Command: "cues"} }
+		case len(parts) == 3 && parts[1] == "test":
+			event := parts[2]
+			return func() tea.Msg { return ExecuteCommandMsg{Command: "cues_test", Args: map[string]string{"event": event}} }
+		case len(parts) == 3:
+			args := map[string]string{"event": parts[1], "cues": parts[2]}
+			return func() tea.Msg { return ExecuteCommandMsg{Command: "cues_set", Args: args} }
+		default:
+			c.AddMessage(SystemMessage, cuesUsage, "system")
+			return nil
+		}
+		
+	case "subscriptions", "subs":
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: "subscriptions"}
+		}
+		
+	case "status-colors", "legend":
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: "status_colors"}
+		}
+		
+	case "dismiss":
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: "dismiss_alerts"}
+		}
+		
 	case "timestamps", "ts":
 		if len(parts) > 1 {
 			switch parts[1] {
@@ -612,9 +1510,219 @@ func (c Chat) handleSlashCommand(command string) tea.Cmd {
 			c.AddMessage(SystemMessage, "Usage: /config <save|load>\n  save - Save current provider/model as defaults\n  load - Load provider/model from config", "system")
 		}
 		
+	case "fallback":
+		if len(parts) == 1 {
+			return func() tea.Msg {
+				return ExecuteCommandMsg{Command: "fallback_retry"}
+			}
+		}
+		switch parts[1] {
+		case "set":
+			if len(parts) > 2 {
+				chain := strings.Join(parts[2:], " ")
+				return func() tea.Msg {
+					return ExecuteCommandMsg{
+						Command: "fallback_set",
+						Args:    map[string]string{"chain": chain},
+					}
+				}
+			}
+		case "auto":
+			if len(parts) > 2 && (parts[2] == "on" || parts[2] == "off") {
+				return func() tea.Msg {
+					return ExecuteCommandMsg{Command: "fallback_auto_" + parts[2]}
+				}
+			}
+		}
+		c.AddMessage(SystemMessage, "Usage: /fallback [set <provider/model ...>|auto <on|off>]\n  (none) - Retry the failed prompt with the next model\n  set    - Set the fallback chain, e.g. /fallback set openai/gpt-4 anthropic/claude-3-sonnet ollama/llama3\n  auto   - Retry automatically on provider errors", "system")
+		
+	case "budget", "cost":
+		if len(parts) == 1 {
+			return func() tea.Msg {
+				return ExecuteCommandMsg{Command: "budget_status"}
+			}
+		}
+		if len(parts) == 3 && (parts[1] == "conversation" || parts[1] == "conv" || parts[1] == "day") {
+			scope := parts[1]
+			amount := parts[2]
+			return func() tea.Msg {
+				return ExecuteCommandMsg{
+					Command: "budget_set",
+					Args:    map[string]string{"scope": scope, "amount": amount},
+				}
+			}
+		}
+		c.AddMessage(SystemMessage, "Usage: /budget [<conversation|day> <usd>]\n  (none)       - Show estimated spend and limits\n  conversation - Set the per-conversation limit (0 disables)\n  day          - Set the daily limit (0 disables)", "system")
+		
+	case "source":
+		if len(parts) > 1 && (parts[1] == FileSourceLocal || parts[1] == FileSourceServer) {
+			source := parts[1]
+			return func() tea.Msg {
+				return ExecuteCommandMsg{
+					Command: "file_source",
+					Args:    map[string]string{"source": source},
+				}
+			}
+		}
+		c.AddMessage(SystemMessage, "Usage: /source <local|server>\n  local  - File tree and editor use the local working directory\n  server - Use the server's file API (falls back to local when unavailable)", "system")
+		
+	case "reload", "keep", "diff":
+		// Resolve an open file that changed on disk
+		command := "file_" + parts[0]
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: command}
+		}
+		
+	case "saved", "history":
+		// Saved conversations are readable offline
+		if len(parts) == 2 && parts[1] == "archived" {
+			return func() tea.Msg {
+				return ExecuteCommandMsg{Command: "saved_archived"}
+			}
+		}
+		if len(parts) > 2 && parts[1] == "tag" {
+			tag := strings.ToLower(strings.TrimPrefix(parts[2], "#"))
+			return func() tea.Msg {
+				return ExecuteCommandMsg{
+					Command: "saved_list",
+					Args:    map[string]string{"tag": tag},
+				}
+			}
+		}
+		if len(parts) > 1 {
+			ref := parts[1]
+			return func() tea.Msg {
+				return ExecuteCommandMsg{
+					Command: "saved_open",
+					Args:    map[string]string{"ref": ref},
+				}
+			}
+		}
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: "saved_list"}
+		}
+		
+	case "goto-date", "goto":
+		// Jump the history to a day
+		if len(parts) < 2 {
+			c.AddMessage(SystemMessage, "Usage: /goto-date <YYYY-MM-DD|today|yesterday>", "system")
+			return nil
+		}
+		date := parts[1]
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: "goto_date", Args: map[string]string{"date": date}}
+		}
+		
+	case "pin":
+		// Pin the latest answer; Alt+Up selects any message to pin
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: "pin_last"}
+		}
+		
+	case "pins":
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: "pins_toggle"}
+		}
+		
+	case "conversation", "conv":
+		// Archive or delete the current conversation, or a saved one
+		if len(parts) > 1 && (parts[1] == "archive" || parts[1] == "delete" || (parts[1] == "unarchive" && len(parts) > 2)) {
+			command := "conversation_" + parts[1]
+			args := map[string]string{}
+			if len(parts) > 2 {
+				args["ref"] = parts[2]
+			}
+			return func() tea.Msg {
+				return ExecuteCommandMsg{Command: command, Args: args}
+			}
+		}
+		c.AddMessage(SystemMessage, "Usage: /conversation archive [saved number]\nUsage: /conversation delete [saved number]\nUsage: /conversation unarchive <id>", "system")
+		return nil
+		
+	case "tag", "tags":
+		// Tag the current conversation, or a saved one by number or ID
+		if len(parts) > 2 && (parts[1] == "add" || parts[1] == "remove" || parts[1] == "rm") {
+			command := "tag_add"
+			if parts[1] != "add" {
+				command = "tag_remove"
+			}
+			args := map[string]string{"tag": parts[2]}
+			if len(parts) > 3 {
+				args["ref"] = parts[3]
+			}
+			return func() tea.Msg {
+				return ExecuteCommandMsg{Command: command, Args: args}
+			}
+		}
+		if len(parts) == 1 || parts[1] == "list" {
+			return func() tea.Msg {
+				return ExecuteCommandMsg{Command: "tag_list"}
+			}
+		}
+		c.AddMessage(SystemMessage, "Usage: /tag add <tag> [saved number]\nUsage: /tag remove <tag> [saved number]\nUsage: /tag list", "system")
+		return nil
+		
+	case "telemetry":
+		if len(parts) > 1 {
+			switch parts[1] {
+			case "on", "off", "status", "upload":
+				return func() tea.Msg {
+					return ExecuteCommandMsg{Command: "telemetry_" + parts[1]}
+				}
+			}
+		}
+		c.AddMessage(SystemMessage, "Usage: /telemetry <on|off|status|upload>\nCounts feature use and error classes, never content", "system")
+		
+	case "retry":
+		// Send the last timed out request again
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: "retry"}
+		}
+		
+	case "doctor":
+		// Copy a bug report bundle, or save it; the file name keeps its case
+		if len(parts) > 1 && parts[1] == "save" {
+			name := ""
+			if fields := strings.Fields(strings.TrimPrefix(command, "/")); len(fields) > 2 {
+				name = strings.Join(fields[2:], " ")
+			}
+			return func() tea.Msg {
+				return ExecuteCommandMsg{Command: "doctor_save", Args: map[string]string{"file": name}}
+			}
+		}
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: "doctor"}
+		}
+		
+	case "stats":
+		// Show response latency statistics
+		return func() tea.Msg {
+			return ExecuteCommandMsg{Command: "stats"}
+		}
+		
+	case "spellcheck", "spell":
+		if len(parts) > 1 && (parts[1] == "on" || parts[1] == "off") {
+			return func() tea.Msg {
+				return ExecuteCommandMsg{Command: "spellcheck_" + parts[1]}
+			}
+		}
+		c.AddMessage(SystemMessage, "Usage: /spellcheck <on|off>\nChecks the input against a hunspell word list (tui.spellcheck_dictionary in config)", "system")
+		
+	case "lint":
+		if len(parts) > 1 && (parts[1] == "on" || parts[1] == "off") {
+			return func() tea.Msg {
+				return ExecuteCommandMsg{Command: "lint_" + parts[1]}
+			}
+		}
+		c.AddMessage(SystemMessage, "Usage: /lint <on|off>\nWarns about empty prompts, unclosed code fences, and missing context", "system")
+		
 	case "plan":
 		// Start planning session with remaining input as query
-		if len(parts) > 1 {
+		if len(parts) == 2 && parts[1] == "cancel" {
+			return func() tea.Msg {
+				return ExecuteCommandMsg{Command: "cancel_planning"}
+			}
+		} else if len(parts) > 1 {
 			query := strings.Join(parts[1:], " ")
 			return func() tea.Msg {
 				return ExecuteCommandMsg{
@@ -623,7 +1731,7 @@ func (c Chat) handleSlashCommand(command string) tea.Cmd {
 				}
 			}
 		} else {
-			c.AddMessage(SystemMessage, "Usage: /plan <query>\nExample: /plan create a REST API for user management", "system")
+			c.AddMessage(SystemMessage, "Usage: /plan <query> | cancel\nExample: /plan create a REST API for user management", "system")
 		}
 		
 	case "quit", "exit", "q":
@@ -633,22 +1741,8 @@ func (c Chat) handleSlashCommand(command string) tea.Cmd {
 	default:
 		// Unknown command - show help in chat
 		helpText := fmt.Sprintf("Unknown command: /%s\n\nAvailable commands:\n", parts[0])
-		helpText += "/help, /h, /?     - Show help\n"
-		helpText += "/model <name>      - Set AI model\n"
-		helpText += "/clear, /new       - New conversation\n"
-		helpText += "/tree, /files      - Toggle file tree\n"
-		helpText += "/editor, /edit     - Toggle editor\n"
-		helpText += "/commands, /cmds   - Show command palette\n"
-		helpText += "/provider <name>   - Set provider for current model\n"
-		helpText += "/config <save|load>- Save/load default provider and model\n"
-		helpText += "/timestamps <cmd>  - Control timestamp display\n"
-		helpText += "/plan <query>      - Start AI planning session\n"
-		helpText += "/login <user> <pw> - Login to server\n"
-		helpText += "/logout            - Logout from server\n"
-		helpText += "/apikey <cmd>      - API key management\n"
-		helpText += "/status, /auth     - Show auth status\n"
-		helpText += "/quit, /exit, /q   - Quit application"
-		c.AddMessage(SystemMessage, helpText, "system")
+		helpText += FormatSlashCommandList()
+		c.AddMessage(SystemMessage, strings.TrimRight(helpText, "\n"), "system")
 	}
 	
 	return nil
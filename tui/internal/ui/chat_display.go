@@ -0,0 +1,169 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rubber_duck/tui/internal/clock"
+)
+
+// Timestamp styles for chat messages
+const (
+	timestampsAbsolute = "absolute"
+	timestampsRelative = "relative"
+	timestampsOff      = "off"
+)
+
+// chatDisplay is how chat messages are laid out
+type chatDisplay struct {
+	Timestamps string            // timestampsAbsolute, timestampsRelative, or timestampsOff
+	Clock12h   bool              // 3:04:05 PM instead of 15:04:05
+	Compact    bool              // Hide the author line of consecutive messages from the same role
+	Glyphs     map[string]string // Role ("user", "assistant", "system", "error") to the glyph before its label
+}
+
+// SetDisplay applies display settings, redrawing the messages
+func (c *Chat) SetDisplay(display chatDisplay) {
+	if display.Timestamps == "" {
+		display.Timestamps = timestampsAbsolute
+	}
+	c.display = display
+	c.viewport.SetContent(c.buildViewportContent())
+}
+
+// formatMessageTime formats a message's time for its author line
+func (d chatDisplay) formatMessageTime(at, now time.Time) string {
+	switch d.Timestamps {
+	case timestampsOff:
+		return ""
+	case timestampsRelative:
+		switch age := now.Sub(at); {
+		case age < time.Minute:
+			return "just now"
+		case age < time.Hour:
+			return fmt.Sprintf("%dm ago", int(age.Minutes()))
+		case age < 24*time.Hour:
+			return fmt.Sprintf("%dh ago", int(age.Hours()))
+		}
+		if d.Clock12h {
+			return at.Format("Jan 2 3:04 PM")
+		}
+		return at.Format("Jan 2 15:04")
+	}
+	if d.Clock12h {
+		return at.Format("3:04:05 PM")
+	}
+	return at.Format("15:04:05")
+}
+
+// roleName returns the config name of a message type
+func roleName(msgType MessageType) string {
+	switch msgType {
+	case UserMessage:
+		return "user"
+	case AssistantMessage:
+		return "assistant"
+	case ErrorMessage:
+		return "error"
+	default:
+		return "system"
+	}
+}
+
+// compactWith reports whether a message's author line is hidden because the
+// one before it is from the same role on the same day
+func (c *Chat) compactWith(index int) bool {
+	if !c.display.Compact || index == 0 {
+		return false
+	}
+	msg, previous := c.messages[index], c.messages[index-1]
+	if msg.Pinned || (c.selecting && c.selected == index) {
+		return false
+	}
+	return msg.Type == previous.Type && sameDay(msg.Timestamp, previous.Timestamp)
+}
+
+// refreshRelativeTimes redraws relative timestamps once a minute
+func (c *Chat) refreshRelativeTimes() {
+	if c.display.Timestamps != timestampsRelative {
+		return
+	}
+	minute := clock.Now().Truncate(time.Minute)
+	if minute.Equal(c.relativeMinute) {
+		return
+	}
+	c.relativeMinute = minute
+	c.viewport.SetContent(c.buildViewportContent())
+}
+
+// applyDisplaySettings applies the chat display config
+func (m *Model) applyDisplaySettings() {
+	m.chat.SetDisplay(chatDisplay{
+		Timestamps: m.config.TUI.TimestampStyle,
+		Clock12h:   m.config.TUI.Clock12h,
+		Compact:    m.config.TUI.CompactChat,
+		Glyphs:     m.config.TUI.RoleGlyphs,
+	})
+}
+
+// describeDisplay lists the current display settings for /display
+func (m Model) describeDisplay() string {
+	tui := m.config.TUI
+	style := tui.TimestampStyle
+	if style == "" {
+		style = timestampsAbsolute
+	}
+	clockFormat := "24h"
+	if tui.Clock12h {
+		clockFormat = "12h"
+	}
+	compact := "off"
+	if tui.CompactChat {
+		compact = "on"
+	}
+	glyphs := "none"
+	if len(tui.RoleGlyphs) > 0 {
+		var pairs []string
+		for role, glyph := range tui.RoleGlyphs {
+			pairs = append(pairs, role+"="+glyph)
+		}
+		sort.Strings(pairs)
+		glyphs = strings.Join(pairs, " ")
+	}
+	return fmt.Sprintf("Chat display:\n  Timestamps: %s\n  Clock: %s\n  Compact: %s\n  Glyphs: %s\n\n%s", style, clockFormat, compact, glyphs, displayUsage)
+}
+
+// displayUsage explains /display
+const displayUsage = "Usage: /display <setting> <value>\n  timestamps <absolute|relative|off>\n  clock <12|24>\n  compact <on|off>\n  glyph <user|assistant|system|error> <glyph|none>"
+
+// setDisplay changes one display setting, applies it, and saves it
+func (m *Model) setDisplay(setting, value, role string) {
+	tui := &m.config.TUI
+	switch {
+	case setting == "timestamps" && (value == timestampsAbsolute || value == timestampsRelative || value == timestampsOff):
+		tui.TimestampStyle = value
+	case setting == "clock" && (value == "12" || value == "24"):
+		tui.Clock12h = value == "12"
+	case setting == "compact" && (value == "on" || value == "off"):
+		tui.CompactChat = value == "on"
+	case setting == "glyph" && (role == "user" || role == "assistant" || role == "system" || role == "error") && value != "":
+		if value == "none" {
+			delete(tui.RoleGlyphs, role)
+			break
+		}
+		if tui.RoleGlyphs == nil {
+			tui.RoleGlyphs = make(map[string]string)
+		}
+		tui.RoleGlyphs[role] = value
+	default:
+		m.chat.AddMessage(SystemMessage, displayUsage, "system")
+		return
+	}
+	m.applyDisplaySettings()
+	m.statusBar = "Display updated"
+	if err := SaveConfig(m.config); err != nil {
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Failed to save config: %v", err), nil)
+	}
+}
@@ -0,0 +1,61 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rubber_duck/tui/internal/clock"
+)
+
+func TestFormatMessageTime(t *testing.T) {
+	now := time.Date(2024, 3, 10, 15, 30, 0, 0, time.Local)
+	tests := []struct {
+		display chatDisplay
+		at      time.Time
+		want    string
+	}{
+		{chatDisplay{Timestamps: timestampsAbsolute}, now, "15:30:00"},
+		{chatDisplay{Timestamps: timestampsAbsolute, Clock12h: true}, now, "3:30:00 PM"},
+		{chatDisplay{Timestamps: timestampsRelative}, now.Add(-20 * time.Second), "just now"},
+		{chatDisplay{Timestamps: timestampsRelative}, now.Add(-5 * time.Minute), "5m ago"},
+		{chatDisplay{Timestamps: timestampsRelative}, now.Add(-3 * time.Hour), "3h ago"},
+		{chatDisplay{Timestamps: timestampsRelative, Clock12h: true}, now.Add(-48 * time.Hour), "Mar 8 3:30 PM"},
+		{chatDisplay{Timestamps: timestampsOff}, now, ""},
+	}
+	for _, tt := range tests {
+		if got := tt.display.formatMessageTime(tt.at, now); got != tt.want {
+			t.Errorf("formatMessageTime(%+v) = %q, want %q", tt.display, got, tt.want)
+		}
+	}
+}
+
+func TestCompactChatHidesRepeatedAuthors(t *testing.T) {
+	restore := clock.Freeze(time.Date(2024, 3, 10, 15, 30, 0, 0, time.Local))
+	defer restore()
+
+	chat := NewChat()
+	chat.SetSize(80, 40)
+	chat.AddMessage(UserMessage, "first", "user")
+	chat.AddMessage(UserMessage, "second", "user")
+	chat.AddMessage(AssistantMessage, "answer", "assistant")
+
+	if view := chat.buildViewportContent(); strings.Count(view, "You") != 2 {
+		t.Fatalf("Expected an author line per message, got:\n%s", view)
+	}
+
+	chat.SetDisplay(chatDisplay{Compact: true, Glyphs: map[string]string{"assistant": "🦆"}})
+	view := chat.buildViewportContent()
+	if strings.Count(view, "You") != 1 {
+		t.Fatalf("Expected compact mode to hide the repeated author line, got:\n%s", view)
+	}
+	if !strings.Contains(view, "🦆 Assistant") {
+		t.Fatalf("Expected the assistant glyph, got:\n%s", view)
+	}
+
+	chat.startSelection()
+	chat.selected = 1
+	if view := chat.buildViewportContent(); strings.Count(view, "You") != 2 {
+		t.Fatalf("Expected the selected message to keep its author line, got:\n%s", view)
+	}
+}
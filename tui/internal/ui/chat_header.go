@@ -3,12 +3,13 @@ package ui
 import (
 	"fmt"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/rubber_duck/tui/internal/phoenix"
 )
 
 // ChatHeader represents the chat header component
 type ChatHeader struct {
-	width           int
-	conversationID  string
+	width          int
+	conversationID string
 	model          string
 	provider       string
 	messageCount   int
@@ -16,6 +17,8 @@ type ChatHeader struct {
 	tokenLimit     int
 	connected      bool
 	authenticated  bool
+	tags           []string
+	providerHealth string // "degraded" or "unavailable", empty when healthy
 }
 
 // NewChatHeader creates a new chat header
@@ -81,6 +84,19 @@ func (h ChatHeader) View() string {
 		connStatus, 
 		h.conversationID,
 		modelInfo)
+	if h.providerHealth != "" {
+		badgeColor := "226" // yellow
+		if h.providerHealth == phoenix.ProviderUnavailable {
+			badgeColor = "196" // red
+		}
+		leftContent += " " + lipgloss.NewStyle().
+			Foreground(lipgloss.Color(badgeColor)).
+			Bold(true).
+			Render("⚠ "+h.providerHealth)
+	}
+	if len(h.tags) > 0 {
+		leftContent += " " + renderTags(h.tags)
+	}
 	
 	rightContent := fmt.Sprintf("Tokens: %s | Messages: %d",
 		tokenStyle.Render(tokenInfo),
@@ -117,6 +133,11 @@ func (h *ChatHeader) SetConversationID(id string) {
 	}
 }
 
+// SetTags updates the conversation's tags
+func (h *ChatHeader) SetTags(tags []string) {
+	h.tags = tags
+}
+
 // SetModel updates the model and provider
 func (h *ChatHeader) SetModel(model, provider string) {
 	if model == "" {
@@ -128,6 +149,12 @@ func (h *ChatHeader) SetModel(model, provider string) {
 	}
 }
 
+// SetProviderHealth shows a warning badge next to the model while the
+// provider is degraded or unavailable
+func (h *ChatHeader) SetProviderHealth(status string) {
+	h.providerHealth = status
+}
+
 // SetMessageCount updates the message count
 func (h *ChatHeader) SetMessageCount(count int) {
 	h.messageCount = count
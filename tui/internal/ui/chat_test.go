@@ -1,10 +1,15 @@
 package ui
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 	
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/testutil"
 )
 
 func TestNewChat(t *testing.T) {
@@ -207,4 +212,313 @@ func TestChatMessage_Timestamp(t *testing.T) {
 	if msg.Timestamp.Before(beforeTime) || msg.Timestamp.After(afterTime) {
 		t.Error("Expected timestamp to be set to current time")
 	}
-}
\ No newline at end of file
+}
+
+func TestChat_PasteInsertsSingleDraft(t *testing.T) {
+	chat := NewChat()
+	chat.focused = true
+	
+	// A multi-line prose paste should land in the input without sending
+	paste := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("first line\r\nsecond line\r\n"), Paste: true}
+	
+	updatedChat, cmd := chat.Update(paste)
+	if cmd != nil {
+		t.Error("Expected no command for pasted text")
+	}
+	
+	inputChat := updatedChat.(Chat)
+	if inputChat.input.Value() != "first line\nsecond line" {
+		t.Errorf("Expected normalized multi-line draft, got '%s'", inputChat.input.Value())
+	}
+}
+
+func TestChat_CodePastePromptsForFence(t *testing.T) {
+	chat := NewChat()
+	chat.focused = true
+	
+	code := "func main() {\n    fmt.Println(\"hi\")\n}"
+	paste := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(code), Paste: true}
+	
+	updatedChat, _ := chat.Update(paste)
+	inputChat := updatedChat.(Chat)
+	if !inputChat.HasPendingPaste() {
+		t.Fatal("Expected code paste to wait for the fence prompt")
+	}
+	
+	// Answer yes to wrap the paste
+	updatedChat, _ = inputChat.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	inputChat = updatedChat.(Chat)
+	if inputChat.HasPendingPaste() {
+		t.Error("Expected prompt to be resolved")
+	}
+	
+	expected := "```go\n" + code + "\n```"
+	if inputChat.input.Value() != expected {
+		t.Errorf("Expected fenced code, got '%s'", inputChat.input.Value())
+	}
+}
+
+func TestChat_DroppedFileIsCheckedInACommand(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "notes.txt")
+	os.WriteFile(file, []byte("notes"), 0644)
+
+	tests := []struct {
+		name  string
+		paste string
+		want  string
+	}{
+		{"quoted file", "'" + file + "'", "`" + file + "`"},
+		{"directory", dir, dir},
+		{"missing file", filepath.Join(dir, "gone.txt"), filepath.Join(dir, "gone.txt")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chat := NewChat()
+			chat.focused = true
+			updatedChat, cmd := chat.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(tt.paste), Paste: true})
+			if cmd == nil || updatedChat.(Chat).input.Value() != "" {
+				t.Fatal("Expected the path looked up in a command before anything is inserted")
+			}
+			checked := updatedChat.(Chat)
+			updatedChat, _ = checked.handleCheckedPaste(cmd().(PasteCheckedMsg))
+			if got := updatedChat.(Chat).input.Value(); got != tt.want {
+				t.Errorf("Expected %q inserted, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestChat_PinSelectedMessage(t *testing.T) {
+	chat := NewChat()
+	chat.SetSize(100, 50)
+	chat.AddMessage(UserMessage, "question", "user")
+	chat.AddMessage(AssistantMessage, "first answer", "assistant")
+	chat.AddMessage(UserMessage, "follow-up", "user")
+	heightBefore := chat.viewport.Height
+	
+	press := func(key tea.KeyMsg) tea.Cmd {
+		model, cmd := chat.Update(key)
+		updated := model.(Chat)
+		chat = &updated
+		return cmd
+	}
+	
+	press(tea.KeyMsg{Type: tea.KeyUp, Alt: true})
+	if !chat.IsSelecting() || chat.selected != 1 {
+		t.Fatalf("Expected Alt+Up to select the last answer, got selecting=%v selected=%d", chat.IsSelecting(), chat.selected)
+	}
+	
+	cmd := press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	if msg, ok := cmd().(MessagePinnedMsg); !ok || msg.Index != 1 || !msg.Pinned {
+		t.Fatalf("Expected a pin message for message 1, got %#v", msg)
+	}
+	if !chat.messages[1].Pinned || chat.GetInputValue() != "" {
+		t.Fatalf("Expected message 1 pinned without typing into the input")
+	}
+	if chat.viewport.Height >= heightBefore {
+		t.Errorf("Expected the pinned panel to shrink the history, got %d (was %d)", chat.viewport.Height, heightBefore)
+	}
+	
+	press(tea.KeyMsg{Type: tea.KeyEsc})
+	if chat.IsSelecting() {
+		t.Fatal("Expected Esc to leave selection")
+	}
+}
+
+func TestChat_DateSeparatorsAndGotoDate(t *testing.T) {
+	chat := NewChat()
+	chat.SetSize(100, 20)
+	day1 := time.Date(2026, 3, 1, 10, 0, 0, 0, time.Local)
+	day2 := time.Date(2026, 3, 4, 9, 0, 0, 0, time.Local)
+	for i := 0; i < 10; i++ {
+		chat.AddMessageAt(UserMessage, "early question", "user", day1.Add(time.Duration(i)*time.Minute))
+	}
+	for i := 0; i < 10; i++ {
+		chat.AddMessageAt(UserMessage, "later question", "user", day2.Add(time.Duration(i)*time.Minute))
+	}
+	
+	if len(chat.dayLines) != 1 {
+		t.Fatalf("Expected one date separator, got %d", len(chat.dayLines))
+	}
+	if !strings.Contains(chat.buildViewportContent(), "Wed, Mar 4 2026") {
+		t.Error("Expected a separator labeled with the new day")
+	}
+	
+	target, err := parseGotoDate("2026-03-02", day2)
+	if err != nil {
+		t.Fatalf("parseGotoDate: %v", err)
+	}
+	if !chat.GotoDate(target) || chat.viewport.YOffset != chat.dayLines[0] {
+		t.Errorf("Expected to jump to the separator at line %d, got offset %d", chat.dayLines[0], chat.viewport.YOffset)
+	}
+	if chat.GotoDate(day2.AddDate(0, 0, 1)) {
+		t.Error("Expected no message after the last day")
+	}
+	if _, err := parseGotoDate("March 4", day2); err == nil {
+		t.Error("Expected an error for an unsupported date format")
+	}
+}
+
+func TestChat_PrependOlderHistory(t *testing.T) {
+	chat := NewChat()
+	chat.SetSize(100, 20)
+	at := time.Date(2026, 3, 1, 10, 0, 0, 0, time.Local)
+	for i := 0; i < 10; i++ {
+		chat.restoreMessage(ChatMessage{ID: fmt.Sprintf("m%d", i+5), Type: UserMessage, Content: "newer", Timestamp: at})
+	}
+	chat.SetOlderHistory(true)
+	chat.viewport.GotoTop()
+	
+	model, cmd := chat.Update(tea.KeyMsg{Type: tea.KeyUp})
+	updated := model.(Chat)
+	chat = &updated
+	if !chat.loadingOlder || cmd == nil {
+		t.Fatal("Expected scrolling past the top to request older history")
+	}
+	
+	older := []ChatMessage{
+		{ID: "m3", Type: UserMessage, Content: "older", Timestamp: at},
+		{ID: "m4", Type: UserMessage, Content: "older", Timestamp: at},
+		{ID: "m5", Type: UserMessage, Content: "duplicate", Timestamp: at},
+	}
+	chat.SetOlderHistory(false)
+	if added := chat.PrependMessages(older); added != 2 {
+		t.Fatalf("Expected the duplicate to be dropped, added %d", added)
+	}
+	if chat.messages[0].ID != "m3" || chat.messages[2].ID != "m5" || len(chat.messages) != 12 {
+		t.Fatalf("Expected older messages stitched first, got %s..%s (%d)", chat.messages[0].ID, chat.messages[2].ID, len(chat.messages))
+	}
+	if chat.viewport.YOffset != chat.messageLines[2] {
+		t.Errorf("Expected the view to stay on the previously first message at line %d, got %d", chat.messageLines[2], chat.viewport.YOffset)
+	}
+}
+
+func TestChat_SpillsOldMessagesToDisk(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	chat := NewChat()
+	chat.SetSize(100, 20)
+	for i := 0; i < 700; i++ {
+		chat.AddMessage(UserMessage, fmt.Sprintf("message %d", i), "user")
+	}
+	chat.SetPinned(0, true)
+	
+	if len(chat.messages) > maxMessagesInMemory || chat.spill.count() == 0 {
+		t.Fatalf("Expected old messages spilled, %d in memory and %d on disk", len(chat.messages), chat.spill.count())
+	}
+	all := chat.GetMessages()
+	if chat.GetMessageCount() != 700 || len(all) != 700 || all[0].Content != "message 0" || all[699].Content != "message 699" {
+		t.Fatalf("Expected all 700 messages in order, got %d", len(all))
+	}
+	
+	chat.viewport.GotoTop()
+	inMemory := len(chat.messages)
+	model, _ := chat.Update(tea.KeyMsg{Type: tea.KeyUp})
+	updated := model.(Chat)
+	chat = &updated
+	if len(chat.messages) != inMemory+spillSegmentSize || chat.messages[0].Content != "message 0" {
+		t.Fatalf("Expected the newest segment read back on scroll, got %d starting at %q", len(chat.messages), chat.messages[0].Content)
+	}
+	
+	dir := chat.spill.dir
+	chat.ClearMessages()
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("Expected the spill directory removed, got %v", err)
+	}
+}
+
+func TestChat_SpilledHistoryIsNotReadBackForEstimates(t *testing.T) {
+	testutil.IsolateHome(t)
+	t.Setenv("TMPDIR", t.TempDir())
+	model := NewModel()
+	for i := 0; i < 700; i++ {
+		model.chat.AddMessage(UserMessage, fmt.Sprintf("message %d", i), "user")
+	}
+	if model.chat.spill.count() == 0 {
+		t.Fatal("Expected old messages spilled")
+	}
+	all := model.chat.GetMessages()
+	if got, want := model.chat.TokenEstimate(), EstimateConversationTokens(all); got != want {
+		t.Errorf("Expected a token estimate of %d, got %d", want, got)
+	}
+	if got := model.chat.UserMessageCount(); got != 700 {
+		t.Errorf("Expected 700 user messages, got %d", got)
+	}
+
+	// Autosaving streams the segments into the saved conversation
+	model.autosaveConversation()
+	saved, err := loadConversationByID(model.savedConversationID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Title != "message 0" || len(saved.Messages) != 700 || saved.Messages[699].Content != "message 699" {
+		t.Errorf("Expected all 700 messages saved under the first one's title, got %d titled %q", len(saved.Messages), saved.Title)
+	}
+}
+
+func TestChat_IncognitoHistoryLeavesMemoryUnwritten(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	chat := NewChat()
+	chat.SetSize(100, 20)
+	for i := 0; i < 100; i++ {
+		chat.AddMessage(UserMessage, fmt.Sprintf("public %d", i), "user")
+	}
+	chat.incognito = true
+	for i := 0; i < 700; i++ {
+		chat.AddMessage(UserMessage, fmt.Sprintf("private %d", i), "user")
+	}
+
+	if len(chat.messages) > maxMessagesInMemory {
+		t.Fatalf("Expected incognito history compacted too, %d in memory", len(chat.messages))
+	}
+	if chat.spill.count() != 100 {
+		t.Fatalf("Expected only the 100 public messages on disk, got %d", chat.spill.count())
+	}
+	for _, segment := range chat.spill.segments {
+		messages, err := readSegment(segment.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, msg := range messages {
+			if msg.Private {
+				t.Fatalf("Expected no private message written to disk, found %q", msg.Content)
+			}
+		}
+	}
+}
+
+func TestChat_DroppedHistoryIsReported(t *testing.T) {
+	testutil.IsolateHome(t)
+	t.Setenv("TMPDIR", t.TempDir())
+	model := NewModel()
+	segment := []ChatMessage{{Type: UserMessage, Content: "old"}, {Type: UserMessage, Content: "older"}}
+	for i := 0; i <= maxSpillSegments; i++ {
+		if err := model.chat.spill.push(segment); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	updated, _ := model.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	*model = updated.(Model)
+	reported := 0
+	for _, msg := range model.statusMessages.messages {
+		if strings.Contains(msg.Text, "Dropped the 2 oldest messages") {
+			reported++
+		}
+	}
+	if reported != 1 {
+		t.Fatalf("Expected the dropped messages reported once, got %d", reported)
+	}
+
+	// Only new drops are reported
+	updated, _ = model.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	*model = updated.(Model)
+	for _, msg := range model.statusMessages.messages {
+		if strings.Contains(msg.Text, "Dropped") {
+			reported--
+		}
+	}
+	if reported != 0 {
+		t.Error("Expected the drop reported only once")
+	}
+}
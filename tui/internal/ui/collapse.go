@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// defaultCollapseLines is how tall a message can render before it is
+// collapsed, when tui.collapse_lines isn't set
+const defaultCollapseLines = 60
+
+// SetCollapseLines sets the rendered height messages collapse beyond;
+// 0 uses the default and a negative value never collapses
+func (c *Chat) SetCollapseLines(lines int) {
+	if lines == 0 {
+		lines = defaultCollapseLines
+	}
+	c.collapseLines = lines
+	c.viewport.SetContent(c.buildViewportContent())
+}
+
+// collapseRendered shortens a rendered message to the collapse height with
+// an expand hint; the message's content itself is never shortened, so copy
+// and export still see all of it
+func (c *Chat) collapseRendered(index int, rendered string) string {
+	if c.collapseLines <= 0 || c.messages[index].Expanded {
+		return rendered
+	}
+	lines := strings.Split(rendered, "\n")
+	if len(lines) <= c.collapseLines {
+		return rendered
+	}
+
+	hint := "select with Alt+↑ and press o to expand"
+	if c.selecting && c.selected == index {
+		hint = "press o to expand"
+	}
+	footer := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("214")).
+		Italic(true).
+		Render(fmt.Sprintf("… %d more lines, %s", len(lines)-c.collapseLines, hint))
+	return strings.Join(lines[:c.collapseLines], "\n") + "\n" + footer
+}
+
+// toggleExpanded expands or re-collapses a message
+func (c *Chat) toggleExpanded(index int) {
+	if index < 0 || index >= len(c.messages) {
+		return
+	}
+	c.messages[index].Expanded = !c.messages[index].Expanded
+}
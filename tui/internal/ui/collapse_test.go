@@ -0,0 +1,35 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestLongMessagesCollapse(t *testing.T) {
+	chat := NewChat()
+	chat.SetSize(80, 40)
+	chat.SetCollapseLines(5)
+	long := strings.TrimSuffix(strings.Repeat("line\n", 12), "\n")
+	chat.AddMessage(UserMessage, long, "user")
+
+	if view := chat.buildViewportContent(); !strings.Contains(view, "… 7 more lines") {
+		t.Fatalf("Expected the message to be collapsed, got:\n%s", view)
+	}
+	if chat.GetMessages()[0].Content != long {
+		t.Fatal("Collapsing must keep the full content")
+	}
+
+	chat.startSelection()
+	updated, _ := chat.updateSelection(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")})
+	expanded := updated.(Chat)
+	if view := expanded.buildViewportContent(); strings.Contains(view, "more lines") {
+		t.Fatalf("Expected o to expand the message, got:\n%s", view)
+	}
+
+	chat.SetCollapseLines(-1)
+	if view := chat.buildViewportContent(); strings.Contains(view, "more lines") {
+		t.Fatal("Expected a negative height to never collapse")
+	}
+}
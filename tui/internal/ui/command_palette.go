@@ -4,6 +4,7 @@ import (
 	"strings"
 	
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/phoenix"
 )
 
 // Command represents a command in the palette
@@ -12,6 +13,7 @@ type Command struct {
 	Description string
 	Shortcut    string
 	Action      string
+	Locked      bool // The signed-in user lacks the permission the action needs
 }
 
 // CommandPalette represents the command palette component
@@ -29,13 +31,66 @@ func NewCommandPalette() CommandPalette {
 		{Name: "New File", Description: "Create a new file", Shortcut: "Ctrl+N", Action: "new_file"},
 		{Name: "Open File", Description: "Open an existing file", Shortcut: "Ctrl+O", Action: "open_file"},
 		{Name: "Save File", Description: "Save the current file", Shortcut: "Ctrl+S", Action: "save_file"},
+		{Name: "Apply Suggested Changes", Description: "Preview and apply refactor changes to the current file", Shortcut: "", Action: "apply_changes"},
+		{Name: "Roll Back Changes", Description: "Restore the files written by the last applied review", Shortcut: "", Action: "rollback_changes"},
+		{Name: "ReAct Execution", Description: "Show the live thought, action, and observation loop", Shortcut: "", Action: "react_view"},
+		{Name: "Export Patch", Description: "Write the applied changes to a unified diff file", Shortcut: "", Action: "export_patch"},
+		{Name: "Open Pull Request", Description: "Open a branch and pull request with the applied changes", Shortcut: "", Action: "create_pull_request"},
+		{Name: "Index: Build Project Index", Description: "Index the project on the server for retrieval", Shortcut: "", Action: "index_build"},
+		{Name: "Index: Status", Description: "Show how much of the project is indexed", Shortcut: "", Action: "index_status"},
+		{Name: "Cache: Clear", Description: "Drop cached answers to server queries", Shortcut: "", Action: "cache_clear"},
+		{Name: "View: Metrics", Description: "Where usage metrics and traces are sent", Shortcut: "", Action: "metrics"},
+		{Name: "Toggle Incognito", Description: "Keep new messages out of saved conversations", Shortcut: "", Action: "incognito"},
+		{Name: "Lock Screen", Description: "Hide the conversation until your password or PIN is entered", Shortcut: "", Action: "lock"},
+		{Name: "View: Permissions", Description: "Remembered answers to local file and program prompts", Shortcut: "", Action: "permissions"},
+		{Name: "Chat: Open Link", Description: "Label the links in view to open or copy one", Shortcut: "Alt+L", Action: "link_hints"},
+		{Name: "Chat: Open Mentioned File", Description: "Label the file paths in view to open one in the editor", Shortcut: "Alt+P", Action: "path_hints"},
+		{Name: "Chat: Insert Emoji or Symbol", Description: "Search emoji and unicode symbols to insert in the input", Shortcut: "Ctrl+.", Action: "emoji"},
+		{Name: "View: Prompt Prefix", Description: "Text added before and after each message in this conversation", Shortcut: "", Action: "prefix"},
+		{Name: "View: Macros", Description: "Keyboard macros recorded this session and saved by name", Shortcut: "Alt+Q", Action: "macro"},
+		{Name: "View: Known Servers", Description: "Servers pinned on first connect, with their fingerprints", Shortcut: "", Action: "trust"},
+		{Name: "Encryption: Status", Description: "Whether saved conversations are encrypted", Shortcut: "", Action: "encrypt"},
+		{Name: "Encryption: Migrate", Description: "Encrypt conversations saved in plaintext", Shortcut: "", Action: "encrypt_migrate"},
+		{Name: "View: Memory", Description: "Estimated memory per component and for the process", Shortcut: "", Action: "memory"},
+		{Name: "View: Audit Log", Description: "The last commands run, with duration and status", Shortcut: "", Action: "audit"},
+		{Name: "View: Command Queue", Description: "Server commands running and waiting for a slot", Shortcut: "", Action: "queue"},
+		{Name: "View: Problems", Description: "Recent errors grouped by component, with counts and details", Shortcut: "", Action: "problems"},
+		{Name: "Doctor: Copy Bug Report Info", Description: "Copy the version, terminal, redacted config, connection, and recent errors", Shortcut: "", Action: "doctor"},
+		{Name: "Project: Settings", Description: "Show the project and the settings its .rubber_duck.toml overrides", Shortcut: "", Action: "project_info"},
+		{Name: "Ask About Selection", Description: "Explain, find bugs in, optimize, or test the editor selection", Shortcut: "Alt+A", Action: "ask_selection"},
+		{Name: "Format File", Description: "Format the current file", Shortcut: "", Action: "format_file"},
 		{Name: "Toggle File Tree", Description: "Show/hide file tree", Shortcut: "Ctrl+F", Action: "toggle_tree"},
 		{Name: "Toggle Editor", Description: "Show/hide editor", Shortcut: "Ctrl+E", Action: "toggle_editor"},
+		{Name: "Toggle Outline", Description: "Show/hide symbols of the open file", Shortcut: "Alt+O", Action: "toggle_outline"},
 		{Name: "Focus Chat", Description: "Focus on chat input", Shortcut: "Ctrl+/", Action: "focus_chat"},
+		{Name: "Focus File Tree", Description: "Show and focus the file tree", Shortcut: "Alt+2", Action: "focus_tree"},
+		{Name: "Focus Editor", Description: "Show and focus the editor", Shortcut: "Alt+3", Action: "focus_editor"},
+		{Name: "Focus Outline", Description: "Show and focus the outline", Shortcut: "Alt+4", Action: "focus_outline"},
+		{Name: "Compose Message", Description: "Write a long prompt in a full-screen editor", Shortcut: "Alt+E", Action: "compose"},
+		{Name: "Search Project", Description: "Search files for text or a regexp", Shortcut: "Alt+F", Action: "search"},
 		{Name: "New Conversation", Description: "Start a new conversation", Shortcut: "Ctrl+Shift+N", Action: "new_conversation"},
+		{Name: "Undo Clear", Description: "Bring back the most recently cleared conversation", Shortcut: "", Action: "undo_clear"},
+		{Name: "Conversation: Archive", Description: "Archive the current conversation", Shortcut: "", Action: "conversation_archive"},
+		{Name: "Conversation: Delete", Description: "Delete the current conversation", Shortcut: "", Action: "conversation_delete"},
+		{Name: "Conversation: Regenerate Answer", Description: "Ask again and show what changed", Shortcut: "", Action: "regenerate"},
+		{Name: "Conversation: Toggle Execution Trace", Description: "Expand or collapse the tool calls behind the last answer", Shortcut: "", Action: "toggle_trace"},
+		{Name: "Conversation: Pin Last Answer", Description: "Pin the latest answer for quick reference", Shortcut: "", Action: "pin_last"},
+		{Name: "Conversation: Toggle Pinned Panel", Description: "Expand or collapse pinned messages", Shortcut: "", Action: "pins_toggle"},
+		{Name: "Conversation: List Tags", Description: "Show conversation tags and how often each is used", Shortcut: "", Action: "tag_list"},
+		{Name: "Diagnostics: Dead Letters", Description: "Server events that couldn't be parsed or routed", Shortcut: "", Action: "dead_letters"},
+		{Name: "Status: Dismiss Critical Updates", Description: "Unpin the critical updates at the top of the status pane", Shortcut: "", Action: "dismiss_alerts"},
+		{Name: "View: Event Cues", Description: "What received messages, errors, and finished plans ring, flash, or toast", Shortcut: "", Action: "cues"},
+		{Name: "Status: Subscriptions", Description: "Choose which status categories this conversation receives", Shortcut: "", Action: "subscriptions"},
+		{Name: "Status: Category Colors", Description: "Show the status category legend and re-color categories", Shortcut: "", Action: "status_colors"},
+		{Name: "Status: Toggle Info Updates", Description: "Show or collapse runs of info updates", Shortcut: "", Action: "status_info"},
+		{Name: "Display: Chat Settings", Description: "Show timestamp, clock, compact, and glyph settings", Shortcut: "", Action: "display"},
+		{Name: "Display: Relative Timestamps", Description: "Show chat times as \"5m ago\"", Shortcut: "", Action: "display_relative"},
+		{Name: "Display: Toggle Compact Chat", Description: "Hide author lines of consecutive messages from the same role", Shortcut: "", Action: "display_compact"},
 		{Name: "Settings", Description: "Open settings", Shortcut: "Ctrl+,", Action: "settings"},
 		{Name: "Help", Description: "Show help", Shortcut: "Ctrl+H", Action: "help"},
+		{Name: "Tutorial", Description: "Take a guided tour of the TUI", Shortcut: "", Action: "tutorial"},
 		// Model selection commands
+		{Name: "Model: Switch...", Description: "Pick a model by context size, cost, and latency", Shortcut: "Alt+M", Action: "model_switcher"},
 		{Name: "Model: Default", Description: "Use system default model", Shortcut: "", Action: "model_default"},
 		{Name: "Model: GPT-4", Description: "Use OpenAI GPT-4", Shortcut: "", Action: "model_gpt4"},
 		{Name: "Model: GPT-3.5 Turbo", Description: "Use OpenAI GPT-3.5 Turbo", Shortcut: "", Action: "model_gpt35"},
@@ -45,12 +100,17 @@ func NewCommandPalette() CommandPalette {
 		{Name: "Model: Mistral", Description: "Use Ollama Mistral (local)", Shortcut: "", Action: "model_mistral"},
 		{Name: "Model: CodeLlama", Description: "Use Ollama CodeLlama (local)", Shortcut: "", Action: "model_codellama"},
 		// Provider commands
+		{Name: "Provider: Credentials", Description: "List the provider credentials stored on the server", Shortcut: "", Action: "provider_credentials_list"},
 		{Name: "Provider: Set Custom", Description: "Set a custom provider", Shortcut: "", Action: "set_provider_prompt"},
 		// Authentication commands
+		{Name: "Auth: Log In", Description: "Log in with username and password", Shortcut: "", Action: "login_form"},
+		{Name: "Auth: Create Account", Description: "Register a new account", Shortcut: "", Action: "register_form"},
+		{Name: "Auth: Change Password", Description: "Change your password", Shortcut: "", Action: "password_form"},
+		{Name: "Admin: Server Panel", Description: "Server metrics, provider health, and maintenance", Shortcut: "", Action: "admin_panel"},
 		{Name: "Auth: Check Status", Description: "Check authentication status", Shortcut: "", Action: "auth_status"},
 		{Name: "Auth: Logout", Description: "Logout from server", Shortcut: "", Action: "auth_logout"},
-		{Name: "Auth: Generate API Key", Description: "Generate new API key", Shortcut: "", Action: "auth_apikey_generate"},
-		{Name: "Auth: List API Keys", Description: "List all API keys", Shortcut: "", Action: "auth_apikey_list"},
+		{Name: "Auth: Generate API Key", Description: "Generate an API key with a name, lifetime, and scopes", Shortcut: "", Action: "auth_apikey_generate"},
+		{Name: "Auth: List API Keys", Description: "API keys with usage counts, sortable", Shortcut: "", Action: "auth_apikey_list"},
 	}
 	
 	return CommandPalette{
@@ -111,6 +171,9 @@ func (cp CommandPalette) View() string {
 		}
 		
 		line := prefix + cmd.Name
+		if cmd.Locked {
+			line = prefix + lockIcon + " " + cmd.Name
+		}
 		if cmd.Shortcut != "" {
 			line += " (" + cmd.Shortcut + ")"
 		}
@@ -140,4 +203,12 @@ func (cp *CommandPalette) Show() {
 // Hide hides the command palette
 func (cp *CommandPalette) Hide() {
 	cp.visible = false
-}
\ No newline at end of file
+}
+// SetPermissions locks the commands the signed-in user may not run
+func (cp *CommandPalette) SetPermissions(permissions phoenix.Permissions) {
+	for i := range cp.commands {
+		permission, ok := commandPermissions[cp.commands[i].Action]
+		cp.commands[i].Locked = ok && !permissions.Has(permission)
+	}
+	cp.filtered = cp.commands
+}
@@ -0,0 +1,129 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rubber_duck/tui/internal/clock"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// scheduler returns the phoenix client's command scheduler, nil without a
+// client
+func (m Model) scheduler() *phoenix.Scheduler {
+	if client, ok := m.phoenixClient.(*phoenix.Client); ok {
+		return client.Channels().Scheduler()
+	}
+	return nil
+}
+
+// applyCommandLimits sets the concurrent commands allowed per category
+func (m *Model) applyCommandLimits() {
+	scheduler, registry := m.scheduler(), m.metrics
+	if scheduler != nil {
+		scheduler.SetLimits(m.config.TUI.CommandLimits)
+	}
+	// Slots held for answers are freed as requests resolve, and the round
+	// trips timed
+	m.requests.onDone = func(request pendingRequest) {
+		if scheduler != nil {
+			scheduler.Finish(request.ID)
+		}
+		observeRequest(registry, request)
+	}
+}
+
+// handleCommandQueue keeps the latest queue snapshot and says where a
+// waiting message is in line
+func (m *Model) handleCommandQueue(msg phoenix.CommandQueueMsg) {
+	if msg.Seq < m.commandQueue.Seq {
+		return
+	}
+	m.commandQueue = msg
+	for _, queued := range msg.Queued {
+		if queued.Category == phoenix.CategoryChat {
+			m.statusBar = fmt.Sprintf("Message queued: #%d in line (/queue)", queued.Position)
+			return
+		}
+	}
+}
+
+// renderQueueIndicator renders the number of commands waiting, e.g. "⧗ 3
+// queued"
+func (m Model) renderQueueIndicator() string {
+	if len(m.commandQueue.Queued) == 0 {
+		return ""
+	}
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color("214")).
+		Render(fmt.Sprintf("⧗ %d queued", len(m.commandQueue.Queued)))
+}
+
+// formatCommandQueue lists the running and queued commands with the limits
+func (m Model) formatCommandQueue() string {
+	scheduler := m.scheduler()
+	if scheduler == nil {
+		return "No server connection, so nothing is queued."
+	}
+	state := scheduler.Snapshot()
+
+	var b strings.Builder
+	b.WriteString("Running (limit):\n")
+	categories := []string{phoenix.CategoryChat, phoenix.CategoryFiles, phoenix.CategoryAnalysis, phoenix.CategoryTotal}
+	for _, category := range categories {
+		limit := "unlimited"
+		if n := scheduler.Limit(category); n >= 0 {
+			limit = strconv.Itoa(n)
+		}
+		fmt.Fprintf(&b, "  %-9s %d (%s)\n", category, state.Running[category], limit)
+	}
+
+	if len(state.Queued) == 0 {
+		b.WriteString("\nNothing queued.")
+		return b.String()
+	}
+	b.WriteString("\nQueued:\n")
+	sort.Slice(state.Queued, func(i, j int) bool { return state.Queued[i].Position < state.Queued[j].Position })
+	for _, queued := range state.Queued {
+		priority := ""
+		if queued.Background {
+			priority = " (background)"
+		}
+		label := ""
+		if queued.Label != "" {
+			label = fmt.Sprintf(" %q", truncateStage(strings.Join(strings.Fields(queued.Label), " "), 40))
+		}
+		fmt.Fprintf(&b, "  #%d %s %s%s%s, waiting %s\n", queued.Position, queued.Category, queued.Event, label, priority,
+			clock.Since(queued.QueuedAt).Round(time.Second))
+	}
+	b.WriteString("\n/queue bump <#> moves a command to the front; /queue cancel <#> drops it.")
+	return b.String()
+}
+
+// changeQueue bumps or cancels the command at a queue position
+func (m *Model) changeQueue(action, position string) {
+	scheduler := m.scheduler()
+	n, err := strconv.Atoi(strings.TrimPrefix(position, "#"))
+	if scheduler == nil || err != nil {
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("No queued command #%s", position), nil)
+		return
+	}
+	for _, queued := range scheduler.Snapshot().Queued {
+		if queued.Position != n {
+			continue
+		}
+		if action == "bump" && scheduler.Bump(queued.ID) {
+			m.statusBar = fmt.Sprintf("Moved %s to the front of the queue", queued.Event)
+			return
+		}
+		if action == "cancel" && scheduler.Cancel(queued.ID) {
+			m.statusBar = fmt.Sprintf("Dropped %s from the queue", queued.Event)
+			return
+		}
+	}
+	m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("No queued command #%s", position), nil)
+}
@@ -0,0 +1,386 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxAttachmentSize limits how much of a file is attached to a prompt
+const maxAttachmentSize = 256 * 1024
+
+// ComposeModal is a full-screen editor for writing long prompts
+type ComposeModal struct {
+	editor      textarea.Model
+	pathInput   textinput.Model
+	attachments []string
+	fetched     map[string][]byte // Attachments read from the server's files, by path
+	dir         string            // Relative attachment paths resolve against it; "" is the working directory
+	visible     bool
+	preview     bool
+	addingPath  bool
+	err         string
+	width       int
+	height      int
+}
+
+// NewComposeModal creates a new compose modal
+func NewComposeModal() ComposeModal {
+	editor := textarea.New()
+	editor.Placeholder = "Write your prompt... (Markdown supported)"
+	editor.ShowLineNumbers = false
+	editor.CharLimit = 0
+	editor.MaxHeight = 0
+
+	pathInput := textinput.New()
+	pathInput.Placeholder = "path/to/file"
+	pathInput.Prompt = "Attach: "
+
+	return ComposeModal{
+		editor:    editor,
+		pathInput: pathInput,
+		width:     80,
+		height:    24,
+	}
+}
+
+// Show displays the compose modal seeded with an existing draft
+func (cm *ComposeModal) Show(draft string) {
+	cm.visible = true
+	cm.preview = false
+	cm.addingPath = false
+	cm.err = ""
+	cm.editor.SetValue(draft)
+	cm.editor.Focus()
+}
+
+// Hide hides the compose modal
+func (cm *ComposeModal) Hide() {
+	cm.visible = false
+	cm.editor.Blur()
+	cm.pathInput.Blur()
+}
+
+// IsVisible returns whether the compose modal is visible
+func (cm ComposeModal) IsVisible() bool {
+	return cm.visible
+}
+
+// Draft returns the current text in the editor
+func (cm ComposeModal) Draft() string {
+	return cm.editor.Value()
+}
+
+// SetSize updates the modal dimensions
+func (cm *ComposeModal) SetSize(width, height int) {
+	cm.width = width
+	cm.height = height
+	cm.editor.SetWidth(width - 4)
+	// Leave room for title, attachment list, and footer
+	cm.editor.SetHeight(height - 8 - len(cm.attachments))
+	cm.pathInput.Width = width - 14
+}
+
+// Update handles compose modal input
+func (cm ComposeModal) Update(msg tea.Msg) (ComposeModal, tea.Cmd) {
+	if !cm.visible {
+		return cm, nil
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		var cmd tea.Cmd
+		cm.editor, cmd = cm.editor.Update(msg)
+		return cm, cmd
+	}
+
+	// Attachment path entry captures all keys until confirmed or cancelled
+	if cm.addingPath {
+		switch keyMsg.String() {
+		case "enter":
+			cm.addAttachment(strings.TrimSpace(cm.pathInput.Value()))
+			cm.addingPath = false
+			cm.pathInput.Blur()
+			cm.editor.Focus()
+			return cm, nil
+		case "esc":
+			cm.addingPath = false
+			cm.pathInput.Blur()
+			cm.editor.Focus()
+			return cm, nil
+		}
+		var cmd tea.Cmd
+		cm.pathInput, cmd = cm.pathInput.Update(msg)
+		return cm, cmd
+	}
+
+	switch keyMsg.String() {
+	case "esc":
+		// Closing keeps the draft; the caller moves it back into the chat input
+		cm.Hide()
+		return cm, nil
+	case "ctrl+j", "ctrl+s":
+		// Ctrl+Enter arrives as Ctrl+J in most terminals
+		content, err := cm.buildMessage()
+		// Sending without a file the user attached would go unnoticed
+		if err != nil {
+			cm.err = fmt.Sprintf("Not sent: %v (Ctrl+X removes the last attachment)", err)
+			return cm, nil
+		}
+		if strings.TrimSpace(content) == "" {
+			cm.err = "Nothing to send"
+			return cm, nil
+		}
+		cm.editor.Reset()
+		cm.attachments = nil
+		cm.fetched = nil
+		cm.Hide()
+		return cm, func() tea.Msg {
+			return ChatMessageSentMsg{Content: content}
+		}
+	case "ctrl+t":
+		cm.preview = !cm.preview
+		return cm, nil
+	case "ctrl+o":
+		cm.addingPath = true
+		cm.err = ""
+		cm.pathInput.SetValue("")
+		cm.editor.Blur()
+		cm.pathInput.Focus()
+		return cm, nil
+	case "ctrl+x":
+		// Remove the most recently added attachment
+		if len(cm.attachments) > 0 {
+			cm.attachments = cm.attachments[:len(cm.attachments)-1]
+			cm.SetSize(cm.width, cm.height)
+		}
+		return cm, nil
+	}
+
+	// Preview is read-only
+	if cm.preview {
+		return cm, nil
+	}
+
+	var cmd tea.Cmd
+	cm.editor, cmd = cm.editor.Update(msg)
+	return cm, cmd
+}
+
+// addAttachment validates and records a file attachment
+func (cm *ComposeModal) addAttachment(path string) {
+	if path == "" {
+		return
+	}
+
+	info, err := os.Stat(cm.localPath(path))
+	if err != nil {
+		cm.err = fmt.Sprintf("Cannot attach %s: %v", path, err)
+		return
+	}
+	if info.IsDir() {
+		cm.err = fmt.Sprintf("Cannot attach %s: is a directory", path)
+		return
+	}
+	if info.Size() > maxAttachmentSize {
+		cm.err = fmt.Sprintf("Cannot attach %s: larger than %d KB", path, maxAttachmentSize/1024)
+		return
+	}
+
+	for _, existing := range cm.attachments {
+		if existing == path {
+			return
+		}
+	}
+
+	cm.attachments = append(cm.attachments, path)
+	cm.SetSize(cm.width, cm.height)
+}
+
+// addFetchedAttachment records a file already read, e.g. from the server
+func (cm *ComposeModal) addFetchedAttachment(path string, data []byte) {
+	if len(data) > maxAttachmentSize {
+		cm.err = fmt.Sprintf("Cannot attach %s: larger than %d KB", path, maxAttachmentSize/1024)
+		return
+	}
+	if _, ok := cm.fetched[path]; !ok {
+		cm.attachments = append(cm.attachments, path)
+	}
+	if cm.fetched == nil {
+		cm.fetched = make(map[string][]byte)
+	}
+	cm.fetched[path] = data
+	cm.SetSize(cm.width, cm.height)
+}
+
+// buildMessage combines the draft and attachment contents into one
+// message, failing when an attachment can no longer be read
+func (cm ComposeModal) buildMessage() (string, error) {
+	var content strings.Builder
+	content.WriteString(strings.TrimSpace(cm.editor.Value()))
+
+	for _, path := range cm.attachments {
+		data, ok := cm.fetched[path]
+		if !ok {
+			var err error
+			if data, err = os.ReadFile(cm.localPath(path)); err != nil {
+				return "", fmt.Errorf("cannot read attachment %s: %v", path, err)
+			}
+		}
+		content.WriteString(fmt.Sprintf("\n\n**Attachment:** `%s`\n", path))
+		content.WriteString(fmt.Sprintf("```%s\n%s\n```", languageForPath(path), strings.TrimRight(string(data), "\n")))
+	}
+
+	return content.String(), nil
+}
+
+// localPath returns where an attached path is on disk
+func (cm ComposeModal) localPath(path string) string {
+	if cm.dir == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(cm.dir, path)
+}
+
+// wordCount returns the number of words in the draft
+func (cm ComposeModal) wordCount() int {
+	return len(strings.Fields(cm.editor.Value()))
+}
+
+// View renders the compose modal
+func (cm ComposeModal) View() string {
+	if !cm.visible {
+		return ""
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("62"))
+
+	mode := "Edit"
+	if cm.preview {
+		mode = "Preview"
+	}
+	title := titleStyle.Render(fmt.Sprintf("◆ Compose Message (%s) ◆", mode))
+
+	// Body is either the editor or rendered markdown
+	var body string
+	if cm.preview {
+		body = cm.renderPreview()
+	} else {
+		body = cm.editor.View()
+	}
+
+	// Attachment list
+	var attachmentLines []string
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	if len(cm.attachments) == 0 {
+		attachmentLines = append(attachmentLines, dimStyle.Render("No attachments (Ctrl+O to attach a file)"))
+	} else {
+		for _, path := range cm.attachments {
+			attachmentLines = append(attachmentLines, "📎 "+path)
+		}
+	}
+	if cm.addingPath {
+		attachmentLines = append(attachmentLines, cm.pathInput.View())
+	}
+	if cm.err != "" {
+		attachmentLines = append(attachmentLines, lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(cm.err))
+	}
+
+	stats := dimStyle.Render(fmt.Sprintf("Words: %d | Chars: %d | Attachments: %d",
+		cm.wordCount(), len(cm.editor.Value()), len(cm.attachments)))
+	help := dimStyle.Render("Ctrl+Enter/Ctrl+S: Send | Ctrl+T: Preview | Ctrl+O: Attach | Ctrl+X: Remove attachment | Esc: Close")
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		"",
+		body,
+		"",
+		strings.Join(attachmentLines, "\n"),
+		stats,
+		help,
+	)
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Width(cm.width - 2).
+		Height(cm.height - 2).
+		Render(content)
+}
+
+// renderPreview renders the draft as markdown
+func (cm ComposeModal) renderPreview() string {
+	draft := cm.editor.Value()
+	if strings.TrimSpace(draft) == "" {
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Italic(true).Render("Nothing to preview")
+	}
+
+	renderer, err := glamour.NewTermRenderer(
+		glamour.WithStylePath("dark"),
+		glamour.WithWordWrap(cm.width-6),
+	)
+	if err != nil {
+		return draft
+	}
+
+	rendered, err := renderer.Render(draft)
+	if err != nil {
+		return draft
+	}
+
+	// Keep the preview within the editor area
+	lines := strings.Split(strings.TrimRight(rendered, "\n"), "\n")
+	maxLines := cm.height - 8 - len(cm.attachments)
+	if maxLines > 0 && len(lines) > maxLines {
+		lines = lines[:maxLines]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// languageForPath returns a code fence language based on the file extension
+func languageForPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ex", ".exs":
+		return "elixir"
+	case ".go":
+		return "go"
+	case ".py":
+		return "python"
+	case ".js", ".mjs", ".cjs":
+		return "javascript"
+	case ".ts", ".tsx":
+		return "typescript"
+	case ".rs":
+		return "rust"
+	case ".rb":
+		return "ruby"
+	case ".json":
+		return "json"
+	case ".yml", ".yaml":
+		return "yaml"
+	case ".toml":
+		return "toml"
+	case ".md":
+		return "markdown"
+	case ".sh", ".bash":
+		return "bash"
+	case ".sql":
+		return "sql"
+	case ".html", ".heex", ".eex":
+		return "html"
+	case ".css":
+		return "css"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestComposeModal_AttachAndRemove(t *testing.T) {
+	dir := t.TempDir()
+	code := filepath.Join(dir, "app.ex")
+	os.WriteFile(code, []byte("defmodule App do\nend\n"), 0644)
+
+	cm := NewComposeModal()
+	cm.Show("Review this")
+	cm.addAttachment(code)
+	cm.addAttachment(code)
+	cm.addAttachment(dir)
+	if len(cm.attachments) != 1 || !strings.Contains(cm.err, "is a directory") {
+		t.Fatalf("Expected one attachment and the directory refused, got %v %q", cm.attachments, cm.err)
+	}
+	cm.addAttachment(filepath.Join(dir, "missing.ex"))
+	if len(cm.attachments) != 1 || !strings.Contains(cm.err, "Cannot attach") {
+		t.Fatalf("Expected a missing file refused, got %q", cm.err)
+	}
+
+	message, err := cm.buildMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(message, "Review this\n\n**Attachment:** `"+code+"`") || !strings.Contains(message, "```elixir\ndefmodule App do\nend\n```") {
+		t.Errorf("Expected the file appended as an elixir block, got:\n%s", message)
+	}
+
+	cm, _ = cm.Update(tea.KeyMsg{Type: tea.KeyCtrlX})
+	if len(cm.attachments) != 0 {
+		t.Fatal("Expected Ctrl+X to remove the attachment")
+	}
+}
+
+func TestComposeModal_WordCount(t *testing.T) {
+	cm := NewComposeModal()
+	for draft, want := range map[string]int{
+		"":                           0,
+		"one":                        1,
+		"  two words  ":              2,
+		"lines\nand\ttabs count too": 5,
+	} {
+		cm.Show(draft)
+		if got := cm.wordCount(); got != want {
+			t.Errorf("wordCount(%q) = %d, want %d", draft, got, want)
+		}
+	}
+}
+
+func TestComposeModal_UnreadableAttachmentBlocksSending(t *testing.T) {
+	dir := t.TempDir()
+	notes := filepath.Join(dir, "notes.md")
+	os.WriteFile(notes, []byte("# Notes"), 0644)
+
+	cm := NewComposeModal()
+	cm.Show("See the notes")
+	cm.addAttachment(notes)
+	os.Remove(notes)
+
+	if _, err := cm.buildMessage(); err == nil {
+		t.Fatal("Expected a vanished attachment to fail the message")
+	}
+	cm, cmd := cm.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	if cmd != nil || !cm.IsVisible() || !strings.Contains(cm.err, "cannot read attachment") {
+		t.Fatalf("Expected sending blocked with an error, got %q", cm.err)
+	}
+	if cm.Draft() != "See the notes" || len(cm.attachments) != 1 {
+		t.Error("Expected the draft and attachments kept")
+	}
+}
@@ -4,15 +4,44 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+
+	"github.com/rubber_duck/tui/internal/phoenix"
 )
 
 // Config represents the TUI configuration
 type Config struct {
-	APIKey          string                    `json:"api_key,omitempty"`
-	DefaultProvider string                    `json:"default_provider,omitempty"`
-	DefaultModel    string                    `json:"default_model,omitempty"`
-	Providers       map[string]ProviderConfig `json:"providers"`
-	TUI             TUIConfig                 `json:"tui"`
+	APIKey             string                            `json:"api_key,omitempty"`
+	DefaultProvider    string                            `json:"default_provider,omitempty"`
+	DefaultModel       string                            `json:"default_model,omitempty"`
+	DefaultTemperature *float64                          `json:"default_temperature,omitempty"`
+	FallbackChain      []ModelRef                        `json:"fallback_chain,omitempty"`
+	AutoFallback       bool                              `json:"auto_fallback,omitempty"`
+	Pricing            map[string]ModelPrice             `json:"pricing,omitempty"`
+	Budget             BudgetConfig                      `json:"budget,omitempty"`
+	Timeouts           TimeoutConfig                     `json:"timeouts,omitempty"`
+	Telemetry          TelemetryConfig                   `json:"telemetry,omitempty"`
+	Metrics            MetricsConfig                     `json:"metrics,omitempty"`
+	OllamaURL          string                            `json:"ollama_url,omitempty"`
+	Network            phoenix.NetworkOptions            `json:"network,omitempty"`          // Proxy, TLS, compression, and keepalive options for the sockets
+	NetworkProfiles    map[string]phoenix.NetworkOptions `json:"network_profiles,omitempty"` // Named overrides of network, e.g. for a slow or mobile link
+	NetworkProfile     string                            `json:"network_profile,omitempty"`  // The profile used unless -network-profile picks another
+	KnownServers       map[string]KnownServer            `json:"known_servers,omitempty"`    // Servers trusted on first connect, by host:port
+	Permissions        map[string]string                 `json:"permissions,omitempty"`      // Remembered answers to local permission prompts: "allow" or "deny" per action
+	Providers          map[string]ProviderConfig         `json:"providers"`
+	TUI                TUIConfig                         `json:"tui"`
+
+	project *projectOverlay // Settings merged from the project file
+}
+
+// ModelRef identifies a model on a specific provider
+type ModelRef struct {
+	Provider string `json:"provider"`
+	Model    string `json:"model"`
+}
+
+// String returns the provider/model form of the reference
+func (r ModelRef) String() string {
+	return r.Provider + "/" + r.Model
 }
 
 // ProviderConfig represents provider configuration
@@ -24,10 +53,60 @@ type ProviderConfig struct {
 // TUIConfig represents TUI-specific configuration
 type TUIConfig struct {
 	StatusCategoryColors map[string]string `json:"status_category_colors"`
+	Spellcheck           bool              `json:"spellcheck,omitempty"`
+	SpellcheckDictionary string            `json:"spellcheck_dictionary,omitempty"`
+	PromptLint           bool              `json:"prompt_lint,omitempty"`
+	FileSource           string            `json:"file_source,omitempty"`           // "local" (default) or "server"
+	SyncTags             bool              `json:"sync_tags,omitempty"`             // Send conversation tags to the server
+	SkipConfirmations    bool              `json:"skip_confirmations,omitempty"`    // Run destructive commands without asking
+	SecretPatterns       []string          `json:"secret_patterns,omitempty"`       // Extra regexps for secrets to mask before sending
+	AllowSecrets         bool              `json:"allow_secrets,omitempty"`         // Send messages without checking for secrets
+	ResponseFilters      []string          `json:"response_filters,omitempty"`      // Filters run over responses, in order; unset uses the defaults
+	ResponseMaxLines     int               `json:"response_max_lines,omitempty"`    // Where the truncate filter cuts responses off
+	CollapseLines        int               `json:"collapse_lines,omitempty"`        // Messages taller than this render collapsed; negative never collapses
+	FormatOnSave         bool              `json:"format_on_save,omitempty"`        // Format files with gofmt/prettier/black or the server before saving
+	StatusNotify         map[string]string `json:"status_notify,omitempty"`         // Per-priority notifications for status updates: "bell", "flash", "chat", or "none"
+	Cues                 map[string]string `json:"cues,omitempty"`                  // Per-event cues ("message_received", "error", "plan_done"): "bell", "flash", "toast", or "none"
+	TimestampStyle       string            `json:"timestamp_style,omitempty"`       // Chat timestamps: "absolute" (default), "relative", or "off"
+	Clock12h             bool              `json:"clock_12h,omitempty"`             // Show chat times on a 12-hour clock
+	CompactChat          bool              `json:"compact_chat,omitempty"`          // Hide the author line of consecutive messages from the same role
+	RoleGlyphs           map[string]string `json:"role_glyphs,omitempty"`           // Glyph shown before each role's label: "user", "assistant", "system", "error"
+	ProviderPollSeconds  int               `json:"provider_poll_seconds,omitempty"` // How often the active provider's health is checked; 0 is every minute, negative never
+	BlockedCommands      []string          `json:"blocked_commands,omitempty"`      // Slash commands or palette actions refused, usually set by the project
+	CommandLimits        map[string]int    `json:"command_limits,omitempty"`        // Concurrent server commands per category ("chat", "files", "analysis", "total"); negative is unlimited
+	AuditLogOff          bool              `json:"audit_log_off,omitempty"`         // Don't record executed commands in ~/.rubber_duck/audit.log
+	CacheTTLSeconds      map[string]int    `json:"cache_ttl_seconds,omitempty"`     // How long answers to "list_models", "get_provider_status", and "list_files" are reused; negative never caches
+	TranscriptBudgetKB   int               `json:"transcript_budget_kb,omitempty"`  // Kilobytes of the newest messages drawn in full; older ones are drawn as one line. 0 is 1024, negative draws everything
+	IdleLockMinutes      int               `json:"idle_lock_minutes,omitempty"`     // Lock the screen after this many minutes without input; 0 never locks
+	LockPINHash          string            `json:"lock_pin_hash,omitempty"`         // Salted hash of the PIN that unlocks the screen, set with /lock pin
+	Encryption           string            `json:"encryption,omitempty"`            // Encrypt saved conversations and history with a "keychain" or "passphrase" key; unset stores plaintext
+	Layout               LayoutConfig      `json:"layout,omitempty"`                // Panes shown at startup
+	Links                LinkConfig        `json:"links,omitempty"`                 // What link hints do
 }
 
-// LoadConfig loads configuration from the user's config file
+// LoadConfig loads configuration from the user's config file, overridden by
+// the project's .rubber_duck.toml when the working directory has one
 func LoadConfig() (*Config, error) {
+	return loadConfigFor(".")
+}
+
+// loadConfigFor loads the configuration for the project in dir
+func loadConfigFor(dir string) (*Config, error) {
+	config, err := loadGlobalConfig()
+	if err != nil {
+		return nil, err
+	}
+	if err := mergeProjectConfig(config, dir); err != nil {
+		return nil, err
+	}
+	if config.TUI.StatusCategoryColors == nil {
+		config.TUI.StatusCategoryColors = make(map[string]string)
+	}
+	return config, nil
+}
+
+// loadGlobalConfig loads ~/.rubber_duck/config.json
+func loadGlobalConfig() (*Config, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return nil, err
@@ -91,8 +170,14 @@ func SaveConfig(config *Config) error {
 		return err
 	}
 	
+	// Project settings stay in the project file
+	global, err := config.globalView()
+	if err != nil {
+		return err
+	}
+	
 	// Marshal config to JSON with indentation
-	data, err := json.MarshalIndent(config, "", "  ")
+	data, err := json.MarshalIndent(global, "", "  ")
 	if err != nil {
 		return err
 	}
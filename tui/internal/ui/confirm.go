@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// destructiveCommand is the confirmation a destructive command asks for
+type destructiveCommand struct {
+	Title  string
+	Prompt func(args map[string]string) string
+}
+
+// destructiveCommands marks the ExecuteCommandMsg commands that ask before
+// running, however they were started (slash command, palette, or shortcut)
+var destructiveCommands = map[string]destructiveCommand{
+	"new_conversation": {
+		Title: "Clear conversation",
+		Prompt: func(map[string]string) string {
+			return "Clear the chat and start a new conversation?"
+		},
+	},
+	"auth_apikey_revoke": {
+		Title: "Revoke API key",
+		Prompt: func(args map[string]string) string {
+			return fmt.Sprintf("Revoke API key %s?\n\nClients using it stop working. This can't be undone.", args["id"])
+		},
+	},
+	"provider_credentials_remove": {
+		Title: "Remove provider credentials",
+		Prompt: func(args map[string]string) string {
+			return fmt.Sprintf("Remove the %s credentials stored on the server?\n\nRequests to %s fail until new ones are set.", args["provider"], args["provider"])
+		},
+	},
+	"react_abort": {
+		Title: "Abort ReAct execution",
+		Prompt: func(map[string]string) string {
+			return "Abort the running ReAct execution? It stops after the current step."
+		},
+	},
+	"cancel_planning": {
+		Title: "Cancel planning",
+		Prompt: func(map[string]string) string {
+			return "Cancel the planning session? Its progress is lost."
+		},
+	},
+}
+
+// needsConfirmation reports whether a command must be confirmed before it
+// runs; skip_confirmations in the config turns the prompts off
+func (m Model) needsConfirmation(msg ExecuteCommandMsg) bool {
+	if msg.Confirmed || (m.config != nil && m.config.TUI.SkipConfirmations) {
+		return false
+	}
+	_, ok := destructiveCommands[msg.Command]
+	return ok
+}
+
+// confirmCommand asks before running a destructive command; confirming
+// sends it again marked as confirmed
+func (m *Model) confirmCommand(msg ExecuteCommandMsg) {
+	destructive := destructiveCommands[msg.Command]
+	msg.Confirmed = true
+	m.modal.ShowConfirm(destructive.Title, destructive.Prompt(msg.Args), func() tea.Msg { return msg })
+}
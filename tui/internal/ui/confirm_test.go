@@ -0,0 +1,45 @@
+package ui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+func TestDestructiveCommandsAskFirst(t *testing.T) {
+	testutil.IsolateHome(t)
+	var model Model = *NewModel()
+	run := func(msg tea.Msg) {
+		updated, cmd := model.Update(msg)
+		model = updated.(Model)
+		if cmd != nil {
+			if next, ok := cmd().(ExecuteCommandMsg); ok {
+				updated, _ = model.Update(next)
+				model = updated.(Model)
+			}
+		}
+	}
+
+	run(ExecuteCommandMsg{Command: "cancel_planning"})
+	if !model.modal.IsVisible() {
+		t.Fatal("Expected cancelling planning to ask for confirmation")
+	}
+	run(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if model.statusBar == "Cancelling planning session..." {
+		t.Fatal("Declining should not cancel planning")
+	}
+
+	run(ExecuteCommandMsg{Command: "cancel_planning"})
+	run(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if model.statusBar != "Cancelling planning session..." {
+		t.Fatalf("Expected confirming to cancel planning, got status %q", model.statusBar)
+	}
+
+	model.config.TUI.SkipConfirmations = true
+	model.statusBar = ""
+	run(ExecuteCommandMsg{Command: "cancel_planning"})
+	if model.modal.IsVisible() || model.statusBar != "Cancelling planning session..." {
+		t.Fatal("Expected skip_confirmations to run the command without asking")
+	}
+}
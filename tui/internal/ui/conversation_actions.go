@@ -0,0 +1,130 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// ConversationActionMsg applies a confirmed archive, unarchive, or delete
+type ConversationActionMsg struct {
+	Action string // "archive", "unarchive", or "delete"
+	ID     string
+}
+
+// resolveConversation finds the conversation a command refers to: a saved
+// conversation by number or ID, or the current one when ref is empty
+func (m *Model) resolveConversation(ref string) (id, title string, err error) {
+	if ref != "" {
+		conv, err := LoadSavedConversation(ref)
+		if err != nil {
+			return "", "", err
+		}
+		return conv.ID, conv.Title, nil
+	}
+	messages := m.chat.GetMessages()
+	if len(messages) == 0 && m.conversationID == "" {
+		return "", "", fmt.Errorf("the current conversation is empty")
+	}
+	return m.savedConversationID(), conversationTitle(messages), nil
+}
+
+// confirmConversationAction asks before archiving or deleting a conversation
+func (m *Model) confirmConversationAction(action, ref string) {
+	id, title, err := m.resolveConversation(ref)
+	if err != nil {
+		m.statusMessages.AddMessage(StatusCategoryError, err.Error(), nil)
+		return
+	}
+
+	confirmed := func() tea.Msg { return ConversationActionMsg{Action: action, ID: id} }
+	if action == "delete" {
+		m.modal.ShowConfirm("Delete conversation", fmt.Sprintf("Delete %q?\n\nIts saved copy is removed and the server forgets it. This can't be undone.", title), confirmed)
+		return
+	}
+	m.modal.ShowConfirm("Archive conversation", fmt.Sprintf("Archive %q?\n\nIt is hidden from /saved. /saved archived lists it and /conversation unarchive <id> brings it back.", title), confirmed)
+}
+
+// applyConversationAction archives, unarchives, or deletes a conversation
+// locally and on the server, starting a fresh conversation when the current
+// one goes away
+func (m *Model) applyConversationAction(msg ConversationActionMsg) tea.Cmd {
+	if msg.Action == "unarchive" {
+		conv, err := LoadSavedConversation(msg.ID)
+		if err != nil {
+			m.statusMessages.AddMessage(StatusCategoryError, err.Error(), nil)
+			return nil
+		}
+		msg.ID = conv.ID
+	}
+	current := msg.ID == m.savedConversationID()
+
+	var err error
+	switch msg.Action {
+	case "archive", "unarchive":
+		if current {
+			m.autosaveConversation()
+		}
+		var conv *SavedConversation
+		conv, err = loadConversationByID(msg.ID)
+		if err == nil {
+			conv.Archived = msg.Action == "archive"
+			err = SaveConversation(*conv)
+		} else if os.IsNotExist(err) {
+			err = nil // Nothing saved locally yet
+		}
+	case "delete":
+		err = DeleteSavedConversation(msg.ID)
+	}
+	if err != nil {
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Failed to %s conversation: %v", msg.Action, err), nil)
+		return nil
+	}
+
+	var cmds []tea.Cmd
+	client, connected := m.phoenixClient.(*phoenix.Client)
+	connected = connected && m.connected
+	if connected && !strings.HasPrefix(msg.ID, "local-") {
+		if msg.Action == "delete" {
+			cmds = append(cmds, client.DeleteConversation(msg.ID))
+		} else {
+			cmds = append(cmds, client.ArchiveConversation(msg.ID, msg.Action == "archive"))
+		}
+	}
+
+	if current && msg.Action != "unarchive" {
+		m.conversationID = ""
+		m.resetChat()
+		if connected {
+			cmds = append(cmds, client.StartNewConversation())
+		}
+	}
+
+	switch msg.Action {
+	case "archive":
+		m.statusBar = fmt.Sprintf("Archived conversation %s", msg.ID)
+	case "unarchive":
+		m.statusBar = fmt.Sprintf("Restored conversation %s", msg.ID)
+	default:
+		m.statusBar = fmt.Sprintf("Deleted conversation %s", msg.ID)
+	}
+	return tea.Batch(cmds...)
+}
+
+// formatArchivedConversations renders the list shown by /saved archived
+func formatArchivedConversations(conversations []SavedConversation) string {
+	if len(conversations) == 0 {
+		return "No archived conversations."
+	}
+
+	var b strings.Builder
+	b.WriteString("Archived conversations:\n\n")
+	for _, conv := range conversations {
+		fmt.Fprintf(&b, "  %s  %s  (%d messages, %s)\n", conv.ID, conv.Title, len(conv.Messages), conv.UpdatedAt.Format("2006-01-02 15:04"))
+	}
+	b.WriteString("\nUse /conversation unarchive <id> to restore one")
+	return b.String()
+}
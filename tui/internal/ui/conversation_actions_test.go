@@ -0,0 +1,91 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+func TestArchiveAndDeleteSavedConversations(t *testing.T) {
+	testutil.IsolateHome(t)
+	for i, id := range []string{"local-older", "local-newer"} {
+		conv := SavedConversation{ID: id, Title: id, UpdatedAt: time.Unix(int64(i), 0), Messages: []ChatMessage{{Type: UserMessage, Content: id}}}
+		if err := SaveConversation(conv); err != nil {
+			t.Fatalf("SaveConversation: %v", err)
+		}
+	}
+
+	var model Model = *NewModel()
+	run := func(msg tea.Msg) {
+		updated, cmd := model.Update(msg)
+		model = updated.(Model)
+		for cmd != nil {
+			next := cmd()
+			if next == nil {
+				return
+			}
+			updated, cmd = model.Update(next)
+			model = updated.(Model)
+		}
+	}
+
+	run(ExecuteCommandMsg{Command: "conversation_archive", Args: map[string]string{"ref": "1"}})
+	if !model.modal.IsVisible() {
+		t.Fatalf("Expected archiving to ask for confirmation")
+	}
+	run(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+
+	active, _ := ListSavedConversations()
+	archived, _ := ListArchivedConversations()
+	if len(active) != 1 || active[0].ID != "local-older" || len(archived) != 1 || archived[0].ID != "local-newer" {
+		t.Fatalf("Expected local-newer archived, got active %v archived %v", active, archived)
+	}
+
+	run(ExecuteCommandMsg{Command: "conversation_unarchive", Args: map[string]string{"ref": "local-newer"}})
+	if active, _ = ListSavedConversations(); len(active) != 2 {
+		t.Fatalf("Expected unarchive to restore the conversation, got %d active", len(active))
+	}
+
+	run(ExecuteCommandMsg{Command: "conversation_delete", Args: map[string]string{"ref": "2"}})
+	run(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if active, _ = ListSavedConversations(); len(active) != 2 {
+		t.Fatalf("Expected declining to keep the conversation, got %d", len(active))
+	}
+	run(ExecuteCommandMsg{Command: "conversation_delete", Args: map[string]string{"ref": "2"}})
+	run(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if active, _ = ListSavedConversations(); len(active) != 1 || active[0].ID != "local-newer" {
+		t.Fatalf("Expected local-older deleted, got %v", active)
+	}
+}
+
+func TestSaveConversation_PrivateFilesAndSafeIDs(t *testing.T) {
+	testutil.IsolateHome(t)
+	conv := SavedConversation{ID: "local-1", Title: "private", UpdatedAt: time.Unix(1, 0), Messages: []ChatMessage{{Type: UserMessage, Content: "private"}}}
+	if err := SaveConversation(conv); err != nil {
+		t.Fatalf("SaveConversation: %v", err)
+	}
+	path, _ := conversationPath(conv.ID)
+	for p, want := range map[string]os.FileMode{path: 0600, filepath.Dir(path): 0700} {
+		info, err := os.Stat(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != want {
+			t.Errorf("Expected %s to be %o, got %o", p, want, info.Mode().Perm())
+		}
+	}
+
+	for _, id := range []string{"../escaped", "nested/id", `nested\id`, "..", ""} {
+		conv.ID = id
+		if err := SaveConversation(conv); err == nil {
+			t.Errorf("Expected ID %q to be refused", id)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(filepath.Dir(path)), "escaped.json")); err == nil {
+		t.Error("Expected nothing written outside the conversations directory")
+	}
+}
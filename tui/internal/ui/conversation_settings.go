@@ -0,0 +1,205 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// defaultTemperature is the temperature used when the config sets none
+const defaultTemperature = 0.7
+
+// conversationSettings are the model settings one conversation uses instead
+// of the global defaults; empty fields inherit the default
+type conversationSettings struct {
+	Model       string   `json:"model,omitempty"`
+	Provider    string   `json:"provider,omitempty"`
+	Temperature *float64 `json:"temperature,omitempty"`
+	Prefix      string   `json:"prefix,omitempty"` // Added before each message sent
+	Suffix      string   `json:"suffix,omitempty"` // Added after each message sent
+	// Status categories unsubscribed from in this conversation
+	MutedCategories []string `json:"muted_categories,omitempty"`
+}
+
+// IsZero reports whether the conversation inherits every setting
+func (s conversationSettings) IsZero() bool {
+	return s.Model == "" && s.Provider == "" && s.Temperature == nil && s.Prefix == "" && s.Suffix == "" && len(s.MutedCategories) == 0
+}
+
+// String summarizes the overridden settings, e.g. "gpt-4 · openai · temp 0.2"
+func (s conversationSettings) String() string {
+	var parts []string
+	if s.Model != "" {
+		parts = append(parts, s.Model)
+	}
+	if s.Provider != "" {
+		parts = append(parts, s.Provider)
+	}
+	if s.Temperature != nil {
+		parts = append(parts, "temp "+formatTemperature(*s.Temperature))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// formatTemperature formats a temperature without trailing zeros
+func formatTemperature(t float64) string {
+	return strconv.FormatFloat(t, 'f', -1, 64)
+}
+
+// parseTemperature reads a temperature between 0 and 2
+func parseTemperature(raw string) (float64, error) {
+	t, err := strconv.ParseFloat(raw, 64)
+	if err != nil || t < 0 || t > 2 {
+		return 0, fmt.Errorf("invalid temperature %q: use a number from 0 to 2", raw)
+	}
+	return t, nil
+}
+
+// currentSettings returns the current conversation's overrides, loading them
+// from its saved copy when the conversation changed
+func (m *Model) currentSettings() conversationSettings {
+	id := m.savedConversationID()
+	if m.settingsFor != id {
+		m.settingsFor = id
+		if conv, err := loadConversationByID(id); err == nil && conv.Settings != nil {
+			m.conversationSettings = *conv.Settings
+		} else if m.chat.GetMessageCount() == 0 {
+			// A fresh conversation starts on the defaults; one with messages
+			// that was just given its server ID keeps what it had
+			m.conversationSettings = conversationSettings{}
+		}
+		m.chat.SetPromptAffixes(m.conversationSettings.Prefix, m.conversationSettings.Suffix)
+	}
+	return m.conversationSettings
+}
+
+// activeModel returns the model the current conversation talks to
+func (m *Model) activeModel() string {
+	if model := m.currentSettings().Model; model != "" {
+		return model
+	}
+	return m.currentModel
+}
+
+// activeProvider returns the provider the current conversation talks to
+func (m *Model) activeProvider() string {
+	if provider := m.currentSettings().Provider; provider != "" {
+		return provider
+	}
+	return m.currentProvider
+}
+
+// activeTemperature returns the current conversation's temperature
+func (m *Model) activeTemperature() float64 {
+	if t := m.currentSettings().Temperature; t != nil {
+		return *t
+	}
+	return m.temperature
+}
+
+// updateSettings changes the current conversation's overrides and saves them
+// with it
+func (m *Model) updateSettings(change func(*conversationSettings)) {
+	settings := m.currentSettings()
+	change(&settings)
+	m.conversationSettings = settings
+	m.chat.SetPromptAffixes(settings.Prefix, settings.Suffix)
+	m.autosaveConversation()
+	m.tokenLimit = GetModelTokenLimit(m.activeModel())
+	m.updateHeaderState()
+}
+
+// setModel changes the model (and provider, when given) of the current
+// conversation, or the global default; "default" or "none" goes back to
+// inheriting, or for the default, to the server's choice
+func (m *Model) setModel(model, provider string, global bool) {
+	inherit := model == "default" || model == "none"
+	if global {
+		if inherit {
+			model, provider = "", ""
+		}
+		m.currentModel = model
+		if provider != "" || inherit {
+			m.currentProvider = provider
+		}
+		m.config.DefaultModel = m.currentModel
+		m.config.DefaultProvider = m.currentProvider
+		m.tokenLimit = GetModelTokenLimit(m.activeModel())
+		m.updateHeaderState()
+		if err := SaveConfig(m.config); err != nil {
+			m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Failed to save config: %v", err), nil)
+		}
+		switch {
+		case inherit:
+			m.statusBar = "Default model cleared - using server default"
+		case provider != "":
+			m.statusBar = fmt.Sprintf("Default model set to: %s (%s)", model, provider)
+		default:
+			m.statusBar = fmt.Sprintf("Default model set to: %s", model)
+		}
+		m.chat.AddMessage(SystemMessage, m.statusBar, "system")
+		return
+	}
+
+	m.updateSettings(func(s *conversationSettings) {
+		if inherit {
+			s.Model, s.Provider = "", ""
+			return
+		}
+		s.Model = model
+		if provider != "" {
+			s.Provider = provider
+		}
+	})
+	switch {
+	case inherit:
+		m.statusBar = fmt.Sprintf("Conversation uses the default model: %s", orServerDefault(m.activeModel()))
+	case provider != "":
+		m.statusBar = fmt.Sprintf("Model set to: %s (%s) for this conversation", model, provider)
+	default:
+		m.statusBar = fmt.Sprintf("Model set to: %s for this conversation", model)
+	}
+	m.chat.AddMessage(SystemMessage, m.statusBar+"\nUse /model --global to change the default", "system")
+}
+
+// setTemperature changes the temperature of the current conversation, or
+// the global default; "default" goes back to inheriting
+func (m *Model) setTemperature(raw string, global bool) {
+	var temperature *float64
+	if raw != "default" {
+		t, err := parseTemperature(raw)
+		if err != nil {
+			m.statusMessages.AddMessage(StatusCategoryError, err.Error(), nil)
+			return
+		}
+		temperature = &t
+	}
+
+	if global {
+		m.config.DefaultTemperature = temperature
+		m.temperature = defaultTemperature
+		if temperature != nil {
+			m.temperature = *temperature
+		}
+		if err := SaveConfig(m.config); err != nil {
+			m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Failed to save config: %v", err), nil)
+		}
+		m.statusBar = "Default temperature set to: " + formatTemperature(m.temperature)
+		return
+	}
+
+	m.updateSettings(func(s *conversationSettings) { s.Temperature = temperature })
+	if temperature == nil {
+		m.statusBar = "Conversation uses the default temperature: " + formatTemperature(m.temperature)
+	} else {
+		m.statusBar = "Temperature set to: " + formatTemperature(*temperature) + " for this conversation"
+	}
+}
+
+// orServerDefault names a model, or the server default when none is set
+func orServerDefault(model string) string {
+	if model == "" {
+		return "server default"
+	}
+	return model
+}
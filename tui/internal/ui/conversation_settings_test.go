@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+func TestConversationSettingsOverrideDefaults(t *testing.T) {
+	testutil.IsolateHome(t)
+	var model Model = *NewModel()
+	update := func(msg any) {
+		updated, _ := model.Update(msg)
+		model = updated.(Model)
+	}
+
+	update(ExecuteCommandMsg{Command: "set_model_with_provider", Args: map[string]string{"model": "gpt-4", "provider": "openai", "scope": "global"}})
+	update(ChatMessageReceivedMsg{Content: "hello", Type: "assistant"})
+	update(ExecuteCommandMsg{Command: "set_model", Args: map[string]string{"model": "claude-3-opus", "scope": "conversation"}})
+	update(ExecuteCommandMsg{Command: "set_temperature", Args: map[string]string{"temperature": "0.2", "scope": "conversation"}})
+
+	if model.activeModel() != "claude-3-opus" || model.activeProvider() != "openai" || model.activeTemperature() != 0.2 {
+		t.Fatalf("Expected the override with the inherited provider, got %s/%s at %v", model.activeProvider(), model.activeModel(), model.activeTemperature())
+	}
+	if model.currentModel != "gpt-4" || model.config.DefaultModel != "gpt-4" {
+		t.Errorf("Expected the default to stay gpt-4, got %q", model.currentModel)
+	}
+
+	conversations, err := ListSavedConversations()
+	if err != nil || len(conversations) != 1 {
+		t.Fatalf("Expected one saved conversation, got %d (%v)", len(conversations), err)
+	}
+	if list := formatSavedConversations(conversations); !strings.Contains(list, "[claude-3-opus · temp 0.2]") {
+		t.Errorf("Expected the switcher to show the override, got:\n%s", list)
+	}
+
+	// A new conversation starts on the defaults
+	model.resetChat()
+	if model.activeModel() != "gpt-4" || model.activeTemperature() != defaultTemperature {
+		t.Errorf("Expected a new conversation to inherit the defaults, got %s at %v", model.activeModel(), model.activeTemperature())
+	}
+}
+
+func TestPromptPrefixWrapsOutgoingMessages(t *testing.T) {
+	testutil.IsolateHome(t)
+	var model Model = *NewModel()
+	update := func(msg any) {
+		updated, _ := model.Update(msg)
+		model = updated.(Model)
+	}
+
+	// The text keeps its case, unlike the command name
+	cmd := model.chat.runSlashCommand("/prefix suffix Follow CONTRIBUTING.md")
+	msg := cmd().(ExecuteCommandMsg)
+	if msg.Command != "prefix_set" || msg.Args["which"] != "suffix" || msg.Args["text"] != "Follow CONTRIBUTING.md" {
+		t.Fatalf("Unexpected command %+v", msg)
+	}
+	update(msg)
+	update(ExecuteCommandMsg{Command: "prefix_set", Args: map[string]string{"which": "prefix", "text": "Answer concisely."}})
+
+	if got := model.wrapPrompt("why?"); got != "Answer concisely.\n\nwhy?\n\nFollow CONTRIBUTING.md" {
+		t.Fatalf("Unexpected wrapped prompt %q", got)
+	}
+	if !strings.Contains(model.chat.renderPromptAffixes(), "prefix: Answer concisely.") {
+		t.Error("Expected the badge to show the prefix")
+	}
+
+	update(ExecuteCommandMsg{Command: "prefix_set", Args: map[string]string{"which": "prefix", "text": "off"}})
+	if got := model.wrapPrompt("why?"); got != "why?\n\nFollow CONTRIBUTING.md" {
+		t.Fatalf("Expected off to remove only the prefix, got %q", got)
+	}
+}
@@ -0,0 +1,282 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SavedConversation is a conversation stored on disk for offline reading
+type SavedConversation struct {
+	ID        string                `json:"id"`
+	Title     string                `json:"title"`
+	UpdatedAt time.Time             `json:"updated_at"`
+	Messages  []ChatMessage         `json:"messages"`
+	Tags      []string              `json:"tags,omitempty"`
+	Archived  bool                  `json:"archived,omitempty"` // Hidden from /saved
+	Settings  *conversationSettings `json:"settings,omitempty"` // Model overrides; unset uses the defaults
+}
+
+// conversationsDir returns the directory conversations are saved in
+func conversationsDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".rubber_duck", "conversations"), nil
+}
+
+// conversationPath returns the file a conversation is saved in, refusing IDs
+// that would reach outside the conversations directory
+func conversationPath(id string) (string, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || strings.Contains(id, "..") || filepath.Base(id) != id {
+		return "", fmt.Errorf("invalid conversation ID %q", id)
+	}
+	dir, err := conversationsDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, id+".json"), nil
+}
+
+// conversationTitle derives a title from the first user message
+func conversationTitle(messages []ChatMessage) string {
+	for _, msg := range messages {
+		if msg.Type == UserMessage {
+			title := strings.Join(strings.Fields(msg.Content), " ")
+			return truncateStage(title, 50)
+		}
+	}
+	return "Untitled conversation"
+}
+
+// savedConversationFile is a conversation as written to disk, its messages
+// given by anything that marshals to a JSON array
+type savedConversationFile struct {
+	SavedConversation
+	Messages any `json:"messages"`
+}
+
+// chatMessages marshals a chat's savable messages
+type chatMessages struct {
+	chat *Chat
+}
+
+// MarshalJSON writes the messages one spilled segment at a time instead of
+// reading them all back at once
+func (cm chatMessages) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	err := cm.chat.eachSegment(func(messages []ChatMessage) error {
+		for _, msg := range savableMessages(messages) {
+			data, err := json.Marshal(msg)
+			if err != nil {
+				return err
+			}
+			if buf.Len() > 1 {
+				buf.WriteByte(',')
+			}
+			buf.Write(data)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	buf.WriteByte(']')
+	return buf.Bytes(), nil
+}
+
+// SaveConversation writes a conversation to disk
+func SaveConversation(conv SavedConversation) error {
+	return writeConversation(savedConversationFile{SavedConversation: conv, Messages: conv.Messages})
+}
+
+// writeConversation writes a conversation file readable only by the user
+func writeConversation(conv savedConversationFile) error {
+	path, err := conversationPath(conv.ID)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	// Directories and files left by older versions were world-readable
+	if err := os.Chmod(dir, 0700); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(conv, "", "  ")
+	if err != nil {
+		return err
+	}
+	data, err = sealTranscript(data)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return err
+	}
+	return os.Chmod(path, 0600)
+}
+
+// ListSavedConversations returns saved conversations that aren't archived,
+// most recent first
+func ListSavedConversations() ([]SavedConversation, error) {
+	return listConversations(false)
+}
+
+// ListArchivedConversations returns archived conversations, most recent first
+func ListArchivedConversations() ([]SavedConversation, error) {
+	return listConversations(true)
+}
+
+// listConversations reads the saved conversations with the given archived
+// state, most recent first
+func listConversations(archived bool) ([]SavedConversation, error) {
+	dir, err := conversationsDir()
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var conversations []SavedConversation
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		// Files sealed with another key are skipped like unreadable ones
+		if data, err = openTranscript(data); err != nil {
+			continue
+		}
+		var conv SavedConversation
+		if err := json.Unmarshal(data, &conv); err != nil || conv.Archived != archived {
+			continue
+		}
+		conversations = append(conversations, conv)
+	}
+
+	sort.Slice(conversations, func(i, j int) bool {
+		return conversations[i].UpdatedAt.After(conversations[j].UpdatedAt)
+	})
+	return conversations, nil
+}
+
+// loadConversationByID reads the saved conversation with exactly this ID
+func loadConversationByID(id string) (*SavedConversation, error) {
+	path, err := conversationPath(id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = openTranscript(data); err != nil {
+		return nil, err
+	}
+	var conv SavedConversation
+	if err := json.Unmarshal(data, &conv); err != nil {
+		return nil, err
+	}
+	return &conv, nil
+}
+
+// DeleteSavedConversation removes a saved conversation from disk
+func DeleteSavedConversation(id string) error {
+	path, err := conversationPath(id)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// LoadSavedConversation finds a saved conversation by list number (1-based)
+// or ID prefix; archived conversations are only found by ID
+func LoadSavedConversation(ref string) (*SavedConversation, error) {
+	conversations, err := ListSavedConversations()
+	if err != nil {
+		return nil, err
+	}
+
+	var index int
+	if _, err := fmt.Sscanf(ref, "%d", &index); err == nil && fmt.Sprint(index) == ref {
+		if index < 1 || index > len(conversations) {
+			return nil, fmt.Errorf("no saved conversation #%d", index)
+		}
+		return &conversations[index-1], nil
+	}
+
+	archived, err := ListArchivedConversations()
+	if err != nil {
+		return nil, err
+	}
+	conversations = append(conversations, archived...)
+	for i := range conversations {
+		if strings.HasPrefix(conversations[i].ID, ref) {
+			return &conversations[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no saved conversation matching %q", ref)
+}
+
+// formatSavedConversations renders the list shown by /saved
+func formatSavedConversations(conversations []SavedConversation) string {
+	if len(conversations) == 0 {
+		return "No saved conversations yet. Conversations are saved after each response."
+	}
+
+	var b strings.Builder
+	b.WriteString("Saved conversations:\n\n")
+	for i, conv := range conversations {
+		b.WriteString(fmt.Sprintf("%2d. %s  (%d messages, %s)", i+1, conv.Title, len(conv.Messages), conv.UpdatedAt.Format("2006-01-02 15:04")))
+		if len(conv.Tags) > 0 {
+			b.WriteString("  " + renderTags(conv.Tags))
+		}
+		if conv.Settings != nil && !conv.Settings.IsZero() {
+			b.WriteString("  [" + conv.Settings.String() + "]")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\nUse /saved <number> to open one")
+	return b.String()
+}
+
+// autosaveConversation saves the current chat so it can be read offline
+func (m *Model) autosaveConversation() {
+	if m.chat.savableCount() == 0 {
+		return
+	}
+
+	var settings *conversationSettings
+	if s := m.currentSettings(); !s.IsZero() {
+		settings = &s
+	}
+
+	// Saving is best effort; failures shouldn't interrupt the chat
+	_ = writeConversation(savedConversationFile{
+		SavedConversation: SavedConversation{
+			ID:        m.savedConversationID(),
+			Title:     m.chat.savableTitle(),
+			UpdatedAt: time.Now(),
+			Tags:      m.currentTags(),
+			Settings:  settings,
+		},
+		Messages: chatMessages{chat: m.chat},
+	})
+}
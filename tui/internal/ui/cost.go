@@ -0,0 +1,209 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rubber_duck/tui/internal/clock"
+)
+
+// assumedResponseTokens is the output size used to project the cost of a send
+const assumedResponseTokens = 500
+
+// ModelPrice is the price of a model in USD per 1K tokens
+type ModelPrice struct {
+	Input  float64 `json:"input"`
+	Output float64 `json:"output"`
+}
+
+// BudgetConfig holds spend limits in USD (0 disables a limit)
+type BudgetConfig struct {
+	PerConversation float64 `json:"per_conversation,omitempty"`
+	PerDay          float64 `json:"per_day,omitempty"`
+	WarnThreshold   float64 `json:"warn_threshold,omitempty"` // Fraction of a limit, default 0.8
+}
+
+// defaultModelPrices are approximate list prices used when config has none
+var defaultModelPrices = map[string]ModelPrice{
+	"gpt-4":             {Input: 0.03, Output: 0.06},
+	"gpt-4-32k":         {Input: 0.06, Output: 0.12},
+	"gpt-4-turbo":       {Input: 0.01, Output: 0.03},
+	"gpt-4o":            {Input: 0.005, Output: 0.015},
+	"gpt-3.5-turbo":     {Input: 0.0005, Output: 0.0015},
+	"gpt-3.5-turbo-16k": {Input: 0.003, Output: 0.004},
+	"claude-3-opus":     {Input: 0.015, Output: 0.075},
+	"claude-3-sonnet":   {Input: 0.003, Output: 0.015},
+	"claude-3-haiku":    {Input: 0.00025, Output: 0.00125},
+	"claude-2.1":        {Input: 0.008, Output: 0.024},
+}
+
+// PriceForModel returns the configured or default price for a model
+func (c *Config) PriceForModel(model string) (ModelPrice, bool) {
+	if price, ok := c.Pricing[model]; ok {
+		return price, true
+	}
+	price, ok := defaultModelPrices[model]
+	return price, ok
+}
+
+// EstimateCost returns the cost in USD of a request, and whether the model has a price
+func (c *Config) EstimateCost(model string, inputTokens, outputTokens int) (float64, bool) {
+	price, ok := c.PriceForModel(model)
+	if !ok {
+		// Local and unknown models are treated as free
+		return 0, false
+	}
+	return float64(inputTokens)/1000*price.Input + float64(outputTokens)/1000*price.Output, true
+}
+
+// warnThreshold returns the fraction of a limit at which to warn
+func (b BudgetConfig) warnThreshold() float64 {
+	if b.WarnThreshold <= 0 || b.WarnThreshold > 1 {
+		return 0.8
+	}
+	return b.WarnThreshold
+}
+
+// CostTracker tracks estimated spend for the conversation and the current day
+type CostTracker struct {
+	conversation float64
+	Date         string  `json:"date"`
+	Day          float64 `json:"spent"`
+	path         string
+}
+
+// NewCostTracker creates a cost tracker, restoring today's spend from disk
+func NewCostTracker() *CostTracker {
+	tracker := &CostTracker{Date: clock.Now().Format("2006-01-02")}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return tracker
+	}
+	tracker.path = filepath.Join(homeDir, ".rubber_duck", "spend.json")
+
+	if data, err := os.ReadFile(tracker.path); err == nil {
+		var saved CostTracker
+		if json.Unmarshal(data, &saved) == nil && saved.Date == tracker.Date {
+			tracker.Day = saved.Day
+		}
+	}
+	return tracker
+}
+
+// Add records spend and persists the daily total
+func (t *CostTracker) Add(amount float64) {
+	t.rollover()
+	t.conversation += amount
+	t.Day += amount
+	t.save()
+}
+
+// ResetConversation clears the conversation total
+func (t *CostTracker) ResetConversation() {
+	t.conversation = 0
+}
+
+// Conversation returns the spend for the current conversation
+func (t *CostTracker) Conversation() float64 {
+	return t.conversation
+}
+
+// Today returns the spend for the current day
+func (t *CostTracker) Today() float64 {
+	t.rollover()
+	return t.Day
+}
+
+// rollover resets the daily total when the date changes
+func (t *CostTracker) rollover() {
+	today := clock.Now().Format("2006-01-02")
+	if t.Date != today {
+		t.Date = today
+		t.Day = 0
+	}
+}
+
+// save writes the daily total to disk
+func (t *CostTracker) save() {
+	if t.path == "" {
+		return
+	}
+	data, err := json.Marshal(t)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(t.path), 0755); err != nil {
+		return
+	}
+	_ = os.WriteFile(t.path, data, 0644)
+}
+
+// BudgetLevel classifies spend against the configured limits
+type BudgetLevel int
+
+const (
+	BudgetOK BudgetLevel = iota
+	BudgetWarning
+	BudgetExceeded
+)
+
+// CheckBudget returns the budget level after spending projected more, with a description
+func (t *CostTracker) CheckBudget(budget BudgetConfig, projected float64) (BudgetLevel, string) {
+	level := BudgetOK
+	var reason string
+
+	check := func(name string, spent, limit float64) {
+		if limit <= 0 {
+			return
+		}
+		switch {
+		case spent+projected > limit:
+			if level < BudgetExceeded {
+				level = BudgetExceeded
+				reason = fmt.Sprintf("%s budget $%.2f would be exceeded ($%.4f spent)", name, limit, spent)
+			}
+		case spent+projected >= limit*budget.warnThreshold():
+			if level < BudgetWarning {
+				level = BudgetWarning
+				reason = fmt.Sprintf("%s spend at %.0f%% of $%.2f budget", name, (spent+projected)/limit*100, limit)
+			}
+		}
+	}
+
+	check("Conversation", t.Conversation(), budget.PerConversation)
+	check("Daily", t.Today(), budget.PerDay)
+	return level, reason
+}
+
+// formatCost formats a USD amount for display
+func formatCost(amount float64) string {
+	if amount < 0.01 {
+		return fmt.Sprintf("$%.4f", amount)
+	}
+	return fmt.Sprintf("$%.2f", amount)
+}
+
+// formatBudget describes spend and limits for /budget
+func (m Model) formatBudget() string {
+	limit := func(amount float64) string {
+		if amount <= 0 {
+			return "no limit"
+		}
+		return formatCost(amount)
+	}
+
+	price := "unknown (treated as free)"
+	if p, ok := m.config.PriceForModel(m.activeModel()); ok {
+		price = fmt.Sprintf("$%.4f in / $%.4f out per 1K tokens", p.Input, p.Output)
+	}
+
+	return fmt.Sprintf("Estimated spend:\n  Conversation: %s (%s)\n  Today:        %s (%s)\n\nCurrent model price: %s\nWarnings at %.0f%% of a limit",
+		formatCost(m.cost.Conversation()), limit(m.config.Budget.PerConversation),
+		formatCost(m.cost.Today()), limit(m.config.Budget.PerDay),
+		price,
+		m.config.Budget.warnThreshold()*100,
+	)
+}
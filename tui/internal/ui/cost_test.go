@@ -0,0 +1,100 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/clock"
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+func TestCostTracker_CheckBudget(t *testing.T) {
+	testutil.IsolateHome(t)
+	tests := []struct {
+		name      string
+		budget    BudgetConfig
+		spent     float64
+		projected float64
+		want      BudgetLevel
+		reason    string
+	}{
+		{"no limits", BudgetConfig{}, 100, 100, BudgetOK, ""},
+		{"under the threshold", BudgetConfig{PerConversation: 1}, 0.5, 0.2, BudgetOK, ""},
+		{"at the default threshold", BudgetConfig{PerConversation: 1}, 0.5, 0.3, BudgetWarning, "Conversation spend at 80%"},
+		{"custom threshold", BudgetConfig{PerConversation: 1, WarnThreshold: 0.5}, 0.5, 0, BudgetWarning, "Conversation spend at 50%"},
+		{"invalid threshold uses the default", BudgetConfig{PerConversation: 1, WarnThreshold: 2}, 0.7, 0, BudgetOK, ""},
+		{"exactly at the limit", BudgetConfig{PerConversation: 1}, 0.5, 0.5, BudgetWarning, ""},
+		{"projected past the limit", BudgetConfig{PerConversation: 1}, 0.5, 0.6, BudgetExceeded, "Conversation budget $1.00 would be exceeded"},
+		{"exceeded beats a warning", BudgetConfig{PerConversation: 10, PerDay: 1}, 8.5, 0, BudgetExceeded, "Daily budget $1.00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := &CostTracker{Date: clock.Now().Format("2006-01-02")}
+			tracker.Add(tt.spent)
+			level, reason := tracker.CheckBudget(tt.budget, tt.projected)
+			if level != tt.want || !strings.Contains(reason, tt.reason) {
+				t.Errorf("CheckBudget = %d %q, want %d containing %q", level, reason, tt.want, tt.reason)
+			}
+		})
+	}
+}
+
+func TestCostTracker_PersistsTodaysSpend(t *testing.T) {
+	testutil.IsolateHome(t)
+	day := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	restore := clock.Freeze(day)
+	defer func() { restore() }()
+
+	tracker := NewCostTracker()
+	tracker.Add(0.25)
+	tracker.Add(0.5)
+
+	// A restart keeps the day's total but starts a new conversation
+	restarted := NewCostTracker()
+	if restarted.Today() != 0.75 || restarted.Conversation() != 0 {
+		t.Fatalf("Expected $0.75 today and nothing this conversation, got %v and %v", restarted.Today(), restarted.Conversation())
+	}
+
+	// The next day starts from zero, on disk too
+	restore()
+	restore = clock.Freeze(day.Add(24 * time.Hour))
+	if NewCostTracker().Today() != 0 || restarted.Today() != 0 {
+		t.Fatal("Expected the daily total to reset on a new day")
+	}
+}
+
+func TestBudget_ConfirmsSendsOverTheLimit(t *testing.T) {
+	testutil.IsolateHome(t)
+	model := NewModel()
+	model.config.OllamaURL = "http://127.0.0.1:1"
+	model.currentProvider, model.currentModel = localOllamaProvider, "gpt-4"
+	model.config.Budget = BudgetConfig{PerConversation: 0.01}
+	model.cost.Add(0.009)
+
+	updated, _ := model.Update(ChatMessageSentMsg{Content: "an expensive question"})
+	*model = updated.(Model)
+	if !model.modal.IsVisible() || model.isProcessing {
+		t.Fatal("Expected a send over the budget to ask first")
+	}
+	if model.chat.GetInputValue() != "an expensive question" {
+		t.Fatalf("Expected the draft kept while asking, got %q", model.chat.GetInputValue())
+	}
+
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	*model = updated.(Model)
+	if cmd == nil {
+		t.Fatal("Expected confirming to resend the message")
+	}
+	confirmed, ok := cmd().(ChatMessageSentMsg)
+	if !ok || !confirmed.BudgetConfirmed {
+		t.Fatalf("Expected a confirmed resend, got %#v", confirmed)
+	}
+	updated, _ = model.Update(confirmed)
+	*model = updated.(Model)
+	messages := model.chat.GetMessages()
+	if !model.isProcessing || messages[len(messages)-1].Content != "an expensive question" || model.chat.GetInputValue() != "" {
+		t.Fatal("Expected the confirmed message sent")
+	}
+}
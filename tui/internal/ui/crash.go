@@ -0,0 +1,232 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/clock"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// crashHistoryLimit is how many recent message types a crash report lists
+const crashHistoryLimit = 50
+
+// crashDir returns the directory crash reports are written to
+func crashDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".rubber_duck", "crash"), nil
+}
+
+// CmdPanic is a panic raised by a command, carried back to the event loop
+// so it is reported like a panic in Update
+type CmdPanic struct {
+	Value any
+	Stack []byte
+}
+
+// Error describes the original panic
+func (p CmdPanic) Error() string {
+	return fmt.Sprintf("panic in command: %v", p.Value)
+}
+
+// cmdPanicMsg delivers a command's panic to the event loop
+type cmdPanicMsg CmdPanic
+
+// crashState is shared by every copy of a CrashGuard
+type crashState struct {
+	mu     sync.Mutex
+	model  tea.Model
+	recent []string
+}
+
+// CrashGuard remembers recent messages and the last good model so a panic
+// can be written up as a crash report. Commands the model returns are
+// wrapped so their panics reach the event loop instead of killing the
+// process from another goroutine
+type CrashGuard struct {
+	model tea.Model
+	state *crashState
+}
+
+// NewCrashGuard wraps a model with crash tracking
+func NewCrashGuard(model tea.Model) CrashGuard {
+	return CrashGuard{model: model, state: &crashState{model: model}}
+}
+
+// Init initializes the wrapped model
+func (g CrashGuard) Init() tea.Cmd {
+	return guardCmd(g.model.Init())
+}
+
+// Update notes the message and updates the wrapped model. A panic from a
+// command is raised again here, on the event loop
+func (g CrashGuard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if p, ok := msg.(cmdPanicMsg); ok {
+		panic(CmdPanic(p))
+	}
+	g.state.note(msg)
+
+	var cmd tea.Cmd
+	g.model, cmd = g.model.Update(msg)
+
+	g.state.mu.Lock()
+	g.state.model = g.model
+	g.state.mu.Unlock()
+	return g, guardCmd(cmd)
+}
+
+// View renders the wrapped model
+func (g CrashGuard) View() string {
+	return g.model.View()
+}
+
+// Unwrap returns the wrapped model
+func (g CrashGuard) Unwrap() tea.Model {
+	return g.model
+}
+
+// SecretInputFocused forwards whether the wrapped model has a masked field
+// focused
+func (g CrashGuard) SecretInputFocused() bool {
+	secret, ok := g.model.(secretInput)
+	return ok && secret.SecretInputFocused()
+}
+
+// note records a message type, keeping the most recent crashHistoryLimit
+func (s *crashState) note(msg tea.Msg) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recent = append(s.recent, fmt.Sprintf("%s %s", clock.Now().Format("15:04:05.000"), msgTypeName(msg)))
+	if len(s.recent) > crashHistoryLimit {
+		s.recent = s.recent[len(s.recent)-crashHistoryLimit:]
+	}
+}
+
+// guardCmd recovers panics in a command, and in the commands of a batch it
+// returns, as a cmdPanicMsg
+func guardCmd(cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() (msg tea.Msg) {
+		defer func() {
+			if r := recover(); r != nil {
+				msg = cmdPanicMsg{Value: r, Stack: debug.Stack()}
+			}
+		}()
+		msg = cmd()
+		if batch, ok := msg.(tea.BatchMsg); ok {
+			guarded := make(tea.BatchMsg, len(batch))
+			for i, c := range batch {
+				guarded[i] = guardCmd(c)
+			}
+			msg = guarded
+		}
+		return msg
+	}
+}
+
+// session returns the conversation of the last good model
+func (g CrashGuard) session() *SavedConversation {
+	g.state.mu.Lock()
+	model := g.state.model
+	g.state.mu.Unlock()
+
+	var m *Model
+	switch v := model.(type) {
+	case Model:
+		m = &v
+	case *Model:
+		m = v
+	case HeadlessModel:
+		m = &v.model
+	default:
+		return nil
+	}
+	messages := savableMessages(m.chat.GetMessages())
+	if len(messages) == 0 {
+		return nil
+	}
+	return &SavedConversation{
+		ID:        "crash-" + clock.Now().Format("20060102-150405"),
+		Title:     conversationTitle(messages),
+		UpdatedAt: clock.Now(),
+		Messages:  messages,
+		Tags:      m.conversationTags,
+	}
+}
+
+// CrashReport is the outcome of writing up a panic
+type CrashReport struct {
+	Path      string // Report file
+	SessionID string // Saved conversation to restore, empty when there was none
+}
+
+// WriteCrashReport writes a report for a recovered panic to
+// ~/.rubber_duck/crash/ and saves the conversation so it can be restored
+func (g CrashGuard) WriteCrashReport(recovered any) (CrashReport, error) {
+	var report CrashReport
+	value, stack := recovered, debug.Stack()
+	if p, ok := recovered.(CmdPanic); ok {
+		value, stack = p.Value, p.Stack
+	}
+
+	if session := g.session(); session != nil {
+		if err := SaveConversation(*session); err == nil {
+			report.SessionID = session.ID
+		}
+	}
+
+	g.state.mu.Lock()
+	recent := append([]string(nil), g.state.recent...)
+	g.state.mu.Unlock()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "RubberDuck TUI crash report\n\n")
+	fmt.Fprintf(&b, "Time:    %s\n", clock.Now().Format(time.RFC3339))
+	fmt.Fprintf(&b, "Version: %s (%s, %s/%s)\n", phoenix.ClientVersion, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	if report.SessionID != "" {
+		fmt.Fprintf(&b, "Session: %s\n", report.SessionID)
+	}
+	fmt.Fprintf(&b, "Panic:   %v\n\nRecent messages (oldest first):\n", value)
+	for _, line := range recent {
+		fmt.Fprintf(&b, "  %s\n", line)
+	}
+	fmt.Fprintf(&b, "\nStack:\n%s", stack)
+
+	dir, err := crashDir()
+	if err != nil {
+		return report, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return report, err
+	}
+	report.Path = filepath.Join(dir, "crash-"+clock.Now().Format("20060102-150405")+".log")
+	return report, os.WriteFile(report.Path, []byte(b.String()), 0644)
+}
+
+// RestoreSession loads a saved conversation into the chat so a session can
+// continue after a crash
+func (m *Model) RestoreSession(ref string) error {
+	conv, err := LoadSavedConversation(ref)
+	if err != nil {
+		return err
+	}
+	for _, saved := range conv.Messages {
+		m.chat.restoreMessage(saved)
+	}
+	m.chat.AddMessage(SystemMessage, "Session restored after a crash", "system")
+	m.messageCount = m.chat.GetMessageCount()
+	m.statusBar = fmt.Sprintf("Restored %s", conv.Title)
+	return nil
+}
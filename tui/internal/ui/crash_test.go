@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+func TestCrashGuardReportsCommandPanics(t *testing.T) {
+	testutil.IsolateHome(t)
+	guard := NewCrashGuard(*NewModel())
+	updated, _ := guard.Update(ChatMessageReceivedMsg{Content: "hello", Type: "assistant"})
+	guard = updated.(CrashGuard)
+
+	cmd := guardCmd(tea.Batch(func() tea.Msg { panic("boom") }, func() tea.Msg { return nil }))
+	batch, ok := cmd().(tea.BatchMsg)
+	if !ok || len(batch) != 2 {
+		t.Fatalf("Expected the batch to be passed through, got %#v", batch)
+	}
+	msg := batch[0]()
+	if _, ok := msg.(cmdPanicMsg); !ok {
+		t.Fatalf("Expected the command's panic as a message, got %#v", msg)
+	}
+
+	var recovered any
+	func() {
+		defer func() { recovered = recover() }()
+		guard.Update(msg)
+	}()
+	if _, ok := recovered.(CmdPanic); !ok {
+		t.Fatalf("Expected the panic raised on the event loop, got %#v", recovered)
+	}
+
+	report, err := guard.WriteCrashReport(recovered)
+	if err != nil {
+		t.Fatalf("WriteCrashReport: %v", err)
+	}
+	data, err := os.ReadFile(report.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Panic:   boom", "ui.ChatMessageReceivedMsg", "Stack:"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Expected %q in the report:\n%s", want, data)
+		}
+	}
+
+	restored := NewModel()
+	if err := restored.RestoreSession(report.SessionID); err != nil {
+		t.Fatalf("RestoreSession: %v", err)
+	}
+	if !strings.Contains(restored.chat.GetMessages()[0].Content, "hello") {
+		t.Error("Expected the conversation to be restored")
+	}
+}
+
+func TestCrashGuardSavesHeadlessSessions(t *testing.T) {
+	testutil.IsolateHome(t)
+	model := NewModel()
+	model.chat.AddMessage(UserMessage, "scripted question", "user")
+	guard := NewCrashGuard(NewHeadlessModel(model, strings.NewReader(""), &strings.Builder{}, "text"))
+
+	report, err := guard.WriteCrashReport("boom")
+	if err != nil {
+		t.Fatalf("WriteCrashReport: %v", err)
+	}
+	if report.SessionID == "" {
+		t.Error("Expected the headless conversation saved with the report")
+	}
+}
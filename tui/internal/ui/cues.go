@@ -0,0 +1,220 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Events that trigger cues, the keys of tui.cues
+const (
+	CueMessageReceived = "message_received" // An answer arrived or finished streaming
+	CueError           = "error"            // A request or the connection failed
+	CuePlanDone        = "plan_done"        // A plan finished
+)
+
+// Cues an event can trigger
+const (
+	CueBell  = "bell"  // Ring the terminal bell
+	CueFlash = "flash" // Show the event in the status bar
+	CueToast = "toast" // Show the event in a box over the top-right corner
+	CueNone  = "none"
+)
+
+// toastDuration is how long a toast stays up
+const toastDuration = 4 * time.Second
+
+// defaultCues are the cues of events tui.cues doesn't set, which show each
+// event in the status bar as before cues were configurable
+var defaultCues = map[string]string{
+	CueMessageReceived: CueFlash,
+	CueError:           CueFlash,
+	CuePlanDone:        CueFlash,
+}
+
+// cuesUsage explains /cues
+const cuesUsage = "Usage: /cues [<event> <bell|flash|toast|none|default>[,...] | test <event>]\nEvents: message_received, error, plan_done"
+
+// toast is a notice shown over the screen for a while
+type toast struct {
+	id    int
+	event string
+	text  string
+}
+
+// toastExpiredMsg takes down a toast once its time is up
+type toastExpiredMsg struct {
+	ID int
+}
+
+// parseCues reads a comma-separated list of cues, rejecting unknown ones
+func parseCues(rule string) ([]string, error) {
+	var cues []string
+	for _, cue := range strings.Split(rule, ",") {
+		switch cue = strings.TrimSpace(strings.ToLower(cue)); cue {
+		case CueBell, CueFlash, CueToast:
+			cues = append(cues, cue)
+		case CueNone, "":
+		default:
+			return nil, fmt.Errorf("unknown cue %q", cue)
+		}
+	}
+	return cues, nil
+}
+
+// eventCues returns the cues an event triggers
+func (m Model) eventCues(event string) []string {
+	rule, ok := m.config.TUI.Cues[event]
+	if !ok {
+		rule = defaultCues[event]
+	}
+	// Unknown cues in a hand-edited config are skipped
+	var cues []string
+	for _, cue := range strings.Split(rule, ",") {
+		switch cue = strings.TrimSpace(strings.ToLower(cue)); cue {
+		case CueBell, CueFlash, CueToast:
+			cues = append(cues, cue)
+		}
+	}
+	return cues
+}
+
+// cue runs the cues configured for an event
+func (m *Model) cue(event, text string) tea.Cmd {
+	var cmds []tea.Cmd
+	for _, cue := range m.eventCues(event) {
+		switch cue {
+		case CueBell:
+			cmds = append(cmds, ringBell)
+		case CueFlash:
+			m.statusBar = text
+		case CueToast:
+			cmds = append(cmds, m.showToast(event, text))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// showToast puts up a toast, replacing any shown, and takes it down after
+// toastDuration
+func (m *Model) showToast(event, text string) tea.Cmd {
+	id := 1
+	if m.toast != nil {
+		id = m.toast.id + 1
+	}
+	m.toast = &toast{id: id, event: event, text: text}
+	return tea.Tick(toastDuration, func(time.Time) tea.Msg { return toastExpiredMsg{ID: id} })
+}
+
+// expireToast takes down a toast unless a newer one replaced it
+func (m *Model) expireToast(msg toastExpiredMsg) {
+	if m.toast != nil && m.toast.id == msg.ID {
+		m.toast = nil
+	}
+}
+
+// overlayToast draws the toast over the top-right corner of the screen
+func (m Model) overlayToast(screen string) string {
+	if m.toast == nil {
+		return screen
+	}
+	color := lipgloss.Color("62")
+	if m.toast.event == CueError {
+		color = lipgloss.Color("196")
+	}
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(color).
+		Padding(0, 1).
+		Width(min(40, max(m.width-4, 10))).
+		Render(truncateStage(m.toast.text, 200))
+	boxWidth := lipgloss.Width(box)
+	left := max(m.width-boxWidth-1, 0)
+
+	lines := strings.Split(screen, "\n")
+	for i, boxLine := range strings.Split(box, "\n") {
+		row := i + 1
+		if row >= len(lines) {
+			break
+		}
+		line := lines[row]
+		before := ansi.Truncate(line, left, "")
+		if gap := left - ansi.StringWidth(before); gap > 0 {
+			before += strings.Repeat(" ", gap)
+		}
+		lines[row] = before + boxLine + ansi.TruncateLeft(line, left+boxWidth, "")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// describeCues lists each event's cues for /cues
+func (m Model) describeCues() string {
+	events := make([]string, 0, len(defaultCues))
+	for event := range defaultCues {
+		events = append(events, event)
+	}
+	sort.Strings(events)
+	var b strings.Builder
+	b.WriteString("Event cues (bell, flash, toast, or none):\n")
+	for _, event := range events {
+		cues := strings.Join(m.eventCues(event), ",")
+		if cues == "" {
+			cues = CueNone
+		}
+		if _, set := m.config.TUI.Cues[event]; !set {
+			cues += " (default)"
+		}
+		fmt.Fprintf(&b, "  %-17s %s\n", event, cues)
+	}
+	b.WriteString("\nChange one with /cues <event> <cues>, e.g. /cues error bell,toast")
+	return b.String()
+}
+
+// setCues changes the cues of an event and saves them; "default" goes back
+// to the default cues
+func (m *Model) setCues(event, rule string) {
+	if _, known := defaultCues[event]; !known {
+		m.chat.AddMessage(SystemMessage, cuesUsage, "system")
+		return
+	}
+	if rule == "default" {
+		delete(m.config.TUI.Cues, event)
+	} else {
+		cues, err := parseCues(rule)
+		if err != nil {
+			m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("%v - use bell, flash, toast, or none", err), nil)
+			return
+		}
+		if m.config.TUI.Cues == nil {
+			m.config.TUI.Cues = make(map[string]string)
+		}
+		rule = strings.Join(cues, ",")
+		if rule == "" {
+			rule = CueNone
+		}
+		m.config.TUI.Cues[event] = rule
+	}
+	if err := SaveConfig(m.config); err != nil {
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Failed to save config: %v", err), nil)
+		return
+	}
+	cues := strings.Join(m.eventCues(event), ",")
+	if cues == "" {
+		cues = CueNone
+	}
+	m.statusBar = fmt.Sprintf("Cues for %s: %s", event, cues)
+}
+
+// testCue runs an event's cues with sample text
+func (m *Model) testCue(event string) tea.Cmd {
+	if _, known := defaultCues[event]; !known {
+		m.chat.AddMessage(SystemMessage, cuesUsage, "system")
+		return nil
+	}
+	return m.cue(event, "Testing the "+strings.ReplaceAll(event, "_", " ")+" cues")
+}
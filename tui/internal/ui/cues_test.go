@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+func TestCuesPerEvent(t *testing.T) {
+	testutil.IsolateHome(t)
+	model := NewModel()
+	model.width, model.height = 80, 24
+
+	// By default errors flash in the status bar
+	updated, _ := model.Update(ErrorMsg{Err: errors.New("boom"), Component: "Test"})
+	*model = updated.(Model)
+	if !strings.Contains(model.statusBar, "boom") || model.toast != nil {
+		t.Fatalf("Expected the error flashed only, got status %q toast %v", model.statusBar, model.toast)
+	}
+
+	updated, _ = model.Update(ExecuteCommandMsg{Command: "cues_set", Args: map[string]string{"event": "error", "cues": "toast"}})
+	*model = updated.(Model)
+	if got := model.config.TUI.Cues["error"]; got != "toast" {
+		t.Fatalf("Expected the error cue saved, got %q", got)
+	}
+	loaded, err := LoadConfig()
+	if err != nil || loaded.TUI.Cues["error"] != "toast" {
+		t.Errorf("Expected the cue in the saved config, got %v (%v)", loaded.TUI.Cues, err)
+	}
+
+	model.statusBar = ""
+	updated, cmd := model.Update(ErrorMsg{Err: errors.New("second failure"), Component: "Other"})
+	*model = updated.(Model)
+	if model.statusBar != "" || model.toast == nil || cmd == nil {
+		t.Fatalf("Expected a toast instead of a flash, got status %q toast %v", model.statusBar, model.toast)
+	}
+	if view := ansi.Strip(model.View()); !strings.Contains(view, "second failure") {
+		t.Errorf("Expected the toast drawn over the screen:\n%s", view)
+	}
+	updated, _ = model.Update(toastExpiredMsg{ID: model.toast.id})
+	*model = updated.(Model)
+	if model.toast != nil {
+		t.Error("Expected the toast taken down when it expires")
+	}
+
+	updated, _ = model.Update(ExecuteCommandMsg{Command: "cues_set", Args: map[string]string{"event": "error", "cues": "siren"}})
+	*model = updated.(Model)
+	if got := model.config.TUI.Cues["error"]; got != "toast" {
+		t.Errorf("Expected an unknown cue refused, got %q", got)
+	}
+}
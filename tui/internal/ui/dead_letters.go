@@ -0,0 +1,190 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rubber_duck/tui/internal/clock"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// maxDeadLetters is how many failed events are kept, oldest dropped first
+const maxDeadLetters = 200
+
+// deadLetter is a server event the TUI received but couldn't parse or route
+type deadLetter struct {
+	At      time.Time
+	Topic   string
+	Event   string
+	Reason  string
+	Payload json.RawMessage
+}
+
+// DeadLetterView lists the events that couldn't be parsed or routed, with
+// the raw payload of the selected one
+type DeadLetterView struct {
+	letters []deadLetter
+	cursor  int
+	visible bool
+	width   int
+	height  int
+}
+
+// Add records a failed event, reporting whether it is the first from its
+// topic and event
+func (dv *DeadLetterView) Add(letter deadLetter) bool {
+	first := true
+	for _, existing := range dv.letters {
+		if existing.Topic == letter.Topic && existing.Event == letter.Event {
+			first = false
+			break
+		}
+	}
+	dv.letters = append(dv.letters, letter)
+	if len(dv.letters) > maxDeadLetters {
+		dv.letters = dv.letters[len(dv.letters)-maxDeadLetters:]
+	}
+	return first
+}
+
+// Count returns how many failed events are kept
+func (dv DeadLetterView) Count() int {
+	return len(dv.letters)
+}
+
+// Clear forgets every failed event
+func (dv *DeadLetterView) Clear() {
+	dv.letters = nil
+	dv.cursor = 0
+}
+
+// Show shows the view with the newest event selected
+func (dv *DeadLetterView) Show() {
+	dv.cursor = max(len(dv.letters)-1, 0)
+	dv.visible = true
+}
+
+// Hide hides the view
+func (dv *DeadLetterView) Hide() {
+	dv.visible = false
+}
+
+// IsVisible returns whether the view is visible
+func (dv DeadLetterView) IsVisible() bool {
+	return dv.visible
+}
+
+// SetSize updates the view dimensions
+func (dv *DeadLetterView) SetSize(width, height int) {
+	dv.width = width
+	dv.height = height
+}
+
+// selectedPayload returns the selected event's payload, indented
+func (dv DeadLetterView) selectedPayload() string {
+	if len(dv.letters) == 0 {
+		return ""
+	}
+	payload := dv.letters[dv.cursor].Payload
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, payload, "", "  "); err != nil {
+		return string(payload)
+	}
+	return indented.String()
+}
+
+// Update handles view input
+func (dv DeadLetterView) Update(msg tea.Msg) (DeadLetterView, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || !dv.visible {
+		return dv, nil
+	}
+
+	switch keyMsg.String() {
+	case "esc", "q":
+		dv.Hide()
+	case "up", "k":
+		dv.cursor = max(dv.cursor-1, 0)
+	case "down", "j":
+		dv.cursor = min(dv.cursor+1, max(len(dv.letters)-1, 0))
+	case "c":
+		return dv, func() tea.Msg { return ExecuteCommandMsg{Command: "dead_letters_copy"} }
+	case "x":
+		dv.Clear()
+	}
+	return dv, nil
+}
+
+// View renders the list above the selected event's payload
+func (dv DeadLetterView) View() string {
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("62"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	cursorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
+	reasonStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+
+	lines := []string{
+		titleStyle.Render("Dead letters"),
+		dimStyle.Render(fmt.Sprintf("%d events received but not parsed or routed", len(dv.letters))),
+		"",
+	}
+	if len(dv.letters) == 0 {
+		lines = append(lines, dimStyle.Render("Nothing yet - every server event was handled"), "", dimStyle.Render("Esc: Close"))
+		return lipgloss.NewStyle().Padding(0, 1).Render(strings.Join(lines, "\n"))
+	}
+
+	// The list gets up to a third of the screen, following the cursor
+	room := max(dv.height/3, 3)
+	start := max(min(dv.cursor-room/2, len(dv.letters)-room), 0)
+	for i := start; i < min(start+room, len(dv.letters)); i++ {
+		letter := dv.letters[i]
+		label := fmt.Sprintf("%s  %s %s  %s", letter.At.Format("15:04:05"), letter.Topic, letter.Event, reasonStyle.Render(letter.Reason))
+		if i == dv.cursor {
+			label = cursorStyle.Render("▶ ") + label
+		} else {
+			label = "  " + label
+		}
+		lines = append(lines, label)
+	}
+	lines = append(lines, "", titleStyle.Render("Payload"))
+
+	payload := strings.Split(dv.selectedPayload(), "\n")
+	if space := max(dv.height-len(lines)-2, 3); len(payload) > space {
+		payload = append(payload[:space-1], dimStyle.Render(fmt.Sprintf("… %d more lines (c copies it all)", len(payload)-space+1)))
+	}
+	lines = append(lines, payload...)
+	lines = append(lines, dimStyle.Render("↑/↓: Select | c: Copy payload | x: Clear | Esc: Close"))
+	return lipgloss.NewStyle().Padding(0, 1).Render(strings.Join(lines, "\n"))
+}
+
+// recordDeadLetter keeps an event that couldn't be parsed or routed, noting
+// the first of each kind in the status panel
+func (m *Model) recordDeadLetter(topic, event, reason string, payload json.RawMessage) {
+	letter := deadLetter{At: clock.Now(), Topic: topic, Event: event, Reason: reason, Payload: payload}
+	if m.deadLetters.Add(letter) {
+		m.statusMessages.AddMessage(StatusCategoryInfo, fmt.Sprintf("Unhandled %s event on %s (%s) - /dead-letters shows it", event, topic, reason), nil)
+	}
+}
+
+// handleUnroutedEvent records a server event no channel handler took
+func (m *Model) handleUnroutedEvent(msg phoenix.UnroutedEventMsg) {
+	m.recordDeadLetter(msg.Topic, msg.Event, msg.Reason, msg.Payload)
+}
+
+// copyDeadLetter copies the selected event's payload to the clipboard
+func (m *Model) copyDeadLetter() {
+	payload := m.deadLetters.selectedPayload()
+	if payload == "" {
+		return
+	}
+	if err := clipboard.WriteAll(payload); err != nil {
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Failed to copy: %v", err), nil)
+		return
+	}
+	m.statusBar = "Payload copied to clipboard"
+}
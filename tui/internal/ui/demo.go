@@ -0,0 +1,80 @@
+package ui
+
+import (
+	_ "embed"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// demoScenario scripts the answers shown in demo mode
+//
+//go:embed demo_scenario.yaml
+var demoScenario []byte
+
+// Demo mode's stand-in account and model
+const (
+	demoUsername = "demo"
+	demoProvider = "demo"
+	demoModel    = "duck-demo"
+)
+
+// EnableDemo runs the TUI against the MockClient and its scripted demo
+// scenario instead of a server
+func (m *Model) EnableDemo() error {
+	scenario, err := phoenix.ParseScenario(demoScenario)
+	if err != nil {
+		return err
+	}
+	m.demo = phoenix.NewMockClient(scenario)
+	return nil
+}
+
+// DemoClient returns the demo's MockClient, or nil outside demo mode
+func (m *Model) DemoClient() *phoenix.MockClient {
+	return m.demo
+}
+
+// startDemo stands in for connecting and logging in
+func (m *Model) startDemo() {
+	m.connected = true
+	m.authenticated = true
+	m.username = demoUsername
+	m.currentProvider = demoProvider
+	m.currentModel = demoModel
+	m.updateHeaderState()
+	m.statusBar = "Demo mode - answers are scripted"
+	m.chat.AddMessage(SystemMessage, "Welcome to the RubberDuck demo! Answers come from a built-in simulated server, so nothing you type leaves this machine. Ask anything, ask for a test, or try /plan. Account commands are disabled.", "system")
+}
+
+// demoBlocks reports whether a command is refused in demo mode, so real
+// credentials are never typed into or sent by the demo
+func demoBlocks(command string) bool {
+	return strings.HasPrefix(command, "auth_") || command == "config_save"
+}
+
+// refuseInDemo explains why an account action is disabled
+func (m *Model) refuseInDemo() {
+	m.statusMessages.AddMessage(StatusCategoryError, "Demo mode: account commands are disabled so no credentials are sent. Restart without --demo to use a server", nil)
+}
+
+// sendToMock answers a message with a MockClient instead of the server
+func (m *Model) sendToMock(client *phoenix.MockClient, content string) tea.Cmd {
+	m.chat.AddMessage(UserMessage, content, "user")
+	m.messageCount = m.chat.GetMessageCount()
+	m.lastPrompt = content
+	m.isProcessing = true
+	return client.SendMessage(content)
+}
+
+// renderDemoBanner renders the persistent demo indicator
+func (m Model) renderDemoBanner() string {
+	return lipgloss.NewStyle().
+		Background(lipgloss.Color("214")).
+		Foreground(lipgloss.Color("16")).
+		Bold(true).
+		Padding(0, 1).
+		Render("DEMO - simulated server")
+}
@@ -0,0 +1,37 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rubber_duck/tui/internal/phoenix"
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+func TestDemoModeNeverUsesTheServer(t *testing.T) {
+	testutil.IsolateHome(t)
+	m := NewModel()
+	if err := m.EnableDemo(); err != nil {
+		t.Fatalf("EnableDemo: %v", err)
+	}
+	model := applyMsgs(*m, InitiateConnectionMsg{})
+	if !model.connected || !model.authenticated || model.currentModel != demoModel {
+		t.Fatal("Expected the demo to stand in for connecting and logging in")
+	}
+	if !strings.Contains(model.renderMiniStatusBar(120), "DEMO") {
+		t.Error("Expected a DEMO banner")
+	}
+
+	model = applyMsgs(model, ChatMessageSentMsg{Content: "write a test"})
+	if calls := model.demo.Calls(); len(calls) != 1 || calls[0].Trigger != phoenix.TriggerSend {
+		t.Fatalf("Expected the message to go to the demo client, got %v", calls)
+	}
+
+	model = applyMsgs(model,
+		LoginSubmitMsg{Username: "duck", Password: "hunter2"},
+		ExecuteCommandMsg{Command: "auth_apikey_save", Args: map[string]string{"key": "secret"}},
+	)
+	if model.config.APIKey != "" {
+		t.Error("Expected demo mode to refuse saving an API key")
+	}
+}
@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rubber_duck/tui/internal/clock"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// diagnosticsLogPath returns the log malformed server payloads are written to
+func diagnosticsLogPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".rubber_duck", "diagnostics.log"), nil
+}
+
+// formatDiagnostic renders a payload diagnostic as one log entry
+func formatDiagnostic(msg phoenix.PayloadDiagnosticMsg) string {
+	issues := make([]string, len(msg.Issues))
+	for i, issue := range msg.Issues {
+		issues[i] = issue.String()
+	}
+	return fmt.Sprintf("%s %s %s: %s\n  payload: %s\n",
+		clock.Now().Format("2006-01-02T15:04:05Z07:00"), msg.Topic, msg.Event, strings.Join(issues, "; "), msg.Payload)
+}
+
+// logDiagnostic appends a payload diagnostic to the diagnostics log
+func logDiagnostic(msg phoenix.PayloadDiagnosticMsg) (string, error) {
+	path, err := diagnosticsLogPath()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	_, err = file.WriteString(formatDiagnostic(msg))
+	return path, err
+}
+
+// handlePayloadDiagnostic logs a malformed server payload and notes it in
+// the status panel
+func (m *Model) handlePayloadDiagnostic(msg phoenix.PayloadDiagnosticMsg) {
+	issues := make([]string, len(msg.Issues))
+	for i, issue := range msg.Issues {
+		issues[i] = issue.String()
+	}
+	m.deadLetters.Add(deadLetter{At: clock.Now(), Topic: msg.Topic, Event: msg.Event, Reason: strings.Join(issues, "; "), Payload: msg.Payload})
+	
+	path, err := logDiagnostic(msg)
+	if err != nil {
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Malformed %s payload from server; writing diagnostics failed: %v", msg.Event, err), nil)
+		return
+	}
+	m.statusMessages.AddMessage(StatusCategoryInfo, fmt.Sprintf("Malformed %s payload from server (%s) - logged to %s", msg.Event, msg.Issues[0], path), nil)
+}
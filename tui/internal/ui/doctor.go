@@ -0,0 +1,155 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/atotto/clipboard"
+	"github.com/rubber_duck/tui/internal/clock"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// doctorErrorLimit is how many recent errors a doctor report lists
+const doctorErrorLimit = 10
+
+// doctorTerminalVars are the environment variables describing the terminal
+var doctorTerminalVars = []string{"TERM", "COLORTERM", "TERM_PROGRAM", "TERM_PROGRAM_VERSION", "TMUX", "LANG"}
+
+// secretConfigKeys are config keys whose values a doctor report hides
+var secretConfigKeys = []string{"key", "token", "secret", "password"}
+
+// RecentErrors returns the last n error updates, oldest first
+func (s *StatusMessages) RecentErrors(n int) []StatusMessage {
+	var errors []StatusMessage
+	for _, msg := range s.messages {
+		if msg.Category == StatusCategoryError {
+			errors = append(errors, msg)
+		}
+	}
+	if len(errors) > n {
+		errors = errors[len(errors)-n:]
+	}
+	return errors
+}
+
+// redactConfigValues hides the values of secret-looking keys
+func redactConfigValues(values map[string]any) {
+	for key, value := range values {
+		if table, ok := value.(map[string]any); ok {
+			redactConfigValues(table)
+			continue
+		}
+		if text, ok := value.(string); !ok || text == "" {
+			continue
+		}
+		for _, secret := range secretConfigKeys {
+			if strings.Contains(strings.ToLower(key), secret) {
+				values[key] = redacted
+				break
+			}
+		}
+	}
+}
+
+// doctorReport gathers the client, terminal, config, connection, and recent
+// errors into a report to attach to a bug report. Secrets are masked
+func (m Model) doctorReport() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "RubberDuck TUI doctor report\n\n")
+	fmt.Fprintf(&b, "Time:     %s\n", clock.Now().Format(time.RFC3339))
+	fmt.Fprintf(&b, "Client:   %s (protocol v%s)\n", phoenix.ClientVersion, phoenix.ProtocolVersion)
+	fmt.Fprintf(&b, "Runtime:  %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+
+	b.WriteString("\nTerminal:\n")
+	fmt.Fprintf(&b, "  size: %dx%d, mouse: %t\n", m.width, m.height, m.mouseEnabled)
+	for _, name := range doctorTerminalVars {
+		if value := os.Getenv(name); value != "" {
+			fmt.Fprintf(&b, "  %s=%s\n", name, value)
+		}
+	}
+
+	b.WriteString("\nConnection:\n")
+	fmt.Fprintf(&b, "  url: %s\n", m.phoenixURL)
+	fmt.Fprintf(&b, "  connected: %t, offline: %t, authenticated: %t, reconnect attempts: %d\n", m.connected, m.offline, m.authenticated, m.reconnectAttempts)
+	if m.serverVersion != "" {
+		fmt.Fprintf(&b, "  server: %s\n", m.serverVersion)
+	}
+	if m.capabilities != nil {
+		var negotiated []string
+		for capability, ok := range m.capabilities {
+			if ok {
+				negotiated = append(negotiated, capability)
+			}
+		}
+		sort.Strings(negotiated)
+		fmt.Fprintf(&b, "  capabilities: %s\n", strings.Join(negotiated, ", "))
+	}
+	fmt.Fprintf(&b, "  conversation: %s, model: %s, provider: %s\n", m.conversationID, orDefault(m.activeModel()), orDefault(m.displayedProvider()))
+
+	b.WriteString("\nConfig:\n")
+	if path := m.config.ProjectConfigPath(); path != "" {
+		fmt.Fprintf(&b, "  (merged with %s)\n", path)
+	}
+	if values, err := configMap(m.config); err == nil {
+		redactConfigValues(values)
+		data, _ := json.MarshalIndent(values, "  ", "  ")
+		fmt.Fprintf(&b, "  %s\n", data)
+	}
+
+	b.WriteString("\nRecent errors:\n")
+	errors := m.statusMessages.RecentErrors(doctorErrorLimit)
+	if len(errors) == 0 {
+		b.WriteString("  (none)\n")
+	}
+	for _, msg := range errors {
+		fmt.Fprintf(&b, "  %s %s\n", msg.Timestamp.Format("15:04:05"), msg.Text)
+	}
+
+	report := b.String()
+	return maskSecrets(report, findSecrets(report, secretPatterns(m.config.TUI.SecretPatterns)))
+}
+
+// orDefault shows an unset setting as "(default)"
+func orDefault(value string) string {
+	if value == "" {
+		return "(default)"
+	}
+	return value
+}
+
+// runDoctor copies the doctor report to the clipboard, or writes it to a
+// file when save is set
+func (m *Model) runDoctor(save bool, name string) {
+	report := m.doctorReport()
+	if !save {
+		if err := clipboard.WriteAll(report); err == nil {
+			m.chat.AddMessage(SystemMessage, "Doctor report copied to the clipboard - paste it into your issue. Secrets are masked; review it before sharing.", "system")
+			m.statusBar = "Doctor report copied"
+			return
+		}
+		// Without a clipboard (e.g. over SSH) the file is the way to share it
+	}
+
+	if name == "" {
+		name = fmt.Sprintf("rubber_duck-doctor-%s.txt", clock.Now().Format("20060102-150405"))
+	}
+	if m.checkPermission(permissionRequest{
+		Action: permissionDoctorReport,
+		Title:  "write the doctor report",
+		Detail: "Write the doctor report to this local file:\n\n  " + name,
+	}, ExecuteCommandMsg{Command: "doctor_save", Args: map[string]string{"file": name}}) != permissionAllowed {
+		return
+	}
+	path := m.projectFile(name)
+	if err := os.WriteFile(path, []byte(report), 0644); err != nil {
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Cannot write doctor report: %v", err), nil)
+		return
+	}
+	m.chat.AddMessage(SystemMessage, fmt.Sprintf("Doctor report written to:\n%s\n\nAttach it to your issue. Secrets are masked; review it before sharing.", path), "system")
+	m.statusBar = "Doctor report saved"
+}
@@ -0,0 +1,50 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+func TestDoctorReportRedactsSecrets(t *testing.T) {
+	testutil.IsolateHome(t)
+	testutil.FreezeClock(t)
+	model := NewModel()
+	model.config.APIKey = "plain-key-value"
+	model.config.Providers = map[string]ProviderConfig{"openai": {APIKey: "another-key", Models: []string{"gpt-4"}}}
+	model.config.TUI.AllowSecrets = true
+	model.statusMessages.AddMessage(StatusCategoryError, "Auth failed for token=abcdefghijklmnop", nil)
+	model.statusMessages.AddMessage(StatusCategoryInfo, "Connected", nil)
+
+	report := model.doctorReport()
+	for _, secret := range []string{"plain-key-value", "another-key", "abcdefghijklmnop"} {
+		if strings.Contains(report, secret) {
+			t.Errorf("Expected %q redacted, got:\n%s", secret, report)
+		}
+	}
+	for _, want := range []string{"Runtime:  go", "\"gpt-4\"", "\"allow_secrets\": true", "Auth failed for token=", "connected: false"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("Expected %q in the report, got:\n%s", want, report)
+		}
+	}
+	if strings.Contains(report, "Connected") {
+		t.Errorf("Expected only errors listed, got:\n%s", report)
+	}
+
+	// Writing the file asks first, then runs once allowed
+	path := filepath.Join(t.TempDir(), "report.txt")
+	model.runDoctor(true, path)
+	if _, err := os.Stat(path); !model.permissionPrompt.IsVisible() || err == nil {
+		t.Fatal("Expected the report to wait for permission")
+	}
+	updated, cmd := model.Update(PermissionDecisionMsg{Action: permissionDoctorReport, Allow: true, Scope: PermissionOnce})
+	*model = updated.(Model)
+	updated, _ = model.Update(cmd())
+	*model = updated.(Model)
+	if data, err := os.ReadFile(path); err != nil || string(data) != report {
+		t.Errorf("Expected the report saved to %s, got %v", path, err)
+	}
+}
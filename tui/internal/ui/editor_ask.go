@@ -0,0 +1,241 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// askPrompt is one question the ask menu offers about the selection
+type askPrompt struct {
+	Key    string
+	Label  string
+	Prompt string
+}
+
+// askPrompts are the questions in the ask menu, in order
+var askPrompts = []askPrompt{
+	{Key: "e", Label: "Explain", Prompt: "Explain what this code does and why."},
+	{Key: "b", Label: "Find bugs", Prompt: "Find bugs in this code. For each, say what goes wrong and how to fix it."},
+	{Key: "o", Label: "Optimize", Prompt: "Suggest how to make this code faster or simpler without changing its behavior."},
+	{Key: "t", Label: "Add tests", Prompt: "Write tests for this code, in the project's test style."},
+}
+
+// AskSelectionMsg sends the editor selection with the chosen question
+type AskSelectionMsg struct {
+	Prompt askPrompt
+}
+
+// editorSelection is a line range of the open file
+type editorSelection struct {
+	Path  string
+	Start int // 1-based, inclusive
+	End   int
+	Text  string
+}
+
+// String formats the range, e.g. "main.go:10-24"
+func (s editorSelection) String() string {
+	if s.Start == s.End {
+		return fmt.Sprintf("%s:%d", s.Path, s.Start)
+	}
+	return fmt.Sprintf("%s:%d-%d", s.Path, s.Start, s.End)
+}
+
+// AskPanel shows the ask menu under the editor and then the answer, so the
+// code stays in view while asking about it
+type AskPanel struct {
+	selection editorSelection
+	cursor    int
+	asked     string // Label of the question sent, empty while choosing
+	answer    string
+	offset    int // First answer line shown
+	visible   bool
+	width     int
+	height    int
+}
+
+// Show opens the menu for a selection
+func (ap *AskPanel) Show(selection editorSelection) {
+	ap.selection = selection
+	ap.cursor = 0
+	ap.asked = ""
+	ap.answer = ""
+	ap.offset = 0
+	ap.visible = true
+}
+
+// Hide hides the panel
+func (ap *AskPanel) Hide() {
+	ap.visible = false
+}
+
+// IsVisible returns whether the panel is visible
+func (ap AskPanel) IsVisible() bool {
+	return ap.visible
+}
+
+// Waiting reports whether a question was sent and not answered yet
+func (ap AskPanel) Waiting() bool {
+	return ap.visible && ap.asked != "" && ap.answer == ""
+}
+
+// SetAnswer shows the answer to the question
+func (ap *AskPanel) SetAnswer(answer string) {
+	ap.answer = answer
+	ap.offset = 0
+}
+
+// SetSize updates the panel dimensions
+func (ap *AskPanel) SetSize(width, height int) {
+	ap.width = width
+	ap.height = height
+}
+
+// answerLines returns the answer wrapped to the panel width
+func (ap AskPanel) answerLines() []string {
+	wrapped := lipgloss.NewStyle().Width(max(ap.width-2, 10)).Render(ap.answer)
+	return strings.Split(wrapped, "\n")
+}
+
+// Update handles panel input
+func (ap AskPanel) Update(msg tea.Msg) (AskPanel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || !ap.visible {
+		return ap, nil
+	}
+
+	key := keyMsg.String()
+	if key == "esc" || key == "alt+a" {
+		ap.Hide()
+		return ap, nil
+	}
+	if ap.asked != "" {
+		room := max(ap.height-3, 1)
+		switch key {
+		case "up", "k":
+			ap.offset = max(ap.offset-1, 0)
+		case "down", "j":
+			ap.offset = min(ap.offset+1, max(len(ap.answerLines())-room, 0))
+		}
+		return ap, nil
+	}
+
+	switch key {
+	case "up", "k":
+		ap.cursor = max(ap.cursor-1, 0)
+	case "down", "j":
+		ap.cursor = min(ap.cursor+1, len(askPrompts)-1)
+	case "enter":
+		return ap.ask(askPrompts[ap.cursor])
+	default:
+		for _, prompt := range askPrompts {
+			if key == prompt.Key {
+				return ap.ask(prompt)
+			}
+		}
+	}
+	return ap, nil
+}
+
+// ask sends the chosen question
+func (ap AskPanel) ask(prompt askPrompt) (AskPanel, tea.Cmd) {
+	ap.asked = prompt.Label
+	return ap, func() tea.Msg { return AskSelectionMsg{Prompt: prompt} }
+}
+
+// View renders the menu or the answer
+func (ap AskPanel) View() string {
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("62"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	cursorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
+
+	title := "Ask about " + ap.selection.String()
+	if ap.asked != "" {
+		title = ap.asked + ": " + ap.selection.String()
+	}
+	lines := []string{titleStyle.Render(truncateStage(title, max(ap.width-2, 10)))}
+
+	switch {
+	case ap.asked == "":
+		for i, prompt := range askPrompts {
+			line := fmt.Sprintf("%s  %s", prompt.Key, prompt.Label)
+			if i == ap.cursor {
+				lines = append(lines, cursorStyle.Render("▶ "+line))
+			} else {
+				lines = append(lines, "  "+line)
+			}
+		}
+		lines = append(lines, dimStyle.Render("Enter/key: Ask | Esc: Cancel"))
+	case ap.answer == "":
+		lines = append(lines, dimStyle.Render("Waiting for the answer..."))
+	default:
+		room := max(ap.height-3, 1)
+		answer := ap.answerLines()
+		lines = append(lines, answer[ap.offset:min(ap.offset+room, len(answer))]...)
+		lines = append(lines, dimStyle.Render("↑/↓: Scroll | Esc: Close (also in chat)"))
+	}
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.NormalBorder(), true, false, false, false).
+		BorderForeground(lipgloss.Color("62")).
+		Width(ap.width).
+		Render(strings.Join(lines, "\n"))
+}
+
+// toggleEditorMark starts a selection at the cursor line, or clears it
+func (m *Model) toggleEditorMark() {
+	if m.editorMark > 0 {
+		m.editorMark = 0
+		m.statusBar = "Selection cleared"
+		return
+	}
+	m.editorMark = m.editor.Line() + 1
+	m.statusBar = fmt.Sprintf("Selection starts at line %d - move the cursor and press Alt+A to ask about it", m.editorMark)
+}
+
+// currentSelection returns the lines between the mark and the cursor, or
+// the cursor line when there is no mark
+func (m Model) currentSelection() editorSelection {
+	lines := strings.Split(m.editor.Value(), "\n")
+	cursor := min(m.editor.Line()+1, len(lines))
+	start, end := cursor, cursor
+	if m.editorMark > 0 {
+		start, end = min(m.editorMark, cursor), max(m.editorMark, cursor)
+		end = min(end, len(lines))
+	}
+	return editorSelection{
+		Path:  m.currentFile,
+		Start: start,
+		End:   end,
+		Text:  strings.Join(lines[start-1:end], "\n"),
+	}
+}
+
+// openAskPanel shows the ask menu for the editor selection
+func (m *Model) openAskPanel() {
+	if m.currentFile == "" {
+		m.statusBar = "Open a file to ask about its code"
+		return
+	}
+	selection := m.currentSelection()
+	if strings.TrimSpace(selection.Text) == "" {
+		m.statusBar = "The selection is empty"
+		return
+	}
+	m.askPanel.Show(selection)
+	m.updateComponentSizes()
+}
+
+// askAboutSelection sends the question with the selection, keeping the
+// editor focused for the answer
+func (m *Model) askAboutSelection(prompt askPrompt) tea.Cmd {
+	selection := m.askPanel.selection
+	m.editorMark = 0
+	content := fmt.Sprintf("%s\n\n`%s` lines %d-%d:\n```%s\n%s\n```",
+		prompt.Prompt, selection.Path, selection.Start, selection.End,
+		languageForPath(selection.Path), strings.TrimRight(selection.Text, "\n"))
+	return func() tea.Msg { return ChatMessageSentMsg{Content: content} }
+}
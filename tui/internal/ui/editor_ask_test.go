@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+func TestAskAboutSelection(t *testing.T) {
+	testutil.IsolateHome(t)
+	model := NewModel()
+	model.width, model.height, model.showEditor = 160, 40, true
+	model.currentFile = "calc.go"
+	model.editor.SetValue("package calc\n\nfunc Div(a, b int) int {\n\treturn a / b\n}")
+	model.editor.CursorUp()
+	model.editor.CursorUp()
+	model.toggleEditorMark()
+	model.editor.CursorDown()
+	model.editor.CursorDown()
+
+	model.openAskPanel()
+	if got := model.askPanel.selection.String(); got != "calc.go:3-5" {
+		t.Fatalf("Expected lines 3-5 selected, got %s", got)
+	}
+
+	var cmd tea.Cmd
+	model.askPanel, cmd = model.askPanel.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+	ask, ok := cmd().(AskSelectionMsg)
+	if !ok || ask.Prompt.Label != "Find bugs" {
+		t.Fatalf("Expected b to ask for bugs, got %+v", ask)
+	}
+	sent := model.askAboutSelection(ask.Prompt)().(ChatMessageSentMsg)
+	for _, want := range []string{"Find bugs", "`calc.go` lines 3-5", "```go\nfunc Div", "return a / b\n}\n```"} {
+		if !strings.Contains(sent.Content, want) {
+			t.Errorf("Expected %q in the message, got:\n%s", want, sent.Content)
+		}
+	}
+
+	// The answer shows under the editor as well as in the chat
+	model.chat.AddMessage(AssistantMessage, "", "assistant")
+	model.showResponse("Div panics when b is 0")
+	if !strings.Contains(model.askPanel.View(), "Div panics when b is 0") {
+		t.Errorf("Expected the answer in the panel, got:\n%s", model.askPanel.View())
+	}
+}
@@ -0,0 +1,469 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxFrequentEmoji limits the frequently used row shown before searching
+const maxFrequentEmoji = 8
+
+// emojiEntry is a symbol the picker offers
+type emojiEntry struct {
+	Symbol   string
+	Name     string
+	Keywords string // Other words it is found by
+}
+
+// emojiCatalog lists the emoji and symbols the picker searches, starting
+// with the status and planning conventions the app and its prompts use
+var emojiCatalog = []emojiEntry{
+	// Status
+	{"✅", "check mark button", "done yes ok pass success complete"},
+	{"✔️", "check mark", "done yes ok tick"},
+	{"❌", "cross mark", "no fail error wrong cancel"},
+	{"⚠️", "warning", "caution alert careful"},
+	{"🚧", "construction", "wip in progress todo"},
+	{"⏳", "hourglass", "waiting pending loading"},
+	{"🔄", "arrows counterclockwise", "retry refresh sync again"},
+	{"🟢", "green circle", "ok healthy up online"},
+	{"🟡", "yellow circle", "degraded warning pending"},
+	{"🔴", "red circle", "down error offline"},
+	{"⚪", "white circle", "unknown idle"},
+	{"🛑", "stop sign", "halt blocked"},
+	{"🚫", "prohibited", "forbidden blocked denied"},
+	{"❓", "question mark", "unknown ask help"},
+	{"❗", "exclamation mark", "important urgent"},
+	{"ℹ️", "information", "info note"},
+	{"🔒", "locked", "secure private lock"},
+	{"🔓", "unlocked", "open unlock"},
+	{"🔑", "key", "auth password api secret"},
+	{"📌", "pushpin", "pin pinned important"},
+	// Planning
+	{"📝", "memo", "note plan write todo"},
+	{"📋", "clipboard", "list tasks copy checklist"},
+	{"🎯", "direct hit", "goal target objective"},
+	{"🗺️", "world map", "plan roadmap"},
+	{"🧭", "compass", "direction strategy"},
+	{"🧩", "puzzle piece", "component part subtask"},
+	{"🔍", "magnifying glass", "search find investigate review"},
+	{"🧪", "test tube", "test experiment"},
+	{"🔬", "microscope", "analysis research inspect"},
+	{"📊", "bar chart", "stats metrics data"},
+	{"📈", "chart increasing", "growth up trend improve"},
+	{"📉", "chart decreasing", "down decline regression"},
+	{"📅", "calendar", "date schedule deadline"},
+	{"⏰", "alarm clock", "time deadline reminder"},
+	{"🏁", "chequered flag", "finish done milestone"},
+	{"🚀", "rocket", "launch deploy ship release fast"},
+	{"📦", "package", "release dependency box bundle"},
+	{"🏷️", "label", "tag version"},
+	{"🗂️", "card index dividers", "organize files categories"},
+	{"📁", "file folder", "directory"},
+	{"📄", "page facing up", "file document"},
+	{"🔗", "link", "url reference chain"},
+	// Code
+	{"🐛", "bug", "defect issue error fix"},
+	{"🔧", "wrench", "fix tool config"},
+	{"🔨", "hammer", "build tool"},
+	{"🛠️", "hammer and wrench", "tools build fix"},
+	{"⚙️", "gear", "settings config"},
+	{"♻️", "recycling symbol", "refactor reuse"},
+	{"✨", "sparkles", "new feature shiny"},
+	{"🔥", "fire", "hot remove delete performance"},
+	{"💥", "collision", "breaking crash boom"},
+	{"⚡", "high voltage", "fast performance zap"},
+	{"💡", "light bulb", "idea tip hint"},
+	{"🧹", "broom", "cleanup chore"},
+	{"🗑️", "wastebasket", "delete remove trash"},
+	{"🚑", "ambulance", "hotfix critical"},
+	{"🩹", "adhesive bandage", "patch simple fix"},
+	{"🔀", "shuffle tracks", "merge"},
+	{"⏪", "fast reverse", "revert rollback"},
+	{"🧵", "thread", "concurrency thread"},
+	{"🔐", "locked with key", "security encryption"},
+	{"🌐", "globe with meridians", "web network internet i18n"},
+	{"💾", "floppy disk", "save"},
+	{"🖥️", "desktop computer", "screen terminal"},
+	{"⌨️", "keyboard", "input type"},
+	{"🤖", "robot", "bot ai automation"},
+	{"🦆", "duck", "rubber duck debugging"},
+	// People and reactions
+	{"👍", "thumbs up", "yes agree approve like +1"},
+	{"👎", "thumbs down", "no disagree dislike -1"},
+	{"👀", "eyes", "look watching review"},
+	{"🙏", "folded hands", "thanks please"},
+	{"👏", "clapping hands", "applause bravo"},
+	{"🎉", "party popper", "celebrate tada congrats"},
+	{"🙂", "slightly smiling face", "smile happy"},
+	{"😀", "grinning face", "smile happy"},
+	{"😅", "grinning face with sweat", "relief oops"},
+	{"😂", "face with tears of joy", "laugh lol"},
+	{"🤔", "thinking face", "hmm consider"},
+	{"😕", "confused face", "unsure"},
+	{"😬", "grimacing face", "awkward yikes"},
+	{"🤯", "exploding head", "mind blown"},
+	{"❤️", "red heart", "love like"},
+	{"💯", "hundred points", "perfect 100"},
+	{"⭐", "star", "favorite important"},
+	// Arrows and symbols
+	{"→", "rightwards arrow", "arrow right then next"},
+	{"←", "leftwards arrow", "arrow left back"},
+	{"↑", "upwards arrow", "arrow up"},
+	{"↓", "downwards arrow", "arrow down"},
+	{"↔", "left right arrow", "arrow both"},
+	{"⇒", "rightwards double arrow", "implies arrow"},
+	{"↪", "rightwards arrow with hook", "reply return"},
+	{"▶", "play button", "triangle right start"},
+	{"◆", "black diamond", "bullet"},
+	{"•", "bullet", "dot point"},
+	{"…", "horizontal ellipsis", "dots more"},
+	{"—", "em dash", "dash"},
+	{"–", "en dash", "dash range"},
+	{"✓", "check", "tick yes done"},
+	{"✗", "ballot x", "no cross fail"},
+	{"★", "black star", "star rating"},
+	{"☆", "white star", "star rating"},
+	{"§", "section sign", "section"},
+	{"¶", "pilcrow", "paragraph"},
+	{"©", "copyright", "copyright"},
+	{"®", "registered", "trademark"},
+	{"™", "trade mark", "trademark tm"},
+	{"°", "degree", "temperature angle"},
+	// Math
+	{"±", "plus minus", "math approximately"},
+	{"×", "multiplication", "times math"},
+	{"÷", "division", "divide math"},
+	{"≈", "almost equal to", "approximately math"},
+	{"≠", "not equal to", "math"},
+	{"≤", "less than or equal to", "math lte"},
+	{"≥", "greater than or equal to", "math gte"},
+	{"∞", "infinity", "math forever"},
+	{"√", "square root", "math"},
+	{"∑", "n-ary summation", "sum sigma math"},
+	{"∆", "increment", "delta change math"},
+	{"∈", "element of", "in set math"},
+	{"∅", "empty set", "null none math"},
+	{"λ", "greek small letter lambda", "lambda function"},
+	{"μ", "greek small letter mu", "micro"},
+	{"π", "greek small letter pi", "pi math"},
+	{"Ω", "greek capital letter omega", "ohm"},
+	// Currency
+	{"€", "euro sign", "currency money"},
+	{"£", "pound sign", "currency money"},
+	{"¥", "yen sign", "currency money"},
+	{"₿", "bitcoin sign", "currency crypto"},
+}
+
+// EmojiPickedMsg inserts a symbol picked in the emoji picker
+type EmojiPickedMsg struct {
+	Symbol string
+}
+
+// EmojiPicker searches emoji and unicode symbols to insert in the chat
+// input, listing the frequently used ones first
+type EmojiPicker struct {
+	query   string
+	usage   map[string]int // Times each symbol was picked
+	cursor  int
+	visible bool
+	width   int
+	height  int
+}
+
+// Show opens the picker with a search, loading the usage counts
+func (ep *EmojiPicker) Show(query string, usage map[string]int) {
+	ep.query = query
+	ep.usage = usage
+	ep.cursor = 0
+	ep.visible = true
+}
+
+// Hide hides the picker
+func (ep *EmojiPicker) Hide() {
+	ep.visible = false
+}
+
+// IsVisible returns whether the picker is visible
+func (ep EmojiPicker) IsVisible() bool {
+	return ep.visible
+}
+
+// SetSize updates the picker dimensions
+func (ep *EmojiPicker) SetSize(width, height int) {
+	ep.width = width
+	ep.height = height
+}
+
+// frequent returns the most used symbols, most used first
+func (ep EmojiPicker) frequent() []emojiEntry {
+	var entries []emojiEntry
+	for symbol, count := range ep.usage {
+		if count > 0 {
+			entries = append(entries, lookupEmoji(symbol))
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := ep.usage[entries[i].Symbol], ep.usage[entries[j].Symbol]
+		if a != b {
+			return a > b
+		}
+		return entries[i].Symbol < entries[j].Symbol
+	})
+	if len(entries) > maxFrequentEmoji {
+		entries = entries[:maxFrequentEmoji]
+	}
+	return entries
+}
+
+// results returns the entries matching the search, best matches first and
+// frequently used ones ahead of others that match as well. Without a search
+// the frequently used come first, then the whole catalog
+func (ep EmojiPicker) results() []emojiEntry {
+	query := strings.ToLower(strings.TrimSpace(ep.query))
+	if query == "" {
+		frequent := ep.frequent()
+		seen := make(map[string]bool, len(frequent))
+		for _, entry := range frequent {
+			seen[entry.Symbol] = true
+		}
+		results := frequent
+		for _, entry := range emojiCatalog {
+			if !seen[entry.Symbol] {
+				results = append(results, entry)
+			}
+		}
+		return results
+	}
+
+	type match struct {
+		entry emojiEntry
+		rank  int
+	}
+	var matches []match
+	if entry, ok := codePointEntry(query); ok {
+		matches = append(matches, match{entry, -1})
+	}
+	for _, entry := range emojiCatalog {
+		if rank := emojiRank(entry, query); rank >= 0 {
+			matches = append(matches, match{entry, rank})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].rank != matches[j].rank {
+			return matches[i].rank < matches[j].rank
+		}
+		return ep.usage[matches[i].entry.Symbol] > ep.usage[matches[j].entry.Symbol]
+	})
+	results := make([]emojiEntry, len(matches))
+	for i, match := range matches {
+		results[i] = match.entry
+	}
+	return results
+}
+
+// emojiRank ranks how well an entry matches a search: 0 when its name
+// starts with it, 1 when a word of the name does, 2 for a keyword, and -1
+// when it doesn't match
+func emojiRank(entry emojiEntry, query string) int {
+	switch {
+	case strings.HasPrefix(entry.Name, query):
+		return 0
+	case strings.Contains(" "+entry.Name, " "+query):
+		return 1
+	case strings.Contains(" "+entry.Keywords, " "+query) || strings.Contains(entry.Name, query):
+		return 2
+	}
+	return -1
+}
+
+// codePointEntry reads a search like "U+2713" or "2713" as a code point
+func codePointEntry(query string) (emojiEntry, bool) {
+	hex := strings.TrimPrefix(strings.TrimPrefix(query, "u+"), "0x")
+	if len(hex) < 2 || len(hex) > 6 {
+		return emojiEntry{}, false
+	}
+	code, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || !utf8.ValidRune(rune(code)) || !unicode.IsGraphic(rune(code)) {
+		return emojiEntry{}, false
+	}
+	return lookupEmoji(string(rune(code))), true
+}
+
+// lookupEmoji returns a symbol's catalog entry, or one named by its code
+// points when the catalog doesn't have it
+func lookupEmoji(symbol string) emojiEntry {
+	for _, entry := range emojiCatalog {
+		if entry.Symbol == symbol {
+			return entry
+		}
+	}
+	var points []string
+	for _, r := range symbol {
+		points = append(points, fmt.Sprintf("U+%04X", r))
+	}
+	return emojiEntry{Symbol: symbol, Name: strings.Join(points, " ")}
+}
+
+// Update handles picker input: typing searches, and Enter inserts the
+// selected symbol
+func (ep EmojiPicker) Update(msg tea.Msg) (EmojiPicker, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || !ep.visible {
+		return ep, nil
+	}
+
+	results := ep.results()
+	switch keyMsg.String() {
+	case "esc", "ctrl+.", "alt+.":
+		ep.Hide()
+	case "up", "ctrl+p":
+		ep.cursor = max(ep.cursor-1, 0)
+	case "down", "ctrl+n":
+		ep.cursor = min(ep.cursor+1, max(len(results)-1, 0))
+	case "backspace":
+		if ep.query != "" {
+			_, size := utf8.DecodeLastRuneInString(ep.query)
+			ep.query = ep.query[:len(ep.query)-size]
+			ep.cursor = 0
+		}
+	case "ctrl+u":
+		ep.query = ""
+		ep.cursor = 0
+	case "enter":
+		if ep.cursor >= len(results) {
+			break
+		}
+		picked := EmojiPickedMsg{Symbol: results[ep.cursor].Symbol}
+		ep.Hide()
+		return ep, func() tea.Msg { return picked }
+	default:
+		if keyMsg.Type == tea.KeyRunes || keyMsg.Type == tea.KeySpace {
+			ep.query += string(keyMsg.Runes)
+			ep.cursor = 0
+		}
+	}
+	return ep, nil
+}
+
+// View renders the search and its results in a box
+func (ep EmojiPicker) View() string {
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("62"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	cursorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
+
+	lines := []string{titleStyle.Render("Insert emoji or symbol"), "Search: " + ep.query + "█", ""}
+	results := ep.results()
+	frequent := 0
+	if strings.TrimSpace(ep.query) == "" {
+		frequent = len(ep.frequent())
+	}
+	if len(results) == 0 {
+		lines = append(lines, dimStyle.Render("No matches - search by name, or by code point like U+2713"))
+	}
+	room := max(ep.height-12, 3)
+	start := max(min(ep.cursor-room/2, len(results)-room), 0)
+	for i := start; i < min(start+room, len(results)); i++ {
+		entry := results[i]
+		symbol := entry.Symbol + strings.Repeat(" ", max(3-lipgloss.Width(entry.Symbol), 1))
+		row := symbol + truncateStage(entry.Name, 40)
+		if i < frequent {
+			row += dimStyle.Render(fmt.Sprintf("  used %d×", ep.usage[entry.Symbol]))
+		}
+		if i == ep.cursor {
+			row = cursorStyle.Render("▶ ") + row
+		} else {
+			row = "  " + row
+		}
+		lines = append(lines, row)
+	}
+	lines = append(lines, "", dimStyle.Render("Type to search | ↑/↓: Select | Enter: Insert | Esc: Close"))
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Padding(0, 1).
+		Width(min(max(ep.width/2, 50), 70)).
+		Render(strings.Join(lines, "\n"))
+}
+
+// emojiUsagePath returns the file the picker's usage counts are saved in
+func emojiUsagePath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".rubber_duck", "emoji.json"), nil
+}
+
+// loadEmojiUsage reads how often each symbol was picked
+func loadEmojiUsage() (map[string]int, error) {
+	path, err := emojiUsagePath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return map[string]int{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	usage := map[string]int{}
+	if err := json.Unmarshal(data, &usage); err != nil {
+		return nil, fmt.Errorf("emoji.json is unreadable: %w", err)
+	}
+	return usage, nil
+}
+
+// saveEmojiUsage writes how often each symbol was picked
+func saveEmojiUsage(usage map[string]int) error {
+	path, err := emojiUsagePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(usage, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// openEmojiPicker shows the picker, searching for query
+func (m *Model) openEmojiPicker(query string) {
+	usage, err := loadEmojiUsage()
+	if err != nil {
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Cannot read frequently used emoji: %v", err), nil)
+		usage = map[string]int{}
+	}
+	m.emojiPicker.SetSize(m.width, m.height)
+	m.emojiPicker.Show(query, usage)
+}
+
+// insertEmoji puts a picked symbol in the chat input at the cursor and
+// counts it as used
+func (m *Model) insertEmoji(symbol string) {
+	m.focusPane(ChatPane)
+	m.chat.input.InsertString(symbol)
+	usage := m.emojiPicker.usage
+	if usage == nil {
+		usage = map[string]int{}
+	}
+	usage[symbol]++
+	if err := saveEmojiUsage(usage); err != nil {
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Cannot save frequently used emoji: %v", err), nil)
+	}
+}
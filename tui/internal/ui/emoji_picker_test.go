@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+func TestEmojiPickerSearch(t *testing.T) {
+	var picker EmojiPicker
+	picker.Show("", map[string]int{"🐛": 3, "→": 5})
+
+	results := picker.results()
+	if len(results) < 2 || results[0].Symbol != "→" || results[1].Symbol != "🐛" {
+		t.Fatalf("Expected the most used symbols first, got %v", results[:2])
+	}
+	for _, entry := range results[2:] {
+		if entry.Symbol == "→" || entry.Symbol == "🐛" {
+			t.Errorf("Expected frequently used symbols listed once, found %s again", entry.Symbol)
+		}
+	}
+
+	for _, r := range "check" {
+		picker, _ = picker.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	if results := picker.results(); len(results) == 0 || results[0].Symbol != "✅" {
+		t.Errorf("Expected the check mark button first for \"check\", got %v", results)
+	}
+
+	picker.query = "U+2713"
+	if results := picker.results(); len(results) == 0 || results[0].Symbol != "✓" {
+		t.Errorf("Expected a code point search to find ✓, got %v", results)
+	}
+	if _, ok := codePointEntry("zz"); ok {
+		t.Error("Expected a non-hex search not to be read as a code point")
+	}
+}
+
+func TestEmojiPickerInsertsAndRemembers(t *testing.T) {
+	testutil.IsolateHome(t)
+	model := NewModel()
+	model.chat.SetInputValue("done ")
+
+	updated, _ := model.Update(ExecuteCommandMsg{Command: "emoji", Args: map[string]string{"query": "rocket"}})
+	*model = updated.(Model)
+	if !model.emojiPicker.IsVisible() {
+		t.Fatal("Expected the picker open")
+	}
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	*model = updated.(Model)
+	if cmd == nil {
+		t.Fatal("Expected Enter to pick the selection")
+	}
+	updated, _ = model.Update(cmd())
+	*model = updated.(Model)
+
+	if got := model.chat.GetInputValue(); got != "done 🚀" {
+		t.Errorf("Expected the rocket inserted, got %q", got)
+	}
+	usage, err := loadEmojiUsage()
+	if err != nil || usage["🚀"] != 1 {
+		t.Errorf("Expected the pick counted, got %v (%v)", usage, err)
+	}
+}
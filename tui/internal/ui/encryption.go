@@ -0,0 +1,302 @@
+package ui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"github.com/rubber_duck/tui/internal/vault"
+)
+
+// Ways tui.encryption can get the transcript key
+const (
+	EncryptionKeychain   = "keychain"   // A random key kept in the OS keychain
+	EncryptionPassphrase = "passphrase" // A key derived from a passphrase asked for at startup
+)
+
+// passphraseEnv holds the passphrase when it can't be typed, e.g. headless
+const passphraseEnv = "RUBBER_DUCK_PASSPHRASE"
+
+// Where the keychain key is stored
+const (
+	keychainService = "rubber_duck"
+	keychainAccount = "transcripts"
+)
+
+// encryptionCheck is sealed into encryption.json so a wrong key is caught
+// before it is used
+var encryptionCheck = []byte("rubber_duck transcripts")
+
+// transcriptVault seals saved conversations, cleared conversations, and
+// spilled history; nil while encryption is off
+var transcriptVault atomic.Pointer[vault.Vault]
+
+// setTranscriptVault sets the vault used for transcripts, nil to turn
+// encryption off
+func setTranscriptVault(v *vault.Vault) {
+	transcriptVault.Store(v)
+}
+
+// sealTranscript encrypts transcript data when encryption is on
+func sealTranscript(data []byte) ([]byte, error) {
+	return transcriptVault.Load().Seal(data)
+}
+
+// openTranscript decrypts transcript data; plaintext passes through
+func openTranscript(data []byte) ([]byte, error) {
+	return transcriptVault.Load().Open(data)
+}
+
+// encryptionState is ~/.rubber_duck/encryption.json: what the key of the
+// encrypted transcripts was made from, and a value sealed with it
+type encryptionState struct {
+	Mode  string `json:"mode"`
+	Salt  []byte `json:"salt,omitempty"` // PBKDF2 salt of a passphrase key
+	Check []byte `json:"check"`          // encryptionCheck sealed with the key
+}
+
+// encryptionStatePath returns where the encryption state is kept
+func encryptionStatePath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".rubber_duck", "encryption.json"), nil
+}
+
+// loadEncryptionState reads the encryption state, nil when there is none
+func loadEncryptionState() (*encryptionState, error) {
+	path, err := encryptionStatePath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var state encryptionState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("encryption.json is unreadable: %w", err)
+	}
+	return &state, nil
+}
+
+// saveEncryptionState writes the encryption state
+func saveEncryptionState(state encryptionState) error {
+	path, err := encryptionStatePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// SetupEncryption unlocks transcript encryption as tui.encryption asks,
+// before any conversation is read or written. ask reads a passphrase typed
+// at startup; it is used when RUBBER_DUCK_PASSPHRASE isn't set
+func (m *Model) SetupEncryption(ask func(prompt string) (string, error)) error {
+	mode := m.config.TUI.Encryption
+	if mode == "" {
+		setTranscriptVault(nil)
+		return nil
+	}
+	if mode != EncryptionKeychain && mode != EncryptionPassphrase {
+		return fmt.Errorf("unknown tui.encryption %q: use %q or %q", mode, EncryptionKeychain, EncryptionPassphrase)
+	}
+
+	state, err := loadEncryptionState()
+	if err != nil {
+		return err
+	}
+	if state != nil && state.Mode != mode {
+		return fmt.Errorf("transcripts are encrypted with a %s key, but tui.encryption is %q; set it back to read them", state.Mode, mode)
+	}
+
+	var key, salt []byte
+	if mode == EncryptionKeychain {
+		key, err = vault.KeychainKey(keychainService, keychainAccount)
+	} else {
+		if state != nil {
+			salt = state.Salt
+		} else if salt, err = vault.NewSalt(); err != nil {
+			return err
+		}
+		var passphrase string
+		if passphrase, err = transcriptPassphrase(ask, state == nil); err == nil {
+			key, err = vault.DeriveKey(passphrase, salt)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	v, err := vault.New(key)
+	if err != nil {
+		return err
+	}
+
+	if state != nil {
+		if plain, err := v.Open(state.Check); err != nil || string(plain) != string(encryptionCheck) {
+			if mode == EncryptionPassphrase {
+				return errors.New("wrong passphrase for the encrypted transcripts")
+			}
+			return errors.New("the keychain key doesn't match the one the transcripts were encrypted with")
+		}
+	} else {
+		check, err := v.Seal(encryptionCheck)
+		if err != nil {
+			return err
+		}
+		if err := saveEncryptionState(encryptionState{Mode: mode, Salt: salt, Check: check}); err != nil {
+			return err
+		}
+	}
+	setTranscriptVault(v)
+	return nil
+}
+
+// transcriptPassphrase gets the passphrase from the environment or by
+// asking, asking twice when it is being chosen
+func transcriptPassphrase(ask func(prompt string) (string, error), choosing bool) (string, error) {
+	if passphrase := os.Getenv(passphraseEnv); passphrase != "" {
+		return passphrase, nil
+	}
+	if ask == nil {
+		return "", fmt.Errorf("set %s to unlock the encrypted transcripts", passphraseEnv)
+	}
+	if !choosing {
+		return ask("Transcript passphrase: ")
+	}
+	passphrase, err := ask("Choose a transcript passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if passphrase == "" {
+		return "", errors.New("the transcript passphrase can't be empty")
+	}
+	confirm, err := ask("Confirm the passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if confirm != passphrase {
+		return "", errors.New("the passphrases didn't match")
+	}
+	return passphrase, nil
+}
+
+// transcriptFiles lists the saved and cleared conversation files
+func transcriptFiles() ([]string, error) {
+	var files []string
+	for _, dirFunc := range []func() (string, error){conversationsDir, recycleDir} {
+		dir, err := dirFunc()
+		if err != nil {
+			return nil, err
+		}
+		matches, err := filepath.Glob(filepath.Join(dir, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+// migrateTranscripts encrypts the conversation files still in plaintext,
+// returning how many it encrypted
+func migrateTranscripts() (int, error) {
+	if transcriptVault.Load() == nil {
+		return 0, fmt.Errorf("encryption is off; set tui.encryption to %q or %q and restart", EncryptionKeychain, EncryptionPassphrase)
+	}
+	files, err := transcriptFiles()
+	if err != nil {
+		return 0, err
+	}
+	migrated := 0
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return migrated, err
+		}
+		if vault.IsSealed(data) {
+			continue
+		}
+		sealed, err := sealTranscript(data)
+		if err != nil {
+			return migrated, err
+		}
+		// Write beside the file and rename, so a failure never leaves it half written
+		tmp := file + ".tmp"
+		if err := os.WriteFile(tmp, sealed, 0600); err != nil {
+			return migrated, err
+		}
+		if err := os.Rename(tmp, file); err != nil {
+			os.Remove(tmp)
+			return migrated, err
+		}
+		migrated++
+	}
+	return migrated, nil
+}
+
+// encryptionStatus describes transcript encryption for /encrypt
+func (m Model) encryptionStatus() string {
+	var b strings.Builder
+	switch {
+	case transcriptVault.Load() == nil:
+		fmt.Fprintf(&b, "Transcript encryption is off. Set tui.encryption to %q or %q and restart to encrypt saved conversations and history", EncryptionKeychain, EncryptionPassphrase)
+	case m.config.TUI.Encryption == EncryptionKeychain:
+		b.WriteString("Transcripts are encrypted with a key kept in the OS keychain")
+	default:
+		b.WriteString("Transcripts are encrypted with a key derived from your passphrase")
+	}
+
+	files, err := transcriptFiles()
+	if err != nil {
+		fmt.Fprintf(&b, "\n\nCannot list the saved conversations: %v", err)
+		return b.String()
+	}
+	sealed, plain := 0, 0
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		if vault.IsSealed(data) {
+			sealed++
+		} else {
+			plain++
+		}
+	}
+	fmt.Fprintf(&b, "\n\n%d conversation files encrypted, %d in plaintext", sealed, plain)
+	if plain > 0 && transcriptVault.Load() != nil {
+		b.WriteString("\nRun /encrypt migrate to encrypt the plaintext ones")
+	}
+	return b.String()
+}
+
+// migrateEncryption encrypts existing plaintext transcripts for /encrypt migrate
+func (m *Model) migrateEncryption() {
+	migrated, err := migrateTranscripts()
+	if err != nil {
+		text := fmt.Sprintf("Cannot encrypt the conversations: %v", err)
+		if migrated > 0 {
+			text += fmt.Sprintf(" (%d encrypted before it)", migrated)
+		}
+		m.statusMessages.AddMessage(StatusCategoryError, text, nil)
+		return
+	}
+	m.statusBar = fmt.Sprintf("Encrypted %d conversation files", migrated)
+	m.chat.AddMessage(SystemMessage, m.statusBar, "system")
+}
@@ -0,0 +1,59 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rubber_duck/tui/internal/testutil"
+	"github.com/rubber_duck/tui/internal/vault"
+)
+
+func TestSetupEncryption_PassphraseSealsConversations(t *testing.T) {
+	testutil.IsolateHome(t)
+	t.Cleanup(func() { setTranscriptVault(nil) })
+
+	// A conversation saved before encryption was turned on
+	if err := SaveConversation(SavedConversation{ID: "old", Title: "Old", Messages: []ChatMessage{{Type: UserMessage, Content: "plain secret"}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	model := NewModel()
+	model.config.TUI.Encryption = EncryptionPassphrase
+	t.Setenv(passphraseEnv, "hunter22")
+	if err := model.SetupEncryption(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SaveConversation(SavedConversation{ID: "new", Title: "New", Messages: []ChatMessage{{Type: UserMessage, Content: "sealed secret"}}}); err != nil {
+		t.Fatal(err)
+	}
+	dir, _ := conversationsDir()
+	data, _ := os.ReadFile(filepath.Join(dir, "new.json"))
+	if !vault.IsSealed(data) || strings.Contains(string(data), "sealed secret") {
+		t.Fatal("Expected the conversation to be written encrypted")
+	}
+	conversations, err := ListSavedConversations()
+	if err != nil || len(conversations) != 2 {
+		t.Fatalf("Expected the encrypted and plaintext conversations to load, got %d, %v", len(conversations), err)
+	}
+
+	if migrated, err := migrateTranscripts(); err != nil || migrated != 1 {
+		t.Fatalf("Expected the plaintext conversation to be migrated, got %d, %v", migrated, err)
+	}
+	data, _ = os.ReadFile(filepath.Join(dir, "old.json"))
+	if !vault.IsSealed(data) {
+		t.Fatal("Expected the migrated conversation to be encrypted")
+	}
+	if conv, err := loadConversationByID("old"); err != nil || conv.Messages[0].Content != "plain secret" {
+		t.Fatalf("Expected the migrated conversation to load, got %v", err)
+	}
+
+	// The next start with a wrong passphrase is refused
+	setTranscriptVault(nil)
+	t.Setenv(passphraseEnv, "hunter23")
+	if err := model.SetupEncryption(nil); err == nil || !strings.Contains(err.Error(), "wrong passphrase") {
+		t.Fatalf("Expected a wrong passphrase to be refused, got %v", err)
+	}
+}
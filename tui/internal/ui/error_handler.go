@@ -5,6 +5,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/rubber_duck/tui/internal/phoenix"
 )
 
 // ErrorHandler manages error display and prevents spam
@@ -55,7 +57,7 @@ func (e *ErrorHandler) HandleError(err error, component string) (display bool, m
 			}
 			
 			// Show suppression message only once, then stay silent
-			message = fmt.Sprintf("%s: Connection failed. Further errors suppressed to prevent spam.", component)
+			message = fmt.Sprintf("%s: Connection failed. Further errors are counted in /problems instead of shown.", component)
 			return true, message
 		}
 		
@@ -92,64 +94,45 @@ func (e *ErrorHandler) Reset() {
 	e.backoffDuration = time.Second
 }
 
-// formatErrorMessage creates a user-friendly error message
+// formatErrorMessage creates a user-friendly error message tagged with its
+// catalog code
 func formatErrorMessage(err error, component string) string {
-	errStr := err.Error()
-	
-	// Common connection errors
-	if strings.Contains(errStr, "connection refused") {
-		return fmt.Sprintf("%s: Cannot connect to server. Is the Phoenix server running on the correct port?", component)
-	}
-	
-	if strings.Contains(errStr, "timeout") {
-		// Preserve the original error message if it contains event information
-		if strings.Contains(errStr, "event:") {
-			return fmt.Sprintf("%s: %v", component, err)
-		}
-		return fmt.Sprintf("%s: Connection timeout. The server might be slow or unreachable.", component)
+	if info := phoenix.ClassifyError(err); info.Code != phoenix.ErrUnknown {
+		return fmt.Sprintf("%s: %v [%s]", component, err, info.Code)
 	}
-	
-	if strings.Contains(errStr, "websocket") && strings.Contains(errStr, "bad handshake") {
-		// Include the actual error for debugging
-		return fmt.Sprintf("%s: WebSocket handshake failed - %v", component, err)
-	}
-	
-	if strings.Contains(errStr, "authentication") || strings.Contains(errStr, "unauthorized") {
-		return fmt.Sprintf("%s: Authentication failed. Please check your credentials.", component)
-	}
-	
-	// Default format
 	return fmt.Sprintf("%s: %v", component, err)
 }
 
-// ConnectionError represents a connection-specific error
-type ConnectionError struct {
-	Err           error
-	Component     string
-	Retryable     bool
-	RetryAfter    time.Duration
-	SuggestedFix  string
-}
-
-func (c ConnectionError) Error() string {
-	return c.Err.Error()
+// GetConnectionAdvice returns the catalog's hint for an error, pointing at
+// its help page, or "" when the catalog has no advice
+func GetConnectionAdvice(err error) string {
+	info := phoenix.ClassifyError(err)
+	if info.Code == phoenix.ErrUnknown {
+		return ""
+	}
+	return fmt.Sprintf("Tip: %s (/help errors %s)", info.Hint, info.Code)
 }
 
-// GetConnectionAdvice provides helpful advice for connection errors
-func GetConnectionAdvice(err error) string {
-	errStr := err.Error()
-	
-	if strings.Contains(errStr, "connection refused") {
-		return "Tip: Make sure the Phoenix server is running with 'mix phx.server' and listening on port 5555"
+// GetErrorHelp describes one error code, or lists them all when code is ""
+func GetErrorHelp(code string) (string, bool) {
+	var b strings.Builder
+	if code == "" {
+		b.WriteString("Errors show their code in brackets, e.g. [E101]. /help errors <code> explains one.\n\n")
+		for _, info := range phoenix.ErrorCatalog() {
+			fmt.Fprintf(&b, "  %s  %s\n", info.Code, info.Title)
+		}
+		return b.String(), true
 	}
-	
-	if strings.Contains(errStr, "no such host") {
-		return "Tip: Check that the server hostname is correct and reachable"
+
+	info, ok := phoenix.LookupError(code)
+	if !ok {
+		return "", false
 	}
-	
-	if strings.Contains(errStr, "certificate") {
-		return "Tip: For development, you may need to use ws:// instead of wss://"
+	fmt.Fprintf(&b, "%s: %s\n\n%s\n", info.Code, info.Title, info.Hint)
+	if info.Retryable {
+		b.WriteString("\nTrying again can help: the client retries on reconnect, and Ctrl+R retries now.\n")
+	} else {
+		b.WriteString("\nTrying again won't help until the cause is fixed.\n")
 	}
-	
-	return ""
-}
\ No newline at end of file
+	return b.String(), true
+}
@@ -0,0 +1,122 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// nextFallback returns the next untried model in the chain after the one that failed
+func nextFallback(chain []ModelRef, failed ModelRef, tried []ModelRef) (ModelRef, bool) {
+	// Continue after the failed model if it is part of the chain
+	start := 0
+	for i, ref := range chain {
+		if ref == failed {
+			start = i + 1
+			break
+		}
+	}
+
+	for _, ref := range chain[start:] {
+		if ref == failed || containsModelRef(tried, ref) {
+			continue
+		}
+		return ref, true
+	}
+	return ModelRef{}, false
+}
+
+// containsModelRef reports whether refs contains ref
+func containsModelRef(refs []ModelRef, ref ModelRef) bool {
+	for _, r := range refs {
+		if r == ref {
+			return true
+		}
+	}
+	return false
+}
+
+// parseFallbackChain parses "provider/model" entries separated by spaces or commas
+func parseFallbackChain(spec string) ([]ModelRef, error) {
+	var chain []ModelRef
+	for _, entry := range strings.FieldsFunc(spec, func(r rune) bool { return r == ',' || r == ' ' }) {
+		provider, model, ok := strings.Cut(entry, "/")
+		if !ok || provider == "" || model == "" {
+			return nil, fmt.Errorf("invalid fallback entry %q (expected provider/model)", entry)
+		}
+		chain = append(chain, ModelRef{Provider: provider, Model: model})
+	}
+	return chain, nil
+}
+
+// formatFallbackChain renders the chain as "a → b → c"
+func formatFallbackChain(chain []ModelRef) string {
+	if len(chain) == 0 {
+		return "(none)"
+	}
+	var names []string
+	for _, ref := range chain {
+		names = append(names, ref.String())
+	}
+	return strings.Join(names, " → ")
+}
+
+// handleProviderError offers or performs a retry with the next fallback model
+func (m Model) handleProviderError(msg phoenix.ProviderErrorMsg) (Model, tea.Cmd) {
+	if m.isProcessing {
+		m.latency.Fail()
+	}
+	m.isProcessing = false
+	m.activity.Stop()
+
+	// The server may omit the failing model; assume the one we sent
+	failed := ModelRef{Provider: msg.Provider, Model: msg.Model}
+	if failed.Model == "" {
+		if m.activeFallback != nil {
+			failed = *m.activeFallback
+		} else {
+			failed = ModelRef{Provider: m.activeProvider(), Model: m.activeModel()}
+		}
+	}
+	reason := msg.Message
+	if reason == "" {
+		reason = "provider error"
+	}
+	m.fallbackTried = append(m.fallbackTried, failed)
+
+	next, ok := nextFallback(m.config.FallbackChain, failed, m.fallbackTried)
+	if !ok || m.lastPrompt == "" {
+		m.statusBar = fmt.Sprintf("%s failed", failed)
+		m.chat.AddMessage(ErrorMessage, fmt.Sprintf("%s failed: %s", failed, reason), "system")
+		return m, nil
+	}
+
+	if m.config.AutoFallback {
+		m.chat.AddMessage(SystemMessage, fmt.Sprintf("%s failed: %s\nRetrying with %s...", failed, reason, next), "system")
+		return m.sendWithFallback(next)
+	}
+
+	m.pendingFallback = &next
+	m.statusBar = fmt.Sprintf("%s failed - /fallback to retry with %s", failed, next)
+	m.chat.AddMessage(SystemMessage, fmt.Sprintf("%s failed: %s\nType /fallback to retry with %s", failed, reason, next), "system")
+	return m, nil
+}
+
+// sendWithFallback resends the last prompt using a fallback model
+func (m Model) sendWithFallback(ref ModelRef) (Model, tea.Cmd) {
+	client, ok := m.phoenixClient.(*phoenix.Client)
+	if !ok || !m.connected {
+		m.statusMessages.AddMessage(StatusCategoryError, "Not connected to server", nil)
+		return m, nil
+	}
+
+	// The user's selected model stays unchanged; only this request uses the fallback
+	m.activeFallback = &ref
+	m.pendingFallback = nil
+	m.isProcessing = true
+	m.latency.Begin(ref.Provider, ref.Model)
+	m.statusBar = fmt.Sprintf("Retrying with %s...", ref)
+	return m, client.SendReplyWithConfig(m.lastPrompt, m.lastReplyTo, ref.Model, ref.Provider, m.activeTemperature())
+}
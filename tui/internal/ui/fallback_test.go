@@ -0,0 +1,72 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rubber_duck/tui/internal/phoenix"
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+func TestNextFallback(t *testing.T) {
+	a, b, c := ModelRef{"openai", "gpt-4"}, ModelRef{"anthropic", "claude"}, ModelRef{"ollama", "llama3"}
+	chain := []ModelRef{a, b, c}
+	tests := []struct {
+		name   string
+		failed ModelRef
+		tried  []ModelRef
+		want   ModelRef
+		ok     bool
+	}{
+		{"after the failed model", a, nil, b, true},
+		{"from the start when not in the chain", ModelRef{"other", "x"}, nil, a, true},
+		{"skips tried models", a, []ModelRef{b}, c, true},
+		{"end of the chain", c, nil, ModelRef{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := nextFallback(chain, tt.failed, tt.tried)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("nextFallback(%s) = %s, %v; want %s, %v", tt.failed, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestAutoFallback_AdvancesUntilTheChainEnds(t *testing.T) {
+	testutil.IsolateHome(t)
+	model := NewModel()
+	model.phoenixClient = phoenix.NewClient()
+	model.connected = true
+	model.lastPrompt = "explain this"
+	model.config.AutoFallback = true
+	model.config.FallbackChain = []ModelRef{{"openai", "gpt-4"}, {"anthropic", "claude"}, {"ollama", "llama3"}}
+
+	// The first failure names its model; later ones leave it to the fallback
+	// in flight
+	failures := []phoenix.ProviderErrorMsg{
+		{Provider: "openai", Model: "gpt-4", Message: "rate limited"},
+		{Message: "overloaded"},
+	}
+	for i, failure := range failures {
+		next, cmd := model.handleProviderError(failure)
+		*model = next
+		want := model.config.FallbackChain[i+1]
+		if cmd == nil || model.activeFallback == nil || *model.activeFallback != want || !model.isProcessing {
+			t.Fatalf("Failure %d: expected a retry with %s, got %v", i+1, want, model.activeFallback)
+		}
+	}
+
+	next, cmd := model.handleProviderError(phoenix.ProviderErrorMsg{Message: "unreachable"})
+	*model = next
+	if cmd != nil || model.isProcessing {
+		t.Fatal("Expected no retry once the chain is exhausted")
+	}
+	messages := model.chat.GetMessages()
+	if last := messages[len(messages)-1]; last.Type != ErrorMessage || !strings.Contains(last.Content, "ollama/llama3 failed: unreachable") {
+		t.Errorf("Expected the last model's failure reported, got %+v", last)
+	}
+	if len(model.fallbackTried) != 3 {
+		t.Errorf("Expected all three models tried, got %v", model.fallbackTried)
+	}
+}
@@ -0,0 +1,248 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// fileWatchInterval is how often local files are polled for changes
+const fileWatchInterval = 2 * time.Second
+
+// FileWatchTickMsg triggers a poll of the open file and file tree
+type FileWatchTickMsg time.Time
+
+// FileWatchResultMsg carries a poll of the local disk made off the update loop
+type FileWatchResultMsg struct {
+	Listing *FileListingMsg // Nil when the file tree is hidden
+	Path    string          // The open file, if it was checked
+	Stamp   fileStamp
+}
+
+// fileStamp identifies a version of a file on disk
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+	exists  bool
+}
+
+// statFile returns the current stamp of a local file
+func statFile(path string) fileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{modTime: info.ModTime(), size: info.Size(), exists: true}
+}
+
+// WatchFile starts polling local files for external changes
+func WatchFile() tea.Cmd {
+	return tea.Tick(fileWatchInterval, func(t time.Time) tea.Msg {
+		return FileWatchTickMsg(t)
+	})
+}
+
+// handleFileWatchTick checks the open buffer and file tree against the local
+// disk in a command, so a large tree doesn't stall the update loop
+func (m Model) handleFileWatchTick() (Model, tea.Cmd) {
+	// Server-backed files are watched through server push instead
+	if m.fileTree.FileSystem().Name() != FileSourceLocal {
+		return m, WatchFile()
+	}
+
+	var list tea.Cmd
+	if m.showFileTree {
+		list = m.fileTree.loadDirs(m.fileTree.expandedPaths())
+	}
+	path := m.currentFile
+	if m.fileChangedOnDisk {
+		path = ""
+	}
+	local := m.projectFile(path)
+	return m, func() tea.Msg {
+		result := FileWatchResultMsg{Path: path}
+		if list != nil {
+			listing := list().(FileListingMsg)
+			result.Listing = &listing
+		}
+		if path != "" {
+			result.Stamp = statFile(local)
+		}
+		return result
+	}
+}
+
+// handleFileWatchResult applies a poll, rebuilding the tree only when its
+// listing changed, and schedules the next one
+func (m Model) handleFileWatchResult(msg FileWatchResultMsg) (Model, tea.Cmd) {
+	if msg.Listing != nil && m.fileTree.listingChanged(*msg.Listing) {
+		m.fileTree.ApplyListing(*msg.Listing)
+	}
+	if msg.Path != "" && msg.Path == m.currentFile && !m.fileChangedOnDisk && msg.Stamp != m.currentFileStamp {
+		m.currentFileStamp = msg.Stamp
+		return m.handleFileChanged(msg.Path, !msg.Stamp.exists, WatchFile())
+	}
+	return m, WatchFile()
+}
+
+// handleFileChanged reacts to an external change of a file
+func (m Model) handleFileChanged(path string, removed bool, next tea.Cmd) (Model, tea.Cmd) {
+	if path != m.currentFile {
+		return m, next
+	}
+
+	if removed {
+		m.fileChangedOnDisk = true
+		m.statusBar = fmt.Sprintf("%s was deleted on disk", path)
+		return m, next
+	}
+
+	// Unmodified buffers reload silently
+	if m.editor.Value() == m.editorOriginal {
+		m, _ = m.reloadCurrentFile()
+		m.statusBar = fmt.Sprintf("Reloaded %s (changed on disk)", path)
+		return m, next
+	}
+
+	m.fileChangedOnDisk = true
+	m.statusBar = fmt.Sprintf("%s changed on disk - /reload, /keep, or /diff", path)
+	return m, next
+}
+
+// handleServerFileChanged handles file change events pushed by the server
+func (m Model) handleServerFileChanged(msg phoenix.FileChangedMsg) (Model, tea.Cmd) {
+	if m.fileTree.FileSystem().Name() != FileSourceServer {
+		return m, nil
+	}
+	var refresh tea.Cmd
+	if msg.Event == "created" || msg.Event == "deleted" {
+		refresh = m.fileTree.Refresh()
+	}
+	return m.handleFileChanged(msg.Path, msg.Event == "deleted", refresh)
+}
+
+// reloadCurrentFile replaces the buffer with the file's content on disk
+func (m Model) reloadCurrentFile() (Model, tea.Cmd) {
+	data, err := m.fileTree.FileSystem().ReadFile(m.currentFile)
+	if err != nil {
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Cannot reload %s: %v", m.currentFile, err), nil)
+		return m, nil
+	}
+	m.editor.SetValue(string(data))
+	m.editorOriginal = string(data)
+	m.hunksApplied = false
+	m.currentFileStamp = statFile(m.projectFile(m.currentFile))
+	m.fileChangedOnDisk = false
+	m.refreshOutline()
+	m.statusBar = fmt.Sprintf("Reloaded %s", m.currentFile)
+	return m, nil
+}
+
+// keepBuffer dismisses the change banner, keeping the edited buffer
+func (m Model) keepBuffer() (Model, tea.Cmd) {
+	m.fileChangedOnDisk = false
+	m.statusBar = fmt.Sprintf("Kept buffer for %s", m.currentFile)
+	return m, nil
+}
+
+// diffBuffer shows the difference between the buffer and the file on disk
+func (m Model) diffBuffer() (Model, tea.Cmd) {
+	data, err := m.fileTree.FileSystem().ReadFile(m.currentFile)
+	if err != nil {
+		data = nil
+	}
+	diff := lineDiff(m.editor.Value(), string(data))
+	if diff == "" {
+		m.chat.AddMessage(SystemMessage, fmt.Sprintf("%s: buffer matches the file on disk", m.currentFile), "system")
+		return m, nil
+	}
+	m.chat.AddMessage(SystemMessage, fmt.Sprintf("Changes on disk for %s (- buffer, + disk):\n```diff\n%s\n```", m.currentFile, diff), "system")
+	return m, nil
+}
+
+// renderFileChangedBanner renders the banner shown above a stale editor buffer
+func (m Model) renderFileChangedBanner(width int) string {
+	return lipgloss.NewStyle().
+		Background(lipgloss.Color("214")).
+		Foreground(lipgloss.Color("0")).
+		Width(width).
+		Render("File changed on disk: /reload, /keep, /diff")
+}
+
+// maxDiffLines limits how much of a diff is shown
+const maxDiffLines = 200
+
+// lineDiff returns a line diff between old and new, prefixing removed lines
+// with "-" and added lines with "+"; unchanged lines are omitted
+func lineDiff(old, new string) string {
+	a := strings.Split(old, "\n")
+	b := strings.Split(new, "\n")
+
+	// Common prefix and suffix don't need the LCS table
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		a, b = a[1:], b[1:]
+	}
+	for len(a) > 0 && len(b) > 0 && a[len(a)-1] == b[len(b)-1] {
+		a, b = a[:len(a)-1], b[:len(b)-1]
+	}
+
+	// Very different large files are shown as a full replacement
+	if len(a)*len(b) > 1_000_000 {
+		var lines []string
+		for _, line := range a {
+			lines = append(lines, "-"+line)
+		}
+		for _, line := range b {
+			lines = append(lines, "+"+line)
+		}
+		return truncateDiff(lines)
+	}
+
+	// Longest common subsequence table
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var lines []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			i++
+			j++
+		case j < len(b) && (i == len(a) || lcs[i][j+1] >= lcs[i+1][j]):
+			lines = append(lines, "+"+b[j])
+			j++
+		default:
+			lines = append(lines, "-"+a[i])
+			i++
+		}
+	}
+
+	return truncateDiff(lines)
+}
+
+// truncateDiff joins diff lines, keeping at most maxDiffLines
+func truncateDiff(lines []string) string {
+	if len(lines) > maxDiffLines {
+		lines = append(lines[:maxDiffLines], fmt.Sprintf("... %d more lines", len(lines)-maxDiffLines))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,83 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+func TestLineDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		want     string
+	}{
+		{"identical", "a\nb\nc", "a\nb\nc", ""},
+		{"changed line", "a\nb\nc", "a\nB\nc", "+B\n-b"},
+		{"added line", "a\nc", "a\nb\nc", "+b"},
+		{"removed line", "a\nb\nc", "a\nc", "-b"},
+		{"from empty", "", "x", "+x\n-"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lineDiff(tt.old, tt.new); got != tt.want {
+				t.Errorf("lineDiff(%q, %q) = %q, want %q", tt.old, tt.new, got, tt.want)
+			}
+		})
+	}
+
+	var old, new []string
+	for i := 0; i < maxDiffLines+10; i++ {
+		old = append(old, "old")
+		new = append(new, "new")
+	}
+	got := lineDiff(strings.Join(old, "\n"), strings.Join(new, "\n"))
+	if !strings.HasSuffix(got, "more lines") || strings.Count(got, "\n") != maxDiffLines {
+		t.Errorf("Expected a long diff cut to %d lines, got %d", maxDiffLines, strings.Count(got, "\n")+1)
+	}
+}
+
+func TestFileWatch_PollsOffTheUpdateLoop(t *testing.T) {
+	testutil.IsolateHome(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.txt")
+	os.WriteFile(path, []byte("first\n"), 0644)
+
+	model := NewModel()
+	model.showFileTree = true
+	model.fileTree.SetFileSystem(NewLocalFS(dir))
+	updated, _ := model.Update(FileSelectedMsg{Path: path})
+	*model = updated.(Model)
+
+	// Nothing changed: the tick only returns a command, and its result
+	// leaves the tree alone
+	os.WriteFile(filepath.Join(dir, "added.txt"), nil, 0644)
+	updated, cmd := model.Update(FileWatchTickMsg{})
+	*model = updated.(Model)
+	if len(model.fileTree.items) != 1 || cmd == nil {
+		t.Fatalf("Expected the disk read left to a command, got %d items", len(model.fileTree.items))
+	}
+	result, ok := cmd().(FileWatchResultMsg)
+	if !ok || result.Listing == nil || !model.fileTree.listingChanged(*result.Listing) {
+		t.Fatalf("Expected the new file noticed, got %#v", result)
+	}
+	updated, _ = model.Update(result)
+	*model = updated.(Model)
+	if len(model.fileTree.items) != 2 {
+		t.Fatalf("Expected the new file listed, got %d items", len(model.fileTree.items))
+	}
+	if model.fileTree.listingChanged(*result.Listing) {
+		t.Error("Expected an unchanged listing to leave the tree alone")
+	}
+
+	os.WriteFile(path, []byte("second, longer\n"), 0644)
+	_, cmd = model.Update(FileWatchTickMsg{})
+	updated, _ = model.Update(cmd())
+	*model = updated.(Model)
+	if model.editor.Value() != "second, longer\n" {
+		t.Errorf("Expected the unmodified buffer reloaded, got %q", model.editor.Value())
+	}
+}
@@ -0,0 +1,218 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// File source modes selectable in config
+const (
+	FileSourceLocal  = "local"
+	FileSourceServer = "server"
+)
+
+// maxEditorFileSize limits the size of files loaded into the editor
+const maxEditorFileSize = 1024 * 1024
+
+// FileSystem lists and loads files for the file tree and editor
+type FileSystem interface {
+	Name() string
+	Root() string
+	ReadDir(path string) ([]FileNode, error)
+	ReadFile(path string) ([]byte, error)
+	WriteFile(path string, data []byte) error
+}
+
+// LocalFS serves files from the local working directory
+type LocalFS struct {
+	root string
+}
+
+// NewLocalFS creates a LocalFS rooted at root
+func NewLocalFS(root string) *LocalFS {
+	return &LocalFS{root: root}
+}
+
+// Name returns the file system name
+func (fs *LocalFS) Name() string {
+	return FileSourceLocal
+}
+
+// Root returns the root directory
+func (fs *LocalFS) Root() string {
+	return fs.root
+}
+
+// resolve returns where a path in the tree is, relative to the root
+func (fs *LocalFS) resolve(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(fs.root, path)
+}
+
+// ReadDir lists a directory, skipping hidden entries
+func (fs *LocalFS) ReadDir(path string) ([]FileNode, error) {
+	entries, err := os.ReadDir(fs.resolve(path))
+	if err != nil {
+		return nil, err
+	}
+
+	var nodes []FileNode
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		nodes = append(nodes, FileNode{
+			Name:  entry.Name(),
+			Path:  filepath.Join(path, entry.Name()),
+			IsDir: entry.IsDir(),
+		})
+	}
+	sortFileNodes(nodes)
+	return nodes, nil
+}
+
+// ReadFile loads a text file
+func (fs *LocalFS) ReadFile(path string) ([]byte, error) {
+	path = fs.resolve(path)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() > maxEditorFileSize {
+		return nil, fmt.Errorf("larger than %d KB", maxEditorFileSize/1024)
+	}
+	return os.ReadFile(path)
+}
+
+// WriteFile saves a file, keeping its permissions and creating missing
+// directories for new files
+func (fs *LocalFS) WriteFile(path string, data []byte) error {
+	path = fs.resolve(path)
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	} else if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, mode)
+}
+
+// ServerFS serves files through the server's file API
+type ServerFS struct {
+	client  *phoenix.Client
+	chunked bool // The server takes large files in chunks
+}
+
+// NewServerFS creates a ServerFS using the conversation channel client.
+// chunked uploads files over phoenix.ChunkThreshold in chunks
+func NewServerFS(client *phoenix.Client, chunked bool) *ServerFS {
+	return &ServerFS{client: client, chunked: chunked}
+}
+
+// Name returns the file system name
+func (fs *ServerFS) Name() string {
+	return FileSourceServer
+}
+
+// Root returns the root directory
+func (fs *ServerFS) Root() string {
+	return "."
+}
+
+// Invalidate forgets cached directory listings, so the next read asks the
+// server
+func (fs *ServerFS) Invalidate() {
+	fs.client.Cache().Clear("list_files")
+}
+
+// ReadDir lists a directory on the server
+func (fs *ServerFS) ReadDir(path string) ([]FileNode, error) {
+	entries, err := fs.client.ListFiles(context.Background(), path)
+	if err != nil {
+		return nil, err
+	}
+
+	var nodes []FileNode
+	for _, entry := range entries {
+		entryPath := entry.Path
+		if entryPath == "" {
+			entryPath = filepath.Join(path, entry.Name)
+		}
+		nodes = append(nodes, FileNode{Name: entry.Name, Path: entryPath, IsDir: entry.IsDir})
+	}
+	sortFileNodes(nodes)
+	return nodes, nil
+}
+
+// ReadFile loads a file from the server
+func (fs *ServerFS) ReadFile(path string) ([]byte, error) {
+	content, err := fs.client.ReadFile(context.Background(), path)
+	if err != nil {
+		return nil, err
+	}
+	if len(content) > maxEditorFileSize {
+		return nil, fmt.Errorf("larger than %d KB", maxEditorFileSize/1024)
+	}
+	return []byte(content), nil
+}
+
+// WriteFile saves a file on the server
+func (fs *ServerFS) WriteFile(path string, data []byte) error {
+	if fs.chunked && len(data) > phoenix.ChunkThreshold {
+		return fs.client.Upload(context.Background(), path, data, nil)
+	}
+	return fs.client.WriteFile(context.Background(), path, string(data))
+}
+
+// readsOverNetwork reports whether a file system's reads go to the server,
+// so they must not run inside Update
+func readsOverNetwork(fs FileSystem) bool {
+	return fs != nil && fs.Name() == FileSourceServer
+}
+
+// applyFileSource points the file tree at the configured file system, falling
+// back to local files when offline or when the server has no file API. The
+// server's tree is listed by the returned command
+func (m *Model) applyFileSource() tea.Cmd {
+	if m.config.TUI.FileSource == FileSourceServer && !m.offline {
+		if !m.capabilities.Has(phoenix.CapabilityFiles) {
+			m.statusMessages.AddMessage(StatusCategoryInfo, "The server does not support the file API - using local files", nil)
+		} else if client, ok := m.phoenixClient.(*phoenix.Client); ok && m.channel != nil {
+			return m.fileTree.SetFileSystem(NewServerFS(client, m.capabilities.Has(phoenix.CapabilityChunkedTransfer)))
+		}
+	}
+	if m.fileTree.FileSystem().Name() != FileSourceLocal {
+		m.fileTree.SetFileSystem(NewLocalFS(m.projectDir))
+	}
+	return nil
+}
+
+// handleFileListing fills the tree with listings read in the background,
+// falling back to local files when the server can't list the top directory
+func (m *Model) handleFileListing(msg FileListingMsg) {
+	m.fileTree.ApplyListing(msg)
+	if msg.Err == nil || msg.FS != m.fileTree.FileSystem() || !readsOverNetwork(msg.FS) || m.fileTree.Loaded() {
+		return
+	}
+	m.statusMessages.AddMessage(StatusCategoryInfo, fmt.Sprintf("Server file API unavailable (%v) - using local files", msg.Err), nil)
+	m.fileTree.SetFileSystem(NewLocalFS(m.projectDir))
+}
+
+// sortFileNodes orders directories first, then by name
+func sortFileNodes(nodes []FileNode) {
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].IsDir != nodes[j].IsDir {
+			return nodes[i].IsDir
+		}
+		return strings.ToLower(nodes[i].Name) < strings.ToLower(nodes[j].Name)
+	})
+}
@@ -1,6 +1,12 @@
 package ui
 
-import tea "github.com/charmbracelet/bubbletea"
+import (
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
 
 // FileNode represents a file or directory in the tree
 type FileNode struct {
@@ -9,6 +15,7 @@ type FileNode struct {
 	IsDir    bool
 	Children []FileNode
 	Expanded bool
+	Loaded   bool
 }
 
 // FileTree represents the file tree component
@@ -19,6 +26,22 @@ type FileTree struct {
 	width    int
 	height   int
 	focused  bool
+	err      string
+	fs       FileSystem
+}
+
+// dirListing is the contents of one directory
+type dirListing struct {
+	Path  string
+	Nodes []FileNode
+}
+
+// FileListingMsg delivers directory listings read off the update loop,
+// parents before children
+type FileListingMsg struct {
+	FS       FileSystem
+	Listings []dirListing
+	Err      error
 }
 
 // FileItem represents a flattened item for display
@@ -30,25 +53,384 @@ type FileItem struct {
 
 // NewFileTree creates a new file tree component
 func NewFileTree() *FileTree {
-	return &FileTree{
+	ft := &FileTree{
 		root: FileNode{
-			Name:  "Project",
-			Path:  ".",
-			IsDir: true,
+			Name:     "Project",
+			Path:     ".",
+			IsDir:    true,
+			Expanded: true,
 		},
 		selected: 0,
 		items:    []FileItem{},
+		fs:       NewLocalFS("."),
+	}
+	ft.Refresh()
+	return ft
+}
+
+// SetFileSystem switches the tree to another file system and reloads it
+func (ft *FileTree) SetFileSystem(fs FileSystem) tea.Cmd {
+	ft.fs = fs
+	ft.root = FileNode{
+		Name:     "Project",
+		Path:     fs.Root(),
+		IsDir:    true,
+		Expanded: true,
+	}
+	ft.selected = 0
+	return ft.Refresh()
+}
+
+// FileSystem returns the file system backing the tree
+func (ft *FileTree) FileSystem() FileSystem {
+	return ft.fs
+}
+
+// Err returns the last error from loading the tree
+func (ft *FileTree) Err() string {
+	return ft.err
+}
+
+// SetFocused marks whether the tree has focus, which highlights its title
+func (ft *FileTree) SetFocused(focused bool) {
+	ft.focused = focused
+}
+
+// Refresh reloads the tree from its file system, keeping expanded
+// directories. Server listings are read by the returned command
+func (ft *FileTree) Refresh() tea.Cmd {
+	if readsOverNetwork(ft.fs) {
+		return ft.loadDirs(ft.expandedPaths())
+	}
+	selected := ft.SelectedPath()
+	ft.err = ""
+	ft.reload(&ft.root)
+	ft.flatten()
+	
+	// Keep the selection on the same file when entries are added or removed
+	for i, item := range ft.items {
+		if item.node.Path == selected {
+			ft.selected = i
+			break
+		}
+	}
+	return nil
+}
+
+// expandedPaths returns the root and the loaded expanded directories below
+// it, parents first
+func (ft *FileTree) expandedPaths() []string {
+	paths := []string{ft.root.Path}
+	var walk func(nodes []FileNode)
+	walk = func(nodes []FileNode) {
+		for _, node := range nodes {
+			if node.IsDir && node.Expanded && node.Loaded {
+				paths = append(paths, node.Path)
+				walk(node.Children)
+			}
+		}
 	}
+	walk(ft.root.Children)
+	return paths
+}
+
+// loadDirs reads directories in the background. A failure to read the first
+// directory is reported; later ones that can't be read, such as directories
+// removed since they were expanded, are skipped
+func (ft *FileTree) loadDirs(paths []string) tea.Cmd {
+	fs := ft.fs
+	return func() tea.Msg {
+		msg := FileListingMsg{FS: fs}
+		for i, path := range paths {
+			nodes, err := fs.ReadDir(path)
+			if err != nil && i == 0 {
+				msg.Err = err
+				break
+			}
+			if err != nil {
+				continue
+			}
+			msg.Listings = append(msg.Listings, dirListing{Path: path, Nodes: nodes})
+		}
+		return msg
+	}
+}
+
+// ApplyListing fills the tree with listings read in the background,
+// keeping expanded directories and the selection. Listings from a file
+// system the tree no longer shows are dropped
+func (ft *FileTree) ApplyListing(msg FileListingMsg) {
+	if msg.FS != ft.fs {
+		return
+	}
+	selected := ft.SelectedPath()
+	ft.err = ""
+	if msg.Err != nil {
+		ft.err = msg.Err.Error()
+	}
+	for _, listing := range msg.Listings {
+		node := findNode(&ft.root, listing.Path)
+		if node == nil {
+			continue
+		}
+		expanded := make(map[string]bool)
+		for _, child := range node.Children {
+			if child.Expanded {
+				expanded[child.Path] = true
+			}
+		}
+		node.Children = listing.Nodes
+		node.Loaded = true
+		for i := range node.Children {
+			node.Children[i].Expanded = expanded[node.Children[i].Path]
+		}
+	}
+	ft.flatten()
+	for i, item := range ft.items {
+		if item.node.Path == selected {
+			ft.selected = i
+			break
+		}
+	}
+}
+
+// listingChanged reports whether listings differ from what the tree shows
+func (ft *FileTree) listingChanged(msg FileListingMsg) bool {
+	if msg.FS != ft.fs {
+		return false
+	}
+	if msg.Err != nil {
+		return msg.Err.Error() != ft.err
+	}
+	if ft.err != "" {
+		return true
+	}
+	for _, listing := range msg.Listings {
+		node := findNode(&ft.root, listing.Path)
+		if node == nil {
+			continue
+		}
+		if !node.Loaded || len(node.Children) != len(listing.Nodes) {
+			return true
+		}
+		for i, child := range node.Children {
+			if n := listing.Nodes[i]; child.Name != n.Name || child.Path != n.Path || child.IsDir != n.IsDir {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Loaded reports whether the tree's top directory has been read
+func (ft *FileTree) Loaded() bool {
+	return ft.root.Loaded
+}
+
+// reload loads a directory's children, recursing into expanded directories
+func (ft *FileTree) reload(node *FileNode) {
+	expanded := make(map[string]bool)
+	for _, child := range node.Children {
+		if child.Expanded {
+			expanded[child.Path] = true
+		}
+	}
+
+	children, err := ft.fs.ReadDir(node.Path)
+	if err != nil {
+		ft.err = err.Error()
+		return
+	}
+	node.Children = children
+	node.Loaded = true
+
+	for i := range node.Children {
+		if expanded[node.Children[i].Path] {
+			node.Children[i].Expanded = true
+			ft.reload(&node.Children[i])
+		}
+	}
+}
+
+// flatten rebuilds the display list from the expanded nodes
+func (ft *FileTree) flatten() {
+	ft.items = ft.items[:0]
+	var walk func(nodes []FileNode, depth int)
+	walk = func(nodes []FileNode, depth int) {
+		for i, node := range nodes {
+			ft.items = append(ft.items, FileItem{node: node, depth: depth, isLast: i == len(nodes)-1})
+			if node.IsDir && node.Expanded {
+				walk(node.Children, depth+1)
+			}
+		}
+	}
+	walk(ft.root.Children, 0)
+
+	if ft.selected >= len(ft.items) {
+		ft.selected = len(ft.items) - 1
+	}
+	if ft.selected < 0 {
+		ft.selected = 0
+	}
+}
+
+// findNode returns the node with the given path
+func findNode(node *FileNode, path string) *FileNode {
+	if node.Path == path {
+		return node
+	}
+	for i := range node.Children {
+		if found := findNode(&node.Children[i], path); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// SelectedPath returns the path of the selected item
+func (ft FileTree) SelectedPath() string {
+	if ft.selected < 0 || ft.selected >= len(ft.items) {
+		return ""
+	}
+	return ft.items[ft.selected].node.Path
+}
+
+// setExpanded expands or collapses the selected directory. A server
+// directory's contents are read by the returned command
+func (ft *FileTree) setExpanded(expanded bool) tea.Cmd {
+	node := findNode(&ft.root, ft.SelectedPath())
+	if node == nil || !node.IsDir {
+		return nil
+	}
+	if expanded && !node.Loaded && readsOverNetwork(ft.fs) {
+		node.Expanded = true
+		ft.flatten()
+		return ft.loadDirs([]string{node.Path})
+	}
+	if expanded && !node.Loaded {
+		children, err := ft.fs.ReadDir(node.Path)
+		if err != nil {
+			ft.err = err.Error()
+			return nil
+		}
+		node.Children = children
+		node.Loaded = true
+	}
+	node.Expanded = expanded
+	ft.flatten()
+	return nil
 }
 
 // Update handles file tree updates
 func (ft FileTree) Update(msg tea.Msg) (FileTree, tea.Cmd) {
-	// TODO: Implement file tree update logic
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || len(ft.items) == 0 && keyMsg.String() != "r" {
+		return ft, nil
+	}
+
+	switch keyMsg.String() {
+	case "up", "k":
+		if ft.selected > 0 {
+			ft.selected--
+		}
+	case "down", "j":
+		if ft.selected < len(ft.items)-1 {
+			ft.selected++
+		}
+	case "enter", "right", "l":
+		item := ft.items[ft.selected]
+		if item.node.IsDir {
+			cmd := ft.setExpanded(!item.node.Expanded || keyMsg.String() != "enter")
+			return ft, cmd
+		}
+		path := item.node.Path
+		return ft, func() tea.Msg {
+			return FileSelectedMsg{Path: path}
+		}
+	case "left", "h":
+		item := ft.items[ft.selected]
+		if item.node.IsDir && item.node.Expanded {
+			ft.setExpanded(false)
+			return ft, nil
+		}
+		// Jump to the parent directory
+		parent := filepath.Dir(item.node.Path)
+		for i, candidate := range ft.items {
+			if candidate.node.Path == parent {
+				ft.selected = i
+				break
+			}
+		}
+	case "r":
+		// An explicit refresh skips cached listings
+		if cached, ok := ft.fs.(interface{ Invalidate() }); ok {
+			cached.Invalidate()
+		}
+		return ft, ft.Refresh()
+	}
 	return ft, nil
 }
 
 // View renders the file tree
 func (ft FileTree) View() string {
-	// TODO: Implement file tree view
-	return "File tree (not yet implemented)"
-}
\ No newline at end of file
+	titleStyle := paneTitleStyle(ft.focused)
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	selectedStyle := lipgloss.NewStyle().
+		Background(lipgloss.Color("62")).
+		Foreground(lipgloss.Color("230"))
+
+	lines := []string{titleStyle.Render(ft.rootLabel())}
+	if ft.err != "" {
+		lines = append(lines, lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(ft.err))
+	}
+	if len(ft.items) == 0 {
+		lines = append(lines, dimStyle.Render("(empty)"))
+		return strings.Join(lines, "\n")
+	}
+
+	// Keep the selection within the visible window
+	visible := ft.height - len(lines) - 2
+	if visible < 1 {
+		visible = len(ft.items)
+	}
+	start := 0
+	if ft.selected >= visible {
+		start = ft.selected - visible + 1
+	}
+	end := start + visible
+	if end > len(ft.items) {
+		end = len(ft.items)
+	}
+
+	for i := start; i < end; i++ {
+		item := ft.items[i]
+		icon := "📄 "
+		if item.node.IsDir {
+			icon = "▸ "
+			if item.node.Expanded {
+				icon = "▾ "
+			}
+		}
+		line := strings.Repeat("  ", item.depth) + icon + item.node.Name
+		if ft.width > 4 && lipgloss.Width(line) > ft.width-2 {
+			line = truncateStage(line, ft.width-2)
+		}
+		if i == ft.selected {
+			line = selectedStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// rootLabel returns the name of the directory shown at the top of the tree
+func (ft FileTree) rootLabel() string {
+	if ft.fs != nil && ft.fs.Name() == FileSourceServer {
+		return ft.root.Name + " (server)"
+	}
+	if abs, err := filepath.Abs(ft.root.Path); err == nil {
+		return filepath.Base(abs)
+	}
+	return ft.root.Name
+}
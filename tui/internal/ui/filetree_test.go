@@ -0,0 +1,114 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+func TestFileTree_ExpandAndSelect(t *testing.T) {
+	dir := t.TempDir()
+	os.Mkdir(filepath.Join(dir, "lib"), 0755)
+	os.WriteFile(filepath.Join(dir, "lib", "app.ex"), []byte("defmodule App do\nend\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "README.md"), []byte("# App\n"), 0644)
+	os.WriteFile(filepath.Join(dir, ".hidden"), []byte(""), 0644)
+
+	ft := FileTree{}
+	ft.SetFileSystem(NewLocalFS(dir))
+
+	// Directories first, hidden files skipped
+	if len(ft.items) != 2 {
+		t.Fatalf("Expected 2 items, got %d", len(ft.items))
+	}
+	if ft.items[0].node.Name != "lib" {
+		t.Errorf("Expected directory first, got %s", ft.items[0].node.Name)
+	}
+
+	// Expanding the directory shows its children
+	ft, _ = ft.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if len(ft.items) != 3 {
+		t.Fatalf("Expected 3 items after expanding, got %d", len(ft.items))
+	}
+
+	// Selecting a file emits FileSelectedMsg
+	ft, _ = ft.Update(tea.KeyMsg{Type: tea.KeyDown})
+	_, cmd := ft.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Expected a command when selecting a file")
+	}
+	selected, ok := cmd().(FileSelectedMsg)
+	if !ok || selected.Path != filepath.Join(dir, "lib", "app.ex") {
+		t.Errorf("Expected FileSelectedMsg for app.ex, got %#v", cmd())
+	}
+}
+
+// remoteFS is a server file system that counts its reads
+type remoteFS struct {
+	LocalFS
+	reads int
+}
+
+func (fs *remoteFS) Name() string { return FileSourceServer }
+
+func (fs *remoteFS) ReadDir(path string) ([]FileNode, error) {
+	fs.reads++
+	return fs.LocalFS.ReadDir(path)
+}
+
+func (fs *remoteFS) ReadFile(path string) ([]byte, error) {
+	fs.reads++
+	return fs.LocalFS.ReadFile(path)
+}
+
+func TestFileTree_ServerReadsRunInCommands(t *testing.T) {
+	testutil.IsolateHome(t)
+	dir := t.TempDir()
+	os.Mkdir(filepath.Join(dir, "lib"), 0755)
+	os.WriteFile(filepath.Join(dir, "lib", "app.ex"), []byte("defmodule App do\n  def run, do: :ok\nend\n"), 0644)
+
+	model := NewModel()
+	fs := &remoteFS{LocalFS: LocalFS{root: dir}}
+	cmd := model.fileTree.SetFileSystem(fs)
+	if fs.reads != 0 || cmd == nil {
+		t.Fatalf("Expected the listing left to a command, got %d reads", fs.reads)
+	}
+	updated, _ := model.Update(cmd())
+	*model = updated.(Model)
+	if len(model.fileTree.items) != 1 || model.fileTree.items[0].node.Name != "lib" {
+		t.Fatalf("Expected the listing applied, got %d items", len(model.fileTree.items))
+	}
+
+	// Expanding a directory reads it in the background too
+	*model.fileTree, cmd = model.fileTree.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if fs.reads != 1 || cmd == nil {
+		t.Fatalf("Expected the directory read left to a command, got %d reads", fs.reads)
+	}
+	updated, _ = model.Update(cmd())
+	*model = updated.(Model)
+	if len(model.fileTree.items) != 2 {
+		t.Fatalf("Expected the directory's file listed, got %d items", len(model.fileTree.items))
+	}
+
+	path := filepath.Join(dir, "lib", "app.ex")
+	updated, cmd = model.Update(FileSelectedMsg{Path: path, Line: 2})
+	*model = updated.(Model)
+	if fs.reads != 2 || cmd == nil || model.currentFile != "" {
+		t.Fatalf("Expected the file read left to a command, got %d reads", fs.reads)
+	}
+	updated, _ = model.Update(cmd())
+	*model = updated.(Model)
+	if model.currentFile != path || model.editor.Line() != 1 {
+		t.Errorf("Expected %s opened at line 2, got %q at line %d", path, model.currentFile, model.editor.Line()+1)
+	}
+
+	// A server that can't list the top directory falls back to local files
+	cmd = model.fileTree.SetFileSystem(&remoteFS{LocalFS: LocalFS{root: filepath.Join(dir, "missing")}})
+	updated, _ = model.Update(cmd())
+	*model = updated.(Model)
+	if model.fileTree.FileSystem().Name() != FileSourceLocal {
+		t.Error("Expected a failed server listing to fall back to local files")
+	}
+}
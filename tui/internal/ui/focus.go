@@ -0,0 +1,83 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// paneNames labels the panes in focus messages
+var paneNames = map[Pane]string{
+	ChatPane:     "Chat",
+	FileTreePane: "File tree",
+	EditorPane:   "Editor",
+	OutlinePane:  "Outline",
+}
+
+// focusKeys maps the direct-focus shortcuts to their panes. Terminals can't
+// report Ctrl+digit, so the scheme lives on Alt+1..4
+var focusKeys = map[string]Pane{
+	"alt+1": ChatPane,
+	"alt+2": FileTreePane,
+	"alt+3": EditorPane,
+	"alt+4": OutlinePane,
+}
+
+// paneTitleStyle styles a pane's title, highlighted while the pane has focus
+func paneTitleStyle(focused bool) lipgloss.Style {
+	style := lipgloss.NewStyle().Bold(true)
+	if focused {
+		return style.Foreground(lipgloss.Color("62"))
+	}
+	return style.Foreground(lipgloss.Color("245"))
+}
+
+// isInputMsg reports whether a message is keyboard or mouse input, which
+// only the focused pane may see
+func isInputMsg(msg tea.Msg) bool {
+	switch msg.(type) {
+	case tea.KeyMsg, tea.MouseMsg, macroKeyMsg:
+		return true
+	}
+	return false
+}
+
+// focusPane moves focus to a pane, showing it first if it is hidden, so
+// only that pane's component is focused
+func (m *Model) focusPane(pane Pane) {
+	switch pane {
+	case FileTreePane:
+		m.showFileTree = true
+	case EditorPane:
+		m.showEditor = true
+	case OutlinePane:
+		if !m.showOutline {
+			m.showOutline = true
+			m.refreshOutline()
+		}
+	}
+	m.updateComponentSizes()
+
+	m.activePane = pane
+	if pane == ChatPane {
+		m.chat.Focus()
+	} else {
+		m.chat.Blur()
+	}
+	if pane == EditorPane {
+		m.editor.Focus()
+	} else {
+		m.editor.Blur()
+	}
+	m.fileTree.SetFocused(pane == FileTreePane)
+	m.outline.SetFocused(pane == OutlinePane)
+}
+
+// refocusIfHidden returns focus to the chat when the focused pane was hidden
+func (m *Model) refocusIfHidden() {
+	hidden := (m.activePane == FileTreePane && !m.showFileTree) ||
+		(m.activePane == EditorPane && !m.showEditor) ||
+		(m.activePane == OutlinePane && !m.showOutline)
+	if hidden {
+		m.focusPane(ChatPane)
+	}
+}
@@ -0,0 +1,197 @@
+package ui
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// errNoFormatter is returned when neither a local tool nor the server can
+// format a file
+var errNoFormatter = errors.New("no formatter available")
+
+// errPermissionPending is returned while the permission prompt waits for an
+// answer; the command is sent again once it is given
+var errPermissionPending = errors.New("waiting for permission")
+
+// localFormatters are the tools that format each file type; they read the
+// source on stdin and write it to stdout. {path} is replaced with the path
+var localFormatters = map[string][]string{
+	".go":   {"gofmt"},
+	".py":   {"black", "-q", "-"},
+	".js":   {"prettier", "--stdin-filepath", "{path}"},
+	".jsx":  {"prettier", "--stdin-filepath", "{path}"},
+	".ts":   {"prettier", "--stdin-filepath", "{path}"},
+	".tsx":  {"prettier", "--stdin-filepath", "{path}"},
+	".json": {"prettier", "--stdin-filepath", "{path}"},
+	".css":  {"prettier", "--stdin-filepath", "{path}"},
+	".md":   {"prettier", "--stdin-filepath", "{path}"},
+	".yaml": {"prettier", "--stdin-filepath", "{path}"},
+	".yml":  {"prettier", "--stdin-filepath", "{path}"},
+	".html": {"prettier", "--stdin-filepath", "{path}"},
+}
+
+// formatterCommand returns the installed local formatter for a file, if any
+func formatterCommand(path string) []string {
+	command, ok := localFormatters[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		return nil
+	}
+	if _, err := exec.LookPath(command[0]); err != nil {
+		return nil
+	}
+	args := make([]string, len(command))
+	for i, arg := range command {
+		args[i] = strings.ReplaceAll(arg, "{path}", path)
+	}
+	return args
+}
+
+// runFormatter formats source with a local tool, returning its error output
+// when it rejects the source
+func runFormatter(command []string, source string) (string, error) {
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Stdin = strings.NewReader(source)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("%s: %s", command[0], message)
+		}
+		return "", fmt.Errorf("%s: %w", command[0], err)
+	}
+	return stdout.String(), nil
+}
+
+// formatSource formats a file's source with a local formatter, falling back
+// to the server's when none is installed or running it isn't permitted.
+// retry is the command sent again once the permission prompt is answered
+func (m *Model) formatSource(path, source string, retry tea.Msg) (string, error) {
+	if command := formatterCommand(path); command != nil {
+		switch m.checkPermission(permissionRequest{
+			Action: permissionExec(command[0]),
+			Title:  "run " + command[0],
+			Detail: fmt.Sprintf("Format %s by running this local program:\n\n  %s", path, strings.Join(command, " ")),
+		}, retry) {
+		case permissionAllowed:
+			return runFormatter(command, source)
+		case permissionAsked:
+			return "", errPermissionPending
+		}
+	}
+	if client, ok := m.phoenixClient.(*phoenix.Client); ok && m.connected && m.capabilities[phoenix.CapabilityFormat] {
+		return client.FormatCode(context.Background(), path, source)
+	}
+	return "", errNoFormatter
+}
+
+// formatErrorLine finds the line number in a formatter error such as
+// "<standard input>:12:5: expected ';'"
+var formatErrorLine = regexp.MustCompile(`:(\d+):`)
+
+// formatBuffer formats the editor buffer in place. Failures are shown above
+// the editor with the cursor on the offending line
+func (m *Model) formatBuffer(retry tea.Msg) error {
+	source := m.editor.Value()
+	formatted, err := m.formatSource(m.currentFile, source, retry)
+	if err != nil {
+		if !errors.Is(err, errNoFormatter) && !errors.Is(err, errPermissionPending) {
+			m.formatError = err.Error()
+			if match := formatErrorLine.FindStringSubmatch(m.formatError); match != nil {
+				line, _ := strconv.Atoi(match[1])
+				m.moveEditorToLine(line)
+			}
+		}
+		return err
+	}
+	m.formatError = ""
+	if formatted != source {
+		line := m.editor.Line() + 1
+		m.editor.SetValue(formatted)
+		m.moveEditorToLine(line)
+		m.refreshOutline()
+	}
+	return nil
+}
+
+// formatFile formats the open file for /format
+func (m *Model) formatFile() {
+	if m.currentFile == "" {
+		m.statusMessages.AddMessage(StatusCategoryError, "No file open to format", nil)
+		return
+	}
+	if err := m.formatBuffer(ExecuteCommandMsg{Command: "format_file"}); err != nil {
+		if errors.Is(err, errPermissionPending) {
+			return
+		}
+		m.statusBar = fmt.Sprintf("Cannot format %s: %v", filepath.Base(m.currentFile), err)
+		return
+	}
+	m.statusBar = fmt.Sprintf("Formatted %s", m.currentFile)
+}
+
+// saveFile writes the editor buffer, formatting it first when
+// tui.format_on_save is set. A formatting failure doesn't stop the save.
+// Files on the server are saved in the background, returning the command
+func (m *Model) saveFile() tea.Cmd {
+	if m.currentFile == "" {
+		m.statusMessages.AddMessage(StatusCategoryError, "No file open to save", nil)
+		return nil
+	}
+	fs := m.fileTree.FileSystem()
+	if fs.Name() == FileSourceLocal && m.checkPermission(permissionRequest{
+		Action: permissionSaveFiles,
+		Title:  "save local files",
+		Detail: "Save the editor buffer to this local file:\n\n  " + m.currentFile,
+	}, ExecuteCommandMsg{Command: "save_file"}) != permissionAllowed {
+		return nil
+	}
+	formatted := ""
+	if m.config.TUI.FormatOnSave {
+		err := m.formatBuffer(ExecuteCommandMsg{Command: "save_file"})
+		if errors.Is(err, errPermissionPending) {
+			return nil
+		}
+		if err == nil {
+			formatted = " (formatted)"
+		}
+	}
+
+	content := m.editor.Value()
+	if fs.Name() == FileSourceServer {
+		return m.saveInBackground(fs, content, formatted)
+	}
+	if err := fs.WriteFile(m.currentFile, []byte(content)); err != nil {
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Cannot save %s: %v", m.currentFile, err), nil)
+		return nil
+	}
+	if m.hunksApplied {
+		m.recordAppliedChange(m.currentFile, m.editorOriginal, content, true)
+		m.hunksApplied = false
+	}
+	m.editorOriginal = content
+	m.currentFileStamp = statFile(m.projectFile(m.currentFile))
+	m.fileChangedOnDisk = false
+	m.statusBar = fmt.Sprintf("Saved %s%s", m.currentFile, formatted)
+	return nil
+}
+
+// renderFormatErrorBanner renders a formatter failure above the editor
+func (m Model) renderFormatErrorBanner(width int) string {
+	return lipgloss.NewStyle().
+		Background(lipgloss.Color("196")).
+		Foreground(lipgloss.Color("15")).
+		Width(width).
+		Render("Format failed: " + strings.SplitN(m.formatError, "\n", 2)[0])
+}
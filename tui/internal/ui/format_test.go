@@ -0,0 +1,41 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+func TestFormatOnSave(t *testing.T) {
+	if formatterCommand("main.go") == nil {
+		t.Skip("gofmt is not installed")
+	}
+	testutil.IsolateHome(t)
+	path := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(path, []byte("package main\nfunc main(){}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	model := NewModel()
+	model.config.TUI.FormatOnSave = true
+	model.sessionPermissions[permissionExec("gofmt")] = permissionAllow
+	model.sessionPermissions[permissionSaveFiles] = permissionAllow
+	*model, _ = model.openFile(FileSelectedMsg{Path: path})
+	model.saveFile()
+	data, _ := os.ReadFile(path)
+	if string(data) != "package main\n\nfunc main() {}\n" {
+		t.Fatalf("Expected the file to be formatted before saving, got %q", data)
+	}
+
+	model.editor.SetValue("package main\n\nfunc main() {\n")
+	model.saveFile()
+	if !strings.Contains(model.formatError, "gofmt") {
+		t.Fatalf("Expected the gofmt error to be shown, got %q", model.formatError)
+	}
+	if data, _ := os.ReadFile(path); !strings.HasSuffix(string(data), "{\n") {
+		t.Fatal("Expected a formatting failure to still save the buffer")
+	}
+}
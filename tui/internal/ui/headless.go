@@ -0,0 +1,329 @@
+package ui
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/clock"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// headlessReadyTimeout is how long headless mode waits to be signed in and
+// joined before giving up
+const headlessReadyTimeout = 30 * time.Second
+
+// headlessSize is the screen size the model lays itself out for
+var headlessSize = tea.WindowSizeMsg{Width: 120, Height: 40}
+
+// HeadlessEvent is one line of headless output. Type says which fields are
+// set:
+//
+//	input    Text is a line read from the input
+//	command  Command, Status, and Text are a command's result
+//	chunk    StreamID and Text are part of a streamed answer
+//	result   Text is an answer; Response is the server's payload, if any
+//	message  Role and Text are something else shown in the chat
+//	status   Category and Text are a status update
+//	error    Text, and Code when known, describe an error
+//	done     Errors counts the errors seen; the run is over
+type HeadlessEvent struct {
+	Type             string          `json:"type"`
+	Time             time.Time       `json:"time"`
+	Text             string          `json:"text,omitempty"`
+	Role             string          `json:"role,omitempty"`
+	Command          string          `json:"command,omitempty"`
+	Status           string          `json:"status,omitempty"`
+	Category         string          `json:"category,omitempty"`
+	Code             string          `json:"code,omitempty"`
+	StreamID         string          `json:"stream_id,omitempty"`
+	ConversationType string          `json:"conversation_type,omitempty"`
+	Response         json.RawMessage `json:"response,omitempty"`
+	Errors           int             `json:"errors,omitempty"`
+}
+
+// headlessOutput writes events as JSON lines or plain text
+type headlessOutput struct {
+	w      io.Writer
+	format string
+	errors int
+}
+
+// emit writes an event
+func (o *headlessOutput) emit(event HeadlessEvent) {
+	event.Time = clock.Now()
+	if event.Type == "error" {
+		o.errors++
+	}
+	if o.format == formatJSON {
+		data, _ := json.Marshal(event)
+		fmt.Fprintf(o.w, "%s\n", data)
+		return
+	}
+
+	switch event.Type {
+	case "chunk":
+		fmt.Fprint(o.w, event.Text)
+	case "result":
+		// A streamed answer was printed as it arrived
+		if event.StreamID == "" {
+			fmt.Fprintln(o.w, event.Text)
+		} else {
+			fmt.Fprintln(o.w)
+		}
+	case "command", "message":
+		if event.Text != "" {
+			fmt.Fprintln(o.w, event.Text)
+		}
+	case "error":
+		fmt.Fprintln(o.w, "error:", event.Text)
+	}
+}
+
+// headlessLineMsg is a line read from the input
+type headlessLineMsg struct {
+	line string
+}
+
+// headlessEOFMsg says the input has ended
+type headlessEOFMsg struct{}
+
+// headlessSubmittedMsg carries what an input line turned into, so the next
+// line waits until it has been handled
+type headlessSubmittedMsg struct {
+	msg tea.Msg
+}
+
+// headlessDeadlineMsg ends a run that never got ready
+type headlessDeadlineMsg struct{}
+
+// HeadlessModel drives a model from lines of input instead of the keyboard,
+// for scripts. Each line is a message or a slash command, run one at a time
+// once the previous one is answered; what happens is written as events
+// instead of being drawn
+type HeadlessModel struct {
+	model      Model
+	out        *headlessOutput
+	input      *bufio.Scanner
+	lines      []string
+	eof        bool
+	submitted  bool // A line is on its way to the model
+	ready      bool
+	chatSeen   int
+	statusSeen int
+}
+
+// NewHeadlessModel reads input lines from r and writes events to w, as
+// JSON lines when format is "json" and as plain text otherwise
+func NewHeadlessModel(model *Model, r io.Reader, w io.Writer, format string) HeadlessModel {
+	out := &headlessOutput{w: w, format: format}
+	model.commandResults = func(result commandResult) {
+		out.emit(HeadlessEvent{Type: "command", Command: result.Command, Status: result.Status, Text: result.Output})
+	}
+	return HeadlessModel{
+		model:      *model,
+		out:        out,
+		input:      bufio.NewScanner(r),
+		chatSeen:   model.chat.GetMessageCount(),
+		statusSeen: model.statusMessages.Added(),
+	}
+}
+
+// Failed reports whether any errors were seen
+func (h HeadlessModel) Failed() bool {
+	return h.out.errors > 0
+}
+
+// Init starts the model and reading input
+func (h HeadlessModel) Init() tea.Cmd {
+	return tea.Batch(
+		h.model.Init(),
+		func() tea.Msg { return headlessSize },
+		h.readLine(),
+		tea.Tick(headlessReadyTimeout, func(time.Time) tea.Msg { return headlessDeadlineMsg{} }),
+	)
+}
+
+// readLine reads the next input line
+func (h HeadlessModel) readLine() tea.Cmd {
+	input := h.input
+	return func() tea.Msg {
+		if input.Scan() {
+			return headlessLineMsg{line: input.Text()}
+		}
+		return headlessEOFMsg{}
+	}
+}
+
+// Update feeds the model and reports what it did
+func (h HeadlessModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmds []tea.Cmd
+	switch msg := msg.(type) {
+	case tea.KeyMsg, tea.MouseMsg:
+		// There is no keyboard; input comes from lines
+		return h, nil
+
+	case headlessLineMsg:
+		if line := strings.TrimSpace(msg.line); line != "" {
+			h.lines = append(h.lines, line)
+		}
+		cmds = append(cmds, h.readLine())
+
+	case headlessEOFMsg:
+		h.eof = true
+
+	case telemetryConsentMsg:
+		// Nobody can answer; the choice stays open for an interactive run
+		return h, nil
+
+	case IdleLockTickMsg:
+		// There is no screen to lock
+		return h, nil
+
+	case headlessDeadlineMsg:
+		if !h.ready {
+			h.out.emit(HeadlessEvent{Type: "error", Code: string(phoenix.ErrNotConnected), Text: fmt.Sprintf("not signed in and joined after %s", headlessReadyTimeout)})
+			return h.finish()
+		}
+		return h, nil
+
+	case headlessSubmittedMsg:
+		h.submitted = false
+		if batch, ok := msg.msg.(tea.BatchMsg); ok {
+			cmds = append(cmds, tea.Batch(batch...))
+		} else if msg.msg != nil {
+			cmds = append(cmds, h.forward(msg.msg))
+		}
+
+	default:
+		h.observe(msg)
+		cmds = append(cmds, h.forward(msg))
+	}
+
+	if !h.ready && h.model.readyForInput() {
+		h.ready = true
+	}
+	if h.ready && !h.submitted && h.model.idle() {
+		if len(h.lines) > 0 {
+			cmds = append(cmds, h.submit())
+		} else if h.eof {
+			return h.finish()
+		}
+	}
+	return h, tea.Batch(cmds...)
+}
+
+// forward passes a message to the model and reports what it added to the
+// chat and status pane
+func (h *HeadlessModel) forward(msg tea.Msg) tea.Cmd {
+	updated, cmd := h.model.Update(msg)
+	h.model = updated.(Model)
+
+	if count := h.model.chat.GetMessageCount(); count > h.chatSeen {
+		messages := h.model.chat.GetMessages()
+		h.reportMessages(messages[len(messages)-(count-h.chatSeen):])
+	}
+	h.chatSeen = h.model.chat.GetMessageCount()
+
+	added := h.model.statusMessages.Added()
+	for _, update := range h.model.statusMessages.Latest(added - h.statusSeen) {
+		if update.Category == StatusCategoryError {
+			h.out.emit(HeadlessEvent{Type: "error", Text: update.Text})
+		} else {
+			h.out.emit(HeadlessEvent{Type: "status", Category: string(update.Category), Text: update.Text})
+		}
+	}
+	h.statusSeen = added
+
+	// Nobody can answer a confirmation; refuse it so the run goes on
+	if h.model.modal.IsVisible() {
+		h.out.emit(HeadlessEvent{Type: "error", Text: fmt.Sprintf("%s: needs confirmation, which headless mode can't give", h.model.modal.title)})
+		h.model.modal.Hide()
+	}
+	return cmd
+}
+
+// reportMessages reports messages added to the chat
+func (h *HeadlessModel) reportMessages(messages []ChatMessage) {
+	for _, message := range messages {
+		switch message.Type {
+		case UserMessage, AssistantMessage:
+			// Input and answers are reported as they happen
+		case ErrorMessage:
+			h.out.emit(HeadlessEvent{Type: "error", Text: message.Content})
+		default:
+			h.out.emit(HeadlessEvent{Type: "message", Role: message.Author, Text: message.Content})
+		}
+	}
+}
+
+// observe reports streamed chunks and answers before the model handles them
+func (h *HeadlessModel) observe(msg tea.Msg) {
+	switch msg := msg.(type) {
+	case phoenix.StreamDataMsg:
+		if msg.ID == h.model.streamID {
+			h.out.emit(HeadlessEvent{Type: "chunk", StreamID: msg.ID, Text: msg.Data})
+		}
+	case phoenix.StreamEndMsg:
+		if msg.ID == h.model.streamID {
+			h.out.emit(HeadlessEvent{Type: "result", StreamID: msg.ID, Text: h.model.streamBuffer})
+		}
+	case phoenix.ConversationResponseMsg:
+		var response phoenix.ConversationMessage
+		if json.Unmarshal(msg.Response, &response) == nil {
+			h.out.emit(HeadlessEvent{
+				Type:             "result",
+				Text:             h.model.responseHandlers.FormatResponse(response),
+				ConversationType: response.ConversationType,
+				Response:         msg.Response,
+			})
+		}
+	case phoenix.StatusUpdateMsg:
+		h.out.emit(HeadlessEvent{Type: "status", Category: msg.Category, Text: msg.Text})
+	}
+}
+
+// submit hands the next line to the model as if typed and sent
+func (h *HeadlessModel) submit() tea.Cmd {
+	line := h.lines[0]
+	h.lines = h.lines[1:]
+	h.out.emit(HeadlessEvent{Type: "input", Text: line})
+
+	var cmd tea.Cmd
+	if strings.HasPrefix(line, "/") {
+		cmd = h.model.chat.handleSlashCommand(line)
+	} else {
+		cmd = func() tea.Msg { return ChatMessageSentMsg{Content: line} }
+	}
+	if cmd == nil {
+		h.out.emit(HeadlessEvent{Type: "error", Text: line + ": nothing to run; the command is unknown or lacks arguments (see /help)"})
+		return nil
+	}
+	h.submitted = true
+	return func() tea.Msg { return headlessSubmittedMsg{msg: cmd()} }
+}
+
+// finish reports the end of the run and quits
+func (h HeadlessModel) finish() (tea.Model, tea.Cmd) {
+	h.out.emit(HeadlessEvent{Type: "done", Errors: h.out.errors})
+	return h, tea.Quit
+}
+
+// View draws nothing; headless output is written as events
+func (h HeadlessModel) View() string {
+	return ""
+}
+
+// readyForInput reports whether messages can be sent
+func (m Model) readyForInput() bool {
+	return m.isLocalProvider() || (m.authenticated && (m.channel != nil || m.demo != nil))
+}
+
+// idle reports whether nothing the user asked for is still under way
+func (m Model) idle() bool {
+	return !m.isProcessing && m.streamID == "" && len(m.commandQueue.Queued) == 0
+}
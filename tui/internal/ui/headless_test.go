@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+// drain runs a command and what it returns, skipping any that wait, such as
+// ticks
+func drain(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	result := make(chan tea.Msg, 1)
+	go func() { result <- cmd() }()
+	select {
+	case msg := <-result:
+		if batch, ok := msg.(tea.BatchMsg); ok {
+			var msgs []tea.Msg
+			for _, cmd := range batch {
+				msgs = append(msgs, drain(cmd)...)
+			}
+			return msgs
+		}
+		return []tea.Msg{msg}
+	case <-time.After(50 * time.Millisecond):
+		return nil
+	}
+}
+
+func TestHeadlessRunsLinesAsEvents(t *testing.T) {
+	testutil.IsolateHome(t)
+	model := NewModel()
+	if err := model.EnableDemo(); err != nil {
+		t.Fatal(err)
+	}
+	model.startDemo()
+
+	var out bytes.Buffer
+	input := strings.NewReader("/queue\n/unknown-command\n")
+	var run tea.Model = NewHeadlessModel(model, input, &out, formatJSON)
+	pending := []tea.Msg{headlessSize, run.(HeadlessModel).readLine()()}
+	for len(pending) > 0 && !strings.Contains(out.String(), `"type":"done"`) {
+		var cmd tea.Cmd
+		run, cmd = run.Update(pending[0])
+		pending = append(pending[1:], drain(cmd)...)
+	}
+
+	var types []string
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var event HeadlessEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("Expected a JSON event per line, got %q", line)
+		}
+		if event.Type == "command" && (event.Command != "queue" || event.Status != "ok" || !strings.Contains(event.Text, "Nothing queued")) {
+			t.Errorf("Unexpected command result: %+v", event)
+		}
+		types = append(types, event.Type)
+	}
+	if got := strings.Join(types, " "); got != "input command input error done" {
+		t.Errorf("Unexpected events: %s", got)
+	}
+	if strings.Contains(run.(HeadlessModel).model.chat.GetAllMessagesPlainText(), "Nothing queued") {
+		t.Error("Command output should go to the events, not the chat")
+	}
+}
+
+func TestFormatJSONWrapsCommandOutput(t *testing.T) {
+	testutil.IsolateHome(t)
+	if command, format := splitFormatFlag("/audit 5 --format json"); command != "/audit 5" || format != formatJSON {
+		t.Errorf("Unexpected split: %q %q", command, format)
+	}
+
+	var model Model = *NewModel()
+	before := model.chat.GetMessageCount()
+	updated, _ := model.Update(ExecuteCommandMsg{Command: "queue", Format: formatJSON})
+	model = updated.(Model)
+	if model.chat.GetMessageCount() != before+1 {
+		t.Fatalf("Expected the output replaced by one message")
+	}
+	messages := model.chat.GetMessages()
+	output := messages[len(messages)-1].Content
+	if !strings.Contains(output, `"command": "queue"`) || !strings.Contains(output, `"status": "ok"`) {
+		t.Errorf("Expected the result as JSON, got %q", output)
+	}
+}
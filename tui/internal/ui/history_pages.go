@@ -0,0 +1,146 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rubber_duck/tui/internal/clock"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// historyPageSize is how many messages each history request asks for
+const historyPageSize = 100
+
+// LoadOlderHistoryMsg asks for the page of history before the oldest
+// message shown, sent when the history is scrolled past its top
+type LoadOlderHistoryMsg struct{}
+
+// historyMessages converts a server history page into chat messages
+func historyMessages(history []phoenix.HistoryMessage) []ChatMessage {
+	messages := make([]ChatMessage, 0, len(history))
+	for _, message := range history {
+		// Map role to message type
+		var msgType MessageType
+		switch message.Role {
+		case "user":
+			msgType = UserMessage
+		case "assistant":
+			msgType = AssistantMessage
+		default:
+			msgType = SystemMessage
+		}
+
+		// Keep the server's timestamps so date separators line up
+		at, err := time.Parse(time.RFC3339, message.Timestamp)
+		if err != nil {
+			at = clock.Now()
+		}
+		messages = append(messages, ChatMessage{
+			ID:        message.ID,
+			Type:      msgType,
+			Content:   message.Content,
+			Author:    message.Role,
+			Timestamp: at,
+		})
+	}
+	return messages
+}
+
+// SetOlderHistory records whether the server has older messages to page in
+func (c *Chat) SetOlderHistory(hasOlder bool) {
+	c.hasOlder = hasOlder
+	c.loadingOlder = false
+	c.viewport.SetContent(c.buildViewportContent())
+}
+
+// wantsOlderHistory reports whether a message scrolled up past the top of
+// the history while older pages are available
+func (c *Chat) wantsOlderHistory(msg tea.Msg) bool {
+	if (!c.hasOlder && len(c.spill.segments) == 0) || c.loadingOlder || !c.viewport.AtTop() {
+		return false
+	}
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "up", "pgup", "ctrl+u", "home":
+			return true
+		}
+	case tea.MouseMsg:
+		return msg.Button == tea.MouseButtonWheelUp
+	}
+	return false
+}
+
+// PrependMessages stitches an older page above the history, skipping
+// messages already shown and keeping the view on the same message. It
+// returns how many messages were added
+func (c *Chat) PrependMessages(older []ChatMessage) int {
+	var added []ChatMessage
+	for _, msg := range older {
+		if !c.hasMessage(msg) {
+			added = append(added, msg)
+		}
+	}
+	c.loadingOlder = false
+	c.prepend(added)
+	return len(added)
+}
+
+// prepend adds older messages above the history, keeping the view on the
+// message that was first
+func (c *Chat) prepend(added []ChatMessage) {
+	firstLine := 0
+	if len(c.messageLines) > 0 {
+		firstLine = c.messageLines[0]
+	}
+	offset := c.viewport.YOffset - firstLine
+
+	c.messages = append(added, c.messages...)
+	c.viewport.SetContent(c.buildViewportContent())
+	if len(added) < len(c.messageLines) {
+		c.viewport.SetYOffset(c.messageLines[len(added)] + offset)
+	}
+}
+
+// hasMessage reports whether a message from the server is already shown,
+// by ID when it has one
+func (c *Chat) hasMessage(msg ChatMessage) bool {
+	for _, existing := range c.messages {
+		if msg.ID != "" && existing.ID == msg.ID {
+			return true
+		}
+		if msg.ID == "" && existing.Type == msg.Type && existing.Content == msg.Content && existing.Timestamp.Equal(msg.Timestamp) {
+			return true
+		}
+	}
+	return false
+}
+
+// renderHistoryTop draws the line above the oldest message while older
+// messages exist on disk or the server
+func (c Chat) renderHistoryTop() string {
+	style := lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Italic(true)
+	if spilled := c.spill.count(); spilled > 0 {
+		return style.Render(fmt.Sprintf("↑ Scroll up for %d earlier messages", spilled))
+	}
+	if c.spill.dropped > 0 && !c.hasOlder {
+		return style.Render(fmt.Sprintf("%d older messages were dropped from this session's scrollback", c.spill.dropped))
+	}
+	if c.loadingOlder {
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Render("⟳ Loading older messages...")
+	}
+	return style.Render("↑ Scroll up for older messages")
+}
+
+// loadOlderHistory requests the page before the oldest message shown
+func (m *Model) loadOlderHistory() tea.Cmd {
+	client, ok := m.phoenixClient.(*phoenix.Client)
+	if !ok || !m.connected || m.historyCursor == "" {
+		m.chat.SetOlderHistory(m.historyCursor != "")
+		return nil
+	}
+	m.statusBar = "Loading older messages..."
+	return client.GetConversationHistoryBefore(historyPageSize, m.historyCursor)
+}
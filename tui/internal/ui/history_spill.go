@@ -0,0 +1,304 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Long conversations keep only their recent messages in memory; older ones
+// are written to compressed segments on disk and read back when scrolled to
+const (
+	maxMessagesInMemory = 500 // Spill once the chat holds more than this
+	spillSegmentSize    = 200 // Messages per on-disk segment
+	maxSpillSegments    = 100 // Segments kept; the oldest is dropped beyond this
+)
+
+// spillSeq numbers the spill directories of this process
+var spillSeq atomic.Int64
+
+// spillRoot is the directory this process spills history into
+func spillRoot() string {
+	return filepath.Join(os.TempDir(), fmt.Sprintf("rubber_duck-history-%d", os.Getpid()))
+}
+
+// RemoveHistorySpill deletes the history this process spilled to disk
+func RemoveHistorySpill() error {
+	return os.RemoveAll(spillRoot())
+}
+
+// segmentCodec compresses spilled segments; both halves are safe for
+// concurrent use
+var segmentCodec = sync.OnceValues(func() (*zstd.Encoder, *zstd.Decoder) {
+	encoder, _ := zstd.NewWriter(nil)
+	decoder, _ := zstd.NewReader(nil)
+	return encoder, decoder
+})
+
+// spillSegment is one compressed block of older messages. What the chat
+// needs to know about them without reading them back is kept in memory
+type spillSegment struct {
+	path         string
+	count        int
+	tokens       int           // Estimated tokens of the messages
+	userMessages int           // Messages the user sent
+	title        string        // Title from the first user message, if any
+	pinned       []ChatMessage // Kept in memory so the pinned panel still lists them
+}
+
+// historySpill is a ring of zstd-compressed segments holding the oldest
+// messages of a chat, oldest first
+type historySpill struct {
+	dir      string
+	segments []spillSegment
+	next       int // Number of the next segment file
+	dropped    int // Messages lost when the ring overflowed
+	unreported int // Dropped messages the user hasn't been told about yet
+}
+
+// count returns how many messages are on disk
+func (s *historySpill) count() int {
+	total := 0
+	for _, segment := range s.segments {
+		total += segment.count
+	}
+	return total
+}
+
+// tokens returns the estimated tokens of the messages on disk
+func (s *historySpill) tokens() int {
+	total := 0
+	for _, segment := range s.segments {
+		total += segment.tokens
+	}
+	return total
+}
+
+// userMessages returns how many messages on disk the user sent
+func (s *historySpill) userMessages() int {
+	total := 0
+	for _, segment := range s.segments {
+		total += segment.userMessages
+	}
+	return total
+}
+
+// push writes messages to a new segment, dropping the oldest segment when
+// the ring is full
+func (s *historySpill) push(messages []ChatMessage) error {
+	if s.dir == "" {
+		dir := filepath.Join(spillRoot(), fmt.Sprint(spillSeq.Add(1)))
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return err
+		}
+		s.dir = dir
+	}
+
+	path := filepath.Join(s.dir, fmt.Sprintf("segment-%06d.json.zst", s.next))
+	if err := writeSegment(path, messages); err != nil {
+		return err
+	}
+	s.next++
+
+	segment := spillSegment{
+		path:         path,
+		count:        len(messages),
+		tokens:       EstimateConversationTokens(messages),
+		userMessages: countUserMessages(messages),
+	}
+	if segment.userMessages > 0 {
+		segment.title = conversationTitle(messages)
+	}
+	for _, msg := range messages {
+		if msg.Pinned {
+			segment.pinned = append(segment.pinned, msg)
+		}
+	}
+	s.segments = append(s.segments, segment)
+
+	if len(s.segments) > maxSpillSegments {
+		oldest := s.segments[0]
+		os.Remove(oldest.path)
+		s.dropped += oldest.count
+		s.unreported += oldest.count
+		s.segments = s.segments[1:]
+	}
+	return nil
+}
+
+// pop reads back the newest segment and removes it from the ring
+func (s *historySpill) pop() ([]ChatMessage, error) {
+	if len(s.segments) == 0 {
+		return nil, nil
+	}
+	newest := s.segments[len(s.segments)-1]
+	messages, err := readSegment(newest.path)
+	if err != nil {
+		return nil, err
+	}
+	os.Remove(newest.path)
+	s.segments = s.segments[:len(s.segments)-1]
+	return messages, nil
+}
+
+// all reads every spilled message, oldest first, leaving the ring intact
+func (s *historySpill) all() []ChatMessage {
+	var messages []ChatMessage
+	for _, segment := range s.segments {
+		// An unreadable segment is skipped rather than losing the rest
+		if segmentMessages, err := readSegment(segment.path); err == nil {
+			messages = append(messages, segmentMessages...)
+		}
+	}
+	return messages
+}
+
+// pinned returns the pinned messages on disk, oldest first
+func (s *historySpill) pinned() []ChatMessage {
+	var pinned []ChatMessage
+	for _, segment := range s.segments {
+		pinned = append(pinned, segment.pinned...)
+	}
+	return pinned
+}
+
+// discard deletes the ring
+func (s *historySpill) discard() {
+	if s.dir != "" {
+		os.RemoveAll(s.dir)
+	}
+	*s = historySpill{}
+}
+
+// writeSegment compresses messages into a segment file, encrypted when
+// transcript encryption is on
+func writeSegment(path string, messages []ChatMessage) error {
+	plain, err := json.Marshal(messages)
+	if err != nil {
+		return err
+	}
+	encoder, _ := segmentCodec()
+	data, err := sealTranscript(encoder.EncodeAll(plain, nil))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// readSegment decompresses a segment file
+func readSegment(path string) ([]ChatMessage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = openTranscript(data); err != nil {
+		return nil, err
+	}
+	_, decoder := segmentCodec()
+	plain, err := decoder.DecodeAll(data, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []ChatMessage
+	if err := json.Unmarshal(plain, &messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// eachSegment calls fn with the spilled messages one segment at a time,
+// oldest first, then with the messages in memory, so the whole chat is
+// never held at once
+func (c *Chat) eachSegment(fn func([]ChatMessage) error) error {
+	for _, segment := range c.spill.segments {
+		messages, err := readSegment(segment.path)
+		// An unreadable segment is skipped rather than losing the rest
+		if err != nil {
+			continue
+		}
+		if err := fn(messages); err != nil {
+			return err
+		}
+	}
+	return fn(c.messages)
+}
+
+// TokenEstimate estimates the tokens of the whole conversation without
+// reading back spilled messages
+func (c *Chat) TokenEstimate() int {
+	return c.spill.tokens() + EstimateConversationTokens(c.messages)
+}
+
+// UserMessageCount counts the messages the user sent without reading back
+// spilled messages
+func (c *Chat) UserMessageCount() int {
+	return c.spill.userMessages() + countUserMessages(c.messages)
+}
+
+// savableCount counts the messages that may be written to disk. Spilled
+// messages are never private
+func (c *Chat) savableCount() int {
+	return c.spill.count() + len(savableMessages(c.messages))
+}
+
+// savableTitle is the title of the savable messages, without reading back
+// spilled messages
+func (c *Chat) savableTitle() string {
+	for _, segment := range c.spill.segments {
+		if segment.title != "" {
+			return segment.title
+		}
+	}
+	return conversationTitle(savableMessages(c.messages))
+}
+
+// compact moves the oldest messages to disk once the chat holds too many.
+// Private messages are never written; they just leave memory
+func (c *Chat) compact() {
+	if len(c.messages) <= maxMessagesInMemory || c.selecting {
+		return
+	}
+	// Keep everything in memory if the disk write fails
+	if savable := savableMessages(c.messages[:spillSegmentSize]); len(savable) > 0 {
+		if err := c.spill.push(savable); err != nil {
+			return
+		}
+	}
+	c.messages = append([]ChatMessage(nil), c.messages[spillSegmentSize:]...)
+}
+
+// takeDropped returns how many messages the spill ring dropped since it was
+// last asked
+func (c *Chat) takeDropped() int {
+	dropped := c.spill.unreported
+	c.spill.unreported = 0
+	return dropped
+}
+
+// reportDroppedHistory tells the user when the oldest spilled messages had
+// to be dropped
+func (m *Model) reportDroppedHistory() {
+	if m.chat == nil {
+		return
+	}
+	if dropped := m.chat.takeDropped(); dropped > 0 {
+		m.statusMessages.AddMessage(StatusCategoryInfo, fmt.Sprintf("Dropped the %d oldest messages from this session's scrollback; it keeps %d", dropped, maxSpillSegments*spillSegmentSize), nil)
+	}
+}
+
+// rehydrate reads the newest spilled segment back above the history,
+// reporting whether there was one
+func (c *Chat) rehydrate() bool {
+	messages, err := c.spill.pop()
+	if err != nil || len(messages) == 0 {
+		return false
+	}
+	c.prepend(messages)
+	return true
+}
@@ -0,0 +1,122 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// hunk is one run of changed lines between two versions of a file
+type hunk struct {
+	Start   int // First replaced line of the old version, 0-based
+	Removed []string
+	Added   []string
+}
+
+// hunkContextLines is how many unchanged lines surround a hunk in previews
+const hunkContextLines = 2
+
+// diffHunks splits the changes from old to new into hunks
+func diffHunks(old, new string) []hunk {
+	if old == new {
+		return nil
+	}
+	a := strings.Split(old, "\n")
+	b := strings.Split(new, "\n")
+
+	// Common prefix and suffix don't need the LCS table
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	a, b = a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+
+	// Very different large files are one replacement
+	if len(a)*len(b) > 1_000_000 {
+		return []hunk{{Start: prefix, Removed: a, Added: b}}
+	}
+
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var hunks []hunk
+	var current *hunk
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		if i < len(a) && j < len(b) && a[i] == b[j] {
+			current = nil
+			i++
+			j++
+			continue
+		}
+		if current == nil {
+			hunks = append(hunks, hunk{Start: prefix + i})
+			current = &hunks[len(hunks)-1]
+		}
+		if j < len(b) && (i == len(a) || lcs[i][j+1] >= lcs[i+1][j]) {
+			current.Added = append(current.Added, b[j])
+			j++
+		} else {
+			current.Removed = append(current.Removed, a[i])
+			i++
+		}
+	}
+	return hunks
+}
+
+// applyHunks rebuilds old with only the selected hunks applied
+func applyHunks(old string, hunks []hunk, selected []bool) string {
+	lines := strings.Split(old, "\n")
+	var out []string
+	next := 0
+	for i, h := range hunks {
+		out = append(out, lines[next:h.Start]...)
+		if selected[i] {
+			out = append(out, h.Added...)
+		} else {
+			out = append(out, h.Removed...)
+		}
+		next = h.Start + len(h.Removed)
+	}
+	out = append(out, lines[next:]...)
+	return strings.Join(out, "\n")
+}
+
+// renderHunk renders a hunk with a little unchanged context around it
+func renderHunk(old []string, h hunk) string {
+	contextStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	removedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	addedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+
+	lines := []string{contextStyle.Render(fmt.Sprintf("@@ line %d @@", h.Start+1))}
+	for _, line := range old[max(h.Start-hunkContextLines, 0):h.Start] {
+		lines = append(lines, contextStyle.Render("  "+line))
+	}
+	for _, line := range h.Removed {
+		lines = append(lines, removedStyle.Render("- "+line))
+	}
+	for _, line := range h.Added {
+		lines = append(lines, addedStyle.Render("+ "+line))
+	}
+	end := h.Start + len(h.Removed)
+	for _, line := range old[end:min(end+hunkContextLines, len(old))] {
+		lines = append(lines, contextStyle.Render("  "+line))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,472 @@
+package ui
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rubber_duck/tui/internal/clock"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// idleLockCheckInterval is how often inactivity is checked
+const idleLockCheckInterval = 15 * time.Second
+
+// minPINLength is the shortest PIN /lock pin accepts
+const minPINLength = 4
+
+// pinHashScheme and pinIterations are how PINs are hashed. A PIN is short,
+// so the hash is made slow to guess from a copied config
+const (
+	pinHashScheme = "pbkdf2-sha256"
+	pinIterations = 600_000
+)
+
+// Wrong unlock attempts beyond freeUnlockAttempts make the next one wait,
+// doubling up to maxUnlockBackoff
+const (
+	freeUnlockAttempts = 3
+	firstUnlockBackoff = 5 * time.Second
+	maxUnlockBackoff   = 5 * time.Minute
+)
+
+// IdleLockTickMsg checks whether the screen should lock for inactivity
+type IdleLockTickMsg time.Time
+
+// UnlockSubmitMsg carries what was typed on the lock screen
+type UnlockSubmitMsg struct {
+	Secret string
+}
+
+// UnlockResultMsg carries a PIN checked or hashed off the update loop, since
+// the slow hash would otherwise freeze the screen
+type UnlockResultMsg struct {
+	Matched bool   // The PIN unlocks the screen
+	PINHash string // The chosen PIN hashed, or the PIN hashed again from an older scheme
+	Err     error
+}
+
+// lockMode says what the lock screen asks for
+type lockMode int
+
+const (
+	lockUnlock     lockMode = iota // The screen is locked
+	lockSetPIN                     // A new PIN is being chosen
+	lockConfirmPIN                 // The new PIN is typed again
+)
+
+// LockScreen hides the conversation until the account password or the
+// local PIN is entered. The session stays signed in underneath
+type LockScreen struct {
+	mode      lockMode
+	input     textinput.Model
+	visible   bool
+	verifying bool   // The attempt is being checked, or the new PIN hashed
+	checking  string // Shown while verifying
+	chosen    string // The new PIN, until it is confirmed
+	failures  int       // Wrong unlock attempts since the last unlock
+	retryAt   time.Time // No attempt is checked before this
+	err       string
+	width     int
+	height    int
+}
+
+// NewLockScreen creates a hidden lock screen
+func NewLockScreen() LockScreen {
+	return LockScreen{width: 80, height: 24}
+}
+
+// show displays the screen in a mode with an empty, masked input
+func (ls *LockScreen) show(mode lockMode, prompt string) {
+	ls.mode = mode
+	ls.visible = true
+	ls.verifying = false
+	ls.err = ""
+	ls.input = textinput.New()
+	ls.input.Prompt = prompt
+	ls.input.CharLimit = 256
+	ls.input.EchoMode = textinput.EchoPassword
+	ls.input.EchoCharacter = '•'
+	ls.input.Focus()
+}
+
+// Hide hides the screen and forgets what was typed
+func (ls *LockScreen) Hide() {
+	ls.visible = false
+	ls.verifying = false
+	ls.chosen = ""
+	ls.input.SetValue("")
+}
+
+// IsVisible returns whether the screen is shown
+func (ls LockScreen) IsVisible() bool {
+	return ls.visible
+}
+
+// Locked reports whether the screen is locked, as opposed to choosing a PIN
+func (ls LockScreen) Locked() bool {
+	return ls.visible && ls.mode == lockUnlock
+}
+
+// SetSize updates the screen dimensions
+func (ls *LockScreen) SetSize(width, height int) {
+	ls.width = width
+	ls.height = height
+}
+
+// SetError shows a rejected attempt and clears the input
+func (ls *LockScreen) SetError(message string) {
+	ls.verifying = false
+	ls.err = message
+	ls.input.SetValue("")
+}
+
+// unlockBackoff returns how long to wait after a number of wrong attempts
+func unlockBackoff(failures int) time.Duration {
+	if failures < freeUnlockAttempts {
+		return 0
+	}
+	backoff := firstUnlockBackoff << min(failures-freeUnlockAttempts, 16)
+	return min(backoff, maxUnlockBackoff)
+}
+
+// fail shows a wrong attempt and starts the wait before the next one
+func (ls *LockScreen) fail(message string) {
+	ls.failures++
+	wait := unlockBackoff(ls.failures)
+	ls.retryAt = clock.Now().Add(wait)
+	if wait > 0 {
+		message += fmt.Sprintf(" - %d wrong attempts, wait %s", ls.failures, wait)
+	}
+	ls.SetError(message)
+}
+
+// wait returns how long until the next attempt is checked
+func (ls LockScreen) wait() time.Duration {
+	return max(ls.retryAt.Sub(clock.Now()), 0)
+}
+
+// Update handles typing on the lock screen
+func (ls LockScreen) Update(msg tea.Msg) (LockScreen, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || !ls.visible || ls.verifying {
+		return ls, nil
+	}
+	switch keyMsg.String() {
+	case "enter":
+		secret := ls.input.Value()
+		if secret == "" {
+			return ls, nil
+		}
+		ls.input.SetValue("")
+		return ls, func() tea.Msg { return UnlockSubmitMsg{Secret: secret} }
+	case "esc":
+		// A locked screen can't be dismissed, only a PIN being chosen
+		if ls.mode != lockUnlock {
+			ls.Hide()
+		}
+		return ls, nil
+	}
+	var cmd tea.Cmd
+	ls.input, cmd = ls.input.Update(msg)
+	return ls, cmd
+}
+
+// View renders the lock screen over a blank terminal
+func (ls LockScreen) View() string {
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("62"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+	title, help := "◆ Locked ◆", "Enter: Unlock"
+	if ls.mode != lockUnlock {
+		title, help = "◆ Set Lock PIN ◆", "Enter: Continue | Esc: Cancel"
+	}
+	lines := []string{titleStyle.Render(title), "", ls.input.View(), ""}
+	switch {
+	case ls.verifying:
+		lines = append(lines, dimStyle.Render(ls.checking))
+	case ls.err != "":
+		lines = append(lines, lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(ls.err))
+	default:
+		lines = append(lines, "")
+	}
+	lines = append(lines, dimStyle.Render(help))
+
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Padding(1, 2).
+		Width(56).
+		Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+	return lipgloss.Place(ls.width, ls.height, lipgloss.Center, lipgloss.Center, box)
+}
+
+// hashPIN hashes a PIN with a new random salt as
+// "pbkdf2-sha256:<iterations>:<salt>:<hash>"
+func hashPIN(pin string) (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key, err := pbkdf2.Key(sha256.New, pin, salt, pinIterations, sha256.Size)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s:%d:%s:%s", pinHashScheme, pinIterations, hex.EncodeToString(salt), hex.EncodeToString(key)), nil
+}
+
+// checkPIN reports whether pin matches a hash from hashPIN, or an older
+// "sha256:<salt>:<hash>" one
+func checkPIN(hash, pin string) bool {
+	parts := strings.Split(hash, ":")
+	var salt, want, got []byte
+	var err error
+	switch {
+	case len(parts) == 4 && parts[0] == pinHashScheme:
+		iterations, convErr := strconv.Atoi(parts[1])
+		if convErr != nil || iterations <= 0 {
+			return false
+		}
+		if salt, err = hex.DecodeString(parts[2]); err != nil {
+			return false
+		}
+		if want, err = hex.DecodeString(parts[3]); err != nil {
+			return false
+		}
+		if got, err = pbkdf2.Key(sha256.New, pin, salt, iterations, len(want)); err != nil {
+			return false
+		}
+	case len(parts) == 3 && parts[0] == "sha256":
+		if salt, err = hex.DecodeString(parts[1]); err != nil {
+			return false
+		}
+		if want, err = hex.DecodeString(parts[2]); err != nil {
+			return false
+		}
+		sum := sha256.Sum256(append(salt, pin...))
+		got = sum[:]
+	default:
+		return false
+	}
+	return subtle.ConstantTimeCompare(got, want) == 1
+}
+
+// outdatedPINHash reports whether a PIN hash predates pinHashScheme
+func outdatedPINHash(hash string) bool {
+	return !strings.HasPrefix(hash, pinHashScheme+":")
+}
+
+// idleLockTimeout returns the inactivity after which the screen locks, 0
+// when the idle lock is off
+func (m Model) idleLockTimeout() time.Duration {
+	if m.config.TUI.IdleLockMinutes <= 0 {
+		return 0
+	}
+	return time.Duration(m.config.TUI.IdleLockMinutes) * time.Minute
+}
+
+// watchIdle schedules the next inactivity check
+func (m Model) watchIdle() tea.Cmd {
+	if m.idleLockTimeout() == 0 {
+		return nil
+	}
+	return tea.Tick(idleLockCheckInterval, func(t time.Time) tea.Msg {
+		return IdleLockTickMsg(t)
+	})
+}
+
+// canLock returns how the screen would be unlocked, "" when it can't be:
+// the local PIN, or the password of an account signed in with one
+func (m Model) canLock() string {
+	if m.config.TUI.LockPINHash != "" {
+		return "PIN"
+	}
+	if _, ok := m.authClient.(*phoenix.AuthClient); ok && m.authenticated && m.username != "" && m.apiKey == "" {
+		return "password"
+	}
+	return ""
+}
+
+// checkIdle locks the screen after enough inactivity
+func (m *Model) checkIdle() tea.Cmd {
+	timeout := m.idleLockTimeout()
+	if timeout == 0 {
+		return nil
+	}
+	if !m.lockScreen.IsVisible() && clock.Now().Sub(m.lastInput) >= timeout {
+		if m.canLock() != "" {
+			m.lockNow()
+		} else if !m.idleLockWarned {
+			m.idleLockWarned = true
+			m.statusMessages.AddMessage(StatusCategoryError, "The idle lock needs a PIN when not signed in with a password - set one with /lock pin", nil)
+		}
+	}
+	return m.watchIdle()
+}
+
+// lockNow locks the screen, unless there is nothing to unlock it with
+func (m *Model) lockNow() {
+	how := m.canLock()
+	if how == "" {
+		m.statusMessages.AddMessage(StatusCategoryError, "Cannot lock: set a PIN with /lock pin, or sign in with a password", nil)
+		return
+	}
+	m.modal.Hide()
+	m.commandPalette.Hide()
+	m.lockScreen.SetSize(m.width, m.height)
+	prompt := "PIN: "
+	if how == "password" {
+		prompt = fmt.Sprintf("Password for %s: ", m.username)
+	}
+	m.lockScreen.show(lockUnlock, prompt)
+}
+
+// handleUnlockSubmit checks an unlock attempt, or takes the next step of
+// choosing a PIN
+func (m *Model) handleUnlockSubmit(msg UnlockSubmitMsg) tea.Cmd {
+	switch m.lockScreen.mode {
+	case lockSetPIN:
+		if len(msg.Secret) < minPINLength {
+			m.lockScreen.SetError(fmt.Sprintf("Use at least %d characters", minPINLength))
+			return nil
+		}
+		m.lockScreen.chosen = msg.Secret
+		m.lockScreen.mode = lockConfirmPIN
+		m.lockScreen.input.Prompt = "Confirm PIN: "
+		m.lockScreen.err = ""
+		return nil
+
+	case lockConfirmPIN:
+		if msg.Secret != m.lockScreen.chosen {
+			m.lockScreen.show(lockSetPIN, "New PIN: ")
+			m.lockScreen.err = "The PINs didn't match; choose it again"
+			return nil
+		}
+		m.lockScreen.startChecking("Saving the PIN...")
+		pin := msg.Secret
+		return func() tea.Msg {
+			hash, err := hashPIN(pin)
+			return UnlockResultMsg{PINHash: hash, Err: err}
+		}
+	}
+
+	if wait := m.lockScreen.wait(); wait > 0 {
+		m.lockScreen.SetError(fmt.Sprintf("Too many wrong attempts - try again in %s", wait.Round(time.Second)))
+		return nil
+	}
+	if hash := m.config.TUI.LockPINHash; hash != "" {
+		m.lockScreen.startChecking("Checking the PIN...")
+		pin := msg.Secret
+		return func() tea.Msg {
+			if !checkPIN(hash, pin) {
+				return UnlockResultMsg{}
+			}
+			// A PIN saved with the older, fast hash is hashed again now it's known
+			result := UnlockResultMsg{Matched: true}
+			if outdatedPINHash(hash) {
+				result.PINHash, result.Err = hashPIN(pin)
+			}
+			return result
+		}
+	}
+	authClient, ok := m.authClient.(*phoenix.AuthClient)
+	if !ok {
+		m.lockScreen.SetError("Not connected to the auth server; try again once reconnected")
+		return nil
+	}
+	// Logging in again checks the password; the session's token is kept
+	m.lockScreen.startChecking("Checking with the server...")
+	return authClient.Login(m.username, msg.Secret)
+}
+
+// startChecking ignores input while an attempt is checked
+func (ls *LockScreen) startChecking(status string) {
+	ls.verifying = true
+	ls.checking = status
+	ls.err = ""
+}
+
+// handleUnlockResult takes a PIN checked or hashed by handleUnlockSubmit
+func (m *Model) handleUnlockResult(msg UnlockResultMsg) {
+	if !m.lockScreen.IsVisible() || !m.lockScreen.verifying {
+		return
+	}
+	if m.lockScreen.mode == lockConfirmPIN {
+		m.lockScreen.Hide()
+		if msg.Err != nil {
+			m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Cannot set the PIN: %v", msg.Err), nil)
+			return
+		}
+		m.config.TUI.LockPINHash = msg.PINHash
+		m.saveLockConfig("Lock PIN set - /lock locks the screen now")
+		return
+	}
+	if !msg.Matched {
+		m.lockScreen.fail("Wrong PIN")
+		return
+	}
+	m.unlock()
+	if msg.PINHash != "" && msg.Err == nil {
+		m.config.TUI.LockPINHash = msg.PINHash
+		if err := SaveConfig(m.config); err != nil {
+			m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Failed to save config: %v", err), nil)
+		}
+	}
+}
+
+// handleLockLogin takes the answer to an unlock password check, reporting
+// whether it was one
+func (m *Model) handleLockLogin(msg tea.Msg) bool {
+	if !m.lockScreen.Locked() || !m.lockScreen.verifying {
+		return false
+	}
+	switch msg := msg.(type) {
+	case phoenix.LoginSuccessMsg:
+		m.unlock()
+	case phoenix.LoginErrorMsg:
+		m.lockScreen.fail(msg.Message)
+	default:
+		return false
+	}
+	return true
+}
+
+// unlock hides the lock screen and restarts the idle clock
+func (m *Model) unlock() {
+	m.lockScreen.Hide()
+	m.lockScreen.failures = 0
+	m.lockScreen.retryAt = time.Time{}
+	m.lastInput = clock.Now()
+	m.statusBar = "Unlocked"
+}
+
+// startPINSetup opens the lock screen to choose a PIN
+func (m *Model) startPINSetup() {
+	m.lockScreen.SetSize(m.width, m.height)
+	m.lockScreen.show(lockSetPIN, "New PIN: ")
+}
+
+// clearPIN removes the lock PIN
+func (m *Model) clearPIN() {
+	m.config.TUI.LockPINHash = ""
+	m.saveLockConfig("Lock PIN removed - the screen unlocks with your password")
+}
+
+// saveLockConfig saves the config after a lock setting changed
+func (m *Model) saveLockConfig(status string) {
+	if err := SaveConfig(m.config); err != nil {
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Failed to save config: %v", err), nil)
+		return
+	}
+	m.statusBar = status
+	m.chat.AddMessage(SystemMessage, status, "system")
+}
@@ -0,0 +1,170 @@
+package ui
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/clock"
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+// submitUnlock types secret on the lock screen and takes the result of
+// checking it, as the update loop would
+func submitUnlock(model *Model, secret string) {
+	if cmd := model.handleUnlockSubmit(UnlockSubmitMsg{Secret: secret}); cmd != nil {
+		if result, ok := cmd().(UnlockResultMsg); ok {
+			model.handleUnlockResult(result)
+		}
+	}
+}
+
+func TestIdleLock_LocksAndUnlocksWithPIN(t *testing.T) {
+	testutil.IsolateHome(t)
+	model := NewModel()
+	model.width, model.height = 80, 24
+	model.config.TUI.IdleLockMinutes = 5
+	hash, err := hashPIN("2468")
+	if err != nil {
+		t.Fatal(err)
+	}
+	model.config.TUI.LockPINHash = hash
+	model.chat.AddMessage(UserMessage, "confidential plans", "user")
+
+	model.checkIdle()
+	if model.lockScreen.IsVisible() {
+		t.Fatal("Expected no lock before the timeout")
+	}
+	model.lastInput = clock.Now().Add(-6 * time.Minute)
+	model.checkIdle()
+	if !model.lockScreen.Locked() {
+		t.Fatal("Expected the screen to lock after the timeout")
+	}
+	if strings.Contains(model.View(), "confidential") {
+		t.Fatal("Expected the locked screen to hide the conversation")
+	}
+
+	// Keys go to the lock screen, not the chat
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	*model = updated.(Model)
+	if model.chat.GetInputValue() != "" {
+		t.Fatal("Expected typing on the lock screen to stay out of the chat input")
+	}
+
+	submitUnlock(model, "1357")
+	if !model.lockScreen.Locked() || model.lockScreen.err != "Wrong PIN" {
+		t.Fatal("Expected a wrong PIN to keep the screen locked")
+	}
+	submitUnlock(model, "2468")
+	if model.lockScreen.IsVisible() {
+		t.Fatal("Expected the right PIN to unlock the screen")
+	}
+}
+
+func TestLockPIN_SetWithConfirmation(t *testing.T) {
+	testutil.IsolateHome(t)
+	model := NewModel()
+	model.startPINSetup()
+	submitUnlock(model, "9999")
+	submitUnlock(model, "9998")
+	if model.config.TUI.LockPINHash != "" || model.lockScreen.mode != lockSetPIN {
+		t.Fatal("Expected mismatched PINs to start over")
+	}
+	submitUnlock(model, "9999")
+	submitUnlock(model, "9999")
+	if !checkPIN(model.config.TUI.LockPINHash, "9999") || strings.Contains(model.config.TUI.LockPINHash, "9999") {
+		t.Fatalf("Expected the PIN stored hashed, got %q", model.config.TUI.LockPINHash)
+	}
+}
+
+func TestIdleLock_WrongPINsBackOff(t *testing.T) {
+	testutil.IsolateHome(t)
+	start := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	restore := clock.Freeze(start)
+	defer func() { restore() }()
+
+	model := NewModel()
+	hash, err := hashPIN("2468")
+	if err != nil {
+		t.Fatal(err)
+	}
+	model.config.TUI.LockPINHash = hash
+	model.lockNow()
+
+	for i := 0; i < freeUnlockAttempts; i++ {
+		submitUnlock(model, "0000")
+	}
+	if wait := model.lockScreen.wait(); wait != firstUnlockBackoff {
+		t.Fatalf("Expected a %s wait after %d wrong PINs, got %s", firstUnlockBackoff, freeUnlockAttempts, wait)
+	}
+	// Even the right PIN isn't checked during the wait
+	submitUnlock(model, "2468")
+	if !model.lockScreen.Locked() || !strings.Contains(model.lockScreen.err, "try again") {
+		t.Fatalf("Expected attempts refused while waiting, got %q", model.lockScreen.err)
+	}
+
+	restore()
+	restore = clock.Freeze(start.Add(firstUnlockBackoff))
+	submitUnlock(model, "0000")
+	if wait := model.lockScreen.wait(); wait != 2*firstUnlockBackoff {
+		t.Fatalf("Expected the wait to double, got %s", wait)
+	}
+
+	restore()
+	restore = clock.Freeze(start.Add(time.Hour))
+	submitUnlock(model, "2468")
+	if model.lockScreen.IsVisible() || model.lockScreen.failures != 0 {
+		t.Fatal("Expected the right PIN to unlock and reset the count once the wait is over")
+	}
+}
+
+func TestCheckPIN_UpgradesOlderHashes(t *testing.T) {
+	testutil.IsolateHome(t)
+	salt := []byte("0123456789abcdef")
+	sum := sha256.Sum256(append(salt, "2468"...))
+	model := NewModel()
+	model.config.TUI.LockPINHash = "sha256:" + hex.EncodeToString(salt) + ":" + hex.EncodeToString(sum[:])
+	model.lockNow()
+
+	submitUnlock(model, "2468")
+	if model.lockScreen.IsVisible() {
+		t.Fatal("Expected an older hash to still unlock")
+	}
+	if hash := model.config.TUI.LockPINHash; outdatedPINHash(hash) || !checkPIN(hash, "2468") {
+		t.Errorf("Expected the PIN hashed again with %s, got %q", pinHashScheme, hash)
+	}
+}
+
+func TestIdleLock_ChecksThePINOffTheUpdateLoop(t *testing.T) {
+	testutil.IsolateHome(t)
+	model := NewModel()
+	hash, err := hashPIN("2468")
+	if err != nil {
+		t.Fatal(err)
+	}
+	model.config.TUI.LockPINHash = hash
+	model.lockNow()
+
+	cmd := model.handleUnlockSubmit(UnlockSubmitMsg{Secret: "2468"})
+	if cmd == nil || !model.lockScreen.Locked() || !strings.Contains(model.lockScreen.View(), "Checking the PIN") {
+		t.Fatal("Expected the PIN checked in a command while the screen says so")
+	}
+	if next, _ := model.lockScreen.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("1")}); next.input.Value() != "" || !next.verifying {
+		t.Fatal("Expected input ignored while checking")
+	}
+
+	updated, _ := model.Update(cmd())
+	*model = updated.(Model)
+	if model.lockScreen.IsVisible() {
+		t.Fatal("Expected the checked PIN to unlock the screen")
+	}
+
+	// A result arriving after the screen was unlocked changes nothing
+	model.handleUnlockResult(UnlockResultMsg{Matched: false})
+	if model.lockScreen.IsVisible() || model.lockScreen.failures != 0 {
+		t.Fatal("Expected a stale result ignored")
+	}
+}
@@ -0,0 +1,50 @@
+package ui
+
+import (
+	"github.com/charmbracelet/lipgloss"
+)
+
+// SetIncognito starts or stops marking new messages private. Private
+// messages stay in memory only: they are left out of saved conversations
+// and crash sessions and never spilled to disk
+func (c *Chat) SetIncognito(on bool) {
+	c.incognito = on
+}
+
+// Incognito reports whether new messages are private
+func (c Chat) Incognito() bool {
+	return c.incognito
+}
+
+// savableMessages returns the messages that may be written to disk
+func savableMessages(messages []ChatMessage) []ChatMessage {
+	savable := make([]ChatMessage, 0, len(messages))
+	for _, msg := range messages {
+		if !msg.Private {
+			savable = append(savable, msg)
+		}
+	}
+	return savable
+}
+
+// setIncognito turns incognito mode on or off for /incognito
+func (m *Model) setIncognito(on bool) {
+	m.chat.SetIncognito(on)
+	if on {
+		m.statusBar = "Incognito on - new messages are not saved locally"
+		m.chat.AddMessage(SystemMessage, "Incognito on: messages from now on aren't written to saved conversations, crash sessions, or disk. They still go to the server. /incognito off ends it; the private messages stay out of the saved conversation", "system")
+	} else {
+		m.statusBar = "Incognito off - new messages are saved locally again"
+		m.chat.AddMessage(SystemMessage, m.statusBar, "system")
+	}
+}
+
+// renderIncognitoBanner renders the persistent incognito indicator
+func (m Model) renderIncognitoBanner() string {
+	return lipgloss.NewStyle().
+		Background(lipgloss.Color("93")).
+		Foreground(lipgloss.Color("231")).
+		Bold(true).
+		Padding(0, 1).
+		Render("INCOGNITO - not saved locally")
+}
@@ -0,0 +1,33 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+func TestIncognitoMessagesAreNotSaved(t *testing.T) {
+	testutil.IsolateHome(t)
+	model := NewModel()
+	model.chat.AddMessage(UserMessage, "public question", "user")
+	model.setIncognito(true)
+	model.chat.AddMessage(UserMessage, "secret question", "user")
+	model.setIncognito(false)
+	model.autosaveConversation()
+
+	conv, err := loadConversationByID(model.savedConversationID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, msg := range conv.Messages {
+		if msg.Content == "secret question" || msg.Private {
+			t.Fatal("Expected the incognito message to stay out of the saved conversation")
+		}
+	}
+	if conv.Messages[0].Content != "public question" {
+		t.Fatalf("Expected the other messages to be saved, got %+v", conv.Messages)
+	}
+	if model.chat.GetMessageCount() != 4 {
+		t.Fatalf("Expected the chat to keep every message, got %d", model.chat.GetMessageCount())
+	}
+}
@@ -0,0 +1,90 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// runIndexCommand sends an index request, joining the index channel first
+// and running the request once joined when needed
+func (m *Model) runIndexCommand(operation string, force bool) tea.Cmd {
+	if !m.connected {
+		m.statusMessages.AddMessage(StatusCategoryError, "Not connected - project indexing runs on the server", nil)
+		return nil
+	}
+	indexClient, ok := m.indexClient.(*phoenix.IndexClient)
+	if !ok {
+		return nil
+	}
+	m.pendingIndex = operation
+	m.pendingIndexForce = force
+	if !indexClient.Joined() {
+		return indexClient.JoinIndexChannel()
+	}
+	return m.sendPendingIndex()
+}
+
+// sendPendingIndex sends the index request waiting for the channel
+func (m *Model) sendPendingIndex() tea.Cmd {
+	indexClient, ok := m.indexClient.(*phoenix.IndexClient)
+	operation := m.pendingIndex
+	m.pendingIndex = ""
+	if !ok {
+		return nil
+	}
+	switch operation {
+	case "build":
+		m.statusBar = "Indexing the project..."
+		m.statusMessages.AddMessage(StatusCategoryProgress, "Project indexing started", nil)
+		return indexClient.BuildIndex(m.pendingIndexForce)
+	case "status":
+		return indexClient.GetIndexStatus()
+	}
+	return nil
+}
+
+// handleIndexProgress reports a build's progress in the status pane
+func (m *Model) handleIndexProgress(progress phoenix.IndexProgress) {
+	text := fmt.Sprintf("Indexing: %s", progress.Phase)
+	if progress.FilesTotal > 0 {
+		text = fmt.Sprintf("Indexing: %d/%d files (%s)", progress.FilesDone, progress.FilesTotal, progress.Phase)
+	}
+	m.statusBar = text
+	if progress.Current != "" {
+		text += " - " + progress.Current
+	}
+	m.statusMessages.AddMessage(StatusCategoryProgress, text, nil)
+}
+
+// handleIndexStatus shows the index's state in the chat, announcing
+// finished builds in the status pane
+func (m *Model) handleIndexStatus(status phoenix.IndexStatus) {
+	summary := formatIndexStatus(status)
+	switch status.State {
+	case phoenix.IndexReady:
+		m.statusMessages.AddMessage(StatusCategoryProgress, "Project index ready: "+summary, nil)
+	case phoenix.IndexFailed:
+		m.statusMessages.AddMessage(StatusCategoryError, "Project indexing failed: "+status.Error, nil)
+	}
+	m.statusBar = "Index: " + summary
+	m.chat.AddMessage(SystemMessage, "Project index: "+summary, "system")
+}
+
+// formatIndexStatus describes the index, e.g. "ready - 120 files, 480
+// chunks, indexed 5m ago"
+func formatIndexStatus(status phoenix.IndexStatus) string {
+	switch status.State {
+	case phoenix.IndexNone, "":
+		return "not built yet (run /index build)"
+	case phoenix.IndexFailed:
+		return "failed - " + status.Error
+	}
+	summary := fmt.Sprintf("%s - %d files, %d chunks", status.State, status.Files, status.Chunks)
+	if at, err := time.Parse(time.RFC3339, status.IndexedAt); err == nil {
+		summary += ", indexed " + chatDisplay{Timestamps: timestampsRelative}.formatMessageTime(at, time.Now())
+	}
+	return summary
+}
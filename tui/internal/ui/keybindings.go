@@ -0,0 +1,77 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+)
+
+// KeyBinding is a shortcut listed in the help
+type KeyBinding struct {
+	Keys        string
+	Description string
+}
+
+// KeyBindingSection groups shortcuts under a help heading
+type KeyBindingSection struct {
+	Title    string
+	Bindings []KeyBinding
+	Note     string // Shown after the bindings
+}
+
+// KeyBindings lists the shortcuts in the order help shows them
+var KeyBindings = []KeyBindingSection{
+	{Title: "KEYBOARD SHORTCUTS", Bindings: []KeyBinding{
+		{"Ctrl+P", "Command palette (all commands)"},
+		{"Ctrl+H", "This help"},
+		{"Ctrl+R", "Reconnect to server"},
+		{"Tab", "Switch panes"},
+		{"Ctrl+C/Ctrl+Q", "Quit"},
+	}},
+	{Title: "NAVIGATION", Bindings: []KeyBinding{
+		{"Ctrl+/", "Focus chat"},
+		{"Alt+1..4", "Focus chat, file tree, editor, outline"},
+		{"Ctrl+F", "Toggle file tree"},
+		{"Ctrl+E", "Toggle editor"},
+		{"Alt+O", "Toggle symbol outline"},
+	}},
+	{Title: "EDITOR", Bindings: []KeyBinding{
+		{"Ctrl+S", "Save file (formats first with format_on_save)"},
+		{"Alt+V", "Start or clear a line selection at the cursor"},
+		{"Alt+A", "Ask about the selection (explain, find bugs, optimize, add tests)"},
+	}},
+	{Title: "COPY/PASTE", Bindings: []KeyBinding{
+		{"Ctrl+A", "Copy all messages to clipboard"},
+		{"Ctrl+L", "Copy last assistant message"},
+		{"Ctrl+T", "Show mouse mode status"},
+	}, Note: "Text selection is enabled by default.\nFor mouse scrolling, start with: ./rubber_duck_tui --mouse"},
+	{Title: "CHAT", Bindings: []KeyBinding{
+		{"Enter", "Send message"},
+		{"Ctrl+Enter", "New line"},
+		{"Alt+E", "Compose long message"},
+		{"Alt+F", "Search project"},
+		{"↑/↓", "Scroll history"},
+		{"Alt+↑", "Select messages (p to pin, c to copy, o to expand, d to diff)"},
+		{"Alt+L", "Label the links in view to open or copy one"},
+		{"Alt+P", "Label the file paths in view to open one in the editor"},
+		{"Ctrl+. / Alt+.", "Insert an emoji or unicode symbol"},
+	}},
+}
+
+// helpRule underlines help headings
+const helpRule = "━━━━━━━━━━━━━━━━━━━━━"
+
+// formatKeyBindings renders the shortcut sections of the help
+func formatKeyBindings() string {
+	var b strings.Builder
+	for _, section := range KeyBindings {
+		fmt.Fprintf(&b, "%s:\n%s\n", section.Title, helpRule)
+		for _, binding := range section.Bindings {
+			fmt.Fprintf(&b, "%-9s - %s\n", binding.Keys, binding.Description)
+		}
+		if section.Note != "" {
+			fmt.Fprintf(&b, "\n%s\n", section.Note)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,195 @@
+package ui
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/clock"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// KnownServer is what was trusted about a server the first time it was
+// connected to
+type KnownServer struct {
+	Fingerprint string    `json:"fingerprint,omitempty"` // SHA-256 of its TLS certificate's public key
+	InstanceID  string    `json:"instance_id,omitempty"` // The instance fingerprint it sent when joining
+	FirstSeen   time.Time `json:"first_seen"`
+}
+
+// serverChange is a server that no longer matches what was trusted on
+// first connect. Connecting stays refused until /trust accept
+type serverChange struct {
+	Host     string
+	Kind     string // "certificate" or "instance"
+	Previous string
+	Current  string
+}
+
+// serverHost returns the host and port of a socket URL
+func serverHost(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
+// pinnedFingerprint returns the certificate pinned for a socket URL
+func (m Model) pinnedFingerprint(rawURL string) string {
+	return m.config.KnownServers[serverHost(rawURL)].Fingerprint
+}
+
+// handleServerFingerprint pins a server's key on first connect and raises
+// the alarm when a known server presents another one
+func (m *Model) handleServerFingerprint(msg phoenix.ServerFingerprintMsg) {
+	if msg.Changed() {
+		m.flagServerChange(serverChange{Host: msg.Host, Kind: "certificate", Previous: msg.Pinned, Current: msg.Fingerprint})
+		return
+	}
+	if msg.Repinned {
+		m.trustServer(msg.Host, func(known *KnownServer) { known.Fingerprint = msg.Fingerprint })
+		return
+	}
+	if m.config.KnownServers[msg.Host].Fingerprint != "" {
+		return
+	}
+	m.trustServer(msg.Host, func(known *KnownServer) { known.Fingerprint = msg.Fingerprint })
+	m.statusMessages.AddMessage(StatusCategoryInfo, fmt.Sprintf("Trusted %s on first connect, key %s", msg.Host, msg.Fingerprint), nil)
+}
+
+// checkInstance pins the instance fingerprint the server sent when joining,
+// which identifies servers reached over ws:// where there's no certificate.
+// By then the credentials have already been sent, so a change is caught
+// only after an impostor has seen them
+func (m *Model) checkInstance(instanceID string) {
+	host := serverHost(m.phoenixURL)
+	if instanceID == "" || host == "" {
+		return
+	}
+	known := m.config.KnownServers[host]
+	switch known.InstanceID {
+	case instanceID:
+	case "":
+		m.trustServer(host, func(known *KnownServer) { known.InstanceID = instanceID })
+		m.statusMessages.AddMessage(StatusCategoryInfo, fmt.Sprintf("Trusted %s on first connect, instance %s", host, instanceID), nil)
+	default:
+		m.flagServerChange(serverChange{Host: host, Kind: "instance", Previous: known.InstanceID, Current: instanceID})
+		// The handshake already succeeded, so drop the connection
+		if m.socket != nil {
+			m.socket.Disconnect()
+			m.socket = nil
+		}
+		m.connected = false
+		m.channel = nil
+		m.updateHeaderState()
+	}
+}
+
+// trustServer records what was learned about a server in known_servers
+func (m *Model) trustServer(host string, update func(*KnownServer)) {
+	if m.config.KnownServers == nil {
+		m.config.KnownServers = make(map[string]KnownServer)
+	}
+	known := m.config.KnownServers[host]
+	if known.FirstSeen.IsZero() {
+		known.FirstSeen = clock.Now()
+	}
+	update(&known)
+	m.config.KnownServers[host] = known
+	if err := SaveConfig(m.config); err != nil {
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Failed to save config: %v", err), nil)
+	}
+}
+
+// flagServerChange warns that a known server changed and refuses further
+// connections to it until the change is accepted
+func (m *Model) flagServerChange(change serverChange) {
+	m.serverChange = &change
+	m.statusBar = fmt.Sprintf("WARNING: %s's %s changed - not connecting", change.Host, change.Kind)
+	m.statusMessages.AddMessage(StatusCategoryError, m.statusBar, nil)
+	m.chat.AddMessage(ErrorMessage, fmt.Sprintf(
+		"WARNING: THE SERVER AT %s IS NOT THE ONE TRUSTED BEFORE\n\n"+
+			"Its %s changed:\n  trusted: %s\n  now:     %s\n\n"+
+			"Someone may be intercepting the connection, so the TUI won't connect to it. "+
+			"If the server was legitimately reinstalled or given a new key, confirm the new fingerprint with its admin, then run /trust accept.",
+		change.Host, change.Kind, change.Previous, change.Current), "system")
+}
+
+// acceptServerChange trusts the changed server for /trust accept and
+// connects again
+func (m *Model) acceptServerChange() tea.Cmd {
+	change := m.serverChange
+	if change == nil {
+		m.statusMessages.AddMessage(StatusCategoryInfo, "No server change to accept", nil)
+		return nil
+	}
+	m.serverChange = nil
+	m.trustServer(change.Host, func(known *KnownServer) {
+		if change.Kind == "certificate" {
+			known.Fingerprint = change.Current
+		} else {
+			known.InstanceID = change.Current
+		}
+	})
+	m.statusBar = fmt.Sprintf("Now trusting the new %s of %s - reconnecting", change.Kind, change.Host)
+	m.chat.AddMessage(SystemMessage, m.statusBar, "system")
+	m.totalConnectionAttempts = 0
+	m.connectionBlocked = false
+	return func() tea.Msg { return InitiateConnectionMsg{} }
+}
+
+// forgetServer removes a server from known_servers, so the next connect
+// trusts whatever it presents
+func (m *Model) forgetServer(host string) {
+	if _, ok := m.config.KnownServers[host]; !ok {
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("%q isn't a known server; /trust lists them", host), nil)
+		return
+	}
+	delete(m.config.KnownServers, host)
+	if m.serverChange != nil && m.serverChange.Host == host {
+		m.serverChange = nil
+	}
+	if err := SaveConfig(m.config); err != nil {
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Failed to save config: %v", err), nil)
+		return
+	}
+	m.statusBar = fmt.Sprintf("Forgot %s - it is trusted again on the next connect", host)
+}
+
+// formatKnownServers lists the trusted servers for /trust
+func (m Model) formatKnownServers() string {
+	var b strings.Builder
+	if change := m.serverChange; change != nil {
+		fmt.Fprintf(&b, "Refusing %s: its %s changed to %s. /trust accept trusts it\n\n", change.Host, change.Kind, change.Current)
+	}
+	if len(m.config.KnownServers) == 0 {
+		b.WriteString("No known servers yet. A server's key (wss://) or instance fingerprint is pinned the first time you connect. " +
+			"Instance fingerprints are checked only after joining, once credentials were already sent over ws://")
+		return b.String()
+	}
+	hosts := make([]string, 0, len(m.config.KnownServers))
+	for host := range m.config.KnownServers {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	b.WriteString("Known servers:\n")
+	for _, host := range hosts {
+		known := m.config.KnownServers[host]
+		fmt.Fprintf(&b, "\n%s (first seen %s)\n", host, known.FirstSeen.Format("2006-01-02"))
+		if known.Fingerprint != "" {
+			fmt.Fprintf(&b, "  certificate: %s\n", known.Fingerprint)
+		}
+		if known.InstanceID != "" {
+			fmt.Fprintf(&b, "  instance:    %s\n", known.InstanceID)
+		}
+		if known.Fingerprint == "" && known.InstanceID != "" {
+			b.WriteString("  (checked only after joining, once credentials were already sent over ws:// - use wss:// to check first)\n")
+		}
+	}
+	b.WriteString("\n/trust forget <host> trusts a server afresh on its next connect")
+	return b.String()
+}
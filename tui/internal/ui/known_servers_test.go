@@ -0,0 +1,55 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rubber_duck/tui/internal/phoenix"
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+func TestServerFingerprint_TrustOnFirstUse(t *testing.T) {
+	testutil.IsolateHome(t)
+	model := NewModel()
+	model.SetPhoenixConfig("wss://duck.example.com/socket", "wss://duck.example.com/auth_socket", "")
+
+	model.handleServerFingerprint(phoenix.ServerFingerprintMsg{Host: "duck.example.com", Fingerprint: "sha256:AA"})
+	if got := model.pinnedFingerprint("wss://duck.example.com/socket"); got != "sha256:AA" {
+		t.Fatalf("Expected the first certificate to be pinned, got %q", got)
+	}
+	if saved, err := LoadConfig(); err != nil || saved.KnownServers["duck.example.com"].Fingerprint != "sha256:AA" {
+		t.Fatal("Expected the pin to be saved to config")
+	}
+
+	model.handleServerFingerprint(phoenix.ServerFingerprintMsg{Host: "duck.example.com", Fingerprint: "sha256:BB", Pinned: "sha256:AA"})
+	if model.serverChange == nil {
+		t.Fatal("Expected a changed certificate to be flagged")
+	}
+	updated, _ := model.Update(InitiateConnectionMsg{})
+	*model = updated.(Model)
+	if model.totalConnectionAttempts != 0 {
+		t.Fatal("Expected connecting to be refused while the change is unaccepted")
+	}
+
+	if model.acceptServerChange() == nil || model.serverChange != nil {
+		t.Fatal("Expected /trust accept to clear the change and reconnect")
+	}
+	if got := model.pinnedFingerprint("wss://duck.example.com/socket"); got != "sha256:BB" {
+		t.Fatalf("Expected the new certificate to be pinned, got %q", got)
+	}
+}
+
+func TestServerFingerprint_OlderPinsMoveToTheKey(t *testing.T) {
+	testutil.IsolateHome(t)
+	model := NewModel()
+	model.trustServer("duck.example.com", func(known *KnownServer) { known.Fingerprint = "sha256:AA" })
+	model.trustServer("plain.example.com", func(known *KnownServer) { known.InstanceID = "inst-1" })
+
+	model.handleServerFingerprint(phoenix.ServerFingerprintMsg{Host: "duck.example.com", Fingerprint: "spki-sha256:CC", Pinned: "spki-sha256:CC", Repinned: true})
+	if got := model.pinnedFingerprint("wss://duck.example.com/socket"); got != "spki-sha256:CC" || model.serverChange != nil {
+		t.Fatalf("Expected the older pin replaced by the key, got %q", got)
+	}
+	if !strings.Contains(model.formatKnownServers(), "credentials were already sent over ws://") {
+		t.Error("Expected /trust to say instance checks come after the credentials are sent")
+	}
+}
@@ -0,0 +1,212 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ModelLatencyStats aggregates response statistics for one provider/model pair
+type ModelLatencyStats struct {
+	Provider  string
+	Model     string
+	Latencies []time.Duration
+	Failures  int
+	Tokens    int
+}
+
+// Requests returns the number of completed and failed requests
+func (s *ModelLatencyStats) Requests() int {
+	return len(s.Latencies) + s.Failures
+}
+
+// Average returns the mean latency of completed requests
+func (s *ModelLatencyStats) Average() time.Duration {
+	if len(s.Latencies) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, latency := range s.Latencies {
+		total += latency
+	}
+	return total / time.Duration(len(s.Latencies))
+}
+
+// Percentile returns the latency at percentile p (0-100) using nearest rank
+func (s *ModelLatencyStats) Percentile(p float64) time.Duration {
+	if len(s.Latencies) == 0 {
+		return 0
+	}
+	sorted := make([]time.Duration, len(s.Latencies))
+	copy(sorted, s.Latencies)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	rank := int(float64(len(sorted))*p/100+0.999999) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
+
+// FailureRate returns the fraction of requests that failed
+func (s *ModelLatencyStats) FailureRate() float64 {
+	if s.Requests() == 0 {
+		return 0
+	}
+	return float64(s.Failures) / float64(s.Requests())
+}
+
+// TokensPerSecond returns response token throughput across completed requests
+func (s *ModelLatencyStats) TokensPerSecond() float64 {
+	var total time.Duration
+	for _, latency := range s.Latencies {
+		total += latency
+	}
+	if total <= 0 {
+		return 0
+	}
+	return float64(s.Tokens) / total.Seconds()
+}
+
+// LatencyTracker measures time from send to completion per model
+type LatencyTracker struct {
+	stats        map[string]*ModelLatencyStats
+	pending      bool
+	pendingSince time.Time
+	provider     string
+	model        string
+}
+
+// NewLatencyTracker creates a new latency tracker
+func NewLatencyTracker() *LatencyTracker {
+	return &LatencyTracker{
+		stats: make(map[string]*ModelLatencyStats),
+	}
+}
+
+// Begin starts timing a request for the given provider and model
+func (t *LatencyTracker) Begin(provider, model string) {
+	t.pending = true
+	t.pendingSince = time.Now()
+	t.provider = provider
+	t.model = model
+}
+
+// Complete records a successful response and returns its latency
+func (t *LatencyTracker) Complete(responseTokens int) time.Duration {
+	if !t.pending {
+		return 0
+	}
+	latency := time.Since(t.pendingSince)
+	stats := t.statsFor(t.provider, t.model)
+	stats.Latencies = append(stats.Latencies, latency)
+	stats.Tokens += responseTokens
+	t.pending = false
+	return latency
+}
+
+// Fail records a failed request
+func (t *LatencyTracker) Fail() {
+	if !t.pending {
+		return
+	}
+	t.statsFor(t.provider, t.model).Failures++
+	t.pending = false
+}
+
+// Cancel discards the in-flight request without recording it
+func (t *LatencyTracker) Cancel() {
+	t.pending = false
+}
+
+// Stats returns the statistics sorted by average latency, fastest first
+func (t *LatencyTracker) Stats() []*ModelLatencyStats {
+	var result []*ModelLatencyStats
+	for _, stats := range t.stats {
+		result = append(result, stats)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Average() == result[j].Average() {
+			return result[i].Model < result[j].Model
+		}
+		// Models without completed requests sort last
+		if len(result[i].Latencies) == 0 || len(result[j].Latencies) == 0 {
+			return len(result[i].Latencies) > 0
+		}
+		return result[i].Average() < result[j].Average()
+	})
+	return result
+}
+
+// recentLatencies is how many of the latest responses Recent averages
+const recentLatencies = 5
+
+// Recent returns the average latency of a model's latest responses, or 0
+// when it hasn't answered yet; provider names match case-insensitively
+func (t *LatencyTracker) Recent(provider, model string) time.Duration {
+	for _, stats := range t.stats {
+		if stats.Model != model || !strings.EqualFold(stats.Provider, provider) || len(stats.Latencies) == 0 {
+			continue
+		}
+		latest := stats.Latencies[max(len(stats.Latencies)-recentLatencies, 0):]
+		var total time.Duration
+		for _, latency := range latest {
+			total += latency
+		}
+		return total / time.Duration(len(latest))
+	}
+	return 0
+}
+
+// statsFor returns the stats entry for a provider/model, creating it if needed
+func (t *LatencyTracker) statsFor(provider, model string) *ModelLatencyStats {
+	key := provider + "/" + model
+	stats, ok := t.stats[key]
+	if !ok {
+		stats = &ModelLatencyStats{Provider: provider, Model: model}
+		t.stats[key] = stats
+	}
+	return stats
+}
+
+// FormatStats renders the statistics table for /stats
+func (t *LatencyTracker) FormatStats() string {
+	stats := t.Stats()
+	if len(stats) == 0 {
+		return "No response statistics yet - send a message to start measuring."
+	}
+
+	var b strings.Builder
+	b.WriteString("Response statistics (this session):\n\n")
+	b.WriteString(fmt.Sprintf("%-28s %4s %8s %8s %6s %8s\n", "Provider/Model", "Reqs", "Avg", "p95", "Fail", "Tok/s"))
+	for _, s := range stats {
+		name := s.Provider + "/" + s.Model
+		if len(name) > 28 {
+			name = name[:27] + "…"
+		}
+		b.WriteString(fmt.Sprintf("%-28s %4d %8s %8s %5.0f%% %8.1f\n",
+			name,
+			s.Requests(),
+			formatLatency(s.Average()),
+			formatLatency(s.Percentile(95)),
+			s.FailureRate()*100,
+			s.TokensPerSecond(),
+		))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// formatLatency formats a latency for display
+func formatLatency(d time.Duration) string {
+	if d == 0 {
+		return "-"
+	}
+	if d < time.Second {
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	}
+	return fmt.Sprintf("%.1fs", d.Seconds())
+}
@@ -0,0 +1,48 @@
+package ui
+
+import (
+	"testing"
+	"time"
+)
+
+func TestModelLatencyStats_Percentile(t *testing.T) {
+	stats := &ModelLatencyStats{}
+	for i := 1; i <= 20; i++ {
+		stats.Latencies = append(stats.Latencies, time.Duration(i)*time.Second)
+	}
+
+	if got := stats.Percentile(95); got != 19*time.Second {
+		t.Errorf("Expected p95 of 19s, got %s", got)
+	}
+	if got := stats.Average(); got != 10500*time.Millisecond {
+		t.Errorf("Expected average of 10.5s, got %s", got)
+	}
+}
+
+func TestLatencyTracker_FailureRate(t *testing.T) {
+	tracker := NewLatencyTracker()
+
+	tracker.Begin("openai", "gpt-4")
+	tracker.Complete(100)
+	tracker.Begin("openai", "gpt-4")
+	tracker.Fail()
+
+	// Completing without a pending request is ignored
+	if latency := tracker.Complete(50); latency != 0 {
+		t.Errorf("Expected no latency without a pending request, got %s", latency)
+	}
+
+	stats := tracker.Stats()
+	if len(stats) != 1 {
+		t.Fatalf("Expected 1 model, got %d", len(stats))
+	}
+	if stats[0].Requests() != 2 {
+		t.Errorf("Expected 2 requests, got %d", stats[0].Requests())
+	}
+	if stats[0].FailureRate() != 0.5 {
+		t.Errorf("Expected failure rate 0.5, got %f", stats[0].FailureRate())
+	}
+	if stats[0].Tokens != 100 {
+		t.Errorf("Expected 100 tokens, got %d", stats[0].Tokens)
+	}
+}
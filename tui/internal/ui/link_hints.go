@@ -0,0 +1,299 @@
+package ui
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Actions a link hint can take, set in tui.links
+const (
+	LinkActionOpen = "open" // Open in the browser
+	LinkActionCopy = "copy" // Copy to the clipboard
+)
+
+// hintAlphabet orders hint labels with the home row first, as tmux does
+const hintAlphabet = "asdfghjklqwertyuiopzxcvbnm"
+
+// urlPattern finds URLs in chat messages
+var urlPattern = regexp.MustCompile(`https?://[^\s<>"'\x60]+`)
+
+// LinkConfig is tui.links: what link hints do
+type LinkConfig struct {
+	Action      string `json:"action,omitempty"`       // What a hint typed in lowercase does: "open" (default) or "copy"
+	ShiftAction string `json:"shift_action,omitempty"` // What a hint typed in uppercase does; unset is the other action
+	Browser     string `json:"browser,omitempty"`      // Command URLs are opened with; unset uses the system's default browser
+}
+
+// HintSelectedMsg reports the hint typed in hint mode. Shift is set when
+// it was typed in uppercase
+type HintSelectedMsg struct {
+	Kind  string // "link" or "path"
+	Text  string
+	Shift bool
+}
+
+// hintTarget is something on screen a hint selects
+type hintTarget struct {
+	Label string
+	Text  string
+	Row   int // Line of the visible chat
+	Col   int // Byte offset in the line without styling
+}
+
+// hintMode overlays letter labels on targets in the visible chat
+type hintMode struct {
+	kind    string
+	lines   []string // Visible lines without styling
+	targets []hintTarget
+	typed   string
+	shift   bool
+}
+
+// hintKind is a kind of thing hint mode labels
+type hintKind struct {
+	find          func(line string) [][]int // Byte ranges of the targets in a line
+	assistantOnly bool                      // Only look in assistant messages
+}
+
+// hintKinds are the kinds of hints, by name
+var hintKinds = map[string]hintKind{
+	"link": {find: findURLs},
+	"path": {find: findPaths, assistantOnly: true},
+}
+
+// findURLs finds URLs in a line, leaving off trailing punctuation
+func findURLs(line string) [][]int {
+	matches := urlPattern.FindAllStringIndex(line, -1)
+	for _, match := range matches {
+		for match[1] > match[0] && strings.ContainsRune(".,;:!?)]}*", rune(line[match[1]-1])) {
+			match[1]--
+		}
+	}
+	return matches
+}
+
+// hintLabels returns n labels, all of the same length so none is the
+// start of another
+func hintLabels(n int) []string {
+	labels := strings.Split(hintAlphabet, "")
+	for len(labels) < n {
+		var next []string
+		for _, prefix := range labels {
+			for _, r := range hintAlphabet {
+				next = append(next, prefix+string(r))
+			}
+		}
+		labels = next
+	}
+	return labels[:n]
+}
+
+// StartHints labels the targets of a kind of hint in the visible chat,
+// reporting how many there are; with none, hint mode doesn't start. keep,
+// when set, leaves out targets it rejects
+func (c *Chat) StartHints(kind string, keep func(text string) bool) int {
+	hintKind := hintKinds[kind]
+	lines := strings.Split(c.viewport.View(), "\n")
+	hints := &hintMode{kind: kind, lines: make([]string, len(lines))}
+	for row, line := range lines {
+		plain := ansi.Strip(line)
+		hints.lines[row] = plain
+		if hintKind.assistantOnly && c.messageTypeAt(c.viewport.YOffset+row) != AssistantMessage {
+			continue
+		}
+		for _, match := range hintKind.find(plain) {
+			text := plain[match[0]:match[1]]
+			if match[1] > match[0] && (keep == nil || keep(text)) {
+				hints.targets = append(hints.targets, hintTarget{Text: text, Row: row, Col: match[0]})
+			}
+		}
+	}
+	if len(hints.targets) == 0 {
+		c.hints = nil
+		return 0
+	}
+	for i, label := range hintLabels(len(hints.targets)) {
+		hints.targets[i].Label = label
+	}
+	c.hints = hints
+	return len(hints.targets)
+}
+
+// messageTypeAt returns the type of the message drawn on a line of the
+// history, or -1 above the first message
+func (c *Chat) messageTypeAt(line int) MessageType {
+	index := sort.Search(len(c.messageLines), func(i int) bool { return c.messageLines[i] > line }) - 1
+	if index < 0 || index >= len(c.messages) {
+		return -1
+	}
+	return c.messages[index].Type
+}
+
+// HintsActive reports whether hint mode is waiting for a label
+func (c *Chat) HintsActive() bool {
+	return c.hints != nil
+}
+
+// updateHints handles a key in hint mode: letters narrow the labels, and a
+// complete one selects its target
+func (c *Chat) updateHints(msg tea.KeyMsg) tea.Cmd {
+	hints := c.hints
+	if msg.Type != tea.KeyRunes || len(msg.Runes) != 1 || !unicode.IsLetter(msg.Runes[0]) {
+		// Esc and anything else leaves hint mode
+		c.hints = nil
+		return nil
+	}
+	r := msg.Runes[0]
+	if unicode.IsUpper(r) {
+		hints.shift = true
+	}
+	hints.typed += string(unicode.ToLower(r))
+
+	matched := false
+	for _, target := range hints.targets {
+		if target.Label == hints.typed {
+			c.hints = nil
+			selected := HintSelectedMsg{Kind: hints.kind, Text: target.Text, Shift: hints.shift}
+			return func() tea.Msg { return selected }
+		}
+		matched = matched || strings.HasPrefix(target.Label, hints.typed)
+	}
+	if !matched {
+		c.hints = nil
+	}
+	return nil
+}
+
+// renderHints draws the visible chat dimmed, with each target's label over
+// its start
+func (c Chat) renderHints() string {
+	hints := c.hints
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	labelStyle := lipgloss.NewStyle().Background(lipgloss.Color("220")).Foreground(lipgloss.Color("16")).Bold(true)
+	targetStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("81")).Underline(true)
+
+	byRow := make(map[int][]hintTarget)
+	for _, target := range hints.targets {
+		if strings.HasPrefix(target.Label, hints.typed) {
+			byRow[target.Row] = append(byRow[target.Row], target)
+		}
+	}
+	rendered := make([]string, len(hints.lines))
+	for row, line := range hints.lines {
+		targets := byRow[row]
+		sort.Slice(targets, func(i, j int) bool { return targets[i].Col < targets[j].Col })
+		var b strings.Builder
+		at := 0
+		for _, target := range targets {
+			b.WriteString(dim.Render(line[at:target.Col]))
+			// The rest of the label covers the start of the target, so the
+			// line keeps its width
+			label := target.Label[len(hints.typed):]
+			rest := []rune(target.Text)
+			if len(label) < len(rest) {
+				rest = rest[len(label):]
+			} else {
+				rest = nil
+			}
+			b.WriteString(labelStyle.Render(strings.ToUpper(label)))
+			b.WriteString(targetStyle.Render(string(rest)))
+			at = target.Col + len(target.Text)
+		}
+		b.WriteString(dim.Render(line[at:]))
+		rendered[row] = b.String()
+	}
+	return strings.Join(rendered, "\n")
+}
+
+// viewportView renders the visible history, with hint labels in hint mode
+func (c Chat) viewportView() string {
+	if c.hints != nil {
+		return c.renderHints()
+	}
+	return c.viewport.View()
+}
+
+// hintsStatus describes hint mode in the status bar
+func hintsStatus(kind string, count int) string {
+	return fmt.Sprintf("%d %ss - type a label (uppercase for the other action), Esc to cancel", count, kind)
+}
+
+// linkAction returns what a link hint does, lowercase or shifted
+func (m Model) linkAction(shift bool) string {
+	links := m.config.TUI.Links
+	action := links.Action
+	if action != LinkActionCopy {
+		action = LinkActionOpen
+	}
+	if !shift {
+		return action
+	}
+	if links.ShiftAction == LinkActionOpen || links.ShiftAction == LinkActionCopy {
+		return links.ShiftAction
+	}
+	if action == LinkActionOpen {
+		return LinkActionCopy
+	}
+	return LinkActionOpen
+}
+
+// handleLinkHint opens or copies the link picked in hint mode
+func (m *Model) handleLinkHint(url string, shift bool) {
+	if m.linkAction(shift) == LinkActionCopy {
+		if err := clipboard.WriteAll(url); err != nil {
+			m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Cannot copy the link: %v", err), nil)
+			return
+		}
+		m.statusBar = "Copied " + url
+		return
+	}
+	browser := m.config.TUI.Links.Browser
+	if browser == "" {
+		browser = "the default browser"
+	}
+	if m.checkPermission(permissionRequest{
+		Action: permissionOpenLinks,
+		Title:  "open a link",
+		Detail: fmt.Sprintf("Open this link with %s:\n\n  %s", browser, url),
+	}, HintSelectedMsg{Kind: "link", Text: url, Shift: shift}) != permissionAllowed {
+		return
+	}
+	if err := openURL(m.config.TUI.Links.Browser, url); err != nil {
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Cannot open the link: %v", err), nil)
+		return
+	}
+	m.statusBar = "Opened " + url
+}
+
+// openURL opens a URL with the browser command, or the system's default
+// browser when it is empty
+func openURL(browser, url string) error {
+	var cmd *exec.Cmd
+	switch {
+	case browser != "":
+		fields := strings.Fields(browser)
+		cmd = exec.Command(fields[0], append(fields[1:], url)...)
+	case runtime.GOOS == "darwin":
+		cmd = exec.Command("open", url)
+	case runtime.GOOS == "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	// Reap the browser launcher without waiting for it
+	go cmd.Wait()
+	return nil
+}
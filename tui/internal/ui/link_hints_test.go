@@ -0,0 +1,70 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+func TestLinkHintsSelectVisibleLinks(t *testing.T) {
+	chat := NewChat()
+	chat.SetSize(100, 30)
+	chat.AddMessage(AssistantMessage, "See https://go.dev/doc/effective_go. Or https://pkg.go.dev/strings!", "assistant")
+
+	if count := chat.StartHints("link", nil); count != 2 {
+		t.Fatalf("Expected 2 links, got %d", count)
+	}
+	if view := chat.viewportView(); !strings.Contains(view, "S") || !strings.Contains(view, "ttps://pkg.go.dev/strings") {
+		t.Errorf("Expected the labels over the links, got %q", view)
+	}
+
+	// The second label is "s"; typed in uppercase it asks for the other action
+	cmd := chat.updateHints(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("S")})
+	if cmd == nil {
+		t.Fatal("Expected a complete label to select its link")
+	}
+	selected := cmd().(HintSelectedMsg)
+	if selected.Text != "https://pkg.go.dev/strings" || !selected.Shift || chat.HintsActive() {
+		t.Errorf("Unexpected selection %+v", selected)
+	}
+
+	chat.StartHints("link", nil)
+	if chat.updateHints(tea.KeyMsg{Type: tea.KeyEsc}); chat.HintsActive() {
+		t.Error("Expected Esc to leave hint mode")
+	}
+}
+
+func TestLinkAction(t *testing.T) {
+	model := Model{config: &Config{}}
+	if model.linkAction(false) != LinkActionOpen || model.linkAction(true) != LinkActionCopy {
+		t.Error("Expected links to open, and copy when shifted, by default")
+	}
+	model.config.TUI.Links = LinkConfig{Action: LinkActionCopy}
+	if model.linkAction(false) != LinkActionCopy || model.linkAction(true) != LinkActionOpen {
+		t.Error("Expected the shifted action to be the other one")
+	}
+	model.config.TUI.Links.ShiftAction = LinkActionCopy
+	if model.linkAction(true) != LinkActionCopy {
+		t.Error("Expected shift_action to be used")
+	}
+}
+
+func TestOpeningLinkAsksPermission(t *testing.T) {
+	testutil.IsolateHome(t)
+	model := NewModel()
+	model.config.TUI.Links.Browser = "false"
+
+	model.handleLinkHint("https://example.com", false)
+	if !model.permissionPrompt.IsVisible() || model.statusBar == "Opened https://example.com" {
+		t.Fatal("Expected opening a link to ask first")
+	}
+
+	model.permissionPrompt.Hide()
+	model.config.Permissions = map[string]string{permissionOpenLinks: permissionDeny}
+	model.handleLinkHint("https://example.com", false)
+	if model.permissionPrompt.IsVisible() || model.statusBar == "Opened https://example.com" {
+		t.Error("Expected a remembered denial to keep the link closed")
+	}
+}
@@ -0,0 +1,316 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// How long a permission decision is remembered
+const (
+	PermissionOnce    = "once"    // This time only
+	PermissionSession = "session" // Until the TUI exits
+	PermissionAlways  = "always"  // Saved in the permissions section of config
+)
+
+// Remembered permission decisions
+const (
+	permissionAllow = "allow"
+	permissionDeny  = "deny"
+)
+
+// permissionWriteFiles is the action of writing changes the server suggested
+// to local files
+const permissionWriteFiles = "write_files"
+
+// Actions that write a local file or start a local program on the user's
+// behalf. Permissions are only read from the global config, since the
+// project config can't set them
+const (
+	permissionSaveFiles    = "save_files"         // Saving the editor buffer to a local file
+	permissionDoctorReport = "save_doctor_report" // /doctor save
+	permissionExportPatch  = "export_patch"       // /patch
+	permissionExportTrace  = "export_trace"       // /react export
+	permissionOpenLinks    = "open_links"         // Opening a chat link in the browser
+)
+
+// permissionExec returns the action of running a local program
+func permissionExec(program string) string {
+	return "exec:" + program
+}
+
+// permissionState is the outcome of checking a permission
+type permissionState int
+
+const (
+	permissionAllowed permissionState = iota
+	permissionDenied
+	permissionAsked // The prompt is shown; the action is sent again once answered
+)
+
+// permissionRequest describes an action that touches the local filesystem
+// or shell
+type permissionRequest struct {
+	Action string // Key the decision is remembered under
+	Title  string
+	Detail string
+}
+
+// PermissionDecisionMsg answers a permission prompt
+type PermissionDecisionMsg struct {
+	Action string
+	Allow  bool
+	Scope  string
+}
+
+// permissionChoice is one answer the prompt offers
+type permissionChoice struct {
+	label string
+	allow bool
+	scope string
+}
+
+// permissionChoices are the prompt's answers, in order
+var permissionChoices = []permissionChoice{
+	{"Allow once", true, PermissionOnce},
+	{"Allow for this session", true, PermissionSession},
+	{"Always allow", true, PermissionAlways},
+	{"Deny", false, PermissionOnce},
+	{"Deny for this session", false, PermissionSession},
+	{"Always deny", false, PermissionAlways},
+}
+
+// pendingPermission is a request waiting for the prompt and the message
+// sent again once it is answered
+type pendingPermission struct {
+	request permissionRequest
+	retry   tea.Msg
+}
+
+// PermissionPrompt asks before a command touches the local filesystem or
+// shell, offering to remember the answer
+type PermissionPrompt struct {
+	request permissionRequest
+	retry   tea.Msg // Sent again once answered, to run or refuse the action
+	queued  []pendingPermission // Requests made while the prompt was open, asked in turn
+	cursor  int
+	visible bool
+	width   int
+	height  int
+}
+
+// Show asks about a request; retry is sent again once it is answered. A
+// request made while another is being asked waits its turn
+func (pp *PermissionPrompt) Show(request permissionRequest, retry tea.Msg) {
+	if pp.visible {
+		pp.queued = append(pp.queued, pendingPermission{request, retry})
+		return
+	}
+	pp.request = request
+	pp.retry = retry
+	pp.cursor = 0
+	pp.visible = true
+}
+
+// Hide hides the prompt
+func (pp *PermissionPrompt) Hide() {
+	pp.visible = false
+}
+
+// takeQueued returns the requests waiting their turn and forgets them
+func (pp *PermissionPrompt) takeQueued() []pendingPermission {
+	queued := pp.queued
+	pp.queued = nil
+	return queued
+}
+
+// IsVisible returns whether the prompt is shown
+func (pp PermissionPrompt) IsVisible() bool {
+	return pp.visible
+}
+
+// SetSize updates the prompt dimensions
+func (pp *PermissionPrompt) SetSize(width, height int) {
+	pp.width = width
+	pp.height = height
+}
+
+// Update handles prompt input; Esc denies once
+func (pp PermissionPrompt) Update(msg tea.Msg) (PermissionPrompt, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || !pp.visible {
+		return pp, nil
+	}
+	key := keyMsg.String()
+	switch key {
+	case "up", "k":
+		pp.cursor = max(pp.cursor-1, 0)
+		return pp, nil
+	case "down", "j":
+		pp.cursor = min(pp.cursor+1, len(permissionChoices)-1)
+		return pp, nil
+	case "esc":
+		pp.cursor = 3
+	case "enter":
+	default:
+		if len(key) != 1 || key[0] < '1' || int(key[0]-'0') > len(permissionChoices) {
+			return pp, nil
+		}
+		pp.cursor = int(key[0] - '1')
+	}
+	choice := permissionChoices[pp.cursor]
+	pp.visible = false
+	action := pp.request.Action
+	return pp, func() tea.Msg {
+		return PermissionDecisionMsg{Action: action, Allow: choice.allow, Scope: choice.scope}
+	}
+}
+
+// View renders the prompt
+func (pp PermissionPrompt) View() string {
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("214"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	cursorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
+
+	lines := []string{titleStyle.Render("Permission: " + pp.request.Title), "", pp.request.Detail, ""}
+	for i, choice := range permissionChoices {
+		line := fmt.Sprintf("  %d. %s", i+1, choice.label)
+		if i == pp.cursor {
+			line = cursorStyle.Render(fmt.Sprintf("> %d. %s", i+1, choice.label))
+		}
+		lines = append(lines, line)
+	}
+	if len(pp.queued) > 0 {
+		lines = append(lines, "", dimStyle.Render(fmt.Sprintf("%d more waiting", len(pp.queued))))
+	}
+	lines = append(lines, "", dimStyle.Render("↑/↓ or 1-6: Choose | Enter: Answer | Esc: Deny"))
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("214")).
+		Padding(1, 2).
+		Width(min(72, max(pp.width-4, 40))).
+		Render(strings.Join(lines, "\n"))
+}
+
+// checkPermission says whether an action may run now. An action with no
+// remembered decision shows the prompt, which sends retry again once
+// answered
+func (m *Model) checkPermission(request permissionRequest, retry tea.Msg) permissionState {
+	decision, ok := m.oncePermissions[request.Action]
+	if ok {
+		delete(m.oncePermissions, request.Action)
+	} else if decision, ok = m.sessionPermissions[request.Action]; !ok {
+		decision, ok = m.config.Permissions[request.Action]
+	}
+	switch {
+	case ok && decision == permissionAllow:
+		return permissionAllowed
+	case ok:
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Not permitted: %s (/permissions reset %s asks again)", request.Title, request.Action), nil)
+		return permissionDenied
+	}
+	m.permissionPrompt.SetSize(m.width, m.height)
+	m.permissionPrompt.Show(request, retry)
+	return permissionAsked
+}
+
+// handlePermissionDecision remembers an answer for its scope and sends the
+// waiting action again, which now runs or is refused
+func (m *Model) handlePermissionDecision(msg PermissionDecisionMsg) tea.Cmd {
+	decision := permissionDeny
+	if msg.Allow {
+		decision = permissionAllow
+	}
+	switch msg.Scope {
+	case PermissionAlways:
+		if m.config.Permissions == nil {
+			m.config.Permissions = make(map[string]string)
+		}
+		m.config.Permissions[msg.Action] = decision
+		if err := SaveConfig(m.config); err != nil {
+			m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Failed to save config: %v", err), nil)
+		}
+	case PermissionSession:
+		m.sessionPermissions[msg.Action] = decision
+	default:
+		m.oncePermissions[msg.Action] = decision
+	}
+
+	cmds := []tea.Cmd{resendMsg(m.permissionPrompt.retry)}
+	m.permissionPrompt.retry = nil
+
+	// Requests made meanwhile are asked in turn, unless this answer is
+	// remembered for them too
+	for _, pending := range m.permissionPrompt.takeQueued() {
+		_, session := m.sessionPermissions[pending.request.Action]
+		_, always := m.config.Permissions[pending.request.Action]
+		if session || always {
+			cmds = append(cmds, resendMsg(pending.retry))
+			continue
+		}
+		m.permissionPrompt.Show(pending.request, pending.retry)
+	}
+	return tea.Batch(cmds...)
+}
+
+// resendMsg returns a command sending msg again, or nil when there is none
+func resendMsg(msg tea.Msg) tea.Cmd {
+	if msg == nil {
+		return nil
+	}
+	return func() tea.Msg { return msg }
+}
+
+// formatPermissions lists the remembered decisions for /permissions
+func (m Model) formatPermissions() string {
+	type remembered struct{ action, decision, scope string }
+	var all []remembered
+	for action, decision := range m.config.Permissions {
+		all = append(all, remembered{action, decision, "always"})
+	}
+	for action, decision := range m.sessionPermissions {
+		all = append(all, remembered{action, decision, "this session"})
+	}
+	if len(all) == 0 {
+		return "No remembered permissions. Commands ask before writing local files or running local programs"
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].action != all[j].action {
+			return all[i].action < all[j].action
+		}
+		return all[i].scope < all[j].scope
+	})
+
+	var b strings.Builder
+	b.WriteString("Remembered permissions:\n\n")
+	for _, r := range all {
+		fmt.Fprintf(&b, "  %-20s %-6s %s\n", r.action, r.decision, r.scope)
+	}
+	b.WriteString("\n/permissions reset [action] forgets them, so the next use asks again")
+	return b.String()
+}
+
+// resetPermissions forgets the remembered decisions for one action, or for
+// every action when it is empty
+func (m *Model) resetPermissions(action string) {
+	if action == "" {
+		m.sessionPermissions = make(map[string]string)
+		m.config.Permissions = nil
+	} else {
+		delete(m.sessionPermissions, action)
+		delete(m.config.Permissions, action)
+	}
+	if err := SaveConfig(m.config); err != nil {
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Failed to save config: %v", err), nil)
+		return
+	}
+	if action == "" {
+		m.statusBar = "Forgot all permissions - commands ask again"
+	} else {
+		m.statusBar = fmt.Sprintf("Forgot the permission for %s - it asks again", action)
+	}
+}
@@ -0,0 +1,76 @@
+package ui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+func TestCheckPermission_RemembersScopes(t *testing.T) {
+	testutil.IsolateHome(t)
+	model := NewModel()
+	request := permissionRequest{Action: permissionExec("gofmt"), Title: "run gofmt"}
+	retry := ExecuteCommandMsg{Command: "format_file"}
+
+	if state := model.checkPermission(request, retry); state != permissionAsked || !model.permissionPrompt.IsVisible() {
+		t.Fatal("Expected an undecided action to ask")
+	}
+
+	// Answering "Allow once" sends the command again, which is then allowed once
+	var cmd tea.Cmd
+	model.permissionPrompt, cmd = model.permissionPrompt.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("1")})
+	if got, ok := model.handlePermissionDecision(cmd().(PermissionDecisionMsg))().(ExecuteCommandMsg); !ok || got.Command != "format_file" {
+		t.Fatalf("Expected the command to be sent again, got %v", got)
+	}
+	if model.checkPermission(request, retry) != permissionAllowed {
+		t.Fatal("Expected the retry to be allowed")
+	}
+	if model.checkPermission(request, retry) != permissionAsked {
+		t.Fatal("Expected a once answer to be used up by the retry")
+	}
+
+	model.permissionPrompt, cmd = model.permissionPrompt.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("6")})
+	model.handlePermissionDecision(cmd().(PermissionDecisionMsg))
+	if model.checkPermission(request, retry) != permissionDenied || model.checkPermission(request, retry) != permissionDenied {
+		t.Fatal("Expected an always answer to keep denying")
+	}
+	if saved, err := LoadConfig(); err != nil || saved.Permissions["exec:gofmt"] != permissionDeny {
+		t.Fatal("Expected the always answer to be saved to config")
+	}
+
+	model.resetPermissions("exec:gofmt")
+	if model.checkPermission(request, retry) != permissionAsked {
+		t.Fatal("Expected a reset action to ask again")
+	}
+}
+
+func TestCheckPermission_QueuesRequestsWhileAsking(t *testing.T) {
+	testutil.IsolateHome(t)
+	model := NewModel()
+	gofmt := permissionRequest{Action: permissionExec("gofmt"), Title: "run gofmt"}
+	patch := permissionRequest{Action: permissionExportPatch, Title: "export the patch"}
+
+	model.checkPermission(gofmt, ExecuteCommandMsg{Command: "format_file"})
+	if model.checkPermission(patch, ExecuteCommandMsg{Command: "patch"}) != permissionAsked || model.permissionPrompt.request.Action != gofmt.Action {
+		t.Fatal("Expected the second request to wait behind the first")
+	}
+
+	// Answering the first runs its command and asks about the second
+	var cmd tea.Cmd
+	model.permissionPrompt, cmd = model.permissionPrompt.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("1")})
+	if got, ok := model.handlePermissionDecision(cmd().(PermissionDecisionMsg))().(ExecuteCommandMsg); !ok || got.Command != "format_file" {
+		t.Fatalf("Expected the first command to be sent again, got %v", got)
+	}
+	if !model.permissionPrompt.IsVisible() || model.permissionPrompt.request.Action != patch.Action {
+		t.Fatal("Expected the second request to be asked next")
+	}
+
+	model.permissionPrompt, cmd = model.permissionPrompt.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("4")})
+	if got, ok := model.handlePermissionDecision(cmd().(PermissionDecisionMsg))().(ExecuteCommandMsg); !ok || got.Command != "patch" {
+		t.Fatalf("Expected the second command to be sent again, got %v", got)
+	}
+	if model.permissionPrompt.IsVisible() || model.checkPermission(patch, nil) != permissionDenied {
+		t.Fatal("Expected the second answer to apply to its own request")
+	}
+}
@@ -0,0 +1,311 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxMacroDepth bounds macros that play macros, so one playing itself stops
+const maxMacroDepth = 8
+
+// macroKeyMsg is a key played back from a macro. It is handled like typed
+// input but never recorded again
+type macroKeyMsg struct {
+	Key   tea.KeyMsg
+	Depth int // How many macros deep the key was played
+}
+
+// macroState records keys into registers, vim style: Alt+Q and a register
+// records, Alt+Q again stops, and Alt+@ and a register plays it back. Plain
+// q and @ type into the chat, so the Alt forms are used everywhere
+type macroState struct {
+	registers map[string][]tea.KeyMsg
+	recording string       // Register being recorded, "" when not recording
+	keys      []tea.KeyMsg // Keys recorded so far
+	pending   string       // "record" or "play" while waiting for the register
+	last      string       // Register played last, for Alt+@ @
+}
+
+// validRegister reports whether a key names a register: a-z or 0-9
+func validRegister(key string) bool {
+	return len(key) == 1 && (key[0] >= 'a' && key[0] <= 'z' || key[0] >= '0' && key[0] <= '9')
+}
+
+// macroInput handles macro keys. It returns the message to go on handling,
+// a played key unwrapped, or handled when the key was used by the macros
+func (m *Model) macroInput(msg tea.Msg) (next tea.Msg, cmd tea.Cmd, handled bool) {
+	var key tea.KeyMsg
+	depth, replayed := 0, false
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		key = msg
+	case macroKeyMsg:
+		key, depth, replayed = msg.Key, msg.Depth, true
+	default:
+		return msg, nil, false
+	}
+	state := &m.macros
+
+	if pending := state.pending; pending != "" {
+		state.pending = ""
+		name := key.String()
+		if pending == "play" && name == "@" {
+			name = state.last
+		}
+		switch {
+		case name == "esc":
+			m.statusBar = "Macro cancelled"
+		case !validRegister(name):
+			m.statusMessages.AddMessage(StatusCategoryError, "Registers are a-z and 0-9", nil)
+		case pending == "record":
+			state.recording, state.keys = name, nil
+			m.statusBar = fmt.Sprintf("Recording macro @%s - Alt+Q stops", name)
+		default:
+			cmd = m.playMacro(name, depth)
+		}
+		return nil, cmd, true
+	}
+
+	switch key.String() {
+	case "alt+q":
+		if state.recording != "" {
+			m.stopMacroRecording()
+		} else if !replayed {
+			state.pending = "record"
+			m.statusBar = "Record macro into register: a-z or 0-9"
+		}
+		return nil, nil, true
+	case "alt+@":
+		state.pending = "play"
+		m.statusBar = "Play macro from register: a-z, 0-9, or @ for the last"
+		return nil, nil, true
+	}
+
+	// Keys typed into a masked field are left out, so PINs and passwords
+	// never reach a register or the macros file
+	if state.recording != "" && !replayed && !m.SecretInputFocused() {
+		state.keys = append(state.keys, key)
+	}
+	return key, nil, false
+}
+
+// stopMacroRecording stores the keys recorded into their register
+func (m *Model) stopMacroRecording() {
+	state := &m.macros
+	if state.registers == nil {
+		state.registers = make(map[string][]tea.KeyMsg)
+	}
+	state.registers[state.recording] = state.keys
+	m.statusBar = fmt.Sprintf("Recorded %d keys into @%s", len(state.keys), state.recording)
+	state.recording, state.keys = "", nil
+}
+
+// playMacro sends a register's keys in order, as if typed
+func (m *Model) playMacro(register string, depth int) tea.Cmd {
+	keys, ok := m.macros.registers[register]
+	if !ok || len(keys) == 0 {
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Register @%s is empty", register), nil)
+		return nil
+	}
+	if depth >= maxMacroDepth {
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Macros nest more than %d deep; stopped @%s", maxMacroDepth, register), nil)
+		return nil
+	}
+	m.macros.last = register
+	cmds := make([]tea.Cmd, len(keys))
+	for i, key := range keys {
+		msg := macroKeyMsg{Key: key, Depth: depth + 1}
+		cmds[i] = func() tea.Msg { return msg }
+	}
+	m.statusBar = fmt.Sprintf("Playing @%s (%d keys)", register, len(keys))
+	return tea.Sequence(cmds...)
+}
+
+// renderMacroBanner renders the indicator shown while recording
+func (m Model) renderMacroBanner() string {
+	return lipgloss.NewStyle().
+		Background(lipgloss.Color("160")).
+		Foreground(lipgloss.Color("231")).
+		Bold(true).
+		Padding(0, 1).
+		Render("REC @" + m.macros.recording)
+}
+
+// macrosPath returns the file named macro slots are saved in
+func macrosPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".rubber_duck", "macros.json"), nil
+}
+
+// loadMacroSlots reads the named macro slots
+func loadMacroSlots() (map[string][]tea.Key, error) {
+	path, err := macrosPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return map[string][]tea.Key{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	slots := map[string][]tea.Key{}
+	if err := json.Unmarshal(data, &slots); err != nil {
+		return nil, fmt.Errorf("macros.json is unreadable: %w", err)
+	}
+	return slots, nil
+}
+
+// saveMacroSlots writes the named macro slots
+func saveMacroSlots(slots map[string][]tea.Key) error {
+	path, err := macrosPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(slots, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// saveMacro saves a register to a named slot for /macro save
+func (m *Model) saveMacro(register, name string) {
+	keys, ok := m.macros.registers[register]
+	if !ok {
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Register @%s is empty", register), nil)
+		return
+	}
+	slots, err := loadMacroSlots()
+	if err == nil {
+		saved := make([]tea.Key, len(keys))
+		for i, key := range keys {
+			saved[i] = tea.Key(key)
+		}
+		slots[name] = saved
+		err = saveMacroSlots(slots)
+	}
+	if err != nil {
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Cannot save the macro: %v", err), nil)
+		return
+	}
+	m.statusBar = fmt.Sprintf("Saved @%s as %q", register, name)
+}
+
+// loadMacro loads a named slot into a register for /macro load, reporting
+// whether it did
+func (m *Model) loadMacro(name, register string) bool {
+	slots, err := loadMacroSlots()
+	if err != nil {
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Cannot load macros: %v", err), nil)
+		return false
+	}
+	saved, ok := slots[name]
+	if !ok {
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("No saved macro %q; /macro lists them", name), nil)
+		return false
+	}
+	keys := make([]tea.KeyMsg, len(saved))
+	for i, key := range saved {
+		keys[i] = tea.KeyMsg(key)
+	}
+	if m.macros.registers == nil {
+		m.macros.registers = make(map[string][]tea.KeyMsg)
+	}
+	m.macros.registers[register] = keys
+	m.statusBar = fmt.Sprintf("Loaded %q into @%s - Alt+@ %s plays it", name, register, register)
+	return true
+}
+
+// deleteMacro removes a named slot for /macro delete
+func (m *Model) deleteMacro(name string) {
+	slots, err := loadMacroSlots()
+	if err == nil {
+		if _, ok := slots[name]; !ok {
+			err = fmt.Errorf("no saved macro %q", name)
+		} else {
+			delete(slots, name)
+			err = saveMacroSlots(slots)
+		}
+	}
+	if err != nil {
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Cannot delete the macro: %v", err), nil)
+		return
+	}
+	m.statusBar = fmt.Sprintf("Deleted macro %q", name)
+}
+
+// describeKeys renders keys as typed, e.g. "hi⏎" or "<tab><alt+2>"
+func describeKeys(keys []tea.KeyMsg) string {
+	var b strings.Builder
+	for _, key := range keys {
+		switch {
+		case key.Type == tea.KeyRunes && !key.Alt:
+			b.WriteString(string(key.Runes))
+		case key.Type == tea.KeySpace:
+			b.WriteString(" ")
+		case key.Type == tea.KeyEnter:
+			b.WriteString("⏎")
+		default:
+			b.WriteString("<" + key.String() + ">")
+		}
+	}
+	return truncateStage(b.String(), 60)
+}
+
+// formatMacros lists the registers and named slots for /macro
+func (m Model) formatMacros() string {
+	var b strings.Builder
+	if m.macros.recording != "" {
+		fmt.Fprintf(&b, "Recording @%s (%d keys so far) - Alt+Q stops\n\n", m.macros.recording, len(m.macros.keys))
+	}
+	registers := make([]string, 0, len(m.macros.registers))
+	for register := range m.macros.registers {
+		registers = append(registers, register)
+	}
+	sort.Strings(registers)
+	if len(registers) == 0 {
+		b.WriteString("No macros recorded this session. Alt+Q and a register (a-z, 0-9) records, Alt+Q stops, Alt+@ and the register plays it\n")
+	} else {
+		b.WriteString("Registers:\n")
+		for _, register := range registers {
+			keys := m.macros.registers[register]
+			fmt.Fprintf(&b, "  @%s  %3d keys  %s\n", register, len(keys), describeKeys(keys))
+		}
+	}
+
+	slots, err := loadMacroSlots()
+	if err != nil {
+		fmt.Fprintf(&b, "\nCannot read saved macros: %v", err)
+		return b.String()
+	}
+	if len(slots) == 0 {
+		b.WriteString("\nNo saved macros. /macro save <register> <name> keeps one across sessions")
+		return b.String()
+	}
+	names := make([]string, 0, len(slots))
+	for name := range slots {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	b.WriteString("\nSaved:\n")
+	for _, name := range names {
+		fmt.Fprintf(&b, "  %-16s %3d keys\n", name, len(slots[name]))
+	}
+	b.WriteString("\n/macro load <name> [register] puts one in a register to play")
+	return b.String()
+}
@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+func TestMacros_RecordPlayAndSave(t *testing.T) {
+	testutil.IsolateHome(t)
+	model := NewModel()
+	model.chat.Focus()
+	send := func(msg tea.Msg) {
+		updated, _ := model.Update(msg)
+		*model = updated.(Model)
+	}
+	runes := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+	altQ := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q"), Alt: true}
+
+	send(altQ)
+	send(runes("a"))
+	if model.macros.recording != "a" {
+		t.Fatal("Expected Alt+Q a to record into @a")
+	}
+	send(runes("h"))
+	send(runes("i"))
+	send(altQ)
+	if got := model.macros.registers["a"]; len(got) != 2 || model.macros.recording != "" {
+		t.Fatalf("Expected the two keys typed to be recorded, got %v", got)
+	}
+	if model.chat.GetInputValue() != "hi" {
+		t.Fatalf("Expected recorded keys to still reach the chat, got %q", model.chat.GetInputValue())
+	}
+
+	// Played keys are typed like real ones
+	model.chat.SetInputValue("")
+	if model.playMacro("a", 0) == nil {
+		t.Fatal("Expected playing @a to send its keys")
+	}
+	for _, key := range model.macros.registers["a"] {
+		send(macroKeyMsg{Key: key, Depth: 1})
+	}
+	if model.chat.GetInputValue() != "hi" {
+		t.Fatalf("Expected playback to type the keys, got %q", model.chat.GetInputValue())
+	}
+	if model.playMacro("a", maxMacroDepth) != nil {
+		t.Fatal("Expected macros nested too deep to stop")
+	}
+
+	model.saveMacro("a", "greet")
+	model.macros.registers = nil
+	if !model.loadMacro("greet", "b") || len(model.macros.registers["b"]) != 2 {
+		t.Fatal("Expected the saved macro to load into @b")
+	}
+	if model.loadMacro("missing", "b") {
+		t.Fatal("Expected loading an unknown macro to fail")
+	}
+}
+
+func TestMacros_SkipMaskedInput(t *testing.T) {
+	testutil.IsolateHome(t)
+	model := NewModel()
+	send := func(msg tea.Msg) {
+		updated, _ := model.Update(msg)
+		*model = updated.(Model)
+	}
+	altQ := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q"), Alt: true}
+
+	send(altQ)
+	send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	send(ExecuteCommandMsg{Command: "login_form", Args: map[string]string{"username": "duck"}})
+	send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("hunter2pw")})
+	send(altQ)
+	if got := model.accountModal.value("password"); got != "hunter2pw" {
+		t.Fatalf("Expected the password still typed, got %q", got)
+	}
+	if got := model.macros.registers["a"]; len(got) != 0 {
+		t.Errorf("Expected keys typed into the password field left out, got %v", got)
+	}
+}
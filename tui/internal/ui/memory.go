@@ -0,0 +1,198 @@
+package ui
+
+import (
+	"fmt"
+	"hash/fnv"
+	"runtime"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// defaultTranscriptBudget is how many bytes of the newest messages render in
+// full when tui.transcript_budget_kb isn't set; older ones render as one line
+const defaultTranscriptBudget = 1 << 20
+
+// renderKey identifies a message's rendering: its content and the width it
+// was wrapped at
+type renderKey struct {
+	sum   uint64
+	width int
+}
+
+// renderCache keeps the glamour rendering of each message, so redrawing the
+// chat doesn't render unchanged messages again. Entries not used by the
+// last redraw are dropped, so the cache never outgrows the transcript
+type renderCache struct {
+	entries map[renderKey]string
+	used    map[renderKey]string
+	hits    int
+	misses  int
+}
+
+// begin starts a redraw
+func (rc *renderCache) begin() {
+	rc.used = make(map[renderKey]string, len(rc.entries))
+}
+
+// end drops the entries the redraw didn't use
+func (rc *renderCache) end() {
+	rc.entries, rc.used = rc.used, nil
+}
+
+// render returns content rendered by render, from the cache when it was
+// rendered at this width before
+func (rc *renderCache) render(content string, width int, render func(string) string) string {
+	h := fnv.New64a()
+	h.Write([]byte(content))
+	key := renderKey{sum: h.Sum64(), width: width}
+	rendered, ok := rc.entries[key]
+	if ok {
+		rc.hits++
+	} else {
+		rc.misses++
+		rendered = render(content)
+	}
+	if rc.used != nil {
+		rc.used[key] = rendered
+	}
+	return rendered
+}
+
+// size returns the bytes held by the cache
+func (rc *renderCache) size() int {
+	total := 0
+	for _, rendered := range rc.entries {
+		total += len(rendered)
+	}
+	return total
+}
+
+// SetTranscriptBudget sets how many kilobytes of the newest messages render
+// in full; 0 uses the default and a negative value renders everything
+func (c *Chat) SetTranscriptBudget(kb int) {
+	switch {
+	case kb == 0:
+		c.transcriptBudget = defaultTranscriptBudget
+	case kb < 0:
+		c.transcriptBudget = -1
+	default:
+		c.transcriptBudget = kb * 1024
+	}
+	c.viewport.SetContent(c.buildViewportContent())
+}
+
+// trimStart returns the index of the oldest message rendered in full; the
+// messages before it exceed the transcript budget. Stored messages are
+// never trimmed, only their rendering
+func (c *Chat) trimStart() int {
+	budget := c.budget()
+	if budget < 0 {
+		return 0
+	}
+	total := 0
+	for i := len(c.messages) - 1; i >= 0; i-- {
+		total += len(c.messages[i].Content)
+		// The newest message always renders, however large
+		if total > budget && i < len(c.messages)-1 {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// budget returns the transcript budget in bytes, negative when off
+func (c *Chat) budget() int {
+	if c.transcriptBudget == 0 {
+		return defaultTranscriptBudget
+	}
+	return c.transcriptBudget
+}
+
+// renderTrimmed renders a message beyond the budget as its first line
+func (c *Chat) renderTrimmed(index, width int) string {
+	first, _, _ := strings.Cut(strings.TrimSpace(c.messages[index].Content), "\n")
+	hint := "select with Alt+↑ and press o to show"
+	if c.selecting && c.selected == index {
+		hint = "press o to show"
+	}
+	footer := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("240")).
+		Italic(true).
+		Render(fmt.Sprintf("… %s not drawn to save memory, %s", formatBytes(int64(len(c.messages[index].Content))), hint))
+	return truncateStage(first, max(width, 20)) + "\n" + footer
+}
+
+// memoryEstimate is the memory one component is estimated to hold
+type memoryEstimate struct {
+	Component string
+	Bytes     int
+	Detail    string
+}
+
+// memoryEstimates estimates what the larger components hold, by the size of
+// the text they keep
+func (m Model) memoryEstimates() []memoryEstimate {
+	stored := 0
+	for _, msg := range m.chat.messages {
+		stored += len(msg.Content) + len(msg.Full) + len(msg.Previous)
+	}
+	chatDetail := fmt.Sprintf("%d messages", len(m.chat.messages))
+	if spilled := m.chat.spill.count(); spilled > 0 {
+		chatDetail += fmt.Sprintf(", %d more compressed on disk", spilled)
+	}
+
+	renderDetail := "everything drawn"
+	if trimmed := m.chat.trimStart(); trimmed > 0 {
+		renderDetail = fmt.Sprintf("%d oldest messages drawn as one line", trimmed)
+	}
+	cache := m.chat.renderCache
+	cacheDetail := fmt.Sprintf("%d messages", len(cache.entries))
+	if total := cache.hits + cache.misses; total > 0 {
+		cacheDetail += fmt.Sprintf(", %.0f%% of renders reused", float64(cache.hits)*100/float64(total))
+	}
+
+	changes := 0
+	for _, change := range m.appliedChanges {
+		changes += len(change.Before) + len(change.After)
+	}
+	for _, snapshot := range m.changeSnapshots {
+		changes += len(snapshot.Content)
+	}
+
+	editorDetail := m.currentFile
+	if editorDetail == "" {
+		editorDetail = "no file open"
+	}
+
+	return []memoryEstimate{
+		{"Chat messages", stored, chatDetail},
+		{"Rendered transcript", m.chat.renderedBytes, renderDetail},
+		{"Render cache", cache.size(), cacheDetail},
+		{"Editor", len(m.editor.Value()) + len(m.editorOriginal), editorDetail},
+		{"Applied changes", changes, fmt.Sprintf("%d files, kept for /patch and /rollback", len(m.appliedChanges)+len(m.changeSnapshots))},
+		{"Stream buffer", len(m.streamBuffer), "answer being received"},
+	}
+}
+
+// formatMemory describes the memory estimates and the Go runtime's totals
+// for /memory
+func (m Model) formatMemory() string {
+	var b strings.Builder
+	b.WriteString("Estimated memory by component:\n")
+	for _, estimate := range m.memoryEstimates() {
+		fmt.Fprintf(&b, "  %-20s %9s  %s\n", estimate.Component, formatBytes(int64(estimate.Bytes)), estimate.Detail)
+	}
+
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	fmt.Fprintf(&b, "\nProcess: %s in use, %s from the OS, %d garbage collections, %d goroutines",
+		formatBytes(int64(stats.HeapAlloc)), formatBytes(int64(stats.Sys)), stats.NumGC, runtime.NumGoroutine())
+
+	if budget := m.chat.budget(); budget >= 0 {
+		fmt.Fprintf(&b, "\n\nThe newest %s of messages are drawn in full; set tui.transcript_budget_kb to change it", formatBytes(int64(budget)))
+	} else {
+		b.WriteString("\n\nEvery message is drawn in full (tui.transcript_budget_kb is negative)")
+	}
+	return b.String()
+}
@@ -0,0 +1,50 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTranscriptBudgetTrimsOldRenderings(t *testing.T) {
+	chat := NewChat()
+	chat.SetSize(80, 40)
+	chat.SetTranscriptBudget(1)
+	old := "first line of the old answer\n" + strings.Repeat("x", 2000)
+	chat.AddMessage(AssistantMessage, old, "assistant")
+	chat.AddMessage(UserMessage, "latest question", "user")
+
+	view := chat.buildViewportContent()
+	if !strings.Contains(view, "not drawn to save memory") || strings.Contains(view, "xxxx") {
+		t.Fatalf("Expected the old answer drawn as one line, got:\n%s", view)
+	}
+	if chat.GetMessages()[0].Content != old {
+		t.Fatal("Trimming must keep the stored message")
+	}
+
+	chat.toggleExpanded(0)
+	if view := chat.buildViewportContent(); strings.Contains(view, "not drawn") {
+		t.Fatal("Expected an expanded message to be drawn in full")
+	}
+
+	chat.SetTranscriptBudget(-1)
+	chat.toggleExpanded(0)
+	if view := chat.buildViewportContent(); strings.Contains(view, "not drawn") {
+		t.Fatal("Expected a negative budget to draw everything")
+	}
+}
+
+func TestRenderCacheReusesRenderings(t *testing.T) {
+	chat := NewChat()
+	chat.SetSize(80, 40)
+	chat.AddMessage(AssistantMessage, "# Title\n\nSome **bold** text", "assistant")
+	chat.buildViewportContent()
+	misses := chat.renderCache.misses
+
+	chat.buildViewportContent()
+	if chat.renderCache.misses != misses || chat.renderCache.hits == 0 {
+		t.Fatalf("Expected the second redraw to reuse the rendering, got %d hits and %d misses", chat.renderCache.hits, chat.renderCache.misses)
+	}
+	if len(chat.renderCache.entries) != 1 {
+		t.Fatalf("Expected one cached rendering, got %d", len(chat.renderCache.entries))
+	}
+}
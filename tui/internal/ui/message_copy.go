@@ -0,0 +1,190 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glamour/styles"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// Formats a selected message can be copied in
+const (
+	CopyMarkdown = "markdown" // The message as written
+	CopyPlain    = "plain"    // Rendered, without markdown syntax
+	CopyCode     = "code"     // Only the contents of its code blocks
+	CopyJSON     = "json"     // The message with its metadata
+)
+
+// copyFormats are the copy menu's entries, by key
+var copyFormats = []struct {
+	Key    string
+	Format string
+	Label  string
+}{
+	{"m", CopyMarkdown, "Markdown"},
+	{"t", CopyPlain, "Plain text"},
+	{"c", CopyCode, "Code blocks"},
+	{"j", CopyJSON, "JSON"},
+}
+
+// CopyMessageMsg copies one message in a format
+type CopyMessageMsg struct {
+	Index  int
+	Format string
+}
+
+// messageExport is a message copied as JSON
+type messageExport struct {
+	ID        string                    `json:"id,omitempty"`
+	Role      string                    `json:"role"`
+	Author    string                    `json:"author,omitempty"`
+	Timestamp time.Time                 `json:"timestamp"`
+	Content   string                    `json:"content"`
+	Pinned    bool                      `json:"pinned,omitempty"`
+	Sources   []phoenix.RetrievedSource `json:"sources,omitempty"`
+	Trace     []phoenix.ToolStep        `json:"trace,omitempty"`
+}
+
+// updateCopyMenu handles a key while the copy menu is open
+func (c Chat) updateCopyMenu(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+	if key == "esc" {
+		c.copyMenu = false
+		return c, nil
+	}
+	for _, entry := range copyFormats {
+		if key == entry.Key {
+			c.copyMenu = false
+			copied := CopyMessageMsg{Index: c.selected, Format: entry.Format}
+			return c, func() tea.Msg { return copied }
+		}
+	}
+	return c, nil
+}
+
+// copyMenuHint lists the copy menu's entries for the selection hint line
+func copyMenuHint() string {
+	entries := make([]string, len(copyFormats))
+	for i, entry := range copyFormats {
+		entries[i] = entry.Key + ": " + entry.Label
+	}
+	return "Copy as " + strings.Join(entries, " | ") + " | Esc: Back"
+}
+
+// formatMessageCopy renders a message in a copy format
+func formatMessageCopy(msg ChatMessage, format string) (string, error) {
+	content := msg.Content
+	if msg.Full != "" {
+		content = msg.Full
+	}
+	switch format {
+	case CopyPlain:
+		return plainText(content)
+	case CopyCode:
+		blocks := codeBlocks(content)
+		if len(blocks) == 0 {
+			return "", fmt.Errorf("the message has no code blocks")
+		}
+		return strings.Join(blocks, "\n\n"), nil
+	case CopyJSON:
+		data, err := json.MarshalIndent(messageExport{
+			ID:        msg.ID,
+			Role:      roleName(msg.Type),
+			Author:    msg.Author,
+			Timestamp: msg.Timestamp,
+			Content:   content,
+			Pinned:    msg.Pinned,
+			Sources:   msg.Sources,
+			Trace:     msg.Trace,
+		}, "", "  ")
+		return string(data), err
+	default:
+		return content, nil
+	}
+}
+
+// plainText renders markdown as unstyled text, without its margin
+func plainText(markdown string) (string, error) {
+	// The dark style without the padding that stands in for backgrounds
+	style := styles.DarkStyleConfig
+	style.H1.Prefix, style.H1.Suffix = "", ""
+	style.Code.Prefix, style.Code.Suffix = "", ""
+	renderer, err := glamour.NewTermRenderer(glamour.WithStyles(style), glamour.WithWordWrap(0))
+	if err != nil {
+		return "", err
+	}
+	rendered, err := renderer.Render(markdown)
+	if err != nil {
+		return "", err
+	}
+	lines := strings.Split(ansi.Strip(rendered), "\n")
+	margin := -1
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+		if lines[i] == "" {
+			continue
+		}
+		if indent := len(lines[i]) - len(strings.TrimLeft(lines[i], " ")); margin < 0 || indent < margin {
+			margin = indent
+		}
+	}
+	for i, line := range lines {
+		if len(line) >= margin && margin > 0 {
+			lines[i] = line[margin:]
+		}
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n"), nil
+}
+
+// codeBlocks returns the contents of a message's fenced code blocks
+func codeBlocks(markdown string) []string {
+	var blocks []string
+	var block []string
+	inBlock := false
+	for _, line := range strings.Split(markdown, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			if inBlock {
+				blocks = append(blocks, strings.Join(block, "\n"))
+				block = nil
+			}
+			inBlock = !inBlock
+			continue
+		}
+		if inBlock {
+			block = append(block, line)
+		}
+	}
+	// An unclosed block runs to the end
+	if inBlock && len(block) > 0 {
+		blocks = append(blocks, strings.Join(block, "\n"))
+	}
+	return blocks
+}
+
+// copyMessage copies a message to the clipboard in the format picked
+func (m *Model) copyMessage(msg CopyMessageMsg) {
+	messages := m.chat.GetMessages()
+	if msg.Index < 0 || msg.Index >= len(messages) {
+		return
+	}
+	content, err := formatMessageCopy(messages[msg.Index], msg.Format)
+	if err == nil {
+		err = clipboard.WriteAll(content)
+	}
+	if err != nil {
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Cannot copy the message: %v", err), nil)
+		return
+	}
+	for _, entry := range copyFormats {
+		if entry.Format == msg.Format {
+			m.statusBar = fmt.Sprintf("Copied the message (%s)", entry.Label)
+		}
+	}
+}
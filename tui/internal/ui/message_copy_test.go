@@ -0,0 +1,52 @@
+package ui
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestFormatMessageCopy(t *testing.T) {
+	msg := ChatMessage{Type: AssistantMessage, Author: "assistant", Content: "Use **this**:\n\n```go\nfmt.Println(1)\n```\n\nand\n\n```sh\ngo run .\n```"}
+
+	if got, _ := formatMessageCopy(msg, CopyMarkdown); got != msg.Content {
+		t.Errorf("Expected the raw markdown, got %q", got)
+	}
+	if got, _ := formatMessageCopy(msg, CopyCode); got != "fmt.Println(1)\n\ngo run ." {
+		t.Errorf("Expected only the code, got %q", got)
+	}
+	plain, err := formatMessageCopy(msg, CopyPlain)
+	if err != nil || strings.Contains(plain, "**") || strings.Contains(plain, "```") || !strings.HasPrefix(plain, "Use this:") {
+		t.Errorf("Expected plain text without markdown, got %q (%v)", plain, err)
+	}
+	data, _ := formatMessageCopy(msg, CopyJSON)
+	var exported messageExport
+	if err := json.Unmarshal([]byte(data), &exported); err != nil || exported.Role != "assistant" || exported.Content != msg.Content {
+		t.Errorf("Unexpected JSON %s (%v)", data, err)
+	}
+	if _, err := formatMessageCopy(ChatMessage{Content: "no code"}, CopyCode); err == nil {
+		t.Error("Expected copying code from a message without any to fail")
+	}
+}
+
+func TestCopyMenuPicksFormat(t *testing.T) {
+	chat := NewChat()
+	chat.SetSize(80, 30)
+	chat.focused = true
+	chat.AddMessage(UserMessage, "question", "user")
+	chat.AddMessage(AssistantMessage, "answer", "assistant")
+
+	var model tea.Model = *chat
+	for _, key := range []tea.KeyMsg{{Type: tea.KeyUp, Alt: true}, {Type: tea.KeyRunes, Runes: []rune("c")}} {
+		model, _ = model.(Chat).Update(key)
+	}
+	if !model.(Chat).copyMenu {
+		t.Fatal("Expected c to open the copy menu")
+	}
+	_, cmd := model.(Chat).Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	if got := cmd().(CopyMessageMsg); got.Index != 1 || got.Format != CopyJSON {
+		t.Errorf("Unexpected copy %+v", got)
+	}
+}
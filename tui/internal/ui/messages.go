@@ -10,7 +10,17 @@ import (
 
 // UI messages
 type WindowSizeMsg struct{ Width, Height int }
-type FileSelectedMsg struct{ Path string }
+type FileSelectedMsg struct {
+	Path    string
+	Line    int // 1-based line to jump to, 0 for the top
+	EndLine int // Last line of a referenced range, 0 for none
+}
+// FileContentMsg delivers a file read off the update loop for the editor
+type FileContentMsg struct {
+	File FileSelectedMsg
+	Data []byte
+	Err  error
+}
 type EditorUpdateMsg struct{ Content string }
 type ErrorMsg struct {
 	Err       error
@@ -19,7 +29,11 @@ type ErrorMsg struct {
 }
 
 // Chat messages
-type ChatMessageSentMsg struct{ Content string }
+type ChatMessageSentMsg struct {
+	Content         string
+	BudgetConfirmed bool // User accepted a send that exceeds the budget
+	SecretsChecked  bool // Secrets were masked, or the user chose to send them
+}
 type ChatMessageReceivedMsg struct {
 	Content string
 	Type    string // "assistant", "system", "error"
@@ -27,8 +41,17 @@ type ChatMessageReceivedMsg struct {
 
 // Command messages
 type ExecuteCommandMsg struct {
-	Command string
-	Args    map[string]string
+	Command   string
+	Args      map[string]string
+	Confirmed bool   // Set once a destructive command was confirmed
+	Format    string // "json" replaces the command's output with a JSON object
+}
+
+// ClearCacheMsg drops cached answers to server queries, all of them when
+// Events is empty
+type ClearCacheMsg struct {
+	Events []string
+	Quiet  bool // Don't report it in the status bar
 }
 
 // Modal messages
@@ -0,0 +1,102 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rubber_duck/tui/internal/clock"
+	"github.com/rubber_duck/tui/internal/metrics"
+)
+
+// metricsShutdownTimeout bounds stopping the listener and the last export
+const metricsShutdownTimeout = 3 * time.Second
+
+// MetricsConfig exposes usage metrics to local monitoring. Both are off
+// unless set
+type MetricsConfig struct {
+	Listen       string `json:"listen,omitempty"`        // Serve Prometheus metrics here, e.g. "127.0.0.1:9464"; loopback only
+	OTLPEndpoint string `json:"otlp_endpoint,omitempty"` // Export command spans to this OTLP/HTTP traces URL, e.g. "http://localhost:4318/v1/traces"
+}
+
+// StartMetrics serves metrics and exports traces as configured. Non-empty
+// arguments, from the command line, override the config
+func (m *Model) StartMetrics(listen, otlpEndpoint string) error {
+	if listen == "" {
+		listen = m.config.Metrics.Listen
+	}
+	if otlpEndpoint == "" {
+		otlpEndpoint = m.config.Metrics.OTLPEndpoint
+	}
+	if listen != "" {
+		if err := m.metrics.Serve(listen); err != nil {
+			return err
+		}
+	}
+	if otlpEndpoint != "" {
+		m.metrics.SetExporter(metrics.NewExporter(otlpEndpoint))
+	}
+	return nil
+}
+
+// StopMetrics stops the listener and sends the spans not yet exported
+func (m *Model) StopMetrics() {
+	ctx, cancel := context.WithTimeout(context.Background(), metricsShutdownTimeout)
+	defer cancel()
+	_ = m.metrics.Close(ctx)
+}
+
+// observeCommand counts a command and times it
+func (m Model) observeCommand(command, status string, started time.Time) {
+	ended := time.Now()
+	m.metrics.Inc(metrics.CommandsTotal, "command", command, "status", status)
+	m.metrics.Observe(metrics.CommandSeconds, ended.Sub(started), "command", command)
+	m.metrics.Span(metrics.Span{
+		Name:       "command " + command,
+		Start:      started,
+		End:        ended,
+		Attributes: map[string]string{"command": command, "status": status},
+		Failed:     status == auditError,
+	})
+}
+
+// observeRequest counts a server request and times its round trip
+func observeRequest(registry *metrics.Registry, request pendingRequest) {
+	ended := clock.Now()
+	registry.Inc(metrics.RequestsTotal, "event", request.Event)
+	registry.Observe(metrics.RequestSeconds, ended.Sub(request.SentAt), "event", request.Event)
+	registry.Span(metrics.Span{
+		Name:       "request " + request.Event,
+		Start:      request.SentAt,
+		End:        ended,
+		Attributes: map[string]string{"event": request.Event, "topic": request.Topic},
+	})
+}
+
+// metricsStatus describes where metrics go and sums up the counters
+func (m Model) metricsStatus() string {
+	var b strings.Builder
+	if addr := m.metrics.Addr(); addr != "" {
+		fmt.Fprintf(&b, "Prometheus metrics: http://%s/metrics\n", addr)
+	} else {
+		b.WriteString("Prometheus metrics: off (set metrics.listen in config or pass -metrics-addr 127.0.0.1:9464)\n")
+	}
+	if exporter := m.metrics.Exporter(); exporter != nil {
+		pending, dropped, err := exporter.Status()
+		fmt.Fprintf(&b, "Traces: exported to %s, %d waiting", exporter.Endpoint(), pending)
+		if dropped > 0 {
+			fmt.Fprintf(&b, ", %d dropped", dropped)
+		}
+		if err != nil {
+			fmt.Fprintf(&b, "\n  Last export failed: %v", err)
+		}
+		b.WriteString("\n")
+	} else {
+		b.WriteString("Traces: off (set metrics.otlp_endpoint in config or pass -otlp-endpoint)\n")
+	}
+	fmt.Fprintf(&b, "\nSince start: %.0f commands, %.0f server requests, %.0f errors, %.0f reconnects",
+		m.metrics.Counter(metrics.CommandsTotal), m.metrics.Counter(metrics.RequestsTotal),
+		m.metrics.Counter(metrics.ErrorsTotal), m.metrics.Counter(metrics.ReconnectsTotal))
+	return b.String()
+}
@@ -1,6 +1,12 @@
 package ui
 
-import tea "github.com/charmbracelet/bubbletea"
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
 
 // ModalType represents different types of modals
 type ModalType int
@@ -21,6 +27,9 @@ type Modal struct {
 	visible   bool
 	width     int
 	height    int
+	offset    int // First content line shown when the content is too tall
+	onConfirm tea.Cmd
+	onCancel  tea.Cmd
 }
 
 // NewModal creates a new modal
@@ -31,22 +40,144 @@ func NewModal() Modal {
 	}
 }
 
+// ShowConfirm displays a yes/no confirmation; onConfirm runs when accepted
+func (m *Modal) ShowConfirm(title, content string, onConfirm tea.Cmd) {
+	m.ShowChoice(title, content, onConfirm, nil)
+}
+
+// ShowChoice displays a yes/no question whose answer matters either way;
+// onCancel runs when it is declined
+func (m *Modal) ShowChoice(title, content string, onConfirm, onCancel tea.Cmd) {
+	m.modalType = ConfirmModal
+	m.title = title
+	m.content = content
+	m.onConfirm = onConfirm
+	m.onCancel = onCancel
+	m.visible = true
+}
+
+// Show displays an informational modal
+func (m *Modal) Show(modalType ModalType, title, content string) {
+	m.modalType = modalType
+	m.title = title
+	m.content = content
+	m.offset = 0
+	m.onConfirm = nil
+	m.onCancel = nil
+	m.visible = true
+}
+
+// Hide hides the modal
+func (m *Modal) Hide() {
+	m.visible = false
+	m.onConfirm = nil
+	m.onCancel = nil
+}
+
+// SetSize updates the modal dimensions
+func (m *Modal) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+}
+
 // Update handles modal updates
 func (m Modal) Update(msg tea.Msg) (Modal, tea.Cmd) {
-	// TODO: Implement modal update logic
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || !m.visible {
+		return m, nil
+	}
+
+	if m.modalType == ConfirmModal {
+		switch keyMsg.String() {
+		case "y", "Y", "enter":
+			cmd := m.onConfirm
+			m.Hide()
+			return m, cmd
+		case "n", "N", "esc":
+			cmd := m.onCancel
+			m.Hide()
+			return m, cmd
+		}
+		return m, nil
+	}
+
+	// Other modals close on any dismiss key and scroll tall content
+	page := m.contentHeight()
+	switch keyMsg.String() {
+	case "esc", "enter", "q":
+		m.Hide()
+	case "up", "k":
+		m.scroll(-1)
+	case "down", "j":
+		m.scroll(1)
+	case "pgup":
+		m.scroll(-page)
+	case "pgdown", " ":
+		m.scroll(page)
+	case "home", "g":
+		m.offset = 0
+	case "end", "G":
+		m.scroll(strings.Count(m.content, "\n") + 1)
+	}
 	return m, nil
 }
 
+// contentHeight is how many content lines fit on screen, or 0 when the
+// modal hasn't been sized
+func (m Modal) contentHeight() int {
+	if m.height == 0 {
+		return 0
+	}
+	// Leave room for the border, padding, title, and hint
+	return max(m.height-10, 3)
+}
+
+// scroll moves the content window, keeping it within the content
+func (m *Modal) scroll(lines int) {
+	height := m.contentHeight()
+	total := strings.Count(m.content, "\n") + 1
+	if height == 0 || total <= height {
+		return
+	}
+	m.offset = min(max(m.offset+lines, 0), total-height)
+}
+
 // View renders the modal
 func (m Modal) View() string {
 	if !m.visible {
 		return ""
 	}
-	// TODO: Implement modal view
-	return "Modal (not yet implemented)"
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("220"))
+
+	hintStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("240"))
+
+	hint := "Enter/Esc: Close"
+	if m.modalType == ConfirmModal {
+		hint = "y/Enter: Confirm | n/Esc: Cancel"
+	}
+
+	content := m.content
+	lines := strings.Split(content, "\n")
+	if height := m.contentHeight(); height > 0 && len(lines) > height && m.modalType != ConfirmModal {
+		content = strings.Join(lines[m.offset:m.offset+height], "\n")
+		hint = fmt.Sprintf("↑/↓ PgUp/PgDn: Scroll (%d-%d of %d) | ", m.offset+1, m.offset+height, len(lines)) + hint
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		titleStyle.Render(m.title),
+		"",
+		content,
+		"",
+		hintStyle.Render(hint),
+	)
 }
 
 // IsVisible returns whether the modal is visible
 func (m Modal) IsVisible() bool {
 	return m.visible
-}
\ No newline at end of file
+}
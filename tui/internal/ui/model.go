@@ -1,13 +1,17 @@
 package ui
 
 import (
+	"fmt"
 	"time"
 	
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/nshafer/phx"
+	"github.com/rubber_duck/tui/internal/clock"
 	"github.com/rubber_duck/tui/internal/phoenix"
+	"github.com/rubber_duck/tui/internal/metrics"
+	"github.com/rubber_duck/tui/internal/telemetry"
 )
 
 // Pane represents the different panes in the UI
@@ -18,6 +22,7 @@ const (
 	FileTreePane
 	EditorPane
 	OutputPane
+	OutlinePane
 )
 
 // Model represents the application state
@@ -43,6 +48,29 @@ type Model struct {
 	editor       textarea.Model
 	showEditor   bool
 	currentFile  string
+	editorOriginal    string    // Buffer content as last loaded from disk
+	currentFileStamp  fileStamp // Disk version of the open file
+	fileChangedOnDisk bool      // Open file changed externally while edited
+	outline      Outline
+	showOutline  bool
+	offline      bool   // True when the server is unreachable
+	localConversationID string // Used to save conversations without a server ID
+	conversationTags    []string // Tags of the conversation saved as tagsFor
+	tagsFor             string
+	conversationSettings conversationSettings // Model overrides of the conversation saved as settingsFor
+	settingsFor          string
+	historyCursor       string // Cursor for the next older history page, empty when none
+	tutorial            Tutorial
+	demo                *phoenix.MockClient // Set by --demo: answers come from a scripted scenario
+	pendingUnredacted   string              // Message held back for containing secrets
+	regenerateFrom      string              // Answer being regenerated, diffed against the new one
+	formatError         string              // Last formatter failure, shown above the editor
+	pendingChanges      []phoenix.FileChange // File rewrites from the last refactor response, for /apply
+	changeSnapshots     []fileSnapshot       // Files before the last applied review, for /rollback
+	appliedChanges      map[string]appliedChange // Files changed by applied refactors, for /patch
+	hunksApplied        bool                 // The editor buffer holds applied hunks not yet saved
+	saveSeq             int                  // Saves started, so a late failure can tell it is stale
+	responseFilters     []ResponseFilter    // Post-process assistant responses before they are shown
 	
 	// Output pane state
 	output       viewport.Model
@@ -53,12 +81,16 @@ type Model struct {
 	statusClient interface{} // Will be *phoenix.StatusClient
 	apiKeyClient interface{} // Will be *phoenix.ApiKeyClient
 	planningClient interface{} // Will be *phoenix.PlanningClient
+	adminClient  interface{} // Will be *phoenix.AdminClient
+	indexClient  interface{} // Will be *phoenix.IndexClient
 	socket       *phx.Socket
 	authSocket   *phx.Socket // Separate socket for auth operations
 	channel      *phx.Channel
 	connected    bool
 	phoenixURL   string
 	authSocketURL string
+	networkFlags  phoenix.NetworkOptions // Proxy and TLS options given as flags
+	networkProfile string               // Profile picked with -network-profile
 	apiKey       string
 	jwtToken     string // JWT token received after authentication
 	
@@ -67,6 +99,10 @@ type Model struct {
 	username      string
 	userID        string // User ID for api_keys channel
 	switchingSocket bool // True when switching from auth to user socket
+	saveKeyAfterLogin bool // Generate and save an API key once the api_keys channel joins
+	reauthRequired    bool // The server revoked the credentials; waiting for a new login
+	permissions   phoenix.Permissions // What the signed-in user may do; nil when the server doesn't say
+	roles         []string
 	
 	// Status bar
 	statusBar    string
@@ -78,10 +114,40 @@ type Model struct {
 	lastReconnectTime time.Time
 	totalConnectionAttempts int
 	connectionBlocked bool
+	serverChange      *serverChange // A known server that changed; connecting is refused
 	
 	// Modal states
 	modal        Modal
 	commandPalette CommandPalette
+	composeModal ComposeModal
+	accountModal AccountModal
+	apiKeyModal  APIKeyModal
+	apiKeyList   APIKeyList
+	lockScreen   LockScreen
+	lastInput      time.Time // Last key or mouse input, for the idle lock
+	idleLockWarned bool      // The idle lock couldn't lock and said why
+	searchPane   SearchPane
+	applyPreview ApplyPreview
+	changesReview ChangesReview
+	permissionPrompt   PermissionPrompt
+	sessionPermissions map[string]string // Permission answers remembered until exit
+	oncePermissions    map[string]string // Answers for the retry of the action they were given for
+	macros       macroState
+	reactView    ReactView
+	deadLetters  DeadLetterView
+	modelSwitcher ModelSwitcher
+	emojiPicker   EmojiPicker
+	statusLegend  StatusLegend
+	statusSubscriptions StatusSubscriptions
+	toast         *toast // Event notice shown over the top-right corner
+	askPanel     AskPanel
+	editorMark   int // 1-based line the editor selection starts at, 0 for none
+	adminPane    AdminPane
+	problemsPane ProblemsPane
+	adminRefreshing bool // An adminRefreshMsg tick is scheduled
+	providerStatus  phoenix.ProviderStatus // Last health reported for the displayed provider
+	pendingIndex      string // Index operation waiting for the index channel: "build" or "status"
+	pendingIndexForce bool
 	
 	// LLM configuration
 	currentModel    string
@@ -90,24 +156,53 @@ type Model struct {
 	
 	// Conversation metadata
 	conversationID string
+	capabilities   phoenix.Capabilities // Negotiated on join; nil assumes every feature
+	serverVersion  string
+	network        networkMeter // Socket send and receive rates
 	messageCount   int
 	tokenUsage     int
 	tokenLimit     int
 	
 	// Status category metadata
 	categoryMetadata map[string]CategoryInfo
+	statusAvailable   []string // Status categories the server offers
+	statusSubscribing bool     // The first subscription after joining the status channel is pending
 	
 	// Configuration
-	config *Config
+	config     *Config
+	projectDir string // Absolute project directory; local paths resolve against it
 	
 	// Mouse mode toggle
 	mouseEnabled bool
 	
 	// Processing state
 	isProcessing bool // True when waiting for response from server
+	activity     ActivityIndicator
+	latency      *LatencyTracker
+	cost         *CostTracker
+	costInputTokens int // Estimated prompt tokens of the in-flight request
+	
+	// Local Ollama backend and streamed response state
+	ollamaClient *phoenix.OllamaClient
+	streamID     string
+	streamBuffer string
+	
+	// Model fallback state for the in-flight prompt
+	lastPrompt      string
+	lastReplyTo     string // Server reference of the message the prompt replies to
+	fallbackTried   []ModelRef
+	activeFallback  *ModelRef
+	pendingFallback *ModelRef
 	
 	// Response handlers
 	responseHandlers *ResponseHandlerRegistry
+	requests         *requestTracker         // Pushes awaiting correlated responses
+	commandQueue     phoenix.CommandQueueMsg // Commands waiting for a slot, latest snapshot
+	auditFailed      bool                    // Writing the audit log failed; said once
+	commandResults   func(commandResult)     // Takes each command's output instead of the chat, in headless mode
+	timedOut         *pendingRequest         // Last push that timed out, for /retry
+	telemetry        *telemetry.Recorder
+	metrics          *metrics.Registry       // Counters and latencies for -metrics-addr and traces
 }
 
 // CategoryInfo stores metadata about a status category
@@ -133,9 +228,12 @@ func NewModel() *Model {
 	// Create Phoenix client
 	phoenixClient := phoenix.NewClient()
 	authClient := phoenix.NewAuthClient()
-	statusClient := phoenix.NewStatusClient()
-	apiKeyClient := phoenix.NewApiKeyClient()
-	planningClient := phoenix.NewPlanningClient()
+	// Channel clients share the user socket through the client's channel manager
+	statusClient := phoenix.NewStatusClient(phoenixClient.Channels())
+	apiKeyClient := phoenix.NewApiKeyClient(phoenixClient.Channels())
+	planningClient := phoenix.NewPlanningClient(phoenixClient.Channels())
+	adminClient := phoenix.NewAdminClient(phoenixClient.Channels())
+	indexClient := phoenix.NewIndexClient(phoenixClient.Channels())
 	
 	// Create chat header
 	chatHeader := NewChatHeader()
@@ -170,11 +268,24 @@ func NewModel() *Model {
 		output:       output,
 		showFileTree: false,    // Hidden by default
 		showEditor:   false,    // Hidden by default
+		outline:      NewOutline(),
 		statusBar:    "Welcome to RubberDuck TUI | Connecting to auth server...",
 		systemMessage: "", // Start with empty system message
 		errorHandler: errorHandler,
 		modal:        NewModal(),
 		commandPalette: NewCommandPalette(),
+		composeModal: NewComposeModal(),
+		accountModal: NewAccountModal(),
+		lockScreen:   NewLockScreen(),
+		sessionPermissions: make(map[string]string),
+		oncePermissions:    make(map[string]string),
+		lastInput:    clock.Now(),
+		searchPane:   NewSearchPane(),
+		adminPane:    NewAdminPane(),
+		problemsPane: NewProblemsPane(),
+		activity:     NewActivityIndicator(),
+		latency:      NewLatencyTracker(),
+		cost:         NewCostTracker(),
 		phoenixURL:   "ws://localhost:5555/socket",
 		authSocketURL: "ws://localhost:5555/auth_socket",
 		apiKey:       config.APIKey, // Load API key from config
@@ -184,9 +295,11 @@ func NewModel() *Model {
 		statusClient: statusClient,
 		apiKeyClient: apiKeyClient,
 		planningClient: planningClient,
+		adminClient:  adminClient,
+		indexClient:  indexClient,
 		currentModel:    config.DefaultModel,    // Load from config or empty for default
 		currentProvider: config.DefaultProvider, // Load from config or empty for unknown
-		temperature:     0.7,
+		temperature:     defaultTemperature,
 		authenticated:   false,
 		username:     "",
 		userID:       "",
@@ -196,12 +309,25 @@ func NewModel() *Model {
 		tokenLimit:    4096,
 		categoryMetadata: make(map[string]CategoryInfo),
 		config:        config,
+		projectDir:    workingDir(),
 		mouseEnabled:  false, // Mouse disabled by default for text selection
 		responseHandlers: NewResponseHandlerRegistry(),
+		requests:         newRequestTracker(),
+		telemetry:        newTelemetry(config),
+		metrics:          metrics.New(),
 	}
 	
-	// Initialize component sizes with defaults
-	model.updateComponentSizes()
+	// Apply input settings from config
+	model.applyChatSettings()
+	model.applyCategoryColors()
+	model.loadResponseFilters()
+	if config.DefaultTemperature != nil {
+		model.temperature = *config.DefaultTemperature
+	}
+	
+	// Initialize component sizes with defaults and the configured panes
+	model.applyLayout()
+	model.warnIgnoredProjectKeys()
 	
 	return model
 }
@@ -214,9 +340,31 @@ func (m Model) Init() tea.Cmd {
 		func() tea.Msg {
 			return InitiateConnectionMsg{} // Connect to Phoenix on startup
 		},
+		WatchFile(),
+		m.watchProviderHealth(),
+		m.telemetryStartup(),
+		m.watchIdle(),
 	)
 }
 
+// applyChatSettings applies config-driven settings to the chat component
+func (m *Model) applyChatSettings() {
+	m.chat.SetLintEnabled(m.config.TUI.PromptLint)
+	m.chat.SetBlockedCommands(m.config.TUI.BlockedCommands)
+	m.chat.SetCollapseLines(m.config.TUI.CollapseLines)
+	m.chat.SetTranscriptBudget(m.config.TUI.TranscriptBudgetKB)
+	m.applyCommandLimits()
+	m.applyCacheTTLs()
+	if m.config.TUI.Spellcheck {
+		// A missing dictionary just leaves spellcheck off
+		checker, _ := NewSpellChecker(m.config.TUI.SpellcheckDictionary)
+		m.chat.SetSpellChecker(checker)
+	} else {
+		m.chat.SetSpellChecker(nil)
+	}
+	m.applyDisplaySettings()
+}
+
 // SetDimensions updates the model dimensions
 func (m *Model) SetDimensions(width, height int) {
 	m.width = width
@@ -234,6 +382,7 @@ func (m *Model) updateComponentSizes() {
 	if m.width == 0 || m.height == 0 {
 		return
 	}
+	m.lockScreen.SetSize(m.width, m.height)
 	
 	// Layout calculation for chat-focused interface
 	statusBarHeight := 1
@@ -254,6 +403,16 @@ func (m *Model) updateComponentSizes() {
 		chatWidth -= editorWidth + 2 // 2 for borders
 		m.editor.SetWidth(editorWidth)
 		m.editor.SetHeight(contentHeight)
+		// The ask panel takes the lower half of the editor pane
+		if m.askPanel.IsVisible() {
+			m.askPanel.SetSize(editorWidth, contentHeight/2)
+			m.editor.SetHeight(contentHeight - contentHeight/2 - 1)
+		}
+	}
+	
+	if m.showOutline {
+		chatWidth -= outlineWidth + 2 // 2 for borders
+		m.outline.SetSize(outlineWidth, contentHeight)
 	}
 	
 	// Update chat header size
@@ -277,6 +436,23 @@ func (m *Model) updateComponentSizes() {
 	// Update output viewport size
 	m.output.Width = 40
 	m.output.Height = contentHeight
+	
+	// Compose modal covers the full screen
+	m.composeModal.SetSize(m.width, m.height)
+	m.modal.SetSize(m.width, m.height)
+	m.searchPane.SetSize(m.width, m.height)
+	m.applyPreview.SetSize(m.width, m.height)
+	m.changesReview.SetSize(m.width, m.height)
+	m.permissionPrompt.SetSize(m.width, m.height)
+	m.reactView.SetSize(m.width, m.height)
+	m.deadLetters.SetSize(m.width, m.height)
+	m.modelSwitcher.SetSize(m.width, m.height)
+	m.emojiPicker.SetSize(m.width, m.height)
+	m.statusLegend.SetSize(m.width, m.height)
+	m.statusSubscriptions.SetSize(m.width, m.height)
+	m.apiKeyList.SetSize(m.width, m.height)
+	m.adminPane.SetSize(m.width, m.height)
+	m.problemsPane.SetSize(m.width, m.height)
 }
 
 // SetPhoenixConfig updates the Phoenix connection configuration
@@ -286,6 +462,36 @@ func (m *Model) SetPhoenixConfig(url, authURL, apiKey string) {
 	m.apiKey = apiKey
 }
 
+// SetNetworkOptions sets proxy and TLS options from flags, which take
+// precedence over the network section of config without being saved to it
+func (m *Model) SetNetworkOptions(options phoenix.NetworkOptions) {
+	m.networkFlags = options
+}
+
+// SetNetworkProfile picks one of the network_profiles in config instead of
+// network_profile
+func (m *Model) SetNetworkProfile(name string) error {
+	if _, ok := m.config.NetworkProfiles[name]; !ok {
+		return fmt.Errorf("no network profile %q in config", name)
+	}
+	m.networkProfile = name
+	return nil
+}
+
+// networkOptions returns the options sockets dial with: the network section
+// of config, then the active profile's overrides, then flags
+func (m Model) networkOptions() phoenix.NetworkOptions {
+	options := m.config.Network
+	profile := m.config.NetworkProfile
+	if m.networkProfile != "" {
+		profile = m.networkProfile
+	}
+	if overrides, ok := m.config.NetworkProfiles[profile]; ok {
+		options = options.Merge(overrides)
+	}
+	return options.Merge(m.networkFlags)
+}
+
 // GetPhoenixClient returns the Phoenix client interface
 func (m *Model) GetPhoenixClient() interface{} {
 	return m.phoenixClient
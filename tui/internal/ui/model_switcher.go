@@ -0,0 +1,341 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// listModelsTimeout bounds how long the switcher waits for the server's list
+const listModelsTimeout = 10 * time.Second
+
+// ModelsListedMsg carries the models the server's providers offer
+type ModelsListedMsg struct {
+	Models []phoenix.AvailableModel
+	Err    error
+}
+
+// knownModels are offered when the server can't list its models
+var knownModels = []ModelRef{
+	{Provider: "openai", Model: "gpt-4"},
+	{Provider: "openai", Model: "gpt-4o"},
+	{Provider: "openai", Model: "gpt-3.5-turbo"},
+	{Provider: "anthropic", Model: "claude-3-opus"},
+	{Provider: "anthropic", Model: "claude-3-sonnet"},
+	{Provider: "anthropic", Model: "claude-3-haiku"},
+	{Provider: "ollama", Model: "llama2"},
+	{Provider: "ollama", Model: "mistral"},
+	{Provider: "ollama", Model: "codellama"},
+}
+
+// modelChoice is one row of the model switcher
+type modelChoice struct {
+	Provider string
+	Name     string
+	Context  int           // Context window in tokens
+	Cost     string        // "$" to "$$$$" relative to the cheapest listed, "free", or "?"
+	Latency  time.Duration // Average of recent responses, 0 when never used
+}
+
+// ModelSwitcher lists the available models with their context size,
+// relative cost, and recent latency, filtered by provider
+type ModelSwitcher struct {
+	models    []modelChoice
+	providers []string
+	provider  string // Shown provider, empty for all
+	current   ModelRef
+	note      string
+	cursor    int
+	loading   bool
+	visible   bool
+	width     int
+	height    int
+}
+
+// Show opens the switcher on the current conversation's model
+func (ms *ModelSwitcher) Show(current ModelRef) {
+	ms.current = current
+	ms.cursor = 0
+	ms.provider = ""
+	ms.loading = true
+	ms.note = ""
+	ms.visible = true
+}
+
+// SetModels fills the switcher, selecting the current model
+func (ms *ModelSwitcher) SetModels(models []modelChoice, note string) {
+	ms.models = models
+	ms.note = note
+	ms.loading = false
+	seen := make(map[string]bool)
+	ms.providers = nil
+	for _, model := range models {
+		if !seen[model.Provider] {
+			seen[model.Provider] = true
+			ms.providers = append(ms.providers, model.Provider)
+		}
+	}
+	sort.Strings(ms.providers)
+	for i, model := range ms.filtered() {
+		if model.Name == ms.current.Model {
+			ms.cursor = i
+		}
+	}
+}
+
+// Hide hides the switcher
+func (ms *ModelSwitcher) Hide() {
+	ms.visible = false
+}
+
+// IsVisible returns whether the switcher is visible
+func (ms ModelSwitcher) IsVisible() bool {
+	return ms.visible
+}
+
+// SetSize updates the switcher dimensions
+func (ms *ModelSwitcher) SetSize(width, height int) {
+	ms.width = width
+	ms.height = height
+}
+
+// filtered returns the models of the shown provider
+func (ms ModelSwitcher) filtered() []modelChoice {
+	if ms.provider == "" {
+		return ms.models
+	}
+	var models []modelChoice
+	for _, model := range ms.models {
+		if model.Provider == ms.provider {
+			models = append(models, model)
+		}
+	}
+	return models
+}
+
+// cycleProvider shows the next (or previous) provider, all of them
+// coming between the last and the first
+func (ms *ModelSwitcher) cycleProvider(step int) {
+	options := append([]string{""}, ms.providers...)
+	index := 0
+	for i, provider := range options {
+		if provider == ms.provider {
+			index = i
+		}
+	}
+	ms.provider = options[(index+step+len(options))%len(options)]
+	ms.cursor = 0
+}
+
+// Update handles switcher input
+func (ms ModelSwitcher) Update(msg tea.Msg) (ModelSwitcher, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || !ms.visible {
+		return ms, nil
+	}
+
+	models := ms.filtered()
+	switch keyMsg.String() {
+	case "esc", "alt+m":
+		ms.Hide()
+	case "up", "k":
+		ms.cursor = max(ms.cursor-1, 0)
+	case "down", "j":
+		ms.cursor = min(ms.cursor+1, max(len(models)-1, 0))
+	case "tab", "right", "l":
+		ms.cycleProvider(1)
+	case "shift+tab", "left", "h":
+		ms.cycleProvider(-1)
+	case "enter":
+		if ms.cursor >= len(models) {
+			break
+		}
+		choice := models[ms.cursor]
+		ms.Hide()
+		return ms, func() tea.Msg {
+			return ExecuteCommandMsg{Command: "set_model_with_provider", Args: map[string]string{
+				"model":    choice.Name,
+				"provider": choice.Provider,
+				"scope":    "conversation",
+			}}
+		}
+	}
+	return ms, nil
+}
+
+// View renders the model table in a box
+func (ms ModelSwitcher) View() string {
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("62"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	cursorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
+	activeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("46"))
+
+	tabs := []string{}
+	for _, provider := range append([]string{""}, ms.providers...) {
+		label := provider
+		if label == "" {
+			label = "all"
+		}
+		if provider == ms.provider {
+			tabs = append(tabs, cursorStyle.Render("["+label+"]"))
+		} else {
+			tabs = append(tabs, dimStyle.Render(label))
+		}
+	}
+
+	lines := []string{titleStyle.Render("Switch model"), strings.Join(tabs, " "), ""}
+	models := ms.filtered()
+	switch {
+	case ms.loading:
+		lines = append(lines, dimStyle.Render("Asking the server for its models..."))
+	case len(models) == 0:
+		lines = append(lines, dimStyle.Render("No models"))
+	default:
+		lines = append(lines, dimStyle.Render(fmt.Sprintf("  %-22s %-10s %8s %6s %8s", "Model", "Provider", "Context", "Cost", "Latency")))
+		room := max(ms.height-12, 3)
+		start := max(min(ms.cursor-room/2, len(models)-room), 0)
+		for i := start; i < min(start+room, len(models)); i++ {
+			model := models[i]
+			latency := "—"
+			if model.Latency > 0 {
+				latency = formatLatency(model.Latency)
+			}
+			row := fmt.Sprintf("%-22s %-10s %8s %6s %8s", truncateStage(model.Name, 22), truncateStage(model.Provider, 10), formatContextSize(model.Context), model.Cost, latency)
+			switch {
+			case i == ms.cursor:
+				row = cursorStyle.Render("▶ " + row)
+			case model.Name == ms.current.Model && strings.EqualFold(model.Provider, ms.current.Provider):
+				row = activeStyle.Render("● " + row)
+			default:
+				row = "  " + row
+			}
+			lines = append(lines, row)
+		}
+	}
+	if ms.note != "" {
+		lines = append(lines, "", dimStyle.Render(ms.note))
+	}
+	lines = append(lines, "", dimStyle.Render("↑/↓: Select | Tab: Provider | Enter: Use for this conversation | Esc: Close"))
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Padding(0, 1).
+		Render(strings.Join(lines, "\n"))
+}
+
+// formatContextSize formats a context window, e.g. "8K" or "200K"
+func formatContextSize(tokens int) string {
+	if tokens >= 1000 {
+		return fmt.Sprintf("%dK", tokens/1000)
+	}
+	return fmt.Sprint(tokens)
+}
+
+// openModelSwitcher shows the switcher and asks the server for its models,
+// falling back to the configured and well-known ones
+func (m *Model) openModelSwitcher() tea.Cmd {
+	m.modelSwitcher.SetSize(m.width, m.height)
+	m.modelSwitcher.Show(ModelRef{Provider: m.activeProvider(), Model: m.activeModel()})
+	client, ok := m.phoenixClient.(*phoenix.Client)
+	if !ok || !m.connected || !m.capabilities[phoenix.CapabilityModels] {
+		m.modelSwitcher.SetModels(m.modelChoices(m.fallbackModels()), "The server can't list its models - showing configured and well-known ones")
+		return nil
+	}
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), listModelsTimeout)
+		defer cancel()
+		models, err := client.ListModels(ctx)
+		return ModelsListedMsg{Models: models, Err: err}
+	}
+}
+
+// handleModelsListed fills the switcher with the server's models
+func (m *Model) handleModelsListed(msg ModelsListedMsg) {
+	if msg.Err != nil || len(msg.Models) == 0 {
+		note := "The server listed no models - showing configured and well-known ones"
+		if msg.Err != nil {
+			note = fmt.Sprintf("Couldn't list models (%v) - showing configured and well-known ones", msg.Err)
+		}
+		m.modelSwitcher.SetModels(m.modelChoices(m.fallbackModels()), note)
+		return
+	}
+	m.modelSwitcher.SetModels(m.modelChoices(msg.Models), "")
+}
+
+// fallbackModels returns the models in the config's providers followed by
+// the well-known ones
+func (m Model) fallbackModels() []phoenix.AvailableModel {
+	var models []phoenix.AvailableModel
+	seen := make(map[string]bool)
+	add := func(provider, name string) {
+		if key := provider + "/" + name; !seen[key] {
+			seen[key] = true
+			models = append(models, phoenix.AvailableModel{Provider: provider, Name: name})
+		}
+	}
+	providers := make([]string, 0, len(m.config.Providers))
+	for provider := range m.config.Providers {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+	for _, provider := range providers {
+		for _, name := range m.config.Providers[provider].Models {
+			add(provider, name)
+		}
+	}
+	for _, known := range knownModels {
+		add(known.Provider, known.Model)
+	}
+	return models
+}
+
+// modelChoices adds context size, relative cost, and recent latency to models
+func (m Model) modelChoices(models []phoenix.AvailableModel) []modelChoice {
+	// Costs are relative to the cheapest priced model listed
+	cheapest := 0.0
+	for _, model := range models {
+		if price, ok := m.config.PriceForModel(model.Name); ok && price.Input+price.Output > 0 {
+			if blended := price.Input + price.Output; cheapest == 0 || blended < cheapest {
+				cheapest = blended
+			}
+		}
+	}
+
+	choices := make([]modelChoice, 0, len(models))
+	for _, model := range models {
+		choice := modelChoice{Provider: model.Provider, Name: model.Name, Context: model.ContextWindow, Cost: "?"}
+		if choice.Context == 0 {
+			choice.Context = GetModelTokenLimit(model.Name)
+		}
+		if price, ok := m.config.PriceForModel(model.Name); ok && cheapest > 0 {
+			choice.Cost = relativeCost((price.Input + price.Output) / cheapest)
+		} else if strings.EqualFold(model.Provider, "ollama") || model.Provider == localOllamaProvider {
+			choice.Cost = "free"
+		}
+		choice.Latency = m.latency.Recent(model.Provider, model.Name)
+		choices = append(choices, choice)
+	}
+	return choices
+}
+
+// relativeCost turns a price ratio to the cheapest model into "$" to "$$$$",
+// each step four times pricier
+func relativeCost(ratio float64) string {
+	switch {
+	case ratio < 4:
+		return "$"
+	case ratio < 16:
+		return "$$"
+	case ratio < 64:
+		return "$$$"
+	default:
+		return "$$$$"
+	}
+}
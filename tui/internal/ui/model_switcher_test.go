@@ -0,0 +1,52 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+func TestModelSwitcherChoicesAndFilter(t *testing.T) {
+	model := Model{config: &Config{}, latency: NewLatencyTracker()}
+	model.latency.statsFor("openai", "gpt-4").Latencies = []time.Duration{10 * time.Second, time.Second, 3 * time.Second}
+
+	choices := model.modelChoices([]phoenix.AvailableModel{
+		{Provider: "anthropic", Name: "claude-3-haiku", ContextWindow: 200000},
+		{Provider: "OpenAI", Name: "gpt-4"},
+		{Provider: "ollama", Name: "llama3"},
+	})
+	want := []struct {
+		cost    string
+		context int
+		latency time.Duration
+	}{
+		{"$", 200000, 0},
+		{"$$$", 8192, 14 * time.Second / 3},
+		{"free", 4096, 0},
+	}
+	for i, w := range want {
+		if c := choices[i]; c.Cost != w.cost || c.Context != w.context || c.Latency != w.latency {
+			t.Errorf("Choice %s = %s, %d, %v; want %s, %d, %v", c.Name, c.Cost, c.Context, c.Latency, w.cost, w.context, w.latency)
+		}
+	}
+
+	var switcher ModelSwitcher
+	switcher.Show(ModelRef{Provider: "openai", Model: "gpt-4"})
+	switcher.SetModels(choices, "")
+	if switcher.cursor != 1 {
+		t.Errorf("Expected the current model selected, got row %d", switcher.cursor)
+	}
+
+	// Tab moves from all providers to the first one
+	switcher, _ = switcher.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if switcher.provider != "OpenAI" || len(switcher.filtered()) != 1 {
+		t.Fatalf("Expected the OpenAI filter, got %q with %d models", switcher.provider, len(switcher.filtered()))
+	}
+	switcher, cmd := switcher.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	msg, ok := cmd().(ExecuteCommandMsg)
+	if !ok || msg.Args["model"] != "gpt-4" || msg.Args["provider"] != "OpenAI" || switcher.IsVisible() {
+		t.Errorf("Expected Enter to switch to gpt-4 and close, got %+v", msg)
+	}
+}
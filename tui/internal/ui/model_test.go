@@ -2,6 +2,8 @@ package ui
 
 import (
 	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
 )
 
 func TestNewModel(t *testing.T) {
@@ -21,4 +23,29 @@ func TestNewModel(t *testing.T) {
 	if model.width == 0 || model.height == 0 {
 		t.Error("Expected default dimensions to be set")
 	}
-}
\ No newline at end of file
+}
+
+func TestFocusShortcutsKeepKeysInFocusedPane(t *testing.T) {
+	m := snapshotModel(t)
+	m = applyMsgs(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("2"), Alt: true})
+	if m.activePane != FileTreePane || !m.showFileTree {
+		t.Fatalf("Alt+2 should show and focus the file tree, got pane %v", m.activePane)
+	}
+	if m.chat.focused {
+		t.Error("Expected chat to lose focus")
+	}
+
+	m = applyMsgs(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	if got := m.chat.GetInputValue(); got != "" {
+		t.Errorf("Key leaked to the chat input: %q", got)
+	}
+
+	m = applyMsgs(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("1"), Alt: true})
+	if m.activePane != ChatPane || !m.chat.focused {
+		t.Fatalf("Alt+1 should focus the chat, got pane %v", m.activePane)
+	}
+	m = applyMsgs(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	if got := m.chat.GetInputValue(); got != "x" {
+		t.Errorf("Expected typed key in chat input, got %q", got)
+	}
+}
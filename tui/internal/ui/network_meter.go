@@ -0,0 +1,96 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// busiestTopicsShown is how many topics /stats lists by messages received
+const busiestTopicsShown = 5
+
+// networkMeter turns the sockets' byte counts into send and receive rates
+type networkMeter struct {
+	sent, received int64
+	sampledAt      time.Time
+	sendRate       float64 // Bytes per second over the last sample
+	receiveRate    float64
+}
+
+// sample updates the rates from the latest byte counts
+func (n *networkMeter) sample(sent, received int64, now time.Time) {
+	if !n.sampledAt.IsZero() {
+		if elapsed := now.Sub(n.sampledAt).Seconds(); elapsed > 0 {
+			n.sendRate = float64(sent-n.sent) / elapsed
+			n.receiveRate = float64(received-n.received) / elapsed
+		}
+	}
+	n.sent, n.received, n.sampledAt = sent, received, now
+}
+
+// traffic returns the phoenix client's counters, nil without a client
+func (m Model) traffic() *phoenix.Traffic {
+	if client, ok := m.phoenixClient.(*phoenix.Client); ok {
+		return client.Traffic()
+	}
+	return nil
+}
+
+// sampleTraffic updates the network meter
+func (m *Model) sampleTraffic(now time.Time) {
+	if traffic := m.traffic(); traffic != nil {
+		m.network.sample(traffic.Sent(), traffic.Received(), now)
+	}
+}
+
+// renderNetworkMeter renders the send and receive rates, e.g. "↑120 B/s
+// ↓3.4 KB/s", once anything has gone over the sockets
+func (m Model) renderNetworkMeter() string {
+	if !m.connected || m.network.sampledAt.IsZero() {
+		return ""
+	}
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color("244")).
+		Render(fmt.Sprintf("↑%s/s ↓%s/s", formatBytes(int64(m.network.sendRate)), formatBytes(int64(m.network.receiveRate))))
+}
+
+// formatTrafficStats describes the bytes sent and received since start and
+// the topics receiving the most messages
+func (m Model) formatTrafficStats() string {
+	traffic := m.traffic()
+	if traffic == nil {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Network: %s sent, %s received", formatBytes(traffic.Sent()), formatBytes(traffic.Received()))
+	topics := traffic.Topics()
+	if len(topics) > busiestTopicsShown {
+		topics = topics[:busiestTopicsShown]
+	}
+	if len(topics) > 0 {
+		b.WriteString("\nBusiest topics (messages received):")
+		for _, topic := range topics {
+			fmt.Fprintf(&b, "\n  %-28s %d", topic.Topic, topic.Messages)
+		}
+	}
+	return b.String()
+}
+
+// formatBytes formats a byte count, e.g. "512 B" or "1.2 MB"
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	value, suffix := float64(n)/unit, "KB"
+	for _, next := range []string{"MB", "GB"} {
+		if value < unit {
+			break
+		}
+		value, suffix = value/unit, next
+	}
+	return fmt.Sprintf("%.1f %s", value, suffix)
+}
@@ -0,0 +1,36 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+func TestNetworkMeterRates(t *testing.T) {
+	var meter networkMeter
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	meter.sample(100, 1000, start)
+	meter.sample(300, 7144, start.Add(2*time.Second))
+	if meter.sendRate != 100 || meter.receiveRate != 3072 {
+		t.Errorf("Expected 100 B/s up and 3 KB/s down, got %v and %v", meter.sendRate, meter.receiveRate)
+	}
+	if got := formatBytes(int64(meter.receiveRate)); got != "3.0 KB" {
+		t.Errorf("Expected 3.0 KB, got %q", got)
+	}
+	if got := formatBytes(5 << 30); got != "5.0 GB" {
+		t.Errorf("Expected 5.0 GB, got %q", got)
+	}
+
+	testutil.IsolateHome(t)
+	model := NewModel()
+	model.connected = true
+	model.network = meter
+	if bar := model.renderMiniStatusBar(200); !strings.Contains(bar, "↑100 B/s ↓3.0 KB/s") {
+		t.Errorf("Expected the meter in the status bar, got %q", bar)
+	}
+	if stats := model.formatTrafficStats(); !strings.HasPrefix(stats, "Network: 0 B sent, 0 B received") {
+		t.Errorf("Expected totals in /stats, got %q", stats)
+	}
+}
@@ -0,0 +1,76 @@
+package ui
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// offlineProbeInterval is how often offline mode checks whether the server is back
+const offlineProbeInterval = 15 * time.Second
+
+// OfflineProbeMsg reports whether the server accepted a TCP connection
+type OfflineProbeMsg struct {
+	Reachable bool
+}
+
+// enterOffline switches to offline mode and starts probing for the server
+func (m *Model) enterOffline() tea.Cmd {
+	if m.offline {
+		return nil
+	}
+	m.offline = true
+	m.statusBar = "Offline - server unreachable"
+	// Offline always means local files, which are listed right away
+	m.applyFileSource()
+	m.chat.AddMessage(SystemMessage, "Server unreachable - running in offline mode.\n\nStill available:\n• Local commands (/help, /config, /stats, ...)\n• File tree (Ctrl+F) and editor (Ctrl+E) on the local filesystem\n• Saved conversations (/saved)\n• Local models via /provider ollama-local\n\nThe TUI reconnects automatically when the server comes back.", "system")
+	return probeServer(m.authSocketURL, offlineProbeInterval)
+}
+
+// leaveOffline returns to online mode after the socket connects
+func (m *Model) leaveOffline() {
+	if !m.offline {
+		return
+	}
+	m.offline = false
+	m.chat.AddMessage(SystemMessage, "Server reachable again - back online", "system")
+}
+
+// probeServer checks after a delay whether the server's port accepts connections
+func probeServer(socketURL string, delay time.Duration) tea.Cmd {
+	return tea.Tick(delay, func(time.Time) tea.Msg {
+		endpoint, err := url.Parse(socketURL)
+		if err != nil {
+			return OfflineProbeMsg{Reachable: false}
+		}
+		host := endpoint.Host
+		if endpoint.Port() == "" {
+			port := "80"
+			if endpoint.Scheme == "wss" || endpoint.Scheme == "https" {
+				port = "443"
+			}
+			host = net.JoinHostPort(endpoint.Hostname(), port)
+		}
+
+		conn, err := net.DialTimeout("tcp", host, 2*time.Second)
+		if err != nil {
+			return OfflineProbeMsg{Reachable: false}
+		}
+		conn.Close()
+		return OfflineProbeMsg{Reachable: true}
+	})
+}
+
+// renderOfflineBanner renders the persistent offline indicator
+func (m Model) renderOfflineBanner() string {
+	return lipgloss.NewStyle().
+		Background(lipgloss.Color("196")).
+		Foreground(lipgloss.Color("230")).
+		Bold(true).
+		Padding(0, 1).
+		Render(fmt.Sprintf("OFFLINE - local mode (retrying every %s)", offlineProbeInterval))
+}
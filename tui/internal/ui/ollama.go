@@ -0,0 +1,59 @@
+package ui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// localOllamaProvider selects the direct Ollama backend instead of the server
+const localOllamaProvider = "ollama-local"
+
+// isLocalProvider returns true when requests bypass the server
+func (m Model) isLocalProvider() bool {
+	return m.activeProvider() == localOllamaProvider
+}
+
+// sendToOllama sends the conversation to the local Ollama server
+func (m Model) sendToOllama() (Model, tea.Cmd) {
+	if m.ollamaClient == nil {
+		m.ollamaClient = phoenix.NewOllamaClient(m.config.OllamaURL)
+	}
+	// The program is only available once the TUI is running
+	m.ollamaClient.SetProgram(m.ProgramHolder())
+
+	m.latency.Begin(m.activeProvider(), m.activeModel())
+	m.statusBar = fmt.Sprintf("Sending to local Ollama (%s)...", m.activeModel())
+	// Start before the return, which copies m first
+	cmd := m.activity.Start()
+	return m, tea.Batch(
+		cmd,
+		m.ollamaClient.Chat(m.activeModel(), m.ollamaPrompt(), m.activeTemperature()),
+	)
+}
+
+// ollamaPrompt returns the history to send, with the message just sent
+// carrying the conversation's prefix and suffix. Messages spilled to disk
+// are left out; they are past any local model's context
+func (m Model) ollamaPrompt() []phoenix.OllamaMessage {
+	history := ollamaHistory(m.chat.messages)
+	if last := len(history) - 1; last >= 0 && history[last].Role == "user" && m.lastPrompt != "" {
+		history[last].Content = m.lastPrompt
+	}
+	return history
+}
+
+// ollamaHistory converts the chat history to Ollama messages
+func ollamaHistory(messages []ChatMessage) []phoenix.OllamaMessage {
+	var history []phoenix.OllamaMessage
+	for _, msg := range messages {
+		switch msg.Type {
+		case UserMessage:
+			history = append(history, phoenix.OllamaMessage{Role: "user", Content: msg.Content})
+		case AssistantMessage:
+			history = append(history, phoenix.OllamaMessage{Role: "assistant", Content: msg.Content})
+		}
+	}
+	return history
+}
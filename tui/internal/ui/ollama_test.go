@@ -0,0 +1,38 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/rubber_duck/tui/internal/phoenix"
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+func TestSendToOllama_StreamsIntoTheChat(t *testing.T) {
+	testutil.IsolateHome(t)
+	model := NewModel()
+	model.config.OllamaURL = "http://127.0.0.1:1"
+	model.chat.AddMessage(UserMessage, "hi", "user")
+
+	sent, cmd := model.sendToOllama()
+	if cmd == nil || !sent.activity.IsActive() {
+		t.Fatal("Expected the activity indicator running while Ollama answers")
+	}
+	*model = sent
+
+	for _, msg := range []any{
+		phoenix.StreamStartMsg{ID: "ollama-1"},
+		phoenix.StreamDataMsg{ID: "ollama-1", Data: "Hel"},
+		phoenix.StreamDataMsg{ID: "ollama-1", Data: "lo"},
+		phoenix.StreamEndMsg{ID: "ollama-1"},
+	} {
+		updated, _ := model.Update(msg)
+		*model = updated.(Model)
+	}
+	messages := model.chat.GetMessages()
+	if last := messages[len(messages)-1]; last.Type != AssistantMessage || last.Content != "Hello" {
+		t.Fatalf("Expected the streamed answer in the chat, got %+v", last)
+	}
+	if model.activity.IsActive() {
+		t.Error("Expected the activity indicator stopped once the stream ends")
+	}
+}
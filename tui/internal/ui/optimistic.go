@@ -0,0 +1,147 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// FileSaveResultMsg reports how a save to the server ended. The editor
+// showed the file as saved when the save started
+type FileSaveResultMsg struct {
+	Path         string
+	Content      string
+	Previous     string // Buffer content as last saved before this save
+	HunksApplied bool   // The buffer held applied hunks, recorded once saved
+	Seq          int
+	Err          error
+}
+
+// ProviderSwitchResultMsg carries the server's answer on a provider switched
+// to before asking it
+type ProviderSwitchResultMsg struct {
+	Provider string
+	Previous string
+	Status   phoenix.ProviderStatus
+	Known    bool // The server lists models for the provider, or said nothing
+	Err      error
+}
+
+// saveInBackground marks the buffer saved right away and writes it to the
+// server in a command, so editing goes on while the save is under way
+func (m *Model) saveInBackground(fs FileSystem, content, formatted string) tea.Cmd {
+	m.saveSeq++
+	result := FileSaveResultMsg{
+		Path:         m.currentFile,
+		Content:      content,
+		Previous:     m.editorOriginal,
+		HunksApplied: m.hunksApplied,
+		Seq:          m.saveSeq,
+	}
+	m.hunksApplied = false
+	m.editorOriginal = content
+	m.currentFileStamp = statFile(m.projectFile(m.currentFile))
+	m.fileChangedOnDisk = false
+	m.statusBar = fmt.Sprintf("Saved %s%s", m.currentFile, formatted)
+	return func() tea.Msg {
+		result.Err = fs.WriteFile(result.Path, []byte(result.Content))
+		return result
+	}
+}
+
+// handleFileSaveResult reconciles a save with the server's answer. A
+// failed save marks the buffer modified again, unless it was saved or
+// reloaded since
+func (m *Model) handleFileSaveResult(msg FileSaveResultMsg) {
+	current := m.currentFile == msg.Path && msg.Seq == m.saveSeq
+	if msg.Err == nil {
+		if msg.HunksApplied {
+			m.recordAppliedChange(msg.Path, msg.Previous, msg.Content, true)
+		}
+		return
+	}
+
+	m.reportError(msg.Err, "files")
+	text := fmt.Sprintf("Cannot save %s: %v", msg.Path, msg.Err)
+	if current && m.editorOriginal == msg.Content {
+		m.editorOriginal = msg.Previous
+		m.hunksApplied = msg.HunksApplied
+		text += " - your changes are still in the editor"
+	}
+	m.statusMessages.AddMessage(StatusCategoryError, text, nil)
+	m.statusBar = text
+}
+
+// reconcileProvider asks the server about a provider switched to, so an
+// unknown or unavailable one can be switched back from
+func (m Model) reconcileProvider(provider, previous string) tea.Cmd {
+	client, ok := m.phoenixClient.(*phoenix.Client)
+	if !ok || !m.connected {
+		return nil
+	}
+	withStatus := m.capabilities[phoenix.CapabilityProviderStatus]
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), providerStatusTimeout)
+		defer cancel()
+		result := ProviderSwitchResultMsg{Provider: provider, Previous: previous, Known: true}
+		if withStatus {
+			result.Status, result.Err = client.GetProviderStatus(ctx, provider)
+			return result
+		}
+		models, err := client.ListModels(ctx)
+		if err == nil && len(models) > 0 {
+			result.Known = false
+			for _, model := range models {
+				if strings.EqualFold(model.Provider, provider) {
+					result.Known = true
+					break
+				}
+			}
+		}
+		return result
+	}
+}
+
+// handleProviderSwitchResult keeps a provider the server accepts and
+// switches back from one it rejects or reports unavailable
+func (m *Model) handleProviderSwitchResult(msg ProviderSwitchResultMsg) {
+	// Switched again in the meantime; that switch has its own answer
+	if !strings.EqualFold(m.currentProvider, msg.Provider) {
+		return
+	}
+
+	var coded *phoenix.Error
+	reason := ""
+	switch {
+	case msg.Err != nil && errors.As(msg.Err, &coded) && coded.Code == phoenix.ErrRequestFailed:
+		reason = msg.Err.Error()
+	case msg.Err != nil:
+		// The check itself failed; that says nothing about the provider
+		return
+	case !msg.Known:
+		reason = "the server offers no models from it"
+	case msg.Status.Status == phoenix.ProviderUnavailable:
+		reason = "it is unavailable"
+		if msg.Status.Reason != "" {
+			reason += " (" + msg.Status.Reason + ")"
+		}
+	default:
+		m.handleProviderStatus(ProviderStatusMsg{Status: msg.Status})
+		return
+	}
+
+	m.currentProvider = msg.Previous
+	m.updateHeaderState()
+	back := msg.Previous
+	if back == "" {
+		back = "the server default"
+	}
+	text := fmt.Sprintf("Provider %s rejected: %s - switched back to %s", msg.Provider, reason, back)
+	m.statusMessages.AddMessage(StatusCategoryError, text, nil)
+	m.statusBar = text
+	m.chat.AddMessage(SystemMessage, text, "system")
+}
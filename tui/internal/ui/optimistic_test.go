@@ -0,0 +1,70 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rubber_duck/tui/internal/phoenix"
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+// failingServerFS is a server file system whose writes fail
+type failingServerFS struct {
+	LocalFS
+}
+
+func (fs *failingServerFS) Name() string { return FileSourceServer }
+
+func (fs *failingServerFS) WriteFile(path string, data []byte) error {
+	return errors.New("write_file timed out")
+}
+
+func TestSaveFile_RollsBackFailedServerSave(t *testing.T) {
+	testutil.IsolateHome(t)
+	model := NewModel()
+	model.fileTree.SetFileSystem(&failingServerFS{LocalFS{root: "."}})
+	model.currentFile = "main.go"
+	model.editorOriginal = "old"
+	model.editor.SetValue("new")
+
+	cmd := model.saveFile()
+	if cmd == nil {
+		t.Fatal("Expected a server save to run in the background")
+	}
+	if model.editorOriginal != "new" {
+		t.Fatal("Expected the buffer to show as saved right away")
+	}
+
+	model.handleFileSaveResult(cmd().(FileSaveResultMsg))
+	if model.editorOriginal != "old" {
+		t.Fatalf("Expected the failed save to mark the buffer modified again, got %q", model.editorOriginal)
+	}
+	if !strings.Contains(model.statusBar, "still in the editor") {
+		t.Fatalf("Expected the failure to be shown, got %q", model.statusBar)
+	}
+}
+
+func TestProviderSwitch_RollsBackRejectedProvider(t *testing.T) {
+	testutil.IsolateHome(t)
+	model := NewModel()
+	model.currentProvider = "nope"
+
+	model.handleProviderSwitchResult(ProviderSwitchResultMsg{Provider: "nope", Previous: "openai", Known: true,
+		Err: phoenix.Errorf(phoenix.ErrRequestFailed, "get_provider_status failed: unknown provider")})
+	if model.currentProvider != "openai" {
+		t.Fatalf("Expected a rejected provider to be switched back, got %q", model.currentProvider)
+	}
+
+	model.currentProvider = "anthropic"
+	model.handleProviderSwitchResult(ProviderSwitchResultMsg{Provider: "openai", Previous: "", Known: false})
+	if model.currentProvider != "anthropic" {
+		t.Fatal("Expected the answer for an earlier switch to be ignored")
+	}
+
+	model.handleProviderSwitchResult(ProviderSwitchResultMsg{Provider: "anthropic", Previous: "openai",
+		Status: phoenix.ProviderStatus{Provider: "anthropic", Status: phoenix.ProviderHealthy}, Known: true})
+	if model.currentProvider != "anthropic" {
+		t.Fatal("Expected an accepted provider to be kept")
+	}
+}
@@ -0,0 +1,240 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// outlineWidth is the fixed width of the outline pane
+const outlineWidth = 30
+
+// Symbol is a function, type, or module found in a buffer
+type Symbol struct {
+	Name  string
+	Kind  string
+	Line  int // 1-based
+	Depth int
+}
+
+// SymbolSelectedMsg asks the editor to jump to a symbol
+type SymbolSelectedMsg struct {
+	Line int
+}
+
+// symbolPattern matches one kind of declaration; the "name" group is the symbol name
+type symbolPattern struct {
+	kind string
+	re   *regexp.Regexp
+}
+
+// symbolPatterns holds the lightweight parsers for each file extension
+var symbolPatterns = map[string][]symbolPattern{
+	".go": {
+		{"method", regexp.MustCompile(`^func\s+\([^)]*\)\s*(?P<name>\w+)`)},
+		{"func", regexp.MustCompile(`^func\s+(?P<name>\w+)`)},
+		{"type", regexp.MustCompile(`^type\s+(?P<name>\w+)`)},
+	},
+	".ex": elixirPatterns,
+	".exs": elixirPatterns,
+	".py": {
+		{"class", regexp.MustCompile(`^\s*class\s+(?P<name>\w+)`)},
+		{"func", regexp.MustCompile(`^\s*(async\s+)?def\s+(?P<name>\w+)`)},
+	},
+	".js": jsPatterns,
+	".jsx": jsPatterns,
+	".ts": jsPatterns,
+	".tsx": jsPatterns,
+	".rs": {
+		{"func", regexp.MustCompile(`^\s*(pub(\([\w:]+\))?\s+)?(const\s+)?(async\s+)?(unsafe\s+)?fn\s+(?P<name>\w+)`)},
+		{"type", regexp.MustCompile(`^\s*(pub(\([\w:]+\))?\s+)?(struct|enum|trait|type)\s+(?P<name>\w+)`)},
+		{"impl", regexp.MustCompile(`^\s*impl(<[^>]*>)?\s+(?P<name>[\w:<>, ]+?)\s*(\{|where|$)`)},
+		{"module", regexp.MustCompile(`^\s*(pub(\([\w:]+\))?\s+)?mod\s+(?P<name>\w+)`)},
+	},
+}
+
+var elixirPatterns = []symbolPattern{
+	{"module", regexp.MustCompile(`^\s*(defmodule|defprotocol|defimpl)\s+(?P<name>[\w.]+)`)},
+	{"func", regexp.MustCompile(`^\s*(def|defp|defmacro|defmacrop|defguard|defguardp)\s+(?P<name>[\w?!]+)`)},
+}
+
+var jsPatterns = []symbolPattern{
+	{"class", regexp.MustCompile(`^\s*(export\s+)?(default\s+)?(abstract\s+)?class\s+(?P<name>\w+)`)},
+	{"type", regexp.MustCompile(`^\s*(export\s+)?(interface|type|enum)\s+(?P<name>\w+)`)},
+	{"func", regexp.MustCompile(`^\s*(export\s+)?(default\s+)?(async\s+)?function\s*\*?\s*(?P<name>\w+)`)},
+	{"func", regexp.MustCompile(`^\s*(export\s+)?(const|let|var)\s+(?P<name>\w+)\s*=\s*(async\s+)?(\([^)]*\)|\w+)\s*=>`)},
+	{"method", regexp.MustCompile(`^\s+(static\s+)?(async\s+)?(?P<name>\w+)\s*\([^)]*\)\s*\{`)},
+}
+
+// jsKeywords are control statements the method pattern must not match
+var jsKeywords = map[string]bool{"if": true, "for": true, "while": true, "switch": true, "catch": true, "return": true}
+
+// parseOutline lists the symbols in a buffer, nesting them by indentation
+func parseOutline(path, content string) []Symbol {
+	patterns, ok := symbolPatterns[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		return nil
+	}
+
+	var symbols []Symbol
+	var indents []int // Indentation of the enclosing symbols
+	for i, line := range strings.Split(content, "\n") {
+		for _, pattern := range patterns {
+			match := pattern.re.FindStringSubmatch(line)
+			if match == nil {
+				continue
+			}
+			name := strings.TrimSpace(match[pattern.re.SubexpIndex("name")])
+			if pattern.kind == "method" && jsKeywords[name] {
+				break
+			}
+
+			indent := len(line) - len(strings.TrimLeft(line, " \t"))
+			for len(indents) > 0 && indents[len(indents)-1] >= indent {
+				indents = indents[:len(indents)-1]
+			}
+			symbols = append(symbols, Symbol{Name: name, Kind: pattern.kind, Line: i + 1, Depth: len(indents)})
+			indents = append(indents, indent)
+			break
+		}
+	}
+	return symbols
+}
+
+// symbolIcons marks each symbol kind in the outline
+var symbolIcons = map[string]string{
+	"module": "◆",
+	"class":  "◆",
+	"impl":   "◆",
+	"type":   "▪",
+	"func":   "ƒ",
+	"method": "·",
+}
+
+// Outline lists the symbols of the file open in the editor
+type Outline struct {
+	path     string
+	content  string
+	symbols  []Symbol
+	selected int
+	width    int
+	height   int
+	focused  bool
+}
+
+// NewOutline creates a new outline pane
+func NewOutline() Outline {
+	return Outline{width: outlineWidth}
+}
+
+// SetContent re-parses the buffer when it has changed
+func (o *Outline) SetContent(path, content string) {
+	if path == o.path && content == o.content {
+		return
+	}
+	if path != o.path {
+		o.selected = 0
+	}
+	o.path = path
+	o.content = content
+	o.symbols = parseOutline(path, content)
+	if o.selected >= len(o.symbols) {
+		o.selected = max(0, len(o.symbols)-1)
+	}
+}
+
+// SetSize updates the pane dimensions
+func (o *Outline) SetSize(width, height int) {
+	o.width = width
+	o.height = height
+}
+
+// SetFocused marks whether the outline has focus, which highlights its title
+func (o *Outline) SetFocused(focused bool) {
+	o.focused = focused
+}
+
+// Symbols returns the parsed symbols
+func (o Outline) Symbols() []Symbol {
+	return o.symbols
+}
+
+// Update handles outline navigation
+func (o Outline) Update(msg tea.Msg) (Outline, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || len(o.symbols) == 0 {
+		return o, nil
+	}
+
+	switch keyMsg.String() {
+	case "up", "k":
+		if o.selected > 0 {
+			o.selected--
+		}
+	case "down", "j":
+		if o.selected < len(o.symbols)-1 {
+			o.selected++
+		}
+	case "home", "g":
+		o.selected = 0
+	case "end", "G":
+		o.selected = len(o.symbols) - 1
+	case "enter":
+		line := o.symbols[o.selected].Line
+		return o, func() tea.Msg {
+			return SymbolSelectedMsg{Line: line}
+		}
+	}
+	return o, nil
+}
+
+// View renders the outline
+func (o Outline) View() string {
+	titleStyle := paneTitleStyle(o.focused)
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	selectedStyle := lipgloss.NewStyle().
+		Background(lipgloss.Color("62")).
+		Foreground(lipgloss.Color("230"))
+
+	lines := []string{titleStyle.Render("Outline")}
+	switch {
+	case o.path == "":
+		lines = append(lines, dimStyle.Render("(no file open)"))
+		return strings.Join(lines, "\n")
+	case len(o.symbols) == 0:
+		if _, ok := symbolPatterns[strings.ToLower(filepath.Ext(o.path))]; !ok {
+			lines = append(lines, dimStyle.Render(fmt.Sprintf("(no parser for %s)", filepath.Base(o.path))))
+		} else {
+			lines = append(lines, dimStyle.Render("(no symbols)"))
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	// Keep the selection within the visible window
+	visible := o.height - len(lines) - 2
+	if visible < 1 {
+		visible = len(o.symbols)
+	}
+	start := 0
+	if o.selected >= visible {
+		start = o.selected - visible + 1
+	}
+	end := min(len(o.symbols), start+visible)
+
+	for i := start; i < end; i++ {
+		symbol := o.symbols[i]
+		line := strings.Repeat("  ", symbol.Depth) + symbolIcons[symbol.Kind] + " " + symbol.Name
+		if o.width > 4 && lipgloss.Width(line) > o.width-2 {
+			line = truncateStage(line, o.width-2)
+		}
+		if i == o.selected {
+			line = selectedStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,62 @@
+package ui
+
+import "testing"
+
+func TestParseOutline(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		want    []Symbol
+	}{
+		{
+			name:    "go",
+			path:    "main.go",
+			content: "package main\n\ntype Model struct{}\n\nfunc (m Model) View() string {\n}\n\nfunc main() {\n}\n",
+			want: []Symbol{
+				{Name: "Model", Kind: "type", Line: 3},
+				{Name: "View", Kind: "method", Line: 5},
+				{Name: "main", Kind: "func", Line: 8},
+			},
+		},
+		{
+			name:    "elixir nests by indentation",
+			path:    "lib/app.ex",
+			content: "defmodule App do\n  def start(_, _), do: :ok\n\n  defp valid?(x), do: x\nend\n",
+			want: []Symbol{
+				{Name: "App", Kind: "module", Line: 1},
+				{Name: "start", Kind: "func", Line: 2, Depth: 1},
+				{Name: "valid?", Kind: "func", Line: 4, Depth: 1},
+			},
+		},
+		{
+			name:    "javascript skips control statements",
+			path:    "app.js",
+			content: "export class App {\n  render() {\n    if (x) {\n    }\n  }\n}\nconst add = (a, b) => a + b\n",
+			want: []Symbol{
+				{Name: "App", Kind: "class", Line: 1},
+				{Name: "render", Kind: "method", Line: 2, Depth: 1},
+				{Name: "add", Kind: "func", Line: 7},
+			},
+		},
+		{
+			name:    "unknown language",
+			path:    "notes.txt",
+			content: "def nothing\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseOutline(tt.path, tt.content)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected %d symbols, got %#v", len(tt.want), got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Symbol %d: expected %#v, got %#v", i, tt.want[i], got[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package ui
+
+import (
+	"encoding/json"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// formatJSON asks for a command's output as JSON
+const formatJSON = "json"
+
+// commandResult is a command's output as JSON, for --format json and
+// headless mode
+type commandResult struct {
+	Command   string `json:"command"`
+	Status    string `json:"status"`
+	Output    string `json:"output,omitempty"`
+	StatusBar string `json:"status_bar,omitempty"`
+}
+
+// splitFormatFlag removes --format <fmt>, --format=<fmt>, or --json from a
+// slash command, returning the rest and the format asked for
+func splitFormatFlag(command string) (string, string) {
+	fields := strings.Fields(command)
+	kept := fields[:0:0]
+	format := ""
+	for i := 0; i < len(fields); i++ {
+		field := strings.ToLower(fields[i])
+		switch {
+		case field == "--json":
+			format = formatJSON
+		case field == "--format" && i+1 < len(fields):
+			format = strings.ToLower(fields[i+1])
+			i++
+		case strings.HasPrefix(field, "--format="):
+			format = strings.TrimPrefix(field, "--format=")
+		default:
+			kept = append(kept, fields[i])
+		}
+	}
+	if format == "" {
+		return command, ""
+	}
+	return strings.Join(kept, " "), format
+}
+
+// withFormat asks the command a slash command runs for its output in a
+// format. Slash commands that run no command ignore it
+func withFormat(cmd tea.Cmd, format string) tea.Cmd {
+	if cmd == nil || format == "" {
+		return cmd
+	}
+	return func() tea.Msg {
+		msg := cmd()
+		if command, ok := msg.(ExecuteCommandMsg); ok {
+			command.Format = format
+			return command
+		}
+		return msg
+	}
+}
+
+// collectOutput removes the messages a command added to the chat since it
+// held count of them, returning them as its result
+func (m *Model) collectOutput(command, status string, count int) commandResult {
+	var output []string
+	for _, message := range m.chat.takeMessagesSince(count) {
+		output = append(output, message.Content)
+	}
+	return commandResult{
+		Command:   command,
+		Status:    status,
+		Output:    strings.Join(output, "\n\n"),
+		StatusBar: m.statusBar,
+	}
+}
+
+// showJSONResult replaces a command's output in the chat with its JSON form
+func (m *Model) showJSONResult(result commandResult) {
+	data, _ := json.MarshalIndent(result, "", "  ")
+	m.chat.AddMessage(SystemMessage, "```json\n"+string(data)+"\n```", "system")
+}
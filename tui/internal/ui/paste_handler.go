@@ -0,0 +1,143 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// PasteCheckedMsg carries a paste once its path was looked up on disk
+type PasteCheckedMsg struct {
+	Text string
+	File string // The dragged-in file the paste names, "" when it is text
+}
+
+// codeLinePatterns are line-level hints that pasted text is source code
+var codeLinePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^\s*(func|def|defmodule|defp|class|import|package|from|use|alias|require|const|let|var|fn|pub|struct|interface|type|return|if|for|while|switch|case|end)\b`),
+	regexp.MustCompile(`[{};]\s*$`),
+	regexp.MustCompile(`^\s*(//|#|--|/\*|\*)`),
+	regexp.MustCompile(`(=>|->|:=|\|>|==|!=)`),
+	regexp.MustCompile(`^\s*<[a-zA-Z/][^>]*>`),
+}
+
+// fenceLanguageHints maps distinctive snippets to code fence languages
+var fenceLanguageHints = []struct {
+	pattern  *regexp.Regexp
+	language string
+}{
+	{regexp.MustCompile(`(?m)^\s*(defmodule|defp?)\s|\|>|\bdo\s*$`), "elixir"},
+	{regexp.MustCompile(`(?m)^\s*package\s+\w+|\bfunc\s+\w*\(|:=`), "go"},
+	{regexp.MustCompile(`(?m)^\s*(def|class)\s+\w+.*:\s*$|^\s*import\s+\w+$`), "python"},
+	{regexp.MustCompile(`(?m)\b(const|let)\s+\w+\s*=|=>\s*{|function\s*\w*\(`), "javascript"},
+	{regexp.MustCompile(`(?m)^\s*fn\s+\w+|\blet\s+mut\b`), "rust"},
+	{regexp.MustCompile(`(?m)^\s*[{\[]\s*$|^\s*"[^"]+"\s*:`), "json"},
+	{regexp.MustCompile(`(?m)^\s*(SELECT|INSERT|UPDATE|DELETE|CREATE)\s`), "sql"},
+	{regexp.MustCompile(`(?m)^\s*\$\s|^#!/bin/(ba)?sh`), "bash"},
+}
+
+// normalizePaste cleans up pasted text so it lands as a single draft
+func normalizePaste(text string) string {
+	// Terminals may deliver CRLF or bare CR line endings
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	// Drop trailing blank lines but keep leading indentation intact
+	return strings.TrimRight(text, "\n\t ")
+}
+
+// looksLikeCode returns true if pasted multi-line text is probably source code
+func looksLikeCode(text string) bool {
+	lines := strings.Split(text, "\n")
+	if len(lines) < 2 {
+		return false
+	}
+
+	// Already fenced content doesn't need another fence
+	if strings.Contains(text, "```") {
+		return false
+	}
+
+	codeLines := 0
+	indentedLines := 0
+	nonEmpty := 0
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		nonEmpty++
+		if strings.HasPrefix(line, "\t") || strings.HasPrefix(line, "  ") {
+			indentedLines++
+		}
+		for _, pattern := range codeLinePatterns {
+			if pattern.MatchString(line) {
+				codeLines++
+				break
+			}
+		}
+	}
+
+	if nonEmpty == 0 {
+		return false
+	}
+
+	// Consider it code when a good share of lines look like code
+	return float64(codeLines)/float64(nonEmpty) >= 0.4 ||
+		(indentedLines > 0 && float64(codeLines+indentedLines)/float64(nonEmpty) >= 0.6)
+}
+
+// guessFenceLanguage returns a best-effort language tag for a code fence
+func guessFenceLanguage(text string) string {
+	for _, hint := range fenceLanguageHints {
+		if hint.pattern.MatchString(text) {
+			return hint.language
+		}
+	}
+	return ""
+}
+
+// wrapInCodeFence wraps text in a markdown code fence
+func wrapInCodeFence(text string) string {
+	return fmt.Sprintf("```%s\n%s\n```", guessFenceLanguage(text), text)
+}
+
+// droppedFilePath returns the absolute path a single-line paste names, which
+// is what most terminals produce when a file is dragged into the window
+func droppedFilePath(text string) string {
+	if strings.Contains(text, "\n") {
+		return ""
+	}
+
+	path := strings.TrimSpace(text)
+	// Strip the quoting terminals add around paths with spaces
+	if len(path) >= 2 && (path[0] == '\'' || path[0] == '"') && path[len(path)-1] == path[0] {
+		path = path[1 : len(path)-1]
+	}
+	path = strings.ReplaceAll(path, `\ `, " ")
+	path = strings.TrimPrefix(path, "file://")
+
+	if path == "" || !filepath.IsAbs(path) {
+		return ""
+	}
+	return path
+}
+
+// checkDroppedFile looks up the path a paste names off the update loop
+func checkDroppedFile(text, path string) tea.Cmd {
+	return func() tea.Msg {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return PasteCheckedMsg{Text: text, File: path}
+		}
+		return PasteCheckedMsg{Text: text}
+	}
+}
+
+// pasteSummary describes a pending paste for the confirmation prompt
+func pasteSummary(text string) string {
+	lines := strings.Count(text, "\n") + 1
+	return fmt.Sprintf("Pasted %d lines (%d chars) that look like code", lines, len(text))
+}
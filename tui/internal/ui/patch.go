@@ -0,0 +1,214 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// pullRequestTimeout bounds how long the server may take to open a pull request
+const pullRequestTimeout = 30 * time.Second
+
+// patchContextLines is how many unchanged lines surround each patch hunk
+const patchContextLines = 3
+
+// appliedChange is a file changed by an applied refactor, from its content
+// before the first change to its content now
+type appliedChange struct {
+	Before  string
+	After   string
+	Existed bool
+}
+
+// PullRequestCreatedMsg reports the branch and pull request opened from a patch
+type PullRequestCreatedMsg struct {
+	Branch string
+	URL    string
+	Err    error
+}
+
+// recordAppliedChange remembers a written change for /patch, keeping the
+// file's content from before the first change
+func (m *Model) recordAppliedChange(path, before, after string, existed bool) {
+	if m.appliedChanges == nil {
+		m.appliedChanges = make(map[string]appliedChange)
+	}
+	path = filepath.Clean(path)
+	if previous, ok := m.appliedChanges[path]; ok {
+		before, existed = previous.Before, previous.Existed
+	}
+	if existed && before == after {
+		delete(m.appliedChanges, path)
+		return
+	}
+	m.appliedChanges[path] = appliedChange{Before: before, After: after, Existed: existed}
+}
+
+// forgetAppliedChange drops a change that was rolled back
+func (m *Model) forgetAppliedChange(path string) {
+	delete(m.appliedChanges, filepath.Clean(path))
+}
+
+// splitLines splits file content into lines, without a final empty line
+// for a trailing newline
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
+
+// unifiedDiff renders one file's change in unified diff format
+func unifiedDiff(path string, change appliedChange) string {
+	old, new := splitLines(change.Before), splitLines(change.After)
+	hunks := diffHunks(strings.Join(old, "\n"), strings.Join(new, "\n"))
+	if !change.Existed {
+		// Everything is added to an empty file
+		hunks = []hunk{{Start: 0, Added: new}}
+		old = nil
+	}
+	if len(hunks) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	from := "a/" + filepath.ToSlash(path)
+	if !change.Existed {
+		from = "/dev/null"
+	}
+	fmt.Fprintf(&b, "--- %s\n+++ b/%s\n", from, filepath.ToSlash(path))
+
+	// Hunks closer than twice the context are merged into one
+	for i := 0; i < len(hunks); {
+		j := i
+		for j+1 < len(hunks) && hunks[j+1].Start-(hunks[j].Start+len(hunks[j].Removed)) <= 2*patchContextLines {
+			j++
+		}
+		start := max(hunks[i].Start-patchContextLines, 0)
+		last := hunks[j]
+		end := min(last.Start+len(last.Removed)+patchContextLines, len(old))
+
+		var body []string
+		oldCount, newCount := 0, 0
+		pos := start
+		for _, h := range hunks[i : j+1] {
+			for ; pos < h.Start; pos++ {
+				body = append(body, " "+old[pos])
+				oldCount++
+				newCount++
+			}
+			for _, line := range h.Removed {
+				body = append(body, "-"+line)
+				oldCount++
+			}
+			for _, line := range h.Added {
+				body = append(body, "+"+line)
+				newCount++
+			}
+			pos = h.Start + len(h.Removed)
+		}
+		for ; pos < end; pos++ {
+			body = append(body, " "+old[pos])
+			oldCount++
+			newCount++
+		}
+
+		// New-file line numbers shift by what earlier hunks added and removed
+		shift := 0
+		for _, h := range hunks[:i] {
+			shift += len(h.Added) - len(h.Removed)
+		}
+		oldStart, newStart := start+1, start+1+shift
+		if oldCount == 0 {
+			oldStart--
+		}
+		if newCount == 0 {
+			newStart--
+		}
+		fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n%s\n", oldStart, oldCount, newStart, newCount, strings.Join(body, "\n"))
+		i = j + 1
+	}
+	return b.String()
+}
+
+// buildPatch bundles the applied changes into one patch, files in order
+func buildPatch(changes map[string]appliedChange) string {
+	paths := make([]string, 0, len(changes))
+	for path := range changes {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	var b strings.Builder
+	for _, path := range paths {
+		b.WriteString(unifiedDiff(path, changes[path]))
+	}
+	return b.String()
+}
+
+// exportPatch writes the applied changes to a patch file in the project
+func (m *Model) exportPatch(name string) {
+	if len(m.appliedChanges) == 0 {
+		m.statusMessages.AddMessage(StatusCategoryError, "No applied changes to export. Use /apply first", nil)
+		return
+	}
+	if name == "" {
+		name = fmt.Sprintf("rubber_duck-%s.patch", time.Now().Format("20060102-150405"))
+	}
+	if m.checkPermission(permissionRequest{
+		Action: permissionExportPatch,
+		Title:  "write a patch file",
+		Detail: "Write the applied changes as a patch to this local file:\n\n  " + name,
+	}, ExecuteCommandMsg{Command: "export_patch", Args: map[string]string{"file": name}}) != permissionAllowed {
+		return
+	}
+	path := m.projectFile(name)
+	if err := os.WriteFile(path, []byte(buildPatch(m.appliedChanges)), 0644); err != nil {
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Cannot write patch: %v", err), nil)
+		return
+	}
+	m.chat.AddMessage(SystemMessage, fmt.Sprintf("Patch with %d changed files written to:\n%s\n\nApply it elsewhere with: git apply %s", len(m.appliedChanges), path, filepath.Base(path)), "system")
+	m.statusBar = "Patch exported"
+}
+
+// createPullRequest sends the applied changes to the server to open a
+// branch and pull request
+func (m *Model) createPullRequest(title string) tea.Cmd {
+	if len(m.appliedChanges) == 0 {
+		m.statusMessages.AddMessage(StatusCategoryError, "No applied changes to open a pull request from. Use /apply first", nil)
+		return nil
+	}
+	client, ok := m.phoenixClient.(*phoenix.Client)
+	if !ok || !m.connected || !m.capabilities[phoenix.CapabilityPullRequests] {
+		m.statusMessages.AddMessage(StatusCategoryError, "The server can't open pull requests - use /patch to export a file instead", nil)
+		return nil
+	}
+	if title == "" {
+		title = fmt.Sprintf("Changes to %d files from RubberDuck", len(m.appliedChanges))
+	}
+	patch := buildPatch(m.appliedChanges)
+	m.statusBar = "Opening pull request..."
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), pullRequestTimeout)
+		defer cancel()
+		branch, url, err := client.CreatePullRequest(ctx, title, patch)
+		return PullRequestCreatedMsg{Branch: branch, URL: url, Err: err}
+	}
+}
+
+// handlePullRequestCreated shows where the pull request was opened
+func (m *Model) handlePullRequestCreated(msg PullRequestCreatedMsg) {
+	if msg.Err != nil {
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Pull request failed: %v", msg.Err), nil)
+		m.statusBar = "Pull request failed"
+		return
+	}
+	m.chat.AddMessage(SystemMessage, fmt.Sprintf("Pull request opened on branch %s:\n%s", msg.Branch, msg.URL), "system")
+	m.statusBar = "Pull request opened"
+}
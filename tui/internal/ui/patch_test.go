@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	before := "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\n"
+	after := "one\nTWO\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\neleven\n"
+	want := `--- a/main.go
++++ b/main.go
+@@ -1,5 +1,5 @@
+ one
+-two
++TWO
+ three
+ four
+ five
+@@ -8,3 +8,4 @@
+ eight
+ nine
+ ten
++eleven
+`
+	if got := unifiedDiff("main.go", appliedChange{Before: before, After: after, Existed: true}); got != want {
+		t.Fatalf("Unexpected diff:\n%s", got)
+	}
+
+	created := unifiedDiff("pkg/new.go", appliedChange{After: "package pkg\n"})
+	if created != "--- /dev/null\n+++ b/pkg/new.go\n@@ -0,0 +1,1 @@\n+package pkg\n" {
+		t.Fatalf("Unexpected diff for a new file:\n%s", created)
+	}
+}
+
+func TestExportPatchKeepsFirstOriginal(t *testing.T) {
+	testutil.IsolateHome(t)
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	var model Model = *NewModel()
+	model.recordAppliedChange("a.go", "v1\n", "v2\n", true)
+	model.recordAppliedChange("a.go", "v2\n", "v3\n", true)
+	model.sessionPermissions[permissionExportPatch] = permissionAllow
+	model.exportPatch("out.patch")
+
+	data, err := os.ReadFile(filepath.Join(dir, "out.patch"))
+	if err != nil {
+		t.Fatalf("Expected the patch to be written: %v", err)
+	}
+	if !strings.Contains(string(data), "-v1\n+v3\n") {
+		t.Fatalf("Expected the patch to span both changes, got:\n%s", data)
+	}
+
+	// Changing a file back drops it from the patch
+	model.recordAppliedChange("a.go", "v3\n", "v1\n", true)
+	if len(model.appliedChanges) != 0 {
+		t.Fatal("Expected a reverted file to be forgotten")
+	}
+}
@@ -0,0 +1,96 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// pathPattern finds file paths, with an optional :line, in chat messages
+var pathPattern = regexp.MustCompile(`(?:^|[\s(\[\x60"'])((?:\.{1,2}/|/)?[\w.\-]+(?:/[\w.\-]+)*\.[A-Za-z]\w{0,5})(?::(\d+))?`)
+
+// findPaths finds file paths in a line. A bare name like "main.go" needs
+// an extension of a known language, so "e.g." and "fmt.Println" aren't paths
+func findPaths(line string) [][]int {
+	var ranges [][]int
+	for _, match := range pathPattern.FindAllStringSubmatchIndex(line, -1) {
+		path := line[match[2]:match[3]]
+		if !strings.Contains(path, "/") && languageForPath(path) == "" {
+			continue
+		}
+		end := match[3]
+		if match[5] > 0 {
+			end = match[5]
+		}
+		ranges = append(ranges, []int{match[2], end})
+	}
+	return ranges
+}
+
+// splitPathLine splits "path:line" into the path and line, 0 without one
+func splitPathLine(text string) (string, int) {
+	if i := strings.LastIndex(text, ":"); i > 0 {
+		if line, err := strconv.Atoi(text[i+1:]); err == nil {
+			return text[:i], line
+		}
+	}
+	return text, 0
+}
+
+// projectPath returns where a path mentioned in the chat is in the file
+// tree's file system
+func (m Model) projectPath(path string) string {
+	fs := m.fileTree.FileSystem()
+	if filepath.IsAbs(path) {
+		if root, err := filepath.Abs(fs.Root()); err == nil {
+			if rel, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(rel, "..") {
+				return filepath.Join(fs.Root(), rel)
+			}
+		}
+		return path
+	}
+	return filepath.Join(fs.Root(), path)
+}
+
+// mentionedFileExists reports whether a path in the chat names a file. Paths
+// on the server are taken on trust, since checking each would ask the server
+func (m Model) mentionedFileExists(text string) bool {
+	if m.fileTree.FileSystem().Name() != FileSourceLocal {
+		return true
+	}
+	path, _ := splitPathLine(text)
+	info, err := os.Stat(m.projectPath(path))
+	return err == nil && !info.IsDir()
+}
+
+// startPathHints labels the file paths in the assistant messages in view
+func (m *Model) startPathHints() {
+	if count := m.chat.StartHints("path", m.mentionedFileExists); count == 0 {
+		m.statusBar = "No file paths in the assistant messages in view"
+	} else {
+		m.statusBar = hintsStatus("path", count)
+	}
+}
+
+// handlePathHint opens the path picked in hint mode in the editor, at its
+// line when it has one, or copies it when typed in uppercase
+func (m *Model) handlePathHint(text string, shift bool) tea.Cmd {
+	if shift {
+		if err := clipboard.WriteAll(text); err != nil {
+			m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Cannot copy the path: %v", err), nil)
+			return nil
+		}
+		m.statusBar = "Copied " + text
+		return nil
+	}
+	path, line := splitPathLine(text)
+	selected := FileSelectedMsg{Path: m.projectPath(path), Line: line}
+	m.focusPane(EditorPane)
+	return func() tea.Msg { return selected }
+}
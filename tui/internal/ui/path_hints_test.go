@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+func TestFindPaths(t *testing.T) {
+	line := "Fix `internal/ui/chat.go:120`, main.go and ./run.sh, e.g. fmt.Println, not https://go.dev/x.go"
+	var found []string
+	for _, match := range findPaths(line) {
+		found = append(found, line[match[0]:match[1]])
+	}
+	want := []string{"internal/ui/chat.go:120", "main.go", "./run.sh"}
+	if len(found) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, found)
+	}
+	for i := range want {
+		if found[i] != want[i] {
+			t.Errorf("Expected %q, got %q", want[i], found[i])
+		}
+	}
+}
+
+func TestPathHintsOpenMentionedFiles(t *testing.T) {
+	testutil.IsolateHome(t)
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "handler.go"), []byte("package app\n\nfunc Handle() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	model := NewModel()
+	model.fileTree.SetFileSystem(NewLocalFS(dir))
+	model.chat.SetSize(100, 30)
+	model.chat.AddMessage(UserMessage, "Why does handler.go panic?", "user")
+	model.chat.AddMessage(AssistantMessage, "Look at handler.go:3 and missing.go", "assistant")
+
+	// Only the existing file in the assistant message is labeled
+	if count := model.chat.StartHints("path", model.mentionedFileExists); count != 1 {
+		t.Fatalf("Expected 1 path, got %d", count)
+	}
+	cmd := model.chat.updateHints(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	updated, cmd := model.Update(cmd())
+	*model = updated.(Model)
+	if cmd == nil {
+		t.Fatal("Expected the path to be opened")
+	}
+	if selected := cmd().(FileSelectedMsg); selected.Path != filepath.Join(dir, "handler.go") || selected.Line != 3 {
+		t.Errorf("Unexpected file selection %+v", selected)
+	}
+}
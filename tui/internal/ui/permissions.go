@@ -0,0 +1,86 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// lockIcon marks commands the signed-in account may not run
+const lockIcon = "🔒"
+
+// commandPermissions maps ExecuteCommandMsg commands to the permission they
+// need
+var commandPermissions = map[string]string{
+	"auth_apikey_generate": phoenix.PermissionAPIKeys,
+	"auth_apikey_list":     phoenix.PermissionAPIKeys,
+	"auth_apikey_revoke":   phoenix.PermissionAPIKeys,
+	"auth_sessions_list":   phoenix.PermissionSessions,
+	"auth_sessions_revoke": phoenix.PermissionSessions,
+	"start_planning":       phoenix.PermissionPlanning,
+	"cancel_planning":      phoenix.PermissionPlanning,
+	"admin_panel":          phoenix.PermissionAdmin,
+}
+
+// permissionNames describes permissions in user-facing messages
+var permissionNames = map[string]string{
+	phoenix.PermissionAPIKeys:  "manage API keys",
+	phoenix.PermissionSessions: "manage sessions",
+	phoenix.PermissionPlanning: "start planning sessions",
+	phoenix.PermissionAdmin:    "use admin commands",
+}
+
+// applyGrants stores what the signed-in user may do and locks the rest
+func (m *Model) applyGrants(user phoenix.AuthUser) {
+	m.permissions = user.Grants()
+	m.roles = user.Roles
+	m.commandPalette.SetPermissions(m.permissions)
+}
+
+// requirePermission reports whether the signed-in user may run a command,
+// explaining why not when they can't
+func (m *Model) requirePermission(permission string) bool {
+	if m.permissions.Has(permission) {
+		return true
+	}
+	m.statusBar = lockIcon + " Permission required"
+	m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Your account is not allowed to %s", permissionNames[permission]), nil)
+	return false
+}
+
+// formatSlashCommandsFor renders the slash command list, marking commands
+// the user may not run
+func formatSlashCommandsFor(permissions phoenix.Permissions) string {
+	if permissions == nil {
+		return FormatSlashCommandList()
+	}
+	lines := strings.Split(strings.TrimRight(FormatSlashCommandList(), "\n"), "\n")
+	for i, c := range SlashCommands {
+		if c.Permission != "" && !permissions.Has(c.Permission) {
+			lines[i] += " " + lockIcon
+		}
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// describeRoles summarizes the user's roles for /status
+func (m Model) describeRoles() string {
+	if m.permissions == nil {
+		return ""
+	}
+	roles := "none"
+	if len(m.roles) > 0 {
+		roles = strings.Join(m.roles, ", ")
+	}
+	var locked []string
+	for _, permission := range []string{phoenix.PermissionAPIKeys, phoenix.PermissionSessions, phoenix.PermissionPlanning, phoenix.PermissionAdmin} {
+		if !m.permissions.Has(permission) {
+			locked = append(locked, permissionNames[permission])
+		}
+	}
+	if len(locked) == 0 {
+		return fmt.Sprintf("Roles: %s", roles)
+	}
+	return fmt.Sprintf("Roles: %s\nNot allowed to: %s", roles, strings.Join(locked, ", "))
+}
@@ -0,0 +1,203 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rubber_duck/tui/internal/clock"
+)
+
+// maxPinnedShown is how many pins the expanded panel lists
+const maxPinnedShown = 5
+
+// MessagePinnedMsg reports that a message was pinned or unpinned
+type MessagePinnedMsg struct {
+	Index  int
+	Pinned bool
+}
+
+// startSelection selects the last assistant answer, or the last message
+func (c *Chat) startSelection() {
+	if len(c.messages) == 0 {
+		return
+	}
+	c.selecting = true
+	c.sourceCursor = 0
+	c.selected = len(c.messages) - 1
+	for i := len(c.messages) - 1; i >= 0; i-- {
+		if c.messages[i].Type == AssistantMessage {
+			c.selected = i
+			break
+		}
+	}
+	c.refreshSelection()
+}
+
+// stopSelection leaves message selection
+func (c *Chat) stopSelection() {
+	c.selecting = false
+	c.copyMenu = false
+	c.viewport.SetContent(c.buildViewportContent())
+}
+
+// IsSelecting reports whether a message is selected
+func (c *Chat) IsSelecting() bool {
+	return c.selecting
+}
+
+// updateSelection handles keys while a message is selected
+func (c Chat) updateSelection(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if c.copyMenu {
+		return c.updateCopyMenu(msg)
+	}
+	switch msg.String() {
+	case "up", "k", "alt+up":
+		if c.selected > 0 {
+			c.selected--
+			c.sourceCursor = 0
+		}
+	case "down", "j", "alt+down":
+		if c.selected < len(c.messages)-1 {
+			c.selected++
+			c.sourceCursor = 0
+		}
+	case "p":
+		index := c.selected
+		pinned := !c.messages[index].Pinned
+		c.SetPinned(index, pinned)
+		return c, func() tea.Msg { return MessagePinnedMsg{Index: index, Pinned: pinned} }
+	case "c":
+		c.copyMenu = true
+		return c, nil
+	case "r":
+		reply := ReplyToMessageMsg{Index: c.selected}
+		return c, func() tea.Msg { return reply }
+	case "o":
+		c.toggleExpanded(c.selected)
+	case "d":
+		c.toggleDiff(c.selected)
+	case "t":
+		c.toggleTrace(c.selected)
+	case "s":
+		c.cycleSource()
+	case "enter":
+		if len(c.selectedSources()) > 0 {
+			return c, c.openSelectedSource()
+		}
+		c.stopSelection()
+		return c, nil
+	case "esc", "q":
+		c.stopSelection()
+		return c, nil
+	default:
+		return c, nil
+	}
+	c.refreshSelection()
+	return c, nil
+}
+
+// refreshSelection redraws the messages and scrolls to the selected one
+func (c *Chat) refreshSelection() {
+	c.viewport.SetContent(c.buildViewportContent())
+	if c.selected < len(c.messageLines) {
+		c.viewport.SetYOffset(c.messageLines[c.selected])
+	}
+}
+
+// SetPinned pins or unpins a message
+func (c *Chat) SetPinned(index int, pinned bool) {
+	if index < 0 || index >= len(c.messages) {
+		return
+	}
+	c.messages[index].Pinned = pinned
+	c.layout()
+	c.viewport.SetContent(c.buildViewportContent())
+}
+
+// PinLastAssistantMessage pins the latest answer, returning its index or -1
+func (c *Chat) PinLastAssistantMessage() int {
+	for i := len(c.messages) - 1; i >= 0; i-- {
+		if c.messages[i].Type == AssistantMessage {
+			c.SetPinned(i, true)
+			return i
+		}
+	}
+	return -1
+}
+
+// TogglePinnedPanel expands or collapses the pinned panel
+func (c *Chat) TogglePinnedPanel() {
+	c.pinsCollapsed = !c.pinsCollapsed
+	c.layout()
+}
+
+// pinnedMessages returns the pinned messages in conversation order
+func (c Chat) pinnedMessages() []ChatMessage {
+	pinned := c.spill.pinned()
+	for _, msg := range c.messages {
+		if msg.Pinned {
+			pinned = append(pinned, msg)
+		}
+	}
+	return pinned
+}
+
+// restoreMessage adds a message from a saved conversation or the server's
+// history, keeping its ID, time, and pin
+func (c *Chat) restoreMessage(saved ChatMessage) {
+	at := saved.Timestamp
+	if at.IsZero() {
+		at = clock.Now()
+	}
+	c.AddMessageAt(saved.Type, saved.Content, saved.Author, at)
+	c.messages[len(c.messages)-1].ID = saved.ID
+	c.messages[len(c.messages)-1].Full = saved.Full
+	c.messages[len(c.messages)-1].Previous = saved.Previous
+	c.messages[len(c.messages)-1].Trace = saved.Trace
+	if saved.Pinned {
+		c.SetPinned(len(c.messages)-1, true)
+	}
+}
+
+// renderPinnedPanel renders the pinned answers above the history, or
+// nothing when none are pinned
+func (c Chat) renderPinnedPanel() string {
+	pinned := c.pinnedMessages()
+	if len(pinned) == 0 {
+		return ""
+	}
+
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("214"))
+	hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	if c.pinsCollapsed {
+		return headerStyle.Render(fmt.Sprintf("📌 %d pinned", len(pinned))) + hintStyle.Render(" · /pins to expand")
+	}
+
+	lines := []string{headerStyle.Render(fmt.Sprintf("📌 Pinned (%d)", len(pinned))) + hintStyle.Render(" · /pins to collapse")}
+	width := max(c.width-6, 10)
+	for i, msg := range pinned {
+		if i == maxPinnedShown {
+			lines = append(lines, hintStyle.Render(fmt.Sprintf("  … and %d more", len(pinned)-maxPinnedShown)))
+			break
+		}
+		summary := strings.Join(strings.Fields(msg.Content), " ")
+		lines = append(lines, "  • "+truncateStage(summary, width))
+	}
+	return lipgloss.NewStyle().
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderBottom(true).
+		BorderForeground(lipgloss.Color("240")).
+		Width(c.width - 2).
+		Render(strings.Join(lines, "\n"))
+}
+
+// layout fits the history viewport around the input and pinned panel
+func (c *Chat) layout() {
+	height := c.height - 7 // Leave room for input area and title
+	if panel := c.renderPinnedPanel(); panel != "" {
+		height -= lipgloss.Height(panel)
+	}
+	c.viewport.Height = height
+}
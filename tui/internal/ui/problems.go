@@ -0,0 +1,317 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rubber_duck/tui/internal/clock"
+	"github.com/rubber_duck/tui/internal/metrics"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// maxProblems caps the distinct problems kept; the stalest are dropped
+const maxProblems = 100
+
+// problemOccurrencesKept is how many recent occurrences a problem lists
+// when expanded
+const problemOccurrencesKept = 5
+
+// problem is an error that occurred once or more with the same message
+type problem struct {
+	Component string
+	Message   string
+	Info      phoenix.ErrorInfo
+	Count     int
+	First     time.Time
+	Last      time.Time
+	Recent    []time.Time // Last few occurrences, oldest first
+}
+
+// ProblemsPane lists recent errors grouped by component, with counts and
+// when each was first and last seen
+type ProblemsPane struct {
+	problems []*problem
+	selected int
+	expanded map[*problem]bool
+	visible  bool
+	width    int
+	height   int
+}
+
+// NewProblemsPane creates an empty problems pane
+func NewProblemsPane() ProblemsPane {
+	return ProblemsPane{expanded: make(map[*problem]bool), width: 80, height: 24}
+}
+
+// Record notes an error from a component
+func (pp *ProblemsPane) Record(err error, component string) {
+	if err == nil {
+		return
+	}
+	now := clock.Now()
+	message := err.Error()
+	for _, p := range pp.problems {
+		if p.Component == component && p.Message == message {
+			p.Count++
+			p.Last = now
+			p.Recent = append(p.Recent, now)
+			if len(p.Recent) > problemOccurrencesKept {
+				p.Recent = p.Recent[1:]
+			}
+			pp.sort()
+			return
+		}
+	}
+
+	pp.problems = append(pp.problems, &problem{
+		Component: component,
+		Message:   message,
+		Info:      phoenix.ClassifyError(err),
+		Count:     1,
+		First:     now,
+		Last:      now,
+		Recent:    []time.Time{now},
+	})
+	pp.sort()
+	if len(pp.problems) > maxProblems {
+		stalest := pp.problems[0]
+		for _, p := range pp.problems[1:] {
+			if p.Last.Before(stalest.Last) {
+				stalest = p
+			}
+		}
+		pp.remove(stalest)
+	}
+}
+
+// sort orders components by their latest problem, and problems within a
+// component latest first
+func (pp *ProblemsPane) sort() {
+	latest := make(map[string]time.Time)
+	for _, p := range pp.problems {
+		if p.Last.After(latest[p.Component]) {
+			latest[p.Component] = p.Last
+		}
+	}
+	sort.SliceStable(pp.problems, func(i, j int) bool {
+		a, b := pp.problems[i], pp.problems[j]
+		if a.Component != b.Component {
+			if !latest[a.Component].Equal(latest[b.Component]) {
+				return latest[a.Component].After(latest[b.Component])
+			}
+			return a.Component < b.Component
+		}
+		return a.Last.After(b.Last)
+	})
+}
+
+// remove drops a problem
+func (pp *ProblemsPane) remove(target *problem) {
+	for i, p := range pp.problems {
+		if p == target {
+			pp.problems = append(pp.problems[:i], pp.problems[i+1:]...)
+			delete(pp.expanded, target)
+			break
+		}
+	}
+	if pp.selected >= len(pp.problems) {
+		pp.selected = max(len(pp.problems)-1, 0)
+	}
+}
+
+// Count returns the number of errors recorded across all problems
+func (pp ProblemsPane) Count() int {
+	total := 0
+	for _, p := range pp.problems {
+		total += p.Count
+	}
+	return total
+}
+
+// Clear forgets every problem
+func (pp *ProblemsPane) Clear() {
+	pp.problems = nil
+	pp.expanded = make(map[*problem]bool)
+	pp.selected = 0
+}
+
+// Show displays the problems pane
+func (pp *ProblemsPane) Show() {
+	pp.visible = true
+}
+
+// Hide hides the problems pane
+func (pp *ProblemsPane) Hide() {
+	pp.visible = false
+}
+
+// IsVisible returns whether the problems pane is visible
+func (pp ProblemsPane) IsVisible() bool {
+	return pp.visible
+}
+
+// SetSize updates the pane dimensions
+func (pp *ProblemsPane) SetSize(width, height int) {
+	pp.width = width
+	pp.height = height
+}
+
+// Update handles problems pane input
+func (pp ProblemsPane) Update(msg tea.Msg) (ProblemsPane, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || !pp.visible {
+		return pp, nil
+	}
+
+	switch keyMsg.String() {
+	case "esc", "q":
+		pp.Hide()
+	case "up", "k":
+		if pp.selected > 0 {
+			pp.selected--
+		}
+	case "down", "j":
+		if pp.selected < len(pp.problems)-1 {
+			pp.selected++
+		}
+	case "enter", " ", "right", "left":
+		if pp.selected < len(pp.problems) {
+			p := pp.problems[pp.selected]
+			pp.expanded[p] = !pp.expanded[p]
+		}
+	case "d", "delete":
+		if pp.selected < len(pp.problems) {
+			pp.remove(pp.problems[pp.selected])
+		}
+	case "c":
+		pp.Clear()
+	}
+	return pp, nil
+}
+
+// View renders the problems pane
+func (pp ProblemsPane) View() string {
+	if !pp.visible {
+		return ""
+	}
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("62"))
+	headingStyle := lipgloss.NewStyle().Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+
+	header := []string{titleStyle.Render(fmt.Sprintf("◆ Problems (%d) ◆", pp.Count())), ""}
+	footer := []string{"", dimStyle.Render("↑/↓: Select | Enter: Details | d: Dismiss | c: Clear all | Esc: Close")}
+
+	var lines []string
+	selectedLine := 0
+	if len(pp.problems) == 0 {
+		lines = append(lines, dimStyle.Render("No problems since start."))
+	}
+	component := ""
+	for i, p := range pp.problems {
+		if p.Component != component {
+			component = p.Component
+			if len(lines) > 0 {
+				lines = append(lines, "")
+			}
+			lines = append(lines, headingStyle.Render(fmt.Sprintf("%s (%d)", component, pp.componentCount(component))))
+		}
+
+		cursor, marker := "  ", "▸"
+		if i == pp.selected {
+			cursor = "> "
+			selectedLine = len(lines)
+		}
+		if pp.expanded[p] {
+			marker = "▾"
+		}
+		code := ""
+		if p.Info.Code != phoenix.ErrUnknown {
+			code = " [" + string(p.Info.Code) + "]"
+		}
+		message := truncateStage(p.Message+code, max(pp.width-40, 20))
+		lines = append(lines, fmt.Sprintf("%s%s %3d× %s  %s",
+			cursor, marker, p.Count, errorStyle.Render(message),
+			dimStyle.Render(fmt.Sprintf("first %s, last %s", p.First.Format("15:04:05"), p.Last.Format("15:04:05")))))
+
+		if pp.expanded[p] {
+			lines = append(lines, pp.details(p)...)
+		}
+	}
+
+	// Scroll so the selected problem stays in view
+	room := max(pp.height-4-len(header)-len(footer), 1)
+	offset := 0
+	if selectedLine >= room {
+		offset = selectedLine - room + 1
+	}
+	end := min(offset+room, len(lines))
+
+	body := append(append(header, lines[offset:end]...), footer...)
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Padding(0, 1).
+		Width(pp.width - 2).
+		Height(pp.height - 2).
+		Render(strings.Join(body, "\n"))
+}
+
+// details renders an expanded problem: its full message, what to do, and
+// when it last happened
+func (pp ProblemsPane) details(p *problem) []string {
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	indent := "       "
+	lines := []string{indent + p.Message}
+	if p.Info.Code != phoenix.ErrUnknown {
+		lines = append(lines, fmt.Sprintf("%s%s %s: %s", indent, p.Info.Code, p.Info.Title, p.Info.Hint))
+		if p.Info.Retryable {
+			lines = append(lines, indent+"Retrying can help (Ctrl+R)")
+		}
+		lines = append(lines, dimStyle.Render(fmt.Sprintf("%sMore: /help errors %s", indent, p.Info.Code)))
+	}
+	var times []string
+	for _, at := range p.Recent {
+		times = append(times, at.Format("15:04:05"))
+	}
+	lines = append(lines, dimStyle.Render(indent+"Recent: "+strings.Join(times, ", ")))
+	return lines
+}
+
+// componentCount returns the errors recorded for a component
+func (pp ProblemsPane) componentCount(component string) int {
+	total := 0
+	for _, p := range pp.problems {
+		if p.Component == component {
+			total += p.Count
+		}
+	}
+	return total
+}
+
+// reportError records an error in the problems pane and shows it unless
+// the error handler is holding back repeats, returning whether it did
+func (m *Model) reportError(err error, component string) (bool, string) {
+	m.problemsPane.Record(err, component)
+	if err != nil {
+		m.metrics.Inc(metrics.ErrorsTotal, "component", component, "code", string(phoenix.ClassifyError(err).Code))
+	}
+	display, message := m.errorHandler.HandleError(err, component)
+	if !display && err != nil {
+		// Repeats aren't shown one by one; point at where they're counted
+		m.statusBar = fmt.Sprintf("%d errors - /problems lists them", m.problemsPane.Count())
+	}
+	return display, message
+}
+
+// openProblemsPane shows the problems pane
+func (m *Model) openProblemsPane() {
+	m.problemsPane.SetSize(m.width, m.height)
+	m.problemsPane.Show()
+	m.statusBar = "Problems"
+}
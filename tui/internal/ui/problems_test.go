@@ -0,0 +1,44 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/clock"
+	"github.com/rubber_duck/tui/internal/phoenix"
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+func TestProblemsPaneGroupsRepeats(t *testing.T) {
+	at := func(minutes int) {
+		t.Cleanup(clock.Freeze(testutil.FrozenTime.Add(time.Duration(minutes) * time.Minute)))
+	}
+	pp := NewProblemsPane()
+	at(0)
+	pp.Record(phoenix.Errorf(phoenix.ErrNotConnected, "socket not connected"), "Connection")
+	at(1)
+	pp.Record(errors.New("quota exceeded"), "Provider")
+	at(2)
+	pp.Record(phoenix.Errorf(phoenix.ErrNotConnected, "socket not connected"), "Connection")
+
+	if len(pp.problems) != 2 || pp.Count() != 3 {
+		t.Fatalf("Expected 2 problems from 3 errors, got %d from %d", len(pp.problems), pp.Count())
+	}
+	first := pp.problems[0]
+	if first.Component != "Connection" || first.Count != 2 || first.Last.Sub(first.First) != 2*time.Minute {
+		t.Errorf("Expected the latest component first with both times, got %+v", first)
+	}
+
+	pp.SetSize(120, 30)
+	pp.Show()
+	pp, _ = pp.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	view := pp.View()
+	for _, want := range []string{"Connection (2)", "Provider (1)", "[E100]", "first 09:30:00, last 09:32:00", "/help errors E100"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected %q in view:\n%s", want, view)
+		}
+	}
+}
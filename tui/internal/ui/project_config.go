@@ -0,0 +1,422 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ProjectConfigFile is the project-local settings file, committed so a team
+// shares TUI settings; its keys override the global config's
+const ProjectConfigFile = ".rubber_duck.toml"
+
+// projectConfigKeys are the settings a project file may set: model
+// defaults, blocked commands, and layout. A cloned repository could
+// otherwise approve permission prompts, pick the browser command, or send
+// traffic through its own proxy
+var projectConfigKeys = [][]string{
+	{"default_provider"},
+	{"default_model"},
+	{"default_temperature"},
+	{"fallback_chain"},
+	{"auto_fallback"},
+	{"tui", "blocked_commands"},
+	{"tui", "layout"},
+}
+
+// LayoutConfig picks the panes shown at startup; unset keeps the defaults
+type LayoutConfig struct {
+	FileTree *bool `json:"file_tree,omitempty"`
+	Editor   *bool `json:"editor,omitempty"`
+	Outline  *bool `json:"outline,omitempty"`
+}
+
+// CommandBlockedMsg reports a command refused by the project's settings
+type CommandBlockedMsg struct {
+	Command string
+}
+
+// projectOverlay remembers what a project file changed, so saving writes
+// only the user's own settings back to the global config
+type projectOverlay struct {
+	Path    string         // The project file
+	Ignored []string       // Keys the project file set that projects may not
+	values  map[string]any // Keys set by the project file
+	global  map[string]any // The global config before merging
+	applied map[string]any // The config right after merging
+}
+
+// findProjectConfig looks for the project file from dir up to the
+// repository root, returning "" when there is none
+func findProjectConfig(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		path := filepath.Join(dir, ProjectConfigFile)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		// Settings above the repository belong to another project
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return ""
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// mergeProjectConfig overrides config with the project file found from dir,
+// if any
+func mergeProjectConfig(config *Config, dir string) error {
+	path := findProjectConfig(dir)
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	values := map[string]any{}
+	if err := toml.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	values, ignored := allowedProjectValues(values)
+
+	global, err := configMap(config)
+	if err != nil {
+		return err
+	}
+	merged, err := configMap(config)
+	if err != nil {
+		return err
+	}
+	mergeValues(merged, values)
+
+	data, err = json.Marshal(merged)
+	if err != nil {
+		return err
+	}
+	var result Config
+	if err := json.Unmarshal(data, &result); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	applied, err := configMap(&result)
+	if err != nil {
+		return err
+	}
+	result.project = &projectOverlay{Path: path, Ignored: ignored, values: values, global: global, applied: applied}
+	*config = result
+	return nil
+}
+
+// allowedProjectValues keeps the keys of projectConfigKeys, returning the
+// dotted paths of the others
+func allowedProjectValues(values map[string]any) (map[string]any, []string) {
+	allowed := map[string]any{}
+	var ignored []string
+	for _, path := range leafPaths(values, nil) {
+		if !projectKeyAllowed(path) {
+			ignored = append(ignored, strings.Join(path, "."))
+			continue
+		}
+		value, _ := lookupPath(values, path)
+		setPath(allowed, path, value)
+	}
+	sort.Strings(ignored)
+	return allowed, ignored
+}
+
+// projectKeyAllowed reports whether a key path falls under projectConfigKeys
+func projectKeyAllowed(path []string) bool {
+	for _, key := range projectConfigKeys {
+		if len(path) >= len(key) && slices.Equal(path[:len(key)], key) {
+			return true
+		}
+	}
+	return false
+}
+
+// ProjectIgnoredKeys returns the keys the project file set but may not
+func (c *Config) ProjectIgnoredKeys() []string {
+	if c.project == nil {
+		return nil
+	}
+	return c.project.Ignored
+}
+
+// warnIgnoredProjectKeys reports the keys the project file wasn't allowed
+// to set
+func (m *Model) warnIgnoredProjectKeys() {
+	ignored := m.config.ProjectIgnoredKeys()
+	if len(ignored) == 0 {
+		return
+	}
+	m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("%s may only set model defaults, blocked commands, and layout; ignored %s", filepath.Base(m.config.ProjectConfigPath()), strings.Join(ignored, ", ")), nil)
+}
+
+// ProjectConfigPath returns the project file merged into the config, or ""
+func (c *Config) ProjectConfigPath() string {
+	if c.project == nil {
+		return ""
+	}
+	return c.project.Path
+}
+
+// globalView returns the config to save: keys the project file set go back
+// to their global values unless they were changed since loading
+func (c *Config) globalView() (*Config, error) {
+	if c.project == nil {
+		return c, nil
+	}
+	current, err := configMap(c)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range leafPaths(c.project.values, nil) {
+		value, _ := lookupPath(current, path)
+		applied, _ := lookupPath(c.project.applied, path)
+		if !reflect.DeepEqual(value, applied) {
+			continue
+		}
+		if global, ok := lookupPath(c.project.global, path); ok {
+			setPath(current, path, global)
+		} else {
+			deletePath(current, path)
+		}
+	}
+
+	data, err := json.Marshal(current)
+	if err != nil {
+		return nil, err
+	}
+	var view Config
+	if err := json.Unmarshal(data, &view); err != nil {
+		return nil, err
+	}
+	return &view, nil
+}
+
+// configMap returns the config as generic JSON values
+func configMap(config *Config) (map[string]any, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]any
+	err = json.Unmarshal(data, &values)
+	return values, err
+}
+
+// mergeValues copies overrides into values, merging tables key by key
+func mergeValues(values, overrides map[string]any) {
+	for key, override := range overrides {
+		table, isTable := override.(map[string]any)
+		existing, hasTable := values[key].(map[string]any)
+		if isTable && hasTable {
+			mergeValues(existing, table)
+			continue
+		}
+		values[key] = override
+	}
+}
+
+// leafPaths lists the key paths of the non-table values in values
+func leafPaths(values map[string]any, prefix []string) [][]string {
+	var paths [][]string
+	for key, value := range values {
+		path := append(append([]string{}, prefix...), key)
+		if table, ok := value.(map[string]any); ok {
+			paths = append(paths, leafPaths(table, path)...)
+		} else {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// lookupPath returns the value at a key path
+func lookupPath(values map[string]any, path []string) (any, bool) {
+	for _, key := range path[:len(path)-1] {
+		table, ok := values[key].(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		values = table
+	}
+	value, ok := values[path[len(path)-1]]
+	return value, ok
+}
+
+// setPath sets the value at a key path, creating tables on the way
+func setPath(values map[string]any, path []string, value any) {
+	for _, key := range path[:len(path)-1] {
+		table, ok := values[key].(map[string]any)
+		if !ok {
+			table = map[string]any{}
+			values[key] = table
+		}
+		values = table
+	}
+	values[path[len(path)-1]] = value
+}
+
+// deletePath removes the value at a key path
+func deletePath(values map[string]any, path []string) {
+	for _, key := range path[:len(path)-1] {
+		table, ok := values[key].(map[string]any)
+		if !ok {
+			return
+		}
+		values = table
+	}
+	delete(values, path[len(path)-1])
+}
+
+// commandBlocked reports whether blocked_commands refuses a command, named
+// by its slash command (aliases count) or command palette action
+func commandBlocked(blockedCommands []string, name string) bool {
+	name = strings.ToLower(strings.TrimPrefix(name, "/"))
+	if command, ok := LookupSlashCommand(name); ok {
+		name = command.Name
+	}
+	for _, blocked := range blockedCommands {
+		blocked = strings.ToLower(strings.TrimPrefix(blocked, "/"))
+		if command, ok := LookupSlashCommand(blocked); ok {
+			blocked = command.Name
+		}
+		if blocked == name {
+			return true
+		}
+	}
+	return false
+}
+
+// refuseBlockedCommand explains why a command didn't run
+func (m *Model) refuseBlockedCommand(command string) {
+	source := "your config"
+	if path := m.config.ProjectConfigPath(); path != "" {
+		source = filepath.Base(path)
+	}
+	m.statusBar = fmt.Sprintf("/%s is blocked by %s", command, source)
+	m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("/%s is blocked by blocked_commands in %s", command, source), nil)
+}
+
+// applyLayout shows the panes the layout settings ask for
+func (m *Model) applyLayout() {
+	layout := m.config.TUI.Layout
+	if layout.FileTree != nil {
+		m.showFileTree = *layout.FileTree
+	}
+	if layout.Editor != nil {
+		m.showEditor = *layout.Editor
+	}
+	if layout.Outline != nil {
+		m.showOutline = *layout.Outline
+	}
+	m.updateComponentSizes()
+}
+
+// openProject switches to another project directory and merges its settings
+// over the global config
+func (m *Model) openProject(dir string) tea.Cmd {
+	if strings.HasPrefix(dir, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			dir = filepath.Join(home, dir[2:])
+		}
+	}
+	dir = m.projectFile(dir)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Not a directory: %s", dir), nil)
+		return nil
+	}
+	config, err := loadConfigFor(dir)
+	if err != nil {
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Failed to load config: %v", err), nil)
+		return nil
+	}
+
+	m.config = config
+	m.projectDir = dir
+	m.composeModal.dir = dir
+	m.loadResponseFilters()
+	m.applyChatSettings()
+	m.currentProvider = config.DefaultProvider
+	m.currentModel = config.DefaultModel
+	m.temperature = defaultTemperature
+	if config.DefaultTemperature != nil {
+		m.temperature = *config.DefaultTemperature
+	}
+	listFiles := m.applyFileSource()
+	if m.fileTree.FileSystem().Name() == FileSourceLocal {
+		m.fileTree.SetFileSystem(NewLocalFS(m.projectDir))
+	}
+	m.applyLayout()
+	m.updateHeaderState()
+	m.warnIgnoredProjectKeys()
+
+	m.chat.AddMessage(SystemMessage, m.projectSummary(), "system")
+	m.statusBar = "Project opened: " + filepath.Base(m.projectRoot())
+	return listFiles
+}
+
+// projectRoot returns the absolute project directory
+func (m Model) projectRoot() string {
+	return m.projectDir
+}
+
+// projectFile resolves a local path against the project directory
+func (m Model) projectFile(path string) string {
+	if path == "" || filepath.IsAbs(path) || m.projectDir == "" {
+		return path
+	}
+	return filepath.Join(m.projectDir, path)
+}
+
+// workingDir returns the absolute directory the TUI started in
+func workingDir() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "."
+	}
+	return dir
+}
+
+// projectSummary describes the project and the settings it overrides
+func (m Model) projectSummary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Project: %s\n", m.projectRoot())
+	path := m.config.ProjectConfigPath()
+	if path == "" {
+		fmt.Fprintf(&b, "No %s - using the global config only", ProjectConfigFile)
+		return b.String()
+	}
+	fmt.Fprintf(&b, "Settings from %s override:", path)
+	var keys []string
+	for _, keyPath := range leafPaths(m.config.project.values, nil) {
+		keys = append(keys, strings.Join(keyPath, "."))
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&b, "\n  %s", key)
+	}
+	if ignored := m.config.ProjectIgnoredKeys(); len(ignored) > 0 {
+		b.WriteString("\nIgnored, projects may only set model defaults, blocked commands, and layout:")
+		for _, key := range ignored {
+			fmt.Fprintf(&b, "\n  %s", key)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// SetPromptAffixes sets the prefix and suffix shown by the input
+func (c *Chat) SetPromptAffixes(prefix, suffix string) {
+	c.promptPrefix, c.promptSuffix = prefix, suffix
+}
+
+// renderPromptAffixes renders the badge above the input saying what is
+// added to each message, or "" when nothing is
+func (c Chat) renderPromptAffixes() string {
+	var parts []string
+	if c.promptPrefix != "" {
+		parts = append(parts, "prefix: "+oneLine(c.promptPrefix))
+	}
+	if c.promptSuffix != "" {
+		parts = append(parts, "suffix: "+oneLine(c.promptSuffix))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color("243")).
+		Italic(true).
+		Render(truncateStage("+ "+strings.Join(parts, " · "), max(c.width-2, 20)))
+}
+
+// oneLine joins text's lines and spaces into one line
+func oneLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// wrapPrompt adds the conversation's prefix and suffix to an outgoing
+// message
+func (m *Model) wrapPrompt(content string) string {
+	settings := m.currentSettings()
+	if settings.Prefix != "" {
+		content = settings.Prefix + "\n\n" + content
+	}
+	if settings.Suffix != "" {
+		content = content + "\n\n" + settings.Suffix
+	}
+	return content
+}
+
+// setPromptAffix sets the current conversation's prefix or suffix for
+// /prefix; "off" removes it
+func (m *Model) setPromptAffix(which, text string) {
+	if text == "off" {
+		text = ""
+	}
+	m.updateSettings(func(s *conversationSettings) {
+		if which == "suffix" {
+			s.Suffix = text
+		} else {
+			s.Prefix = text
+		}
+	})
+	if text == "" {
+		m.statusBar = fmt.Sprintf("Messages in this conversation are sent without a %s", which)
+	} else {
+		m.statusBar = fmt.Sprintf("Each message in this conversation now gets the %s: %s", which, oneLine(text))
+	}
+}
+
+// editPromptAffix puts the current prefix or suffix in the input to edit
+func (m *Model) editPromptAffix(which string) {
+	settings := m.currentSettings()
+	current := settings.Prefix
+	command := "/prefix "
+	if which == "suffix" {
+		current = settings.Suffix
+		command = "/prefix suffix "
+	}
+	m.chat.SetInputValue(command + current)
+	m.focusPane(ChatPane)
+}
+
+// formatPromptAffixes describes the prefix and suffix for /prefix
+func (m *Model) formatPromptAffixes() string {
+	settings := m.currentSettings()
+	if settings.Prefix == "" && settings.Suffix == "" {
+		return "Messages in this conversation are sent as typed.\n/prefix <text> adds text before each one, /prefix suffix <text> after it"
+	}
+	var b strings.Builder
+	b.WriteString("Added to each message in this conversation:\n")
+	if settings.Prefix != "" {
+		fmt.Fprintf(&b, "\nBefore:\n%s\n", settings.Prefix)
+	}
+	if settings.Suffix != "" {
+		fmt.Fprintf(&b, "\nAfter:\n%s\n", settings.Suffix)
+	}
+	b.WriteString("\n/prefix edit or /prefix suffix edit changes one, /prefix off or /prefix suffix off removes it")
+	return b.String()
+}
@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"regexp"
+	"strings"
+)
+
+// LintSeverity indicates how serious a prompt lint issue is
+type LintSeverity int
+
+const (
+	LintHint LintSeverity = iota
+	LintWarning
+)
+
+// LintIssue describes a problem found in a prompt before sending
+type LintIssue struct {
+	Severity LintSeverity
+	Message  string
+}
+
+var (
+	// contextReferencePattern matches prompts that refer to content the model can't see
+	contextReferencePattern = regexp.MustCompile(`(?i)\b(this|that|the following|the above|my|attached)\s+(code|file|function|module|error|stack ?trace|snippet|test|diff|log)\b`)
+	// contextProvidedPattern matches prompts that already carry context
+	contextProvidedPattern = regexp.MustCompile("```|`[^`]+`|\\b[\\w./-]+\\.(ex|exs|go|py|js|ts|rs|rb|json|yml|yaml|md)\\b|\\w+\\.\\w+:\\d+")
+)
+
+// LintPrompt checks a prompt for common mistakes
+func LintPrompt(text string) []LintIssue {
+	var issues []LintIssue
+
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return nil
+	}
+
+	// Slash commands aren't prompts
+	if strings.HasPrefix(trimmed, "/") {
+		return nil
+	}
+
+	// Prompts with nothing but punctuation are effectively empty
+	if strings.Trim(trimmed, "?!.,;:-_ ") == "" {
+		issues = append(issues, LintIssue{Severity: LintWarning, Message: "empty prompt"})
+		return issues
+	}
+
+	// Unclosed code fences make the whole rest of the message a code block
+	fences := 0
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			fences++
+		}
+	}
+	if fences%2 != 0 {
+		issues = append(issues, LintIssue{Severity: LintWarning, Message: "unclosed code fence"})
+	}
+
+	// Referencing code or errors without including them
+	if contextReferencePattern.MatchString(text) && !contextProvidedPattern.MatchString(text) {
+		issues = append(issues, LintIssue{Severity: LintHint, Message: "mentions code/errors but includes none (paste it or attach a file)"})
+	}
+
+	return issues
+}
@@ -0,0 +1,41 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLintPrompt(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"empty", "   ", nil},
+		{"slash command", "/model gpt-4", nil},
+		{"only punctuation", "?!", []string{"empty prompt"}},
+		{"fine prompt", "How do I reverse a list in Elixir?", nil},
+		{"unclosed fence", "Why does this fail?\n```elixir\nEnum.map(list)", []string{"unclosed code fence"}},
+		{"closed fence", "Why does this fail?\n```\nEnum.map(list)\n```", nil},
+		{"missing context", "Why does this code crash?", []string{"mentions code/errors but includes none (paste it or attach a file)"}},
+		{"file named", "Why does this function in lib/app.ex crash?", nil},
+		{"inline code", "What does the error `badarg` mean?", nil},
+		{"location given", "Fix the error at app.ex:12", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, issue := range LintPrompt(tt.text) {
+				got = append(got, issue.Message)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LintPrompt(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+
+	issues := LintPrompt("Explain this error\n```")
+	if len(issues) != 1 || issues[0].Severity != LintWarning {
+		t.Errorf("Expected an unclosed fence to be a warning, got %+v", issues)
+	}
+}
@@ -0,0 +1,125 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// defaultProviderPollInterval is how often the active provider's health is
+// checked unless provider_poll_seconds says otherwise
+const defaultProviderPollInterval = time.Minute
+
+// providerStatusTimeout bounds one health check
+const providerStatusTimeout = 10 * time.Second
+
+// ProviderHealthTickMsg triggers a health check of the active provider
+type ProviderHealthTickMsg time.Time
+
+// ProviderStatusMsg carries the health the server reports for a provider
+type ProviderStatusMsg struct {
+	Status phoenix.ProviderStatus
+	Err    error
+}
+
+// providerPollInterval returns the configured polling interval, 0 when
+// polling is off
+func (m Model) providerPollInterval() time.Duration {
+	switch seconds := m.config.TUI.ProviderPollSeconds; {
+	case seconds < 0:
+		return 0
+	case seconds == 0:
+		return defaultProviderPollInterval
+	default:
+		return time.Duration(seconds) * time.Second
+	}
+}
+
+// watchProviderHealth schedules the next health check
+func (m Model) watchProviderHealth() tea.Cmd {
+	interval := m.providerPollInterval()
+	if interval == 0 {
+		return nil
+	}
+	return tea.Tick(interval, func(t time.Time) tea.Msg {
+		return ProviderHealthTickMsg(t)
+	})
+}
+
+// displayedProvider returns the provider shown in the header, guessed from
+// the model when none is set
+func (m Model) displayedProvider() string {
+	if provider := m.activeProvider(); provider != "" {
+		return provider
+	}
+	return m.getProviderForModel(m.activeModel())
+}
+
+// pollProviderHealth asks the server about the active provider and
+// schedules the next check
+func (m Model) pollProviderHealth() tea.Cmd {
+	next := m.watchProviderHealth()
+	client, ok := m.phoenixClient.(*phoenix.Client)
+	provider := m.displayedProvider()
+	if !ok || !m.connected || !m.capabilities[phoenix.CapabilityProviderStatus] || provider == "" {
+		return next
+	}
+	return tea.Batch(func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), providerStatusTimeout)
+		defer cancel()
+		status, err := client.GetProviderStatus(ctx, provider)
+		return ProviderStatusMsg{Status: status, Err: err}
+	}, next)
+}
+
+// handleProviderStatus updates the header badge and warns in the status bar
+// when the active provider becomes degraded or unavailable
+func (m *Model) handleProviderStatus(msg ProviderStatusMsg) {
+	// A failed check says nothing about the provider; keep the last status.
+	// A status for a provider switched away from in the meantime is stale.
+	if msg.Err != nil || !strings.EqualFold(msg.Status.Provider, m.displayedProvider()) {
+		return
+	}
+
+	previous := m.providerStatus
+	m.providerStatus = msg.Status
+	changed := !strings.EqualFold(previous.Provider, msg.Status.Provider) || previous.Status != msg.Status.Status
+	switch {
+	case !msg.Status.Healthy() && changed:
+		warning := fmt.Sprintf("⚠ %s is %s", msg.Status.Provider, msg.Status.Status)
+		if msg.Status.Reason != "" {
+			warning += " (" + msg.Status.Reason + ")"
+		}
+		m.statusBar = warning + " - " + m.providerFallbackHint()
+	case msg.Status.Healthy() && !previous.Healthy() && strings.EqualFold(previous.Provider, msg.Status.Provider):
+		m.statusBar = fmt.Sprintf("%s is healthy again", msg.Status.Provider)
+	}
+	m.updateHeaderState()
+}
+
+// providerWarning returns the active provider's health when it isn't healthy
+func (m Model) providerWarning() string {
+	if m.providerStatus.Healthy() || !strings.EqualFold(m.providerStatus.Provider, m.displayedProvider()) {
+		return ""
+	}
+	return m.providerStatus.Status
+}
+
+// providerFallbackHint suggests a model from another provider, preferring
+// the fallback chain
+func (m Model) providerFallbackHint() string {
+	current := m.displayedProvider()
+	for _, ref := range m.config.FallbackChain {
+		if !strings.EqualFold(ref.Provider, current) {
+			if m.config.AutoFallback {
+				return fmt.Sprintf("failed requests fall back to %s", ref)
+			}
+			return fmt.Sprintf("switch with /model %s %s", ref.Model, ref.Provider)
+		}
+	}
+	return "pick another model with /models (Alt+M)"
+}
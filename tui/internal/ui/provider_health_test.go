@@ -0,0 +1,39 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rubber_duck/tui/internal/phoenix"
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+func TestProviderStatusWarnsAndSuggestsFallback(t *testing.T) {
+	testutil.IsolateHome(t)
+	model := NewModel()
+	model.config.FallbackChain = []ModelRef{{Provider: "openai", Model: "gpt-4o"}, {Provider: "anthropic", Model: "claude-3-haiku"}}
+	model.currentModel = "gpt-4"
+	model.currentProvider = "openai"
+
+	model.handleProviderStatus(ProviderStatusMsg{Status: phoenix.ProviderStatus{Provider: "openai", Status: phoenix.ProviderDegraded, Reason: "rate limited"}})
+	if !strings.Contains(model.statusBar, "openai is degraded (rate limited)") || !strings.Contains(model.statusBar, "/model claude-3-haiku anthropic") {
+		t.Errorf("Expected a warning suggesting the first other provider, got %q", model.statusBar)
+	}
+	if !strings.Contains(model.chatHeader.View(), "⚠ degraded") {
+		t.Errorf("Expected a header badge, got %q", model.chatHeader.View())
+	}
+
+	// A failed check keeps the badge, and the same status doesn't warn twice
+	model.statusBar = ""
+	model.handleProviderStatus(ProviderStatusMsg{Err: errors.New("timeout")})
+	model.handleProviderStatus(ProviderStatusMsg{Status: phoenix.ProviderStatus{Provider: "openai", Status: phoenix.ProviderDegraded}})
+	if model.statusBar != "" || model.providerWarning() != phoenix.ProviderDegraded {
+		t.Errorf("Expected the badge without a repeated warning, got %q", model.statusBar)
+	}
+
+	model.handleProviderStatus(ProviderStatusMsg{Status: phoenix.ProviderStatus{Provider: "openai", Status: phoenix.ProviderHealthy}})
+	if model.statusBar != "openai is healthy again" || strings.Contains(model.chatHeader.View(), "⚠") {
+		t.Errorf("Expected the badge cleared on recovery, got %q", model.statusBar)
+	}
+}
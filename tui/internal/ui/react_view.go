@@ -0,0 +1,306 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rubber_duck/tui/internal/clock"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// reactCycle is one thought → action → observation turn of a ReAct execution
+type reactCycle struct {
+	Number      int
+	Thought     string
+	Action      string
+	Tool        string
+	Observation string
+	Started     time.Time
+	Ended       time.Time     // When the observation arrived
+	Duration    time.Duration // Server-measured time, summed over the phases
+}
+
+// elapsed returns the cycle's duration, preferring the server's measurement
+func (rc reactCycle) elapsed(now time.Time) time.Duration {
+	if rc.Duration > 0 {
+		return rc.Duration
+	}
+	if rc.Ended.IsZero() {
+		return now.Sub(rc.Started)
+	}
+	return rc.Ended.Sub(rc.Started)
+}
+
+// ReactView follows a ReAct execution live, cycle by cycle
+type ReactView struct {
+	executionID string
+	cycles      []reactCycle
+	state       string // "running", "paused", "completed", "aborted", or "failed"
+	answer      string
+	started     time.Time
+	ended       time.Time
+	scroll      int // Lines scrolled up from the newest
+	visible     bool
+	width       int
+	height      int
+}
+
+// Start begins following a new execution
+func (rv *ReactView) Start(executionID string) {
+	*rv = ReactView{
+		executionID: executionID,
+		state:       "running",
+		started:     clock.Now(),
+		width:       rv.width,
+		height:      rv.height,
+	}
+}
+
+// Show shows the view
+func (rv *ReactView) Show() {
+	rv.visible = true
+	rv.scroll = 0
+}
+
+// Hide hides the view; the execution is still followed
+func (rv *ReactView) Hide() {
+	rv.visible = false
+}
+
+// IsVisible returns whether the view is visible
+func (rv ReactView) IsVisible() bool {
+	return rv.visible
+}
+
+// SetSize updates the view dimensions
+func (rv *ReactView) SetSize(width, height int) {
+	rv.width = width
+	rv.height = height
+}
+
+// Running reports whether the execution hasn't finished
+func (rv ReactView) Running() bool {
+	return rv.state == "running" || rv.state == "paused"
+}
+
+// AddStep records a phase of the loop
+func (rv *ReactView) AddStep(msg phoenix.ReactStepMsg) {
+	now := clock.Now()
+	number := msg.Cycle
+	if number == 0 {
+		// Servers that don't number cycles start one with each thought
+		number = len(rv.cycles)
+		if msg.Phase == "thought" || number == 0 {
+			number++
+		}
+	}
+	if len(rv.cycles) == 0 || rv.cycles[len(rv.cycles)-1].Number != number {
+		if len(rv.cycles) > 0 && rv.cycles[len(rv.cycles)-1].Ended.IsZero() {
+			rv.cycles[len(rv.cycles)-1].Ended = now
+		}
+		rv.cycles = append(rv.cycles, reactCycle{Number: number, Started: now})
+	}
+	cycle := &rv.cycles[len(rv.cycles)-1]
+	cycle.Duration += msg.Duration
+	switch msg.Phase {
+	case "thought":
+		cycle.Thought = msg.Content
+	case "action":
+		cycle.Action = msg.Content
+		cycle.Tool = msg.Tool
+	case "observation":
+		cycle.Observation = msg.Content
+		cycle.Ended = now
+	}
+}
+
+// SetState records the execution pausing, resuming, or finishing
+func (rv *ReactView) SetState(msg phoenix.ReactStateMsg) {
+	rv.state = msg.State
+	if msg.Answer != "" {
+		rv.answer = msg.Answer
+	}
+	if !rv.Running() {
+		rv.ended = clock.Now()
+	}
+}
+
+// Update handles view input
+func (rv ReactView) Update(msg tea.Msg) (ReactView, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || !rv.visible {
+		return rv, nil
+	}
+
+	command := ""
+	switch keyMsg.String() {
+	case "esc", "q":
+		rv.Hide()
+	case "up", "k":
+		rv.scroll++
+	case "down", "j":
+		rv.scroll = max(rv.scroll-1, 0)
+	case "end", "G":
+		rv.scroll = 0
+	case "p", " ":
+		command = "react_pause"
+	case "x":
+		command = "react_abort"
+	case "e":
+		command = "react_export"
+	}
+	if command == "" {
+		return rv, nil
+	}
+	return rv, func() tea.Msg { return ExecuteCommandMsg{Command: command} }
+}
+
+// View renders the loop with the newest cycle at the bottom
+func (rv ReactView) View() string {
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("62"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	cycleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("214"))
+	toolStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("75"))
+	wrap := lipgloss.NewStyle().Width(max(rv.width-8, 20))
+
+	now := clock.Now()
+	end := rv.ended
+	if end.IsZero() {
+		end = now
+	}
+	status := fmt.Sprintf("%s · %d cycles · %s", rv.state, len(rv.cycles), formatLatency(end.Sub(rv.started)))
+	header := []string{titleStyle.Render("ReAct execution " + rv.executionID), dimStyle.Render(status), ""}
+
+	var body []string
+	for i, cycle := range rv.cycles {
+		timing := formatLatency(cycle.elapsed(now))
+		if cycle.Ended.IsZero() && i == len(rv.cycles)-1 && rv.Running() {
+			timing += "…"
+		}
+		body = append(body, cycleStyle.Render(fmt.Sprintf("Cycle %d", cycle.Number))+dimStyle.Render("  "+timing))
+		phase := func(label, text string) {
+			if text != "" {
+				body = append(body, strings.Split(wrap.Render(label+" "+text), "\n")...)
+			}
+		}
+		phase("💭 Thought:", cycle.Thought)
+		if cycle.Tool != "" {
+			phase("🔧 Action:", toolStyle.Render(cycle.Tool)+" "+cycle.Action)
+		} else {
+			phase("🔧 Action:", cycle.Action)
+		}
+		phase("👁  Observation:", cycle.Observation)
+		body = append(body, "")
+	}
+	if rv.answer != "" {
+		body = append(body, strings.Split(wrap.Render("✅ Answer: "+rv.answer), "\n")...)
+	}
+	if len(body) == 0 {
+		body = []string{dimStyle.Render("Waiting for the first thought...")}
+	}
+
+	// Follow the newest lines unless scrolled up
+	room := max(rv.height-len(header)-2, 3)
+	last := max(len(body)-rv.scroll, min(room, len(body)))
+	body = body[max(last-room, 0):last]
+
+	footer := "p: Pause/resume | x: Abort | e: Export trace | ↑/↓: Scroll | Esc: Hide"
+	if !rv.Running() {
+		footer = "e: Export trace | ↑/↓: Scroll | Esc: Close"
+	}
+	lines := append(append(header, body...), dimStyle.Render(footer))
+	return lipgloss.NewStyle().Padding(0, 1).Render(strings.Join(lines, "\n"))
+}
+
+// exportMarkdown renders the execution as a Markdown trace
+func (rv ReactView) exportMarkdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# ReAct execution %s\n\n", rv.executionID)
+	fmt.Fprintf(&b, "- State: %s\n- Started: %s\n- Cycles: %d\n", rv.state, rv.started.Format(time.RFC3339), len(rv.cycles))
+	if !rv.ended.IsZero() {
+		fmt.Fprintf(&b, "- Duration: %s\n", formatLatency(rv.ended.Sub(rv.started)))
+	}
+	for _, cycle := range rv.cycles {
+		fmt.Fprintf(&b, "\n## Cycle %d (%s)\n\n", cycle.Number, formatLatency(cycle.elapsed(rv.ended)))
+		fmt.Fprintf(&b, "**Thought:** %s\n\n", cycle.Thought)
+		if cycle.Tool != "" {
+			fmt.Fprintf(&b, "**Action:** `%s` %s\n\n", cycle.Tool, cycle.Action)
+		} else {
+			fmt.Fprintf(&b, "**Action:** %s\n\n", cycle.Action)
+		}
+		fmt.Fprintf(&b, "**Observation:** %s\n", cycle.Observation)
+	}
+	if rv.answer != "" {
+		fmt.Fprintf(&b, "\n## Answer\n\n%s\n", rv.answer)
+	}
+	return b.String()
+}
+
+// handleReactStep follows a ReAct execution, opening the view when one starts
+func (m *Model) handleReactStep(msg phoenix.ReactStepMsg) {
+	if msg.ExecutionID != m.reactView.executionID {
+		m.reactView.Start(msg.ExecutionID)
+		m.reactView.Show()
+	}
+	m.reactView.AddStep(msg)
+}
+
+// handleReactState reports a ReAct execution pausing, resuming, or finishing
+func (m *Model) handleReactState(msg phoenix.ReactStateMsg) {
+	if msg.ExecutionID != m.reactView.executionID {
+		return
+	}
+	m.reactView.SetState(msg)
+	switch msg.State {
+	case "paused":
+		m.statusBar = "ReAct execution paused - p resumes"
+	case "running":
+		m.statusBar = "ReAct execution resumed"
+	default:
+		m.statusBar = fmt.Sprintf("ReAct execution %s after %d cycles - /react export saves the trace", msg.State, len(m.reactView.cycles))
+	}
+}
+
+// controlReact pauses, resumes, or aborts the followed execution
+func (m *Model) controlReact(action string) tea.Cmd {
+	if !m.reactView.Running() {
+		m.statusMessages.AddMessage(StatusCategoryError, "No ReAct execution is running", nil)
+		return nil
+	}
+	client, ok := m.phoenixClient.(*phoenix.Client)
+	if !ok || !m.connected || !m.capabilities[phoenix.CapabilityReact] {
+		m.statusMessages.AddMessage(StatusCategoryError, "The server can't pause or abort ReAct executions", nil)
+		return nil
+	}
+	if action == "pause" && m.reactView.state == "paused" {
+		action = "resume"
+	}
+	return client.ControlReact(m.reactView.executionID, action)
+}
+
+// exportReactTrace writes the followed execution to a Markdown file
+func (m *Model) exportReactTrace() {
+	if m.reactView.executionID == "" {
+		m.statusMessages.AddMessage(StatusCategoryError, "No ReAct execution to export", nil)
+		return
+	}
+	name := fmt.Sprintf("react-%s.md", m.reactView.executionID)
+	if m.checkPermission(permissionRequest{
+		Action: permissionExportTrace,
+		Title:  "write the ReAct trace",
+		Detail: "Write the execution's trace to this local file:\n\n  " + name,
+	}, ExecuteCommandMsg{Command: "react_export"}) != permissionAllowed {
+		return
+	}
+	path := m.projectFile(name)
+	if err := os.WriteFile(path, []byte(m.reactView.exportMarkdown()), 0644); err != nil {
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Cannot write trace: %v", err), nil)
+		return
+	}
+	m.chat.AddMessage(SystemMessage, "ReAct trace written to:\n"+path, "system")
+	m.statusBar = "Trace exported"
+}
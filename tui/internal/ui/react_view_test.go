@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rubber_duck/tui/internal/clock"
+	"github.com/rubber_duck/tui/internal/phoenix"
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+func TestReactViewFollowsCycles(t *testing.T) {
+	testutil.IsolateHome(t)
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	restore := clock.Freeze(start)
+	defer restore()
+
+	model := NewModel()
+	model.reactView.SetSize(100, 40)
+	model.handleReactStep(phoenix.ReactStepMsg{ExecutionID: "r1", Cycle: 1, Phase: "thought", Content: "Look for the handler"})
+	if !model.reactView.IsVisible() {
+		t.Fatal("Expected the view to open when an execution starts")
+	}
+	model.handleReactStep(phoenix.ReactStepMsg{ExecutionID: "r1", Cycle: 1, Phase: "action", Tool: "search_code", Content: "handler"})
+	clock.Freeze(start.Add(1500 * time.Millisecond))
+	model.handleReactStep(phoenix.ReactStepMsg{ExecutionID: "r1", Cycle: 1, Phase: "observation", Content: "2 matches"})
+	model.handleReactStep(phoenix.ReactStepMsg{ExecutionID: "r1", Cycle: 2, Phase: "thought", Content: "Read the first match"})
+
+	view := model.reactView.View()
+	for _, want := range []string{"Cycle 1", "1.5s", "search_code", "2 matches", "Cycle 2", "running · 2 cycles"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected %q in the view, got:\n%s", want, view)
+		}
+	}
+
+	// States of another execution are ignored
+	model.handleReactState(phoenix.ReactStateMsg{ExecutionID: "other", State: "aborted"})
+	model.handleReactState(phoenix.ReactStateMsg{ExecutionID: "r1", State: "completed", Answer: "It's in router.ex"})
+	if model.reactView.Running() {
+		t.Fatal("Expected the execution to have finished")
+	}
+	trace := model.reactView.exportMarkdown()
+	if !strings.Contains(trace, "## Cycle 1 (1.5s)") || !strings.Contains(trace, "**Action:** `search_code` handler") || !strings.Contains(trace, "It's in router.ex") {
+		t.Errorf("Unexpected trace:\n%s", trace)
+	}
+}
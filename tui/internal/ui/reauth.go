@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// revokedCredential names the credential the server stopped accepting
+func (m Model) revokedCredential(credential string) string {
+	switch credential {
+	case "api_key":
+		return "API key"
+	case "token":
+		return "session"
+	}
+	// A session started from an API key ends with it
+	if m.apiKey != "" {
+		return "API key"
+	}
+	return "session"
+}
+
+// credentialsRevoked signs out after the server stopped accepting the
+// session's API key or token. Reconnecting with them can only fail again,
+// so they are forgotten, the user socket is closed instead of retried, and
+// the login form is offered
+func (m *Model) credentialsRevoked(credential, reason string) tea.Cmd {
+	if m.reauthRequired {
+		return nil
+	}
+	m.reauthRequired = true
+	what := m.revokedCredential(credential)
+	username := m.username
+
+	m.authenticated = false
+	m.jwtToken = ""
+	m.saveKeyAfterLogin = false
+	m.switchingSocket = false
+	m.applyGrants(phoenix.AuthUser{})
+	// Connection failures so far were the credentials, not the server
+	m.totalConnectionAttempts = 0
+	m.connectionBlocked = false
+	m.errorHandler.Reset()
+	m.leaveOffline()
+
+	if revoked := m.apiKey; revoked != "" {
+		m.apiKey = ""
+		if m.config != nil && m.config.APIKey == revoked {
+			m.config.APIKey = ""
+			if err := SaveConfig(m.config); err != nil {
+				m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Failed to remove the revoked API key from the config: %v", err), nil)
+			}
+		}
+	}
+	m.updateHeaderState()
+
+	explanation := fmt.Sprintf("Your %s was revoked or has expired, so the server signed you out.", what)
+	if reason != "" {
+		explanation += "\nServer: " + reason
+	}
+	if what == "API key" {
+		explanation += "\n\nThe key was removed from the config. Log in with your password, then /apikey generate makes a new one."
+	} else {
+		explanation += "\n\nLog in again to continue."
+	}
+	m.statusBar = fmt.Sprintf("Signed out: %s revoked - log in again", what)
+	m.chat.AddMessage(SystemMessage, explanation, "system")
+	m.modal.ShowConfirm("Signed out", explanation+"\n\nLog in with your password now?", func() tea.Msg {
+		return ExecuteCommandMsg{Command: "login_form", Args: map[string]string{"username": username}}
+	})
+
+	var cmds []tea.Cmd
+	if client, ok := m.phoenixClient.(*phoenix.Client); ok {
+		// Closing waits for the server, so it runs off the update loop
+		cmds = append(cmds, func() tea.Msg {
+			client.Channels().Close()
+			return nil
+		})
+	}
+	// Logging in needs the auth socket, which may have gone down too
+	if m.authSocket == nil || !m.authSocket.IsConnected() {
+		cmds = append(cmds, func() tea.Msg { return InitiateConnectionMsg{} })
+	}
+	return tea.Batch(cmds...)
+}
@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/phoenix"
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+func TestRevokedCredentialsSignOut(t *testing.T) {
+	testutil.IsolateHome(t)
+	model := NewModel()
+	model.config.APIKey = "rd_revoked"
+	model.apiKey = "rd_revoked"
+	model.authenticated = true
+	model.username = "alice"
+	model.jwtToken = "jwt"
+	model.totalConnectionAttempts = 4
+
+	revoked := phoenix.DisconnectedMsg{
+		Error:      phoenix.Errorf(phoenix.ErrCredentialsRevoked, "the server refused the socket's credentials (HTTP 403)"),
+		SocketType: phoenix.UserSocketType,
+	}
+	updated, _ := model.Update(revoked)
+	*model = updated.(Model)
+
+	if model.authenticated || model.jwtToken != "" || model.apiKey != "" || model.offline {
+		t.Errorf("Expected signed out without going offline, got authenticated=%v token=%q key=%q offline=%v", model.authenticated, model.jwtToken, model.apiKey, model.offline)
+	}
+	if model.totalConnectionAttempts != 0 || model.connectionBlocked {
+		t.Error("Expected the failed attempts forgotten")
+	}
+	if loaded, err := LoadConfig(); err != nil || loaded.APIKey != "" {
+		t.Errorf("Expected the revoked key removed from the config, got %q (%v)", loaded.APIKey, err)
+	}
+	last := model.chat.messages[len(model.chat.messages)-1].Content
+	if !strings.Contains(last, "API key was revoked") {
+		t.Errorf("Expected an explanation in the chat, got %q", last)
+	}
+
+	// A second failure doesn't stack prompts
+	updated, cmd := model.Update(revoked)
+	*model = updated.(Model)
+	if cmd != nil {
+		t.Error("Expected repeated revocations ignored until the next login")
+	}
+
+	// Accepting the prompt opens the login form with the username
+	updated, cmd = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	*model = updated.(Model)
+	if cmd == nil {
+		t.Fatal("Expected the prompt to offer the login form")
+	}
+	updated, _ = model.Update(cmd())
+	*model = updated.(Model)
+	if !model.accountModal.IsVisible() || model.accountModal.Form() != LoginForm || model.accountModal.value("username") != "alice" {
+		t.Error("Expected the login form with the username filled in")
+	}
+}
+
+func TestRefusedAPIKeyKeptUnlessRevoked(t *testing.T) {
+	testutil.IsolateHome(t)
+	model := NewModel()
+	model.config.APIKey = "rd_key"
+	model.apiKey = "rd_key"
+	if err := SaveConfig(model.config); err != nil {
+		t.Fatal(err)
+	}
+
+	// A refusal without a revocation reason may be the server's fault
+	updated, _ := model.Update(phoenix.LoginErrorMsg{Message: "Authentication service unavailable", APIKey: true})
+	*model = updated.(Model)
+	if model.apiKey != "rd_key" || model.reauthRequired {
+		t.Error("Expected the key kept after a refusal that isn't a revocation")
+	}
+	if loaded, err := LoadConfig(); err != nil || loaded.APIKey != "rd_key" {
+		t.Errorf("Expected the key left in the config, got %q (%v)", loaded.APIKey, err)
+	}
+
+	updated, _ = model.Update(phoenix.LoginErrorMsg{Message: "API key revoked", APIKey: true, Revoked: true})
+	*model = updated.(Model)
+	if model.apiKey != "" || !model.reauthRequired {
+		t.Error("Expected a revoked key to sign out")
+	}
+}
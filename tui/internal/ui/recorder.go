@@ -0,0 +1,475 @@
+package ui
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// recordingVersion is the format version written to recording headers
+const recordingVersion = 1
+
+// redacted replaces secrets in recordings
+const redacted = "[REDACTED]"
+
+// secretFieldPattern matches field names whose string values are never recorded
+var secretFieldPattern = regexp.MustCompile(`(?i)^(password|passwd|secret|token|access_token|refresh_token|api_?key|key|pin_?hash)$`)
+
+var (
+	errorType = reflect.TypeOf((*error)(nil)).Elem()
+	timeType  = reflect.TypeOf(time.Time{})
+)
+
+// replayableMsgs lists the message types that can be read back from a
+// recording; types holding live connections or commands are recorded by name only
+var replayableMsgs = registerReplayable(
+	tea.KeyMsg{}, tea.MouseMsg{}, tea.WindowSizeMsg{},
+	// UI messages
+	FileSelectedMsg{}, EditorUpdateMsg{}, ChatMessageSentMsg{}, ChatMessageReceivedMsg{},
+	ExecuteCommandMsg{}, ShowModalMsg{}, CopyToClipboardMsg{}, ToggleMouseModeMsg{},
+	CancelRequestMsg{}, ProcessingCancelledMsg{}, OfflineProbeMsg{},
+	SymbolSelectedMsg{}, SearchRequestMsg{}, SearchResultsMsg{}, ErrorMsg{},
+	MessagePinnedMsg{}, ConversationActionMsg{}, LoadOlderHistoryMsg{}, ApplyHunksMsg{},
+	ApplyNextChangeMsg{}, PullRequestCreatedMsg{}, ModelsListedMsg{}, AskSelectionMsg{},
+	CommandBlockedMsg{}, AttachmentUploadedMsg{}, ClearCacheMsg{},
+	ProviderHealthTickMsg{}, ProviderStatusMsg{}, FileSaveResultMsg{}, ProviderSwitchResultMsg{},
+	IdleLockTickMsg{}, UnlockSubmitMsg{}, UnlockResultMsg{}, PermissionDecisionMsg{}, macroKeyMsg{},
+	PasteCheckedMsg{}, StackTracePastedMsg{}, AttachTraceFilesMsg{}, HintSelectedMsg{}, CopyMessageMsg{}, ReplyToMessageMsg{}, EmojiPickedMsg{}, StatusColorsSavedMsg{}, StatusSubscriptionToggledMsg{},
+	APIKeyGenerateSubmitMsg{},
+	// Server messages
+	phoenix.ConnectedMsg{}, phoenix.DisconnectedMsg{}, phoenix.ChannelJoiningMsg{}, phoenix.ErrorMsg{},
+	phoenix.ServerFingerprintMsg{},
+	phoenix.ConversationResponseMsg{}, phoenix.ConversationThinkingMsg{}, phoenix.ConversationQueuedMsg{},
+	phoenix.ConversationContextUpdatedMsg{}, phoenix.ProcessingCancelledMsg{}, phoenix.FileChangedMsg{},
+	phoenix.ProviderErrorMsg{}, phoenix.ConversationResetMsg{}, phoenix.ConversationHistoryMsg{},
+	phoenix.ReactStepMsg{}, phoenix.ReactStateMsg{}, phoenix.TransferProgressMsg{}, phoenix.CommandQueueMsg{},
+	phoenix.ConversationArchivedMsg{}, phoenix.ConversationDeletedMsg{}, phoenix.ConversationResumedMsg{},
+	phoenix.StreamStartMsg{}, phoenix.StreamDataMsg{}, phoenix.StreamEndMsg{},
+	phoenix.StatusChannelJoinedMsg{}, phoenix.StatusCategoriesSubscribedMsg{}, phoenix.StatusSubscriptionsMsg{},
+	phoenix.StatusUpdateMsg{}, phoenix.PlanningStartedMsg{}, phoenix.PlanningStepMsg{},
+	phoenix.PlanningCompletedMsg{}, phoenix.PlanningErrorMsg{}, phoenix.PlanningCancelledMsg{},
+	phoenix.AuthConnectedMsg{}, phoenix.AuthChannelJoinedMsg{}, phoenix.LoginSuccessMsg{},
+	phoenix.LoginErrorMsg{}, phoenix.CredentialsRevokedMsg{}, phoenix.LogoutSuccessMsg{}, phoenix.LogoutErrorMsg{}, phoenix.AuthStatusMsg{},
+	phoenix.APIKeyGeneratedMsg{}, phoenix.APIKeyListMsg{}, phoenix.APIKeyUsageMsg{}, phoenix.APIKeyRevokedMsg{},
+	phoenix.APIKeyErrorMsg{}, phoenix.TokenRefreshedMsg{}, phoenix.TokenErrorMsg{}, phoenix.OllamaModelsMsg{},
+	phoenix.PayloadDiagnosticMsg{}, phoenix.UnroutedEventMsg{}, phoenix.RequestSentMsg{}, phoenix.SessionListMsg{},
+	phoenix.SessionRevokedMsg{}, phoenix.SessionErrorMsg{},
+	phoenix.ProviderCredentialsMsg{}, phoenix.ProviderCredentialsSavedMsg{}, phoenix.ProviderCredentialsRemovedMsg{},
+	phoenix.ProviderCredentialsTestedMsg{}, phoenix.ProviderCredentialsErrorMsg{},
+	phoenix.RegisterSuccessMsg{}, phoenix.RegisterErrorMsg{}, phoenix.PasswordChangedMsg{}, phoenix.PasswordChangeErrorMsg{},
+	phoenix.AdminChannelJoinedMsg{}, phoenix.AdminStatsMsg{}, phoenix.AdminActionMsg{}, phoenix.AdminErrorMsg{},
+	phoenix.IndexChannelJoinedMsg{}, phoenix.IndexProgressMsg{}, phoenix.IndexStatusMsg{}, phoenix.IndexErrorMsg{},
+)
+
+// registerReplayable indexes message types by their recorded name
+func registerReplayable(msgs ...tea.Msg) map[string]reflect.Type {
+	types := make(map[string]reflect.Type, len(msgs))
+	for _, msg := range msgs {
+		types[msgTypeName(msg)] = reflect.TypeOf(msg)
+	}
+	return types
+}
+
+// msgTypeName returns the name a message is recorded under
+func msgTypeName(msg tea.Msg) string {
+	return fmt.Sprintf("%T", msg)
+}
+
+// recordingHeader is the first line of a recording file
+type recordingHeader struct {
+	Version   int       `json:"version"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// RecordedMsg is one message in a recording
+type RecordedMsg struct {
+	AtMS    int64                      `json:"at_ms"`
+	Type    string                     `json:"type"`
+	Fields  map[string]json.RawMessage `json:"fields,omitempty"`
+	Skipped bool                       `json:"skipped,omitempty"`
+}
+
+// encodeMsg converts a message into its sanitized recorded form
+func encodeMsg(msg tea.Msg) RecordedMsg {
+	recorded := RecordedMsg{Type: msgTypeName(msg)}
+	t, ok := replayableMsgs[recorded.Type]
+	if !ok {
+		recorded.Skipped = true
+		return recorded
+	}
+
+	value := reflect.ValueOf(msg)
+	if t.Kind() != reflect.Struct || t.ConvertibleTo(timeType) {
+		recorded.Fields = map[string]json.RawMessage{"value": encodeValue("value", value)}
+		return recorded
+	}
+
+	recorded.Fields = make(map[string]json.RawMessage)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		recorded.Fields[field.Name] = encodeValue(field.Name, value.Field(i))
+	}
+	return recorded
+}
+
+// encodeValue marshals a field, recording errors as their message and
+// redacting secrets
+func encodeValue(name string, value reflect.Value) json.RawMessage {
+	var data any
+	switch {
+	case value.Type() == errorType:
+		if value.IsNil() {
+			return json.RawMessage("null")
+		}
+		data = value.Interface().(error).Error()
+	case value.Type() != timeType && value.Type().ConvertibleTo(timeType):
+		data = value.Convert(timeType).Interface()
+	case value.Kind() == reflect.Func || value.Kind() == reflect.Chan:
+		return json.RawMessage("null")
+	default:
+		data = value.Interface()
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return json.RawMessage("null")
+	}
+	var generic any
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		return raw
+	}
+	raw, _ = json.Marshal(redact(name, generic))
+	return raw
+}
+
+// redact replaces string values stored under secret field names
+func redact(name string, value any) any {
+	switch v := value.(type) {
+	case string:
+		if secretFieldPattern.MatchString(name) && v != "" {
+			return redacted
+		}
+	case map[string]any:
+		for key, item := range v {
+			v[key] = redact(key, item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redact(name, item)
+		}
+	}
+	return value
+}
+
+// decodeMsg rebuilds a message from its recorded form
+func decodeMsg(recorded RecordedMsg) (tea.Msg, error) {
+	t, ok := replayableMsgs[recorded.Type]
+	if !ok || recorded.Skipped {
+		return nil, fmt.Errorf("%s is not replayable", recorded.Type)
+	}
+
+	value := reflect.New(t).Elem()
+	if t.Kind() != reflect.Struct || t.ConvertibleTo(timeType) {
+		if err := decodeValue(recorded.Fields["value"], value); err != nil {
+			return nil, fmt.Errorf("%s: %w", recorded.Type, err)
+		}
+		return value.Interface(), nil
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		raw, ok := recorded.Fields[field.Name]
+		if !field.IsExported() || !ok {
+			continue
+		}
+		if err := decodeValue(raw, value.Field(i)); err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", recorded.Type, field.Name, err)
+		}
+	}
+	return value.Interface(), nil
+}
+
+// decodeValue unmarshals a recorded field into value
+func decodeValue(raw json.RawMessage, value reflect.Value) error {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	switch {
+	case value.Type() == errorType:
+		var message string
+		if err := json.Unmarshal(raw, &message); err != nil {
+			return err
+		}
+		value.Set(reflect.ValueOf(errors.New(message)))
+		return nil
+	case value.Type() != timeType && value.Type().ConvertibleTo(timeType):
+		var t time.Time
+		if err := json.Unmarshal(raw, &t); err != nil {
+			return err
+		}
+		value.Set(reflect.ValueOf(t).Convert(value.Type()))
+		return nil
+	case value.Kind() == reflect.Func || value.Kind() == reflect.Chan:
+		return nil
+	}
+	return json.Unmarshal(raw, value.Addr().Interface())
+}
+
+// Recorder writes the message traffic of a session to a file
+type Recorder struct {
+	mu      sync.Mutex
+	file    *os.File
+	writer  *bufio.Writer
+	started time.Time
+	secrets []string
+}
+
+// NewRecorder creates a recording file; occurrences of secrets are redacted
+func NewRecorder(path string, secrets ...string) (*Recorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Recorder{
+		file:    file,
+		writer:  bufio.NewWriter(file),
+		started: time.Now(),
+	}
+	// Short values would redact unrelated text
+	for _, secret := range secrets {
+		if len(secret) >= 8 {
+			r.secrets = append(r.secrets, secret)
+		}
+	}
+
+	header, _ := json.Marshal(recordingHeader{Version: recordingVersion, StartedAt: r.started})
+	r.writer.Write(append(header, '\n'))
+	return r, nil
+}
+
+// Record appends a message to the recording
+func (r *Recorder) Record(msg tea.Msg) {
+	recorded := encodeMsg(msg)
+	recorded.AtMS = time.Since(r.started).Milliseconds()
+	line, err := json.Marshal(recorded)
+	if err != nil {
+		return
+	}
+	text := string(line)
+	for _, secret := range r.secrets {
+		text = strings.ReplaceAll(text, secret, redacted)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.writer.WriteString(text + "\n")
+}
+
+// Close flushes and closes the recording
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.writer.Flush(); err != nil {
+		r.file.Close()
+		return err
+	}
+	return r.file.Close()
+}
+
+// LoadRecording reads a recording file
+func LoadRecording(path string) ([]RecordedMsg, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	if !scanner.Scan() {
+		return nil, fmt.Errorf("%s: empty recording", path)
+	}
+	var header recordingHeader
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil || header.Version == 0 {
+		return nil, fmt.Errorf("%s: not a recording", path)
+	}
+	if header.Version > recordingVersion {
+		return nil, fmt.Errorf("%s: unsupported recording version %d", path, header.Version)
+	}
+
+	var msgs []RecordedMsg
+	for scanner.Scan() {
+		var recorded RecordedMsg
+		if err := json.Unmarshal(scanner.Bytes(), &recorded); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		msgs = append(msgs, recorded)
+	}
+	return msgs, scanner.Err()
+}
+
+// secretInput is a model that can tell when keys typed into it are secret
+type secretInput interface {
+	SecretInputFocused() bool
+}
+
+// SecretInputFocused reports whether keys go to a masked field, such as a
+// password, an API key, or the lock screen
+func (m Model) SecretInputFocused() bool {
+	if m.lockScreen.IsVisible() {
+		return true
+	}
+	return m.accountModal.IsVisible() && m.accountModal.SecretFocused()
+}
+
+// maskKey replaces the runes of a key typed into a secret field
+func maskKey(msg tea.KeyMsg) tea.KeyMsg {
+	if len(msg.Runes) > 0 {
+		msg.Runes = []rune(strings.Repeat("•", len(msg.Runes)))
+	}
+	return msg
+}
+
+// RecordingModel records every message before passing it to the wrapped model
+type RecordingModel struct {
+	model    tea.Model
+	recorder *Recorder
+}
+
+// NewRecordingModel wraps a model with a recorder
+func NewRecordingModel(model tea.Model, recorder *Recorder) RecordingModel {
+	return RecordingModel{model: model, recorder: recorder}
+}
+
+// Init initializes the wrapped model
+func (rm RecordingModel) Init() tea.Cmd {
+	return rm.model.Init()
+}
+
+// Update records the message and updates the wrapped model
+func (rm RecordingModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	recorded := msg
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		if secret, ok := rm.model.(secretInput); ok && secret.SecretInputFocused() {
+			recorded = maskKey(keyMsg)
+		}
+	}
+	rm.recorder.Record(recorded)
+	var cmd tea.Cmd
+	rm.model, cmd = rm.model.Update(msg)
+	return rm, cmd
+}
+
+// View renders the wrapped model
+func (rm RecordingModel) View() string {
+	return rm.model.View()
+}
+
+// replayStepMsg delivers the next recorded message
+type replayStepMsg struct {
+	index int
+}
+
+// ReplayModel drives a model from a recording instead of live input; commands
+// returned by the model are dropped since their results are in the recording
+type ReplayModel struct {
+	model   tea.Model
+	msgs    []RecordedMsg
+	speed   float64
+	skipped int
+	done    bool
+	err     string
+}
+
+// NewReplayModel creates a model that replays msgs at the given speed
+func NewReplayModel(model tea.Model, msgs []RecordedMsg, speed float64) ReplayModel {
+	if speed <= 0 {
+		speed = 1
+	}
+	return ReplayModel{model: model, msgs: msgs, speed: speed}
+}
+
+// Init starts the replay
+func (rp ReplayModel) Init() tea.Cmd {
+	return rp.schedule(0)
+}
+
+// schedule delivers recorded message index after its recorded delay
+func (rp ReplayModel) schedule(index int) tea.Cmd {
+	if index >= len(rp.msgs) {
+		return nil
+	}
+	var delay time.Duration
+	if index > 0 {
+		delay = time.Duration(float64(rp.msgs[index].AtMS-rp.msgs[index-1].AtMS)/rp.speed) * time.Millisecond
+	}
+	return tea.Tick(delay, func(time.Time) tea.Msg {
+		return replayStepMsg{index: index}
+	})
+}
+
+// Update feeds recorded messages to the model; live input only quits
+func (rp ReplayModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case replayStepMsg:
+		rp.step(rp.msgs[msg.index])
+		if msg.index == len(rp.msgs)-1 {
+			rp.done = true
+			return rp, nil
+		}
+		if rp.err != "" {
+			return rp, nil
+		}
+		return rp, rp.schedule(msg.index + 1)
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "ctrl+q":
+			return rp, tea.Quit
+		}
+	}
+	// Live terminal events (including resizes) would make the replay
+	// nondeterministic, so the recorded ones are used instead
+	return rp, nil
+}
+
+// step applies one recorded message, stopping the replay if the model panics
+func (rp *ReplayModel) step(recorded RecordedMsg) {
+	msg, err := decodeMsg(recorded)
+	if err != nil {
+		rp.skipped++
+		return
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			rp.err = fmt.Sprintf("replay stopped at %s (%dms): %v", recorded.Type, recorded.AtMS, r)
+			rp.done = true
+		}
+	}()
+	rp.model, _ = rp.model.Update(msg)
+}
+
+// View renders the model with the replay state
+func (rp ReplayModel) View() string {
+	view := rp.model.View()
+	switch {
+	case rp.err != "":
+		return view + "\n" + rp.err + " - Ctrl+C to quit"
+	case rp.done:
+		return view + fmt.Sprintf("\nReplay finished (%d messages, %d not replayable) - Ctrl+C to quit", len(rp.msgs), rp.skipped)
+	}
+	return view
+}
@@ -0,0 +1,121 @@
+package ui
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/phoenix"
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+func TestRecorderRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	recorder, err := NewRecorder(path, "sk-secret-api-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tick := IdleLockTickMsg(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	msgs := []tea.Msg{
+		tea.WindowSizeMsg{Width: 120, Height: 40},
+		tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("hi")},
+		phoenix.StreamDataMsg{ID: "1", Data: "chunk"},
+		phoenix.ErrorMsg{Err: errors.New("boom"), Component: "Phoenix"},
+		tick,
+	}
+	for _, msg := range msgs {
+		recorder.Record(msg)
+	}
+	// Secrets are redacted by field name and by value
+	recorder.Record(phoenix.LoginSuccessMsg{User: phoenix.AuthUser{Username: "duck"}, Token: "jwt-token-value"})
+	recorder.Record(ChatMessageReceivedMsg{Content: "key is sk-secret-api-key", Type: "assistant"})
+	// Messages holding live connections are recorded by name only
+	recorder.Record(phoenix.SocketCreatedMsg{})
+	if err := recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "jwt-token-value") || strings.Contains(string(data), "sk-secret-api-key") {
+		t.Errorf("Recording contains secrets:\n%s", data)
+	}
+
+	recorded, err := LoadRecording(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recorded) != len(msgs)+3 {
+		t.Fatalf("Expected %d recorded messages, got %d", len(msgs)+3, len(recorded))
+	}
+
+	for i, want := range msgs[:3] {
+		got, err := decodeMsg(recorded[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Message %d: expected %#v, got %#v", i, want, got)
+		}
+	}
+
+	got, err := decodeMsg(recorded[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errMsg, ok := got.(phoenix.ErrorMsg); !ok || errMsg.Err == nil || errMsg.Err.Error() != "boom" {
+		t.Errorf("Expected error message to survive the recording, got %#v", got)
+	}
+
+	got, err = decodeMsg(recorded[4])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !time.Time(got.(IdleLockTickMsg)).Equal(time.Time(tick)) {
+		t.Errorf("Expected tick %v, got %v", time.Time(tick), got)
+	}
+
+	if !recorded[7].Skipped {
+		t.Errorf("Expected %s to be skipped", recorded[7].Type)
+	}
+	if _, err := decodeMsg(recorded[7]); err == nil {
+		t.Error("Expected skipped message to be unreplayable")
+	}
+}
+
+func TestRecorderMasksSecretInput(t *testing.T) {
+	testutil.IsolateHome(t)
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	recorder, err := NewRecorder(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var model tea.Model = NewRecordingModel(NewCrashGuard(NewModel()), recorder)
+	msgs := []tea.Msg{
+		tea.WindowSizeMsg{Width: 120, Height: 40},
+		ExecuteCommandMsg{Command: "login_form", Args: map[string]string{"username": "duck"}},
+		tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("hunter2pw")},
+	}
+	for _, msg := range msgs {
+		model, _ = model.Update(msg)
+	}
+	// The model still gets the real keys
+	inner := model.(RecordingModel).model.(CrashGuard).model.(Model)
+	if got := inner.accountModal.value("password"); got != "hunter2pw" {
+		t.Errorf("Expected the password field to hold the typed password, got %q", got)
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "hunter2pw") {
+		t.Errorf("Recording contains the typed password:\n%s", data)
+	}
+}
@@ -0,0 +1,162 @@
+package ui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// maxRecycledConversations is how many cleared conversations /undo-clear
+// can bring back
+const maxRecycledConversations = 5
+
+// errNothingToUndo is returned when no cleared conversation is kept
+var errNothingToUndo = errors.New("nothing to undo: no cleared conversations")
+
+// recycleDir returns the directory cleared conversations are kept in
+func recycleDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".rubber_duck", "recycle"), nil
+}
+
+// recycledFiles lists the recycle area's files, oldest first
+func recycledFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".json") {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	// Names are zero-padded timestamps, so they sort by age
+	sort.Strings(files)
+	return files, nil
+}
+
+// recycleConversation keeps a cleared conversation, dropping the oldest
+// beyond maxRecycledConversations
+func recycleConversation(conv SavedConversation) error {
+	dir, err := recycleDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(conv)
+	if err != nil {
+		return err
+	}
+	if data, err = sealTranscript(data); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%020d.json", time.Now().UnixNano())
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+		return err
+	}
+
+	files, err := recycledFiles(dir)
+	if err != nil {
+		return err
+	}
+	for len(files) > maxRecycledConversations {
+		os.Remove(files[0])
+		files = files[1:]
+	}
+	return nil
+}
+
+// popRecycledConversation removes and returns the most recently cleared
+// conversation
+func popRecycledConversation() (*SavedConversation, error) {
+	dir, err := recycleDir()
+	if err != nil {
+		return nil, err
+	}
+	files, err := recycledFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, errNothingToUndo
+	}
+
+	newest := files[len(files)-1]
+	data, err := os.ReadFile(newest)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = openTranscript(data); err != nil {
+		return nil, fmt.Errorf("cleared conversation is unreadable: %w", err)
+	}
+	var conv SavedConversation
+	if err := json.Unmarshal(data, &conv); err != nil {
+		return nil, fmt.Errorf("cleared conversation is unreadable: %w", err)
+	}
+	os.Remove(newest)
+	return &conv, nil
+}
+
+// recycleChat keeps the chat in the recycle area before it is cleared,
+// leaving out incognito messages
+func (m *Model) recycleChat() {
+	messages := savableMessages(m.chat.GetMessages())
+	if len(messages) == 0 {
+		return
+	}
+	// Recycling is best effort; a failure shouldn't block the reset
+	_ = recycleConversation(SavedConversation{
+		ID:        m.savedConversationID(),
+		Title:     conversationTitle(messages),
+		UpdatedAt: time.Now(),
+		Messages:  messages,
+		Tags:      m.currentTags(),
+	})
+}
+
+// undoClear restores the most recently cleared conversation, rejoining it
+// on the server when the server can resume conversations
+func (m *Model) undoClear() tea.Cmd {
+	conv, err := popRecycledConversation()
+	if err != nil {
+		m.statusMessages.AddMessage(StatusCategoryError, err.Error(), nil)
+		return nil
+	}
+
+	// The conversation being replaced can be brought back in turn
+	m.recycleChat()
+	m.resetChat()
+	for _, saved := range conv.Messages {
+		m.chat.restoreMessage(saved)
+	}
+	m.messageCount = m.chat.GetMessageCount()
+
+	client, ok := m.phoenixClient.(*phoenix.Client)
+	resumable := !strings.HasPrefix(conv.ID, "local-") && ok && m.connected && m.capabilities[phoenix.CapabilityResume]
+	if !resumable {
+		// Offline, keep saving under the old ID so the copy isn't duplicated
+		m.localConversationID = conv.ID
+		m.updateHeaderState()
+		m.statusBar = fmt.Sprintf("Restored %q locally", conv.Title)
+		return nil
+	}
+	m.statusBar = fmt.Sprintf("Restoring %q...", conv.Title)
+	return client.ResumeConversation(conv.ID)
+}
@@ -0,0 +1,71 @@
+package ui
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/rubber_duck/tui/internal/phoenix"
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+func TestUndoClearRestoresConversation(t *testing.T) {
+	testutil.IsolateHome(t)
+	m := applyMsgs(*NewModel(), phoenix.ConversationResetMsg{SessionInfo: json.RawMessage(`{"conversation_id":"conv-1"}`)})
+	m.chat.AddMessage(UserMessage, "why is the parser slow?", "user")
+
+	m = applyMsgs(m, phoenix.ConversationResetMsg{SessionInfo: json.RawMessage(`{"conversation_id":"conv-2"}`)})
+	if m.chat.GetMessageCount() != 0 {
+		t.Fatalf("Expected the reset to clear the chat")
+	}
+
+	m = applyMsgs(m, ExecuteCommandMsg{Command: "undo_clear"})
+	messages := m.chat.GetMessages()
+	if len(messages) != 1 || messages[0].Content != "why is the parser slow?" {
+		t.Fatalf("Expected the cleared conversation back, got %v", messages)
+	}
+	if _, err := popRecycledConversation(); err != errNothingToUndo {
+		t.Errorf("Expected the recycle area to be empty, got %v", err)
+	}
+
+	for i := 0; i < maxRecycledConversations+2; i++ {
+		if err := recycleConversation(SavedConversation{ID: "local-test"}); err != nil {
+			t.Fatalf("recycleConversation: %v", err)
+		}
+	}
+	dir, _ := recycleDir()
+	if files, _ := recycledFiles(dir); len(files) != maxRecycledConversations {
+		t.Errorf("Expected %d recycled conversations kept, got %d", maxRecycledConversations, len(files))
+	}
+}
+
+func TestClearWhileIncognitoRecyclesNoPrivateMessages(t *testing.T) {
+	testutil.IsolateHome(t)
+	m := applyMsgs(*NewModel(), phoenix.ConversationResetMsg{SessionInfo: json.RawMessage(`{"conversation_id":"conv-1"}`)})
+	m.chat.AddMessage(UserMessage, "public question", "user")
+	m.setIncognito(true)
+	m.chat.AddMessage(UserMessage, "private question", "user")
+
+	m = applyMsgs(m, phoenix.ConversationResetMsg{SessionInfo: json.RawMessage(`{"conversation_id":"conv-2"}`)})
+	conv, err := popRecycledConversation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, msg := range conv.Messages {
+		if msg.Private || msg.Content == "private question" {
+			t.Errorf("Expected no private messages in the recycle area, got %q", msg.Content)
+		}
+	}
+	if len(conv.Messages) == 0 || conv.Messages[0].Content != "public question" {
+		t.Errorf("Expected the public messages recycled, got %v", conv.Messages)
+	}
+
+	// A chat with only private messages leaves nothing to recycle
+	if !m.chat.Incognito() {
+		t.Fatal("Expected incognito to outlast the clear")
+	}
+	m.chat.AddMessage(UserMessage, "another private question", "user")
+	applyMsgs(m, phoenix.ConversationResetMsg{SessionInfo: json.RawMessage(`{"conversation_id":"conv-3"}`)})
+	if _, err := popRecycledConversation(); err != errNothingToUndo {
+		t.Errorf("Expected nothing recycled, got %v", err)
+	}
+}
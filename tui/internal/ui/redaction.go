@@ -0,0 +1,140 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// secretPattern detects one kind of secret. When the expression has a
+// capture group, only the group is masked so the surrounding key name stays
+type secretPattern struct {
+	Kind string
+	Re   *regexp.Regexp
+}
+
+// builtinSecretPatterns are the secrets checked before every send
+var builtinSecretPatterns = []secretPattern{
+	{"private key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`)},
+	{"AWS access key", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"AWS secret key", regexp.MustCompile(`(?i)aws_secret_access_key\s*[=:]\s*["']?([A-Za-z0-9/+=]{40})`)},
+	{"GitHub token", regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`)},
+	{"Slack token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{"API key", regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{20,}`)},
+	{"JWT", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`)},
+	{"credential", regexp.MustCompile(`(?i)\b(?:api[_-]?key|secret|token|password|passwd)\b["']?\s*[=:]\s*["']?([^\s"']{8,})`)},
+}
+
+// secretMatch is a detected secret's position in a message
+type secretMatch struct {
+	Kind       string
+	Start, End int
+}
+
+// secretPatterns returns the built-in patterns plus the configured ones.
+// Invalid configured patterns are skipped rather than blocking every send
+func secretPatterns(extra []string) []secretPattern {
+	patterns := append([]secretPattern(nil), builtinSecretPatterns...)
+	for _, expr := range extra {
+		if re, err := regexp.Compile(expr); err == nil {
+			patterns = append(patterns, secretPattern{"custom pattern", re})
+		}
+	}
+	return patterns
+}
+
+// findSecrets returns the likely secrets in content, in order and without
+// overlaps
+func findSecrets(content string, patterns []secretPattern) []secretMatch {
+	var found []secretMatch
+	for _, p := range patterns {
+		for _, loc := range p.Re.FindAllStringSubmatchIndex(content, -1) {
+			start, end := loc[0], loc[1]
+			if len(loc) >= 4 && loc[2] >= 0 {
+				start, end = loc[2], loc[3]
+			}
+			found = append(found, secretMatch{Kind: p.Kind, Start: start, End: end})
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].Start < found[j].Start })
+
+	// A private key block can contain text that looks like other secrets
+	var merged []secretMatch
+	for _, match := range found {
+		if len(merged) > 0 && match.Start < merged[len(merged)-1].End {
+			continue
+		}
+		merged = append(merged, match)
+	}
+	return merged
+}
+
+// maskSecrets replaces each detected secret with the redaction marker
+func maskSecrets(content string, matches []secretMatch) string {
+	var b strings.Builder
+	last := 0
+	for _, match := range matches {
+		b.WriteString(content[last:match.Start])
+		b.WriteString(redacted)
+		last = match.End
+	}
+	b.WriteString(content[last:])
+	return b.String()
+}
+
+// secretHint shows enough of a secret to recognize it without revealing it
+func secretHint(secret string) string {
+	secret = strings.TrimSpace(secret)
+	if strings.HasPrefix(secret, "-----BEGIN") {
+		return strings.SplitN(secret, "\n", 2)[0]
+	}
+	if len(secret) <= 8 {
+		return strings.Repeat("•", len(secret))
+	}
+	return secret[:4] + strings.Repeat("•", 8)
+}
+
+// checkSecrets holds back a message that looks like it contains secrets and
+// asks whether to mask them; it returns false when the message can be sent
+func (m *Model) checkSecrets(content string) bool {
+	if m.config.TUI.AllowSecrets {
+		return false
+	}
+	matches := findSecrets(content, secretPatterns(m.config.TUI.SecretPatterns))
+	if len(matches) == 0 {
+		return false
+	}
+
+	var lines []string
+	for _, match := range matches {
+		lines = append(lines, fmt.Sprintf("• %s: %s", match.Kind, secretHint(content[match.Start:match.End])))
+	}
+	masked := maskSecrets(content, matches)
+	// Keep the draft in case the user wants to edit it, and remember it
+	// for /send-unredacted
+	m.chat.SetInputValue(content)
+	m.pendingUnredacted = content
+	m.modal.ShowConfirm("Possible secrets", fmt.Sprintf(
+		"This message looks like it contains secrets:\n%s\n\nMask them with %s and send?\n(n keeps the draft; /send-unredacted sends it unchanged)",
+		strings.Join(lines, "\n"), redacted,
+	), func() tea.Msg {
+		return ChatMessageSentMsg{Content: masked, SecretsChecked: true}
+	})
+	return true
+}
+
+// sendUnredacted sends the message held back by checkSecrets as written
+func (m *Model) sendUnredacted() tea.Cmd {
+	content := m.pendingUnredacted
+	if content == "" {
+		m.statusMessages.AddMessage(StatusCategoryError, "No message is waiting to be sent unredacted", nil)
+		return nil
+	}
+	m.pendingUnredacted = ""
+	return func() tea.Msg {
+		return ChatMessageSentMsg{Content: content, SecretsChecked: true}
+	}
+}
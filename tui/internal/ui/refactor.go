@@ -0,0 +1,240 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// RefactorResponseHandler handles refactor responses, which carry the
+// rewritten files in their metadata
+type RefactorResponseHandler struct {
+	BaseResponseHandler
+}
+
+// GetConversationType returns the conversation type this handler handles
+func (h *RefactorResponseHandler) GetConversationType() string {
+	return "refactor"
+}
+
+// FormatResponse formats a refactor response with the files it changes
+func (h *RefactorResponseHandler) FormatResponse(response phoenix.ConversationMessage) string {
+	parts := []string{"## ♻️ Refactor\n", response.Response}
+	if changes := response.FileChanges(); len(changes) > 0 {
+		parts = append(parts, h.addSectionHeader("Changes"))
+		for _, change := range changes {
+			line := fmt.Sprintf("- `%s`", change.Path)
+			if change.Description != "" {
+				line += " - " + change.Description
+			}
+			parts = append(parts, line)
+		}
+		parts = append(parts, "\nType /apply to review and apply them.")
+	}
+	return strings.Join(parts, "\n")
+}
+
+// ApplyHunksMsg carries an open file's content with the accepted hunks applied
+type ApplyHunksMsg struct {
+	Path     string
+	Content  string
+	Accepted int
+	Total    int
+}
+
+// ApplyPreview shows a suggested change to the open file hunk by hunk so
+// each can be accepted or skipped
+type ApplyPreview struct {
+	path     string
+	source   string
+	old      []string
+	hunks    []hunk
+	selected []bool
+	cursor   int
+	visible  bool
+	width    int
+	height   int
+}
+
+// Show previews the changes from source to updated, reporting false when
+// there are none
+func (ap *ApplyPreview) Show(path, source, updated string) bool {
+	hunks := diffHunks(source, updated)
+	if len(hunks) == 0 {
+		return false
+	}
+	ap.path = path
+	ap.source = source
+	ap.old = strings.Split(source, "\n")
+	ap.hunks = hunks
+	ap.selected = make([]bool, len(hunks))
+	for i := range ap.selected {
+		ap.selected[i] = true
+	}
+	ap.cursor = 0
+	ap.visible = true
+	return true
+}
+
+// Hide hides the preview
+func (ap *ApplyPreview) Hide() {
+	ap.visible = false
+}
+
+// IsVisible returns whether the preview is visible
+func (ap ApplyPreview) IsVisible() bool {
+	return ap.visible
+}
+
+// SetSize updates the preview dimensions
+func (ap *ApplyPreview) SetSize(width, height int) {
+	ap.width = width
+	ap.height = height
+}
+
+// accepted counts the selected hunks
+func (ap ApplyPreview) accepted() int {
+	count := 0
+	for _, selected := range ap.selected {
+		if selected {
+			count++
+		}
+	}
+	return count
+}
+
+// Update handles preview input
+func (ap ApplyPreview) Update(msg tea.Msg) (ApplyPreview, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || !ap.visible {
+		return ap, nil
+	}
+
+	switch keyMsg.String() {
+	case "esc", "q":
+		ap.Hide()
+	case "up", "k":
+		ap.cursor = max(ap.cursor-1, 0)
+	case "down", "j":
+		ap.cursor = min(ap.cursor+1, len(ap.hunks)-1)
+	case " ":
+		ap.selected[ap.cursor] = !ap.selected[ap.cursor]
+	case "a":
+		// Select all, or none when all are selected
+		all := ap.accepted() < len(ap.hunks)
+		for i := range ap.selected {
+			ap.selected[i] = all
+		}
+	case "enter":
+		ap.Hide()
+		applied := ApplyHunksMsg{
+			Path:     ap.path,
+			Content:  applyHunks(ap.source, ap.hunks, ap.selected),
+			Accepted: ap.accepted(),
+			Total:    len(ap.hunks),
+		}
+		return ap, func() tea.Msg { return applied }
+	}
+	return ap, nil
+}
+
+// View renders the preview
+func (ap ApplyPreview) View() string {
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("62"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	cursorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
+
+	header := []string{
+		titleStyle.Render("Apply changes to " + ap.path),
+		dimStyle.Render(fmt.Sprintf("%d of %d hunks selected", ap.accepted(), len(ap.hunks))),
+		"",
+	}
+
+	var body []string
+	cursorLine := 0
+	for i, h := range ap.hunks {
+		mark := "[ ]"
+		if ap.selected[i] {
+			mark = "[x]"
+		}
+		label := fmt.Sprintf("%s Hunk %d", mark, i+1)
+		if i == ap.cursor {
+			cursorLine = len(body)
+			label = cursorStyle.Render("▶ " + label)
+		} else {
+			label = "  " + label
+		}
+		body = append(body, label)
+		body = append(body, strings.Split(renderHunk(ap.old, h), "\n")...)
+		body = append(body, "")
+	}
+
+	// Keep the hunk under the cursor on screen
+	visible := max(ap.height-len(header)-2, 3)
+	start := 0
+	if cursorLine >= visible {
+		start = cursorLine
+	}
+	body = body[start:min(start+visible, len(body))]
+
+	footer := dimStyle.Render("↑/↓: Move | Space: Toggle hunk | a: All/none | Enter: Apply | Esc: Cancel")
+	return lipgloss.NewStyle().
+		Padding(0, 1).
+		Render(strings.Join(append(append(header, body...), footer), "\n"))
+}
+
+// samePath reports whether two paths name the same project file
+func samePath(a, b string) bool {
+	return filepath.Clean(a) == filepath.Clean(b)
+}
+
+// offerChanges keeps a refactor response's file changes for /apply
+func (m *Model) offerChanges(changes []phoenix.FileChange) {
+	m.pendingChanges = changes
+	if m.editsOpenFile() {
+		m.statusBar = fmt.Sprintf("Suggested changes for %s - /apply to preview them", m.currentFile)
+		return
+	}
+	m.statusBar = fmt.Sprintf("Suggested changes to %d files - /apply to review them", len(changes))
+}
+
+// editsOpenFile reports whether the pending changes are to the open file only
+func (m *Model) editsOpenFile() bool {
+	return len(m.pendingChanges) == 1 && m.currentFile != "" && samePath(m.pendingChanges[0].Path, m.currentFile)
+}
+
+// previewChanges opens the apply preview when the suggested change is to
+// the open file, and the multi-file review otherwise
+func (m *Model) previewChanges() {
+	if len(m.pendingChanges) == 0 {
+		m.statusMessages.AddMessage(StatusCategoryError, "No suggested changes to apply", nil)
+		return
+	}
+	if !m.editsOpenFile() {
+		m.reviewChanges(m.pendingChanges)
+		return
+	}
+	if !m.applyPreview.Show(m.currentFile, m.editor.Value(), m.pendingChanges[0].Content) {
+		m.statusBar = fmt.Sprintf("%s already matches the suggested changes", m.currentFile)
+	}
+}
+
+// applyHunksToBuffer puts accepted hunks into the editor, leaving the buffer
+// unsaved so the result can be reviewed first
+func (m *Model) applyHunksToBuffer(msg ApplyHunksMsg) {
+	if !samePath(msg.Path, m.currentFile) {
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("%s is no longer open", msg.Path), nil)
+		return
+	}
+	line := m.editor.Line() + 1
+	m.editor.SetValue(msg.Content)
+	m.moveEditorToLine(line)
+	m.refreshOutline()
+	m.focusPane(EditorPane)
+	m.hunksApplied = true
+	m.statusBar = fmt.Sprintf("Applied %d of %d hunks to %s (unsaved - Ctrl+S saves)", msg.Accepted, msg.Total, m.currentFile)
+}
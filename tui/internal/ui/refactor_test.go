@@ -0,0 +1,46 @@
+package ui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/phoenix"
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+func TestApplyRefactorHunks(t *testing.T) {
+	old := "a\nb\nc\nd\ne\nf"
+	updated := "a\nB\nc\nd\ne\nf\ng"
+	hunks := diffHunks(old, updated)
+	if len(hunks) != 2 {
+		t.Fatalf("Expected 2 hunks, got %+v", hunks)
+	}
+	if got := applyHunks(old, hunks, []bool{true, true}); got != updated {
+		t.Fatalf("Expected all hunks to give the new version, got %q", got)
+	}
+	if got := applyHunks(old, hunks, []bool{false, true}); got != "a\nb\nc\nd\ne\nf\ng" {
+		t.Fatalf("Expected only the second hunk, got %q", got)
+	}
+
+	testutil.IsolateHome(t)
+	model := NewModel()
+	model.currentFile = "main.go"
+	model.editor.SetValue(old)
+	model.offerChanges([]phoenix.FileChange{{Path: "./main.go", Content: updated}})
+	model.previewChanges()
+	if !model.applyPreview.IsVisible() {
+		t.Fatal("Expected /apply to preview the open file's changes")
+	}
+
+	// Skip the first hunk, then apply
+	preview, _ := model.applyPreview.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	preview, cmd := preview.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model.applyPreview = preview
+	model.applyHunksToBuffer(cmd().(ApplyHunksMsg))
+	if got := model.editor.Value(); got != "a\nb\nc\nd\ne\nf\ng" {
+		t.Fatalf("Expected the accepted hunk in the buffer, got %q", got)
+	}
+	if model.editor.Value() == model.editorOriginal {
+		t.Fatal("Expected the buffer to be left unsaved")
+	}
+}
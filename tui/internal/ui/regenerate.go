@@ -0,0 +1,180 @@
+package ui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// diffOp is one sentence of an answer diff
+type diffOp struct {
+	Kind byte // '=' unchanged, '-' removed, '+' added
+	Text string
+}
+
+// splitSentences splits text after sentence ends and line breaks, keeping
+// the whitespace with each sentence so joining them restores the text
+func splitSentences(text string) []string {
+	var sentences []string
+	start := 0
+	for i := 0; i < len(text); i++ {
+		end := text[i] == '\n' ||
+			(strings.IndexByte(".!?", text[i]) >= 0 && i+1 < len(text) && (text[i+1] == ' ' || text[i+1] == '\n'))
+		if !end {
+			continue
+		}
+		// Take the following whitespace along
+		j := i + 1
+		for j < len(text) && (text[j] == ' ' || text[j] == '\n') {
+			j++
+		}
+		sentences = append(sentences, text[start:j])
+		start = j
+		i = j - 1
+	}
+	if start < len(text) {
+		sentences = append(sentences, text[start:])
+	}
+	return sentences
+}
+
+// diffSentences compares two answers sentence by sentence using the longest
+// common subsequence
+func diffSentences(before, after string) []diffOp {
+	a, b := splitSentences(before), splitSentences(after)
+	same := func(i, j int) bool { return strings.TrimSpace(a[i]) == strings.TrimSpace(b[j]) }
+
+	// lcs[i][j] is the common length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if same(i, j) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var ops []diffOp
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case same(i, j):
+			ops = append(ops, diffOp{'=', b[j]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+	return ops
+}
+
+// renderAnswerDiff renders a regenerated answer with removed sentences struck
+// through and added ones highlighted
+func renderAnswerDiff(before, after string) string {
+	removed := lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Strikethrough(true)
+	added := lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	var b strings.Builder
+	for _, op := range diffSentences(before, after) {
+		// Style the sentence but not its trailing whitespace
+		text := strings.TrimRight(op.Text, " \n")
+		space := op.Text[len(text):]
+		switch op.Kind {
+		case '-':
+			b.WriteString(removed.Render(text))
+		case '+':
+			b.WriteString(added.Render(text))
+		default:
+			b.WriteString(text)
+		}
+		b.WriteString(space)
+	}
+	return b.String()
+}
+
+// regenerate asks for a new answer to the last prompt; the answer shows
+// what changed from the one it replaces
+func (m *Model) regenerate() tea.Cmd {
+	previous := m.chat.GetLastAssistantMessage()
+	if m.lastPrompt == "" || previous == "" {
+		m.statusMessages.AddMessage(StatusCategoryError, "Nothing to regenerate yet", nil)
+		return nil
+	}
+	if m.demo != nil {
+		m.regenerateFrom = previous
+		m.statusBar = "Regenerating..."
+		return m.demo.SendMessage(m.lastPrompt)
+	}
+	client, ok := m.phoenixClient.(*phoenix.Client)
+	if !ok || !m.connected {
+		m.statusMessages.AddMessage(StatusCategoryError, "Not connected to server", nil)
+		return nil
+	}
+	m.regenerateFrom = previous
+	m.isProcessing = true
+	m.latency.Begin(m.activeProvider(), m.activeModel())
+	m.statusBar = "Regenerating..."
+	return client.SendReplyWithConfig(m.lastPrompt, m.lastReplyTo, m.activeModel(), m.activeProvider(), m.activeTemperature())
+}
+
+// finishRegenerate attaches the replaced answer to a regenerated one so
+// the changes are shown
+func (m *Model) finishRegenerate() {
+	if m.regenerateFrom == "" {
+		return
+	}
+	m.chat.setLastMessagePrevious(m.regenerateFrom)
+	m.regenerateFrom = ""
+	m.statusBar = "Regenerated - /changes hides the changes"
+}
+
+// setLastMessagePrevious shows the last message as a diff against the
+// answer it replaced
+func (c *Chat) setLastMessagePrevious(previous string) {
+	if len(c.messages) == 0 {
+		return
+	}
+	last := &c.messages[len(c.messages)-1]
+	last.Previous = previous
+	last.ShowDiff = true
+	c.viewport.SetContent(c.buildViewportContent())
+	c.viewport.GotoBottom()
+}
+
+// toggleDiff switches a regenerated message between its diff and the
+// plain answer, returning false when the message wasn't regenerated
+func (c *Chat) toggleDiff(index int) bool {
+	if index < 0 || index >= len(c.messages) || c.messages[index].Previous == "" {
+		return false
+	}
+	c.messages[index].ShowDiff = !c.messages[index].ShowDiff
+	c.viewport.SetContent(c.buildViewportContent())
+	return true
+}
+
+// ToggleLastDiff toggles the diff of the most recent regenerated message
+func (c *Chat) ToggleLastDiff() bool {
+	for i := len(c.messages) - 1; i >= 0; i-- {
+		if c.messages[i].Previous != "" {
+			return c.toggleDiff(i)
+		}
+	}
+	return false
+}
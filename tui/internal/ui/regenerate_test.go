@@ -0,0 +1,34 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegeneratedAnswerDiff(t *testing.T) {
+	before := "Use a map. It is fast. Done."
+	after := "Use a map. It is simple and fast. Done."
+
+	var kinds []string
+	for _, op := range diffSentences(before, after) {
+		kinds = append(kinds, string(op.Kind)+strings.TrimSpace(op.Text))
+	}
+	want := "=Use a map.|-It is fast.|+It is simple and fast.|=Done."
+	if got := strings.Join(kinds, "|"); got != want {
+		t.Fatalf("Expected %s, got %s", want, got)
+	}
+	if got := strings.Join(splitSentences("One. Two!\nThree"), ""); got != "One. Two!\nThree" {
+		t.Fatalf("Splitting must keep the text intact, got %q", got)
+	}
+
+	chat := NewChat()
+	chat.SetSize(80, 40)
+	chat.AddMessage(AssistantMessage, after, "assistant")
+	chat.setLastMessagePrevious(before)
+	if !chat.GetMessages()[0].ShowDiff {
+		t.Fatal("Expected a regenerated answer to show its changes")
+	}
+	if !chat.ToggleLastDiff() || chat.GetMessages()[0].ShowDiff {
+		t.Fatal("Expected the diff to toggle off")
+	}
+}
@@ -0,0 +1,175 @@
+package ui
+
+import (
+	"strings"
+	"time"
+
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rubber_duck/tui/internal/clock"
+)
+
+// maxQuoteLines limits how much of a message a reply quotes
+const maxQuoteLines = 12
+
+// replyIndent is how far replies are indented in the history
+const replyIndent = 4
+
+// localRefPrefix marks a reference to a message the server has no ID for
+const localRefPrefix = "local:"
+
+// ReplyToMessageMsg starts a reply quoting a message
+type ReplyToMessageMsg struct {
+	Index int
+}
+
+// replyTarget is the message the next send replies to
+type replyTarget struct {
+	Ref    string // The message's reference, see messageRef
+	Author string // Whose message it is, e.g. "your message", for the badge
+}
+
+// messageRef references a message: its server ID, or its time when it has
+// none
+func messageRef(msg ChatMessage) string {
+	if msg.ID != "" {
+		return msg.ID
+	}
+	return localRefPrefix + msg.Timestamp.UTC().Format(time.RFC3339Nano)
+}
+
+// serverRef returns the reference the server threads a reply on, empty for
+// messages only this client knows
+func serverRef(ref string) string {
+	if strings.HasPrefix(ref, localRefPrefix) {
+		return ""
+	}
+	return ref
+}
+
+// quoteReply formats text as a markdown blockquote, cut off after
+// maxQuoteLines lines
+func quoteReply(text string) string {
+	lines := strings.Split(strings.Trim(text, "\n"), "\n")
+	cut := len(lines) > maxQuoteLines
+	if cut {
+		lines = lines[:maxQuoteLines]
+	}
+	for i, line := range lines {
+		lines[i] = strings.TrimRight("> "+line, " ")
+	}
+	if cut {
+		lines = append(lines, "> …")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// replyExcerpt returns what a reply to a message quotes: the part of it on
+// the clipboard, when a selection of it was copied, or all of it
+func replyExcerpt(content, copied string) string {
+	copied = strings.TrimSpace(copied)
+	if copied != "" && copied != strings.TrimSpace(content) && strings.Contains(content, copied) {
+		return copied
+	}
+	return content
+}
+
+// StartReply puts a quote in the input above the draft and makes the next
+// send a reply to target
+func (c *Chat) StartReply(target replyTarget, quote string) {
+	c.reply = &target
+	value := quote + "\n\n"
+	if draft := strings.TrimSpace(c.input.Value()); draft != "" {
+		value += draft
+	}
+	c.input.SetValue(value)
+	c.input.CursorEnd()
+	c.stopSelection()
+}
+
+// takeReply marks the last message as the pending reply and returns the
+// reference the server threads it on
+func (c *Chat) takeReply() string {
+	if c.reply == nil || len(c.messages) == 0 {
+		return ""
+	}
+	ref := c.reply.Ref
+	c.reply = nil
+	c.messages[len(c.messages)-1].ReplyTo = ref
+	c.viewport.SetContent(c.buildViewportContent())
+	c.viewport.GotoBottom()
+	return serverRef(ref)
+}
+
+// replyLabel describes the message a reply answers, for its header
+func (c Chat) replyLabel(ref string) string {
+	for _, msg := range c.messages {
+		if messageRef(msg) == ref {
+			return "↪ " + strings.ToLower(c.authorName(msg)) + " " + c.display.formatMessageTime(msg.Timestamp, clock.Now())
+		}
+	}
+	return "↪ reply"
+}
+
+// authorName names who wrote a message as the history does
+func (c Chat) authorName(msg ChatMessage) string {
+	switch msg.Type {
+	case UserMessage:
+		return "You"
+	case AssistantMessage:
+		return "Assistant"
+	case ErrorMessage:
+		return "Error"
+	default:
+		return "System"
+	}
+}
+
+// messageOwner names whose message msg is, e.g. "the assistant's message"
+func (c Chat) messageOwner(msg ChatMessage) string {
+	if msg.Type == UserMessage {
+		return "your message"
+	}
+	return "the " + strings.ToLower(c.authorName(msg)) + "'s message"
+}
+
+// renderReplyBadge reminds that the next send is a reply
+func (c Chat) renderReplyBadge() string {
+	if c.reply == nil {
+		return ""
+	}
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color("81")).
+		Italic(true).
+		Render(truncateStage("↪ Replying to "+c.reply.Author+" - clear the input to cancel", max(c.width-2, 20)))
+}
+
+// dropReplyIfCleared forgets the reply once its quote and draft are deleted
+func (c *Chat) dropReplyIfCleared(before string) {
+	if c.reply != nil && strings.TrimSpace(before) != "" && strings.TrimSpace(c.input.Value()) == "" {
+		c.reply = nil
+	}
+}
+
+// startReply quotes a message, or the part of it copied to the clipboard,
+// into the input
+func (m *Model) startReply(msg ReplyToMessageMsg) {
+	messages := m.chat.GetMessages()
+	if msg.Index < 0 || msg.Index >= len(messages) {
+		return
+	}
+	target := messages[msg.Index]
+	content := target.Content
+	if target.Full != "" {
+		content = target.Full
+	}
+	copied, _ := clipboard.ReadAll()
+	excerpt := replyExcerpt(content, copied)
+	owner := m.chat.messageOwner(target)
+	m.chat.StartReply(replyTarget{Ref: messageRef(target), Author: owner}, quoteReply(excerpt))
+	m.statusBar = "Replying to " + owner
+	if excerpt != content {
+		m.statusBar += " (quoting the copied excerpt)"
+	}
+	m.focusPane(ChatPane)
+}
@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestQuoteReply(t *testing.T) {
+	if got := quoteReply("first\n\nsecond  \n"); got != "> first\n>\n> second" {
+		t.Errorf("Unexpected quote %q", got)
+	}
+	long := strings.Repeat("line\n", maxQuoteLines+5)
+	lines := strings.Split(quoteReply(long), "\n")
+	if len(lines) != maxQuoteLines+1 || lines[len(lines)-1] != "> …" {
+		t.Errorf("Expected the quote cut off after %d lines, got %q", maxQuoteLines, lines)
+	}
+
+	content := "Use a mutex here.\nOr a channel."
+	if got := replyExcerpt(content, " a channel \n"); got != "a channel" {
+		t.Errorf("Expected the copied excerpt, got %q", got)
+	}
+	if got := replyExcerpt(content, "something else"); got != content {
+		t.Errorf("Expected the whole message when the clipboard isn't part of it, got %q", got)
+	}
+}
+
+func TestReplyThreadsAndIndents(t *testing.T) {
+	chat := NewChat()
+	chat.SetSize(80, 30)
+	chat.AddMessage(AssistantMessage, "answer", "assistant")
+	chat.messages[0].ID = "msg-1"
+
+	chat.SetInputValue("draft")
+	chat.StartReply(replyTarget{Ref: messageRef(chat.messages[0]), Author: chat.messageOwner(chat.messages[0])}, quoteReply("answer"))
+	if got := chat.GetInputValue(); got != "> answer\n\ndraft" {
+		t.Errorf("Expected the quote above the draft, got %q", got)
+	}
+	if !strings.Contains(chat.View(), "Replying to the assistant's message") {
+		t.Error("Expected a badge while replying")
+	}
+
+	chat.AddMessage(UserMessage, "> answer\n\nwhy?", "user")
+	if ref := chat.takeReply(); ref != "msg-1" {
+		t.Errorf("Expected the server reference msg-1, got %q", ref)
+	}
+	if chat.messages[1].ReplyTo != "msg-1" || chat.reply != nil {
+		t.Errorf("Expected the message marked as a reply, got %+v", chat.messages[1])
+	}
+	rendered := ansi.Strip(chat.buildViewportContent())
+	if !strings.Contains(rendered, strings.Repeat(" ", replyIndent)+"> answer") || !strings.Contains(rendered, "↪ assistant") {
+		t.Errorf("Expected the reply indented under a note, got:\n%s", rendered)
+	}
+
+	// Messages without a server ID thread locally only
+	if ref := serverRef(messageRef(ChatMessage{})); ref != "" {
+		t.Errorf("Expected no server reference for a local message, got %q", ref)
+	}
+}
@@ -0,0 +1,149 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rubber_duck/tui/internal/clock"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// requestTTL is how long an unanswered push stays tracked
+const requestTTL = 10 * time.Minute
+
+// pendingRequest is a push awaiting the events it causes
+type pendingRequest struct {
+	ID      string
+	Topic   string
+	Event   string
+	Payload any
+	Label   string // What the user asked for, for messages
+	SentAt  time.Time
+}
+
+// requestTracker matches server events to the pushes that caused them by
+// correlation ID, falling back to the oldest push of the same kind for
+// servers that don't echo IDs
+type requestTracker struct {
+	pending map[string]pendingRequest
+	order   []string
+	// uncorrelated counts events matched without an ID
+	uncorrelated int
+	// onDone is told each push that was answered or given up on
+	onDone func(request pendingRequest)
+}
+
+// newRequestTracker creates an empty tracker
+func newRequestTracker() *requestTracker {
+	return &requestTracker{pending: make(map[string]pendingRequest)}
+}
+
+// Track records a sent push
+func (t *requestTracker) Track(msg phoenix.RequestSentMsg) {
+	if msg.RequestID == "" {
+		return
+	}
+	t.prune()
+	request := pendingRequest{ID: msg.RequestID, Topic: msg.Topic, Event: msg.Event, Payload: msg.Payload, SentAt: clock.Now()}
+	if payload, ok := msg.Payload.(map[string]any); ok {
+		request.Label, _ = payload["content"].(string)
+	}
+	t.pending[msg.RequestID] = request
+	t.order = append(t.order, msg.RequestID)
+}
+
+// Resolve removes and returns the push an event answers. An event without
+// an ID resolves the oldest pending push of the given kind
+func (t *requestTracker) Resolve(id, event string) (pendingRequest, bool) {
+	if id == "" {
+		for _, pendingID := range t.order {
+			if t.pending[pendingID].Event == event {
+				id = pendingID
+				t.uncorrelated++
+				break
+			}
+		}
+	}
+	request, ok := t.pending[id]
+	if !ok {
+		return pendingRequest{}, false
+	}
+	t.remove(id)
+	return request, true
+}
+
+// Lookup returns a pending push without resolving it
+func (t *requestTracker) Lookup(id string) (pendingRequest, bool) {
+	request, ok := t.pending[id]
+	return request, ok
+}
+
+// ResolveAll drops every pending push of a kind, as when processing is cancelled
+func (t *requestTracker) ResolveAll(event string) {
+	for _, id := range append([]string(nil), t.order...) {
+		if t.pending[id].Event == event {
+			t.remove(id)
+		}
+	}
+}
+
+// Outstanding counts pending pushes of a kind
+func (t *requestTracker) Outstanding(event string) int {
+	count := 0
+	for _, request := range t.pending {
+		if request.Event == event {
+			count++
+		}
+	}
+	return count
+}
+
+// remove forgets a push
+func (t *requestTracker) remove(id string) {
+	if request, ok := t.pending[id]; ok && t.onDone != nil {
+		t.onDone(request)
+	}
+	delete(t.pending, id)
+	for i, pendingID := range t.order {
+		if pendingID == id {
+			t.order = append(t.order[:i], t.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// prune forgets pushes older than requestTTL
+func (t *requestTracker) prune() {
+	cutoff := clock.Now().Add(-requestTTL)
+	for len(t.order) > 0 && t.pending[t.order[0]].SentAt.Before(cutoff) {
+		delete(t.pending, t.order[0])
+		t.order = t.order[1:]
+	}
+}
+
+// inReplyTo labels a response when other messages are still waiting, so
+// parallel answers can be told apart
+func inReplyTo(request pendingRequest, stillPending int) string {
+	if stillPending == 0 || request.Label == "" {
+		return ""
+	}
+	label := []rune(request.Label)
+	if len(label) > 40 {
+		label = append(label[:37], []rune("...")...)
+	}
+	return fmt.Sprintf("_In reply to: %q_\n\n", string(label))
+}
+
+// resolveRequest resolves the push an event answers. A server that took part
+// in the handshake must echo request IDs; the first event without one is
+// logged as a payload diagnostic
+func (m *Model) resolveRequest(id, topic, event, answers string) (pendingRequest, bool) {
+	if id == "" && m.capabilities != nil && m.requests.uncorrelated == 0 {
+		m.handlePayloadDiagnostic(phoenix.PayloadDiagnosticMsg{
+			Topic:  topic,
+			Event:  event,
+			Issues: []phoenix.SchemaIssue{{Field: phoenix.RequestIDField, Problem: "required field is missing"}},
+		})
+	}
+	return m.requests.Resolve(id, answers)
+}
@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/rubber_duck/tui/internal/phoenix"
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+func TestParallelResponsesMatchTheirMessages(t *testing.T) {
+	testutil.IsolateHome(t)
+	m := *NewModel()
+	m.isProcessing = true
+	for _, sent := range []phoenix.RequestSentMsg{
+		{RequestID: "req-1", Topic: "conversation:lobby", Event: "message", Payload: map[string]any{"content": "analyze main.go"}},
+		{RequestID: "req-2", Topic: "conversation:lobby", Event: "message", Payload: map[string]any{"content": "analyze util.go"}},
+	} {
+		updated, _ := m.Update(sent)
+		m = updated.(Model)
+	}
+
+	// The second message is answered first
+	data, _ := json.Marshal(phoenix.ConversationMessage{Query: "analyze util.go", Response: "util looks fine", RequestID: "req-2"})
+	updated, _ := m.Update(phoenix.ConversationResponseMsg{Response: data})
+	m = updated.(Model)
+
+	messages := m.chat.GetMessages()
+	if last := messages[len(messages)-1].Content; !strings.Contains(last, `In reply to: "analyze util.go"`) {
+		t.Errorf("Expected the response labelled with its message, got %q", last)
+	}
+	if !m.isProcessing || m.requests.Outstanding("message") != 1 {
+		t.Error("Expected the first message to still be pending")
+	}
+
+	data, _ = json.Marshal(phoenix.ConversationMessage{Query: "analyze main.go", Response: "main looks fine", RequestID: "req-1"})
+	updated, _ = m.Update(phoenix.ConversationResponseMsg{Response: data})
+	m = updated.(Model)
+	if m.isProcessing {
+		t.Error("Expected processing to end with the last answer")
+	}
+}
+
+func TestRequestTrackerFallsBackToOldest(t *testing.T) {
+	tracker := newRequestTracker()
+	tracker.Track(phoenix.RequestSentMsg{RequestID: "a", Event: "message"})
+	tracker.Track(phoenix.RequestSentMsg{RequestID: "b", Event: "get_history"})
+	tracker.Track(phoenix.RequestSentMsg{RequestID: "c", Event: "message"})
+
+	if request, ok := tracker.Resolve("", "message"); !ok || request.ID != "a" {
+		t.Errorf("Expected an uncorrelated response to resolve the oldest message, got %+v", request)
+	}
+	if request, ok := tracker.Resolve("b", "message"); !ok || request.Event != "get_history" {
+		t.Errorf("Expected a correlated ID to win over the event kind, got %+v", request)
+	}
+	if tracker.Outstanding("message") != 1 || tracker.uncorrelated != 1 {
+		t.Errorf("Unexpected tracker state: %+v", tracker)
+	}
+}
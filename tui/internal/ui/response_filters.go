@@ -0,0 +1,172 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ResponseFilter post-processes assistant responses before they are shown
+type ResponseFilter interface {
+	Name() string
+	Filter(content string) string
+}
+
+// defaultResponseFilters run when tui.response_filters isn't configured
+var defaultResponseFilters = []string{"strip_ansi", "collapse_blank_lines", "truncate"}
+
+// defaultResponseMaxLines is where the truncate filter cuts responses off
+const defaultResponseMaxLines = 2000
+
+// responseFilterFactories builds the filters tui.response_filters can name
+var responseFilterFactories = map[string]func(cfg TUIConfig) ResponseFilter{
+	"strip_ansi":           func(TUIConfig) ResponseFilter { return StripANSIFilter{} },
+	"collapse_blank_lines": func(TUIConfig) ResponseFilter { return CollapseBlankLinesFilter{Max: 2} },
+	"truncate": func(cfg TUIConfig) ResponseFilter {
+		maxLines := cfg.ResponseMaxLines
+		if maxLines <= 0 {
+			maxLines = defaultResponseMaxLines
+		}
+		return TruncateFilter{MaxLines: maxLines}
+	},
+}
+
+// RegisterResponseFilter makes a filter available to tui.response_filters
+func RegisterResponseFilter(name string, factory func(cfg TUIConfig) ResponseFilter) {
+	responseFilterFactories[name] = factory
+}
+
+// buildResponseFilters returns the configured filters in order. Unknown
+// names are reported but don't stop the others from running
+func buildResponseFilters(cfg TUIConfig) ([]ResponseFilter, error) {
+	names := cfg.ResponseFilters
+	if names == nil {
+		names = defaultResponseFilters
+	}
+	var filters []ResponseFilter
+	var unknown []string
+	for _, name := range names {
+		factory, ok := responseFilterFactories[name]
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		filters = append(filters, factory(cfg))
+	}
+	if len(unknown) > 0 {
+		return filters, fmt.Errorf("unknown response filters: %s", strings.Join(unknown, ", "))
+	}
+	return filters, nil
+}
+
+// applyResponseFilters runs the filters over a response. When the truncate
+// filter shortens it, full holds the response as it was before truncation
+func applyResponseFilters(filters []ResponseFilter, content string) (shown, full string) {
+	for _, filter := range filters {
+		next := filter.Filter(content)
+		if _, truncates := filter.(TruncateFilter); truncates && next != content {
+			full = content
+		}
+		content = next
+	}
+	return content, full
+}
+
+// ansiEscape matches terminal escape sequences
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)`)
+
+// StripANSIFilter removes terminal escape sequences, which would otherwise
+// be interpreted by the terminal
+type StripANSIFilter struct{}
+
+func (StripANSIFilter) Name() string { return "strip_ansi" }
+
+func (StripANSIFilter) Filter(content string) string {
+	return ansiEscape.ReplaceAllString(content, "")
+}
+
+// CollapseBlankLinesFilter limits runs of blank lines to Max
+type CollapseBlankLinesFilter struct {
+	Max int
+}
+
+func (CollapseBlankLinesFilter) Name() string { return "collapse_blank_lines" }
+
+func (f CollapseBlankLinesFilter) Filter(content string) string {
+	lines := strings.Split(content, "\n")
+	kept := lines[:0]
+	blank := 0
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			blank++
+			if blank > f.Max {
+				continue
+			}
+		} else {
+			blank = 0
+		}
+		kept = append(kept, line)
+	}
+	return strings.Join(kept, "\n")
+}
+
+// TruncateFilter cuts extremely long responses off after MaxLines; the full
+// response is kept and /show-more brings it back
+type TruncateFilter struct {
+	MaxLines int
+}
+
+func (TruncateFilter) Name() string { return "truncate" }
+
+func (f TruncateFilter) Filter(content string) string {
+	lines := strings.Split(content, "\n")
+	if len(lines) <= f.MaxLines {
+		return content
+	}
+	// Close a code block left open by the cut so the notice renders as text
+	shown := strings.Join(lines[:f.MaxLines], "\n")
+	if strings.Count(shown, "```")%2 == 1 {
+		shown += "\n```"
+	}
+	return fmt.Sprintf("%s\n\n_… %d more lines truncated, /show-more to see them_", shown, len(lines)-f.MaxLines)
+}
+
+// loadResponseFilters builds the configured response filters
+func (m *Model) loadResponseFilters() {
+	filters, err := buildResponseFilters(m.config.TUI)
+	if err != nil {
+		m.statusMessages.AddMessage(StatusCategoryError, err.Error(), nil)
+	}
+	m.responseFilters = filters
+}
+
+// showResponse sets the last chat message to a filtered assistant response
+func (m *Model) showResponse(content string) {
+	shown, full := applyResponseFilters(m.responseFilters, content)
+	m.chat.UpdateLastMessage(shown)
+	m.chat.setLastMessageFull(full)
+	if m.askPanel.Waiting() {
+		m.askPanel.SetAnswer(shown)
+	}
+}
+
+// setLastMessageFull records the untruncated content of the last message
+func (c *Chat) setLastMessageFull(full string) {
+	if len(c.messages) > 0 {
+		c.messages[len(c.messages)-1].Full = full
+	}
+}
+
+// ExpandLastTruncated restores the most recent truncated message in full,
+// returning false when none was truncated
+func (c *Chat) ExpandLastTruncated() bool {
+	for i := len(c.messages) - 1; i >= 0; i-- {
+		if c.messages[i].Full != "" {
+			c.messages[i].Content = c.messages[i].Full
+			c.messages[i].Full = ""
+			c.viewport.SetContent(c.buildViewportContent())
+			return true
+		}
+	}
+	return false
+}
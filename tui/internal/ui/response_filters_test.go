@@ -0,0 +1,38 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+func TestResponseFilters(t *testing.T) {
+	filters, err := buildResponseFilters(TUIConfig{ResponseMaxLines: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	shown, full := applyResponseFilters(filters, "\x1b[31mred\x1b[0m\n\n\n\n\nend")
+	if shown != "red\n\n\nend" || full != "" {
+		t.Fatalf("Expected escapes stripped and blank lines collapsed, got %q", shown)
+	}
+
+	long := strings.Repeat("line\n", 9) + "last"
+	shown, full = applyResponseFilters(filters, long)
+	if !strings.Contains(shown, "5 more lines truncated") || full != long {
+		t.Fatalf("Expected a truncated response keeping the full text, got %q", shown)
+	}
+
+	if _, err := buildResponseFilters(TUIConfig{ResponseFilters: []string{"strip_ansi", "bogus"}}); err == nil {
+		t.Fatal("Expected unknown filters to be reported")
+	}
+
+	testutil.IsolateHome(t)
+	model := NewModel()
+	model.responseFilters = filters
+	model.chat.AddMessage(AssistantMessage, "", "assistant")
+	model.showResponse(long)
+	if !model.chat.ExpandLastTruncated() || model.chat.GetMessages()[0].Content != long {
+		t.Fatal("Expected /show-more to restore the full response")
+	}
+}
@@ -56,6 +56,9 @@ func NewResponseHandlerRegistry() *ResponseHandlerRegistry {
 	registry.handlers["planning"] = &PlanningResponseHandler{}
 	registry.handlers["planning_conversation"] = &PlanningResponseHandler{}
 	
+	registry.handlers["refactor"] = &RefactorResponseHandler{}
+	registry.handlers["refactor_conversation"] = &RefactorResponseHandler{}
+	
 	return registry
 }
 
@@ -0,0 +1,458 @@
+package ui
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// Limits for the embedded search
+const (
+	maxSearchFiles     = 5000
+	maxSearchResults   = 500
+	maxSearchFileSize  = 1024 * 1024
+	searchContextLines = 1
+)
+
+// errProjectTooLarge means the project should be searched on the server
+var errProjectTooLarge = errors.New("project too large for local search")
+
+// searchSkipDirs are directories never searched locally
+var searchSkipDirs = map[string]bool{
+	"node_modules": true,
+	"_build":       true,
+	"deps":         true,
+	"vendor":       true,
+}
+
+// SearchResult is a single matching line with its surrounding context
+type SearchResult struct {
+	Path   string
+	Line   int
+	Text   string
+	Before []string
+	After  []string
+}
+
+// SearchRequestMsg asks the model to run a search
+type SearchRequestMsg struct {
+	Query string
+	Globs []string
+}
+
+// SearchResultsMsg carries the results of a search
+type SearchResultsMsg struct {
+	Query     string
+	Results   []SearchResult
+	Source    string
+	Truncated bool
+	Err       error
+}
+
+// compileSearchPattern compiles a query as a regexp, falling back to a literal
+// match; lowercase queries match case-insensitively
+func compileSearchPattern(query string) *regexp.Regexp {
+	prefix := ""
+	if strings.IndexFunc(query, unicode.IsUpper) < 0 {
+		prefix = "(?i)"
+	}
+	if re, err := regexp.Compile(prefix + query); err == nil {
+		return re
+	}
+	return regexp.MustCompile(prefix + regexp.QuoteMeta(query))
+}
+
+// parseGlobs splits a comma or space separated glob list
+func parseGlobs(input string) []string {
+	return strings.FieldsFunc(input, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+}
+
+// matchesGlobs checks a relative path against the globs; "!" excludes
+func matchesGlobs(path string, globs []string) bool {
+	included := true
+	hasInclude := false
+	for _, glob := range globs {
+		exclude := strings.HasPrefix(glob, "!")
+		pattern := strings.TrimPrefix(glob, "!")
+		matched, _ := filepath.Match(pattern, filepath.Base(path))
+		if !matched {
+			matched, _ = filepath.Match(pattern, path)
+		}
+		if exclude {
+			if matched {
+				return false
+			}
+			continue
+		}
+		if !hasInclude {
+			hasInclude = true
+			included = false
+		}
+		included = included || matched
+	}
+	return included
+}
+
+// searchLocal searches files under root, returning errProjectTooLarge when the
+// tree has more files than the embedded search handles
+func searchLocal(root, query string, globs []string) ([]SearchResult, bool, error) {
+	re := compileSearchPattern(query)
+
+	var files []string
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		name := entry.Name()
+		if entry.IsDir() {
+			if path != root && (strings.HasPrefix(name, ".") || searchSkipDirs[name]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasPrefix(name, ".") {
+			return nil
+		}
+		if len(files) >= maxSearchFiles {
+			return errProjectTooLarge
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			rel = path
+		}
+		if matchesGlobs(rel, globs) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	var results []SearchResult
+	for _, path := range files {
+		matches, err := searchFile(path, re)
+		if err != nil {
+			continue
+		}
+		results = append(results, matches...)
+		if len(results) >= maxSearchResults {
+			return results[:maxSearchResults], true, nil
+		}
+	}
+	return results, false, nil
+}
+
+// searchFile returns the matching lines of a text file
+func searchFile(path string, re *regexp.Regexp) ([]SearchResult, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() > maxSearchFileSize {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.IndexByte(data, 0) >= 0 {
+		return nil, nil
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxSearchFileSize)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	var results []SearchResult
+	for i, line := range lines {
+		if !re.MatchString(line) {
+			continue
+		}
+		start := max(0, i-searchContextLines)
+		end := min(len(lines), i+1+searchContextLines)
+		results = append(results, SearchResult{
+			Path:   path,
+			Line:   i + 1,
+			Text:   line,
+			Before: lines[start:i],
+			After:  lines[i+1 : end],
+		})
+	}
+	return results, nil
+}
+
+// runSearch searches locally when possible and on the server for large
+// projects or server-backed file trees
+func (m Model) runSearch(query string, globs []string) tea.Cmd {
+	root := m.fileTree.FileSystem().Root()
+	client, hasServer := m.phoenixClient.(*phoenix.Client)
+	hasServer = hasServer && m.channel != nil && !m.offline && m.capabilities.Has(phoenix.CapabilitySearch)
+	useServer := hasServer && m.fileTree.FileSystem().Name() == FileSourceServer
+
+	return func() tea.Msg {
+		if !useServer {
+			results, truncated, err := searchLocal(root, query, globs)
+			if err != errProjectTooLarge || !hasServer {
+				if err == errProjectTooLarge {
+					err = fmt.Errorf("more than %d files - connect to the server to search this project", maxSearchFiles)
+				}
+				return SearchResultsMsg{Query: query, Results: results, Source: FileSourceLocal, Truncated: truncated, Err: err}
+			}
+		}
+
+		matches, err := client.SearchCode(context.Background(), query, globs, searchContextLines)
+		if err != nil {
+			return SearchResultsMsg{Query: query, Source: FileSourceServer, Err: err}
+		}
+		results := make([]SearchResult, 0, len(matches))
+		for _, match := range matches {
+			results = append(results, SearchResult(match))
+		}
+		return SearchResultsMsg{Query: query, Results: results, Source: FileSourceServer, Truncated: len(results) >= maxSearchResults}
+	}
+}
+
+// SearchPane is the project-wide search overlay
+type SearchPane struct {
+	queryInput textinput.Model
+	globInput  textinput.Model
+	globFocus  bool
+	results    []SearchResult
+	selected   int
+	lastQuery  string
+	lastGlobs  string
+	searching  bool
+	status     string
+	visible    bool
+	width      int
+	height     int
+}
+
+// NewSearchPane creates a new search pane
+func NewSearchPane() SearchPane {
+	queryInput := textinput.New()
+	queryInput.Placeholder = "regexp or text"
+	queryInput.Prompt = "Search: "
+
+	globInput := textinput.New()
+	globInput.Placeholder = "*.ex, lib/**, !*_test.go"
+	globInput.Prompt = "Files:  "
+
+	return SearchPane{
+		queryInput: queryInput,
+		globInput:  globInput,
+		width:      80,
+		height:     24,
+	}
+}
+
+// Show displays the search pane, optionally seeding the query
+func (sp *SearchPane) Show(query string) {
+	sp.visible = true
+	sp.globFocus = false
+	if query != "" {
+		sp.queryInput.SetValue(query)
+	}
+	sp.queryInput.CursorEnd()
+	sp.queryInput.Focus()
+	sp.globInput.Blur()
+}
+
+// Hide hides the search pane
+func (sp *SearchPane) Hide() {
+	sp.visible = false
+	sp.queryInput.Blur()
+	sp.globInput.Blur()
+}
+
+// IsVisible returns whether the search pane is visible
+func (sp SearchPane) IsVisible() bool {
+	return sp.visible
+}
+
+// SetSize updates the pane dimensions
+func (sp *SearchPane) SetSize(width, height int) {
+	sp.width = width
+	sp.height = height
+	sp.queryInput.Width = width - 14
+	sp.globInput.Width = width - 14
+}
+
+// SetResults shows the results of a finished search
+func (sp *SearchPane) SetResults(msg SearchResultsMsg) {
+	// Ignore results from an outdated query
+	if msg.Query != sp.lastQuery {
+		return
+	}
+	sp.searching = false
+	sp.results = msg.Results
+	sp.selected = 0
+
+	switch {
+	case msg.Err != nil:
+		sp.status = fmt.Sprintf("Search failed: %v", msg.Err)
+	case len(msg.Results) == 0:
+		sp.status = fmt.Sprintf("No matches (%s)", msg.Source)
+	case msg.Truncated:
+		sp.status = fmt.Sprintf("First %d matches (%s)", len(msg.Results), msg.Source)
+	default:
+		sp.status = fmt.Sprintf("%d matches (%s)", len(msg.Results), msg.Source)
+	}
+}
+
+// Search starts a search for the current inputs
+func (sp *SearchPane) Search() tea.Cmd {
+	query := sp.queryInput.Value()
+	if strings.TrimSpace(query) == "" {
+		return nil
+	}
+	sp.lastQuery = query
+	sp.lastGlobs = sp.globInput.Value()
+	sp.searching = true
+	sp.status = "Searching..."
+	globs := parseGlobs(sp.lastGlobs)
+	return func() tea.Msg {
+		return SearchRequestMsg{Query: query, Globs: globs}
+	}
+}
+
+// Update handles search pane input
+func (sp SearchPane) Update(msg tea.Msg) (SearchPane, tea.Cmd) {
+	if !sp.visible {
+		return sp, nil
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return sp, nil
+	}
+
+	switch keyMsg.String() {
+	case "esc":
+		sp.Hide()
+		return sp, nil
+	case "tab", "shift+tab":
+		sp.globFocus = !sp.globFocus
+		if sp.globFocus {
+			sp.queryInput.Blur()
+			sp.globInput.Focus()
+		} else {
+			sp.globInput.Blur()
+			sp.queryInput.Focus()
+		}
+		return sp, nil
+	case "up", "ctrl+k":
+		if sp.selected > 0 {
+			sp.selected--
+		}
+		return sp, nil
+	case "down", "ctrl+j":
+		if sp.selected < len(sp.results)-1 {
+			sp.selected++
+		}
+		return sp, nil
+	case "enter":
+		// Enter searches when the inputs changed, otherwise opens the selection
+		if sp.queryInput.Value() != sp.lastQuery || sp.globInput.Value() != sp.lastGlobs || len(sp.results) == 0 {
+			return sp, sp.Search()
+		}
+		result := sp.results[sp.selected]
+		sp.Hide()
+		return sp, func() tea.Msg {
+			return FileSelectedMsg{Path: result.Path, Line: result.Line}
+		}
+	}
+
+	var cmd tea.Cmd
+	if sp.globFocus {
+		sp.globInput, cmd = sp.globInput.Update(msg)
+	} else {
+		sp.queryInput, cmd = sp.queryInput.Update(msg)
+	}
+	return sp, cmd
+}
+
+// View renders the search pane
+func (sp SearchPane) View() string {
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("62"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	pathStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("39"))
+	selectedStyle := lipgloss.NewStyle().
+		Background(lipgloss.Color("62")).
+		Foreground(lipgloss.Color("230"))
+
+	lines := []string{
+		titleStyle.Render("Search Project"),
+		sp.queryInput.View(),
+		sp.globInput.View(),
+		dimStyle.Render(sp.status),
+		"",
+	}
+
+	// Each result takes a header line plus its context lines
+	var body []string
+	selectedLine := 0
+	for i, result := range sp.results {
+		if i == sp.selected {
+			selectedLine = len(body)
+		}
+		header := fmt.Sprintf("%s:%d", result.Path, result.Line)
+		if i == sp.selected {
+			body = append(body, selectedStyle.Render(header))
+		} else {
+			body = append(body, pathStyle.Render(header))
+		}
+		for _, line := range result.Before {
+			body = append(body, dimStyle.Render("  "+sp.clip(line)))
+		}
+		body = append(body, "> "+sp.clip(result.Text))
+		for _, line := range result.After {
+			body = append(body, dimStyle.Render("  "+sp.clip(line)))
+		}
+	}
+
+	// Scroll so the selected result stays visible
+	visible := sp.height - len(lines) - 2
+	if visible < 1 {
+		visible = len(body)
+	}
+	start := 0
+	if selectedLine+2*searchContextLines+2 > visible {
+		start = selectedLine + 2*searchContextLines + 2 - visible
+	}
+	end := min(len(body), start+visible)
+	if start < end {
+		lines = append(lines, body[start:end]...)
+	}
+
+	lines = append(lines, dimStyle.Render("Enter: search/open • Tab: query/files • ↑/↓: select • Esc: close"))
+	return strings.Join(lines, "\n")
+}
+
+// clip shortens a line to the pane width
+func (sp SearchPane) clip(line string) string {
+	line = strings.ReplaceAll(line, "\t", "    ")
+	if sp.width > 8 && lipgloss.Width(line) > sp.width-4 {
+		return truncateStage(line, sp.width-4)
+	}
+	return line
+}
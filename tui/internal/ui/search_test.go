@@ -0,0 +1,58 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSearchLocal(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"lib/app.ex":        "defmodule App do\n  def start(), do: :ok\nend\n",
+		"lib/app_test.exs":  "defmodule AppTest do\n  test \"start\"\nend\n",
+		"README.md":         "Start here\n",
+		".git/config":       "start\n",
+		"deps/dep/lib/x.ex": "def start\n",
+	}
+	for path, content := range files {
+		full := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Lowercase queries are case-insensitive; hidden and dependency dirs are skipped
+	results, truncated, err := searchLocal(dir, "start", nil)
+	if err != nil || truncated {
+		t.Fatalf("Unexpected error %v (truncated %v)", err, truncated)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 matches, got %d: %#v", len(results), results)
+	}
+
+	// Globs restrict the files searched, with context lines around the match
+	results, _, _ = searchLocal(dir, "def start", []string{"*.ex"})
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 match in *.ex, got %d", len(results))
+	}
+	match := results[0]
+	if match.Line != 2 || len(match.Before) != 1 || len(match.After) != 1 {
+		t.Errorf("Unexpected match %#v", match)
+	}
+
+	// Excluded globs remove files; an uppercase query is case-sensitive
+	results, _, _ = searchLocal(dir, "Start", []string{"!*.md"})
+	if len(results) != 0 {
+		t.Errorf("Expected no matches, got %#v", results)
+	}
+
+	// Invalid regexps fall back to a literal search
+	results, _, _ = searchLocal(dir, "start(", nil)
+	if len(results) != 1 {
+		t.Errorf("Expected literal fallback match, got %d", len(results))
+	}
+}
@@ -0,0 +1,71 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// formatProviderCredentials renders /providers with the server's masked values
+func formatProviderCredentials(credentials []phoenix.ProviderCredential) string {
+	if len(credentials) == 0 {
+		return "No provider credentials stored. Use /providers configure <provider> to add some"
+	}
+	var b strings.Builder
+	b.WriteString("Provider credentials:\n\n")
+	for _, credential := range credentials {
+		fmt.Fprintf(&b, "%s\n", credential.Provider)
+		fields := make([]string, 0, len(credential.Fields))
+		for field := range credential.Fields {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			fmt.Fprintf(&b, "  %s: %s\n", field, maskCredential(field, credential.Fields[field]))
+		}
+		if !credential.UpdatedAt.IsZero() {
+			fmt.Fprintf(&b, "  Updated: %s\n", credential.UpdatedAt.Local().Format("2006-01-02 15:04:05"))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("Use /providers configure|test|remove <provider>")
+	return b.String()
+}
+
+// maskCredential masks a secret the server sent unmasked, leaving base URLs
+// and values that are already masked alone
+func maskCredential(field, value string) string {
+	if field == "base_url" || strings.ContainsAny(value, "…•*") {
+		return value
+	}
+	if len(value) <= 10 {
+		return "••••"
+	}
+	return value[:3] + "…" + value[len(value)-4:]
+}
+
+// formatSessionList renders /sessions
+func formatSessionList(sessions []phoenix.AuthSession) string {
+	if len(sessions) == 0 {
+		return "No active sessions"
+	}
+	var b strings.Builder
+	b.WriteString("Active sessions:\n\n")
+	for _, session := range sessions {
+		fmt.Fprintf(&b, "ID: %s", session.ID)
+		if session.Current {
+			b.WriteString(" (this session)")
+		}
+		fmt.Fprintf(&b, "\nClient: %s\n", session.Client)
+		if session.IP != "" {
+			fmt.Fprintf(&b, "IP: %s\n", session.IP)
+		}
+		fmt.Fprintf(&b, "Signed in: %s\nLast seen: %s\n\n",
+			session.CreatedAt.Local().Format("2006-01-02 15:04:05"),
+			session.LastSeenAt.Local().Format("2006-01-02 15:04:05"))
+	}
+	b.WriteString("Use /sessions revoke <id> to sign a session out")
+	return b.String()
+}
@@ -0,0 +1,221 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// SlashCommand describes a chat slash command for in-app help, --help, and
+// the man page
+type SlashCommand struct {
+	Name     string   // Without the leading slash
+	Aliases  []string // Other names handleSlashCommand accepts
+	Args     string   // Argument synopsis, e.g. "<name> [provider]"
+	Summary  string
+	Examples []string
+	// Permission the server must grant for the command to run, if any
+	Permission string
+	Related    []string // Names of commands shown under "See also"
+}
+
+// SlashCommands lists every slash command in the order help shows them
+var SlashCommands = []SlashCommand{
+	{Name: "help", Aliases: []string{"h", "?"}, Args: "[command | errors [code]]", Summary: "Show help, a command's examples and related commands, or what an error code means and how to fix it",
+		Examples: []string{"/help", "/help model", "/help errors", "/help errors E101"}},
+	{Name: "tutorial", Aliases: []string{"tour"}, Args: "[next|quit]", Summary: "Take a guided tour; practices against a simulated server when none is connected",
+		Examples: []string{"/tutorial", "/tutorial next", "/tutorial quit"}, Related: []string{"help"}},
+	{Name: "model", Aliases: []string{"m"}, Args: "<name> [provider] [--global]", Summary: "Set the AI model of this conversation, optionally with its provider; --global changes the default",
+		Examples: []string{"/model gpt-4", "/model claude-3-opus anthropic", "/model --global gpt-4", "/model default"}, Related: []string{"provider", "temperature", "fallback", "budget"}},
+	{Name: "providers", Args: "[configure|test|remove <provider>]", Summary: "Manage the provider credentials stored on the server: list them masked, set, ping, or remove",
+		Examples: []string{"/providers", "/providers configure openai", "/providers test openai", "/providers remove openai"}, Related: []string{"provider"}},
+	{Name: "models", Summary: "Pick a model from the providers' list, with context size, relative cost, and recent latency (Alt+M)", Related: []string{"model"}},
+	{Name: "temperature", Aliases: []string{"temp"}, Args: "<0-2|default> [--global]", Summary: "Set the temperature of this conversation; --global changes the default",
+		Examples: []string{"/temperature 0.2", "/temperature --global 0.7", "/temperature default"}, Related: []string{"model"}},
+	{Name: "provider", Aliases: []string{"p"}, Args: "<name>", Summary: "Set the provider for the current model; ollama-local talks to a local Ollama server",
+		Examples: []string{"/provider azure", "/provider ollama-local"}, Related: []string{"model", "fallback"}},
+	{Name: "plan", Args: "<query> | cancel", Summary: "Start or cancel an AI planning session",
+		Examples: []string{"/plan create a REST API for user management", "/plan cancel"}, Permission: phoenix.PermissionPlanning},
+	{Name: "compose", Summary: "Write a long prompt in a full-screen editor"},
+	{Name: "attach", Args: "<file>", Summary: "Attach a file to the next message; files over 256 KB are uploaded to the server in chunks",
+		Examples: []string{"/attach main.go"}, Related: []string{"compose"}},
+	{Name: "search", Aliases: []string{"grep"}, Args: "[query]", Summary: "Search the project (regexp, glob filters)",
+		Examples: []string{"/search TODO"}},
+	{Name: "index", Args: "[build [--force]|status]", Summary: "Index the project on the server so answers can retrieve relevant files, or show the index's state",
+		Examples: []string{"/index build", "/index build --force", "/index status"}, Related: []string{"search"}},
+	{Name: "outline", Aliases: []string{"symbols"}, Summary: "Toggle the symbol outline for the open file"},
+	{Name: "spellcheck", Aliases: []string{"spell"}, Args: "<on|off>", Summary: "Spellcheck the input against a hunspell word list"},
+	{Name: "lint", Args: "<on|off>", Summary: "Warn about empty prompts, unclosed code fences, and missing context"},
+	{Name: "cache", Args: "[clear [event]]", Summary: "Show which server answers are cached and how often the cache answered; clear drops them, or only one event's",
+		Examples: []string{"/cache", "/cache clear", "/cache clear list_files"}, Related: []string{"stats"}},
+	{Name: "metrics", Summary: "Show where Prometheus metrics are served and command traces exported, with totals since start",
+		Examples: []string{"/metrics"}, Related: []string{"audit", "stats"}},
+	{Name: "incognito", Aliases: []string{"private"}, Args: "[on|off]", Summary: "Keep new messages out of saved conversations and off the disk, with an indicator while on",
+		Examples: []string{"/incognito", "/incognito off"}, Related: []string{"saved"}},
+	{Name: "lock", Args: "[pin [off]]", Summary: "Lock the screen until your password or PIN is entered; pin chooses the PIN, pin off removes it",
+		Examples: []string{"/lock", "/lock pin", "/lock pin off"}},
+	{Name: "permissions", Args: "[reset [action]]", Summary: "List the remembered answers to prompts before writing local files or running local programs; reset forgets them",
+		Examples: []string{"/permissions", "/permissions reset exec:gofmt", "/permissions reset"}, Related: []string{"apply", "format"}},
+	{Name: "trust", Args: "[accept|forget <host>]", Summary: "List the servers pinned on first connect; accept trusts a server whose certificate or instance changed, forget unpins one",
+		Examples: []string{"/trust", "/trust accept", "/trust forget duck.example.com:443"}, Related: []string{"doctor"}},
+	{Name: "links", Summary: "Label the links in view with letters (also Alt+L); typing one opens it in the browser, or in uppercase copies it (tui.links changes which)",
+		Examples: []string{"/links"}},
+	{Name: "paths", Summary: "Label the file paths in the assistant messages in view (also Alt+P); typing one opens it in the editor at the line mentioned, or in uppercase copies it",
+		Examples: []string{"/paths"}, Related: []string{"links"}},
+	{Name: "emoji", Aliases: []string{"symbol"}, Args: "[search]", Summary: "Pick an emoji or unicode symbol to insert in the input (also Ctrl+. or Alt+.), searching by name or code point like U+2713; the ones you use most are listed first",
+		Examples: []string{"/emoji", "/emoji check", "/emoji u+2192"}},
+	{Name: "prefix", Args: "[suffix] [text | edit | off]", Summary: "Show or set text added before (or with suffix, after) every message sent in this conversation, such as \"answer concisely\" or project conventions",
+		Examples: []string{"/prefix", "/prefix Answer concisely.", "/prefix suffix Follow the conventions in CONTRIBUTING.md", "/prefix edit", "/prefix off"}, Related: []string{"model", "temperature"}},
+	{Name: "macro", Aliases: []string{"macros"}, Args: "[save <register> <name> | load <name> [register] | play <name|register> | delete <name>]", Summary: "List the keyboard macros recorded with Alt+Q, save one under a name for later sessions, load, play, or delete it",
+		Examples: []string{"/macro", "/macro save a review", "/macro load review", "/macro play review"}},
+	{Name: "encrypt", Args: "[migrate]", Summary: "Show whether saved conversations and history are encrypted; migrate encrypts the files saved before it was turned on",
+		Examples: []string{"/encrypt", "/encrypt migrate"}, Related: []string{"saved", "incognito"}},
+	{Name: "memory", Aliases: []string{"mem"}, Summary: "Estimate the memory held by the chat, its renderings, the editor, and applied changes, with the process totals",
+		Examples: []string{"/memory"}, Related: []string{"stats"}},
+	{Name: "audit", Args: "[count]", Summary: "Show the last commands run, with when, how long they took, whether they worked, and a hash of their arguments",
+		Examples: []string{"/audit", "/audit 50"}, Related: []string{"doctor"}},
+	{Name: "queue", Args: "[bump|cancel <#>]", Summary: "Show the server commands running and waiting for a slot, or move one to the front or drop it",
+		Examples: []string{"/queue", "/queue bump 2", "/queue cancel 3"}},
+	{Name: "problems", Aliases: []string{"errors"}, Summary: "List recent errors grouped by component, with counts, first and last occurrence, and details",
+		Related: []string{"doctor", "help"}},
+	{Name: "stats", Summary: "Show response latency and throughput per model, and the bytes sent and received over the sockets"},
+	{Name: "doctor", Args: "[save [file]]", Summary: "Copy the version, terminal, redacted config, connection state, and recent errors for a bug report, or save them to a file",
+		Examples: []string{"/doctor", "/doctor save", "/doctor save report.txt"}},
+	{Name: "saved", Aliases: []string{"history"}, Args: "[number | tag <tag> | archived]", Summary: "List or open saved conversations (works offline)",
+		Examples: []string{"/saved", "/saved 2", "/saved tag bug-hunt", "/saved archived"}, Related: []string{"conversation", "tag"}},
+	{Name: "goto-date", Aliases: []string{"goto"}, Args: "<YYYY-MM-DD|today|yesterday>", Summary: "Scroll the history to the first message on or after a date",
+		Examples: []string{"/goto-date 2026-01-15", "/goto-date yesterday"}},
+	{Name: "pin", Summary: "Pin the last answer to the pinned panel (Alt+Up, then p, pins any message)",
+		Examples: []string{"/pin"}, Related: []string{"pins"}},
+	{Name: "pins", Summary: "Expand or collapse the pinned panel",
+		Examples: []string{"/pins"}, Related: []string{"pin"}},
+	{Name: "conversation", Aliases: []string{"conv"}, Args: "archive|delete [number] | unarchive <id>", Summary: "Archive or delete the current or a saved conversation",
+		Examples: []string{"/conversation archive", "/conversation delete 2", "/conversation unarchive local-20260101-120000"}, Related: []string{"saved", "clear"}},
+	{Name: "tag", Aliases: []string{"tags"}, Args: "add|remove <tag> [number] | list", Summary: "Tag conversations to organize and filter them",
+		Examples: []string{"/tag add bug-hunt", "/tag remove bug-hunt 2", "/tag list"}, Related: []string{"saved"}},
+	{Name: "source", Args: "<local|server>", Summary: "Choose where the file tree and editor get files"},
+	{Name: "reload", Aliases: []string{"keep", "diff"}, Summary: "Resolve an open file changed on disk: reload it, keep your buffer, or diff"},
+	{Name: "budget", Aliases: []string{"cost"}, Args: "[<conversation|day> <usd>]", Summary: "Show estimated spend or set a limit",
+		Examples: []string{"/budget", "/budget day 5"}},
+	{Name: "fallback", Args: "[set <provider/model ...>|auto <on|off>]", Summary: "Retry a failed prompt with the next fallback model",
+		Examples: []string{"/fallback set openai/gpt-4 ollama/llama3", "/fallback auto on"}, Related: []string{"model", "retry"}},
+	{Name: "retry", Summary: "Send a request that timed out again"},
+	{Name: "telemetry", Args: "<on|off|status|upload>", Summary: "Anonymous usage counts"},
+	{Name: "timestamps", Aliases: []string{"ts"}, Args: "[on|off|toggle]", Summary: "Control timestamps in status messages"},
+	{Name: "display", Args: "[timestamps|clock|compact|glyph] [value]", Summary: "Show or change chat timestamps, clock format, compact mode, and role glyphs"},
+	{Name: "dead-letters", Aliases: []string{"deadletters", "unhandled"}, Summary: "List server events that couldn't be parsed or routed, with their raw payloads"},
+	{Name: "status-info", Summary: "Show every info update in the status pane, or collapse runs of them again", Related: []string{"dismiss"}},
+	{Name: "cues", Aliases: []string{"cue"}, Args: "[<event> <cues> | test <event>]", Summary: "Show or set what a received message, an error, or a finished plan does: ring the bell, flash the status bar, show a toast, or nothing (comma-separate several)",
+		Examples: []string{"/cues", "/cues error bell,toast", "/cues plan_done toast", "/cues message_received none", "/cues error default", "/cues test error"}, Related: []string{"status-colors"}},
+	{Name: "subscriptions", Aliases: []string{"subs"}, Summary: "List the status categories the server offers and toggle which this conversation subscribes to; the choice is saved with the conversation",
+		Related: []string{"status-colors", "status-info"}},
+	{Name: "status-colors", Aliases: []string{"legend"}, Summary: "Show the status categories with their colors and the server's descriptions, and re-color them; Enter saves the colors to the config",
+		Related: []string{"status-info"}},
+	{Name: "dismiss", Summary: "Unpin the critical updates at the top of the status pane", Related: []string{"status-info"}},
+	{Name: "config", Args: "<save|load>", Summary: "Save or load the default provider and model"},
+	{Name: "project", Args: "[open <dir>]", Summary: "Show the project and the settings its .rubber_duck.toml overrides, or open another project",
+		Examples: []string{"/project", "/project open ../api"}, Related: []string{"config"}},
+	{Name: "clear", Aliases: []string{"cls", "new"}, Summary: "Start a new conversation", Related: []string{"undo-clear", "saved"}},
+	{Name: "undo-clear", Aliases: []string{"undo"}, Summary: "Bring back the most recently cleared conversation", Related: []string{"clear"}},
+	{Name: "send-unredacted", Summary: "Send a message held back for secrets without masking them"},
+	{Name: "show-more", Summary: "Show the most recent truncated response in full"},
+	{Name: "apply", Summary: "Review the last refactor's changes: hunk by hunk for the open file, file by file across the project", Related: []string{"rollback", "save"}},
+	{Name: "rollback", Summary: "Restore the files written by the last applied review", Related: []string{"apply", "patch"}},
+	{Name: "react", Args: "[pause|resume|abort|export]", Summary: "Show the live view of the running ReAct execution, or pause, abort, or export its trace", Examples: []string{"/react", "/react pause", "/react export"}},
+	{Name: "patch", Args: "[file] | pr [title]", Summary: "Export the applied changes as a unified diff, or open a pull request with them on the server", Examples: []string{"/patch", "/patch fix-login.patch", "/patch pr Fix login redirect"}, Related: []string{"apply", "rollback"}},
+	{Name: "save", Summary: "Save the open file, formatting it first when format_on_save is set", Related: []string{"format"}},
+	{Name: "format", Aliases: []string{"fmt"}, Summary: "Format the open file with gofmt, prettier, black, or the server", Related: []string{"save"}},
+	{Name: "regenerate", Aliases: []string{"regen"}, Summary: "Ask for a new answer to the last prompt, showing what changed", Related: []string{"changes"}},
+	{Name: "changes", Summary: "Show or hide the changes in the last regenerated answer", Related: []string{"regenerate"}},
+	{Name: "trace", Summary: "Expand or collapse the tool calls behind the last answer that made any"},
+	{Name: "tree", Aliases: []string{"files"}, Summary: "Toggle the file tree"},
+	{Name: "editor", Aliases: []string{"edit"}, Summary: "Toggle the editor"},
+	{Name: "commands", Aliases: []string{"cmds", "palette"}, Summary: "Show the command palette"},
+	{Name: "login", Args: "[username]", Summary: "Log in to the server with a masked password form",
+		Examples: []string{"/login", "/login duck"}, Related: []string{"logout", "register", "apikey", "status"}},
+	{Name: "register", Aliases: []string{"signup"}, Args: "[username]", Summary: "Create an account"},
+	{Name: "password", Aliases: []string{"passwd"}, Args: "change", Summary: "Change your password"},
+	{Name: "logout", Summary: "Log out from the server"},
+	{Name: "apikey", Aliases: []string{"api-key"}, Args: "<generate|list|revoke <id>|save <key>>", Summary: "Manage API keys",
+		Examples: []string{"/apikey generate", "/apikey revoke key-1"}, Permission: phoenix.PermissionAPIKeys, Related: []string{"login", "sessions"}},
+	{Name: "sessions", Args: "[revoke <id>]", Summary: "List the account's active sessions or sign one out",
+		Examples: []string{"/sessions", "/sessions revoke session-3"}, Permission: phoenix.PermissionSessions, Related: []string{"apikey", "logout"}},
+	{Name: "status", Aliases: []string{"auth"}, Summary: "Show authentication status"},
+	{Name: "admin", Summary: "Server metrics, provider health, and maintenance (admins only)", Permission: phoenix.PermissionAdmin},
+	{Name: "quit", Aliases: []string{"exit", "q"}, Summary: "Quit the application"},
+}
+
+// Usage returns the command with its argument synopsis, e.g. "/model <name> [provider]"
+func (c SlashCommand) Usage() string {
+	if c.Args == "" {
+		return "/" + c.Name
+	}
+	return "/" + c.Name + " " + c.Args
+}
+
+// LookupSlashCommand finds a command by name or alias, with or without the
+// leading slash
+func LookupSlashCommand(name string) (SlashCommand, bool) {
+	name = strings.ToLower(strings.TrimPrefix(name, "/"))
+	for _, c := range SlashCommands {
+		if c.Name == name {
+			return c, true
+		}
+		for _, alias := range c.Aliases {
+			if alias == name {
+				return c, true
+			}
+		}
+	}
+	return SlashCommand{}, false
+}
+
+// GetCommandHelp renders the help page for a command: usage, aliases,
+// examples, the permission it needs, and related commands
+func GetCommandHelp(name string) (string, bool) {
+	c, ok := LookupSlashCommand(name)
+	if !ok {
+		return "", false
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n%s\n", c.Usage(), c.Summary)
+	if len(c.Aliases) > 0 {
+		fmt.Fprintf(&b, "\nAliases: /%s\n", strings.Join(c.Aliases, ", /"))
+	}
+	if len(c.Examples) > 0 {
+		b.WriteString("\nExamples:\n")
+		for _, example := range c.Examples {
+			fmt.Fprintf(&b, "  %s\n", example)
+		}
+	}
+	if c.Permission != "" {
+		fmt.Fprintf(&b, "\nRequires permission to %s\n", permissionNames[c.Permission])
+	}
+	if len(c.Related) > 0 {
+		b.WriteString("\nSee also:\n")
+		for _, name := range c.Related {
+			if related, ok := LookupSlashCommand(name); ok {
+				fmt.Fprintf(&b, "  %s - %s\n", related.Usage(), related.Summary)
+			}
+		}
+	}
+	return strings.TrimRight(b.String(), "\n"), true
+}
+
+// FormatSlashCommandList renders one line per command, for help text
+func FormatSlashCommandList() string {
+	width := 0
+	for _, c := range SlashCommands {
+		if n := len(c.Usage()); n > width && n <= 28 {
+			width = n
+		}
+	}
+
+	var b strings.Builder
+	for _, c := range SlashCommands {
+		fmt.Fprintf(&b, "%-*s - %s\n", width, c.Usage(), c.Summary)
+	}
+	return b.String()
+}
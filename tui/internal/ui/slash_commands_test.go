@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSlashCommandRegistryMatchesHandler(t *testing.T) {
+	chat := NewChat()
+	for _, c := range SlashCommands {
+		if c.Args == "" || c.Args[0] == '[' {
+			for _, name := range append([]string{c.Name}, c.Aliases...) {
+				if chat.handleSlashCommand("/"+name) == nil {
+					t.Errorf("/%s is listed but not handled", name)
+				}
+			}
+		}
+		for _, example := range c.Examples {
+			if chat.handleSlashCommand(example) == nil {
+				t.Errorf("Example %q for /%s is not handled", example, c.Name)
+			}
+		}
+	}
+}
+
+func TestCommandHelpPages(t *testing.T) {
+	for _, c := range SlashCommands {
+		for _, name := range c.Related {
+			if _, ok := LookupSlashCommand(name); !ok {
+				t.Errorf("/%s lists unknown related command %q", c.Name, name)
+			}
+		}
+	}
+
+	page, ok := GetCommandHelp("/m")
+	if !ok || !strings.Contains(page, "/model <name> [provider]") || !strings.Contains(page, "/model gpt-4") || !strings.Contains(page, "See also:\n  /provider <name>") {
+		t.Errorf("Unexpected help page for /m:\n%s", page)
+	}
+	if _, ok := GetCommandHelp("nope"); ok {
+		t.Error("Expected no help page for an unknown command")
+	}
+
+	modal := NewModal()
+	modal.SetSize(80, 20)
+	modal.Show(HelpModal, "Help", strings.Repeat("line\n", 40)+"last")
+	for i := 0; i < 50; i++ {
+		modal, _ = modal.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	if !strings.Contains(modal.View(), "last") {
+		t.Error("Expected scrolling to reach the end of a tall help page")
+	}
+}
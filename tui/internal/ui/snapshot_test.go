@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"errors"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/phoenix"
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+// snapshotModel creates a model at the fixed snapshot size with a frozen clock
+func snapshotModel(t *testing.T) Model {
+	t.Helper()
+	testutil.IsolateHome(t)
+	testutil.FreezeClock(t)
+	return applyMsgs(*NewModel(), tea.WindowSizeMsg{Width: testutil.SnapshotWidth, Height: testutil.SnapshotHeight})
+}
+
+// applyMsgs feeds messages to the model, dropping the returned commands
+func applyMsgs(m Model, msgs ...tea.Msg) Model {
+	for _, msg := range msgs {
+		updated, _ := m.Update(msg)
+		m = updated.(Model)
+	}
+	return m
+}
+
+func TestViewSnapshots(t *testing.T) {
+	tests := []struct {
+		name string
+		msgs []tea.Msg
+	}{
+		{
+			name: "connected",
+			msgs: []tea.Msg{phoenix.ConnectedMsg{SocketType: phoenix.UserSocketType}},
+		},
+		{
+			name: "error",
+			msgs: []tea.Msg{phoenix.ErrorMsg{Err: errors.New("dial tcp 127.0.0.1:5555: connection refused"), Component: "Phoenix Connection"}},
+		},
+		{
+			name: "streaming",
+			msgs: []tea.Msg{
+				phoenix.ConnectedMsg{SocketType: phoenix.UserSocketType},
+				phoenix.StreamStartMsg{ID: "stream-1"},
+				phoenix.StreamDataMsg{ID: "stream-1", Data: "Rubber ducks help you "},
+				phoenix.StreamDataMsg{ID: "stream-1", Data: "explain your code out loud."},
+			},
+		},
+		{
+			name: "plan",
+			msgs: []tea.Msg{
+				phoenix.ConnectedMsg{SocketType: phoenix.UserSocketType},
+				phoenix.PlanningStartedMsg{Started: phoenix.PlanningStarted{SessionID: "plan-42"}},
+				phoenix.PlanningStepMsg{Step: phoenix.PlanningStep{StepID: "1", Type: "analysis", Description: "Read the failing test"}},
+				phoenix.PlanningCompletedMsg{Completed: phoenix.PlanningCompleted{Summary: "Fix the parser", Steps: []phoenix.PlanningStepSummary{{Description: "Read the failing test"}, {Description: "Patch the tokenizer"}}}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := applyMsgs(snapshotModel(t), tt.msgs...)
+			testutil.AssertSnapshot(t, tt.name, m.View())
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// setLastMessageSources records the project files retrieved as context for
+// the latest answer
+func (c *Chat) setLastMessageSources(sources []phoenix.RetrievedSource) {
+	if len(c.messages) == 0 || len(sources) == 0 {
+		return
+	}
+	c.messages[len(c.messages)-1].Sources = sources
+	c.viewport.SetContent(c.buildViewportContent())
+	c.viewport.GotoBottom()
+}
+
+// selectedSources returns the sources of the selected message
+func (c Chat) selectedSources() []phoenix.RetrievedSource {
+	if !c.selecting || c.selected < 0 || c.selected >= len(c.messages) {
+		return nil
+	}
+	return c.messages[c.selected].Sources
+}
+
+// cycleSource highlights the selected message's next source
+func (c *Chat) cycleSource() {
+	if sources := c.selectedSources(); len(sources) > 0 {
+		c.sourceCursor = (c.sourceCursor + 1) % len(sources)
+	}
+}
+
+// openSelectedSource opens the highlighted source in the editor at its lines
+func (c *Chat) openSelectedSource() tea.Cmd {
+	sources := c.selectedSources()
+	if c.sourceCursor >= len(sources) {
+		return nil
+	}
+	source := sources[c.sourceCursor]
+	c.stopSelection()
+	return func() tea.Msg {
+		return FileSelectedMsg{Path: source.Path, Line: source.StartLine, EndLine: source.EndLine}
+	}
+}
+
+// formatSourceRange formats a source as "path:start-end"
+func formatSourceRange(source phoenix.RetrievedSource) string {
+	switch {
+	case source.StartLine == 0:
+		return source.Path
+	case source.EndLine <= source.StartLine:
+		return fmt.Sprintf("%s:%d", source.Path, source.StartLine)
+	}
+	return fmt.Sprintf("%s:%d-%d", source.Path, source.StartLine, source.EndLine)
+}
+
+// renderSources renders the "Sources" footer of an answer, one chunk per
+// line, highlighting the one Enter opens while the answer is selected
+func (c Chat) renderSources(index int) string {
+	msg := c.messages[index]
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	pathStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("75"))
+	cursorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
+	selected := c.selecting && c.selected == index
+
+	title := fmt.Sprintf("Sources (%d)", len(msg.Sources))
+	if selected {
+		title += dimStyle.Render(" - s: next | Enter: open")
+	} else {
+		title += dimStyle.Render(" - select with Alt+↑ to open")
+	}
+	lines := []string{dimStyle.Render("📎 ") + title}
+	for i, source := range msg.Sources {
+		label := fmt.Sprintf("[%d] %s", i+1, formatSourceRange(source))
+		score := ""
+		if source.Score > 0 {
+			score = dimStyle.Render(fmt.Sprintf(" %.0f%%", source.Score*100))
+		}
+		if selected && i == c.sourceCursor {
+			lines = append(lines, cursorStyle.Render("  ▶ "+label)+score)
+		} else {
+			lines = append(lines, "    "+pathStyle.Render(label)+score)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,38 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+func TestSourcesFooterOpensSelectedSource(t *testing.T) {
+	response := phoenix.ConversationMessage{Metadata: map[string]any{
+		"retrieved_context": []any{
+			map[string]any{"path": "lib/parser.ex", "start_line": float64(10), "end_line": float64(42), "score": 0.91},
+			map[string]any{"file": "lib/lexer.ex"},
+		},
+	}}
+	chat := NewChat()
+	chat.AddMessage(AssistantMessage, "The parser skips EOF", "assistant")
+	chat.setLastMessageSources(response.RetrievedSources())
+
+	footer := chat.renderSources(0)
+	for _, want := range []string{"Sources (2)", "[1] lib/parser.ex:10-42", "91%", "[2] lib/lexer.ex"} {
+		if !strings.Contains(footer, want) {
+			t.Errorf("Expected %q in the footer, got:\n%s", want, footer)
+		}
+	}
+
+	// Select the answer, move to the second source, and open it
+	chat.startSelection()
+	model, _ := chat.updateSelection(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	*chat = model.(Chat)
+	model, cmd := chat.updateSelection(tea.KeyMsg{Type: tea.KeyEnter})
+	*chat = model.(Chat)
+	if msg, ok := cmd().(FileSelectedMsg); !ok || msg.Path != "lib/lexer.ex" || chat.IsSelecting() {
+		t.Errorf("Expected Enter to open lib/lexer.ex and end selection, got %+v", msg)
+	}
+}
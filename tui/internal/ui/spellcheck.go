@@ -0,0 +1,311 @@
+package ui
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// defaultDictionaryPaths are the usual locations of hunspell word lists
+var defaultDictionaryPaths = []string{
+	"/usr/share/hunspell/en_US.dic",
+	"/usr/share/myspell/en_US.dic",
+	"/usr/share/myspell/dicts/en_US.dic",
+	"/Library/Spelling/en_US.dic",
+	"/opt/homebrew/share/hunspell/en_US.dic",
+}
+
+var (
+	// spellSkipPattern matches text that should never be spellchecked
+	spellSkipPattern = regexp.MustCompile("(?s)```.*?(```|$)|`[^`]*`|https?://\\S+|\\S+@\\S+|\\S*[/\\\\]\\S*")
+	spellWordPattern = regexp.MustCompile(`[A-Za-z][A-Za-z']*`)
+)
+
+// SpellChecker checks words against a hunspell-style word list
+type SpellChecker struct {
+	words   map[string]struct{}
+	path    string
+	affixes *affixFile // Rules from the .aff beside a .dic, nil without one
+}
+
+// affixRule is one PFX or SFX rule of a hunspell .aff file
+type affixRule struct {
+	strip     string
+	add       string
+	condition *regexp.Regexp
+}
+
+// affixClass is the rules sharing a flag
+type affixClass struct {
+	prefix bool
+	cross  bool // Combines with the other kind of affix
+	rules  []affixRule
+}
+
+// affixFile is the part of a hunspell .aff file needed to expand the flags
+// in a .dic file into the words they stand for
+type affixFile struct {
+	flagType string // "long", "num", or single characters otherwise
+	classes  map[string]*affixClass
+}
+
+// NewSpellChecker creates a spellchecker from the configured dictionary or the
+// first hunspell dictionary found on the system
+func NewSpellChecker(dictionaryPath string) (*SpellChecker, error) {
+	candidates := defaultDictionaryPaths
+	if dictionaryPath != "" {
+		candidates = []string{dictionaryPath}
+	}
+
+	for _, path := range candidates {
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		checker := &SpellChecker{words: make(map[string]struct{}), path: path}
+		// Without the .aff file only the bare stems are known
+		if affixes, err := loadAffixFile(strings.TrimSuffix(path, ".dic") + ".aff"); err == nil {
+			checker.affixes = affixes
+		}
+		if err := checker.loadWordList(path, true); err != nil {
+			return nil, err
+		}
+		checker.loadPersonalWords()
+		return checker, nil
+	}
+
+	return nil, fmt.Errorf("no hunspell dictionary found (set tui.spellcheck_dictionary in config)")
+}
+
+// loadWordList reads a hunspell .dic file, expanding its affix flags, or a
+// plain one-word-per-line list
+func (s *SpellChecker) loadWordList(path string, hunspell bool) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	first := true
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		// The first line of a .dic file is the approximate word count
+		if first && hunspell {
+			first = false
+			if _, err := fmt.Sscanf(line, "%d", new(int)); err == nil {
+				continue
+			}
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// Drop morphological fields, then expand the affix flags of
+		// "word/FLAGS"
+		if idx := strings.IndexAny(line, "\t "); idx >= 0 {
+			line = line[:idx]
+		}
+		word, flags, _ := strings.Cut(line, "/")
+		s.words[strings.ToLower(word)] = struct{}{}
+		if hunspell && flags != "" && s.affixes != nil {
+			for _, form := range s.affixes.expand(word, flags) {
+				s.words[strings.ToLower(form)] = struct{}{}
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// loadAffixFile reads the flag type and PFX/SFX rules of a hunspell .aff
+// file. Rules on affixes themselves (continuation classes) are ignored
+func loadAffixFile(path string) (*affixFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	aff := &affixFile{classes: make(map[string]*affixClass)}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		switch fields[0] {
+		case "FLAG":
+			aff.flagType = fields[1]
+		case "PFX", "SFX":
+			if len(fields) < 4 {
+				continue
+			}
+			// The first line of a class is its header: flag, cross, count
+			class := aff.classes[fields[1]]
+			if class == nil {
+				aff.classes[fields[1]] = &affixClass{prefix: fields[0] == "PFX", cross: fields[2] == "Y"}
+				continue
+			}
+			rule := affixRule{strip: fields[2], add: fields[3]}
+			if rule.strip == "0" {
+				rule.strip = ""
+			}
+			rule.add, _, _ = strings.Cut(rule.add, "/")
+			if rule.add == "0" {
+				rule.add = ""
+			}
+			condition := "."
+			if len(fields) > 4 {
+				condition = fields[4]
+			}
+			if class.prefix {
+				condition = "^" + condition
+			} else {
+				condition += "$"
+			}
+			if rule.condition, err = regexp.Compile(condition); err != nil {
+				continue
+			}
+			class.rules = append(class.rules, rule)
+		}
+	}
+	return aff, scanner.Err()
+}
+
+// splitFlags splits a .dic entry's flags by the file's flag type
+func (a *affixFile) splitFlags(flags string) []string {
+	switch a.flagType {
+	case "long":
+		var split []string
+		for i := 0; i+1 < len(flags); i += 2 {
+			split = append(split, flags[i:i+2])
+		}
+		return split
+	case "num":
+		return strings.Split(flags, ",")
+	}
+	var split []string
+	for _, r := range flags {
+		split = append(split, string(r))
+	}
+	return split
+}
+
+// expand returns the forms of word its flags allow, including prefixed and
+// suffixed forms of classes that combine
+func (a *affixFile) expand(word, flags string) []string {
+	var prefixes, suffixes []*affixClass
+	for _, flag := range a.splitFlags(flags) {
+		if class := a.classes[flag]; class != nil && class.prefix {
+			prefixes = append(prefixes, class)
+		} else if class != nil {
+			suffixes = append(suffixes, class)
+		}
+	}
+
+	var forms, crossed []string
+	for _, class := range suffixes {
+		for _, rule := range class.rules {
+			if !strings.HasSuffix(word, rule.strip) || !rule.condition.MatchString(word) {
+				continue
+			}
+			form := strings.TrimSuffix(word, rule.strip) + rule.add
+			forms = append(forms, form)
+			if class.cross {
+				crossed = append(crossed, form)
+			}
+		}
+	}
+	for _, class := range prefixes {
+		for _, rule := range class.rules {
+			if !strings.HasPrefix(word, rule.strip) || !rule.condition.MatchString(word) {
+				continue
+			}
+			forms = append(forms, rule.add+strings.TrimPrefix(word, rule.strip))
+			if !class.cross {
+				continue
+			}
+			for _, suffixed := range crossed {
+				if strings.HasPrefix(suffixed, rule.strip) {
+					forms = append(forms, rule.add+strings.TrimPrefix(suffixed, rule.strip))
+				}
+			}
+		}
+	}
+	return forms
+}
+
+// loadPersonalWords adds the user's own words from ~/.rubber_duck/words.txt
+func (s *SpellChecker) loadPersonalWords() {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return
+	}
+	_ = s.loadWordList(filepath.Join(homeDir, ".rubber_duck", "words.txt"), false)
+}
+
+// Path returns the dictionary the checker was loaded from
+func (s *SpellChecker) Path() string {
+	return s.path
+}
+
+// Misspelled returns the unknown words in text, in order of appearance
+func (s *SpellChecker) Misspelled(text string) []string {
+	if s == nil || len(s.words) == 0 {
+		return nil
+	}
+
+	// Code, URLs, and paths are not prose
+	text = spellSkipPattern.ReplaceAllString(text, " ")
+
+	var misspelled []string
+	seen := make(map[string]bool)
+	for _, word := range spellWordPattern.FindAllString(text, -1) {
+		word = strings.Trim(word, "'")
+		if !s.shouldCheck(word) || seen[word] {
+			continue
+		}
+		if !s.Known(word) {
+			misspelled = append(misspelled, word)
+			seen[word] = true
+		}
+	}
+	return misspelled
+}
+
+// Known reports whether a word is in the dictionary
+func (s *SpellChecker) Known(word string) bool {
+	lower := strings.ToLower(word)
+	if _, ok := s.words[lower]; ok {
+		return true
+	}
+	// Accept simple possessives and contractions of known words
+	for _, suffix := range []string{"'s", "'ll", "'re", "'ve", "'d", "n't"} {
+		if strings.HasSuffix(lower, suffix) {
+			if _, ok := s.words[strings.TrimSuffix(lower, suffix)]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// shouldCheck skips short words, acronyms, and identifiers
+func (s *SpellChecker) shouldCheck(word string) bool {
+	if len(word) < 3 {
+		return false
+	}
+	upper := 0
+	for i, r := range word {
+		if unicode.IsUpper(r) {
+			upper++
+			// camelCase identifiers
+			if i > 0 {
+				return false
+			}
+		}
+	}
+	return upper != len(word)
+}
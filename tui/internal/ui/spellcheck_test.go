@@ -0,0 +1,112 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+// testDictionary writes a small hunspell dictionary with en_US style rules
+func testDictionary(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	aff := `SET UTF-8
+
+PFX U Y 1
+PFX U   0     un         .
+
+SFX S Y 3
+SFX S   y     ies        [^aeiou]y
+SFX S   0     s          [aeiou]y
+SFX S   0     s          [^sxzhy]
+
+SFX G Y 2
+SFX G   e     ing        e
+SFX G   0     ning       n
+`
+	dic := `5
+test/SG
+file/SG
+run/G
+do/U
+happy
+`
+	os.WriteFile(filepath.Join(dir, "en_US.aff"), []byte(aff), 0644)
+	path := filepath.Join(dir, "en_US.dic")
+	os.WriteFile(path, []byte(dic), 0644)
+	return path
+}
+
+func TestSpellChecker_Misspelled(t *testing.T) {
+	testutil.IsolateHome(t)
+	checker, err := NewSpellChecker(testDictionary(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"stems", "test the file", []string{"the"}},
+		{"suffixes from the affix file", "tests files running filing", nil},
+		{"prefixes", "undo", nil},
+		{"typos", "tset fiels tset", []string{"tset", "fiels"}},
+		{"contractions and possessives", "test's file'll", nil},
+		{"code, URLs, and paths skipped", "`qwzx` https://qwzx.dev lib/qwzx.ex", nil},
+		{"short words, acronyms, and identifiers skipped", "qw QWZX qwzxFile", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checker.Misspelled(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Misspelled(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpellChecker_Known(t *testing.T) {
+	testutil.IsolateHome(t)
+	checker, err := NewSpellChecker(testDictionary(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for word, want := range map[string]bool{
+		"Test":   true,
+		"TESTS":  true,
+		"happy":  true,
+		"happys": false, // No flags, so no forms
+		"runs":   false, // Only G applies to run
+		"undoes": false,
+	} {
+		if got := checker.Known(word); got != want {
+			t.Errorf("Known(%q) = %v, want %v", word, got, want)
+		}
+	}
+}
+
+func TestSpellChecker_PersonalWordsAndPlainLists(t *testing.T) {
+	testutil.IsolateHome(t)
+	home, _ := os.UserHomeDir()
+	os.MkdirAll(filepath.Join(home, ".rubber_duck"), 0755)
+	os.WriteFile(filepath.Join(home, ".rubber_duck", "words.txt"), []byte("# project words\nGenServer\nphoenix\n"), 0644)
+
+	// A plain list without an .aff file knows only its own words
+	list := filepath.Join(t.TempDir(), "words.dic")
+	os.WriteFile(list, []byte("hello\nworld\n"), 0644)
+	checker, err := NewSpellChecker(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := checker.Misspelled("hello phoenix world genserver worlds"); !reflect.DeepEqual(got, []string{"worlds"}) {
+		t.Errorf("Expected personal words known and nothing expanded, got %q", got)
+	}
+
+	if _, err := NewSpellChecker(filepath.Join(t.TempDir(), "missing.dic")); err == nil {
+		t.Error("Expected a missing dictionary to be reported")
+	}
+}
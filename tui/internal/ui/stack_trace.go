@@ -0,0 +1,237 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxTraceAttachments limits how many files a pasted trace offers to attach
+const maxTraceAttachments = 5
+
+// Frame references in the stack traces of the languages people paste
+var (
+	// Python: File "app/models.py", line 42, in save
+	pythonFramePattern = regexp.MustCompile(`File "([^"]+)", line (\d+)`)
+	// Go, Elixir, JavaScript, Rust, and compiler output: path/file.ext:42
+	fileLineFramePattern = regexp.MustCompile(`(?:^|[\s(\[])((?:[A-Za-z]:)?[\w.\-/\\]*[\w\-]\.[A-Za-z]{1,5}):(\d+)`)
+	// Lines that only start stack traces
+	traceMarkerPattern = regexp.MustCompile(`(?m)^(Traceback \(most recent call last\)|panic: |goroutine \d+ \[|\*\* \(\w+|Exception in thread |thread '.+' panicked at |\s+at \S)`)
+)
+
+// libraryPathMarkers mark frames in the language runtime or dependencies,
+// which aren't worth attaching
+var libraryPathMarkers = []string{"/usr/", "/go/src/", "/go/pkg/mod/", "site-packages/", "node_modules/", "deps/", "/.cargo/", "/rustc/", "<"}
+
+// stackFrame is one file:line a stack trace passes through
+type stackFrame struct {
+	Path string
+	Line int
+}
+
+// String renders the frame as path:line
+func (f stackFrame) String() string {
+	return fmt.Sprintf("%s:%d", f.Path, f.Line)
+}
+
+// StackTracePastedMsg asks to resolve the frames of a pasted stack trace
+// against the project's files before offering to attach them
+type StackTracePastedMsg struct {
+	Text string
+}
+
+// AttachTraceFilesMsg attaches the files of a pasted stack trace
+type AttachTraceFilesMsg struct {
+	Paths []string
+}
+
+// pendingTrace is a pasted stack trace waiting for the attach prompt
+type pendingTrace struct {
+	text   string
+	frames []stackFrame // Frames in project files, first per file
+}
+
+// parseStackTrace returns the frames of a stack trace in the order given
+func parseStackTrace(text string) []stackFrame {
+	var frames []stackFrame
+	for _, line := range strings.Split(text, "\n") {
+		match := pythonFramePattern.FindStringSubmatch(line)
+		if match == nil {
+			match = fileLineFramePattern.FindStringSubmatch(line)
+		}
+		// URLs like http://host.com:80 aren't frames
+		if match == nil || strings.Contains(line, "://"+match[1]) {
+			continue
+		}
+		lineNumber, err := strconv.Atoi(match[2])
+		if err != nil || lineNumber == 0 {
+			continue
+		}
+		frames = append(frames, stackFrame{Path: match[1], Line: lineNumber})
+	}
+	return frames
+}
+
+// looksLikeStackTrace reports whether pasted text is a stack trace: several
+// frames, or one after a line only traces start with
+func looksLikeStackTrace(text string) bool {
+	if !strings.Contains(text, "\n") {
+		return false
+	}
+	frames := len(parseStackTrace(text))
+	return frames >= 2 || frames == 1 && traceMarkerPattern.MatchString(text)
+}
+
+// projectFrames keeps the frames in files of the project, or local files
+// outside the runtime and dependencies, one per file
+func (m Model) projectFrames(frames []stackFrame) []stackFrame {
+	fs := m.fileTree.FileSystem()
+	local := fs.Name() == FileSourceLocal
+	root, _ := filepath.Abs(fs.Root())
+
+	seen := make(map[string]bool)
+	var kept []stackFrame
+	for _, frame := range frames {
+		path := filepath.FromSlash(frame.Path)
+		if isLibraryPath(filepath.ToSlash(path)) {
+			continue
+		}
+		if filepath.IsAbs(path) {
+			// Absolute paths into the project become project paths
+			if rel, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(rel, "..") {
+				path = rel
+			} else if !local {
+				continue
+			}
+		}
+		if local {
+			check := path
+			if !filepath.IsAbs(check) {
+				check = filepath.Join(fs.Root(), path)
+			}
+			if info, err := os.Stat(check); err != nil || info.IsDir() {
+				continue
+			}
+		}
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		kept = append(kept, stackFrame{Path: path, Line: frame.Line})
+	}
+	return kept
+}
+
+// isLibraryPath reports whether a frame is in the runtime or a dependency
+func isLibraryPath(path string) bool {
+	for _, marker := range libraryPathMarkers {
+		if strings.Contains(path, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// handleStackTracePasted offers to attach the project files a pasted trace
+// passes through
+func (m *Model) handleStackTracePasted(msg StackTracePastedMsg) {
+	frames := m.projectFrames(parseStackTrace(msg.Text))
+	if len(frames) == 0 {
+		m.chat.input.InsertString(formatStackTrace(msg.Text, nil))
+		return
+	}
+	m.chat.pendingTrace = &pendingTrace{text: msg.Text, frames: frames}
+}
+
+// attachTraceFiles opens the compose modal with a trace's files attached
+func (m *Model) attachTraceFiles(paths []string) {
+	fs := m.fileTree.FileSystem()
+	m.composeModal.SetSize(m.width, m.height)
+	m.composeModal.Show(m.chat.GetInputValue())
+	m.chat.SetInputValue("")
+	var failed []string
+	for _, path := range paths {
+		if fs.Name() == FileSourceLocal {
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(fs.Root(), path)
+			}
+			m.composeModal.addAttachment(path)
+			continue
+		}
+		data, err := fs.ReadFile(path)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s (%v)", path, err))
+			continue
+		}
+		m.composeModal.addFetchedAttachment(path, data)
+	}
+	if len(failed) > 0 {
+		m.composeModal.err = "Cannot attach " + strings.Join(failed, ", ")
+	}
+	m.statusBar = fmt.Sprintf("Composing message with %d files from the stack trace", len(m.composeModal.attachments))
+}
+
+// formatStackTrace fences a stack trace and lists its project frames after
+// it, so they stand out in the message
+func formatStackTrace(text string, frames []stackFrame) string {
+	formatted := "```text\n" + text + "\n```"
+	if len(frames) == 0 {
+		return formatted
+	}
+	refs := make([]string, len(frames))
+	for i, frame := range frames {
+		refs[i] = "`" + frame.String() + "`"
+	}
+	return formatted + "\n\n**Frames in this project:** " + strings.Join(refs, ", ")
+}
+
+// resolvePendingTrace handles the answer to the attach prompt
+func (c Chat) resolvePendingTrace(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	trace := c.pendingTrace
+	var cmd tea.Cmd
+	switch msg.String() {
+	case "y", "Y":
+		c.input.InsertString(formatStackTrace(trace.text, trace.frames))
+		frames := trace.frames
+		if len(frames) > maxTraceAttachments {
+			frames = frames[:maxTraceAttachments]
+		}
+		paths := make([]string, len(frames))
+		for i, frame := range frames {
+			paths[i] = frame.Path
+		}
+		cmd = func() tea.Msg { return AttachTraceFilesMsg{Paths: paths} }
+	case "n", "N", "enter":
+		c.input.InsertString(formatStackTrace(trace.text, trace.frames))
+	case "esc":
+		// Discard the paste entirely
+	default:
+		// Keep waiting for an answer
+		return c, nil
+	}
+	c.pendingTrace = nil
+	return c, cmd
+}
+
+// renderTracePrompt renders the attach prompt shown in place of the
+// separator
+func (c Chat) renderTracePrompt() string {
+	files := make([]string, 0, len(c.pendingTrace.frames))
+	for _, frame := range c.pendingTrace.frames {
+		files = append(files, filepath.Base(frame.Path))
+	}
+	count := len(files)
+	if count > maxTraceAttachments {
+		count = maxTraceAttachments
+	}
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color("220")).
+		Bold(true).
+		Render(truncateStage(fmt.Sprintf("Stack trace through %s - attach %d files as context? (y/n, Esc to discard)", strings.Join(files, ", "), count), max(c.width-2, 20)))
+}
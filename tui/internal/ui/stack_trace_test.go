@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+func TestParseStackTrace(t *testing.T) {
+	python := "Traceback (most recent call last):\n  File \"app/models.py\", line 42, in save\n    self.validate()\nValueError: bad"
+	if frames := parseStackTrace(python); len(frames) != 1 || frames[0] != (stackFrame{Path: "app/models.py", Line: 42}) {
+		t.Errorf("Unexpected Python frames %v", frames)
+	}
+	if !looksLikeStackTrace(python) {
+		t.Error("Expected a Python traceback to be a stack trace")
+	}
+	elixir := "** (ArgumentError) bad\n    (my_app 0.1.0) lib/my_app/worker.ex:17: MyApp.Worker.run/1\n    (elixir 1.15.0) lib/enum.ex:4: Enum.map/2"
+	if frames := parseStackTrace(elixir); len(frames) != 2 || frames[0].Path != "lib/my_app/worker.ex" {
+		t.Errorf("Unexpected Elixir frames %v", frames)
+	}
+	if looksLikeStackTrace("see http://example.com:8080\nand http://example.org:80") {
+		t.Error("Expected URLs with ports not to be frames")
+	}
+}
+
+func TestStackTracePasteAttachesProjectFiles(t *testing.T) {
+	testutil.IsolateHome(t)
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "app"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "app", "handler.go"), []byte("package app\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	model := NewModel()
+	model.fileTree.SetFileSystem(NewLocalFS(dir))
+	model.focusPane(ChatPane)
+	send := func(msg tea.Msg) tea.Cmd {
+		updated, cmd := model.Update(msg)
+		*model = updated.(Model)
+		return cmd
+	}
+
+	trace := "panic: boom\n\ngoroutine 1 [running]:\nmain.handle()\n\t" + filepath.Join(dir, "app", "handler.go") + ":12 +0x1d\nmain.main()\n\t/usr/local/go/src/runtime/proc.go:250 +0x1"
+	cmd := send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(trace), Paste: true})
+	if cmd == nil {
+		t.Fatal("Expected the trace to be resolved against the project")
+	}
+	send(cmd())
+	if model.chat.pendingTrace == nil || len(model.chat.pendingTrace.frames) != 1 {
+		t.Fatalf("Expected only the project frame to be offered, got %+v", model.chat.pendingTrace)
+	}
+
+	cmd = send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	send(cmd())
+	if !model.composeModal.visible || len(model.composeModal.attachments) != 1 {
+		t.Fatalf("Expected the compose modal with the file attached, got %v", model.composeModal.attachments)
+	}
+	if draft := model.composeModal.editor.Value(); !strings.Contains(draft, "**Frames in this project:** `app/handler.go:12`") {
+		t.Errorf("Expected the project frame to be listed after the trace, got %q", draft)
+	}
+}
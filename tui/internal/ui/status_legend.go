@@ -0,0 +1,313 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// legendPalette is the colors ←/→ step through in the legend
+var legendPalette = []string{"33", "39", "45", "51", "46", "82", "118", "226", "220", "214", "208", "202", "196", "199", "213", "177", "141", "99", "255", "250", "244", "240"}
+
+// hexColorPattern matches #rgb and #rrggbb colors
+var hexColorPattern = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// StatusColorsSavedMsg saves the legend's colors; a category mapped to ""
+// goes back to its default color
+type StatusColorsSavedMsg struct {
+	Colors map[string]string
+}
+
+// legendRow is one category in the legend
+type legendRow struct {
+	Category    string
+	Description string
+	Color       string
+	Custom      bool // Colored by the user instead of by default
+}
+
+// StatusLegend lists the status categories with their colors and
+// descriptions, and re-colors them
+type StatusLegend struct {
+	rows    []legendRow
+	cursor  int
+	typing  bool   // Entering a color by hand
+	input   string // The color being entered
+	err     string
+	visible bool
+	width   int
+	height  int
+}
+
+// Show opens the legend on the categories
+func (sl *StatusLegend) Show(rows []legendRow) {
+	sl.rows = rows
+	sl.cursor = 0
+	sl.typing = false
+	sl.input = ""
+	sl.err = ""
+	sl.visible = true
+}
+
+// Hide hides the legend
+func (sl *StatusLegend) Hide() {
+	sl.visible = false
+}
+
+// IsVisible returns whether the legend is visible
+func (sl StatusLegend) IsVisible() bool {
+	return sl.visible
+}
+
+// SetSize updates the legend dimensions
+func (sl *StatusLegend) SetSize(width, height int) {
+	sl.width = width
+	sl.height = height
+}
+
+// validColor reports whether a color is one the status pane can draw: an
+// ANSI color 0-255 or a hex color
+func validColor(color string) bool {
+	if n, err := strconv.Atoi(color); err == nil {
+		return n >= 0 && n <= 255
+	}
+	return hexColorPattern.MatchString(color)
+}
+
+// stepColor moves the selected category's color along the palette
+func (sl *StatusLegend) stepColor(step int) {
+	row := &sl.rows[sl.cursor]
+	index := -1
+	for i, color := range legendPalette {
+		if color == row.Color {
+			index = i
+		}
+	}
+	if index < 0 && step < 0 {
+		index = 0
+	}
+	row.Color = legendPalette[(index+step+len(legendPalette))%len(legendPalette)]
+	row.Custom = true
+}
+
+// Update handles legend input
+func (sl StatusLegend) Update(msg tea.Msg) (StatusLegend, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || !sl.visible {
+		return sl, nil
+	}
+	if sl.typing {
+		return sl.updateTyping(keyMsg), nil
+	}
+	if len(sl.rows) == 0 {
+		if keyMsg.String() == "esc" || keyMsg.String() == "q" {
+			sl.Hide()
+		}
+		return sl, nil
+	}
+
+	sl.err = ""
+	switch keyMsg.String() {
+	case "esc", "q":
+		// Leaves without saving
+		sl.Hide()
+	case "up", "k":
+		sl.cursor = max(sl.cursor-1, 0)
+	case "down", "j":
+		sl.cursor = min(sl.cursor+1, len(sl.rows)-1)
+	case "right", "l":
+		sl.stepColor(1)
+	case "left", "h":
+		sl.stepColor(-1)
+	case "c", "#":
+		sl.typing = true
+		sl.input = ""
+		if keyMsg.String() == "#" {
+			sl.input = "#"
+		}
+	case "r":
+		row := &sl.rows[sl.cursor]
+		row.Color = defaultCategoryColor(row.Category)
+		row.Custom = false
+	case "enter":
+		colors := make(map[string]string, len(sl.rows))
+		for _, row := range sl.rows {
+			colors[row.Category] = ""
+			if row.Custom {
+				colors[row.Category] = row.Color
+			}
+		}
+		sl.Hide()
+		return sl, func() tea.Msg { return StatusColorsSavedMsg{Colors: colors} }
+	}
+	return sl, nil
+}
+
+// updateTyping handles a key while a color is entered by hand
+func (sl StatusLegend) updateTyping(msg tea.KeyMsg) StatusLegend {
+	switch msg.String() {
+	case "esc":
+		sl.typing = false
+	case "backspace":
+		if sl.input != "" {
+			sl.input = sl.input[:len(sl.input)-1]
+		}
+	case "enter":
+		color := strings.TrimSpace(sl.input)
+		if !validColor(color) {
+			sl.err = fmt.Sprintf("%q isn't a color - use 0-255 or #rrggbb", color)
+			return sl
+		}
+		sl.rows[sl.cursor].Color = strings.ToLower(color)
+		sl.rows[sl.cursor].Custom = true
+		sl.typing = false
+		sl.err = ""
+	default:
+		if msg.Type == tea.KeyRunes {
+			sl.input += string(msg.Runes)
+		}
+	}
+	return sl
+}
+
+// View renders the legend in a box
+func (sl StatusLegend) View() string {
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("62"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	cursorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
+	errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+
+	width := min(max(sl.width-10, 50), 90)
+	lines := []string{titleStyle.Render("Status categories"), ""}
+	if len(sl.rows) == 0 {
+		lines = append(lines, dimStyle.Render("No status categories yet - they arrive when the status channel is joined"))
+	}
+	room := max(sl.height-12, 3)
+	start := max(min(sl.cursor-room/2, len(sl.rows)-room), 0)
+	for i := start; i < min(start+room, len(sl.rows)); i++ {
+		row := sl.rows[i]
+		swatch := lipgloss.NewStyle().Foreground(lipgloss.Color(row.Color)).Render("██")
+		name := lipgloss.NewStyle().Foreground(lipgloss.Color(row.Color)).Render(fmt.Sprintf("%-14s", truncateStage(row.Category, 14)))
+		color := fmt.Sprintf("%-8s", row.Color)
+		if !row.Custom {
+			color = dimStyle.Render(color)
+		}
+		line := swatch + " " + name + " " + color
+		if row.Description != "" {
+			line += " " + dimStyle.Render(truncateStage(row.Description, max(width-32, 10)))
+		}
+		if i == sl.cursor {
+			line = cursorStyle.Render("▶ ") + line
+		} else {
+			line = "  " + line
+		}
+		lines = append(lines, line)
+	}
+
+	lines = append(lines, "")
+	if sl.typing {
+		lines = append(lines, "Color for "+sl.rows[sl.cursor].Category+": "+sl.input+"█ "+dimStyle.Render("(0-255 or #rrggbb, Enter: Set, Esc: Back)"))
+	}
+	if sl.err != "" {
+		lines = append(lines, errStyle.Render(sl.err))
+	}
+	lines = append(lines, dimStyle.Render("↑/↓: Select | ←/→: Change color | c: Enter a color | r: Default | Enter: Save | Esc: Cancel"))
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Padding(0, 1).
+		Width(width).
+		Render(strings.Join(lines, "\n"))
+}
+
+// legendRows lists the known status categories, the default ones first,
+// with their colors and the server's descriptions
+func (m Model) legendRows() []legendRow {
+	seen := make(map[string]bool)
+	var categories []string
+	for _, category := range []StatusCategory{StatusCategoryEngine, StatusCategoryTool, StatusCategoryWorkflow, StatusCategoryProgress, StatusCategoryError, StatusCategoryInfo} {
+		seen[string(category)] = true
+		categories = append(categories, string(category))
+	}
+	var others []string
+	for category := range m.categoryMetadata {
+		if !seen[category] {
+			seen[category] = true
+			others = append(others, category)
+		}
+	}
+	for category := range m.config.TUI.StatusCategoryColors {
+		if !seen[category] {
+			seen[category] = true
+			others = append(others, category)
+		}
+	}
+	sort.Strings(others)
+
+	rows := make([]legendRow, 0, len(categories)+len(others))
+	for _, category := range append(categories, others...) {
+		_, custom := m.config.TUI.StatusCategoryColors[category]
+		rows = append(rows, legendRow{
+			Category:    category,
+			Description: m.categoryMetadata[category].Description,
+			Color:       m.statusMessages.categoryColor(category),
+			Custom:      custom,
+		})
+	}
+	return rows
+}
+
+// openStatusLegend shows the status category legend
+func (m *Model) openStatusLegend() {
+	m.statusLegend.SetSize(m.width, m.height)
+	m.statusLegend.Show(m.legendRows())
+}
+
+// applyCategoryColors colors the status pane with the configured colors
+func (m *Model) applyCategoryColors() {
+	colors := make(map[string]string, len(m.config.TUI.StatusCategoryColors))
+	for category, color := range m.config.TUI.StatusCategoryColors {
+		colors[category] = color
+	}
+	m.statusMessages.SetCategoryColors(colors)
+	for category, info := range m.categoryMetadata {
+		info.Color = m.statusMessages.categoryColor(category)
+		m.categoryMetadata[category] = info
+	}
+}
+
+// saveStatusColors stores the colors picked in the legend in the config
+func (m *Model) saveStatusColors(colors map[string]string) {
+	if m.config.TUI.StatusCategoryColors == nil {
+		m.config.TUI.StatusCategoryColors = make(map[string]string)
+	}
+	changed := 0
+	for category, color := range colors {
+		current, configured := m.config.TUI.StatusCategoryColors[category]
+		switch {
+		case color == "" && configured:
+			delete(m.config.TUI.StatusCategoryColors, category)
+		case color != "" && color != current:
+			m.config.TUI.StatusCategoryColors[category] = color
+		default:
+			continue
+		}
+		changed++
+	}
+	m.applyCategoryColors()
+	if changed == 0 {
+		m.statusBar = "Status colors unchanged"
+		return
+	}
+	if err := SaveConfig(m.config); err != nil {
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Cannot save the status colors: %v", err), nil)
+		return
+	}
+	m.statusBar = fmt.Sprintf("Saved colors of %d status categories", changed)
+}
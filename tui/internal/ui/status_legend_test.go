@@ -0,0 +1,82 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/rubber_duck/tui/internal/phoenix"
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+func TestStatusLegendRecolorsAndSaves(t *testing.T) {
+	testutil.IsolateHome(t)
+	model := NewModel()
+	model.config.TUI.StatusCategoryColors = map[string]string{"info": "250"}
+	model.applyCategoryColors()
+
+	updated, _ := model.Update(phoenix.StatusChannelJoinedMsg{
+		ConversationID:       "c1",
+		CategoryDescriptions: map[string]string{"engine": "LLM engine activity", "billing": "Spend updates"},
+	})
+	*model = updated.(Model)
+	if got := model.statusMessages.categoryColor("info"); got != "250" {
+		t.Errorf("Expected the configured color applied on join, got %q", got)
+	}
+
+	updated, _ = model.Update(ExecuteCommandMsg{Command: "status_colors"})
+	*model = updated.(Model)
+	view := ansi.Strip(model.statusLegend.View())
+	for _, want := range []string{"engine", "LLM engine activity", "billing", "Spend updates"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected %q in the legend:\n%s", want, view)
+		}
+	}
+
+	// engine is first: type a color for it, then reset info (sixth) to its default
+	keys := []tea.KeyMsg{
+		{Type: tea.KeyRunes, Runes: []rune{'c'}},
+		{Type: tea.KeyRunes, Runes: []rune("#ff8800")},
+		{Type: tea.KeyEnter},
+	}
+	for range 5 {
+		keys = append(keys, tea.KeyMsg{Type: tea.KeyDown})
+	}
+	keys = append(keys, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	for _, key := range keys {
+		updated, _ = model.Update(key)
+		*model = updated.(Model)
+	}
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	*model = updated.(Model)
+	if cmd == nil {
+		t.Fatal("Expected Enter to save the colors")
+	}
+	updated, _ = model.Update(cmd())
+	*model = updated.(Model)
+
+	if got := model.config.TUI.StatusCategoryColors; got["engine"] != "#ff8800" || got["info"] != "" {
+		t.Errorf("Expected engine re-colored and info reset, got %v", got)
+	}
+	if got := model.statusMessages.categoryColor("engine.thinking"); got != "#ff8800" {
+		t.Errorf("Expected the new color on engine sub-categories, got %q", got)
+	}
+	loaded, err := LoadConfig()
+	if err != nil || loaded.TUI.StatusCategoryColors["engine"] != "#ff8800" {
+		t.Errorf("Expected the colors saved to the config, got %v (%v)", loaded, err)
+	}
+}
+
+func TestValidColor(t *testing.T) {
+	for _, color := range []string{"0", "255", "#abc", "#A0B1C2"} {
+		if !validColor(color) {
+			t.Errorf("Expected %q to be a color", color)
+		}
+	}
+	for _, color := range []string{"256", "-1", "white", "#abcd", ""} {
+		if validColor(color) {
+			t.Errorf("Expected %q not to be a color", color)
+		}
+	}
+}
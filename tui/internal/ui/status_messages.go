@@ -7,6 +7,7 @@ import (
 	
 	"github.com/charmbracelet/bubbles/viewport"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/rubber_duck/tui/internal/clock"
 )
 
 // StatusCategory represents different categories of status messages
@@ -24,6 +25,8 @@ const (
 // StatusMessage represents a single status update
 type StatusMessage struct {
 	Category  StatusCategory
+	Priority  StatusPriority
+	Dismissed bool // A critical update no longer pinned at the top
 	Text      string
 	Metadata  map[string]interface{}
 	Timestamp time.Time
@@ -38,8 +41,17 @@ type StatusMessages struct {
 	maxMessages     int
 	showTimestamp   bool
 	categoryColors  map[string]string // Category name to color code mapping
+	expandInfo      bool              // Show every info update instead of collapsing runs of them
+	errorCount      int               // Errors added since start, including ones trimmed since
+	added           int               // Updates added since start, including ones trimmed since
 }
 
+// maxPinnedCritical is how many critical updates are pinned at the top
+const maxPinnedCritical = 3
+
+// infoRunCollapse is how many consecutive info updates collapse into one line
+const infoRunCollapse = 3
+
 // NewStatusMessages creates a new status messages component
 func NewStatusMessages() *StatusMessages {
 	vp := viewport.New(0, 0)
@@ -60,12 +72,49 @@ func (s *StatusMessages) SetSize(width, height int) {
 	s.width = width
 	s.height = height
 	s.viewport.Width = width
-	s.viewport.Height = height - 2 // Account for title and margin
+	s.layout()
 	
 	// Update viewport content when size changes
 	s.viewport.SetContent(s.buildContent())
 }
 
+// layout shrinks the viewport to make room for pinned critical updates
+func (s *StatusMessages) layout() {
+	s.viewport.Height = max(1, s.height-2-len(s.pinned())) // Account for title and margin
+}
+
+// defaultCategoryColors color the categories without a configured color
+var defaultCategoryColors = map[StatusCategory]string{
+	StatusCategoryEngine:   "33",  // Blue
+	StatusCategoryTool:     "213", // Purple
+	StatusCategoryWorkflow: "226", // Yellow
+	StatusCategoryProgress: "46",  // Green
+	StatusCategoryError:    "196", // Red
+	StatusCategoryInfo:     "240", // Gray
+}
+
+// categoryColor returns a category's color: the configured one, then its
+// taxonomy root's, then the default
+func (s *StatusMessages) categoryColor(category string) string {
+	root := categoryRoot(category)
+	if color, exists := s.categoryColors[category]; exists {
+		return color
+	}
+	if color, exists := s.categoryColors[root]; exists {
+		return color
+	}
+	return defaultCategoryColor(category)
+}
+
+// defaultCategoryColor returns the color a category has when none is
+// configured
+func defaultCategoryColor(category string) string {
+	if color, exists := defaultCategoryColors[StatusCategory(categoryRoot(category))]; exists {
+		return color
+	}
+	return "240" // Default gray
+}
+
 // SetCategoryColors sets the color mapping for categories
 func (s *StatusMessages) SetCategoryColors(colors map[string]string) {
 	s.categoryColors = colors
@@ -73,16 +122,27 @@ func (s *StatusMessages) SetCategoryColors(colors map[string]string) {
 	s.viewport.SetContent(s.buildContent())
 }
 
-// AddMessage adds a new status message
+// AddMessage adds a new status message, prioritized by its category
 func (s *StatusMessages) AddMessage(category StatusCategory, text string, metadata map[string]interface{}) {
+	s.AddUpdate(category, resolveStatusPriority(category, ""), text, metadata)
+}
+
+// AddUpdate adds a status message with a priority; critical ones are
+// pinned at the top until dismissed
+func (s *StatusMessages) AddUpdate(category StatusCategory, priority StatusPriority, text string, metadata map[string]interface{}) {
 	msg := StatusMessage{
 		Category:  category,
+		Priority:  priority,
 		Text:      text,
 		Metadata:  metadata,
-		Timestamp: time.Now(),
+		Timestamp: clock.Now(),
 	}
 	
 	s.messages = append(s.messages, msg)
+	s.added++
+	if category == StatusCategoryError {
+		s.errorCount++
+	}
 	
 	// Limit number of messages
 	if len(s.messages) > s.maxMessages {
@@ -90,15 +150,66 @@ func (s *StatusMessages) AddMessage(category StatusCategory, text string, metada
 	}
 	
 	// Update viewport
+	s.layout()
 	s.viewport.SetContent(s.buildContent())
 	
 	// Auto-scroll to bottom
 	s.viewport.GotoBottom()
 }
 
+// Added returns how many updates were added since start
+func (s *StatusMessages) Added() int {
+	return s.added
+}
+
+// Latest returns up to the n most recent updates, oldest first
+func (s *StatusMessages) Latest(n int) []StatusMessage {
+	if n > len(s.messages) {
+		n = len(s.messages)
+	}
+	return s.messages[len(s.messages)-n:]
+}
+
+// pinned returns the critical updates pinned at the top, newest last
+func (s StatusMessages) pinned() []StatusMessage {
+	var pinned []StatusMessage
+	for _, msg := range s.messages {
+		if msg.Priority == StatusPriorityCritical && !msg.Dismissed {
+			pinned = append(pinned, msg)
+		}
+	}
+	if len(pinned) > maxPinnedCritical {
+		pinned = pinned[len(pinned)-maxPinnedCritical:]
+	}
+	return pinned
+}
+
+// DismissCritical unpins every critical update, returning how many were pinned
+func (s *StatusMessages) DismissCritical() int {
+	dismissed := 0
+	for i := range s.messages {
+		if s.messages[i].Priority == StatusPriorityCritical && !s.messages[i].Dismissed {
+			s.messages[i].Dismissed = true
+			dismissed++
+		}
+	}
+	s.layout()
+	s.viewport.SetContent(s.buildContent())
+	return dismissed
+}
+
+// ToggleInfo shows or collapses runs of info updates, reporting whether
+// they are now shown
+func (s *StatusMessages) ToggleInfo() bool {
+	s.expandInfo = !s.expandInfo
+	s.viewport.SetContent(s.buildContent())
+	return s.expandInfo
+}
+
 // Clear removes all messages
 func (s *StatusMessages) Clear() {
 	s.messages = []StatusMessage{}
+	s.layout()
 	s.viewport.SetContent("")
 }
 
@@ -127,6 +238,14 @@ func (s StatusMessages) View() string {
 		content = s.viewport.View()
 	}
 	
+	if pinned := s.pinned(); len(pinned) > 0 {
+		pinStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true).Width(s.width).MaxHeight(1)
+		lines := []string{title}
+		for _, msg := range pinned {
+			lines = append(lines, pinStyle.Render("‼ "+msg.Text))
+		}
+		return lipgloss.JoinVertical(lipgloss.Left, append(lines, content)...)
+	}
 	return lipgloss.JoinVertical(lipgloss.Left, title, content)
 }
 
@@ -138,35 +257,40 @@ func (s StatusMessages) buildContent() string {
 	
 	var content strings.Builder
 	
-	// Define default category styles
-	defaultCategoryStyles := map[StatusCategory]string{
-		StatusCategoryEngine:   "33",    // Blue
-		StatusCategoryTool:     "213",   // Purple
-		StatusCategoryWorkflow: "226",   // Yellow
-		StatusCategoryProgress: "46",    // Green
-		StatusCategoryError:    "196",   // Red
-		StatusCategoryInfo:     "240",   // Gray
-	}
-	
 	timeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
 	
-	for i, msg := range s.messages {
+	// Pinned critical updates are shown above the log instead
+	var log []StatusMessage
+	for _, msg := range s.messages {
+		if msg.Priority != StatusPriorityCritical || msg.Dismissed {
+			log = append(log, msg)
+		}
+	}
+	
+	for i := 0; i < len(log); i++ {
+		msg := log[i]
 		if i > 0 {
 			content.WriteString("\n")
 		}
 		
-		// Get color for category
-		color := "240" // Default gray
-		
-		// First check if we have a configured color for this category
-		if configuredColor, exists := s.categoryColors[string(msg.Category)]; exists {
-			color = configuredColor
-		} else if defaultColor, exists := defaultCategoryStyles[msg.Category]; exists {
-			// Fall back to default color
-			color = defaultColor
+		// Runs of info updates collapse to their latest
+		if !s.expandInfo && msg.Priority == StatusPriorityInfo {
+			run := 1
+			for i+run < len(log) && log[i+run].Priority == StatusPriorityInfo {
+				run++
+			}
+			if run >= infoRunCollapse {
+				content.WriteString(timeStyle.Render(fmt.Sprintf("· %d earlier info updates (/status-info shows them)", run-1)))
+				content.WriteString("\n")
+				i += run - 1
+				msg = log[i]
+			}
 		}
 		
-		style := lipgloss.NewStyle().Foreground(lipgloss.Color(color))
+		style := lipgloss.NewStyle().Foreground(lipgloss.Color(s.categoryColor(string(msg.Category))))
+		if msg.Priority == StatusPriorityCritical || msg.Priority == StatusPriorityHigh {
+			style = style.Bold(true)
+		}
 		
 		// Format line without category brackets
 		if s.showTimestamp {
@@ -179,7 +303,7 @@ func (s StatusMessages) buildContent() string {
 		content.WriteString(style.Render(msg.Text))
 		
 		// Add metadata if present and it's an error or has details
-		if msg.Category == StatusCategoryError && msg.Metadata != nil {
+		if categoryRoot(string(msg.Category)) == string(StatusCategoryError) && msg.Metadata != nil {
 			if details, ok := msg.Metadata["error"].(string); ok {
 				content.WriteString(fmt.Sprintf("\n    %s", lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(details)))
 			}
@@ -0,0 +1,125 @@
+package ui
+
+import (
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// StatusPriority is how prominently a status update is shown, most urgent first
+type StatusPriority int
+
+const (
+	StatusPriorityCritical StatusPriority = iota
+	StatusPriorityHigh
+	StatusPriorityNormal
+	StatusPriorityInfo
+)
+
+// String returns the priority's config name
+func (p StatusPriority) String() string {
+	switch p {
+	case StatusPriorityCritical:
+		return "critical"
+	case StatusPriorityHigh:
+		return "high"
+	case StatusPriorityInfo:
+		return "info"
+	default:
+		return "normal"
+	}
+}
+
+// priorityNames maps the server's priority names onto priorities
+var priorityNames = map[string]StatusPriority{
+	"critical":  StatusPriorityCritical,
+	"emergency": StatusPriorityCritical,
+	"urgent":    StatusPriorityCritical,
+	"high":      StatusPriorityHigh,
+	"normal":    StatusPriorityNormal,
+	"medium":    StatusPriorityNormal,
+	"low":       StatusPriorityInfo,
+	"info":      StatusPriorityInfo,
+	"debug":     StatusPriorityInfo,
+}
+
+// categoryPriorities are the priorities of taxonomy roots that don't send one
+var categoryPriorities = map[string]StatusPriority{
+	"security":  StatusPriorityCritical,
+	"alert":     StatusPriorityCritical,
+	"error":     StatusPriorityHigh,
+	"engine":    StatusPriorityNormal,
+	"tool":      StatusPriorityNormal,
+	"workflow":  StatusPriorityNormal,
+	"progress":  StatusPriorityNormal,
+	"info":      StatusPriorityInfo,
+	"debug":     StatusPriorityInfo,
+	"telemetry": StatusPriorityInfo,
+}
+
+// categoryRoot returns the top of a taxonomy category, e.g. "error" for
+// "error.provider.timeout"
+func categoryRoot(category string) string {
+	root, _, _ := strings.Cut(category, ".")
+	return strings.ToLower(root)
+}
+
+// resolveStatusPriority picks an update's priority from the name the server
+// sent, or from its category when none was sent
+func resolveStatusPriority(category StatusCategory, priority string) StatusPriority {
+	if p, ok := priorityNames[strings.ToLower(priority)]; ok {
+		return p
+	}
+	if p, ok := categoryPriorities[categoryRoot(string(category))]; ok {
+		return p
+	}
+	return StatusPriorityNormal
+}
+
+// defaultStatusNotify are the notification rules used for priorities
+// tui.status_notify doesn't set
+var defaultStatusNotify = map[string]string{
+	"critical": "bell,flash",
+	"high":     "flash",
+	"normal":   "none",
+	"info":     "none",
+}
+
+// statusNotifyActions returns the notifications for a priority: any of
+// "bell", "flash" (status bar), and "chat", or "none"
+func (m Model) statusNotifyActions(priority StatusPriority) []string {
+	rule, ok := m.config.TUI.StatusNotify[priority.String()]
+	if !ok {
+		rule = defaultStatusNotify[priority.String()]
+	}
+	var actions []string
+	for _, action := range strings.Split(rule, ",") {
+		if action = strings.TrimSpace(strings.ToLower(action)); action != "" && action != "none" {
+			actions = append(actions, action)
+		}
+	}
+	return actions
+}
+
+// notifyStatus runs the notification rules for a server status update
+func (m *Model) notifyStatus(priority StatusPriority, text string) tea.Cmd {
+	var cmd tea.Cmd
+	for _, action := range m.statusNotifyActions(priority) {
+		switch action {
+		case "bell":
+			cmd = ringBell
+		case "flash":
+			m.statusBar = strings.ToUpper(priority.String()[:1]) + priority.String()[1:] + ": " + text
+		case "chat":
+			m.chat.AddMessage(SystemMessage, "Status ("+priority.String()+"): "+text, "system")
+		}
+	}
+	return cmd
+}
+
+// ringBell rings the terminal bell
+func ringBell() tea.Msg {
+	os.Stdout.WriteString("\a")
+	return nil
+}
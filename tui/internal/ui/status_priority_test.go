@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+func TestStatusPriorityRouting(t *testing.T) {
+	if got := resolveStatusPriority("error.provider.timeout", ""); got != StatusPriorityHigh {
+		t.Errorf("Expected taxonomy errors to be high priority, got %s", got)
+	}
+	if got := resolveStatusPriority("engine", "CRITICAL"); got != StatusPriorityCritical {
+		t.Errorf("Expected the sent priority to win, got %s", got)
+	}
+
+	status := NewStatusMessages()
+	status.SetSize(80, 20)
+	status.AddUpdate("security.alert", StatusPriorityCritical, "Key leaked", nil)
+	for _, text := range []string{"tick 1", "tick 2", "tick 3"} {
+		status.AddUpdate("info", StatusPriorityInfo, text, nil)
+	}
+	view := status.View()
+	if !strings.Contains(view, "‼ Key leaked") || status.viewport.Height != 17 {
+		t.Errorf("Expected the critical update pinned above the log, got:\n%s", view)
+	}
+	if !strings.Contains(view, "2 earlier info updates") || strings.Contains(view, "tick 1") || !strings.Contains(view, "tick 3") {
+		t.Errorf("Expected the info run collapsed to its latest, got:\n%s", view)
+	}
+
+	status.ToggleInfo()
+	if view := status.View(); !strings.Contains(view, "tick 1") {
+		t.Errorf("Expected every info update once expanded, got:\n%s", view)
+	}
+	if status.DismissCritical() != 1 || strings.Contains(status.View(), "‼") {
+		t.Error("Expected dismissing to unpin the critical update")
+	}
+}
+
+func TestStatusNotifyRules(t *testing.T) {
+	testutil.IsolateHome(t)
+	model := NewModel()
+	model.config.TUI.StatusNotify = map[string]string{"high": "chat, flash"}
+
+	before := model.chat.GetMessageCount()
+	model.notifyStatus(StatusPriorityHigh, "Provider down")
+	if model.chat.GetMessageCount() != before+1 || model.statusBar != "High: Provider down" {
+		t.Errorf("Expected a chat message and status bar flash, got %q", model.statusBar)
+	}
+	if cmd := model.notifyStatus(StatusPriorityCritical, "Disk full"); cmd == nil {
+		t.Error("Expected critical updates to ring the bell by default")
+	}
+	if actions := model.statusNotifyActions(StatusPriorityInfo); len(actions) != 0 {
+		t.Errorf("Expected no notifications for info updates, got %v", actions)
+	}
+}
@@ -0,0 +1,263 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// defaultStatusCategories are subscribed to when the server lists none
+var defaultStatusCategories = []string{"engine", "tool", "workflow", "progress", "error", "info"}
+
+// StatusSubscriptionToggledMsg subscribes to or unsubscribes from status
+// categories for the current conversation
+type StatusSubscriptionToggledMsg struct {
+	Categories []string
+	Subscribed bool
+}
+
+// subscriptionRow is one category in the subscriptions manager
+type subscriptionRow struct {
+	Category    string
+	Description string
+	Subscribed  bool
+}
+
+// StatusSubscriptions lists the status categories the server offers with
+// toggles for the current conversation's subscriptions
+type StatusSubscriptions struct {
+	rows    []subscriptionRow
+	cursor  int
+	visible bool
+	width   int
+	height  int
+}
+
+// Show opens the manager on the categories, keeping the selection when
+// it is refreshed while open
+func (ss *StatusSubscriptions) Show(rows []subscriptionRow) {
+	if !ss.visible {
+		ss.cursor = 0
+	}
+	ss.rows = rows
+	ss.cursor = min(ss.cursor, max(len(rows)-1, 0))
+	ss.visible = true
+}
+
+// Hide hides the manager
+func (ss *StatusSubscriptions) Hide() {
+	ss.visible = false
+}
+
+// IsVisible returns whether the manager is visible
+func (ss StatusSubscriptions) IsVisible() bool {
+	return ss.visible
+}
+
+// SetSize updates the manager dimensions
+func (ss *StatusSubscriptions) SetSize(width, height int) {
+	ss.width = width
+	ss.height = height
+}
+
+// Update handles manager input; toggles take effect straight away
+func (ss StatusSubscriptions) Update(msg tea.Msg) (StatusSubscriptions, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || !ss.visible {
+		return ss, nil
+	}
+
+	switch keyMsg.String() {
+	case "esc", "q":
+		ss.Hide()
+	case "up", "k":
+		ss.cursor = max(ss.cursor-1, 0)
+	case "down", "j":
+		ss.cursor = min(ss.cursor+1, max(len(ss.rows)-1, 0))
+	case " ", "enter":
+		if ss.cursor >= len(ss.rows) {
+			break
+		}
+		row := &ss.rows[ss.cursor]
+		row.Subscribed = !row.Subscribed
+		toggled := StatusSubscriptionToggledMsg{Categories: []string{row.Category}, Subscribed: row.Subscribed}
+		return ss, func() tea.Msg { return toggled }
+	case "a", "n":
+		// a subscribes to every category, n to none
+		subscribed := keyMsg.String() == "a"
+		var categories []string
+		for i := range ss.rows {
+			if ss.rows[i].Subscribed != subscribed {
+				ss.rows[i].Subscribed = subscribed
+				categories = append(categories, ss.rows[i].Category)
+			}
+		}
+		if len(categories) == 0 {
+			break
+		}
+		toggled := StatusSubscriptionToggledMsg{Categories: categories, Subscribed: subscribed}
+		return ss, func() tea.Msg { return toggled }
+	}
+	return ss, nil
+}
+
+// View renders the categories with their toggles in a box
+func (ss StatusSubscriptions) View() string {
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("62"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	cursorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
+	onStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("46"))
+
+	width := min(max(ss.width-10, 50), 90)
+	subscribed := 0
+	for _, row := range ss.rows {
+		if row.Subscribed {
+			subscribed++
+		}
+	}
+	lines := []string{
+		titleStyle.Render("Status subscriptions") + dimStyle.Render(fmt.Sprintf("  %d of %d for this conversation", subscribed, len(ss.rows))),
+		"",
+	}
+	if len(ss.rows) == 0 {
+		lines = append(lines, dimStyle.Render("No status categories yet - they arrive when the status channel is joined"))
+	}
+	room := max(ss.height-12, 3)
+	start := max(min(ss.cursor-room/2, len(ss.rows)-room), 0)
+	for i := start; i < min(start+room, len(ss.rows)); i++ {
+		row := ss.rows[i]
+		toggle := dimStyle.Render("[ ]")
+		if row.Subscribed {
+			toggle = onStyle.Render("[x]")
+		}
+		line := toggle + " " + fmt.Sprintf("%-14s", truncateStage(row.Category, 14))
+		if row.Description != "" {
+			line += " " + dimStyle.Render(truncateStage(row.Description, max(width-24, 10)))
+		}
+		if i == ss.cursor {
+			line = cursorStyle.Render("▶ ") + line
+		} else {
+			line = "  " + line
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, "", dimStyle.Render("↑/↓: Select | Space: Toggle | a: All | n: None | Esc: Close"))
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Padding(0, 1).
+		Width(width).
+		Render(strings.Join(lines, "\n"))
+}
+
+// availableStatusCategories returns the categories the server offered,
+// or the ones seen so far when it hasn't listed them
+func (m Model) availableStatusCategories() []string {
+	if len(m.statusAvailable) > 0 {
+		return m.statusAvailable
+	}
+	categories := slices.Clone(defaultStatusCategories)
+	var others []string
+	for category := range m.categoryMetadata {
+		if !slices.Contains(categories, category) {
+			others = append(others, category)
+		}
+	}
+	sort.Strings(others)
+	return append(categories, others...)
+}
+
+// statusMuted reports whether the current conversation unsubscribed from a
+// category, or from its taxonomy root
+func (m *Model) statusMuted(category string) bool {
+	muted := m.currentSettings().MutedCategories
+	return slices.Contains(muted, category) || slices.Contains(muted, categoryRoot(category))
+}
+
+// subscribedCategories leaves out the categories the current conversation
+// unsubscribed from
+func (m *Model) subscribedCategories(categories []string) []string {
+	kept := make([]string, 0, len(categories))
+	for _, category := range categories {
+		if !m.statusMuted(category) {
+			kept = append(kept, category)
+		}
+	}
+	return kept
+}
+
+// subscriptionRows lists the available categories with their toggles
+func (m *Model) subscriptionRows() []subscriptionRow {
+	categories := m.availableStatusCategories()
+	rows := make([]subscriptionRow, len(categories))
+	for i, category := range categories {
+		rows[i] = subscriptionRow{
+			Category:    category,
+			Description: m.categoryMetadata[category].Description,
+			Subscribed:  !m.statusMuted(category),
+		}
+	}
+	return rows
+}
+
+// openStatusSubscriptions shows the subscriptions manager and asks the
+// server which categories it offers
+func (m *Model) openStatusSubscriptions() tea.Cmd {
+	m.statusSubscriptions.SetSize(m.width, m.height)
+	m.statusSubscriptions.Show(m.subscriptionRows())
+	if statusClient, ok := m.statusClient.(*phoenix.StatusClient); ok && m.connected {
+		return statusClient.GetSubscriptions()
+	}
+	return nil
+}
+
+// toggleStatusSubscription remembers a subscription change for the current
+// conversation and tells the server
+func (m *Model) toggleStatusSubscription(msg StatusSubscriptionToggledMsg) tea.Cmd {
+	m.updateSettings(func(s *conversationSettings) {
+		muted := slices.DeleteFunc(slices.Clone(s.MutedCategories), func(category string) bool {
+			return slices.Contains(msg.Categories, category)
+		})
+		if !msg.Subscribed {
+			muted = append(muted, msg.Categories...)
+			sort.Strings(muted)
+		}
+		if len(muted) == 0 {
+			muted = nil
+		}
+		s.MutedCategories = muted
+	})
+	verb := "Unsubscribed from"
+	if msg.Subscribed {
+		verb = "Subscribed to"
+	}
+	m.statusBar = fmt.Sprintf("%s %s for this conversation", verb, strings.Join(msg.Categories, ", "))
+
+	statusClient, ok := m.statusClient.(*phoenix.StatusClient)
+	if !ok || !m.connected {
+		return nil
+	}
+	if msg.Subscribed {
+		return statusClient.SubscribeCategories(msg.Categories)
+	}
+	return statusClient.UnsubscribeCategories(msg.Categories)
+}
+
+// handleStatusSubscriptions refreshes the manager with the categories the
+// server offers
+func (m *Model) handleStatusSubscriptions(msg phoenix.StatusSubscriptionsMsg) {
+	if len(msg.Available) > 0 {
+		m.statusAvailable = msg.Available
+	}
+	if m.statusSubscriptions.IsVisible() {
+		m.statusSubscriptions.Show(m.subscriptionRows())
+		return
+	}
+	m.statusBar = fmt.Sprintf("Status subscriptions - Active: %v, Available: %v", msg.Subscribed, msg.Available)
+}
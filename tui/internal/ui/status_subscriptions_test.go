@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"slices"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/phoenix"
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+func TestStatusSubscriptionsToggle(t *testing.T) {
+	testutil.IsolateHome(t)
+	model := NewModel()
+	updated, _ := model.Update(phoenix.StatusSubscriptionsMsg{Available: []string{"engine", "tool", "billing"}})
+	*model = updated.(Model)
+
+	updated, _ = model.Update(ExecuteCommandMsg{Command: "subscriptions"})
+	*model = updated.(Model)
+	if !model.statusSubscriptions.IsVisible() || len(model.statusSubscriptions.rows) != 3 {
+		t.Fatalf("Expected the manager open on the server's categories, got %+v", model.statusSubscriptions.rows)
+	}
+
+	// Turn off tool, the second category
+	for _, key := range []tea.KeyMsg{{Type: tea.KeyDown}, {Type: tea.KeySpace, Runes: []rune{' '}}} {
+		var cmd tea.Cmd
+		updated, cmd = model.Update(key)
+		*model = updated.(Model)
+		if cmd != nil {
+			updated, _ = model.Update(cmd())
+			*model = updated.(Model)
+		}
+	}
+	if muted := model.currentSettings().MutedCategories; !slices.Equal(muted, []string{"tool"}) {
+		t.Errorf("Expected tool muted for the conversation, got %v", muted)
+	}
+	if got := model.subscribedCategories([]string{"engine", "tool", "billing"}); !slices.Equal(got, []string{"engine", "billing"}) {
+		t.Errorf("Expected tool left out of subscriptions, got %v", got)
+	}
+
+	// Updates in a muted category, or under it, are dropped
+	before := len(model.statusMessages.messages)
+	updated, _ = model.Update(phoenix.StatusUpdateMsg{Category: "tool.call", Text: "grep"})
+	*model = updated.(Model)
+	if len(model.statusMessages.messages) != before {
+		t.Error("Expected the muted update dropped")
+	}
+
+	// a subscribes to everything again
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	*model = updated.(Model)
+	updated, _ = model.Update(cmd())
+	*model = updated.(Model)
+	if muted := model.currentSettings().MutedCategories; len(muted) != 0 {
+		t.Errorf("Expected nothing muted, got %v", muted)
+	}
+}
@@ -0,0 +1,182 @@
+package ui
+
+import (
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// tagPattern matches normalized tag names
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// tagColors are the colors tags are drawn in, picked by a hash of the name
+// so a tag keeps its color everywhere
+var tagColors = []string{"39", "170", "214", "42", "203", "141", "81", "222"}
+
+// normalizeTag lowercases a tag and strips a leading #
+func normalizeTag(raw string) (string, error) {
+	tag := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(raw), "#"))
+	if !tagPattern.MatchString(tag) {
+		return "", fmt.Errorf("invalid tag %q: use up to 32 letters, digits, '-' or '_'", raw)
+	}
+	return tag, nil
+}
+
+// renderTag draws a tag in its color
+func renderTag(tag string) string {
+	h := fnv.New32a()
+	h.Write([]byte(tag))
+	color := tagColors[h.Sum32()%uint32(len(tagColors))]
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Render("#" + tag)
+}
+
+// renderTags draws a list of tags separated by spaces
+func renderTags(tags []string) string {
+	rendered := make([]string, len(tags))
+	for i, tag := range tags {
+		rendered[i] = renderTag(tag)
+	}
+	return strings.Join(rendered, " ")
+}
+
+// withTag returns tags plus tag, sorted and without duplicates
+func withTag(tags []string, tag string) []string {
+	for _, existing := range tags {
+		if existing == tag {
+			return tags
+		}
+	}
+	tags = append(append([]string(nil), tags...), tag)
+	sort.Strings(tags)
+	return tags
+}
+
+// withoutTag returns tags minus tag
+func withoutTag(tags []string, tag string) []string {
+	var kept []string
+	for _, existing := range tags {
+		if existing != tag {
+			kept = append(kept, existing)
+		}
+	}
+	return kept
+}
+
+// filterByTag returns the conversations carrying tag
+func filterByTag(conversations []SavedConversation, tag string) []SavedConversation {
+	var tagged []SavedConversation
+	for _, conv := range conversations {
+		for _, t := range conv.Tags {
+			if t == tag {
+				tagged = append(tagged, conv)
+				break
+			}
+		}
+	}
+	return tagged
+}
+
+// formatTagList renders every tag in use with its conversation count
+func formatTagList(conversations []SavedConversation) string {
+	counts := make(map[string]int)
+	for _, conv := range conversations {
+		for _, tag := range conv.Tags {
+			counts[tag]++
+		}
+	}
+	if len(counts) == 0 {
+		return "No tags yet. Use /tag add <tag> to tag the current conversation"
+	}
+	tags := make([]string, 0, len(counts))
+	for tag := range counts {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	var b strings.Builder
+	b.WriteString("Tags:\n\n")
+	for _, tag := range tags {
+		fmt.Fprintf(&b, "  %s  (%d)\n", renderTag(tag), counts[tag])
+	}
+	b.WriteString("\nUse /saved tag <tag> to list its conversations")
+	return b.String()
+}
+
+// savedConversationID returns the ID the current conversation is saved under
+func (m *Model) savedConversationID() string {
+	if m.conversationID != "" {
+		return m.conversationID
+	}
+	if m.localConversationID == "" {
+		m.localConversationID = "local-" + time.Now().Format("20060102-150405")
+	}
+	return m.localConversationID
+}
+
+// currentTags returns the current conversation's tags, loading them from
+// its saved copy when the conversation changed
+func (m *Model) currentTags() []string {
+	id := m.savedConversationID()
+	if m.tagsFor != id {
+		m.tagsFor = id
+		m.conversationTags = nil
+		if conv, err := loadConversationByID(id); err == nil {
+			m.conversationTags = conv.Tags
+		}
+	}
+	return m.conversationTags
+}
+
+// updateTags changes the tags of the current conversation, or of a saved
+// one when ref is given
+func (m *Model) updateTags(ref string, change func([]string) []string) tea.Cmd {
+	var id string
+	var tags []string
+	if ref == "" {
+		id = m.savedConversationID()
+		tags = change(m.currentTags())
+		m.conversationTags = tags
+		m.autosaveConversation()
+		m.updateHeaderState()
+	} else {
+		conv, err := LoadSavedConversation(ref)
+		if err != nil {
+			m.statusMessages.AddMessage(StatusCategoryError, err.Error(), nil)
+			return nil
+		}
+		id = conv.ID
+		tags = change(conv.Tags)
+		conv.Tags = tags
+		if err := SaveConversation(*conv); err != nil {
+			m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Failed to save tags: %v", err), nil)
+			return nil
+		}
+		if id == m.tagsFor {
+			m.conversationTags = tags
+			m.updateHeaderState()
+		}
+	}
+
+	if len(tags) == 0 {
+		m.statusBar = "No tags"
+	} else {
+		m.statusBar = "Tags: " + renderTags(tags)
+	}
+	return m.syncTags(id, tags)
+}
+
+// syncTags sends a server conversation's tags to the server when enabled
+func (m *Model) syncTags(id string, tags []string) tea.Cmd {
+	client, ok := m.phoenixClient.(*phoenix.Client)
+	if !m.config.TUI.SyncTags || !ok || !m.connected || strings.HasPrefix(id, "local-") {
+		return nil
+	}
+	return client.SetTags(id, tags)
+}
@@ -0,0 +1,58 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+func TestNormalizeTag(t *testing.T) {
+	for raw, want := range map[string]string{"bug-hunt": "bug-hunt", "#Review": "review", " v2_api ": "v2_api"} {
+		if got, err := normalizeTag(raw); err != nil || got != want {
+			t.Errorf("normalizeTag(%q) = %q, %v; want %q", raw, got, err, want)
+		}
+	}
+	for _, raw := range []string{"", "#", "two words", "-leading", "a/b"} {
+		if _, err := normalizeTag(raw); err == nil {
+			t.Errorf("normalizeTag(%q) should fail", raw)
+		}
+	}
+}
+
+func TestTagsSurviveAutosave(t *testing.T) {
+	testutil.IsolateHome(t)
+	var model Model = *NewModel()
+	update := func(msg any) {
+		updated, _ := model.Update(msg)
+		model = updated.(Model)
+	}
+
+	update(ChatMessageReceivedMsg{Content: "hello", Type: "assistant"})
+	update(ExecuteCommandMsg{Command: "tag_add", Args: map[string]string{"tag": "#Bug-Hunt"}})
+	update(ExecuteCommandMsg{Command: "tag_add", Args: map[string]string{"tag": "api"}})
+	update(ChatMessageReceivedMsg{Content: "more", Type: "assistant"})
+
+	conversations, err := ListSavedConversations()
+	if err != nil || len(conversations) != 1 {
+		t.Fatalf("Expected one saved conversation, got %d (%v)", len(conversations), err)
+	}
+	if want := []string{"api", "bug-hunt"}; !reflect.DeepEqual(conversations[0].Tags, want) {
+		t.Fatalf("Expected tags %v after autosave, got %v", want, conversations[0].Tags)
+	}
+	if len(filterByTag(conversations, "api")) != 1 || len(filterByTag(conversations, "other")) != 0 {
+		t.Fatalf("filterByTag did not match the saved tags")
+	}
+
+	update(ExecuteCommandMsg{Command: "tag_remove", Args: map[string]string{"tag": "api", "ref": "1"}})
+	conv, err := LoadSavedConversation("1")
+	if err != nil {
+		t.Fatalf("LoadSavedConversation: %v", err)
+	}
+	if want := []string{"bug-hunt"}; !reflect.DeepEqual(conv.Tags, want) {
+		t.Fatalf("Expected tags %v after removing by number, got %v", want, conv.Tags)
+	}
+	if !reflect.DeepEqual(model.currentTags(), conv.Tags) {
+		t.Fatalf("Expected the open conversation to pick up the change, got %v", model.currentTags())
+	}
+}
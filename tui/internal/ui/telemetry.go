@@ -0,0 +1,99 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/telemetry"
+)
+
+// telemetryUploadTimeout bounds an upload of the telemetry counts
+const telemetryUploadTimeout = 10 * time.Second
+
+// TelemetryConfig records the user's telemetry choice. Enabled is nil until
+// the consent modal has been answered
+type TelemetryConfig struct {
+	Enabled   *bool  `json:"enabled,omitempty"`
+	UploadURL string `json:"upload_url,omitempty"` // Counts are only uploaded when set
+}
+
+// telemetryConsentText explains what consenting to telemetry records
+const telemetryConsentText = `RubberDuck can count which features you use and which kinds of
+errors you hit, to help decide what to work on next.
+
+Recorded: command names, how many messages you send, and error
+classes such as "timeout" or "connection".
+Never recorded: messages, code, file names, or error text.
+
+Counts stay in ~/.rubber_duck/telemetry.json unless an upload URL
+is configured. Change your mind any time with /telemetry on|off.
+
+Enable anonymous telemetry?`
+
+// telemetryConsentMsg asks for consent once the UI is running
+type telemetryConsentMsg struct{}
+
+// TelemetryUploadedMsg reports the result of uploading the counts
+type TelemetryUploadedMsg struct {
+	Err error
+}
+
+// newTelemetry creates the recorder for the configured choice
+func newTelemetry(config *Config) *telemetry.Recorder {
+	enabled := config.Telemetry.Enabled != nil && *config.Telemetry.Enabled
+	// Without a home directory counts are kept in memory only
+	path, _ := telemetry.DefaultPath()
+	return telemetry.New(path, enabled)
+}
+
+// telemetryStartup asks for consent when it hasn't been given or refused,
+// and uploads pending counts when an upload URL is configured
+func (m Model) telemetryStartup() tea.Cmd {
+	if m.config.Telemetry.Enabled == nil {
+		return func() tea.Msg { return telemetryConsentMsg{} }
+	}
+	if m.telemetry.Enabled() && m.config.Telemetry.UploadURL != "" {
+		return m.uploadTelemetry()
+	}
+	return nil
+}
+
+// uploadTelemetry posts the counts to the configured URL
+func (m Model) uploadTelemetry() tea.Cmd {
+	recorder, url := m.telemetry, m.config.Telemetry.UploadURL
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), telemetryUploadTimeout)
+		defer cancel()
+		return TelemetryUploadedMsg{Err: recorder.Upload(ctx, url)}
+	}
+}
+
+// setTelemetry records the user's choice in the config
+func (m *Model) setTelemetry(enabled bool) {
+	m.config.Telemetry.Enabled = &enabled
+	if err := m.telemetry.SetEnabled(enabled); err != nil {
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Failed to clear telemetry: %v", err), nil)
+	}
+	if err := SaveConfig(m.config); err != nil {
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Failed to save config: %v", err), nil)
+	}
+	if enabled {
+		m.statusBar = "Telemetry on - thank you"
+	} else {
+		m.statusBar = "Telemetry off"
+	}
+}
+
+// telemetryStatus describes the telemetry setting and current counts
+func (m Model) telemetryStatus() string {
+	if !m.telemetry.Enabled() {
+		return "Telemetry is off. Use /telemetry on to share anonymous feature and error counts"
+	}
+	status := fmt.Sprintf("Telemetry is on. Counts are kept in %s", m.telemetry.Path())
+	if url := m.config.Telemetry.UploadURL; url != "" {
+		status += fmt.Sprintf(" and uploaded to %s", url)
+	}
+	return status + "\n\n" + telemetry.Format(m.telemetry.Snapshot())
+}
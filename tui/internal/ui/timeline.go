@@ -0,0 +1,112 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// scrollbarWidth is the column reserved beside the history for the scrollbar
+const scrollbarWidth = 1
+
+// sameDay reports whether two times fall on the same local calendar day
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Local().Date()
+	by, bm, bd := b.Local().Date()
+	return ay == by && am == bm && ad == bd
+}
+
+// renderDateSeparator draws the line that starts a new day in the history
+func renderDateSeparator(day time.Time, width int) string {
+	label := " " + day.Local().Format("Mon, Jan 2 2006") + " "
+	side := max((width-lipgloss.Width(label))/2, 2)
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color("240")).
+		Render(strings.Repeat("─", side) + label + strings.Repeat("─", side))
+}
+
+// renderScrollbar draws the history's scrollbar: the thumb shows the visible
+// part and dots mark where a new day starts
+func (c Chat) renderScrollbar() string {
+	height := c.viewport.Height
+	total := c.viewport.TotalLineCount()
+	if height <= 0 {
+		return ""
+	}
+	if total <= height {
+		return strings.TrimSuffix(strings.Repeat(" \n", height), "\n")
+	}
+
+	thumbSize := max(height*height/total, 1)
+	thumbStart := int(c.viewport.ScrollPercent() * float64(height-thumbSize))
+
+	trackStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("238"))
+	thumbStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("62"))
+	dayStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+
+	rows := make([]string, height)
+	for row := range rows {
+		from, to := row*total/height, (row+1)*total/height
+		switch {
+		case row >= thumbStart && row < thumbStart+thumbSize:
+			rows[row] = thumbStyle.Render("┃")
+		case c.dayStartsBetween(from, to):
+			rows[row] = dayStyle.Render("•")
+		default:
+			rows[row] = trackStyle.Render("│")
+		}
+	}
+	return strings.Join(rows, "\n")
+}
+
+// dayStartsBetween reports whether a date separator lies in [from, to)
+func (c Chat) dayStartsBetween(from, to int) bool {
+	for _, line := range c.dayLines {
+		if line >= from && line < to {
+			return true
+		}
+	}
+	return false
+}
+
+// GotoDate scrolls to the first message on or after the start of day,
+// reporting whether there is one
+func (c *Chat) GotoDate(day time.Time) bool {
+	y, mo, d := day.Date()
+	start := time.Date(y, mo, d, 0, 0, 0, 0, time.Local)
+	// Read back spilled messages until the day is in memory
+	for len(c.messages) == 0 || !c.messages[0].Timestamp.Before(start) {
+		if !c.rehydrate() {
+			break
+		}
+	}
+	for i, msg := range c.messages {
+		if !msg.Timestamp.Before(start) && i < len(c.messageLines) {
+			line := c.messageLines[i]
+			if i > 0 && !sameDay(c.messages[i-1].Timestamp, msg.Timestamp) {
+				line -= 2 // Show the date separator too
+			}
+			c.viewport.SetYOffset(line)
+			return true
+		}
+	}
+	return false
+}
+
+// parseGotoDate reads the /goto-date argument: an ISO date, "today", or
+// "yesterday"
+func parseGotoDate(arg string, now time.Time) (time.Time, error) {
+	switch strings.ToLower(arg) {
+	case "today":
+		return now, nil
+	case "yesterday":
+		return now.AddDate(0, 0, -1), nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", arg, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q: use YYYY-MM-DD, today, or yesterday", arg)
+	}
+	return day, nil
+}
@@ -0,0 +1,136 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// Default per-operation timeouts, used when the config leaves one unset
+const (
+	defaultChatSendTimeout     = 2 * time.Minute
+	defaultHistoryFetchTimeout = 15 * time.Second
+	defaultPlanStartTimeout    = time.Minute
+	defaultAPIKeyTimeout       = 15 * time.Second
+)
+
+// TimeoutConfig sets how long each kind of request may go unanswered, in
+// seconds. Zero uses the default
+type TimeoutConfig struct {
+	ChatSend     float64 `json:"chat_send,omitempty"`
+	HistoryFetch float64 `json:"history_fetch,omitempty"`
+	PlanStart    float64 `json:"plan_start,omitempty"`
+	APIKeys      float64 `json:"api_keys,omitempty"`
+}
+
+// For returns the timeout and a description of the operation a push event
+// starts; ok is false for events without a timeout
+func (t TimeoutConfig) For(event string) (timeout time.Duration, operation string, ok bool) {
+	switch event {
+	case "message":
+		return seconds(t.ChatSend, defaultChatSendTimeout), "your message", true
+	case "get_history":
+		return seconds(t.HistoryFetch, defaultHistoryFetchTimeout), "the history request", true
+	case "start_planning":
+		return seconds(t.PlanStart, defaultPlanStartTimeout), "the planning request", true
+	case "generate_api_key", "list_api_keys", "revoke_api_key", "get_api_key_usage":
+		return seconds(t.APIKeys, defaultAPIKeyTimeout), "the API key request", true
+	}
+	return 0, "", false
+}
+
+// apiKeyEvents maps API key error operations to the events that request them
+var apiKeyEvents = map[string]string{
+	"generate": "generate_api_key",
+	"list":     "list_api_keys",
+	"revoke":   "revoke_api_key",
+	"usage":    "get_api_key_usage",
+}
+
+// seconds converts a configured number of seconds, falling back to def
+func seconds(value float64, def time.Duration) time.Duration {
+	if value <= 0 {
+		return def
+	}
+	return time.Duration(value * float64(time.Second))
+}
+
+// RequestTimeoutMsg fires when a tracked push's timeout elapses
+type RequestTimeoutMsg struct {
+	RequestID string
+}
+
+// requestTimeout starts the timer for a sent push, or returns nil when its
+// event has no timeout
+func (m Model) requestTimeout(msg phoenix.RequestSentMsg) tea.Cmd {
+	if msg.RequestID == "" {
+		return nil
+	}
+	var timeouts TimeoutConfig
+	if m.config != nil {
+		timeouts = m.config.Timeouts
+	}
+	timeout, _, ok := timeouts.For(msg.Event)
+	if !ok {
+		return nil
+	}
+	id := msg.RequestID
+	return tea.Tick(timeout, func(time.Time) tea.Msg {
+		return RequestTimeoutMsg{RequestID: id}
+	})
+}
+
+// handleRequestTimeout gives up on a push that is still unanswered and keeps
+// it so /retry can send it again
+func (m Model) handleRequestTimeout(msg RequestTimeoutMsg) (Model, tea.Cmd) {
+	request, ok := m.requests.Resolve(msg.RequestID, "")
+	if !ok {
+		return m, nil
+	}
+	var timeouts TimeoutConfig
+	if m.config != nil {
+		timeouts = m.config.Timeouts
+	}
+	timeout, operation, _ := timeouts.For(request.Event)
+	m.timedOut = &request
+	m.telemetry.ErrorClass("Request", "timeout")
+
+	if request.Event == "message" {
+		m.latency.Fail()
+		m.isProcessing = m.requests.Outstanding("message") > 0
+		if !m.isProcessing {
+			m.activity.Stop()
+			m.streamID = ""
+		}
+	}
+
+	message := fmt.Sprintf("No reply to %s after %s", operation, timeout)
+	m.statusBar = message
+	m.statusMessages.AddMessage(StatusCategoryError, message, nil)
+	m.chat.AddMessage(ErrorMessage, message+"\nType /retry to send it again", "system")
+	return m, nil
+}
+
+// retryTimedOut sends the last timed out push again
+func (m Model) retryTimedOut() (Model, tea.Cmd) {
+	request := m.timedOut
+	if request == nil {
+		m.chat.AddMessage(SystemMessage, "Nothing to retry", "system")
+		return m, nil
+	}
+	client, ok := m.phoenixClient.(*phoenix.Client)
+	if !ok || !m.connected {
+		m.statusMessages.AddMessage(StatusCategoryError, "Not connected to server", nil)
+		return m, nil
+	}
+	m.timedOut = nil
+
+	if request.Event == "message" {
+		m.isProcessing = true
+		m.latency.Begin(m.activeProvider(), m.activeModel())
+	}
+	m.statusBar = fmt.Sprintf("Retrying %s...", request.Event)
+	return m, client.Channels().Push(request.Topic, request.Event, request.Payload, phoenix.PushOptions{NoReply: true})
+}
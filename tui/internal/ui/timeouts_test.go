@@ -0,0 +1,55 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rubber_duck/tui/internal/phoenix"
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+func TestTimeoutConfigFor(t *testing.T) {
+	timeouts := TimeoutConfig{HistoryFetch: 2.5}
+	if timeout, _, ok := timeouts.For("get_history"); !ok || timeout != 2500*time.Millisecond {
+		t.Errorf("Expected the configured history timeout, got %v", timeout)
+	}
+	if timeout, _, ok := timeouts.For("message"); !ok || timeout != defaultChatSendTimeout {
+		t.Errorf("Expected the default chat timeout, got %v", timeout)
+	}
+	if _, _, ok := timeouts.For("subscribe_status"); ok {
+		t.Error("Expected no timeout for untimed events")
+	}
+}
+
+func TestTimedOutMessageOffersRetry(t *testing.T) {
+	testutil.IsolateHome(t)
+	m := *NewModel()
+	m.isProcessing = true
+	sent := phoenix.RequestSentMsg{RequestID: "req-1", Topic: "conversation:lobby", Event: "message", Payload: map[string]any{"content": "hello"}}
+	updated, cmd := m.Update(sent)
+	m = updated.(Model)
+	if cmd == nil {
+		t.Fatal("Expected a timeout to be scheduled for the message")
+	}
+
+	updated, _ = m.Update(RequestTimeoutMsg{RequestID: "req-1"})
+	m = updated.(Model)
+	if m.isProcessing {
+		t.Error("Expected processing to stop when the only message timed out")
+	}
+	if m.timedOut == nil || m.timedOut.Event != "message" {
+		t.Fatalf("Expected the message to be kept for /retry, got %+v", m.timedOut)
+	}
+	messages := m.chat.GetMessages()
+	if last := messages[len(messages)-1].Content; !strings.Contains(last, "/retry") {
+		t.Errorf("Expected a retry hint, got %q", last)
+	}
+
+	// A timeout for an answered request is ignored
+	m.timedOut = nil
+	updated, _ = m.Update(RequestTimeoutMsg{RequestID: "req-1"})
+	if updated.(Model).timedOut != nil {
+		t.Error("Expected a stale timeout to be ignored")
+	}
+}
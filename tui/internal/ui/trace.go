@@ -0,0 +1,125 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// traceFieldWidth caps how much of a tool's input or result a trace shows
+const traceFieldWidth = 80
+
+// setLastMessageTrace attaches the tool calls behind the last answer
+func (c *Chat) setLastMessageTrace(steps []phoenix.ToolStep) {
+	if len(c.messages) == 0 || len(steps) == 0 {
+		return
+	}
+	c.messages[len(c.messages)-1].Trace = steps
+	c.viewport.SetContent(c.buildViewportContent())
+	c.viewport.GotoBottom()
+}
+
+// toggleTrace opens or closes a message's execution trace, returning false
+// when it has none
+func (c *Chat) toggleTrace(index int) bool {
+	if index < 0 || index >= len(c.messages) || len(c.messages[index].Trace) == 0 {
+		return false
+	}
+	c.messages[index].TraceOpen = !c.messages[index].TraceOpen
+	c.viewport.SetContent(c.buildViewportContent())
+	return true
+}
+
+// ToggleLastTrace toggles the trace of the most recent answer that has one
+func (c *Chat) ToggleLastTrace() bool {
+	for i := len(c.messages) - 1; i >= 0; i-- {
+		if len(c.messages[i].Trace) > 0 {
+			return c.toggleTrace(i)
+		}
+	}
+	return false
+}
+
+// summarizeInput renders a tool's input as key=value pairs in key order
+func summarizeInput(input map[string]any) string {
+	keys := make([]string, 0, len(input))
+	for key := range input {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = fmt.Sprintf("%s=%v", key, input[key])
+	}
+	return strings.Join(pairs, " ")
+}
+
+// firstLine returns the first non-blank line of text
+func firstLine(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// renderTrace renders a message's tool calls under its answer: a one-line
+// summary when closed, and each call's input, duration, and result when open
+func (c *Chat) renderTrace(index int) string {
+	msg := c.messages[index]
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	toolStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("75")).Bold(true)
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+
+	var total time.Duration
+	failed := 0
+	for _, step := range msg.Trace {
+		total += step.Duration
+		if step.Error != "" {
+			failed++
+		}
+	}
+	summary := fmt.Sprintf("Execution trace: %d tool calls", len(msg.Trace))
+	if total > 0 {
+		summary += ", " + formatLatency(total)
+	}
+	if failed > 0 {
+		summary += ", " + errorStyle.Render(fmt.Sprintf("%d failed", failed))
+	}
+
+	if !msg.TraceOpen {
+		hint := "select with Alt+↑ and press t to expand"
+		if c.selecting && c.selected == index {
+			hint = "press t to expand"
+		}
+		return dimStyle.Render("▸ ") + summary + dimStyle.Render(" ("+hint+")")
+	}
+
+	lines := []string{dimStyle.Render("▾ ") + summary}
+	for i, step := range msg.Trace {
+		mark := "✓"
+		if step.Error != "" {
+			mark = errorStyle.Render("✗")
+		}
+		line := fmt.Sprintf("  %s %d. %s", mark, i+1, toolStyle.Render(step.Tool))
+		if step.Duration > 0 {
+			line += dimStyle.Render(" " + formatLatency(step.Duration))
+		}
+		lines = append(lines, line)
+		if input := summarizeInput(step.Input); input != "" {
+			lines = append(lines, dimStyle.Render("      in:  "+truncateStage(input, traceFieldWidth)))
+		}
+		switch {
+		case step.Error != "":
+			lines = append(lines, errorStyle.Render("      err: "+truncateStage(firstLine(step.Error), traceFieldWidth)))
+		case step.Result != "":
+			lines = append(lines, dimStyle.Render("      out: "+truncateStage(firstLine(step.Result), traceFieldWidth)))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
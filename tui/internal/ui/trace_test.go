@@ -0,0 +1,41 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+func TestToolStepsParsesTrace(t *testing.T) {
+	response := phoenix.ConversationMessage{Metadata: map[string]any{
+		"tool_calls": []any{
+			map[string]any{"tool": "read_file", "input": map[string]any{"path": "main.go"}, "duration_ms": float64(120), "result": "package main\nfunc main() {}"},
+			map[string]any{"name": "run_tests", "duration_ms": float64(900), "error": "exit status 1"},
+			map[string]any{"input": map[string]any{}},
+		},
+	}}
+	steps := response.ToolSteps()
+	if len(steps) != 2 || steps[1].Tool != "run_tests" || steps[0].Duration != 120*time.Millisecond {
+		t.Fatalf("Unexpected steps: %+v", steps)
+	}
+
+	chat := NewChat()
+	chat.AddMessage(AssistantMessage, "Done", "assistant")
+	chat.setLastMessageTrace(steps)
+	closed := chat.renderTrace(0)
+	if !strings.Contains(closed, "Execution trace: 2 tool calls, 1.0s") || strings.Contains(closed, "read_file") {
+		t.Errorf("Expected a one-line summary while closed, got:\n%s", closed)
+	}
+
+	if !chat.ToggleLastTrace() {
+		t.Fatal("Expected the trace to open")
+	}
+	open := chat.renderTrace(0)
+	for _, want := range []string{"read_file", "in:  path=main.go", "out: package main", "err: exit status 1"} {
+		if !strings.Contains(open, want) {
+			t.Errorf("Expected %q in the open trace, got:\n%s", want, open)
+		}
+	}
+}
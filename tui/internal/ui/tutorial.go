@@ -0,0 +1,172 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rubber_duck/tui/internal/phoenix"
+)
+
+// tutorialStep is one lesson of /tutorial. Done verifies the user did it;
+// in practice mode, steps that need a server are confirmed with /tutorial next
+type tutorialStep struct {
+	Title       string
+	Instruction string
+	Practice    string // Instruction used in practice mode, if different
+	Pane        Pane   // Pane highlighted while the step is shown
+	Done        func(m *Model, t *Tutorial) bool
+}
+
+// tutorialSteps are the lessons in order
+var tutorialSteps = []tutorialStep{
+	{
+		Title:       "Connect",
+		Instruction: "The header shows the connection. Press Ctrl+R to reconnect if it says Disconnected.",
+		Practice:    "No server is running, so this tour uses a simulated one. Ctrl+R connects to a real server. Type /tutorial next.",
+		Pane:        ChatPane,
+		Done:        func(m *Model, _ *Tutorial) bool { return m.connected },
+	},
+	{
+		Title:       "Log in",
+		Instruction: "Type /login and fill in the form, or start with -api-key to log in automatically.",
+		Practice:    "On a real server you'd type /login here. Type /tutorial next.",
+		Pane:        ChatPane,
+		Done:        func(m *Model, _ *Tutorial) bool { return m.authenticated },
+	},
+	{
+		Title:       "Choose a model",
+		Instruction: "Pick the model that answers: type /model gpt-4 (add a provider, e.g. /model llama3 ollama).",
+		Pane:        ChatPane,
+		Done:        func(m *Model, _ *Tutorial) bool { return m.activeModel() != "" },
+	},
+	{
+		Title:       "Ask something",
+		Instruction: "Type a question and press Enter.",
+		Pane:        ChatPane,
+		Done:        func(m *Model, t *Tutorial) bool { return m.chat.UserMessageCount() > t.baseline },
+	},
+	{
+		Title:       "Plan",
+		Instruction: "For bigger tasks, ask for a plan: /plan add input validation to the signup form",
+		Pane:        ChatPane,
+		Done:        func(_ *Model, t *Tutorial) bool { return t.planned },
+	},
+	{
+		Title:       "Edit files",
+		Instruction: "Press Alt+3 (or Ctrl+E, then Tab) to show and focus the editor. Ctrl+F opens the file tree.",
+		Pane:        EditorPane,
+		Done:        func(m *Model, _ *Tutorial) bool { return m.showEditor && m.activePane == EditorPane },
+	},
+}
+
+// Tutorial walks new users through the main features, checking each step
+type Tutorial struct {
+	active   bool
+	step     int
+	practice bool                // No server: server steps are simulated
+	mock     *phoenix.MockClient // Answers messages in practice mode
+	baseline int                 // User messages when the current step began
+	planned  bool                // A plan was requested during the step
+}
+
+// countUserMessages counts the messages the user sent
+func countUserMessages(messages []ChatMessage) int {
+	count := 0
+	for _, msg := range messages {
+		if msg.Type == UserMessage {
+			count++
+		}
+	}
+	return count
+}
+
+// current returns the step being shown
+func (t *Tutorial) current() tutorialStep {
+	return tutorialSteps[t.step]
+}
+
+// startTutorial begins the tutorial, practicing against the MockClient
+// when no server is connected
+func (m *Model) startTutorial() {
+	m.tutorial = Tutorial{active: true, practice: !m.connected}
+	if m.tutorial.practice {
+		m.tutorial.mock = phoenix.NewMockClient(nil)
+	}
+	m.tutorial.begin(m)
+	m.statusBar = "Tutorial started"
+}
+
+// begin resets the verification state for the current step
+func (t *Tutorial) begin(m *Model) {
+	t.baseline = m.chat.UserMessageCount()
+	t.planned = false
+	m.focusPane(ChatPane)
+}
+
+// verify moves on once the current step is done
+func (t *Tutorial) verify(m *Model) {
+	for t.active && t.current().Done(m, t) {
+		t.advance(m)
+	}
+}
+
+// advance moves to the next step, finishing after the last
+func (t *Tutorial) advance(m *Model) {
+	m.chat.AddMessage(SystemMessage, fmt.Sprintf("✓ Tutorial: %s", t.current().Title), "tutorial")
+	t.step++
+	if t.step == len(tutorialSteps) {
+		t.active = false
+		m.chat.AddMessage(SystemMessage, "Tutorial complete! /help lists every command and /help <command> explains one.", "tutorial")
+		return
+	}
+	t.begin(m)
+}
+
+// skipTutorialStep moves past a step without verifying it; in practice
+// mode this is how server steps are completed
+func (m *Model) skipTutorialStep() {
+	if !m.tutorial.active {
+		m.statusMessages.AddMessage(StatusCategoryError, "No tutorial running. Type /tutorial to start one", nil)
+		return
+	}
+	m.tutorial.advance(m)
+}
+
+// practicing reports whether messages should go to the practice client
+func (t *Tutorial) practicing() bool {
+	return t.active && t.practice
+}
+
+// highlights reports whether the tutorial points at a pane
+func (t *Tutorial) highlights(pane Pane) bool {
+	return t.active && t.current().Pane == pane
+}
+
+// renderTutorialCard renders the current step over the status messages
+func (m Model) renderTutorialCard(width, height int) string {
+	step := m.tutorial.current()
+	instruction := step.Instruction
+	if m.tutorial.practice && step.Practice != "" {
+		instruction = step.Practice
+	}
+	title := fmt.Sprintf("Tutorial %d/%d · %s", m.tutorial.step+1, len(tutorialSteps), step.Title)
+	if m.tutorial.practice {
+		title += " (practice)"
+	}
+
+	lines := []string{
+		lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("214")).Render(title),
+		instruction,
+		lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render("/tutorial next skips · /tutorial quit ends"),
+	}
+	return lipgloss.NewStyle().
+		Width(width).
+		MaxHeight(height).
+		Render(strings.Join(lines, "\n"))
+}
+
+// tutorialBorderStyle outlines the pane the tutorial points at
+var tutorialBorderStyle = lipgloss.NewStyle().
+	Border(lipgloss.RoundedBorder()).
+	BorderForeground(lipgloss.Color("214"))
@@ -0,0 +1,52 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/testutil"
+)
+
+func TestTutorialPracticeMode(t *testing.T) {
+	testutil.IsolateHome(t)
+	var model Model = *NewModel()
+	run := func(msg tea.Msg) {
+		updated, cmd := model.Update(msg)
+		model = updated.(Model)
+		// Follow the command chain, e.g. a slash command and the practice reply
+		for i := 0; cmd != nil && i < 5; i++ {
+			next := cmd()
+			if next == nil {
+				break
+			}
+			updated, cmd = model.Update(next)
+			model = updated.(Model)
+		}
+	}
+
+	run(ExecuteCommandMsg{Command: "tutorial"})
+	if !model.tutorial.active || !model.tutorial.practice {
+		t.Fatal("Expected the tutorial to start in practice mode without a server")
+	}
+
+	// Server steps are confirmed by hand in practice mode
+	run(ExecuteCommandMsg{Command: "tutorial_next"})
+	run(ExecuteCommandMsg{Command: "tutorial_next"})
+	run(ExecuteCommandMsg{Command: "set_model", Args: map[string]string{"model": "gpt-4"}})
+	if got := model.tutorial.current().Title; got != "Ask something" {
+		t.Fatalf("Expected setting a model to be verified, on step %q", got)
+	}
+
+	run(ChatMessageSentMsg{Content: "what is a rubber duck?"})
+	messages := model.chat.GetMessages()
+	if last := messages[len(messages)-1].Content; !strings.Contains(last, "Mock response to: what is a rubber duck?") {
+		t.Fatalf("Expected the practice client to answer, got %q", last)
+	}
+
+	run(ExecuteCommandMsg{Command: "start_planning", Args: map[string]string{"query": "add tests"}})
+	run(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("3"), Alt: true})
+	if model.tutorial.active {
+		t.Fatalf("Expected the tutorial to finish, still on %q", model.tutorial.current().Title)
+	}
+}
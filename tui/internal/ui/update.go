@@ -1,18 +1,74 @@
 package ui
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 	
 	"github.com/atotto/clipboard"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rubber_duck/tui/internal/clock"
+	"github.com/rubber_duck/tui/internal/metrics"
 	"github.com/rubber_duck/tui/internal/phoenix"
 )
 
-// Update handles all state transitions
+// Update handles all state transitions, then reports scrollback the spill
+// ring dropped and checks whether the tutorial's current step is done
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	updated, cmd := m.update(msg)
+	model, ok := updated.(Model)
+	if !ok {
+		return updated, cmd
+	}
+	model.reportDroppedHistory()
+	if model.tutorial.active {
+		model.tutorial.verify(&model)
+	}
+	return model, cmd
+}
+
+// update handles a message
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
+	
+	// Demo mode never sends credentials anywhere
+	if m.demo != nil {
+		switch msg.(type) {
+		case LoginSubmitMsg, RegisterSubmitMsg, PasswordChangeSubmitMsg, ProviderCredentialsSubmitMsg:
+			m.accountModal.Hide()
+			m.refuseInDemo()
+			return m, nil
+		}
+	}
+
+	// The lock screen takes all input; everything else carries on
+	// underneath so the session stays connected
+	if isInputMsg(msg) {
+		m.lastInput = clock.Now()
+	}
+	if m.lockScreen.IsVisible() && isInputMsg(msg) {
+		if key, ok := msg.(tea.KeyMsg); ok && (key.String() == "ctrl+c" || key.String() == "ctrl+q") {
+			return m, tea.Quit
+		}
+		var cmd tea.Cmd
+		m.lockScreen, cmd = m.lockScreen.Update(msg)
+		return m, cmd
+	}
+	if m.handleLockLogin(msg) {
+		return m, nil
+	}
+
+	// Macros record keys before anything else sees them, and played keys
+	// carry on as if typed
+	next, macroCmd, handled := m.macroInput(msg)
+	if handled {
+		return m, macroCmd
+	}
+	msg = next
 
 	// Handle global keys first
 	switch msg := msg.(type) {
@@ -24,6 +80,125 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, cmd
 		}
 		
+		if m.permissionPrompt.IsVisible() {
+			var cmd tea.Cmd
+			m.permissionPrompt, cmd = m.permissionPrompt.Update(msg)
+			return m, cmd
+		}
+		
+		// Hint mode takes the keys until a label is typed or it is left
+		if m.chat.HintsActive() {
+			cmd := m.chat.updateHints(msg)
+			if cmd == nil && !m.chat.HintsActive() {
+				m.statusBar = "Hints cancelled"
+			}
+			return m, cmd
+		}
+		
+		// Check if compose modal is visible
+		if m.composeModal.IsVisible() {
+			var cmd tea.Cmd
+			m.composeModal, cmd = m.composeModal.Update(msg)
+			// Closing without sending hands the draft back to the chat input
+			if !m.composeModal.IsVisible() && cmd == nil {
+				m.chat.SetInputValue(m.composeModal.Draft())
+			}
+			return m, cmd
+		}
+		
+		// Account forms capture all keys so passwords never reach the chat
+		if m.accountModal.IsVisible() {
+			var cmd tea.Cmd
+			m.accountModal, cmd = m.accountModal.Update(msg)
+			return m, cmd
+		}
+		
+		// The generated key is only ever shown inside the modal
+		if m.apiKeyModal.IsVisible() {
+			var cmd tea.Cmd
+			m.apiKeyModal, cmd = m.apiKeyModal.Update(msg)
+			return m, cmd
+		}
+		
+		if m.apiKeyList.IsVisible() {
+			var cmd tea.Cmd
+			m.apiKeyList, cmd = m.apiKeyList.Update(msg)
+			return m, cmd
+		}
+		
+		// Check if search pane is visible
+		if m.searchPane.IsVisible() {
+			var cmd tea.Cmd
+			m.searchPane, cmd = m.searchPane.Update(msg)
+			return m, cmd
+		}
+		
+		if m.applyPreview.IsVisible() {
+			var cmd tea.Cmd
+			m.applyPreview, cmd = m.applyPreview.Update(msg)
+			return m, cmd
+		}
+		
+		if m.changesReview.IsVisible() {
+			var cmd tea.Cmd
+			m.changesReview, cmd = m.changesReview.Update(msg)
+			return m, cmd
+		}
+		
+		if m.reactView.IsVisible() {
+			var cmd tea.Cmd
+			m.reactView, cmd = m.reactView.Update(msg)
+			return m, cmd
+		}
+		
+		if m.deadLetters.IsVisible() {
+			var cmd tea.Cmd
+			m.deadLetters, cmd = m.deadLetters.Update(msg)
+			return m, cmd
+		}
+		
+		if m.modelSwitcher.IsVisible() {
+			var cmd tea.Cmd
+			m.modelSwitcher, cmd = m.modelSwitcher.Update(msg)
+			return m, cmd
+		}
+		
+		if m.emojiPicker.IsVisible() {
+			var cmd tea.Cmd
+			m.emojiPicker, cmd = m.emojiPicker.Update(msg)
+			return m, cmd
+		}
+		
+		if m.statusLegend.IsVisible() {
+			var cmd tea.Cmd
+			m.statusLegend, cmd = m.statusLegend.Update(msg)
+			return m, cmd
+		}
+		
+		if m.statusSubscriptions.IsVisible() {
+			var cmd tea.Cmd
+			m.statusSubscriptions, cmd = m.statusSubscriptions.Update(msg)
+			return m, cmd
+		}
+		
+		if m.problemsPane.IsVisible() {
+			var cmd tea.Cmd
+			m.problemsPane, cmd = m.problemsPane.Update(msg)
+			return m, cmd
+		}
+		
+		if m.adminPane.IsVisible() {
+			var cmd tea.Cmd
+			m.adminPane, cmd = m.adminPane.Update(msg)
+			// Stop receiving admin events once the pane is closed
+			if !m.adminPane.IsVisible() {
+				if adminClient, ok := m.adminClient.(*phoenix.AdminClient); ok {
+					adminClient.LeaveChannel()
+				}
+			}
+			return m, cmd
+		}
+		
 		// Check if command palette is visible
 		if m.commandPalette.IsVisible() {
 			switch msg.String() {
@@ -36,27 +211,31 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, cmd
 		}
 		
+		// Alt+1..4 focus a pane directly
+		if pane, ok := focusKeys[msg.String()]; ok {
+			m.focusPane(pane)
+			m.statusBar = paneNames[pane] + " focused"
+			return m, nil
+		}
+		
 		// Global hotkeys
 		switch msg.String() {
 		case "ctrl+c", "ctrl+q":
 			return m, tea.Quit
 		case "tab":
-			m.activePane = m.nextPane()
+			m.focusPane(m.nextPane())
+			m.statusBar = paneNames[m.activePane] + " focused"
 			return m, nil
 		case "ctrl+p":
 			m.commandPalette.Show()
 			return m, nil
 		case "ctrl+h":
-			m.modal = Modal{
-				modalType: HelpModal,
-				title:     "Help",
-				content:   m.buildHelpContent(),
-				visible:   true,
-			}
+			m.modal.Show(HelpModal, "Help", m.buildHelpContent())
 			return m, nil
 		case "ctrl+f":
 			m.showFileTree = !m.showFileTree
 			m.updateComponentSizes()
+			m.refocusIfHidden()
 			if m.showFileTree {
 				m.statusBar = "File tree shown"
 			} else {
@@ -66,6 +245,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "ctrl+e":
 			m.showEditor = !m.showEditor
 			m.updateComponentSizes()
+			m.refocusIfHidden()
 			if m.showEditor {
 				m.statusBar = "Editor shown"
 			} else {
@@ -73,8 +253,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, nil
 		case "ctrl+/":
-			m.activePane = ChatPane
-			m.chat.Focus()
+			m.focusPane(ChatPane)
 			m.statusBar = "Chat focused"
 			return m, nil
 		case "ctrl+r":
@@ -111,6 +290,25 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "ctrl+t":
 			// Toggle mouse mode info
 			return m, func() tea.Msg { return ToggleMouseModeMsg{} }
+		case "alt+e":
+			// Terminals can't report Ctrl+Shift+E distinctly from Ctrl+E, so
+			// the compose modal lives on Alt+E (and /compose)
+			return m.handleCommand(ExecuteCommandMsg{Command: "compose"})
+		case "alt+f":
+			return m.handleCommand(ExecuteCommandMsg{Command: "search"})
+		case "alt+l":
+			return m.handleCommand(ExecuteCommandMsg{Command: "link_hints"})
+		case "alt+p":
+			return m.handleCommand(ExecuteCommandMsg{Command: "path_hints"})
+		case "ctrl+.", "alt+.":
+			// Most terminals send Ctrl+. as a plain ".", so Alt+. opens it too
+			return m.handleCommand(ExecuteCommandMsg{Command: "emoji"})
+		case "alt+o":
+			return m.handleCommand(ExecuteCommandMsg{Command: "toggle_outline"})
+		case "alt+m":
+			// Terminals send Ctrl+M as Enter, so the model switcher lives on
+			// Alt+M (and /models)
+			return m.handleCommand(ExecuteCommandMsg{Command: "model_switcher"})
 		}
 		
 		// Handle pane-specific input
@@ -130,9 +328,47 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				cmds = append(cmds, cmd)
 			}
 		case EditorPane:
+			// The ask panel takes the keys while it is open under the editor
+			if m.askPanel.IsVisible() {
+				var cmd tea.Cmd
+				m.askPanel, cmd = m.askPanel.Update(msg)
+				if !m.askPanel.IsVisible() {
+					m.updateComponentSizes()
+				}
+				return m, cmd
+			}
+			switch msg.String() {
+			case "alt+v":
+				m.toggleEditorMark()
+				return m, nil
+			case "alt+a":
+				m.openAskPanel()
+				return m, nil
+			}
+			// Resolve the file-changed banner from the editor
+			if m.fileChangedOnDisk {
+				switch msg.String() {
+				case "alt+r":
+					return m.reloadCurrentFile()
+				case "alt+k":
+					return m.keepBuffer()
+				case "alt+d":
+					return m.diffBuffer()
+				}
+			}
+			if msg.String() == "ctrl+s" {
+				return m, m.saveFile()
+			}
 			if m.showEditor {
 				var cmd tea.Cmd
 				m.editor, cmd = m.editor.Update(msg)
+				m.refreshOutline()
+				cmds = append(cmds, cmd)
+			}
+		case OutlinePane:
+			if m.showOutline {
+				var cmd tea.Cmd
+				m.outline, cmd = m.outline.Update(msg)
 				cmds = append(cmds, cmd)
 			}
 		}
@@ -156,6 +392,15 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 		
 	case InitiateConnectionMsg:
+		if m.demo != nil {
+			m.startDemo()
+			return m, nil
+		}
+		// A known server that changed stays refused until /trust accept
+		if m.serverChange != nil {
+			m.statusBar = fmt.Sprintf("Not connecting: %s's %s changed - /trust accept if expected", m.serverChange.Host, m.serverChange.Kind)
+			return m, nil
+		}
 		// Check if connection is blocked due to too many attempts
 		if m.connectionBlocked {
 			m.statusBar = "Connection blocked - too many failed attempts"
@@ -171,16 +416,18 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.connectionBlocked = true
 			m.statusBar = "Connection blocked after repeated failures. Please restart TUI."
 			m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Connection blocked after %d failed attempts. Please verify the server is running and restart the TUI.", maxTotalAttempts), nil)
-			return m, nil
+			return m, m.enterOffline()
 		}
 		
 		m.statusBar = fmt.Sprintf("Connecting to auth server... (attempt %d)", m.totalConnectionAttempts)
 		client := m.phoenixClient.(*phoenix.Client)
 		// First connect to auth socket
 		config := phoenix.Config{
-			URL:     m.authSocketURL,
-			IsAuth:  true,
-			Channel: "auth:lobby",
+			URL:         m.authSocketURL,
+			IsAuth:      true,
+			Channel:     "auth:lobby",
+			Network:     m.networkOptions(),
+			Fingerprint: m.pinnedFingerprint(m.authSocketURL),
 		}
 		return m, client.Connect(config)
 		
@@ -189,6 +436,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.reconnectAttempts = 0
 		m.totalConnectionAttempts = 0
 		m.connectionBlocked = false
+		m.leaveOffline()
 		
 		// Update connection status based on socket type
 		if msg.SocketType == phoenix.AuthSocketType {
@@ -203,12 +451,13 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.switchingSocket = false // Clear the switching flag
 			m.statusBar = "Connected to authenticated socket - Joining channels..."
 			m.updateHeaderState()
-			// Join conversation, status, api_keys, and planning channels
-			return m, tea.Batch(
-				func() tea.Msg { return JoinConversationChannelMsg{} },
-				func() tea.Msg { return JoinApiKeyChannelMsg{} },
-				func() tea.Msg { return JoinPlanningChannelMsg{} },
-			)
+			// After a reconnect the channel manager rejoins the channels itself
+			if client, ok := m.phoenixClient.(*phoenix.Client); ok && client.Channels().Registered("conversation:lobby") {
+				m.statusBar = "Connected to authenticated socket - Rejoining channels..."
+				return m, nil
+			}
+			// The conversation join negotiates capabilities; the other channels follow it
+			return m, func() tea.Msg { return JoinConversationChannelMsg{} }
 		}
 		
 	case phoenix.DisconnectedMsg:
@@ -227,15 +476,24 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		m.updateHeaderState()
 		
+		// Refused credentials would fail every reconnect, so sign out instead
+		if phoenix.IsCredentialsRevoked(msg.Error) {
+			return m, m.credentialsRevoked("", "")
+		}
+		
 		if msg.Error != nil {
 			// Use error handler for disconnect errors
-			if display, message := m.errorHandler.HandleError(msg.Error, "Connection"); display {
+			if display, message := m.reportError(msg.Error, "Connection"); display {
 				m.statusBar = message
 				m.statusMessages.AddMessage(StatusCategoryError, message, nil)
 				
 				// Add reconnection advice
 				m.statusMessages.AddMessage(StatusCategoryInfo, "Connection lost. You can try reconnecting with Ctrl+R or restart the TUI.", nil)
 			}
+			// Without any connection, fall back to offline mode
+			if !m.connected {
+				return m, m.enterOffline()
+			}
 		} else {
 			m.statusBar = "Disconnected"
 			// Reset error handler on clean disconnect
@@ -243,6 +501,36 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 		
+	case FileWatchTickMsg:
+		m.chat.refreshRelativeTimes()
+		m.sampleTraffic(time.Time(msg))
+		return m.handleFileWatchTick()
+		
+	case FileWatchResultMsg:
+		return m.handleFileWatchResult(msg)
+		
+	case phoenix.FileChangedMsg:
+		return m.handleServerFileChanged(msg)
+		
+	case OfflineProbeMsg:
+		if !m.offline {
+			return m, nil
+		}
+		// Keep probing until a connection succeeds
+		next := probeServer(m.authSocketURL, offlineProbeInterval)
+		if !msg.Reachable {
+			return m, next
+		}
+		m.statusBar = "Server reachable - reconnecting..."
+		m.totalConnectionAttempts = 0
+		m.connectionBlocked = false
+		m.errorHandler.Reset()
+		return m, tea.Batch(next, func() tea.Msg { return InitiateConnectionMsg{} })
+		
+	case phoenix.ServerFingerprintMsg:
+		m.handleServerFingerprint(msg)
+		return m, nil
+		
 	case phoenix.SocketCreatedMsg:
 		// Store socket based on authenticated state
 		if !m.authenticated {
@@ -250,11 +538,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.authSocket = msg.Socket
 		} else {
 			// After authentication, we're creating user socket
+			// The client's channel manager rejoins its channels on it
 			m.socket = msg.Socket
-			// Update clients with new socket
-			if statusClient, ok := m.statusClient.(*phoenix.StatusClient); ok {
-				statusClient.SetSocket(m.socket)
-			}
 		}
 		return m, nil
 		
@@ -269,14 +554,22 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.conversationID = convID
 					m.chatHeader.SetConversationID(convID)
 					m.statusBar = fmt.Sprintf("Joined conversation %s", convID)
+					negotiation := phoenix.ParseNegotiation(msg.Response)
+					m.applyNegotiation(negotiation)
+					m.checkInstance(negotiation.InstanceID)
+					if m.serverChange != nil {
+						return m, nil
+					}
 					
-					// Don't request history immediately - wait for channel to be fully ready
-					// Just join the status channel
-					if statusClient, ok := m.statusClient.(*phoenix.StatusClient); ok {
-						statusClient.SetSocket(m.socket)
-						statusClient.SetProgram(m.ProgramHolder())
-						return m, statusClient.JoinStatusChannel(m.conversationID)
+					// Server-backed files need the conversation channel
+					var listFiles tea.Cmd
+					if m.config.TUI.FileSource == FileSourceServer {
+						listFiles = m.applyFileSource()
 					}
+					
+					// Don't request history immediately - wait for channel to be fully ready
+					// Join status plus the channels the server supports
+					return m, tea.Batch(append(m.joinFeatureChannels(), listFiles)...)
 				}
 			}
 		}
@@ -289,34 +582,80 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 		
 	case ChatMessageSentMsg:
+		// Demo mode and the tutorial's practice mode answer without a server
+		if m.demo != nil {
+			return m, m.sendToMock(m.demo, msg.Content)
+		}
+		if m.tutorial.practicing() {
+			return m, m.sendToMock(m.tutorial.mock, msg.Content)
+		}
+		// Local Ollama works without the server
+		local := m.isLocalProvider()
+		if m.offline && !local {
+			m.chat.SetInputValue(msg.Content)
+			m.statusMessages.AddMessage(StatusCategoryError, "Offline - messages can't be sent to the server. Use /provider ollama-local to chat with a local model.", nil)
+			return m, nil
+		}
 		// Check if authenticated first
-		if !m.authenticated {
-			m.statusMessages.AddMessage(StatusCategoryError, "You must be authenticated to send messages. Use /login <username> <password>", nil)
+		if !local && !m.authenticated {
+			m.statusMessages.AddMessage(StatusCategoryError, "You must be authenticated to send messages. Use /login", nil)
 			return m, nil
 		}
 		// Check if conversation channel is joined
-		if m.channel == nil {
+		if !local && m.channel == nil {
 			m.statusMessages.AddMessage(StatusCategoryError, "Not connected to conversation channel", nil)
 			return m, nil
 		}
 		// Check if provider and model are set
-		if m.currentProvider == "" || m.currentModel == "" {
+		if m.activeProvider() == "" || m.activeModel() == "" {
 			m.statusMessages.AddMessage(StatusCategoryError, "Please set both provider and model before sending messages. Use /provider <name> and /model <name>", nil)
 			m.chat.AddMessage(SystemMessage, "Please configure your LLM:\n• Use /provider <name> to set the provider\n• Use /model <name> to set the model\n\nExample:\n/provider openai\n/model gpt-4", "system")
 			return m, nil
 		}
+		// Offer to mask secrets before anything leaves the machine
+		if !msg.SecretsChecked && m.checkSecrets(msg.Content) {
+			return m, nil
+		}
+		// Confirm sends that would exceed the spend budget
+		inputTokens := m.chat.TokenEstimate() + EstimateTokens(msg.Content)
+		if msg.BudgetConfirmed || msg.SecretsChecked {
+			m.chat.SetInputValue("")
+		} else if projected, priced := m.config.EstimateCost(m.activeModel(), inputTokens, assumedResponseTokens); priced {
+			if level, reason := m.cost.CheckBudget(m.config.Budget, projected); level == BudgetExceeded {
+				content := msg.Content
+				// Keep the draft in the input in case the send is cancelled
+				m.chat.SetInputValue(content)
+				m.modal.ShowConfirm("Budget exceeded", fmt.Sprintf("%s.\nThis send is estimated at %s.\n\nSend anyway?", reason, formatCost(projected)), func() tea.Msg {
+					return ChatMessageSentMsg{Content: content, BudgetConfirmed: true, SecretsChecked: true}
+				})
+				return m, nil
+			}
+		}
+		m.costInputTokens = inputTokens
+		
 		// Send message through Phoenix channel
 		m.chat.AddMessage(UserMessage, msg.Content, "user")
+		m.lastReplyTo = m.chat.takeReply()
+		m.pendingUnredacted = ""
+		m.telemetry.Feature("chat_send")
+		m.lastPrompt = m.wrapPrompt(msg.Content)
+		m.fallbackTried = nil
+		m.activeFallback = nil
+		m.pendingFallback = nil
 		m.messageCount = m.chat.GetMessageCount()
 		// Update token usage
-		m.tokenUsage = EstimateConversationTokens(m.chat.GetMessages())
-		m.tokenLimit = GetModelTokenLimit(m.currentModel)
+		m.tokenUsage = m.chat.TokenEstimate()
+		m.tokenLimit = GetModelTokenLimit(m.activeModel())
 		m.updateHeaderState()
 		m.statusBar = "Sending message..."
 		m.isProcessing = true // Mark as processing
+		if local {
+			return m.sendToOllama()
+		}
 		if client, ok := m.phoenixClient.(*phoenix.Client); ok && m.connected {
+			m.latency.Begin(m.activeProvider(), m.activeModel())
 			// Always send with provider and model configuration
-			return m, client.SendMessageWithConfig(msg.Content, m.currentModel, m.currentProvider, m.temperature)
+			return m, client.SendReplyWithConfig(m.lastPrompt, m.lastReplyTo, m.activeModel(), m.activeProvider(), m.activeTemperature())
 		}
 		// If not connected, show error
 		m.statusMessages.AddMessage(StatusCategoryError, "Not connected to server", nil)
@@ -336,20 +675,107 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			msgType = AssistantMessage
 		}
 		m.chat.AddMessage(msgType, msg.Content, msg.Type)
+		if msgType == AssistantMessage {
+			m.showResponse(msg.Content)
+		}
 		m.statusBar = "Message received"
 		return m, nil
 		
 	case FileSelectedMsg:
-		m.currentFile = msg.Path
-		m.statusBar = fmt.Sprintf("Loading %s...", msg.Path)
-		// TODO: Load file content
+		return m.openFile(msg)
+		
+	case ApplyHunksMsg:
+		m.applyHunksToBuffer(msg)
+		return m, nil
+		
+	case ApplyNextChangeMsg:
+		if !m.permitChangeBatch(msg) {
+			m.changesReview.applying = false
+			return m, nil
+		}
+		m.changesReview.applying = true
+		return m, m.applyNextChange(msg.Index)
+		
+	case PermissionDecisionMsg:
+		return m, m.handlePermissionDecision(msg)
+		
+	case CopyMessageMsg:
+		m.copyMessage(msg)
+		return m, nil
+		
+	case ReplyToMessageMsg:
+		m.startReply(msg)
+		return m, nil
+		
+	case toastExpiredMsg:
+		m.expireToast(msg)
+		return m, nil
+		
+	case EmojiPickedMsg:
+		m.insertEmoji(msg.Symbol)
+		return m, nil
+		
+	case StatusColorsSavedMsg:
+		m.saveStatusColors(msg.Colors)
+		return m, nil
+		
+	case StatusSubscriptionToggledMsg:
+		return m, m.toggleStatusSubscription(msg)
+		
+	case HintSelectedMsg:
+		switch msg.Kind {
+		case "link":
+			m.handleLinkHint(msg.Text, msg.Shift)
+		case "path":
+			return m, m.handlePathHint(msg.Text, msg.Shift)
+		}
+		return m, nil
+		
+	case PasteCheckedMsg:
+		chatModel, cmd := m.chat.handleCheckedPaste(msg)
+		if chat, ok := chatModel.(Chat); ok {
+			m.chat = &chat
+		}
+		return m, cmd
+		
+	case StackTracePastedMsg:
+		m.handleStackTracePasted(msg)
+		return m, nil
+		
+	case AttachTraceFilesMsg:
+		m.attachTraceFiles(msg.Paths)
+		return m, nil
+		
+	case PullRequestCreatedMsg:
+		m.handlePullRequestCreated(msg)
+		return m, nil
+		
+	case phoenix.ReactStepMsg:
+		m.handleReactStep(msg)
+		return m, nil
+		
+	case phoenix.ReactStateMsg:
+		m.handleReactState(msg)
+		return m, nil
+		
+	case SymbolSelectedMsg:
+		m.focusPane(EditorPane)
+		m.moveEditorToLine(msg.Line)
+		return m, nil
+		
+	case SearchRequestMsg:
+		return m, m.runSearch(msg.Query, msg.Globs)
+		
+	case SearchResultsMsg:
+		m.searchPane.SetResults(msg)
 		return m, nil
 		
 	case ErrorMsg:
 		m.err = msg.Err
 		// Use error handler to prevent spam
-		if display, message := m.errorHandler.HandleError(msg.Err, msg.Component); display {
-			m.statusBar = message
+		var cueCmd tea.Cmd
+		if display, message := m.reportError(msg.Err, msg.Component); display {
+			cueCmd = m.cue(CueError, message)
 			m.statusMessages.AddMessage(StatusCategoryError, message, nil)
 			
 			// Add connection advice if available
@@ -357,15 +783,24 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.statusMessages.AddMessage(StatusCategoryInfo, advice, nil)
 			}
 		}
-		return m, nil
+		return m, cueCmd
 		
 	case ExecuteCommandMsg:
-		return m.handleCommand(msg)
+		return m.runCommand(msg)
+	
+	case ClearCacheMsg:
+		m.handleClearCache(msg)
+		return m, nil
 		
 	case CancelRequestMsg:
 		// Only process cancel if we're currently processing
 		if m.isProcessing {
 			m.statusBar = "Cancelling..."
+			// Local requests are cancelled client-side
+			if m.isLocalProvider() && m.ollamaClient != nil {
+				m.ollamaClient.Cancel()
+				return m, func() tea.Msg { return ProcessingCancelledMsg{} }
+			}
 			if client, ok := m.phoenixClient.(*phoenix.Client); ok && m.connected {
 				return m, client.CancelProcessing()
 			}
@@ -373,7 +808,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 		
 	case ProcessingCancelledMsg:
+		m.requests.ResolveAll("message")
 		m.isProcessing = false
+		m.streamID = ""
+		m.regenerateFrom = ""
+		m.activity.Stop()
+		m.latency.Cancel()
 		m.statusBar = "Request cancelled"
 		m.chat.AddMessage(SystemMessage, "Request cancelled by user", "system")
 		return m, nil
@@ -382,107 +822,213 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case phoenix.ConversationResponseMsg:
 		// Parse the response
 		var response phoenix.ConversationMessage
-		if err := json.Unmarshal(msg.Response, &response); err == nil {
+		var cueCmd tea.Cmd
+		err := json.Unmarshal(msg.Response, &response)
+		if err == nil {
 			// Use response handler to format the response based on conversation type
 			formattedResponse := m.responseHandlers.FormatResponse(response)
 			
+			// Match the response to the message that asked for it
+			request, _ := m.resolveRequest(response.RequestID, "conversation:lobby", "response", "message")
+			stillPending := m.requests.Outstanding("message")
+			formattedResponse = inReplyTo(request, stillPending) + formattedResponse
+			
+			// Record estimated spend for the model that answered
+			answeredBy := m.activeModel()
+			if m.activeFallback != nil {
+				answeredBy = m.activeFallback.Model
+			}
+			if cost, priced := m.config.EstimateCost(answeredBy, m.costInputTokens, EstimateTokens(response.Response)); priced {
+				m.cost.Add(cost)
+			}
+			m.costInputTokens = 0
+			
+			// Note which model actually answered when a fallback was used
+			if m.activeFallback != nil {
+				formattedResponse += fmt.Sprintf("\n\n_Answered by %s (fallback)_", m.activeFallback)
+				m.activeFallback = nil
+			}
+			
 			// Add formatted response to chat
-			m.chat.AddMessage(AssistantMessage, formattedResponse, "assistant")
+			m.chat.AddMessage(AssistantMessage, "", "assistant")
+			m.showResponse(formattedResponse)
+			m.chat.setLastMessageTrace(response.ToolSteps())
+			m.chat.setLastMessageSources(response.RetrievedSources())
+			m.finishRegenerate()
+			if changes := response.FileChanges(); len(changes) > 0 {
+				m.offerChanges(changes)
+			}
 			m.messageCount = m.chat.GetMessageCount()
 			
 			// Note: Provider and model info from responses should NOT override user settings
 			// Only explicit user commands should change these values
 			
 			// Update token usage
-			m.tokenUsage = EstimateConversationTokens(m.chat.GetMessages())
-			m.tokenLimit = GetModelTokenLimit(m.currentModel)
+			m.tokenUsage = m.chat.TokenEstimate()
+			m.tokenLimit = GetModelTokenLimit(m.activeModel())
 			m.updateHeaderState()
 			
-			// Update status bar with conversation type
+			// Record latency for /stats
+			latency := m.latency.Complete(EstimateTokens(response.Response))
+			
+			// Cue the answer, noting the conversation type
+			received := "Response received"
 			if response.ConversationType != "" {
-				m.statusBar = fmt.Sprintf("Response received (%s)", response.ConversationType)
-			} else {
-				m.statusBar = "Response received"
+				received = fmt.Sprintf("Response received (%s)", response.ConversationType)
+			}
+			if latency > 0 {
+				received += fmt.Sprintf(" in %s", formatLatency(latency))
 			}
-			m.isProcessing = false // Clear processing state
+			if stillPending > 0 {
+				received += fmt.Sprintf(" - %d more pending", stillPending)
+			}
+			cueCmd = m.cue(CueMessageReceived, received)
+			
+			// Warn when spend approaches or passes the budget
+			if level, reason := m.cost.CheckBudget(m.config.Budget, 0); level != BudgetOK {
+				m.statusBar = reason
+				category := StatusCategoryInfo
+				if level == BudgetExceeded {
+					category = StatusCategoryError
+				}
+				m.statusMessages.AddMessage(category, reason, nil)
+			}
+			// Parallel sends keep processing until the last answer
+			m.isProcessing = stillPending > 0
+			if !m.isProcessing {
+				m.activity.Stop()
+			}
+			m.autosaveConversation()
+		} else {
+			m.recordDeadLetter("conversation:lobby", "response", fmt.Sprintf("unparseable: %v", err), msg.Response)
 		}
-		return m, nil
+		return m, cueCmd
 		
 	case phoenix.ConversationThinkingMsg:
 		m.statusBar = "Assistant is thinking..."
-		return m, nil
+		cmd := m.activity.Start()
+		m.activity.SetQueuePosition(msg.QueuePosition)
+		if msg.Stage != "" {
+			m.activity.SetStage(StatusCategoryEngine, msg.Stage)
+		}
+		return m, cmd
+		
+	case phoenix.ConversationQueuedMsg:
+		cmd := m.activity.Start()
+		m.activity.SetQueuePosition(msg.Position)
+		if msg.Position > 0 {
+			m.statusBar = fmt.Sprintf("Server busy - queued at position %d", msg.Position)
+		}
+		return m, cmd
+		
+	case phoenix.ProviderErrorMsg:
+		m.resolveRequest(msg.RequestID, "conversation:lobby", "error", "message")
+		m.telemetry.Error("Provider", errors.New(msg.Message))
+		return m.handleProviderError(msg)
+		
+	case ActivityTickMsg:
+		var cmd tea.Cmd
+		m.activity, cmd = m.activity.Update(msg)
+		return m, cmd
 		
 	case phoenix.ConversationContextUpdatedMsg:
-		// Parse the context update to check if model was set
-		var context struct {
-			Context struct {
-				PreferredModel    string `json:"preferred_model"`
-				PreferredProvider string `json:"preferred_provider"`
-			} `json:"context"`
-		}
-		if err := json.Unmarshal(msg.Context, &context); err == nil {
-			// Note: Context updates should not override user-selected model/provider
-			// Only show that the server has acknowledged the preference
-			if context.Context.PreferredModel != "" {
-				m.statusBar = fmt.Sprintf("Server acknowledged model preference: %s", context.Context.PreferredModel)
-			} else {
-				m.statusBar = "Context updated"
-			}
+		// Note: Context updates should not override user-selected model/provider
+		// Only show that the server has acknowledged the preference
+		if model := msg.Update.Context.PreferredModel; model != "" {
+			m.statusBar = fmt.Sprintf("Server acknowledged model preference: %s", model)
 		} else {
 			m.statusBar = "Context updated"
 		}
 		return m, nil
 		
 	case phoenix.ConversationResetMsg:
-		// Clear chat history on reset
-		m.chat = NewChat()
-		chatHeight := m.height - 1 - 3 // status bar and header
-		m.chat.SetSize(m.width-2, chatHeight)
-		m.messageCount = 0
-		m.tokenUsage = 0
-		m.updateHeaderState()
+		// Keep the cleared conversation for /undo-clear
+		m.recycleChat()
+		var session struct {
+			ConversationID string `json:"conversation_id"`
+		}
+		if json.Unmarshal(msg.SessionInfo, &session) == nil && session.ConversationID != "" {
+			m.conversationID = session.ConversationID
+		}
+		m.resetChat()
 		m.statusBar = "Conversation reset"
 		return m, nil
 		
+	case phoenix.ConversationResumedMsg:
+		m.conversationID = msg.ConversationID
+		m.updateHeaderState()
+		m.statusBar = fmt.Sprintf("Resumed conversation %s", msg.ConversationID)
+		return m, nil
+		
+	case LoadOlderHistoryMsg:
+		return m, m.loadOlderHistory()
+		
+	case MessagePinnedMsg:
+		m.autosaveConversation()
+		if msg.Pinned {
+			m.statusBar = "Pinned message"
+		} else {
+			m.statusBar = "Unpinned message"
+		}
+		return m, nil
+		
+	case ConversationActionMsg:
+		return m, m.applyConversationAction(msg)
+		
+	case phoenix.ConversationArchivedMsg:
+		verb := "archived"
+		if !msg.Archived {
+			verb = "restored"
+		}
+		m.statusMessages.AddMessage(StatusCategoryInfo, fmt.Sprintf("Server %s conversation %s", verb, msg.ConversationID), nil)
+		return m, nil
+		
+	case phoenix.ConversationDeletedMsg:
+		m.statusMessages.AddMessage(StatusCategoryInfo, fmt.Sprintf("Server deleted conversation %s", msg.ConversationID), nil)
+		return m, nil
+		
+	case phoenix.PayloadDiagnosticMsg:
+		m.handlePayloadDiagnostic(msg)
+		return m, nil
+		
+	case phoenix.UnroutedEventMsg:
+		m.handleUnroutedEvent(msg)
+		return m, nil
+		
 	case phoenix.ConversationHistoryMsg:
+		m.resolveRequest(msg.History.RequestID, "conversation:lobby", "history", "get_history")
 		// Clear system message
 		m.systemMessage = ""
 		
+		m.historyCursor = msg.History.NextCursor
+		
+		// An older page is stitched above what is already shown
+		if msg.History.Before != "" {
+			m.chat.SetOlderHistory(m.historyCursor != "")
+			added := m.chat.PrependMessages(historyMessages(msg.History.Messages))
+			m.messageCount = m.chat.GetMessageCount()
+			m.tokenUsage = m.chat.TokenEstimate()
+			m.updateHeaderState()
+			m.statusBar = fmt.Sprintf("Loaded %d older messages", added)
+			return m, nil
+		}
+		
 		// Clear existing messages first
 		m.chat.ClearMessages()
+		m.chat.SetOlderHistory(m.historyCursor != "")
 		
 		// Process history messages
-		if messages, ok := msg.Messages.([]any); ok && len(messages) > 0 {
+		if messages := msg.History.Messages; len(messages) > 0 {
 			m.statusBar = fmt.Sprintf("Loading %d messages from history...", len(messages))
 			
 			// Add each historical message
-			for _, msgData := range messages {
-				if msgMap, ok := msgData.(map[string]any); ok {
-					// Extract message fields
-					content, _ := msgMap["content"].(string)
-					role, _ := msgMap["role"].(string)
-					
-					// Map role to message type
-					var msgType MessageType
-					switch role {
-					case "user":
-						msgType = UserMessage
-					case "assistant":
-						msgType = AssistantMessage
-					case "system":
-						msgType = SystemMessage
-					default:
-						msgType = SystemMessage
-					}
-					
-					// Add message to chat
-					m.chat.AddMessage(msgType, content, role)
-				}
+			for _, message := range historyMessages(messages) {
+				m.chat.restoreMessage(message)
 			}
 			
 			// Update message count and token usage
 			m.messageCount = m.chat.GetMessageCount()
-			m.tokenUsage = EstimateConversationTokens(m.chat.GetMessages())
+			m.tokenUsage = m.chat.TokenEstimate()
 			m.chatHeader.SetMessageCount(m.messageCount)
 			m.chatHeader.SetTokenUsage(m.tokenUsage, m.tokenLimit)
 			
@@ -496,25 +1042,156 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	// Phoenix streaming messages
 	case phoenix.StreamStartMsg:
 		m.statusBar = "Receiving response..."
-		// TODO: Could add a streaming indicator to chat
+		m.resolveRequest(msg.RequestID, "conversation:lobby", "stream:start", "message")
+		// Stream into a new assistant message
+		m.streamID = msg.ID
+		m.streamBuffer = ""
+		m.chat.AddMessage(AssistantMessage, "", "assistant")
 		return m, nil
 		
 	case phoenix.StreamDataMsg:
-		// TODO: Implement streaming support in chat
-		// For now, we'll wait for the complete response
+		if msg.ID == m.streamID {
+			m.streamBuffer += msg.Data
+			m.showResponse(m.streamBuffer)
+		}
 		return m, nil
 		
 	case phoenix.StreamEndMsg:
-		m.statusBar = "Response complete"
+		complete := "Response complete"
+		var cueCmd tea.Cmd
+		if msg.ID == m.streamID {
+			if latency := m.latency.Complete(EstimateTokens(m.streamBuffer)); latency > 0 {
+				complete = fmt.Sprintf("Response complete in %s", formatLatency(latency))
+			}
+			cueCmd = m.cue(CueMessageReceived, complete)
+			m.streamID = ""
+			m.finishRegenerate()
+			m.isProcessing = m.requests.Outstanding("message") > 0
+			if !m.isProcessing {
+				m.activity.Stop()
+			}
+			m.autosaveConversation()
+			m.messageCount = m.chat.GetMessageCount()
+			m.tokenUsage = m.chat.TokenEstimate()
+			m.updateHeaderState()
+		}
+		return m, cueCmd
+		
+	case ModelsListedMsg:
+		m.handleModelsListed(msg)
+		return m, nil
+		
+	case AskSelectionMsg:
+		return m, m.askAboutSelection(msg.Prompt)
+
+	case CommandBlockedMsg:
+		m.refuseBlockedCommand(msg.Command)
+		return m, nil
+
+	case ProviderHealthTickMsg:
+		return m, m.pollProviderHealth()
+
+	case phoenix.CommandQueueMsg:
+		m.handleCommandQueue(msg)
+		return m, nil
+
+	case phoenix.TransferProgressMsg:
+		m.handleTransferProgress(msg)
+		return m, nil
+
+	case AttachmentUploadedMsg:
+		m.handleAttachmentUploaded(msg)
+		return m, nil
+
+	case ProviderStatusMsg:
+		m.handleProviderStatus(msg)
+		return m, nil
+
+	case FileSaveResultMsg:
+		m.handleFileSaveResult(msg)
+		return m, nil
+		
+	case FileListingMsg:
+		m.handleFileListing(msg)
+		return m, nil
+		
+	case FileContentMsg:
+		return m.showFile(msg.File, msg.Data, msg.Err), nil
+
+	case IdleLockTickMsg:
+		return m, m.checkIdle()
+
+	case UnlockSubmitMsg:
+		return m, m.handleUnlockSubmit(msg)
+
+	case UnlockResultMsg:
+		m.handleUnlockResult(msg)
+		return m, nil
+
+	case ProviderSwitchResultMsg:
+		m.handleProviderSwitchResult(msg)
+		return m, nil
+		
+	case phoenix.OllamaModelsMsg:
+		if len(msg.Models) == 0 {
+			m.chat.AddMessage(SystemMessage, "No local Ollama models installed (run: ollama pull llama3)", "system")
+		} else {
+			m.chat.AddMessage(SystemMessage, fmt.Sprintf("Local Ollama models:\n  %s\n\nUse /model <name> to select one", strings.Join(msg.Models, "\n  ")), "system")
+		}
+		return m, nil
+		
+	case phoenix.RequestSentMsg:
+		m.requests.Track(msg)
+		return m, m.requestTimeout(msg)
+		
+	case RequestTimeoutMsg:
+		return m.handleRequestTimeout(msg)
+		
+	case telemetryConsentMsg:
+		m.modal.ShowChoice("Anonymous telemetry", telemetryConsentText, func() tea.Msg {
+			return ExecuteCommandMsg{Command: "telemetry_on"}
+		}, func() tea.Msg {
+			return ExecuteCommandMsg{Command: "telemetry_off"}
+		})
+		return m, nil
+		
+	case TelemetryUploadedMsg:
+		if msg.Err != nil {
+			m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Telemetry upload failed: %v", msg.Err), nil)
+		} else {
+			m.statusMessages.AddMessage(StatusCategoryInfo, "Telemetry uploaded", nil)
+		}
 		return m, nil
 		
 	// Phoenix error handling
 	case phoenix.ErrorMsg:
 		m.err = msg.Err
-		m.isProcessing = false // Clear processing state on error
+		if phoenix.IsCredentialsRevoked(msg.Err) {
+			return m, m.credentialsRevoked("", msg.Err.Error())
+		}
+		if msg.Component == "Admin Client" && m.adminPane.IsVisible() {
+			m.adminPane.SetError(msg.Err.Error())
+		}
+		m.telemetry.Error(msg.Component, msg.Err)
+		// A failed side request (status, API keys) leaves a pending message alone
+		var request pendingRequest
+		correlated := false
+		if msg.RequestID != "" {
+			request, correlated = m.requests.Resolve(msg.RequestID, "")
+		}
+		if !correlated || request.Event == "message" || m.requests.Outstanding("message") == 0 {
+			// Only errors during a request count as a failed response
+			if m.isProcessing {
+				m.latency.Fail()
+			}
+			m.isProcessing = false // Clear processing state on error
+			m.activity.Stop()
+			m.streamID = ""
+		}
 		// Use error handler to prevent spam
-		if display, message := m.errorHandler.HandleError(msg.Err, msg.Component); display {
-			m.statusBar = message
+		var cueCmd tea.Cmd
+		if display, message := m.reportError(msg.Err, msg.Component); display {
+			cueCmd = m.cue(CueError, message)
 			m.statusMessages.AddMessage(StatusCategoryError, message, nil)
 			
 			// Also show API key channel errors in chat for debugging
@@ -532,7 +1209,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.chat.AddMessage(SystemMessage, "You can retry this operation by pressing Ctrl+R", "system")
 			}
 		}
-		return m, nil
+		return m, cueCmd
 		
 	// Planning channel messages
 	case phoenix.PlanningChannelJoinedMsg:
@@ -540,67 +1217,46 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 		
 	case phoenix.PlanningStartedMsg:
-		// Parse planning started data
-		var data map[string]any
-		if err := json.Unmarshal(msg.Data, &data); err == nil {
-			if sessionID, ok := data["session_id"].(string); ok {
-				m.chat.AddMessage(SystemMessage, fmt.Sprintf("Planning session started (ID: %s)", sessionID), "planning")
-			}
+		m.resolveRequest(msg.Started.RequestID, "planning:lobby", "planning_started", "start_planning")
+		if sessionID := msg.Started.SessionID; sessionID != "" {
+			m.chat.AddMessage(SystemMessage, fmt.Sprintf("Planning session started (ID: %s)", sessionID), "planning")
 		}
 		m.statusMessages.AddMessage(StatusCategoryInfo, "Planning started", nil)
 		return m, nil
 		
 	case phoenix.PlanningStepMsg:
-		// Parse planning step data
-		var data map[string]any
-		if err := json.Unmarshal(msg.Data, &data); err == nil {
-			stepID := data["step_id"]
-			stepType := data["type"]
-			description := data["description"]
-			
-			stepMsg := fmt.Sprintf("Planning Step: %s\nType: %s\nDescription: %s", stepID, stepType, description)
-			
-			// Add any additional details
-			if details, ok := data["details"].(map[string]any); ok {
-				stepMsg += "\nDetails:"
-				for k, v := range details {
-					stepMsg += fmt.Sprintf("\n  - %s: %v", k, v)
-				}
+		step := msg.Step
+		stepMsg := fmt.Sprintf("Planning Step: %s\nType: %s\nDescription: %s", step.StepID, step.Type, step.Description)
+		
+		// Add any additional details
+		if len(step.Details) > 0 {
+			stepMsg += "\nDetails:"
+			for k, v := range step.Details {
+				stepMsg += fmt.Sprintf("\n  - %s: %v", k, v)
 			}
-			
-			m.chat.AddMessage(SystemMessage, stepMsg, "planning")
 		}
+		
+		m.chat.AddMessage(SystemMessage, stepMsg, "planning")
 		return m, nil
 		
 	case phoenix.PlanningCompletedMsg:
-		// Parse planning completed data
-		var data map[string]any
-		if err := json.Unmarshal(msg.Data, &data); err == nil {
-			summary := data["summary"]
-			if steps, ok := data["steps"].([]any); ok {
-				completedMsg := fmt.Sprintf("Planning completed!\nSummary: %s\n\nSteps (%d):", summary, len(steps))
-				for i, step := range steps {
-					if stepMap, ok := step.(map[string]any); ok {
-						completedMsg += fmt.Sprintf("\n%d. %s", i+1, stepMap["description"])
-					}
-				}
-				m.chat.AddMessage(SystemMessage, completedMsg, "planning")
-			}
+		completed := msg.Completed
+		completedMsg := fmt.Sprintf("Planning completed!\nSummary: %s\n\nSteps (%d):", completed.Summary, len(completed.Steps))
+		for i, step := range completed.Steps {
+			completedMsg += fmt.Sprintf("\n%d. %s", i+1, step.Description)
 		}
+		m.chat.AddMessage(SystemMessage, completedMsg, "planning")
 		m.statusMessages.AddMessage(StatusCategoryInfo, "Planning completed", nil)
-		return m, nil
+		return m, m.cue(CuePlanDone, fmt.Sprintf("Planning completed: %d steps", len(completed.Steps)))
 		
 	case phoenix.PlanningErrorMsg:
-		// Parse planning error data
-		var data map[string]any
-		if err := json.Unmarshal(msg.Data, &data); err == nil {
-			errorMsg := fmt.Sprintf("Planning error: %s", data["message"])
-			if details, ok := data["details"].(string); ok && details != "" {
-				errorMsg += fmt.Sprintf("\nDetails: %s", details)
-			}
-			m.chat.AddMessage(ErrorMessage, errorMsg, "planning")
-			m.statusMessages.AddMessage(StatusCategoryError, "Planning failed", nil)
+		m.resolveRequest(msg.Error.RequestID, "planning:lobby", "planning_error", "start_planning")
+		errorMsg := fmt.Sprintf("Planning error: %s", msg.Error.Message)
+		if details := msg.Error.Details; details != nil && details != "" {
+			errorMsg += fmt.Sprintf("\nDetails: %v", details)
 		}
+		m.chat.AddMessage(ErrorMessage, errorMsg, "planning")
+		m.statusMessages.AddMessage(StatusCategoryError, "Planning failed", nil)
 		return m, nil
 		
 	case phoenix.PlanningCancelledMsg:
@@ -628,8 +1284,6 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.authenticated {
 			m.statusBar = "Joining status channel..."
 			if statusClient, ok := m.statusClient.(*phoenix.StatusClient); ok {
-				statusClient.SetSocket(m.socket)
-				statusClient.SetProgram(m.ProgramHolder())
 				// Join status channel with current conversation ID
 				return m, statusClient.JoinStatusChannel(m.conversationID)
 			}
@@ -641,8 +1295,6 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.authenticated && m.userID != "" {
 			m.statusBar = "Joining API key channel..."
 			if apiKeyClient, ok := m.apiKeyClient.(*phoenix.ApiKeyClient); ok {
-				apiKeyClient.SetSocket(m.socket)
-				apiKeyClient.SetProgram(m.ProgramHolder())
 				apiKeyClient.SetUserID(m.userID)
 				return m, apiKeyClient.JoinApiKeyChannel()
 			}
@@ -654,8 +1306,6 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.authenticated {
 			m.statusBar = "Joining planning channel..."
 			if planningClient, ok := m.planningClient.(*phoenix.PlanningClient); ok {
-				planningClient.SetSocket(m.socket)
-				planningClient.SetProgram(m.ProgramHolder())
 				return m, planningClient.JoinPlanningChannel()
 			}
 		}
@@ -670,8 +1320,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Now connect to user socket with JWT token only
 		client := m.phoenixClient.(*phoenix.Client)
 		config := phoenix.Config{
-			URL:      m.phoenixURL,
-			IsAuth:   false,
+			URL:         m.phoenixURL,
+			IsAuth:      false,
+			Network:     m.networkOptions(),
+			Fingerprint: m.pinnedFingerprint(m.phoenixURL),
 		}
 		// Always use JWT token for user socket authentication
 		if m.jwtToken != "" {
@@ -728,6 +1380,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		
 	case phoenix.LoginSuccessMsg:
 		m.authenticated = true
+		m.reauthRequired = false
 		m.username = msg.User.Username
 		m.userID = msg.User.ID // Store user ID for api_keys channel
 		m.jwtToken = msg.Token // Store the JWT token
@@ -741,6 +1394,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.chat.AddMessage(SystemMessage, fmt.Sprintf("Successfully logged in as %s", msg.User.Username), "system")
 		}
 		
+		m.applyGrants(msg.User)
+		m.accountModal.Hide()
 		m.updateHeaderState()
 		// Now switch to the authenticated socket
 		return m, func() tea.Msg { return SwitchToUserSocketMsg{} }
@@ -748,11 +1403,83 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case phoenix.LoginErrorMsg:
 		m.statusBar = fmt.Sprintf("Login failed: %s", msg.Message)
 		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Login failed: %s - %s", msg.Message, msg.Details), nil)
+		if m.accountModal.IsVisible() {
+			m.accountModal.SetError(msg.Message)
+		}
+		m.saveKeyAfterLogin = false
+		// The saved key was revoked, not refused while typing a password. Other
+		// refusals keep the key, since it may still be good
+		if msg.APIKey && msg.Revoked && m.apiKey != "" && !m.accountModal.IsVisible() {
+			return m, m.credentialsRevoked("api_key", msg.Message)
+		}
+		return m, nil
+		
+	case phoenix.CredentialsRevokedMsg:
+		return m, m.credentialsRevoked(msg.Credential, msg.Message)
+		
+	case LoginSubmitMsg:
+		authClient, ok := m.authClient.(*phoenix.AuthClient)
+		if !ok {
+			m.accountModal.SetError("Not connected to the auth server")
+			return m, nil
+		}
+		m.saveKeyAfterLogin = msg.SaveAPIKey
+		m.statusBar = "Logging in..."
+		return m, authClient.Login(msg.Username, msg.Password)
+		
+	case RegisterSubmitMsg:
+		authClient, ok := m.authClient.(*phoenix.AuthClient)
+		if !ok {
+			m.accountModal.SetError("Not connected to the auth server")
+			return m, nil
+		}
+		m.statusBar = "Creating account..."
+		return m, authClient.Register(msg.Username, msg.Email, msg.Password)
+		
+	case phoenix.RegisterSuccessMsg:
+		m.chat.AddMessage(SystemMessage, fmt.Sprintf("Account %s created", msg.User.Username), "system")
+		// A new account is signed in straight away
+		return m.Update(phoenix.LoginSuccessMsg{User: msg.User, Token: msg.Token})
+		
+	case phoenix.RegisterErrorMsg:
+		m.statusBar = fmt.Sprintf("Registration failed: %s", msg.Message)
+		if m.accountModal.IsVisible() {
+			m.accountModal.SetError(msg.Message)
+		} else {
+			m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Registration failed: %s", msg.Message), nil)
+		}
+		return m, nil
+		
+	case PasswordChangeSubmitMsg:
+		authClient, ok := m.authClient.(*phoenix.AuthClient)
+		if !ok {
+			m.accountModal.SetError("Not connected to the auth server")
+			return m, nil
+		}
+		m.statusBar = "Changing password..."
+		return m, authClient.ChangePassword(msg.CurrentPassword, msg.NewPassword)
+		
+	case phoenix.PasswordChangedMsg:
+		m.accountModal.Hide()
+		m.statusBar = "Password changed"
+		m.chat.AddMessage(SystemMessage, msg.Message, "system")
+		return m, nil
+		
+	case phoenix.PasswordChangeErrorMsg:
+		m.statusBar = fmt.Sprintf("Password change failed: %s", msg.Message)
+		if m.accountModal.IsVisible() {
+			m.accountModal.SetError(msg.Message)
+		} else {
+			m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Password change failed: %s", msg.Message), nil)
+		}
 		return m, nil
 		
 	case phoenix.LogoutSuccessMsg:
+		// Answers cached for this account mustn't reach the next one
+		m.handleClearCache(ClearCacheMsg{Quiet: true})
 		m.authenticated = false
 		m.username = ""
+		m.applyGrants(phoenix.AuthUser{})
 		m.statusBar = "Logged out"
 		m.chat.AddMessage(SystemMessage, msg.Message, "system")
 		// Leave conversation channel when logged out
@@ -769,6 +1496,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if msg.Authenticated && msg.User != nil {
 			m.username = msg.User.Username
 			m.userID = msg.User.ID // Store user ID for api_keys channel
+			m.applyGrants(*msg.User)
+			if roles := m.describeRoles(); roles != "" {
+				m.chat.AddMessage(SystemMessage, roles, "system")
+			}
 			// If authenticated via API key, we should switch to user socket
 			if m.apiKey != "" {
 				m.statusBar = fmt.Sprintf("Authenticated as %s via API key - Switching to authenticated connection...", msg.User.Username)
@@ -783,57 +1514,153 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		} else {
 			m.username = ""
 			m.userID = ""
-			m.statusBar = "Not authenticated - Please login with /login <username> <password>"
-			m.chat.AddMessage(SystemMessage, "Authentication status: Not logged in\nPlease use /login <username> <password> to authenticate", "system")
+			m.statusBar = "Not authenticated - Please log in with /login"
+			m.chat.AddMessage(SystemMessage, "Authentication status: Not logged in\nPlease use /login to authenticate", "system")
 		}
 		return m, nil
 		
 	case phoenix.APIKeyGeneratedMsg:
+		m.requests.Resolve(msg.RequestID, "generate_api_key")
 		m.statusBar = "API key generated"
+		if m.saveKeyAfterLogin && msg.APIKey.Key != "" {
+			// Requested from the login form: save it instead of showing it
+			m.saveKeyAfterLogin = false
+			m.apiKey = msg.APIKey.Key
+			m.config.APIKey = msg.APIKey.Key
+			if err := SaveConfig(m.config); err != nil {
+				m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Failed to save config: %v", err), nil)
+				return m, nil
+			}
+			m.statusBar = "API key saved"
+			m.chat.AddMessage(SystemMessage, fmt.Sprintf("API key saved to ~/.rubber_duck/config.json (expires %s)", msg.APIKey.ExpiresAt.Format("2006-01-02")), "system")
+			return m, nil
+		}
 		// Debug: Check if we have the key
 		if msg.APIKey.Key == "" {
+			m.apiKeyModal.Hide()
 			m.chat.AddMessage(ErrorMessage, "Error: API key was generated but key value is empty", "system")
 			m.statusMessages.AddMessage(StatusCategoryError, "API key generation succeeded but key value is missing", nil)
 		} else {
-			// Show the key and warning
-			keyMsg := fmt.Sprintf("API Key Generated!\n\nKey: %s\n\n%s\n\nExpires: %s", 
-				msg.APIKey.Key, 
-				msg.Warning,
-				msg.APIKey.ExpiresAt.Format("2006-01-02 15:04:05"))
-			m.chat.AddMessage(SystemMessage, keyMsg, "system")
+			// The key itself stays out of the chat, which is saved and exported
+			m.apiKeyModal.ShowKey(msg.APIKey, msg.Warning)
+			m.chat.AddMessage(SystemMessage, fmt.Sprintf("API key %s generated (expires %s)",
+				msg.APIKey.ID,
+				msg.APIKey.ExpiresAt.Format("2006-01-02 15:04:05")), "system")
+		}
+		return m, nil
+		
+	case APIKeyGenerateSubmitMsg:
+		return m, m.generateAPIKey(msg)
+		
+	case apiKeyCopiedMsg:
+		m.apiKeyModal, _ = m.apiKeyModal.Update(msg)
+		if msg.Err == nil {
+			m.statusBar = "API key copied to the clipboard"
 		}
 		return m, nil
 		
 	case phoenix.APIKeyListMsg:
+		m.requests.Resolve(msg.RequestID, "list_api_keys")
 		m.statusBar = fmt.Sprintf("Found %d API keys", msg.Count)
-		// Format and display the keys
-		keyList := "Your API Keys:\n\n"
-		for _, key := range msg.APIKeys {
-			status := "Valid"
-			if !key.Valid {
-				status = "Revoked"
-			}
-			keyList += fmt.Sprintf("ID: %s\nStatus: %s\nCreated: %s\nExpires: %s\n\n",
-				key.ID,
-				status,
-				key.CreatedAt.Format("2006-01-02 15:04:05"),
-				key.ExpiresAt.Format("2006-01-02 15:04:05"))
+		m.apiKeyList.SetKeys(msg.APIKeys)
+		return m, nil
+		
+	case phoenix.APIKeyUsageMsg:
+		m.requests.Resolve(msg.RequestID, "get_api_key_usage")
+		m.apiKeyList.SetUsage(msg.Usage)
+		return m, nil
+		
+	case ProviderCredentialsSubmitMsg:
+		authClient, ok := m.authClient.(*phoenix.AuthClient)
+		if !ok || !m.authenticated {
+			m.accountModal.SetError("Log in to store provider credentials")
+			return m, nil
+		}
+		m.statusBar = fmt.Sprintf("Saving %s credentials...", msg.Provider)
+		return m, authClient.SetProviderCredentials(msg.Provider, msg.Fields)
+		
+	case phoenix.ProviderCredentialsMsg:
+		m.statusBar = fmt.Sprintf("%d providers with credentials", len(msg.Credentials))
+		m.chat.AddMessage(SystemMessage, formatProviderCredentials(msg.Credentials), "system")
+		return m, nil
+		
+	case phoenix.ProviderCredentialsSavedMsg:
+		if m.accountModal.IsVisible() && m.accountModal.Form() == CredentialsForm {
+			m.accountModal.Hide()
 		}
-		m.chat.AddMessage(SystemMessage, keyList, "system")
+		m.statusBar = fmt.Sprintf("%s credentials saved", msg.Provider)
+		m.chat.AddMessage(SystemMessage, fmt.Sprintf("%s\nUse /providers test %s to check them", msg.Message, msg.Provider), "system")
+		return m, nil
+		
+	case phoenix.ProviderCredentialsRemovedMsg:
+		m.statusBar = fmt.Sprintf("%s credentials removed", msg.Provider)
+		m.chat.AddMessage(SystemMessage, msg.Message, "system")
+		return m, nil
+		
+	case phoenix.ProviderCredentialsTestedMsg:
+		if msg.OK {
+			m.statusBar = fmt.Sprintf("%s reachable (%s)", msg.Provider, formatLatency(msg.Latency))
+			m.chat.AddMessage(SystemMessage, fmt.Sprintf("✓ %s: %s in %s", msg.Provider, msg.Message, formatLatency(msg.Latency)), "system")
+		} else {
+			m.statusBar = fmt.Sprintf("%s unreachable", msg.Provider)
+			m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("%s credentials test failed: %s", msg.Provider, msg.Message), nil)
+		}
+		return m, nil
+		
+	case phoenix.ProviderCredentialsErrorMsg:
+		m.statusBar = fmt.Sprintf("Credentials error: %s", msg.Message)
+		if msg.Operation == "set" && m.accountModal.IsVisible() && m.accountModal.Form() == CredentialsForm {
+			m.accountModal.SetError(msg.Message)
+		} else {
+			m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Provider credentials %s failed: %s", msg.Operation, msg.Message), nil)
+		}
+		return m, nil
+		
+	case phoenix.SessionListMsg:
+		m.statusBar = fmt.Sprintf("%d active sessions", len(msg.Sessions))
+		m.chat.AddMessage(SystemMessage, formatSessionList(msg.Sessions), "system")
+		return m, nil
+		
+	case phoenix.SessionRevokedMsg:
+		m.statusBar = "Session revoked"
+		m.chat.AddMessage(SystemMessage, fmt.Sprintf("%s (%s)", msg.Message, msg.SessionID), "system")
+		return m, nil
+		
+	case phoenix.SessionErrorMsg:
+		m.statusBar = fmt.Sprintf("Session error: %s", msg.Message)
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Session %s failed: %s", msg.Operation, msg.Message), nil)
 		return m, nil
 		
 	case phoenix.APIKeyRevokedMsg:
+		m.requests.Resolve(msg.RequestID, "revoke_api_key")
 		m.statusBar = "API key revoked"
 		m.chat.AddMessage(SystemMessage, msg.Message, "system")
+		if m.apiKeyList.IsVisible() {
+			return m, m.listAPIKeys()
+		}
 		return m, nil
 		
 	case phoenix.APIKeyErrorMsg:
+		m.requests.Resolve(msg.RequestID, apiKeyEvents[msg.Operation])
 		m.statusBar = fmt.Sprintf("API key error: %s", msg.Message)
+		if msg.Operation == "generate" && m.apiKeyModal.Waiting() {
+			m.apiKeyModal.SetError(msg.Message)
+		}
+		switch msg.Operation {
+		case "usage":
+			// Older servers don't count requests; the list still works
+			m.apiKeyList.SetUsageError(msg.Message)
+			return m, nil
+		case "list":
+			m.apiKeyList.SetError(msg.Message)
+		}
 		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("API key %s failed: %s - %s", msg.Operation, msg.Message, msg.Details), nil)
 		m.chat.AddMessage(ErrorMessage, fmt.Sprintf("API Key Error (%s): %s\nDetails: %s", msg.Operation, msg.Message, msg.Details), "system")
 		return m, nil
 		
 	case phoenix.TokenRefreshedMsg:
+		// Roles can change between refreshes
+		m.applyGrants(msg.User)
 		m.statusBar = "Token refreshed"
 		m.chat.AddMessage(SystemMessage, "Authentication token refreshed successfully", "system")
 		return m, nil
@@ -851,95 +1678,154 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case phoenix.StatusChannelJoinedMsg:
 		m.statusBar = fmt.Sprintf("Status channel joined for conversation %s", msg.ConversationID)
 		
-		// Store category metadata with colors from config
+		// Store category metadata; applyCategoryColors fills in the colors
 		if msg.CategoryDescriptions != nil {
 			for category, description := range msg.CategoryDescriptions {
-				// Get color from config, fallback to white
-				color := m.config.GetCategoryColor(category, "white")
 				m.categoryMetadata[category] = CategoryInfo{
 					Name:        category,
 					Description: description,
-					Color:       color,
 				}
 			}
 		}
 		
-		// Subscribe to all available categories
+		// Subscribe to the available categories this conversation didn't
+		// unsubscribe from
+		m.statusAvailable = msg.AvailableCategories
 		if len(msg.AvailableCategories) > 0 {
 			// Also ensure metadata exists for categories without descriptions
 			for _, category := range msg.AvailableCategories {
 				if _, exists := m.categoryMetadata[category]; !exists {
-					color := m.config.GetCategoryColor(category, "white")
 					m.categoryMetadata[category] = CategoryInfo{
 						Name:        category,
 						Description: "", // No description provided
-						Color:       color,
 					}
 				}
 			}
+			m.applyCategoryColors()
 			
 			if statusClient, ok := m.statusClient.(*phoenix.StatusClient); ok {
 				m.statusBar = "Subscribing to status categories..."
-				return m, statusClient.SubscribeCategories(msg.AvailableCategories)
+				m.statusSubscribing = true
+				return m, statusClient.SubscribeCategories(m.subscribedCategories(msg.AvailableCategories))
 			}
 		} else {
 			// Fallback to default categories if none provided
 			if statusClient, ok := m.statusClient.(*phoenix.StatusClient); ok {
-				categories := []string{"engine", "tool", "workflow", "progress", "error", "info"}
+				categories := defaultStatusCategories
 				
 				// Create default metadata for fallback categories
 				for _, category := range categories {
 					if _, exists := m.categoryMetadata[category]; !exists {
-						color := m.config.GetCategoryColor(category, "white")
 						m.categoryMetadata[category] = CategoryInfo{
 							Name:        category,
 							Description: "", // No description for defaults
-							Color:       color,
 						}
 					}
 				}
+				m.applyCategoryColors()
 				
-				return m, statusClient.SubscribeCategories(categories)
+				m.statusSubscribing = true
+				return m, statusClient.SubscribeCategories(m.subscribedCategories(categories))
 			}
 		}
 		
 		// Update status messages component with category colors
-		colors := make(map[string]string)
-		for category, info := range m.categoryMetadata {
-			colors[category] = info.Color
-		}
-		m.statusMessages.SetCategoryColors(colors)
+		m.applyCategoryColors()
 		
 		return m, nil
 		
 	case phoenix.StatusCategoriesSubscribedMsg:
 		m.statusBar = fmt.Sprintf("Subscribed to status categories: %v", msg.Categories)
+		// Later changes come from the subscriptions manager
+		if !m.statusSubscribing {
+			return m, nil
+		}
+		m.statusSubscribing = false
 		
 		// Now that all channels are ready, request conversation history
 		m.systemMessage = "Loading conversation history..."
 		if client, ok := m.phoenixClient.(*phoenix.Client); ok {
-			return m, client.GetConversationHistory(100)
+			return m, client.GetConversationHistory(historyPageSize)
 		}
 		
 		return m, nil
 		
 	case phoenix.StatusUpdateMsg:
-		// Add status message to the status messages component
-		m.statusMessages.AddMessage(
-			StatusCategory(msg.Category),
-			msg.Text,
-			msg.Metadata,
-		)
-		return m, nil
+		// Engine and tool updates describe the current processing stage
+		category := StatusCategory(msg.Category)
+		if m.activity.IsActive() && (category == StatusCategoryEngine || category == StatusCategoryTool) {
+			m.activity.SetStage(category, msg.Text)
+			// Receiving stage updates means we're no longer queued
+			m.activity.SetQueuePosition(0)
+		}
+		
+		// Updates sent before an unsubscribe reached the server are dropped
+		if m.statusMuted(msg.Category) {
+			return m, nil
+		}
+		
+		// Add status message to the status messages component, routed by priority
+		priority := resolveStatusPriority(category, msg.Priority)
+		m.statusMessages.AddUpdate(category, priority, msg.Text, msg.Metadata)
+		return m, m.notifyStatus(priority, msg.Text)
 		
 	case phoenix.StatusSubscriptionsMsg:
-		m.statusBar = fmt.Sprintf("Status subscriptions - Active: %v, Available: %v", msg.Subscribed, msg.Available)
+		m.handleStatusSubscriptions(msg)
+		return m, nil
+		
+	case phoenix.AdminChannelJoinedMsg:
+		if adminClient, ok := m.adminClient.(*phoenix.AdminClient); ok && m.adminPane.IsVisible() {
+			return m, adminClient.GetStats()
+		}
+		return m, nil
+		
+	case phoenix.AdminStatsMsg:
+		m.adminPane.SetStats(msg.Stats, clock.Now())
+		return m, nil
+		
+	case phoenix.AdminActionMsg:
+		m.adminPane.SetNotice(msg.Message)
+		m.statusMessages.AddMessage(StatusCategoryInfo, fmt.Sprintf("Admin %s: %s", msg.Action, msg.Message), nil)
+		// Show the effect of the action straight away
+		return m, m.runAdminRequest(AdminRequestMsg{Operation: "refresh"})
+		
+	case phoenix.AdminErrorMsg:
+		m.adminPane.SetError(fmt.Sprintf("%s failed: %s", msg.Operation, msg.Message))
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Admin %s failed: %s", msg.Operation, msg.Message), nil)
 		return m, nil
 		
+	case AdminRequestMsg:
+		return m, m.runAdminRequest(msg)
+		
+	case phoenix.IndexChannelJoinedMsg:
+		return m, m.sendPendingIndex()
+		
+	case phoenix.IndexProgressMsg:
+		m.handleIndexProgress(msg.Progress)
+		return m, nil
+		
+	case phoenix.IndexStatusMsg:
+		m.handleIndexStatus(msg.Status)
+		return m, nil
+		
+	case phoenix.IndexErrorMsg:
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Index %s failed: %s", msg.Operation, msg.Message), nil)
+		m.statusBar = "Indexing failed: " + msg.Message
+		return m, nil
+		
+	case adminRefreshMsg:
+		return m, m.refreshAdminStats()
+		
 	// API key channel joined
 	case phoenix.ApiKeyChannelJoinedMsg:
 		m.statusBar = "API key channel joined - Ready for API key management"
 		m.chat.AddMessage(SystemMessage, "API key management channel joined successfully", "system")
+		if m.saveKeyAfterLogin {
+			if apiKeyClient, ok := m.apiKeyClient.(*phoenix.ApiKeyClient); ok {
+				m.statusBar = "Generating an API key to save..."
+				return m, apiKeyClient.GenerateAPIKey(nil)
+			}
+		}
 		return m, nil
 	}
 	
@@ -966,14 +1852,21 @@ func (m Model) nextPane() Pane {
 			return FileTreePane
 		} else if m.showEditor {
 			return EditorPane
+		} else if m.showOutline {
+			return OutlinePane
 		}
 		return ChatPane
 	case FileTreePane:
 		if m.showEditor {
 			return EditorPane
+		} else if m.showOutline {
+			return OutlinePane
 		}
 		return ChatPane
 	case EditorPane:
+		if m.showOutline {
+			return OutlinePane
+		}
 		return ChatPane
 	default:
 		return ChatPane
@@ -1000,62 +1893,25 @@ func (m Model) getKeyHints() string {
 func (m Model) buildHelpContent() string {
 	help := "RubberDuck TUI Help\n\n"
 	
-	help += "KEYBOARD SHORTCUTS:\n"
-	help += "━━━━━━━━━━━━━━━━━━━━━\n"
-	help += "Ctrl+P    - Command palette (all commands)\n"
-	help += "Ctrl+H    - This help\n"
-	help += "Ctrl+R    - Reconnect to server\n"
-	help += "Tab       - Switch panes\n"
-	help += "Ctrl+C/Ctrl+Q - Quit\n\n"
-	
-	help += "NAVIGATION:\n"
-	help += "━━━━━━━━━━━━━━━━━━━━━\n"
-	help += "Ctrl+/    - Focus chat\n"
-	help += "Ctrl+F    - Toggle file tree\n"
-	help += "Ctrl+E    - Toggle editor\n\n"
-	
-	help += "COPY/PASTE:\n"
-	help += "━━━━━━━━━━━━━━━━━━━━━\n"
-	help += "Ctrl+A    - Copy all messages to clipboard\n"
-	help += "Ctrl+L    - Copy last assistant message\n"
-	help += "Ctrl+T    - Show mouse mode status\n"
-	help += "\nText selection is enabled by default.\n"
-	help += "For mouse scrolling, start with: ./rubber_duck_tui --mouse\n\n"
-	
-	help += "CHAT:\n"
-	help += "━━━━━━━━━━━━━━━━━━━━━\n"
-	help += "Enter     - Send message\n"
-	help += "Ctrl+Enter - New line\n"
-	help += "↑/↓       - Scroll history\n\n"
-	
-	help += "SLASH COMMANDS:\n"
-	help += "━━━━━━━━━━━━━━━━━━━━━\n"
-	help += "/help     - Show this help\n"
-	help += "/model    - Set AI model (e.g., /model gpt4 [provider])\n"
-	help += "/provider - Set provider (e.g., /provider azure)\n"
-	help += "/plan     - Start AI planning session (e.g., /plan create REST API)\n"
-	help += "/clear    - New conversation\n"
-	help += "/tree     - Toggle file tree\n"
-	help += "/editor   - Toggle editor\n"
-	help += "/commands - Show command palette\n"
-	help += "/login    - Login to server\n"
-	help += "/logout   - Logout from server\n"
-	help += "/apikey   - API key management (generate/list/revoke/save)\n"
-	help += "/status   - Check auth status\n"
-	help += "/quit     - Exit application\n\n"
+	// Shortcuts and slash commands come from their registries
+	help += formatKeyBindings()
+	
+	help += "SLASH COMMANDS:\n" + helpRule + "\n"
+	help += formatSlashCommandsFor(m.permissions)
+	help += "\n/help <command> shows a command's examples and related commands\n\n"
 	
 	help += "MODELS (via Ctrl+P):\n"
-	help += "━━━━━━━━━━━━━━━━━━━━━\n"
+	help += helpRule + "\n"
 	help += "• Default (system default)\n"
 	help += "• GPT-4, GPT-3.5 Turbo\n"
 	help += "• Claude 3 Opus, Sonnet\n"
 	help += "• Llama 2, Mistral, CodeLlama\n\n"
 	
 	help += "Current Model: "
-	if m.currentModel != "" {
-		help += m.currentModel
-		if m.currentProvider != "" {
-			help += fmt.Sprintf(" (%s)", m.currentProvider)
+	if m.activeModel() != "" {
+		help += m.activeModel()
+		if m.activeProvider() != "" {
+			help += fmt.Sprintf(" (%s)", m.activeProvider())
 		}
 	} else {
 		help += "default"
@@ -1063,27 +1919,47 @@ func (m Model) buildHelpContent() string {
 	help += "\n\n"
 	
 	help += "AUTHENTICATION:\n"
-	help += "━━━━━━━━━━━━━━━━━━━━━\n"
+	help += helpRule + "\n"
 	if m.authenticated {
 		help += fmt.Sprintf("Logged in as: %s\n", m.username)
 	} else {
 		help += "Not authenticated\n"
-		help += "Use /login <username> <password> to login\n"
+		help += "Use /login to log in\n"
 	}
 	
 	return help
 }
 
+// resetChat clears the chat for a fresh conversation
+func (m *Model) resetChat() {
+	incognito := m.chat.Incognito()
+	m.chat.ClearMessages()
+	m.chat = NewChat()
+	// Clearing doesn't end incognito
+	m.chat.SetIncognito(incognito)
+	m.applyChatSettings()
+	chatHeight := m.height - 1 - 3 // status bar and header
+	m.chat.SetSize(m.width-2, chatHeight)
+	m.activity.Stop()
+	m.cost.ResetConversation()
+	m.localConversationID = ""
+	m.historyCursor = ""
+	// A fresh conversation starts on the default model settings
+	m.conversationSettings = conversationSettings{}
+	m.settingsFor = m.savedConversationID()
+	m.messageCount = 0
+	m.tokenUsage = 0
+	m.updateHeaderState()
+}
+
 // updateHeaderState updates the chat header with current state
 func (m *Model) updateHeaderState() {
 	m.chatHeader.SetConnectionStatus(m.connected, m.authenticated)
 	// Use actual provider if available, otherwise fall back to guessed provider
-	provider := m.currentProvider
-	if provider == "" {
-		provider = m.getProviderForModel(m.currentModel)
-	}
-	m.chatHeader.SetModel(m.currentModel, provider)
+	m.chatHeader.SetModel(m.activeModel(), m.displayedProvider())
+	m.chatHeader.SetProviderHealth(m.providerWarning())
 	m.chatHeader.SetConversationID(m.conversationID)
+	m.chatHeader.SetTags(m.currentTags())
 	m.chatHeader.SetMessageCount(m.messageCount)
 	m.chatHeader.SetTokenUsage(m.tokenUsage, m.tokenLimit)
 }
@@ -1125,8 +2001,8 @@ func (m Model) buildStatusBar() string {
 	}
 	
 	// Add model info
-	if m.currentModel != "" {
-		status += " | Model: " + m.currentModel
+	if m.activeModel() != "" {
+		status += " | Model: " + m.activeModel()
 	} else {
 		status += " | Model: default"
 	}
@@ -1139,23 +2015,325 @@ func (m Model) buildStatusBar() string {
 
 // handleCommand processes command execution
 func (m Model) handleCommand(msg ExecuteCommandMsg) (Model, tea.Cmd) {
+	// Only the command name is counted, never its arguments
+	m.telemetry.Feature("command:" + msg.Command)
+	if permission, ok := commandPermissions[msg.Command]; ok && !m.requirePermission(permission) {
+		return m, nil
+	}
+	if commandBlocked(m.config.TUI.BlockedCommands, msg.Command) {
+		m.refuseBlockedCommand(msg.Command)
+		return m, nil
+	}
+	if m.needsConfirmation(msg) {
+		m.confirmCommand(msg)
+		return m, nil
+	}
+	if m.demo != nil && demoBlocks(msg.Command) {
+		m.refuseInDemo()
+		return m, nil
+	}
+	if msg.Command == "start_planning" {
+		m.tutorial.planned = m.tutorial.active
+		if m.demo != nil {
+			return m, m.sendToMock(m.demo, "/plan "+msg.Args["query"])
+		}
+		if m.tutorial.practicing() {
+			return m, m.sendToMock(m.tutorial.mock, "/plan "+msg.Args["query"])
+		}
+	}
 	switch msg.Command {
 	case "help":
-		m.modal = Modal{
-			modalType: HelpModal,
-			title:     "Help",
-			content:   m.buildHelpContent(),
-			visible:   true,
+		if code, ok := msg.Args["errors"]; ok {
+			page, ok := GetErrorHelp(code)
+			if !ok {
+				m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("No error with code %s - /help errors lists them", code), nil)
+				return m, nil
+			}
+			m.modal.Show(HelpModal, strings.TrimSpace("Help: errors "+strings.ToUpper(code)), page)
+			return m, nil
+		}
+		if name := msg.Args["command"]; name != "" {
+			page, ok := GetCommandHelp(name)
+			if !ok {
+				m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("No help for unknown command /%s", strings.TrimPrefix(name, "/")), nil)
+				return m, nil
+			}
+			m.modal.Show(HelpModal, "Help: /"+strings.TrimPrefix(name, "/"), page)
+			return m, nil
 		}
+		m.modal.Show(HelpModal, "Help", m.buildHelpContent())
 	case "toggle_tree":
 		m.showFileTree = !m.showFileTree
 		m.updateComponentSizes()
+		m.refocusIfHidden()
 	case "toggle_editor":
 		m.showEditor = !m.showEditor
 		m.updateComponentSizes()
+		m.refocusIfHidden()
+	case "toggle_outline":
+		m.showOutline = !m.showOutline
+		m.refreshOutline()
+		m.updateComponentSizes()
+		if m.showOutline {
+			m.focusPane(OutlinePane)
+			m.statusBar = fmt.Sprintf("Outline: %d symbols", len(m.outline.Symbols()))
+		} else {
+			m.refocusIfHidden()
+			m.statusBar = "Outline hidden"
+		}
 	case "focus_chat":
-		m.activePane = ChatPane
-		m.chat.Focus()
+		m.focusPane(ChatPane)
+	case "focus_tree":
+		m.focusPane(FileTreePane)
+	case "focus_editor":
+		m.focusPane(EditorPane)
+	case "focus_outline":
+		m.focusPane(OutlinePane)
+	case "retry":
+		return m.retryTimedOut()
+	case "telemetry_on", "telemetry_off":
+		m.setTelemetry(msg.Command == "telemetry_on")
+	case "telemetry_status":
+		m.chat.AddMessage(SystemMessage, m.telemetryStatus(), "system")
+	case "telemetry_upload":
+		if !m.telemetry.Enabled() || m.config.Telemetry.UploadURL == "" {
+			m.chat.AddMessage(SystemMessage, "Uploading needs telemetry on and telemetry.upload_url set in config", "system")
+			return m, nil
+		}
+		m.statusBar = "Uploading telemetry..."
+		return m, m.uploadTelemetry()
+	case "fallback_retry":
+		if m.pendingFallback == nil {
+			m.chat.AddMessage(SystemMessage, fmt.Sprintf("Nothing to retry\n\nFallback chain: %s\nAutomatic fallback: %t", formatFallbackChain(m.config.FallbackChain), m.config.AutoFallback), "system")
+			return m, nil
+		}
+		return m.sendWithFallback(*m.pendingFallback)
+	case "fallback_auto_on", "fallback_auto_off":
+		m.config.AutoFallback = msg.Command == "fallback_auto_on"
+		m.statusBar = fmt.Sprintf("Automatic fallback: %t", m.config.AutoFallback)
+		if err := SaveConfig(m.config); err != nil {
+			m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Failed to save config: %v", err), nil)
+		}
+	case "fallback_set":
+		chain, err := parseFallbackChain(msg.Args["chain"])
+		if err != nil {
+			m.statusMessages.AddMessage(StatusCategoryError, err.Error(), nil)
+			return m, nil
+		}
+		m.config.FallbackChain = chain
+		m.chat.AddMessage(SystemMessage, fmt.Sprintf("Fallback chain: %s", formatFallbackChain(chain)), "system")
+		if err := SaveConfig(m.config); err != nil {
+			m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Failed to save config: %v", err), nil)
+		}
+	case "budget_status":
+		m.chat.AddMessage(SystemMessage, m.formatBudget(), "system")
+	case "budget_set":
+		amount, err := strconv.ParseFloat(strings.TrimPrefix(msg.Args["amount"], "$"), 64)
+		if err != nil || amount < 0 {
+			m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Invalid budget amount: %s", msg.Args["amount"]), nil)
+			return m, nil
+		}
+		if msg.Args["scope"] == "day" {
+			m.config.Budget.PerDay = amount
+		} else {
+			m.config.Budget.PerConversation = amount
+		}
+		m.chat.AddMessage(SystemMessage, m.formatBudget(), "system")
+		if err := SaveConfig(m.config); err != nil {
+			m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Failed to save config: %v", err), nil)
+		}
+	case "file_source":
+		source := msg.Args["source"]
+		m.config.TUI.FileSource = source
+		cmd := m.applyFileSource()
+		active := m.fileTree.FileSystem().Name()
+		m.statusBar = fmt.Sprintf("File source: %s", active)
+		if active != source {
+			m.chat.AddMessage(SystemMessage, fmt.Sprintf("File source set to %s (using %s until the server is available)", source, active), "system")
+		} else {
+			m.chat.AddMessage(SystemMessage, fmt.Sprintf("File source set to %s", source), "system")
+		}
+		if err := SaveConfig(m.config); err != nil {
+			m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Failed to save config: %v", err), nil)
+		}
+		return m, cmd
+	case "file_reload", "file_keep", "file_diff":
+		if m.currentFile == "" {
+			m.statusMessages.AddMessage(StatusCategoryError, "No file open in the editor", nil)
+			return m, nil
+		}
+		switch msg.Command {
+		case "file_reload":
+			return m.reloadCurrentFile()
+		case "file_keep":
+			return m.keepBuffer()
+		default:
+			return m.diffBuffer()
+		}
+	case "saved_list":
+		conversations, err := ListSavedConversations()
+		if err != nil {
+			m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Failed to list saved conversations: %v", err), nil)
+			return m, nil
+		}
+		if tag := msg.Args["tag"]; tag != "" {
+			conversations = filterByTag(conversations, tag)
+			if len(conversations) == 0 {
+				m.chat.AddMessage(SystemMessage, fmt.Sprintf("No saved conversations tagged %s", renderTag(tag)), "system")
+				return m, nil
+			}
+		}
+		m.chat.AddMessage(SystemMessage, formatSavedConversations(conversations), "system")
+	case "tag_add", "tag_remove":
+		tag, err := normalizeTag(msg.Args["tag"])
+		if err != nil {
+			m.statusMessages.AddMessage(StatusCategoryError, err.Error(), nil)
+			return m, nil
+		}
+		if msg.Command == "tag_add" {
+			return m, m.updateTags(msg.Args["ref"], func(tags []string) []string { return withTag(tags, tag) })
+		}
+		return m, m.updateTags(msg.Args["ref"], func(tags []string) []string { return withoutTag(tags, tag) })
+	case "conversation_archive", "conversation_delete":
+		m.confirmConversationAction(strings.TrimPrefix(msg.Command, "conversation_"), msg.Args["ref"])
+	case "conversation_unarchive":
+		return m, m.applyConversationAction(ConversationActionMsg{Action: "unarchive", ID: msg.Args["ref"]})
+	case "saved_archived":
+		conversations, err := ListArchivedConversations()
+		if err != nil {
+			m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Failed to list archived conversations: %v", err), nil)
+			return m, nil
+		}
+		m.chat.AddMessage(SystemMessage, formatArchivedConversations(conversations), "system")
+	case "goto_date":
+		day, err := parseGotoDate(msg.Args["date"], clock.Now())
+		if err != nil {
+			m.statusMessages.AddMessage(StatusCategoryError, err.Error(), nil)
+			return m, nil
+		}
+		if !m.chat.GotoDate(day) {
+			m.statusBar = fmt.Sprintf("No messages on or after %s", day.Format("2006-01-02"))
+			return m, nil
+		}
+		m.statusBar = fmt.Sprintf("Jumped to %s", day.Format("Mon, Jan 2 2006"))
+	case "pin_last":
+		if m.chat.PinLastAssistantMessage() < 0 {
+			m.statusBar = "No assistant answer to pin"
+			return m, nil
+		}
+		m.autosaveConversation()
+		m.statusBar = "Pinned the last answer"
+	case "pins_toggle":
+		m.chat.TogglePinnedPanel()
+		m.statusBar = "Toggled pinned panel"
+	case "tag_list":
+		conversations, err := ListSavedConversations()
+		if err != nil {
+			m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Failed to list saved conversations: %v", err), nil)
+			return m, nil
+		}
+		m.chat.AddMessage(SystemMessage, formatTagList(conversations), "system")
+	case "undo_clear":
+		return m, m.undoClear()
+	case "send_unredacted":
+		return m, m.sendUnredacted()
+	case "apply_changes":
+		m.previewChanges()
+	case "rollback_changes":
+		return m, m.rollbackChanges()
+	case "display":
+		m.chat.AddMessage(SystemMessage, m.describeDisplay(), "system")
+	case "model_switcher":
+		return m, m.openModelSwitcher()
+	case "display_set":
+		m.setDisplay(msg.Args["setting"], msg.Args["value"], msg.Args["role"])
+	case "display_relative":
+		m.setDisplay("timestamps", timestampsRelative, "")
+	case "display_compact":
+		compact := "on"
+		if m.config.TUI.CompactChat {
+			compact = "off"
+		}
+		m.setDisplay("compact", compact, "")
+	case "dead_letters":
+		m.deadLetters.Show()
+	case "dead_letters_copy":
+		m.copyDeadLetter()
+	case "react_view":
+		if m.reactView.executionID == "" {
+			m.statusMessages.AddMessage(StatusCategoryError, "No ReAct execution to show", nil)
+			break
+		}
+		m.reactView.Show()
+	case "react_pause":
+		return m, m.controlReact("pause")
+	case "react_abort":
+		return m, m.controlReact("abort")
+	case "react_export":
+		m.exportReactTrace()
+	case "export_patch":
+		m.exportPatch(msg.Args["file"])
+	case "create_pull_request":
+		return m, m.createPullRequest(msg.Args["title"])
+	case "save_file":
+		return m, m.saveFile()
+	case "format_file":
+		m.formatFile()
+	case "regenerate":
+		return m, m.regenerate()
+	case "toggle_diff":
+		if !m.chat.ToggleLastDiff() {
+			m.statusMessages.AddMessage(StatusCategoryError, "No regenerated answer to compare", nil)
+		}
+	case "toggle_trace":
+		if !m.chat.ToggleLastTrace() {
+			m.statusMessages.AddMessage(StatusCategoryError, "No answer with an execution trace", nil)
+		}
+	case "show_more":
+		if !m.chat.ExpandLastTruncated() {
+			m.statusMessages.AddMessage(StatusCategoryError, "No truncated response to expand", nil)
+		}
+	case "tutorial":
+		m.startTutorial()
+	case "tutorial_next":
+		m.skipTutorialStep()
+	case "tutorial_quit":
+		if m.tutorial.active {
+			m.tutorial = Tutorial{}
+			m.statusBar = "Tutorial ended"
+		}
+	case "saved_open":
+		conv, err := LoadSavedConversation(msg.Args["ref"])
+		if err != nil {
+			m.statusMessages.AddMessage(StatusCategoryError, err.Error(), nil)
+			return m, nil
+		}
+		m.chat.ClearMessages()
+		m.chat.AddMessage(SystemMessage, fmt.Sprintf("Viewing saved conversation: %s (%s)", conv.Title, conv.UpdatedAt.Format("2006-01-02 15:04")), "system")
+		for _, saved := range conv.Messages {
+			m.chat.restoreMessage(saved)
+		}
+		m.messageCount = m.chat.GetMessageCount()
+		m.statusBar = fmt.Sprintf("Opened saved conversation %s", conv.Title)
+	case "stats":
+		stats := m.latency.FormatStats()
+		if traffic := m.formatTrafficStats(); traffic != "" {
+			stats += "\n\n" + traffic
+		}
+		m.chat.AddMessage(SystemMessage, stats, "system")
+		m.statusBar = "Response statistics"
+	case "search":
+		m.searchPane.SetSize(m.width, m.height)
+		m.searchPane.Show(msg.Args["query"])
+		if msg.Args["query"] != "" {
+			return m, m.searchPane.Search()
+		}
+	case "compose":
+		// Move the current draft into the compose modal
+		m.composeModal.SetSize(m.width, m.height)
+		m.composeModal.Show(m.chat.GetInputValue())
+		m.chat.SetInputValue("")
+		m.statusBar = "Composing message"
 	case "new_conversation":
 		if !m.authenticated {
 			m.statusMessages.AddMessage(StatusCategoryError, "You must be authenticated to start a new conversation", nil)
@@ -1173,25 +2351,12 @@ func (m Model) handleCommand(msg ExecuteCommandMsg) (Model, tea.Cmd) {
 	
 	// Model command
 	case "set_model":
-		if args := msg.Args; args != nil {
-			model := args["model"]
-			if model != "" {
-				// If model is "default" or "none", clear the model
-				if model == "default" || model == "none" {
-					m.currentModel = ""
-					m.currentProvider = ""
-					m.updateHeaderState()
-					m.statusBar = "Model cleared - using server default"
-					m.chat.AddMessage(SystemMessage, "Model preference cleared - using server default", "system")
-				} else {
-					m.currentModel = model
-					// Don't change provider if already set
-					m.tokenLimit = GetModelTokenLimit(model)
-					m.updateHeaderState()
-					m.statusBar = fmt.Sprintf("Model set to: %s", model)
-					m.chat.AddMessage(SystemMessage, fmt.Sprintf("Model set to: %s", model), "system")
-				}
-			}
+		if model := msg.Args["model"]; model != "" {
+			m.setModel(model, "", msg.Args["scope"] == "global")
+		}
+	case "set_temperature":
+		if temperature := msg.Args["temperature"]; temperature != "" {
+			m.setTemperature(temperature, msg.Args["scope"] == "global")
 		}
 		
 	// Authentication commands
@@ -1206,6 +2371,44 @@ func (m Model) handleCommand(msg ExecuteCommandMsg) (Model, tea.Cmd) {
 		}
 		m.statusBar = "Login failed: invalid arguments"
 		
+	case "login_form":
+		m.accountModal.Show(LoginForm, msg.Args["username"])
+		
+	case "ask_selection":
+		if !m.showEditor {
+			m.statusBar = "Open the editor to ask about its code"
+			return m, nil
+		}
+		m.focusPane(EditorPane)
+		m.openAskPanel()
+		
+	case "index_build":
+		return m, m.runIndexCommand("build", msg.Args["force"] == "true")
+		
+	case "index_status":
+		return m, m.runIndexCommand("status", false)
+		
+	case "admin_panel":
+		if !m.authenticated {
+			m.statusMessages.AddMessage(StatusCategoryError, "You must be authenticated to open the admin panel", nil)
+			return m, nil
+		}
+		return m, m.openAdminPane()
+		
+	case "register_form":
+		if m.authenticated {
+			m.chat.AddMessage(SystemMessage, fmt.Sprintf("Already logged in as %s. Use /logout first to create another account", m.username), "system")
+			return m, nil
+		}
+		m.accountModal.Show(RegisterForm, msg.Args["username"])
+		
+	case "password_form":
+		if !m.authenticated {
+			m.statusMessages.AddMessage(StatusCategoryError, "You must be authenticated to change your password", nil)
+			return m, nil
+		}
+		m.accountModal.Show(PasswordForm, "")
+		
 	case "auth_logout":
 		m.statusBar = "Logging out..."
 		if authClient, ok := m.authClient.(*phoenix.AuthClient); ok {
@@ -1224,27 +2427,30 @@ func (m Model) handleCommand(msg ExecuteCommandMsg) (Model, tea.Cmd) {
 			m.statusMessages.AddMessage(StatusCategoryError, "You must be authenticated to manage API keys", nil)
 			return m, nil
 		}
-		m.statusBar = "Generating API key..."
-		m.chat.AddMessage(SystemMessage, "Requesting API key generation...", "system")
-		if apiKeyClient, ok := m.apiKeyClient.(*phoenix.ApiKeyClient); ok {
-			return m, apiKeyClient.GenerateAPIKey(nil)
+		if !m.requireCapability(phoenix.CapabilityAPIKeys) {
+			return m, nil
 		}
+		m.openAPIKeyModal()
+		return m, nil
 		
 	case "auth_apikey_list":
 		if !m.authenticated {
 			m.statusMessages.AddMessage(StatusCategoryError, "You must be authenticated to manage API keys", nil)
 			return m, nil
 		}
-		m.statusBar = "Listing API keys..."
-		if apiKeyClient, ok := m.apiKeyClient.(*phoenix.ApiKeyClient); ok {
-			return m, apiKeyClient.ListAPIKeys()
+		if !m.requireCapability(phoenix.CapabilityAPIKeys) {
+			return m, nil
 		}
+		return m, m.listAPIKeys()
 		
 	case "auth_apikey_revoke":
 		if !m.authenticated {
 			m.statusMessages.AddMessage(StatusCategoryError, "You must be authenticated to manage API keys", nil)
 			return m, nil
 		}
+		if !m.requireCapability(phoenix.CapabilityAPIKeys) {
+			return m, nil
+		}
 		if args := msg.Args; args != nil {
 			keyID := args["id"]
 			m.statusBar = "Revoking API key..."
@@ -1254,6 +2460,46 @@ func (m Model) handleCommand(msg ExecuteCommandMsg) (Model, tea.Cmd) {
 		}
 		m.statusBar = "Revoke failed: missing key ID"
 		
+	case "provider_credentials_list", "provider_credentials_form", "provider_credentials_test", "provider_credentials_remove":
+		if !m.authenticated {
+			m.statusMessages.AddMessage(StatusCategoryError, "You must be authenticated to manage provider credentials", nil)
+			return m, nil
+		}
+		authClient, ok := m.authClient.(*phoenix.AuthClient)
+		if !ok {
+			return m, nil
+		}
+		provider := msg.Args["provider"]
+		switch msg.Command {
+		case "provider_credentials_form":
+			m.accountModal.ShowCredentials(provider)
+		case "provider_credentials_test":
+			m.statusBar = fmt.Sprintf("Pinging %s...", provider)
+			return m, authClient.TestProviderCredentials(provider)
+		case "provider_credentials_remove":
+			m.statusBar = fmt.Sprintf("Removing %s credentials...", provider)
+			return m, authClient.RemoveProviderCredentials(provider)
+		default:
+			m.statusBar = "Listing provider credentials..."
+			return m, authClient.ListProviderCredentials()
+		}
+		
+	case "auth_sessions_list", "auth_sessions_revoke":
+		if !m.authenticated {
+			m.statusMessages.AddMessage(StatusCategoryError, "You must be authenticated to manage sessions", nil)
+			return m, nil
+		}
+		authClient, ok := m.authClient.(*phoenix.AuthClient)
+		if !ok {
+			return m, nil
+		}
+		if msg.Command == "auth_sessions_revoke" {
+			m.statusBar = "Revoking session..."
+			return m, authClient.RevokeSession(msg.Args["id"])
+		}
+		m.statusBar = "Listing sessions..."
+		return m, authClient.ListSessions()
+		
 	case "auth_apikey_save":
 		if args := msg.Args; args != nil {
 			apiKey := args["apikey"]
@@ -1280,10 +2526,21 @@ func (m Model) handleCommand(msg ExecuteCommandMsg) (Model, tea.Cmd) {
 		if args := msg.Args; args != nil {
 			provider := args["provider"]
 			if provider != "" {
+				previous := m.currentProvider
 				m.currentProvider = provider
 				m.updateHeaderState()
 				m.statusBar = fmt.Sprintf("Provider set to: %s", provider)
 				m.chat.AddMessage(SystemMessage, fmt.Sprintf("Provider set to: %s", provider), "system")
+				
+				// Direct Ollama mode bypasses the server entirely
+				if provider == localOllamaProvider {
+					if m.ollamaClient == nil {
+						m.ollamaClient = phoenix.NewOllamaClient(m.config.OllamaURL)
+					}
+					m.chat.AddMessage(SystemMessage, fmt.Sprintf("Using local Ollama at %s - messages are not sent to the server", m.ollamaClient.BaseURL()), "system")
+					return m, m.ollamaClient.ListModels()
+				}
+				return m, m.reconcileProvider(provider, previous)
 			}
 		}
 		
@@ -1292,12 +2549,7 @@ func (m Model) handleCommand(msg ExecuteCommandMsg) (Model, tea.Cmd) {
 			model := args["model"]
 			provider := args["provider"]
 			if model != "" && provider != "" {
-				m.currentModel = model
-				m.currentProvider = provider
-				m.tokenLimit = GetModelTokenLimit(model)
-				m.updateHeaderState()
-				m.statusBar = fmt.Sprintf("Model set to: %s (%s)", model, provider)
-				m.chat.AddMessage(SystemMessage, fmt.Sprintf("Model set to: %s\nProvider set to: %s", model, provider), "system")
+				m.setModel(model, provider, args["scope"] == "global")
 			}
 		}
 	
@@ -1308,6 +2560,9 @@ func (m Model) handleCommand(msg ExecuteCommandMsg) (Model, tea.Cmd) {
 			m.statusMessages.AddMessage(StatusCategoryError, "You must be authenticated to use planning", nil)
 			return m, nil
 		}
+		if !m.requireCapability(phoenix.CapabilityPlanning) {
+			return m, nil
+		}
 		
 		// Get the query from args
 		query := msg.Args["query"]
@@ -1321,12 +2576,18 @@ func (m Model) handleCommand(msg ExecuteCommandMsg) (Model, tea.Cmd) {
 		if planningClient, ok := m.planningClient.(*phoenix.PlanningClient); ok {
 			// Create context with current model/provider info
 			context := map[string]any{
-				"provider": m.currentProvider,
-				"model":    m.currentModel,
+				"provider": m.activeProvider(),
+				"model":    m.activeModel(),
 			}
 			return m, planningClient.StartPlanning(query, context)
 		}
 		return m, nil
+	case "cancel_planning":
+		if planningClient, ok := m.planningClient.(*phoenix.PlanningClient); ok {
+			m.statusBar = "Cancelling planning session..."
+			return m, planningClient.CancelPlanning()
+		}
+		return m, nil
 	
 	// Config commands
 	case "config_save":
@@ -1351,6 +2612,160 @@ func (m Model) handleCommand(msg ExecuteCommandMsg) (Model, tea.Cmd) {
 			m.chat.AddMessage(SystemMessage, message, "system")
 		}
 		
+	case "doctor":
+		m.runDoctor(false, "")
+	
+	case "doctor_save":
+		m.runDoctor(true, msg.Args["file"])
+	
+	case "problems":
+		m.openProblemsPane()
+	
+	case "queue":
+		m.chat.AddMessage(SystemMessage, m.formatCommandQueue(), "system")
+	
+	case "metrics":
+		m.chat.AddMessage(SystemMessage, m.metricsStatus(), "system")
+	
+	case "memory":
+		m.chat.AddMessage(SystemMessage, m.formatMemory(), "system")
+	
+	case "encrypt":
+		m.chat.AddMessage(SystemMessage, m.encryptionStatus(), "system")
+	
+	case "trust":
+		m.chat.AddMessage(SystemMessage, m.formatKnownServers(), "system")
+	
+	case "permissions":
+		m.chat.AddMessage(SystemMessage, m.formatPermissions(), "system")
+	
+	case "permissions_reset":
+		m.resetPermissions(msg.Args["action"])
+	
+	case "trust_accept":
+		return m, m.acceptServerChange()
+	
+	case "link_hints":
+		if count := m.chat.StartHints("link", nil); count == 0 {
+			m.statusBar = "No links in view"
+		} else {
+			m.statusBar = hintsStatus("link", count)
+		}
+	
+	case "path_hints":
+		m.startPathHints()
+	
+	case "prefix":
+		m.chat.AddMessage(SystemMessage, m.formatPromptAffixes(), "system")
+	
+	case "prefix_set":
+		m.setPromptAffix(msg.Args["which"], msg.Args["text"])
+	
+	case "prefix_edit":
+		m.editPromptAffix(msg.Args["which"])
+	
+	case "macro":
+		m.chat.AddMessage(SystemMessage, m.formatMacros(), "system")
+	
+	case "emoji":
+		m.openEmojiPicker(msg.Args["query"])
+	
+	case "status_colors":
+		m.openStatusLegend()
+	
+	case "subscriptions":
+		return m, m.openStatusSubscriptions()
+	
+	case "cues":
+		m.chat.AddMessage(SystemMessage, m.describeCues(), "system")
+	
+	case "cues_set":
+		m.setCues(msg.Args["event"], msg.Args["cues"])
+	
+	case "cues_test":
+		return m, m.testCue(msg.Args["event"])
+	
+	case "macro_save":
+		if !validRegister(msg.Args["register"]) {
+			m.statusMessages.AddMessage(StatusCategoryError, "Registers are a-z and 0-9", nil)
+			break
+		}
+		m.saveMacro(msg.Args["register"], msg.Args["name"])
+	
+	case "macro_load":
+		if !validRegister(msg.Args["register"]) {
+			m.statusMessages.AddMessage(StatusCategoryError, "Registers are a-z and 0-9", nil)
+			break
+		}
+		m.loadMacro(msg.Args["name"], msg.Args["register"])
+	
+	case "macro_play":
+		// A register plays directly; a name is loaded into @0 first
+		register := msg.Args["name"]
+		if !validRegister(register) || m.macros.registers[register] == nil {
+			if !m.loadMacro(register, "0") {
+				break
+			}
+			register = "0"
+		}
+		return m, m.playMacro(register, 0)
+	
+	case "macro_delete":
+		m.deleteMacro(msg.Args["name"])
+	
+	case "trust_forget":
+		m.forgetServer(msg.Args["host"])
+	
+	case "encrypt_migrate":
+		m.migrateEncryption()
+	
+	case "incognito":
+		on := !m.chat.Incognito()
+		switch msg.Args["state"] {
+		case "on":
+			on = true
+		case "off":
+			on = false
+		}
+		m.setIncognito(on)
+	
+	case "lock":
+		m.lockNow()
+	
+	case "lock_pin":
+		m.startPINSetup()
+	
+	case "lock_pin_off":
+		m.clearPIN()
+	
+	case "cache":
+		m.chat.AddMessage(SystemMessage, m.formatCacheStatus(), "system")
+	
+	case "cache_clear":
+		if event := msg.Args["event"]; event != "" {
+			return m, clearCache(event)
+		}
+		return m, clearCache()
+	
+	case "audit":
+		count, err := strconv.Atoi(msg.Args["count"])
+		if err != nil || count <= 0 {
+			count = auditEntriesShown
+		}
+		m.chat.AddMessage(SystemMessage, formatAuditLog(count), "system")
+	
+	case "queue_bump", "queue_cancel":
+		m.changeQueue(strings.TrimPrefix(msg.Command, "queue_"), msg.Args["position"])
+	
+	case "project_info":
+		m.chat.AddMessage(SystemMessage, m.projectSummary(), "system")
+	
+	case "project_open":
+		return m, m.openProject(msg.Args["path"])
+	
+	case "attach":
+		return m, m.attachFile(msg.Args["path"])
+	
 	case "config_load":
 		// Reload config from file
 		config, err := LoadConfig()
@@ -1360,6 +2775,8 @@ func (m Model) handleCommand(msg ExecuteCommandMsg) (Model, tea.Cmd) {
 		} else {
 			// Update model's config and current settings
 			m.config = config
+			m.loadResponseFilters()
+			m.applyChatSettings()
 			m.currentProvider = config.DefaultProvider
 			m.currentModel = config.DefaultModel
 			m.updateHeaderState()
@@ -1398,19 +2815,145 @@ func (m Model) handleCommand(msg ExecuteCommandMsg) (Model, tea.Cmd) {
 		m.statusBar = fmt.Sprintf("Timestamps %s", status)
 		m.chat.AddMessage(SystemMessage, fmt.Sprintf("Timestamps %s for status messages", status), "system")
 		
+	case "status_info":
+		if m.statusMessages.ToggleInfo() {
+			m.statusBar = "Showing every info update"
+		} else {
+			m.statusBar = "Collapsing runs of info updates"
+		}
+		
+	case "dismiss_alerts":
+		if m.statusMessages.DismissCritical() == 0 {
+			m.statusBar = "No critical updates pinned"
+		} else {
+			m.statusBar = "Critical updates dismissed"
+		}
+		
 	case "timestamps_status":
 		status := "enabled"
 		if !m.statusMessages.GetShowTimestamp() {
 			status = "disabled"
 		}
 		m.chat.AddMessage(SystemMessage, fmt.Sprintf("Timestamps are currently %s\n\nUsage: /timestamps <on|off|toggle>\n  on    - Show timestamps in status messages\n  off   - Hide timestamps in status messages\n  toggle - Toggle timestamp display", status), "system")
+	
+	// Input diagnostics commands
+	case "spellcheck_on":
+		checker, err := NewSpellChecker(m.config.TUI.SpellcheckDictionary)
+		if err != nil {
+			m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Spellcheck unavailable: %v", err), nil)
+			return m, nil
+		}
+		m.chat.SetSpellChecker(checker)
+		m.config.TUI.Spellcheck = true
+		m.statusBar = "Spellcheck enabled"
+		m.chat.AddMessage(SystemMessage, fmt.Sprintf("Spellcheck enabled using %s", checker.Path()), "system")
+		if err := SaveConfig(m.config); err != nil {
+			m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Failed to save config: %v", err), nil)
+		}
+		
+	case "spellcheck_off":
+		m.chat.SetSpellChecker(nil)
+		m.config.TUI.Spellcheck = false
+		m.statusBar = "Spellcheck disabled"
+		m.chat.AddMessage(SystemMessage, "Spellcheck disabled", "system")
+		if err := SaveConfig(m.config); err != nil {
+			m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Failed to save config: %v", err), nil)
+		}
+		
+	case "lint_on", "lint_off":
+		enabled := msg.Command == "lint_on"
+		m.chat.SetLintEnabled(enabled)
+		m.config.TUI.PromptLint = enabled
+		status := "disabled"
+		if enabled {
+			status = "enabled"
+		}
+		m.statusBar = fmt.Sprintf("Prompt linting %s", status)
+		m.chat.AddMessage(SystemMessage, fmt.Sprintf("Prompt linting %s", status), "system")
+		if err := SaveConfig(m.config); err != nil {
+			m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Failed to save config: %v", err), nil)
+		}
 	}
 	
 	return m, nil
 }
 
+// openFile loads a file from the file tree's file system into the editor.
+// Server files are read by the returned command
+func (m Model) openFile(file FileSelectedMsg) (Model, tea.Cmd) {
+	fs := m.fileTree.FileSystem()
+	if readsOverNetwork(fs) {
+		m.statusBar = fmt.Sprintf("Opening %s...", file.Path)
+		return m, func() tea.Msg {
+			data, err := fs.ReadFile(file.Path)
+			return FileContentMsg{File: file, Data: data, Err: err}
+		}
+	}
+	data, err := fs.ReadFile(file.Path)
+	return m.showFile(file, data, err), nil
+}
+
+// showFile puts a file's content in the editor, at the selected line
+func (m Model) showFile(file FileSelectedMsg, data []byte, err error) Model {
+	path := file.Path
+	if err != nil {
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Cannot open %s: %v", path, err), nil)
+		return m
+	}
+	if bytes.IndexByte(data, 0) >= 0 {
+		m.statusMessages.AddMessage(StatusCategoryError, fmt.Sprintf("Cannot open %s: binary file", path), nil)
+		return m
+	}
+	
+	m.currentFile = path
+	m.editorMark = 0
+	m.askPanel.Hide()
+	m.formatError = ""
+	m.editor.SetValue(string(data))
+	m.editorOriginal = string(data)
+	m.hunksApplied = false
+	m.currentFileStamp = statFile(path)
+	m.fileChangedOnDisk = false
+	m.showEditor = true
+	m.refreshOutline()
+	m.updateComponentSizes()
+	m.statusBar = fmt.Sprintf("Opened %s", path)
+	if file.Line > 0 {
+		m.moveEditorToLine(file.Line)
+		m.focusPane(EditorPane)
+		if file.EndLine > file.Line {
+			m.statusBar = fmt.Sprintf("%s:%d-%d", path, file.Line, file.EndLine)
+		}
+	}
+	return m
+}
+
+// refreshOutline re-parses the outline from the editor buffer
+func (m *Model) refreshOutline() {
+	if m.showOutline {
+		m.outline.SetContent(m.currentFile, m.editor.Value())
+	}
+}
+
+// moveEditorToLine places the editor cursor at the start of a 1-based line
+func (m *Model) moveEditorToLine(line int) {
+	target := min(line-1, m.editor.LineCount()-1)
+	for m.editor.Line() > target {
+		m.editor.CursorUp()
+	}
+	for m.editor.Line() < target {
+		m.editor.CursorDown()
+	}
+	m.editor.CursorStart()
+	m.statusBar = fmt.Sprintf("%s:%d", m.currentFile, line)
+}
+
 // handleReconnect attempts to reconnect with exponential backoff
 func (m *Model) handleReconnect() (Model, tea.Cmd) {
+	if m.demo != nil {
+		m.statusBar = "Demo mode - there is no server to reconnect to"
+		return *m, nil
+	}
 	now := time.Now()
 	const maxReconnectAttempts = 3
 	
@@ -1466,6 +3009,7 @@ func (m *Model) handleReconnect() (Model, tea.Cmd) {
 	
 	// Update reconnect tracking
 	m.reconnectAttempts++
+	m.metrics.Inc(metrics.ReconnectsTotal)
 	m.lastReconnectTime = now
 	
 	m.statusBar = fmt.Sprintf("Reconnecting... (attempt %d)", m.reconnectAttempts)
@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"fmt"
 	"strings"
 	
 	"github.com/charmbracelet/lipgloss"
@@ -12,17 +13,89 @@ func (m Model) View() string {
 		return "Loading..."
 	}
 	
+	// A locked screen hides everything
+	if m.lockScreen.IsVisible() {
+		return m.lockScreen.View()
+	}
+	
 	// Check if modal is visible
 	if m.modal.IsVisible() {
 		return m.renderWithModal()
 	}
 	
+	// Nothing touches the local machine until the prompt is answered
+	if m.permissionPrompt.IsVisible() {
+		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.permissionPrompt.View())
+	}
+	
+	// Compose modal takes over the whole screen
+	if m.composeModal.IsVisible() {
+		return m.composeModal.View()
+	}
+	
+	if m.accountModal.IsVisible() {
+		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.accountModal.View())
+	}
+	
+	if m.apiKeyModal.IsVisible() {
+		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.apiKeyModal.View())
+	}
+	
+	if m.apiKeyList.IsVisible() {
+		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.apiKeyList.View())
+	}
+	
+	// Search pane takes over the whole screen
+	if m.searchPane.IsVisible() {
+		return m.searchPane.View()
+	}
+	
+	if m.applyPreview.IsVisible() {
+		return m.applyPreview.View()
+	}
+	
+	if m.changesReview.IsVisible() {
+		return m.changesReview.View()
+	}
+	
+	if m.reactView.IsVisible() {
+		return m.reactView.View()
+	}
+	
+	if m.deadLetters.IsVisible() {
+		return m.deadLetters.View()
+	}
+	
+	if m.modelSwitcher.IsVisible() {
+		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.modelSwitcher.View())
+	}
+	
+	if m.statusSubscriptions.IsVisible() {
+		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.statusSubscriptions.View())
+	}
+	
+	if m.statusLegend.IsVisible() {
+		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.statusLegend.View())
+	}
+	
+	if m.emojiPicker.IsVisible() {
+		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.emojiPicker.View())
+	}
+	
+	if m.adminPane.IsVisible() {
+		return m.adminPane.View()
+	}
+	
+	if m.problemsPane.IsVisible() {
+		return m.problemsPane.View()
+	}
+	
 	// Check if command palette is visible
 	if m.commandPalette.IsVisible() {
 		return m.renderWithCommandPalette()
 	}
 	
-	return m.renderBase()
+	return m.overlayToast(m.renderBase())
 }
 
 // renderBase renders the base UI without overlays
@@ -48,6 +121,9 @@ func (m Model) renderBase() string {
 		if m.activePane == FileTreePane {
 			style = activeBorderStyle
 		}
+		if m.tutorial.highlights(FileTreePane) {
+			style = tutorialBorderStyle
+		}
 		fileTree := style.
 			Width(30).
 			Height(contentHeight).
@@ -60,6 +136,9 @@ func (m Model) renderBase() string {
 	if m.activePane == ChatPane {
 		chatStyle = activeBorderStyle
 	}
+	if m.tutorial.highlights(ChatPane) {
+		chatStyle = tutorialBorderStyle
+	}
 	
 	// Calculate chat width based on visible panels
 	chatWidth := m.width
@@ -69,6 +148,9 @@ func (m Model) renderBase() string {
 	if m.showEditor {
 		chatWidth -= 42 // 40 + 2 for borders
 	}
+	if m.showOutline {
+		chatWidth -= outlineWidth + 2
+	}
 	
 	// Build chat content with status messages at top, conversation at bottom
 	// Calculate heights for chat and status sections
@@ -103,6 +185,11 @@ func (m Model) renderBase() string {
 	// Status messages content (without status bar)
 	statusContent := m.statusMessages.View()
 	
+	// The tutorial's current step shows in place of the status messages
+	if m.tutorial.active {
+		statusContent = m.renderTutorialCard(chatWidth-4, statusHeight)
+	}
+	
 	// Apply borders to sections
 	statusSection := statusBorderStyle.Render(statusContent)
 	chatSection := chatBorderStyle.Render(m.chat.View())
@@ -144,13 +231,39 @@ func (m Model) renderBase() string {
 		if m.activePane == EditorPane {
 			style = activeBorderStyle
 		}
+		if m.tutorial.highlights(EditorPane) {
+			style = tutorialBorderStyle
+		}
+		editorContent := m.editor.View()
+		if m.fileChangedOnDisk {
+			editorContent = lipgloss.JoinVertical(lipgloss.Left, m.renderFileChangedBanner(40), editorContent)
+		}
+		if m.formatError != "" {
+			editorContent = lipgloss.JoinVertical(lipgloss.Left, m.renderFormatErrorBanner(40), editorContent)
+		}
+		if m.askPanel.IsVisible() {
+			editorContent = lipgloss.JoinVertical(lipgloss.Left, editorContent, m.askPanel.View())
+		}
 		editor := style.
 			Width(40).
 			Height(contentHeight).
-			Render(m.editor.View())
+			Render(editorContent)
 		components = append(components, editor)
 	}
 	
+	// Outline (if visible)
+	if m.showOutline {
+		style := borderStyle
+		if m.activePane == OutlinePane {
+			style = activeBorderStyle
+		}
+		outline := style.
+			Width(outlineWidth).
+			Height(contentHeight).
+			Render(m.outline.View())
+		components = append(components, outline)
+	}
+	
 	// Join components horizontally with top margin to ensure visibility
 	content := lipgloss.JoinHorizontal(lipgloss.Top, components...)
 	// Add top margin of 2 to push content down and make status bar visible
@@ -181,6 +294,18 @@ func (m Model) renderMiniStatusBar(width int) string {
 	
 	// Build status components
 	var components []string
+	if m.demo != nil {
+		components = append(components, m.renderDemoBanner())
+	}
+	if m.chat.Incognito() {
+		components = append(components, m.renderIncognitoBanner())
+	}
+	if m.macros.recording != "" {
+		components = append(components, m.renderMacroBanner())
+	}
+	if m.offline {
+		components = append(components, m.renderOfflineBanner())
+	}
 	components = append(components, connStatus)
 	
 	// Add authentication status
@@ -199,11 +324,11 @@ func (m Model) renderMiniStatusBar(width int) string {
 	}
 	
 	// Add provider status
-	if m.currentProvider != "" {
+	if m.activeProvider() != "" {
 		providerStatus := lipgloss.NewStyle().
 			Foreground(lipgloss.Color("46")).
 			Bold(true).
-			Render("● " + m.currentProvider)
+			Render("● " + m.activeProvider())
 		components = append(components, providerStatus)
 	} else {
 		providerStatus := lipgloss.NewStyle().
@@ -214,11 +339,11 @@ func (m Model) renderMiniStatusBar(width int) string {
 	}
 	
 	// Add model status
-	if m.currentModel != "" {
+	if m.activeModel() != "" {
 		modelStatus := lipgloss.NewStyle().
 			Foreground(lipgloss.Color("46")).
 			Bold(true).
-			Render("● " + m.currentModel)
+			Render("● " + m.activeModel())
 		components = append(components, modelStatus)
 	} else {
 		modelStatus := lipgloss.NewStyle().
@@ -228,6 +353,26 @@ func (m Model) renderMiniStatusBar(width int) string {
 		components = append(components, modelStatus)
 	}
 	
+	// Add estimated spend once anything has been spent or a budget is set
+	if spend := m.renderSpend(); spend != "" {
+		components = append(components, spend)
+	}
+	
+	// Add the number of commands waiting for a slot
+	if queued := m.renderQueueIndicator(); queued != "" {
+		components = append(components, queued)
+	}
+	
+	// Add the network meter while connected
+	if meter := m.renderNetworkMeter(); meter != "" {
+		components = append(components, meter)
+	}
+	
+	// Add activity indicator while a request is in flight
+	if m.activity.IsActive() {
+		components = append(components, m.activity.View())
+	}
+	
 	// Add system message if present
 	if m.systemMessage != "" {
 		sysMsg := lipgloss.NewStyle().
@@ -243,6 +388,27 @@ func (m Model) renderMiniStatusBar(width int) string {
 	return statusStyle.Render(content)
 }
 
+// renderSpend renders the estimated spend, colored by budget level
+func (m Model) renderSpend() string {
+	budget := m.config.Budget
+	if m.cost.Today() == 0 && budget.PerConversation <= 0 && budget.PerDay <= 0 {
+		return ""
+	}
+	
+	color := lipgloss.Color("46")
+	switch level, _ := m.cost.CheckBudget(budget, 0); level {
+	case BudgetWarning:
+		color = lipgloss.Color("220")
+	case BudgetExceeded:
+		color = lipgloss.Color("196")
+	}
+	
+	return lipgloss.NewStyle().
+		Foreground(color).
+		Bold(true).
+		Render(fmt.Sprintf("%s conv / %s today", formatCost(m.cost.Conversation()), formatCost(m.cost.Today())))
+}
+
 // renderWithCommandPalette renders the UI with command palette overlay
 func (m Model) renderWithCommandPalette() string {
 	// Create command palette overlay
@@ -275,10 +441,17 @@ func (m Model) renderWithCommandPalette() string {
 
 // renderWithModal renders the UI with a modal overlay
 func (m Model) renderWithModal() string {
-	// Render base view
-	base := m.renderBase()
+	modalStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("220")).
+		Padding(1, 2).
+		Width(60)
 	
-	// TODO: Implement modal overlay
-	return base
+	// Center the modal over the screen
+	return lipgloss.Place(
+		m.width, m.height,
+		lipgloss.Center, lipgloss.Center,
+		modalStyle.Render(m.modal.View()),
+	)
 }
 
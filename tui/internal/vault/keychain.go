@@ -0,0 +1,131 @@
+package vault
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ErrNoKeychain is returned where no supported OS keychain is installed
+var ErrNoKeychain = errors.New("no OS keychain available: needs the macOS security tool or libsecret's secret-tool; use a passphrase instead")
+
+// KeychainKey returns the key stored in the OS keychain under service and
+// account, creating and storing a random one the first time
+func KeychainKey(service, account string) ([]byte, error) {
+	stored, err := keychainLookup(service, account)
+	if err != nil {
+		return nil, err
+	}
+	if stored != "" {
+		key, err := hex.DecodeString(stored)
+		if err != nil || len(key) != KeySize {
+			return nil, fmt.Errorf("keychain entry %s/%s isn't a vault key", service, account)
+		}
+		return key, nil
+	}
+
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := keychainStore(service, account, hex.EncodeToString(key)); err != nil {
+		return nil, err
+	}
+	// Read it back, so a key that didn't stick isn't used to encrypt
+	if stored, err = keychainLookup(service, account); err != nil {
+		return nil, err
+	}
+	if stored != hex.EncodeToString(key) {
+		return nil, fmt.Errorf("keychain entry %s/%s didn't keep the new key", service, account)
+	}
+	return key, nil
+}
+
+// securityNotFound is the exit code of macOS security when no item matches
+const securityNotFound = 44
+
+// keychainResult is what a keychain tool printed and how it exited
+type keychainResult struct {
+	stdout string
+	stderr string
+	code   int
+}
+
+// keychainOS selects the keychain tool; tests set it to try each platform
+var keychainOS = runtime.GOOS
+
+// runKeychain runs a keychain tool with stdin. The error is set only when
+// the tool couldn't be run at all; tests replace it
+var runKeychain = func(stdin, name string, args ...string) (keychainResult, error) {
+	if _, err := exec.LookPath(name); err != nil {
+		return keychainResult{}, ErrNoKeychain
+	}
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return keychainResult{}, err
+	}
+	return keychainResult{stdout: stdout.String(), stderr: strings.TrimSpace(stderr.String()), code: cmd.ProcessState.ExitCode()}, nil
+}
+
+// keychainLookup reads a secret from the keychain, "" when there is none.
+// Any failure other than a missing entry, such as a locked keychain or a
+// refused access prompt, is an error, so a stored key is never replaced
+func keychainLookup(service, account string) (string, error) {
+	var result keychainResult
+	var err error
+	var notFound bool
+	switch keychainOS {
+	case "darwin":
+		result, err = runKeychain("", "security", "find-generic-password", "-s", service, "-a", account, "-w")
+		notFound = result.code == securityNotFound
+	case "linux", "freebsd", "openbsd":
+		result, err = runKeychain("", "secret-tool", "lookup", "service", service, "account", account)
+		// secret-tool says nothing and exits 1 when the entry doesn't exist
+		notFound = result.code == 1 && result.stdout == "" && result.stderr == ""
+	default:
+		return "", ErrNoKeychain
+	}
+	switch {
+	case err != nil:
+		return "", err
+	case notFound:
+		return "", nil
+	case result.code != 0:
+		return "", fmt.Errorf("cannot read the key from the keychain: exit status %d %s", result.code, result.stderr)
+	}
+	return strings.TrimSpace(result.stdout), nil
+}
+
+// keychainStore adds a secret to the keychain. It never replaces an existing
+// entry, and the secret goes through stdin, where ps can't see it
+func keychainStore(service, account, secret string) error {
+	var result keychainResult
+	var err error
+	switch keychainOS {
+	case "darwin":
+		// security -i reads its commands from stdin
+		command := fmt.Sprintf("add-generic-password -s %q -a %q -w %q\n", service, account, secret)
+		result, err = runKeychain(command, "security", "-i")
+	case "linux", "freebsd", "openbsd":
+		result, err = runKeychain(secret, "secret-tool", "store", "--label=RubberDuck transcript key", "service", service, "account", account)
+	default:
+		return ErrNoKeychain
+	}
+	if err == nil && result.code != 0 {
+		err = fmt.Errorf("exit status %d %s", result.code, result.stderr)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot store the key in the keychain: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package vault
+
+import (
+	"strings"
+	"testing"
+)
+
+// fakeKeychain stands in for the keychain tools, answering lookups with
+// lookup and remembering what was stored
+type fakeKeychain struct {
+	lookup keychainResult
+	stored string
+	calls  [][]string
+}
+
+func (f *fakeKeychain) run(stdin, name string, args ...string) (keychainResult, error) {
+	f.calls = append(f.calls, append([]string{name}, args...))
+	if args[0] == "find-generic-password" || args[0] == "lookup" {
+		if f.stored != "" {
+			return keychainResult{stdout: f.stored + "\n"}, nil
+		}
+		return f.lookup, nil
+	}
+	f.stored = stdin
+	if name == "security" {
+		// Keep only the -w argument of the command read from stdin
+		f.stored = strings.Trim(stdin[strings.LastIndex(stdin, " ")+1:], "\"\n")
+	}
+	return keychainResult{}, nil
+}
+
+func useFakeKeychain(t *testing.T, goos string, lookup keychainResult) *fakeKeychain {
+	fake := &fakeKeychain{lookup: lookup}
+	oldOS, oldRun := keychainOS, runKeychain
+	keychainOS, runKeychain = goos, fake.run
+	t.Cleanup(func() { keychainOS, runKeychain = oldOS, oldRun })
+	return fake
+}
+
+func TestKeychainKey_FailedLookupKeepsTheStoredKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		goos   string
+		lookup keychainResult
+	}{
+		{"locked macOS keychain", "darwin", keychainResult{code: 51, stderr: "User interaction is not allowed."}},
+		{"refused access prompt", "darwin", keychainResult{code: 128}},
+		{"secret service unreachable", "linux", keychainResult{code: 1, stderr: "Cannot autolaunch D-Bus without X11 $DISPLAY"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := useFakeKeychain(t, tt.goos, tt.lookup)
+			if _, err := KeychainKey("svc", "acct"); err == nil {
+				t.Fatal("Expected the failed lookup reported")
+			}
+			if len(fake.calls) != 1 {
+				t.Fatalf("Expected nothing stored after a failed lookup, got %v", fake.calls)
+			}
+		})
+	}
+}
+
+func TestKeychainKey_CreatesAMissingKey(t *testing.T) {
+	for goos, notFound := range map[string]keychainResult{
+		"darwin": {code: securityNotFound},
+		"linux":  {code: 1},
+	} {
+		t.Run(goos, func(t *testing.T) {
+			fake := useFakeKeychain(t, goos, notFound)
+			key, err := KeychainKey("svc", "acct")
+			if err != nil || len(key) != KeySize {
+				t.Fatalf("Expected a new key, got %v", err)
+			}
+			store := fake.calls[1]
+			for _, arg := range store {
+				if arg == "-U" || strings.Contains(arg, fake.stored) {
+					t.Errorf("Expected the key passed on stdin without -U, got %v", store)
+				}
+			}
+
+			again, err := KeychainKey("svc", "acct")
+			if err != nil || string(again) != string(key) {
+				t.Error("Expected the stored key returned the next time")
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+// Package vault encrypts files the TUI keeps on disk, such as saved
+// conversations, with AES-256-GCM
+package vault
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+)
+
+// KeySize is the length of a vault key in bytes
+const KeySize = 32
+
+// pbkdf2Iterations is how many rounds turn a passphrase into a key
+const pbkdf2Iterations = 600_000
+
+// magic starts every sealed file, so plaintext files written before
+// encryption was turned on can still be told apart and read
+var magic = []byte("RDENC1\n")
+
+var (
+	// ErrLocked is returned when a sealed file is read without a key
+	ErrLocked = errors.New("file is encrypted and no key is set; configure tui.encryption")
+
+	// ErrWrongKey is returned when a sealed file doesn't open with the key
+	ErrWrongKey = errors.New("file doesn't decrypt with this key")
+)
+
+// Vault seals and opens data with one key. A nil *Vault is valid and
+// leaves data as plaintext
+type Vault struct {
+	aead cipher.AEAD
+}
+
+// New creates a vault from a KeySize key
+func New(key []byte) (*Vault, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("vault key must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Vault{aead: aead}, nil
+}
+
+// NewSalt returns a random salt for DeriveKey
+func NewSalt() ([]byte, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+// DeriveKey derives a key from a passphrase with PBKDF2-SHA256
+func DeriveKey(passphrase string, salt []byte) ([]byte, error) {
+	if passphrase == "" {
+		return nil, errors.New("passphrase is empty")
+	}
+	return pbkdf2.Key(sha256.New, passphrase, salt, pbkdf2Iterations, KeySize)
+}
+
+// IsSealed reports whether data was written by Seal
+func IsSealed(data []byte) bool {
+	return bytes.HasPrefix(data, magic)
+}
+
+// Seal encrypts data as the magic header, a random nonce, and the
+// ciphertext. A nil vault returns data unchanged
+func (v *Vault) Seal(data []byte) ([]byte, error) {
+	if v == nil {
+		return data, nil
+	}
+	nonce := make([]byte, v.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, len(magic)+len(nonce)+len(data)+v.aead.Overhead())
+	out = append(out, magic...)
+	out = append(out, nonce...)
+	// The header is authenticated too, so it can't be swapped
+	return v.aead.Seal(out, nonce, data, magic), nil
+}
+
+// Open decrypts data written by Seal. Plaintext passes through unchanged,
+// so files from before encryption was turned on stay readable
+func (v *Vault) Open(data []byte) ([]byte, error) {
+	if !IsSealed(data) {
+		return data, nil
+	}
+	if v == nil {
+		return nil, ErrLocked
+	}
+	body := data[len(magic):]
+	if len(body) < v.aead.NonceSize() {
+		return nil, ErrWrongKey
+	}
+	nonce, ciphertext := body[:v.aead.NonceSize()], body[v.aead.NonceSize():]
+	plain, err := v.aead.Open(nil, nonce, ciphertext, magic)
+	if err != nil {
+		return nil, ErrWrongKey
+	}
+	return plain, nil
+}
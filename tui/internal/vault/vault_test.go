@@ -0,0 +1,49 @@
+package vault
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestVault_SealOpen(t *testing.T) {
+	salt, err := NewSalt()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := DeriveKey("correct horse", salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := New(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sealed, err := v.Seal([]byte(`{"id":"abc"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsSealed(sealed) || bytes.Contains(sealed, []byte("abc")) {
+		t.Fatalf("Expected sealed data without the plaintext, got %q", sealed)
+	}
+	plain, err := v.Open(sealed)
+	if err != nil || string(plain) != `{"id":"abc"}` {
+		t.Fatalf("Expected the plaintext back, got %q, %v", plain, err)
+	}
+
+	// Files written before encryption pass through
+	if plain, err := v.Open([]byte(`{"id":"old"}`)); err != nil || string(plain) != `{"id":"old"}` {
+		t.Fatalf("Expected plaintext to pass through, got %q, %v", plain, err)
+	}
+
+	otherKey, _ := DeriveKey("wrong horse", salt)
+	other, _ := New(otherKey)
+	if _, err := other.Open(sealed); !errors.Is(err, ErrWrongKey) {
+		t.Fatalf("Expected ErrWrongKey, got %v", err)
+	}
+	var none *Vault
+	if _, err := none.Open(sealed); !errors.Is(err, ErrLocked) {
+		t.Fatalf("Expected ErrLocked without a key, got %v", err)
+	}
+}